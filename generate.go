@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/labring/sealos-state-metrics/pkg/config"
+	"github.com/labring/sealos-state-metrics/pkg/generate"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	log "github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// generateCommand is the subcommand printing the dashboard, alerting rules or
+// RBAC manifests:
+//
+//	sealos-state-metric generate dashboard|alerts|rbac [flags]
+//
+// The flags, config file and env vars are the same as the server's, so the
+// output matches the enabled collectors, their configuration and the metrics
+// namespace.
+const generateCommand = "generate"
+
+// runGenerate writes the generated dashboard, alerting rules or RBAC manifests to stdout and
+// returns the process exit code
+func runGenerate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: sealos-state-metric generate dashboard|alerts|rbac [flags]")
+		return 1
+	}
+
+	cfg, err := config.LoadGlobalConfig(config.LoadOptions{Args: args[1:]})
+	if err != nil {
+		log.WithError(err).Error("Failed to load configuration")
+		return 1
+	}
+
+	opts := generate.Options{
+		Namespace:  cfg.Metrics.Namespace,
+		Collectors: cfg.EnabledCollectors,
+	}
+
+	var output []byte
+
+	switch args[0] {
+	case "dashboard":
+		output, err = generate.Dashboard(opts)
+	case "alerts":
+		output, err = generate.Alerts(opts)
+	case "rbac":
+		output, err = generateRBAC(cfg)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown generate target %q, expected dashboard, alerts or rbac\n", args[0])
+		return 1
+	}
+
+	if err != nil {
+		log.WithError(err).Error("Failed to generate " + args[0])
+		return 1
+	}
+
+	if _, err := os.Stdout.Write(output); err != nil {
+		log.WithError(err).Error("Failed to write output")
+		return 1
+	}
+
+	return 0
+}
+
+// generateRBAC returns the RBAC manifests granting the permissions of the
+// enabled collectors with their configuration, and of the enabled server
+// features: cluster identity detection, authentication and leader election
+func generateRBAC(cfg *config.GlobalConfig) ([]byte, error) {
+	var configContent []byte
+	if cfg.ConfigPath != "" {
+		var err error
+
+		configContent, err = os.ReadFile(cfg.ConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	rules, err := registry.GetRegistry().RequiredRBAC(configContent, cfg.EnabledCollectors)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterComplete := cfg.Cluster.Name != "" && cfg.Cluster.Region != "" && cfg.Cluster.Zone != ""
+	if cfg.Cluster.NodeLabels && !clusterComplete {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"nodes"},
+			Verbs:     []string{"get", "list"},
+		})
+	}
+
+	if cfg.Server.Auth.Enabled {
+		rules = append(rules,
+			rbacv1.PolicyRule{
+				APIGroups: []string{"authentication.k8s.io"},
+				Resources: []string{"tokenreviews"},
+				Verbs:     []string{"create"},
+			},
+			rbacv1.PolicyRule{
+				APIGroups: []string{"authorization.k8s.io"},
+				Resources: []string{"subjectaccessreviews"},
+				Verbs:     []string{"create"},
+			},
+		)
+	}
+
+	opts := generate.RBACOptions{Rules: registry.MergeRBAC(rules)}
+	if cfg.LeaderElection.Enabled {
+		opts.LeaseNamespace = cfg.LeaderElection.Namespace
+		// Sharded lease names depend on the collector instances
+		if !cfg.LeaderElection.Sharding {
+			opts.LeaseName = cfg.LeaderElection.LeaseName
+		}
+	}
+
+	return generate.RBAC(opts)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,6 +16,11 @@ import (
 )
 
 func main() {
+	// Dashboard and alerting rules generation: print and exit
+	if len(os.Args) > 1 && os.Args[1] == generateCommand {
+		os.Exit(runGenerate(os.Args[2:]))
+	}
+
 	// Store CLI args for config reload (skip program name)
 	cliArgs := os.Args[1:]
 
@@ -31,17 +37,24 @@ func main() {
 		log.WithError(err).Fatal("Configuration validation failed")
 	}
 
-	// Initialize logger
+	// Initialize logger (batch mode logs to stderr so metrics can be written to stdout)
+	logOutput := os.Stdout
+	if cfg.Once {
+		logOutput = os.Stderr
+	}
+
 	logger.InitLog(
 		logger.WithDebug(cfg.Logging.Debug),
 		logger.WithLevel(cfg.Logging.Level),
 		logger.WithFormat(cfg.Logging.Format),
+		logger.WithOutput(logOutput),
 	)
 
 	log.WithFields(log.Fields{
 		"collectors":     cfg.EnabledCollectors,
 		"leaderElection": cfg.LeaderElection.Enabled,
 		"metricsAddress": cfg.Server.Address,
+		"standalone":     cfg.Standalone,
 	}).Info("Configuration loaded")
 
 	// Read config file content if provided
@@ -56,6 +69,11 @@ func main() {
 	// Create server
 	srv := server.New(cfg, configContent)
 
+	// Batch mode: run a single collection cycle and exit
+	if cfg.Once {
+		os.Exit(runOnce(srv))
+	}
+
 	// Create pprof server
 	var pprofServer *pprof.Server
 	if cfg.Pprof.Enabled {
@@ -116,6 +134,25 @@ func main() {
 	log.Info("Server exited successfully")
 }
 
+// runOnce runs the server in batch mode and returns the process exit code
+func runOnce(srv *server.Server) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := srv.RunOnce(ctx)
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, server.ErrThresholdBreached):
+		log.WithError(err).Error("Batch run finished with breached thresholds")
+		return 2
+	default:
+		log.WithError(err).Error("Batch run failed")
+		return 1
+	}
+}
+
 // handleReload handles configuration reload for logger, server and pprof
 func handleReload(
 	cliArgs []string,
@@ -1,4 +1,5 @@
-// Package auth provides Kubernetes authentication middleware for HTTP endpoints
+// Package auth provides Kubernetes and static credential authentication
+// middleware for HTTP endpoints
 package auth
 
 import (
@@ -19,12 +20,41 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-// Authenticator handles Kubernetes authentication and authorization
+// Results of authenticated requests, reported to the result observer
+const (
+	// ResultAuthorized is a request authenticated and authorized
+	ResultAuthorized = "authorized"
+	// ResultUnauthenticated is a request without a valid bearer token or basic authentication
+	ResultUnauthenticated = "unauthenticated"
+	// ResultForbidden is a request whose user is not allowed to access the path
+	ResultForbidden = "forbidden"
+	// ResultError is a request whose authorization could not be checked
+	ResultError = "error"
+)
+
+// Authenticator handles Kubernetes authentication and authorization, and
+// static bearer tokens and basic authentication users
 type Authenticator struct {
+	// client reviews the bearer tokens that are not static, nil to only
+	// accept static credentials
 	client       kubernetes.Interface
+	tokens       []staticToken
+	users        map[string][sha256.Size]byte
 	authCache    *authCache
 	authzCache   *authzCache
 	retryBackoff wait.Backoff
+	observe      func(result string)
+}
+
+// Option configures an Authenticator
+type Option func(*Authenticator)
+
+// WithResultObserver calls observe with the result of every authenticated
+// request (one of the Result constants), e.g. to count authorized scrapes
+func WithResultObserver(observe func(result string)) Option {
+	return func(a *Authenticator) {
+		a.observe = observe
+	}
 }
 
 // authCache caches TokenReview results
@@ -49,8 +79,9 @@ type authzCacheEntry struct {
 	expiresAt time.Time
 }
 
-// NewAuthenticator creates a new authenticator with caching
-func NewAuthenticator(client kubernetes.Interface) *Authenticator {
+// NewAuthenticator creates a new authenticator with caching. With a nil
+// client, only the static credentials configured by the options are accepted.
+func NewAuthenticator(client kubernetes.Interface, opts ...Option) *Authenticator {
 	a := &Authenticator{
 		client: client,
 		authCache: &authCache{
@@ -66,6 +97,11 @@ func NewAuthenticator(client kubernetes.Interface) *Authenticator {
 			Jitter:   0.2,
 			Steps:    5,
 		},
+		observe: func(string) {},
+	}
+
+	for _, opt := range opts {
+		opt(a)
 	}
 
 	// Start cache cleanup goroutine
@@ -107,14 +143,41 @@ func (a *Authenticator) cleanupExpiredEntries() {
 }
 
 // Middleware returns an HTTP middleware that authenticates requests using Kubernetes TokenReview
-// and authorizes them using SubjectAccessReview
+// and authorizes them using SubjectAccessReview. Static bearer tokens and basic
+// authentication users are allowed on every path without review.
 func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); ok {
+			if !a.checkBasicAuth(user, password) {
+				log.WithField("user", user).Warn("Basic authentication failed")
+				a.unauthorized(w, "Unauthorized: invalid username or password")
+
+				return
+			}
+
+			a.allow(w, r, next, user)
+
+			return
+		}
+
 		// Extract bearer token from Authorization header
 		token := extractBearerToken(r)
 		if token == "" {
 			log.Debug("No bearer token found in request")
-			http.Error(w, "Unauthorized: no bearer token provided", http.StatusUnauthorized)
+			a.unauthorized(w, "Unauthorized: no bearer token provided")
+
+			return
+		}
+
+		if user, ok := a.staticTokenUser(token); ok {
+			a.allow(w, r, next, user)
+			return
+		}
+
+		if a.client == nil {
+			log.Warn("Static token authentication failed")
+			a.unauthorized(w, "Unauthorized: invalid bearer token")
+
 			return
 		}
 
@@ -122,7 +185,8 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 		userInfo, err := a.authenticateTokenCached(r.Context(), token)
 		if err != nil {
 			log.WithError(err).Warn("Token authentication failed")
-			http.Error(w, fmt.Sprintf("Unauthorized: %v", err), http.StatusUnauthorized)
+			a.unauthorized(w, fmt.Sprintf("Unauthorized: %v", err))
+
 			return
 		}
 
@@ -130,6 +194,7 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 		allowed, err := a.authorizeRequestCached(r.Context(), userInfo, r.URL.Path, r.Method)
 		if err != nil {
 			log.WithError(err).Error("Authorization check failed")
+			a.observe(ResultError)
 			http.Error(w, fmt.Sprintf("Internal error: %v", err), http.StatusInternalServerError)
 
 			return
@@ -141,21 +206,39 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Warn("Authorization denied")
+			a.observe(ResultForbidden)
 			http.Error(w, "Forbidden: insufficient permissions", http.StatusForbidden)
 
 			return
 		}
 
-		log.WithFields(log.Fields{
-			"user": userInfo.Username,
-			"path": r.URL.Path,
-		}).Debug("Request authenticated and authorized")
-
-		// Continue to the next handler
-		next.ServeHTTP(w, r)
+		a.allow(w, r, next, userInfo.Username)
 	})
 }
 
+// allow serves an authenticated and authorized request of user
+func (a *Authenticator) allow(w http.ResponseWriter, r *http.Request, next http.Handler, user string) {
+	log.WithFields(log.Fields{
+		"user": user,
+		"path": r.URL.Path,
+	}).Debug("Request authenticated and authorized")
+	a.observe(ResultAuthorized)
+
+	// Continue to the next handler
+	next.ServeHTTP(w, r)
+}
+
+// unauthorized rejects an unauthenticated request, asking for basic
+// authentication when users are configured
+func (a *Authenticator) unauthorized(w http.ResponseWriter, message string) {
+	if len(a.users) > 0 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="sealos-state-metrics"`)
+	}
+
+	a.observe(ResultUnauthenticated)
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
 // extractBearerToken extracts the bearer token from the Authorization header
 func extractBearerToken(r *http.Request) string {
 	auth := r.Header.Get("Authorization")
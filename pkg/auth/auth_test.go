@@ -0,0 +1,223 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/auth"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns a client authenticating the "valid" and "denied"
+// tokens, and only authorizing the user of the "valid" token
+func newFakeClient() *fake.Clientset {
+	client := fake.NewClientset()
+
+	client.PrependReactor("create", "tokenreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review, _ := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+
+			switch review.Spec.Token {
+			case "valid", "denied":
+				review.Status.Authenticated = true
+				review.Status.User.Username = review.Spec.Token
+			default:
+				review.Status.Error = "invalid token"
+			}
+
+			return true, review, nil
+		})
+
+	client.PrependReactor("create", "subjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			sar, _ := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			sar.Status.Allowed = sar.Spec.User == "valid"
+
+			return true, sar, nil
+		})
+
+	return client
+}
+
+func TestMiddlewareResults(t *testing.T) {
+	results := make(map[string]int)
+
+	authenticator := auth.NewAuthenticator(newFakeClient(), auth.WithResultObserver(func(result string) {
+		results[result]++
+	}))
+
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		token      string
+		wantStatus int
+	}{
+		{token: "", wantStatus: http.StatusUnauthorized},
+		{token: "unknown", wantStatus: http.StatusUnauthorized},
+		{token: "denied", wantStatus: http.StatusForbidden},
+		{token: "valid", wantStatus: http.StatusOK},
+		{token: "valid", wantStatus: http.StatusOK}, // cached
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("token %q: expected status %d, got %d", tt.token, tt.wantStatus, rec.Code)
+		}
+	}
+
+	want := map[string]int{auth.ResultUnauthenticated: 2, auth.ResultForbidden: 1, auth.ResultAuthorized: 2}
+	for result, count := range want {
+		if results[result] != count {
+			t.Errorf("Expected %d %s results, got %d", count, result, results[result])
+		}
+	}
+}
+
+func TestMiddlewareStaticCredentials(t *testing.T) {
+	results := make(map[string]int)
+
+	authenticator := auth.NewAuthenticator(
+		newFakeClient(),
+		auth.WithStaticTokens(map[string]string{"static": "prometheus"}),
+		auth.WithBasicAuth(map[string]string{"admin": "secret"}),
+		auth.WithResultObserver(func(result string) {
+			results[result]++
+		}),
+	)
+
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		setAuth    func(req *http.Request)
+		wantStatus int
+	}{
+		{
+			name:       "static token",
+			setAuth:    func(req *http.Request) { req.Header.Set("Authorization", "Bearer static") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reviewed token",
+			setAuth:    func(req *http.Request) { req.Header.Set("Authorization", "Bearer valid") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "basic auth",
+			setAuth:    func(req *http.Request) { req.SetBasicAuth("admin", "secret") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong password",
+			setAuth:    func(req *http.Request) { req.SetBasicAuth("admin", "wrong") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unknown user",
+			setAuth:    func(req *http.Request) { req.SetBasicAuth("nobody", "secret") },
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		tt.setAuth(req)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, rec.Code)
+		}
+
+		if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: expected a WWW-Authenticate header", tt.name)
+		}
+	}
+
+	want := map[string]int{auth.ResultUnauthenticated: 2, auth.ResultAuthorized: 3}
+	for result, count := range want {
+		if results[result] != count {
+			t.Errorf("Expected %d %s results, got %d", count, result, results[result])
+		}
+	}
+}
+
+func TestMiddlewareStaticOnly(t *testing.T) {
+	authenticator := auth.NewAuthenticator(nil, auth.WithStaticTokens(map[string]string{"static": "prometheus"}))
+
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for token, wantStatus := range map[string]int{"static": http.StatusOK, "valid": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != wantStatus {
+			t.Errorf("token %q: expected status %d, got %d", token, wantStatus, rec.Code)
+		}
+	}
+}
+
+func TestLoadCredentialsFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	tokensFile := filepath.Join(dir, "tokens.csv")
+	if err := os.WriteFile(tokensFile, []byte("# scrapers\nabc,prometheus,uid-1\n\ndef,vmagent\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := auth.LoadTokensFile(tokensFile)
+	if err != nil {
+		t.Fatalf("LoadTokensFile() error = %v", err)
+	}
+
+	if len(tokens) != 2 || tokens["abc"] != "prometheus" || tokens["def"] != "vmagent" {
+		t.Errorf("Unexpected tokens: %v", tokens)
+	}
+
+	usersFile := filepath.Join(dir, "users")
+	if err := os.WriteFile(usersFile, []byte("admin:pass:with:colons\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := auth.LoadBasicAuthFile(usersFile)
+	if err != nil {
+		t.Fatalf("LoadBasicAuthFile() error = %v", err)
+	}
+
+	if users["admin"] != "pass:with:colons" {
+		t.Errorf("Unexpected users: %v", users)
+	}
+
+	if err := os.WriteFile(usersFile, []byte("admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.LoadBasicAuthFile(usersFile); err == nil {
+		t.Error("Expected an error for a line without password")
+	}
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// staticToken is a static bearer token, kept as a hash to compare in constant time
+type staticToken struct {
+	hash [sha256.Size]byte
+	user string
+}
+
+// WithStaticTokens accepts the bearer tokens of tokens (token to user name)
+// on every path, before any TokenReview
+func WithStaticTokens(tokens map[string]string) Option {
+	return func(a *Authenticator) {
+		for token, user := range tokens {
+			a.tokens = append(a.tokens, staticToken{hash: sha256.Sum256([]byte(token)), user: user})
+		}
+	}
+}
+
+// WithBasicAuth accepts basic authentication with the users of users (user
+// name to password) on every path
+func WithBasicAuth(users map[string]string) Option {
+	return func(a *Authenticator) {
+		if a.users == nil {
+			a.users = make(map[string][sha256.Size]byte, len(users))
+		}
+
+		for user, password := range users {
+			a.users[user] = sha256.Sum256([]byte(password))
+		}
+	}
+}
+
+// staticTokenUser returns the user of a static bearer token. Every token is
+// compared so that the time taken does not tell which one matched.
+func (a *Authenticator) staticTokenUser(token string) (string, bool) {
+	hash := sha256.Sum256([]byte(token))
+
+	var (
+		user  string
+		found bool
+	)
+
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(hash[:], t.hash[:]) == 1 {
+			user, found = t.user, true
+		}
+	}
+
+	return user, found
+}
+
+// checkBasicAuth reports whether password is the password of user
+func (a *Authenticator) checkBasicAuth(user, password string) bool {
+	expected, ok := a.users[user]
+	hash := sha256.Sum256([]byte(password))
+
+	return subtle.ConstantTimeCompare(hash[:], expected[:]) == 1 && ok
+}
+
+// LoadTokensFile reads static bearer tokens from a file of "token,user" lines,
+// like the static token file of the API server (further fields are ignored).
+// Blank lines and lines starting with # are ignored.
+func LoadTokensFile(path string) (map[string]string, error) {
+	lines, err := loadCredentialsFile(path, ",")
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]string, len(lines))
+	for _, fields := range lines {
+		user, _, _ := strings.Cut(fields[1], ",")
+		tokens[fields[0]] = user
+	}
+
+	return tokens, nil
+}
+
+// LoadBasicAuthFile reads basic authentication users from a file of
+// "user:password" lines. Blank lines and lines starting with # are ignored.
+func LoadBasicAuthFile(path string) (map[string]string, error) {
+	lines, err := loadCredentialsFile(path, ":")
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]string, len(lines))
+	for _, fields := range lines {
+		users[fields[0]] = fields[1]
+	}
+
+	return users, nil
+}
+
+// loadCredentialsFile reads the lines of a credentials file, split at the
+// first sep
+func loadCredentialsFile(path, sep string) ([][2]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open credentials file: %w", err)
+	}
+	defer file.Close()
+
+	var lines [][2]string
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		first, second, ok := strings.Cut(text, sep)
+		if !ok || first == "" || second == "" {
+			return nil, fmt.Errorf("%s:%d: expected two fields separated by %q", path, line, sep)
+		}
+
+		lines = append(lines, [2]string{first, second})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	return lines, nil
+}
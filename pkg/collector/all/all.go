@@ -3,13 +3,24 @@ package all
 
 import (
 	// Import all collectors to trigger their init() functions
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/cert"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/cloudbalance"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/critical"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/dbprobe"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/domain"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/dynamic"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/event"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/helm"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/imagepull"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/kubeblocks"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/lvm"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/node"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/openebs"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/plugin"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/pod"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/probe"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/pvc"
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/quota"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/userbalance"
 	_ "github.com/labring/sealos-state-metrics/pkg/collector/zombie"
 )
@@ -3,9 +3,11 @@ package base
 import (
 	"context"
 	"fmt"
+	"runtime/pprof"
 	"sync"
 	"time"
 
+	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
@@ -27,12 +29,38 @@ type BaseCollector struct {
 	//nolint:containedctx // Context is intentionally stored to manage collector lifecycle between Start/Stop
 	ctx    context.Context
 	cancel context.CancelFunc
+	//nolint:containedctx // Parent context of the last Start, to restart the collector on a stale watch
+	parentCtx context.Context
 
 	// Metrics registry
 	descs []*prometheus.Desc
 
 	// Lifecycle implementation
 	lifecycle Lifecycle
+
+	// Observers notified after each poll cycle (polling collectors only)
+	pollObservers []PollObserver
+
+	// Lifecycle state reported by Liveness
+	startErr        error
+	startedAt       time.Time
+	lastPollSuccess time.Time
+
+	// Timeout hierarchy bounding poll cycles (see deadline.go)
+	globalTimeout    time.Duration
+	collectorTimeout time.Duration
+	canceledChecks   map[string]uint64 // key: deadline level
+
+	// Restart on stale informer watches (see watchdog.go)
+	staleWatchTimeout time.Duration
+
+	// Event handler and lock counters (see runtime.go)
+	runtime runtimeStats
+
+	// Informer notification queue of the running collector (see queue.go)
+	queueWorkers  int
+	queueMaxDepth int
+	queue         *eventQueue
 }
 
 // BaseCollectorOption is a functional option for configuring BaseCollector
@@ -132,7 +160,11 @@ func (b *BaseCollector) Start(ctx context.Context) error {
 	}
 
 	b.ctx, b.cancel = context.WithCancel(ctx)
+	b.parentCtx = ctx
 	b.started = true
+	b.startErr = nil
+	b.startedAt = time.Now()
+	b.lastPollSuccess = time.Time{}
 	b.ready = false
 	b.readyCh = make(chan struct{})
 	b.stoppedCh = make(chan struct{})
@@ -140,11 +172,22 @@ func (b *BaseCollector) Start(ctx context.Context) error {
 
 	b.mu.Unlock()
 
+	// Informers of the previous run are stopped, OnStart creates new ones
+	b.runtime.resetInformers()
+
 	b.logger.WithField("name", b.name).Info("Collector started")
 
 	// Call lifecycle OnStart hook if set (outside the lock to avoid deadlock)
 	if lifecycle != nil {
-		if err := lifecycle.OnStart(b.ctx); err != nil {
+		// Goroutines started by the hook (informers, poll loops) inherit the
+		// collector label, so they can be counted per collector
+		var err error
+
+		pprof.Do(b.ctx, pprof.Labels(collector.GoroutineLabel, b.name), func(ctx context.Context) {
+			err = lifecycle.OnStart(ctx)
+		})
+
+		if err != nil {
 			// OnStart failed, rollback the started state
 			b.mu.Lock()
 
@@ -154,6 +197,8 @@ func (b *BaseCollector) Start(ctx context.Context) error {
 			}
 
 			b.started = false
+			b.startErr = err
+			b.stopEventQueue()
 
 			b.ready = false
 			if b.stoppedCh != nil {
@@ -174,6 +219,9 @@ func (b *BaseCollector) Start(ctx context.Context) error {
 func (b *BaseCollector) Stop() error {
 	b.mu.Lock()
 
+	// A collector stopped after a failed start is no longer expected to run
+	b.startErr = nil
+
 	if !b.started {
 		b.mu.Unlock()
 		return fmt.Errorf("collector %s not started", b.name)
@@ -206,6 +254,11 @@ func (b *BaseCollector) Stop() error {
 		}
 	}
 
+	// The informers are stopped, the notifications still queued are dropped
+	b.mu.Lock()
+	b.stopEventQueue()
+	b.mu.Unlock()
+
 	b.logger.WithField("name", b.name).Info("Collector stopped")
 
 	return nil
@@ -220,7 +273,18 @@ func (b *BaseCollector) IsStarted() bool {
 
 // SetReady marks the collector as ready to collect metrics
 // Note: Once ready, the collector cannot become not-ready again (except through Stop/Start cycle)
+// With an event queue, it first waits for the notifications queued so far
+// (e.g. the initial list of the synced informers) to be handled.
 func (b *BaseCollector) SetReady() {
+	b.mu.RLock()
+	queue := b.queue
+	ctx := b.ctx
+	b.mu.RUnlock()
+
+	if queue != nil && ctx != nil {
+		queue.waitIdle(ctx)
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -245,6 +309,20 @@ func (b *BaseCollector) IsReady() bool {
 	return b.ready
 }
 
+// Liveness returns the lifecycle state of the collector
+func (b *BaseCollector) Liveness() collector.Liveness {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return collector.Liveness{
+		Started:         b.started,
+		StartError:      b.startErr,
+		Ready:           b.ready,
+		StartedAt:       b.startedAt,
+		LastPollSuccess: b.lastPollSuccess,
+	}
+}
+
 // WaitReady blocks until the collector is ready to collect metrics
 // Returns nil if ready, or an error if context is cancelled or collector is stopped
 func (b *BaseCollector) WaitReady(ctx context.Context) error {
@@ -0,0 +1,55 @@
+package base_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestBaseCollectorLiveness(t *testing.T) {
+	b := base.NewBaseCollector("test", log.NewEntry(log.StandardLogger()))
+	b.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error { return errors.New("forbidden") },
+	})
+
+	if err := b.Start(context.Background()); err == nil {
+		t.Fatal("Expected start to fail")
+	}
+
+	if b.Liveness().StartError == nil {
+		t.Error("Expected the start error to be reported")
+	}
+
+	// Stopping the collector (e.g. on leadership loss) clears the error
+	_ = b.Stop()
+
+	if b.Liveness().StartError != nil {
+		t.Error("Expected the start error to be cleared on stop")
+	}
+
+	b.SetLifecycle(base.LifecycleFuncs{})
+
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("Unexpected start error: %v", err)
+	}
+	defer func() { _ = b.Stop() }()
+
+	if liveness := b.Liveness(); !liveness.Started || !liveness.LastPollSuccess.IsZero() {
+		t.Errorf("Expected a started collector without successful poll, got %+v", liveness)
+	}
+
+	b.RecordPoll(errors.New("timeout"))
+
+	if !b.Liveness().LastPollSuccess.IsZero() {
+		t.Error("Expected failed polls not to be recorded as successful")
+	}
+
+	b.RecordPoll(nil)
+
+	if b.Liveness().LastPollSuccess.IsZero() {
+		t.Error("Expected the successful poll to be recorded")
+	}
+}
@@ -0,0 +1,120 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Deadline levels of the timeout hierarchy: global -> collector -> check.
+// Each level can only shorten the deadline inherited from the level above.
+const (
+	DeadlineLevelGlobal    = "global"
+	DeadlineLevelCollector = "collector"
+	DeadlineLevelCheck     = "check"
+)
+
+var (
+	// ErrGlobalDeadline is the cancellation cause of a cycle exceeding the global collection timeout
+	ErrGlobalDeadline = errors.New("global collection deadline exceeded")
+	// ErrCollectorDeadline is the cancellation cause of a cycle exceeding the collector timeout
+	ErrCollectorDeadline = errors.New("collector deadline exceeded")
+	// ErrCheckDeadline is the cancellation cause of a single check exceeding its timeout
+	ErrCheckDeadline = errors.New("check deadline exceeded")
+)
+
+// WithCollectionTimeouts returns an option that sets the global and collector-level
+// timeouts bounding each poll cycle. Zero disables the corresponding level.
+func WithCollectionTimeouts(global, collector time.Duration) BaseCollectorOption {
+	return func(b *BaseCollector) {
+		b.globalTimeout = global
+		b.collectorTimeout = collector
+	}
+}
+
+// CycleContext returns a context bounded by the global and collector-level timeouts.
+// The cancellation cause identifies which level expired first.
+func (b *BaseCollector) CycleContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancelGlobal := parent, context.CancelFunc(func() {})
+	if b.globalTimeout > 0 {
+		ctx, cancelGlobal = context.WithTimeoutCause(ctx, b.globalTimeout, ErrGlobalDeadline)
+	}
+
+	if b.collectorTimeout <= 0 {
+		return ctx, cancelGlobal
+	}
+
+	ctx, cancelCollector := context.WithTimeoutCause(ctx, b.collectorTimeout, ErrCollectorDeadline)
+
+	return ctx, func() {
+		cancelCollector()
+		cancelGlobal()
+	}
+}
+
+// WithCheckTimeout returns a context bounded by a per-check timeout, the lowest
+// level of the timeout hierarchy. Zero keeps the inherited deadline.
+func WithCheckTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeoutCause(parent, timeout, ErrCheckDeadline)
+}
+
+// DeadlineLevel returns the level of the timeout hierarchy that canceled ctx,
+// or false if ctx is not done or was canceled for another reason (e.g. shutdown)
+func DeadlineLevel(ctx context.Context) (string, bool) {
+	if ctx.Err() == nil {
+		return "", false
+	}
+
+	cause := context.Cause(ctx)
+
+	switch {
+	case errors.Is(cause, ErrCheckDeadline):
+		return DeadlineLevelCheck, true
+	case errors.Is(cause, ErrCollectorDeadline):
+		return DeadlineLevelCollector, true
+	case errors.Is(cause, ErrGlobalDeadline):
+		return DeadlineLevelGlobal, true
+	default:
+		return "", false
+	}
+}
+
+// RecordCanceled counts a check canceled by a deadline of the timeout hierarchy.
+// It is a no-op when ctx is not done or was canceled for another reason.
+func (b *BaseCollector) RecordCanceled(ctx context.Context) {
+	level, ok := DeadlineLevel(ctx)
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.canceledChecks == nil {
+		b.canceledChecks = make(map[string]uint64)
+	}
+
+	b.canceledChecks[level]++
+}
+
+// CanceledChecks returns the number of checks canceled per deadline level
+func (b *BaseCollector) CanceledChecks() map[string]uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	counts := map[string]uint64{
+		DeadlineLevelGlobal:    0,
+		DeadlineLevelCollector: 0,
+		DeadlineLevelCheck:     0,
+	}
+
+	for level, count := range b.canceledChecks {
+		counts[level] = count
+	}
+
+	return counts
+}
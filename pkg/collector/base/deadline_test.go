@@ -0,0 +1,92 @@
+package base_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+)
+
+// TestDeadlineLevel verifies that the level of the timeout hierarchy expiring first is reported
+func TestDeadlineLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		global        time.Duration
+		collector     time.Duration
+		check         time.Duration
+		expectedLevel string
+	}{
+		{
+			name:          "check expires first",
+			global:        time.Minute,
+			collector:     time.Minute,
+			check:         time.Millisecond,
+			expectedLevel: base.DeadlineLevelCheck,
+		},
+		{
+			name:          "collector expires first",
+			global:        time.Minute,
+			collector:     time.Millisecond,
+			check:         time.Minute,
+			expectedLevel: base.DeadlineLevelCollector,
+		},
+		{
+			name:          "global expires first",
+			global:        time.Millisecond,
+			collector:     time.Minute,
+			check:         time.Minute,
+			expectedLevel: base.DeadlineLevelGlobal,
+		},
+		{
+			name:          "collector level disabled",
+			global:        time.Millisecond,
+			check:         time.Minute,
+			expectedLevel: base.DeadlineLevelGlobal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := base.NewBaseCollector(
+				"test",
+				log.NewEntry(log.New()),
+				base.WithCollectionTimeouts(tt.global, tt.collector),
+			)
+
+			cycleCtx, cancelCycle := b.CycleContext(context.Background())
+			defer cancelCycle()
+
+			checkCtx, cancelCheck := base.WithCheckTimeout(cycleCtx, tt.check)
+			defer cancelCheck()
+
+			<-checkCtx.Done()
+
+			level, ok := base.DeadlineLevel(checkCtx)
+			if !ok || level != tt.expectedLevel {
+				t.Errorf("Expected level %q, got %q (ok=%v)", tt.expectedLevel, level, ok)
+			}
+
+			b.RecordCanceled(checkCtx)
+
+			if count := b.CanceledChecks()[tt.expectedLevel]; count != 1 {
+				t.Errorf("Expected 1 canceled check at level %q, got %d", tt.expectedLevel, count)
+			}
+		})
+	}
+}
+
+// TestDeadlineLevel_ParentCanceled verifies that shutdown is not counted as a deadline
+func TestDeadlineLevel_ParentCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+
+	checkCtx, cancelCheck := base.WithCheckTimeout(parent, time.Minute)
+	defer cancelCheck()
+
+	cancel()
+
+	if level, ok := base.DeadlineLevel(checkCtx); ok {
+		t.Errorf("Expected no deadline level for canceled parent, got %q", level)
+	}
+}
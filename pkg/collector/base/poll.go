@@ -0,0 +1,51 @@
+package base
+
+import (
+	"context"
+	"time"
+)
+
+// PollObserver is notified after every poll cycle of a polling collector.
+// err is nil when the cycle completed successfully.
+type PollObserver func(collector string, err error)
+
+// AddPollObserver registers an observer that is notified after every poll cycle.
+// Observers are called synchronously from the poll loop and must not block.
+func (b *BaseCollector) AddPollObserver(observer PollObserver) {
+	if observer == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pollObservers = append(b.pollObservers, observer)
+}
+
+// RecordPoll records the result of a poll cycle and notifies registered observers.
+// Polling collectors should call this after each Poll.
+func (b *BaseCollector) RecordPoll(err error) {
+	b.mu.Lock()
+	observers := b.pollObservers
+
+	if err == nil {
+		b.lastPollSuccess = time.Now()
+	}
+	b.mu.Unlock()
+
+	for _, observer := range observers {
+		observer(b.name, err)
+	}
+}
+
+// PollOnce runs one poll cycle bounded by the collection timeouts
+// (see CycleContext) and notifies registered observers of its result
+func (b *BaseCollector) PollOnce(ctx context.Context, poll func(context.Context) error) error {
+	cycleCtx, cancel := b.CycleContext(ctx)
+	defer cancel()
+
+	err := poll(cycleCtx)
+	b.RecordPoll(err)
+
+	return err
+}
@@ -0,0 +1,370 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// maxEventRetries is the number of times a notification whose handler
+// panicked is retried before it is dropped
+const maxEventRetries = 5
+
+// queueIdlePollInterval is the interval at which SetReady checks whether the
+// event queue handled the notifications of the initial list
+const queueIdlePollInterval = 10 * time.Millisecond
+
+// WithEventQueue returns an option that hands the informer notifications
+// wrapped by InstrumentHandler over to workers through a rate-limited workqueue, so
+// slow handlers (e.g. waiting for a collection to release the collector
+// state) no longer block the informer delivery goroutine. Notifications of
+// the same object waiting in the queue are coalesced, and at most maxDepth
+// objects (0 = unbounded) wait in the queue: above it, informer notifications
+// wait for the workers. Zero workers handles the notifications on the
+// informer goroutine.
+func WithEventQueue(workers, maxDepth int) BaseCollectorOption {
+	return func(b *BaseCollector) {
+		b.queueWorkers = workers
+		b.queueMaxDepth = maxDepth
+	}
+}
+
+// runningQueue returns the event queue of the running collector, created on
+// first use, or nil when the collector has no event queue or is not started
+func (b *BaseCollector) runningQueue() *eventQueue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.queueWorkers <= 0 || !b.started {
+		return nil
+	}
+
+	if b.queue == nil {
+		b.queue = newEventQueue(b.name, b.queueWorkers, b.queueMaxDepth, &b.runtime, b.logger)
+	}
+
+	return b.queue
+}
+
+// stopEventQueue shuts the event queue of the last run down, b.mu must be held
+func (b *BaseCollector) stopEventQueue() {
+	if b.queue != nil {
+		b.queue.shutdown()
+		b.queue = nil
+	}
+}
+
+// queueDepth returns the number of objects with notifications waiting in the
+// event queue of the running collector
+func (b *BaseCollector) queueDepth() int {
+	b.mu.RLock()
+	queue := b.queue
+	b.mu.RUnlock()
+
+	if queue == nil {
+		return 0
+	}
+
+	return queue.depth()
+}
+
+// queuedEvent is an informer notification waiting in the event queue
+type queuedEvent struct {
+	eventType string
+	oldObj    any
+	obj       any
+	// initial is set for the additions of the initial list
+	initial bool
+	queued  time.Time
+}
+
+// eventQueue hands the informer notifications of a collector over to its
+// workers. Keys are the handler index and the object key; the notifications
+// of a key are kept in pending until a worker takes the key off the queue.
+type eventQueue struct {
+	queue    workqueue.TypedRateLimitingInterface[string]
+	maxDepth int
+	stats    *runtimeStats
+	logger   *log.Entry
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signaled when a key leaves pending or on shutdown
+	handlers []cache.ResourceEventHandlerDetailedFuncs
+	pending  map[string][]queuedEvent
+	active   int // keys being handled
+	closed   bool
+}
+
+// newEventQueue returns an event queue and starts its workers, which exit
+// once the queue is shut down
+func newEventQueue(name string, workers, maxDepth int, stats *runtimeStats, logger *log.Entry) *eventQueue {
+	q := &eventQueue{
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: name},
+		),
+		maxDepth: maxDepth,
+		stats:    stats,
+		logger:   logger,
+		pending:  make(map[string][]queuedEvent),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	for range workers {
+		go q.run()
+	}
+
+	return q
+}
+
+// register returns handlers queueing the notifications for handler
+func (q *eventQueue) register(
+	handler cache.ResourceEventHandlerDetailedFuncs,
+) cache.ResourceEventHandlerDetailedFuncs {
+	q.mu.Lock()
+	index := len(q.handlers)
+	q.handlers = append(q.handlers, handler)
+	q.mu.Unlock()
+
+	queued := cache.ResourceEventHandlerDetailedFuncs{}
+
+	if handler.AddFunc != nil {
+		queued.AddFunc = func(obj any, isInInitialList bool) {
+			q.add(index, queuedEvent{eventType: EventTypeAdd, obj: obj, initial: isInInitialList})
+		}
+	}
+
+	if handler.UpdateFunc != nil {
+		queued.UpdateFunc = func(oldObj, newObj any) {
+			q.add(index, queuedEvent{eventType: EventTypeUpdate, oldObj: oldObj, obj: newObj})
+		}
+	}
+
+	if handler.DeleteFunc != nil {
+		queued.DeleteFunc = func(obj any) {
+			q.add(index, queuedEvent{eventType: EventTypeDelete, obj: obj})
+		}
+	}
+
+	return queued
+}
+
+// add queues the notification of a handler, waiting while the queue is full.
+// Notifications of objects without a key are handled right away.
+func (q *eventQueue) add(index int, event queuedEvent) {
+	objKey, err := cache.DeletionHandlingMetaNamespaceKeyFunc(event.obj)
+	if err != nil {
+		if err := q.handle(index, event); err != nil {
+			q.logger.WithError(err).Error("Informer notification handler failed")
+		}
+
+		return
+	}
+
+	key := strconv.Itoa(index) + "/" + objKey
+	event.queued = time.Now()
+
+	q.mu.Lock()
+
+	// Notifications of an object already waiting are coalesced, they never
+	// grow the queue
+	for q.maxDepth > 0 && len(q.pending) >= q.maxDepth && q.pending[key] == nil && !q.closed {
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+
+	q.pending[key] = coalesceEvents(q.pending[key], event)
+	q.mu.Unlock()
+
+	q.queue.Add(key)
+}
+
+// coalesceEvents appends event to the notifications waiting for an object,
+// keeping the latest state only. A deletion is kept until it is handled, so
+// that handlers keyed by UID see a recreated object as deleted and added
+// again.
+func coalesceEvents(events []queuedEvent, event queuedEvent) []queuedEvent {
+	if len(events) == 0 {
+		return []queuedEvent{event}
+	}
+
+	last := &events[len(events)-1]
+
+	switch {
+	case event.eventType == EventTypeDelete:
+		// Additions and updates of a deleted object are moot
+		if events[0].eventType == EventTypeDelete {
+			return []queuedEvent{events[0], event}
+		}
+
+		event.queued = events[0].queued
+
+		return []queuedEvent{event}
+	case last.eventType == EventTypeDelete:
+		return append(events, event)
+	case event.eventType == EventTypeUpdate:
+		// An addition or update not handled yet carries the latest state
+		last.obj = event.obj
+	default:
+		last.eventType = event.eventType
+		last.oldObj = nil
+		last.obj = event.obj
+		last.initial = event.initial
+	}
+
+	return events
+}
+
+// run handles the queued notifications until the queue is shut down
+func (q *eventQueue) run() {
+	for q.processNext() {
+	}
+}
+
+// processNext handles the notifications of the next key, and retries them
+// with rate limiting when a handler panicked
+func (q *eventQueue) processNext() bool {
+	key, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(key)
+
+	prefix, _, _ := strings.Cut(key, "/")
+	index, _ := strconv.Atoi(prefix)
+
+	q.mu.Lock()
+	events := q.pending[key]
+	delete(q.pending, key)
+	q.active++
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.active--
+		q.mu.Unlock()
+	}()
+
+	for i, event := range events {
+		q.stats.queueWaitNanos.Add(int64(time.Since(event.queued)))
+
+		if err := q.handle(index, event); err != nil {
+			q.retry(key, events[i:], err)
+			return true
+		}
+	}
+
+	q.queue.Forget(key)
+
+	return true
+}
+
+// retry puts the notifications left back in front of the ones queued
+// meanwhile, or drops them after maxEventRetries attempts
+func (q *eventQueue) retry(key string, events []queuedEvent, err error) {
+	logger := q.logger.WithError(err).WithField("key", key)
+
+	if q.queue.NumRequeues(key) >= maxEventRetries {
+		logger.Error("Dropping informer notification after repeated handler failures")
+		q.queue.Forget(key)
+
+		return
+	}
+
+	logger.Warn("Informer notification handler failed, retrying")
+	q.stats.queueRetries.Add(1)
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+
+	for _, event := range q.pending[key] {
+		events = coalesceEvents(events, event)
+	}
+
+	q.pending[key] = events
+	q.mu.Unlock()
+
+	q.queue.AddRateLimited(key)
+}
+
+// handle calls the handler of a notification, turning a panic into an error
+func (q *eventQueue) handle(index int, event queuedEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	q.mu.Lock()
+	handler := q.handlers[index]
+	q.mu.Unlock()
+
+	switch event.eventType {
+	case EventTypeAdd:
+		handler.OnAdd(event.obj, event.initial)
+	case EventTypeUpdate:
+		handler.OnUpdate(event.oldObj, event.obj)
+	case EventTypeDelete:
+		handler.OnDelete(event.obj)
+	}
+
+	return nil
+}
+
+// depth returns the number of objects with notifications waiting
+func (q *eventQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// waitIdle waits until no notification is queued nor being handled, or ctx
+// is done
+func (q *eventQueue) waitIdle(ctx context.Context) {
+	ticker := time.NewTicker(queueIdlePollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.mu.Lock()
+		idle := len(q.pending) == 0 && q.active == 0
+		q.mu.Unlock()
+
+		if idle {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdown drops the queued notifications and stops the workers once they
+// handled the current ones
+func (q *eventQueue) shutdown() {
+	q.mu.Lock()
+	q.closed = true
+	q.pending = make(map[string][]queuedEvent)
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	q.queue.ShutDown()
+}
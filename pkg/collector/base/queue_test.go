@@ -0,0 +1,168 @@
+package base_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// startQueued starts a collector with an event queue whose start hook
+// instruments handler, and returns the queued handler
+func startQueued(
+	t *testing.T,
+	handler cache.ResourceEventHandlerFuncs,
+) (*base.BaseCollector, cache.ResourceEventHandlerFuncs) {
+	t.Helper()
+
+	b := base.NewBaseCollector("test", log.NewEntry(log.StandardLogger()), base.WithEventQueue(1, 0))
+
+	var queued cache.ResourceEventHandlerFuncs
+
+	b.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(context.Context) error {
+			queued = b.InstrumentHandler(handler)
+			return nil
+		},
+	})
+
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = b.Stop() })
+
+	return b, queued
+}
+
+func pod(name, version string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, ResourceVersion: version}}
+}
+
+func TestEventQueue(t *testing.T) {
+	release := make(chan struct{})
+
+	var (
+		mu      sync.Mutex
+		handled []string
+	)
+
+	record := func(event string, obj any) {
+		p := obj.(*corev1.Pod)
+
+		mu.Lock()
+		handled = append(handled, event+" "+p.Name+"@"+p.ResourceVersion)
+		mu.Unlock()
+	}
+
+	b, queued := startQueued(t, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			// The first notification blocks the worker
+			if obj.(*corev1.Pod).Name == "blocker" {
+				<-release
+			}
+
+			record("add", obj)
+		},
+		UpdateFunc: func(_, newObj any) { record("update", newObj) },
+		DeleteFunc: func(obj any) { record("delete", obj) },
+	})
+
+	queued.OnAdd(pod("blocker", "1"), false)
+
+	// The worker is blocked, notifications return right away and are
+	// coalesced per object
+	done := make(chan struct{})
+	go func() {
+		queued.OnAdd(pod("a", "1"), false)
+		queued.OnUpdate(pod("a", "1"), pod("a", "2"))
+		queued.OnUpdate(pod("b", "1"), pod("b", "2"))
+		queued.OnUpdate(pod("b", "2"), pod("b", "3"))
+		queued.OnUpdate(pod("c", "1"), pod("c", "2"))
+		queued.OnDelete(pod("c", "2"))
+		queued.OnDelete(pod("d", "1"))
+		queued.OnAdd(pod("d", "2"), false)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected queued notifications not to block on a busy handler")
+	}
+
+	// Blocker is being handled, a, b, c and d wait
+	time.Sleep(20 * time.Millisecond)
+
+	if depth := b.RuntimeStats().QueueDepth; depth != 4 {
+		t.Errorf("Expected a queue depth of 4, got %d", depth)
+	}
+
+	close(release)
+
+	// SetReady waits for the queued notifications to be handled
+	b.SetReady()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []string{"add blocker@1", "add a@2", "update b@3", "delete c@2", "delete d@1", "add d@2"}
+	if len(handled) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, handled)
+	}
+
+	for i := range expected {
+		if handled[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, handled)
+		}
+	}
+
+	stats := b.RuntimeStats()
+	if stats.QueueDepth != 0 || stats.QueueWait < 10*time.Millisecond {
+		t.Errorf("Unexpected queue stats %+v", stats)
+	}
+
+	if stats.HandlerEventsByType[base.EventTypeAdd] != 3 || stats.HandlerEventsByType[base.EventTypeDelete] != 2 {
+		t.Errorf("Unexpected handler events by type %v", stats.HandlerEventsByType)
+	}
+}
+
+func TestEventQueueRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+
+	b, queued := startQueued(t, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(any) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			attempts++
+			if attempts == 1 {
+				panic("transient failure")
+			}
+		},
+	})
+
+	queued.OnAdd(pod("a", "1"), false)
+
+	b.SetReady()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if attempts != 2 {
+		t.Errorf("Expected the handler to be retried once, got %d attempts", attempts)
+	}
+
+	if retries := b.RuntimeStats().QueueRetries; retries != 1 {
+		t.Errorf("Expected 1 retry, got %d", retries)
+	}
+}
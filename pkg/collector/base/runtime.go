@@ -0,0 +1,258 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Lock modes reported in collector.RuntimeStats.LockWait
+const (
+	LockModeRead  = "read"
+	LockModeWrite = "write"
+)
+
+// Event types reported in collector.RuntimeStats.HandlerEventsByType
+const (
+	EventTypeAdd    = "add"
+	EventTypeUpdate = "update"
+	EventTypeDelete = "delete"
+)
+
+// runtimeStats holds the runtime counters of a collector
+type runtimeStats struct {
+	instrumented   atomic.Bool
+	addEvents      atomic.Uint64
+	updateEvents   atomic.Uint64
+	deleteEvents   atomic.Uint64
+	handlerPending atomic.Int64
+	handlerNanos   atomic.Int64
+	readWaitNanos  atomic.Int64
+	writeWaitNanos atomic.Int64
+
+	informerRestarts atomic.Uint64
+	watchErrors      atomic.Uint64
+
+	queueWaitNanos atomic.Int64
+	queueRetries   atomic.Uint64
+
+	// informers are the informers of the running collector whose cache
+	// size is reported, reset on every start
+	informersMu sync.Mutex
+	informers   []trackedInformer
+}
+
+// trackedInformer is an instrumented informer and the resource it caches
+type trackedInformer struct {
+	resource string
+	informer cache.SharedIndexInformer
+}
+
+// RuntimeStats returns a snapshot of the event handler and lock counters
+func (b *BaseCollector) RuntimeStats() collector.RuntimeStats {
+	events := map[string]uint64{
+		EventTypeAdd:    b.runtime.addEvents.Load(),
+		EventTypeUpdate: b.runtime.updateEvents.Load(),
+		EventTypeDelete: b.runtime.deleteEvents.Load(),
+	}
+
+	return collector.RuntimeStats{
+		Instrumented:        b.runtime.instrumented.Load(),
+		HandlerEvents:       events[EventTypeAdd] + events[EventTypeUpdate] + events[EventTypeDelete],
+		HandlerEventsByType: events,
+		HandlerPending:      b.runtime.handlerPending.Load(),
+		HandlerDuration:     time.Duration(b.runtime.handlerNanos.Load()),
+		LockWait: map[string]time.Duration{
+			LockModeRead:  time.Duration(b.runtime.readWaitNanos.Load()),
+			LockModeWrite: time.Duration(b.runtime.writeWaitNanos.Load()),
+		},
+		InformerRestarts: b.runtime.informerRestarts.Load(),
+		WatchErrors:      b.runtime.watchErrors.Load(),
+		CacheObjects:     b.runtime.cacheObjects(),
+		QueueDepth:       b.queueDepth(),
+		QueueWait:        time.Duration(b.runtime.queueWaitNanos.Load()),
+		QueueRetries:     b.runtime.queueRetries.Load(),
+	}
+}
+
+// cacheObjects returns the number of objects cached by the tracked
+// informers, per resource
+func (s *runtimeStats) cacheObjects() map[string]int {
+	s.informersMu.Lock()
+	defer s.informersMu.Unlock()
+
+	if len(s.informers) == 0 {
+		return nil
+	}
+
+	objects := make(map[string]int, len(s.informers))
+	for _, tracked := range s.informers {
+		objects[tracked.resource] += len(tracked.informer.GetStore().ListKeys())
+	}
+
+	return objects
+}
+
+// resetInformers forgets the tracked informers of a previous run
+func (s *runtimeStats) resetInformers() {
+	s.informersMu.Lock()
+	defer s.informersMu.Unlock()
+
+	s.informers = nil
+}
+
+// InstrumentInformer counts the watch errors of an informer and reports the
+// number of objects it caches for resource (e.g. pods). Informers caching the
+// same resource are summed. It must be called before the informer starts,
+// typically next to SetTransform.
+func (b *BaseCollector) InstrumentInformer(resource string, informer cache.SharedIndexInformer) {
+	stats := &b.runtime
+	stats.instrumented.Store(true)
+
+	// Closed and expired watches are part of the normal watch cycle
+	_ = informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *cache.Reflector, err error) {
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) &&
+			!apierrors.IsResourceExpired(err) && !apierrors.IsGone(err) {
+			stats.watchErrors.Add(1)
+		}
+
+		cache.DefaultWatchErrorHandler(ctx, r, err)
+	})
+
+	stats.informersMu.Lock()
+	defer stats.informersMu.Unlock()
+
+	stats.informers = append(stats.informers, trackedInformer{resource: resource, informer: informer})
+}
+
+// InstrumentHandler wraps informer event handlers to count the notifications
+// handled, those being handled and the time spent handling them. With an
+// event queue (see WithEventQueue), the notifications received while the
+// collector is started are handled by the queue workers.
+func (b *BaseCollector) InstrumentHandler(handler cache.ResourceEventHandlerFuncs) cache.ResourceEventHandlerFuncs {
+	detailed := cache.ResourceEventHandlerDetailedFuncs{
+		UpdateFunc: handler.UpdateFunc,
+		DeleteFunc: handler.DeleteFunc,
+	}
+
+	if add := handler.AddFunc; add != nil {
+		detailed.AddFunc = func(obj any, _ bool) { add(obj) }
+	}
+
+	detailed = b.InstrumentDetailedHandler(detailed)
+
+	instrumented := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: detailed.UpdateFunc,
+		DeleteFunc: detailed.DeleteFunc,
+	}
+
+	if add := detailed.AddFunc; add != nil {
+		instrumented.AddFunc = func(obj any) { add(obj, false) }
+	}
+
+	return instrumented
+}
+
+// InstrumentDetailedHandler is InstrumentHandler for handlers telling apart
+// the additions of the initial list, the flag is kept through the event queue
+func (b *BaseCollector) InstrumentDetailedHandler(
+	handler cache.ResourceEventHandlerDetailedFuncs,
+) cache.ResourceEventHandlerDetailedFuncs {
+	stats := &b.runtime
+	stats.instrumented.Store(true)
+
+	track := func(events *atomic.Uint64) func() {
+		stats.handlerPending.Add(1)
+		start := time.Now()
+
+		return func() {
+			stats.handlerNanos.Add(int64(time.Since(start)))
+			stats.handlerPending.Add(-1)
+			events.Add(1)
+		}
+	}
+
+	instrumented := cache.ResourceEventHandlerDetailedFuncs{}
+
+	if add := handler.AddFunc; add != nil {
+		instrumented.AddFunc = func(obj any, isInInitialList bool) {
+			defer track(&stats.addEvents)()
+			add(obj, isInInitialList)
+		}
+	}
+
+	if update := handler.UpdateFunc; update != nil {
+		instrumented.UpdateFunc = func(oldObj, newObj any) {
+			defer track(&stats.updateEvents)()
+			update(oldObj, newObj)
+		}
+	}
+
+	if del := handler.DeleteFunc; del != nil {
+		instrumented.DeleteFunc = func(obj any) {
+			defer track(&stats.deleteEvents)()
+			del(obj)
+		}
+	}
+
+	if queue := b.runningQueue(); queue != nil {
+		return queue.register(instrumented)
+	}
+
+	return instrumented
+}
+
+// RWMutex is a sync.RWMutex recording the time spent waiting for it in the
+// runtime stats of a collector. Uncontended locks are not timed. The zero
+// value is an uninstrumented mutex, see Instrument.
+type RWMutex struct {
+	sync.RWMutex
+
+	stats *runtimeStats
+}
+
+// Instrument records the lock wait time in the runtime stats of b.
+// It must be called before the mutex is used concurrently.
+func (m *RWMutex) Instrument(b *BaseCollector) {
+	m.stats = &b.runtime
+	m.stats.instrumented.Store(true)
+}
+
+// Lock locks m for writing
+func (m *RWMutex) Lock() {
+	if m.stats == nil {
+		m.RWMutex.Lock()
+		return
+	}
+
+	if m.TryLock() {
+		return
+	}
+
+	start := time.Now()
+	m.RWMutex.Lock()
+	m.stats.writeWaitNanos.Add(int64(time.Since(start)))
+}
+
+// RLock locks m for reading
+func (m *RWMutex) RLock() {
+	if m.stats == nil {
+		m.RWMutex.RLock()
+		return
+	}
+
+	if m.TryRLock() {
+		return
+	}
+
+	start := time.Now()
+	m.RWMutex.RLock()
+	m.stats.readWaitNanos.Add(int64(time.Since(start)))
+}
@@ -0,0 +1,98 @@
+package base_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRuntimeStats(t *testing.T) {
+	b := base.NewBaseCollector("test", log.NewEntry(log.StandardLogger()))
+
+	if b.RuntimeStats().Instrumented {
+		t.Error("Expected collector without instrumentation to report Instrumented=false")
+	}
+
+	var mu base.RWMutex
+	mu.Instrument(b)
+
+	handler := b.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(any) {
+			mu.Lock()
+			defer mu.Unlock()
+		},
+	})
+
+	if handler.UpdateFunc != nil || handler.DeleteFunc != nil {
+		t.Error("Expected unset handler funcs to stay unset")
+	}
+
+	mu.RLock()
+
+	done := make(chan struct{})
+	go func() {
+		handler.OnAdd(nil, false)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	if pending := b.RuntimeStats().HandlerPending; pending != 1 {
+		t.Errorf("Expected 1 pending notification, got %d", pending)
+	}
+
+	mu.RUnlock()
+	<-done
+
+	stats := b.RuntimeStats()
+	if !stats.Instrumented || stats.HandlerEvents != 1 || stats.HandlerPending != 0 {
+		t.Errorf("Unexpected handler stats %+v", stats)
+	}
+
+	if stats.HandlerEventsByType[base.EventTypeAdd] != 1 || stats.HandlerEventsByType[base.EventTypeUpdate] != 0 {
+		t.Errorf("Unexpected handler events by type %v", stats.HandlerEventsByType)
+	}
+
+	if stats.LockWait[base.LockModeWrite] < 10*time.Millisecond {
+		t.Errorf("Expected write lock wait of at least 10ms, got %s", stats.LockWait[base.LockModeWrite])
+	}
+}
+
+func TestInstrumentInformer(t *testing.T) {
+	b := base.NewBaseCollector("test", log.NewEntry(log.StandardLogger()))
+
+	if objects := b.RuntimeStats().CacheObjects; objects != nil {
+		t.Errorf("Expected no cache objects without informers, got %v", objects)
+	}
+
+	// Two informers caching pods (e.g. one per namespace) and one caching nodes
+	for i, names := range [][]string{{"a", "b"}, {"c"}} {
+		informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Pod{}, 0, cache.Indexers{})
+		b.InstrumentInformer("pods", informer)
+
+		for _, name := range names {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: fmt.Sprint("ns-", i)}}
+			if err := informer.GetStore().Add(pod); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	nodes := cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Node{}, 0, cache.Indexers{})
+	b.InstrumentInformer("nodes", nodes)
+
+	stats := b.RuntimeStats()
+	if !stats.Instrumented {
+		t.Error("Expected instrumented informers to report Instrumented=true")
+	}
+
+	if stats.CacheObjects["pods"] != 3 || stats.CacheObjects["nodes"] != 0 || len(stats.CacheObjects) != 2 {
+		t.Errorf("Unexpected cache objects %v", stats.CacheObjects)
+	}
+}
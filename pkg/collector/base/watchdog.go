@@ -0,0 +1,121 @@
+package base
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WithStaleWatchTimeout returns an option that restarts the collector when
+// none of its watched informers received an event or bookmark for timeout.
+// Zero disables the watchdog. See WatchInformers.
+func WithStaleWatchTimeout(timeout time.Duration) BaseCollectorOption {
+	return func(b *BaseCollector) {
+		b.staleWatchTimeout = timeout
+	}
+}
+
+// watchProgress tracks the last resource version synced by an informer
+type watchProgress struct {
+	version string
+	changed time.Time
+}
+
+// observe records the current resource version and reports whether it did
+// not change for longer than timeout
+func (p *watchProgress) observe(version string, now time.Time, timeout time.Duration) bool {
+	if version != p.version {
+		p.version = version
+		p.changed = now
+
+		return false
+	}
+
+	return now.Sub(p.changed) > timeout
+}
+
+// WatchInformers starts a watchdog restarting the collector when the watch of
+// one of the informers goes stale: its last synced resource version, advanced
+// by every event and by the periodic bookmarks of the API server, does not
+// change for the stale watch timeout (e.g. a watch left hanging by an API
+// server restart). It must be called from the start hook, once the informers
+// synced; the watchdog stops with the collector.
+func (b *BaseCollector) WatchInformers(informers ...cache.SharedIndexInformer) {
+	b.mu.RLock()
+	ctx := b.ctx
+	timeout := b.staleWatchTimeout
+	b.mu.RUnlock()
+
+	if timeout <= 0 || ctx == nil || len(informers) == 0 {
+		return
+	}
+
+	go b.watchStaleness(ctx, informers, timeout)
+}
+
+// watchStaleness checks the progress of the informers until ctx is done, and
+// restarts the collector on the first stale watch
+func (b *BaseCollector) watchStaleness(ctx context.Context, informers []cache.SharedIndexInformer, timeout time.Duration) {
+	now := time.Now()
+
+	progress := make([]watchProgress, len(informers))
+	for i, informer := range informers {
+		progress[i] = watchProgress{version: informer.LastSyncResourceVersion(), changed: now}
+	}
+
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for i, informer := range informers {
+				if !progress[i].observe(informer.LastSyncResourceVersion(), now, timeout) {
+					continue
+				}
+
+				b.logger.WithFields(log.Fields{
+					"name":            b.name,
+					"resourceVersion": progress[i].version,
+					"since":           progress[i].changed,
+				}).Warn("Informer watch is stale, restarting collector")
+
+				b.restartStale(timeout / 4)
+
+				return
+			}
+		}
+	}
+}
+
+// restartStale stops and starts the collector again, retrying every interval
+// until it starts or the context it was started with is done
+func (b *BaseCollector) restartStale(interval time.Duration) {
+	b.mu.RLock()
+	parent := b.parentCtx
+	b.mu.RUnlock()
+
+	// The collector was stopped meanwhile (e.g. leadership lost)
+	if err := b.Stop(); err != nil {
+		return
+	}
+
+	for parent.Err() == nil {
+		err := b.Start(parent)
+		if err == nil {
+			b.runtime.informerRestarts.Add(1)
+			return
+		}
+
+		b.logger.WithError(err).WithField("name", b.name).Error("Failed to restart collector, retrying")
+
+		select {
+		case <-parent.Done():
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package base_test
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeInformer reports a fixed last synced resource version
+type fakeInformer struct {
+	cache.SharedIndexInformer
+
+	version atomic.Value
+}
+
+func (f *fakeInformer) LastSyncResourceVersion() string {
+	version, _ := f.version.Load().(string)
+	return version
+}
+
+func TestWatchInformersRestartsStaleCollector(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress bool // whether the informer resource version keeps advancing
+		restarts uint64
+	}{
+		{name: "stale watch", restarts: 1},
+		{name: "advancing watch", progress: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			informer := &fakeInformer{}
+			informer.version.Store("1")
+
+			b := base.NewBaseCollector(
+				"test",
+				log.NewEntry(log.StandardLogger()),
+				base.WithStaleWatchTimeout(100*time.Millisecond),
+			)
+
+			var starts atomic.Int32
+
+			b.SetLifecycle(base.LifecycleFuncs{
+				StartFunc: func(context.Context) error {
+					starts.Add(1)
+					b.WatchInformers(informer)
+
+					return nil
+				},
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if err := b.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			// Wait for the first restart, or past the timeout when the watch advances
+			for i := range 20 {
+				time.Sleep(20 * time.Millisecond)
+
+				if tt.progress {
+					informer.version.Store(strconv.Itoa(i + 2))
+				} else if b.RuntimeStats().InformerRestarts > 0 {
+					break
+				}
+			}
+
+			if got := b.RuntimeStats().InformerRestarts; got != tt.restarts {
+				t.Errorf("Expected %d restarts, got %d", tt.restarts, got)
+			}
+
+			if !b.IsStarted() || starts.Load() != int32(tt.restarts)+1 {
+				t.Errorf("Expected the collector to be started %d times, got %d", tt.restarts+1, starts.Load())
+			}
+
+			if err := b.Stop(); err != nil {
+				t.Errorf("Stop() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package cert
+
+import (
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// certificate is the parsed certificate chain of a TLS secret
+type certificate struct {
+	namespace  string
+	secret     string
+	commonName string
+	sans       []string
+	issuer     string
+	// fingerprint is the SHA-256 fingerprint of the leaf
+	fingerprint string
+	notBefore   time.Time
+	notAfter    time.Time
+	// chainNotAfter is the earliest expiry across the stored chain
+	chainNotAfter time.Time
+	// parseError is set when tls.crt is missing or cannot be parsed
+	parseError string
+}
+
+// Collector collects TLS secret certificate metrics
+type Collector struct {
+	*base.BaseCollector
+
+	client    kubernetes.Interface
+	config    *Config
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	// collectors returns the running collectors, to correlate the secrets
+	// with the certificates served for Ingress hosts (may be nil)
+	collectors func() map[string]collector.Collector
+
+	mu        base.RWMutex
+	certs     map[string]*certificate // key: namespace/secret
+	consumers map[string]*consumer    // key: namespace/pod, only tracked with TrackConsumers
+
+	// Metrics
+	certExpiry         *prometheus.Desc
+	certChainExpiry    *prometheus.Desc
+	certParseError     *prometheus.Desc
+	certConsumers      *prometheus.Desc
+	certConsumerInfo   *prometheus.Desc
+	certServedMismatch *prometheus.Desc
+
+	certFingerprintSecrets     *prometheus.Desc
+	certFingerprintExpiry      *prometheus.Desc
+	certFingerprintChainExpiry *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.certExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "expiry_timestamp_seconds"),
+		"Expiry (notAfter) of the certificate stored in a TLS secret, as a Unix timestamp",
+		[]string{"namespace", "secret", "common_name", "issuer"},
+		nil,
+	)
+	c.certChainExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "chain_expiry_timestamp_seconds"),
+		"Earliest expiry (notAfter) across the certificate chain stored in a TLS secret, "+
+			"intermediates included, as a Unix timestamp",
+		[]string{"namespace", "secret"},
+		nil,
+	)
+	c.certParseError = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "parse_error"),
+		"TLS secrets whose certificate is missing or cannot be parsed, with the redacted error (always 1)",
+		[]string{"namespace", "secret", "error", "error_hash"},
+		nil,
+	)
+	c.certConsumers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "consumers"),
+		"Number of non-terminated pods mounting a TLS secret",
+		[]string{"namespace", "secret"},
+		nil,
+	)
+	c.certConsumerInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "consumer_info"),
+		"Workloads mounting a TLS secret through a secret, projected or CSI volume (always 1)",
+		[]string{"namespace", "secret", "workload_kind", "workload", "volume_type"},
+		nil,
+	)
+	c.certServedMismatch = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "served_mismatch"),
+		"Whether an IP of an Ingress host serves a certificate expiring before the one stored in the "+
+			"referenced TLS secret (1), i.e. the gateway has not reloaded the renewed secret, or not (0)",
+		[]string{"namespace", "secret", "domain", "ip"},
+		nil,
+	)
+
+	c.certFingerprintSecrets = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "fingerprint_secrets"),
+		"Number of TLS secrets storing the same leaf certificate, for certificates stored in several secrets",
+		[]string{"fingerprint", "common_name", "issuer"},
+		nil,
+	)
+	c.certFingerprintExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "fingerprint_expiry_timestamp_seconds"),
+		"Expiry (notAfter) of a distinct certificate stored in TLS secrets, as a Unix timestamp",
+		[]string{"fingerprint", "common_name", "issuer"},
+		nil,
+	)
+	c.certFingerprintChainExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cert", "fingerprint_chain_expiry_timestamp_seconds"),
+		"Earliest expiry (notAfter) across the certificate chains stored along a distinct certificate, "+
+			"as a Unix timestamp",
+		[]string{"fingerprint"},
+		nil,
+	)
+
+	// Register descriptors
+	if c.config.CollapseByFingerprint {
+		c.MustRegisterDesc(c.certFingerprintExpiry)
+		c.MustRegisterDesc(c.certFingerprintChainExpiry)
+	} else {
+		c.MustRegisterDesc(c.certExpiry)
+		c.MustRegisterDesc(c.certChainExpiry)
+	}
+
+	c.MustRegisterDesc(c.certParseError)
+	c.MustRegisterDesc(c.certFingerprintSecrets)
+	c.MustRegisterDesc(c.certServedMismatch)
+
+	if c.config.TrackConsumers {
+		c.MustRegisterDesc(c.certConsumers)
+		c.MustRegisterDesc(c.certConsumerInfo)
+	}
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// trimSecret reduces memory by keeping only the certificate of a TLS secret.
+// The private key is dropped before the secret reaches the informer cache.
+func trimSecret(obj any) (any, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return obj, nil
+	}
+
+	transformed := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       secret.Namespace,
+			Name:            secret.Name,
+			UID:             secret.UID,
+			ResourceVersion: secret.ResourceVersion,
+		},
+		Type: secret.Type,
+	}
+
+	if crt, ok := secret.Data[corev1.TLSCertKey]; ok {
+		transformed.Data = map[string][]byte{corev1.TLSCertKey: crt}
+	}
+
+	return transformed, nil
+}
+
+// handleSecret parses and records the certificate of a TLS secret
+func (c *Collector) handleSecret(obj any) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Secret")
+		return
+	}
+
+	cert := &certificate{
+		namespace: secret.Namespace,
+		secret:    secret.Name,
+	}
+
+	info, err := util.ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		cert.parseError = err.Error()
+
+		c.logger.WithError(err).WithFields(log.Fields{
+			"namespace":  secret.Namespace,
+			"secret":     secret.Name,
+			"error_hash": util.ErrorHash(cert.parseError),
+		}).Debug("Failed to parse TLS secret certificate")
+	} else {
+		cert.commonName = info.CommonName
+		cert.sans = info.SANs
+		cert.issuer = info.Issuer
+		cert.fingerprint = info.Fingerprint
+		cert.notBefore = info.NotBefore
+		cert.notAfter = info.NotAfter
+		cert.chainNotAfter = info.ChainNotAfter
+	}
+
+	c.mu.Lock()
+	c.certs[objectKey(secret.Namespace, secret.Name)] = cert
+	c.mu.Unlock()
+}
+
+// handleSecretDelete removes a tracked TLS secret
+func (c *Collector) handleSecretDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		secret, ok = tombstone.Obj.(*corev1.Secret)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a Secret")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.certs, objectKey(secret.Namespace, secret.Name))
+	c.mu.Unlock()
+}
+
+// Certificates returns the certificates of the tracked TLS secrets (served by
+// /api/v1/certs)
+func (c *Collector) Certificates() []collector.Certificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	certs := make([]collector.Certificate, 0, len(c.certs))
+
+	for _, cert := range c.certs {
+		certs = append(certs, collector.Certificate{
+			Namespace:   cert.namespace,
+			Secret:      cert.secret,
+			CommonName:  cert.commonName,
+			SANs:        cert.sans,
+			Issuer:      cert.issuer,
+			NotBefore:   cert.notBefore,
+			NotAfter:    cert.notAfter,
+			Fingerprint: cert.fingerprint,
+			Error:       cert.parseError,
+		})
+	}
+
+	return certs
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	// Queried before locking, the other collectors take their own locks
+	served := c.servedCertificates()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, cert := range c.certs {
+		if cert.parseError != "" {
+			errorLabel, errorHash := util.SanitizeErrorLabel(cert.parseError)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.certParseError,
+				prometheus.GaugeValue,
+				1,
+				cert.namespace,
+				cert.secret,
+				errorLabel,
+				errorHash,
+			)
+
+			continue
+		}
+
+		if c.config.CollapseByFingerprint {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.certExpiry,
+			prometheus.GaugeValue,
+			float64(cert.notAfter.Unix()),
+			cert.namespace,
+			cert.secret,
+			cert.commonName,
+			cert.issuer,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.certChainExpiry,
+			prometheus.GaugeValue,
+			float64(cert.chainNotAfter.Unix()),
+			cert.namespace,
+			cert.secret,
+		)
+	}
+
+	c.collectFingerprints(ch)
+	c.collectServed(ch, served)
+
+	if c.config.TrackConsumers {
+		c.collectConsumers(ch)
+	}
+}
+
+// objectKey generates a unique key for a namespaced object
+func objectKey(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,25 @@
+package cert
+
+// Config contains configuration for the Cert collector
+type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces"              env:"NAMESPACES"                envSeparator:","`
+	// IncludeSystemNamespaces watches TLS secrets in system namespaces (kube-system, sealos-system, ...)
+	// when Namespaces is empty; they are excluded by default
+	IncludeSystemNamespaces bool `yaml:"includeSystemNamespaces" env:"INCLUDE_SYSTEM_NAMESPACES"`
+	// TrackConsumers watches pods and correlates their secret, projected and CSI
+	// volumes with TLS secrets, to report the workloads affected by an expiring certificate
+	TrackConsumers bool `yaml:"trackConsumers"          env:"TRACK_CONSUMERS"`
+	// CollapseByFingerprint exports the expiry once per distinct certificate
+	// instead of once per secret, for clusters copying wildcard certificates
+	// to many namespaces
+	CollapseByFingerprint bool `yaml:"collapseByFingerprint"   env:"COLLAPSE_BY_FINGERPRINT"`
+}
+
+// NewDefaultConfig returns the default configuration for Cert collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces: []string{},
+	}
+}
@@ -0,0 +1,221 @@
+package cert
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Volume types through which a pod consumes a secret
+const (
+	volumeTypeSecret    = "secret"
+	volumeTypeProjected = "projected"
+	volumeTypeCSI       = "csi"
+)
+
+// secretRef is a secret mounted by a pod
+type secretRef struct {
+	secret     string
+	volumeType string
+}
+
+// consumer is a running pod mounting secrets
+type consumer struct {
+	namespace    string
+	workloadKind string
+	workload     string
+	refs         []secretRef
+}
+
+// consumerKey identifies a cert_consumer_info series
+type consumerKey struct {
+	secret       string
+	workloadKind string
+	workload     string
+	volumeType   string
+}
+
+// secretRefs returns the secrets referenced by the volumes of a pod.
+// CSI volumes reference a secret through nodePublishSecretRef
+// (e.g. the secrets-store CSI driver credentials).
+func secretRefs(volumes []corev1.Volume) []secretRef {
+	var refs []secretRef
+
+	for i := range volumes {
+		volume := &volumes[i]
+
+		switch {
+		case volume.Secret != nil:
+			refs = append(refs, secretRef{secret: volume.Secret.SecretName, volumeType: volumeTypeSecret})
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					refs = append(refs, secretRef{secret: source.Secret.Name, volumeType: volumeTypeProjected})
+				}
+			}
+		case volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil:
+			refs = append(refs, secretRef{
+				secret:     volume.CSI.NodePublishSecretRef.Name,
+				volumeType: volumeTypeCSI,
+			})
+		}
+	}
+
+	return refs
+}
+
+// trimPod reduces memory by keeping only the fields needed to correlate
+// pods with the secrets they mount
+func trimPod(obj any) (any, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+
+	transformed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       pod.Namespace,
+			Name:            pod.Name,
+			UID:             pod.UID,
+			OwnerReferences: pod.OwnerReferences,
+		},
+		Status: corev1.PodStatus{
+			Phase: pod.Status.Phase,
+		},
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil || volume.Projected != nil || volume.CSI != nil {
+			transformed.Spec.Volumes = append(transformed.Spec.Volumes, volume)
+		}
+	}
+
+	// Only keep the label needed to resolve Deployments from ReplicaSets
+	if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		transformed.Labels = map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash}
+	}
+
+	return transformed, nil
+}
+
+// handlePod records the secrets mounted by a running pod
+func (c *Collector) handlePod(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Pod")
+		return
+	}
+
+	key := objectKey(pod.Namespace, pod.Name)
+
+	refs := secretRefs(pod.Spec.Volumes)
+	if len(refs) == 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		c.mu.Lock()
+		delete(c.consumers, key)
+		c.mu.Unlock()
+
+		return
+	}
+
+	kind, name := util.WorkloadOf(pod)
+	if kind == "" {
+		kind, name = "Pod", pod.Name
+	}
+
+	c.mu.Lock()
+	c.consumers[key] = &consumer{
+		namespace:    pod.Namespace,
+		workloadKind: kind,
+		workload:     name,
+		refs:         refs,
+	}
+	c.mu.Unlock()
+}
+
+// handlePodDelete removes a tracked pod
+func (c *Collector) handlePodDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		pod, ok = tombstone.Obj.(*corev1.Pod)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a Pod")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.consumers, objectKey(pod.Namespace, pod.Name))
+	c.mu.Unlock()
+}
+
+// collectConsumers emits the consumers of every known TLS secret.
+// Secrets that are not TLS secrets (or are excluded) are ignored.
+// Must be called with c.mu held.
+func (c *Collector) collectConsumers(ch chan<- prometheus.Metric) {
+	counts := make(map[string]float64)
+	infos := make(map[string]map[consumerKey]struct{})
+
+	for _, consumer := range c.consumers {
+		// A pod mounting the same secret twice counts once
+		counted := make(map[string]struct{}, len(consumer.refs))
+
+		for _, ref := range consumer.refs {
+			key := objectKey(consumer.namespace, ref.secret)
+			if _, ok := c.certs[key]; !ok {
+				continue
+			}
+
+			if _, ok := counted[key]; !ok {
+				counted[key] = struct{}{}
+				counts[key]++
+			}
+
+			if infos[key] == nil {
+				infos[key] = make(map[consumerKey]struct{})
+			}
+
+			infos[key][consumerKey{
+				secret:       ref.secret,
+				workloadKind: consumer.workloadKind,
+				workload:     consumer.workload,
+				volumeType:   ref.volumeType,
+			}] = struct{}{}
+		}
+	}
+
+	for key, count := range counts {
+		cert := c.certs[key]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.certConsumers,
+			prometheus.GaugeValue,
+			count,
+			cert.namespace,
+			cert.secret,
+		)
+
+		for info := range infos[key] {
+			ch <- prometheus.MustNewConstMetric(
+				c.certConsumerInfo,
+				prometheus.GaugeValue,
+				1,
+				cert.namespace,
+				info.secret,
+				info.workloadKind,
+				info.workload,
+				info.volumeType,
+			)
+		}
+	}
+}
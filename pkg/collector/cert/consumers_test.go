@@ -0,0 +1,142 @@
+//nolint:testpackage // Tests need access to private functions secretRefs and collectConsumers
+package cert
+
+import (
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSecretRefs(t *testing.T) {
+	volumes := []corev1.Volume{
+		{Name: "tls", VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: "web-tls"},
+		}},
+		{Name: "bundle", VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "ca-tls"},
+				}},
+				{ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "config"},
+				}},
+			}},
+		}},
+		{Name: "store", VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:               "secrets-store.csi.k8s.io",
+				NodePublishSecretRef: &corev1.LocalObjectReference{Name: "store-creds"},
+			},
+		}},
+		{Name: "data", VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}},
+	}
+
+	expected := []secretRef{
+		{secret: "web-tls", volumeType: volumeTypeSecret},
+		{secret: "ca-tls", volumeType: volumeTypeProjected},
+		{secret: "store-creds", volumeType: volumeTypeCSI},
+	}
+
+	refs := secretRefs(volumes)
+	if len(refs) != len(expected) {
+		t.Fatalf("Expected %d refs, got %v", len(expected), refs)
+	}
+
+	for i := range expected {
+		if refs[i] != expected[i] {
+			t.Errorf("Expected ref %d to be %v, got %v", i, expected[i], refs[i])
+		}
+	}
+}
+
+func TestCollectConsumers(t *testing.T) {
+	logger := log.NewEntry(log.StandardLogger())
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        &Config{TrackConsumers: true},
+		certs:         make(map[string]*certificate),
+		consumers:     make(map[string]*consumer),
+		logger:        logger,
+	}
+	c.initMetrics("sealos")
+
+	c.certs[objectKey("ns-a", "web-tls")] = &certificate{namespace: "ns-a", secret: "web-tls"}
+
+	controller := true
+	pod := func(name string, volumes ...corev1.Volume) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns-a",
+				Name:      name,
+				Labels:    map[string]string{"pod-template-hash": "5d8f7c9b4"},
+				OwnerReferences: []metav1.OwnerReference{{
+					Kind:       "ReplicaSet",
+					Name:       "web-5d8f7c9b4",
+					Controller: &controller,
+				}},
+			},
+			Spec:   corev1.PodSpec{Volumes: volumes},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	tlsVolume := corev1.Volume{Name: "tls", VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: "web-tls"},
+	}}
+	otherVolume := corev1.Volume{Name: "other", VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: "db-password"},
+	}}
+
+	c.handlePod(pod("web-1", tlsVolume, otherVolume))
+	c.handlePod(pod("web-2", tlsVolume))
+
+	finished := pod("web-3", tlsVolume)
+	finished.Status.Phase = corev1.PodSucceeded
+	c.handlePod(finished)
+
+	ch := make(chan prometheus.Metric, 10)
+	c.collectConsumers(ch)
+	close(ch)
+
+	var count float64
+
+	infos := 0
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		switch metric.Desc() {
+		case c.certConsumers:
+			count = m.GetGauge().GetValue()
+		case c.certConsumerInfo:
+			infos++
+
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["workload_kind"] != "Deployment" || labels["workload"] != "web" {
+				t.Errorf("Expected Deployment/web, got %s/%s", labels["workload_kind"], labels["workload"])
+			}
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 consumers, got %v", count)
+	}
+
+	if infos != 1 {
+		t.Errorf("Expected 1 consumer info series, got %d", infos)
+	}
+}
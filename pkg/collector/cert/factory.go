@@ -0,0 +1,184 @@
+package cert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "cert"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("TLS secret certificate expiry and the workloads mounting them"),
+		registry.WithRBAC([]string{""}, []string{"secrets"}, []string{"list", "watch"}),
+		registry.WithRBAC([]string{""}, []string{"pods"}, []string{"list", "watch"}),
+	)
+}
+
+// NewCollector creates a new Cert collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.cert", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load cert collector config, using defaults")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client:    client,
+		config:    cfg,
+		certs:     make(map[string]*certificate),
+		consumers: make(map[string]*consumer),
+		stopCh:    make(chan struct{}),
+		logger:    factoryCtx.Logger,
+
+		collectors: factoryCtx.Collectors,
+	}
+
+	c.mu.Instrument(c.BaseCollector)
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and state to support restart
+			c.stopCh = make(chan struct{})
+			c.informers = nil
+
+			c.mu.Lock()
+			c.certs = make(map[string]*certificate)
+			c.consumers = make(map[string]*consumer)
+			c.mu.Unlock()
+
+			excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+			exclusion := util.NamespaceExclusionSelector(excluded)
+
+			// Only TLS secrets are sent by the API server, so other secrets are never cached
+			secretSelector := fields.OneTermEqualSelector("type", string(corev1.SecretTypeTLS))
+			if exclusion != nil {
+				secretSelector = fields.AndSelectors(secretSelector, exclusion)
+			}
+
+			factories := util.NewInformerFactories(
+				c.client,
+				factoryCtx.InformerResyncPeriod,
+				c.config.Namespaces,
+				informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+					options.FieldSelector = secretSelector.String()
+				}),
+			)
+
+			for _, factory := range factories {
+				informer := factory.Core().V1().Secrets().Informer()
+
+				// Apply transform to reduce memory usage
+				// Only keep the certificate, never the private key
+				_ = informer.SetTransform(trimSecret)
+
+				c.InstrumentInformer("secrets", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleSecret,
+					UpdateFunc: func(_, newObj any) { c.handleSecret(newObj) },
+					DeleteFunc: c.handleSecretDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			// Pods are watched by separate factories, since the TLS field selector
+			// of the secret watch does not apply to them
+			if c.config.TrackConsumers {
+				var opts []informers.SharedInformerOption
+				if exclusion != nil {
+					opts = append(opts, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+						options.FieldSelector = exclusion.String()
+					}))
+				}
+
+				podFactories := util.NewInformerFactories(
+					c.client,
+					factoryCtx.InformerResyncPeriod,
+					c.config.Namespaces,
+					opts...,
+				)
+
+				for _, factory := range podFactories {
+					informer := factory.Core().V1().Pods().Informer()
+					_ = informer.SetTransform(trimPod)
+
+					c.InstrumentInformer("pods", informer)
+
+					//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+					informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+						AddFunc:    c.handlePod,
+						UpdateFunc: func(_, newObj any) { c.handlePod(newObj) },
+						DeleteFunc: c.handlePodDelete,
+					}))
+
+					c.informers = append(c.informers, informer)
+				}
+
+				factories = append(factories, podFactories...)
+			}
+
+			// Start informers
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			// Wait for cache sync
+			c.logger.Info("Waiting for cert informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				return errors.New("failed to sync cert informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			c.logger.Info("Cert collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
@@ -0,0 +1,86 @@
+package cert
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fingerprintGroup is a certificate stored in one or more TLS secrets
+type fingerprintGroup struct {
+	commonName string
+	issuer     string
+	notAfter   time.Time
+	// chainNotAfter is the earliest chain expiry across the secrets, which
+	// may store different intermediates along the same leaf
+	chainNotAfter time.Time
+	secrets       int
+}
+
+// groupByFingerprint groups the parsed certificates by leaf fingerprint
+func groupByFingerprint(certs map[string]*certificate) map[string]*fingerprintGroup {
+	groups := make(map[string]*fingerprintGroup)
+
+	for _, cert := range certs {
+		if cert.parseError != "" {
+			continue
+		}
+
+		group, ok := groups[cert.fingerprint]
+		if !ok {
+			group = &fingerprintGroup{
+				commonName:    cert.commonName,
+				issuer:        cert.issuer,
+				notAfter:      cert.notAfter,
+				chainNotAfter: cert.chainNotAfter,
+			}
+			groups[cert.fingerprint] = group
+		}
+
+		if cert.chainNotAfter.Before(group.chainNotAfter) {
+			group.chainNotAfter = cert.chainNotAfter
+		}
+
+		group.secrets++
+	}
+
+	return groups
+}
+
+// collectFingerprints emits the number of secrets of the certificates stored
+// in several secrets and, with CollapseByFingerprint, the expiry of each
+// distinct certificate.
+// Must be called with c.mu held.
+func (c *Collector) collectFingerprints(ch chan<- prometheus.Metric) {
+	for fingerprint, group := range groupByFingerprint(c.certs) {
+		if group.secrets > 1 {
+			ch <- prometheus.MustNewConstMetric(
+				c.certFingerprintSecrets,
+				prometheus.GaugeValue,
+				float64(group.secrets),
+				fingerprint,
+				group.commonName,
+				group.issuer,
+			)
+		}
+
+		if !c.config.CollapseByFingerprint {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.certFingerprintExpiry,
+			prometheus.GaugeValue,
+			float64(group.notAfter.Unix()),
+			fingerprint,
+			group.commonName,
+			group.issuer,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.certFingerprintChainExpiry,
+			prometheus.GaugeValue,
+			float64(group.chainNotAfter.Unix()),
+			fingerprint,
+		)
+	}
+}
@@ -0,0 +1,97 @@
+//nolint:testpackage // Tests need access to private function collectFingerprints
+package cert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestCollectFingerprints(t *testing.T) {
+	logger := log.NewEntry(log.StandardLogger())
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        &Config{CollapseByFingerprint: true},
+		certs:         make(map[string]*certificate),
+		logger:        logger,
+	}
+	c.initMetrics("sealos")
+
+	notAfter := time.Unix(2000, 0)
+	wildcard := func(namespace string, chainNotAfter int64) *certificate {
+		return &certificate{
+			namespace:     namespace,
+			secret:        "wildcard-tls",
+			commonName:    "*.example.com",
+			issuer:        "R3",
+			fingerprint:   "aa",
+			notAfter:      notAfter,
+			chainNotAfter: time.Unix(chainNotAfter, 0),
+		}
+	}
+
+	c.certs[objectKey("ns-a", "wildcard-tls")] = wildcard("ns-a", 2000)
+	c.certs[objectKey("ns-b", "wildcard-tls")] = wildcard("ns-b", 1500)
+	c.certs[objectKey("ns-c", "wildcard-tls")] = wildcard("ns-c", 2000)
+	c.certs[objectKey("ns-a", "api-tls")] = &certificate{
+		namespace:     "ns-a",
+		secret:        "api-tls",
+		commonName:    "api.example.com",
+		fingerprint:   "bb",
+		notAfter:      notAfter,
+		chainNotAfter: notAfter,
+	}
+	c.certs[objectKey("ns-a", "broken-tls")] = &certificate{
+		namespace:  "ns-a",
+		secret:     "broken-tls",
+		parseError: "failed to decode PEM block",
+	}
+
+	ch := make(chan prometheus.Metric, 20)
+	c.collect(ch)
+	close(ch)
+
+	series := make(map[*prometheus.Desc]map[string]float64)
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		labels := make(map[string]string)
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if series[metric.Desc()] == nil {
+			series[metric.Desc()] = make(map[string]float64)
+		}
+
+		series[metric.Desc()][labels["fingerprint"]] = m.GetGauge().GetValue()
+	}
+
+	if len(series[c.certExpiry]) != 0 || len(series[c.certChainExpiry]) != 0 {
+		t.Error("Expected no per-secret expiry series when collapsed")
+	}
+
+	if secrets := series[c.certFingerprintSecrets]; len(secrets) != 1 || secrets["aa"] != 3 {
+		t.Errorf("Expected 3 secrets for the shared certificate only, got %v", secrets)
+	}
+
+	if expiry := series[c.certFingerprintExpiry]; len(expiry) != 2 || expiry["aa"] != 2000 {
+		t.Errorf("Expected one expiry series per certificate, got %v", expiry)
+	}
+
+	if chain := series[c.certFingerprintChainExpiry]; chain["aa"] != 1500 {
+		t.Errorf("Expected the earliest chain expiry, got %v", chain["aa"])
+	}
+
+	if len(series[c.certParseError]) != 1 {
+		t.Error("Expected parse errors to be reported per secret")
+	}
+}
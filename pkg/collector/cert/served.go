@@ -0,0 +1,64 @@
+package cert
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// servedCertificates returns the certificates served for Ingress hosts, as
+// reported by the running collectors checking them (the domain collector
+// with discoverIngresses)
+func (c *Collector) servedCertificates() []collector.ServedCertificate {
+	if c.collectors == nil {
+		return nil
+	}
+
+	var served []collector.ServedCertificate
+
+	for _, other := range c.collectors() {
+		if reporter, ok := other.(collector.ServedCertificateReporter); ok {
+			served = append(served, reporter.ServedCertificates()...)
+		}
+	}
+
+	return served
+}
+
+// collectServed emits whether each IP of an Ingress host serves a certificate
+// expiring before the one of the referenced secret. Once cert-manager renews
+// a secret, a gateway that has not reloaded it keeps serving the previous
+// certificate until it expires. Secrets not tracked or not parsed are skipped.
+// Must be called with c.mu held.
+func (c *Collector) collectServed(ch chan<- prometheus.Metric, served []collector.ServedCertificate) {
+	// Several domain collector instances may check the same host
+	seen := make(map[collector.ServedCertificate]bool, len(served))
+
+	for _, s := range served {
+		cert, ok := c.certs[objectKey(s.Namespace, s.Secret)]
+		if !ok || cert.parseError != "" {
+			continue
+		}
+
+		key := collector.ServedCertificate{Namespace: s.Namespace, Secret: s.Secret, Domain: s.Domain, IP: s.IP}
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		mismatch := 0.0
+		if s.NotAfter.Before(cert.notAfter) {
+			mismatch = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.certServedMismatch,
+			prometheus.GaugeValue,
+			mismatch,
+			s.Namespace,
+			s.Secret,
+			s.Domain,
+			s.IP,
+		)
+	}
+}
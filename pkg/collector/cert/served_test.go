@@ -0,0 +1,103 @@
+//nolint:testpackage // Tests need access to private function collectServed
+package cert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// servedReporter is a collector reporting fixed served certificates
+type servedReporter struct {
+	*base.BaseCollector
+
+	served []collector.ServedCertificate
+}
+
+func (r *servedReporter) ServedCertificates() []collector.ServedCertificate {
+	return r.served
+}
+
+func TestCollectServed(t *testing.T) {
+	logger := log.NewEntry(log.StandardLogger())
+
+	renewed := time.Unix(3000, 0)
+	previous := time.Unix(2000, 0)
+
+	reporter := &servedReporter{
+		BaseCollector: base.NewBaseCollector("domain", logger),
+		served: []collector.ServedCertificate{
+			{Domain: "app.example.com", IP: "10.0.0.1", Namespace: "ns-a", Secret: "app-tls", NotAfter: renewed},
+			{Domain: "app.example.com", IP: "10.0.0.2", Namespace: "ns-a", Secret: "app-tls", NotAfter: previous},
+			{Domain: "api.example.com", IP: "10.0.0.1", Namespace: "ns-a", Secret: "broken-tls", NotAfter: previous},
+			{Domain: "old.example.com", IP: "10.0.0.1", Namespace: "ns-a", Secret: "missing-tls", NotAfter: previous},
+		},
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        &Config{},
+		certs:         make(map[string]*certificate),
+		logger:        logger,
+		collectors: func() map[string]collector.Collector {
+			// Two instances checking the same hosts are reported once
+			return map[string]collector.Collector{"domain": reporter, "domain-2": reporter}
+		},
+	}
+	c.initMetrics("sealos")
+
+	c.certs[objectKey("ns-a", "app-tls")] = &certificate{
+		namespace:     "ns-a",
+		secret:        "app-tls",
+		notAfter:      renewed,
+		chainNotAfter: renewed,
+	}
+	c.certs[objectKey("ns-a", "broken-tls")] = &certificate{
+		namespace:  "ns-a",
+		secret:     "broken-tls",
+		parseError: "failed to decode PEM block",
+	}
+
+	ch := make(chan prometheus.Metric, 20)
+	c.collect(ch)
+	close(ch)
+
+	mismatch := make(map[string]float64)
+
+	for metric := range ch {
+		if metric.Desc() != c.certServedMismatch {
+			continue
+		}
+
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		labels := make(map[string]string)
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		mismatch[labels["secret"]+"/"+labels["domain"]+"/"+labels["ip"]] = m.GetGauge().GetValue()
+	}
+
+	expected := map[string]float64{
+		"app-tls/app.example.com/10.0.0.1": 0,
+		"app-tls/app.example.com/10.0.0.2": 1,
+	}
+	if len(mismatch) != len(expected) {
+		t.Fatalf("Expected series %v, got %v", expected, mismatch)
+	}
+
+	for key, value := range expected {
+		if got, ok := mismatch[key]; !ok || got != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -18,6 +19,28 @@ type ClientProvider interface {
 	GetClient() (kubernetes.Interface, error)
 }
 
+// ErrStandalone is returned by the client provider in standalone mode
+var ErrStandalone = errors.New("kubernetes is not available in standalone mode")
+
+// standaloneClientProvider implements ClientProvider without Kubernetes
+type standaloneClientProvider struct{}
+
+// NewStandaloneClientProvider creates a ClientProvider for standalone mode,
+// which never initializes a Kubernetes client and always returns ErrStandalone
+func NewStandaloneClientProvider() ClientProvider {
+	return standaloneClientProvider{}
+}
+
+// GetRestConfig returns ErrStandalone
+func (standaloneClientProvider) GetRestConfig() (*rest.Config, error) {
+	return nil, ErrStandalone
+}
+
+// GetClient returns ErrStandalone
+func (standaloneClientProvider) GetClient() (kubernetes.Interface, error) {
+	return nil, ErrStandalone
+}
+
 // lazyClientProvider implements ClientProvider with lazy initialization using sync.Once
 type lazyClientProvider struct {
 	config     ClientConfig
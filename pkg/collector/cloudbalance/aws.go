@@ -0,0 +1,235 @@
+package cloudbalance
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// awsDefaultRegion is the region of the Cost Explorer endpoint, which is
+	// global and only served from us-east-1 (cn-northwest-1 in China)
+	awsDefaultRegion = "us-east-1"
+	awsChinaRegion   = "cn-northwest-1"
+
+	awsCostExplorerService = "ce"
+	awsCostExplorerTarget  = "AWSInsightsIndexService.GetCostAndUsage"
+
+	// awsCostMetric is the cost after discounts and credits, the amount billed
+	awsCostMetric = "NetUnblendedCost"
+
+	awsRequestTimeout = 30 * time.Second
+)
+
+// awsEndpoint returns the Cost Explorer endpoint and signing region of an
+// account region, overridden in tests
+var awsEndpoint = func(regionID string) (endpoint, region string) {
+	if strings.HasPrefix(regionID, "cn-") {
+		return "https://ce." + awsChinaRegion + ".amazonaws.com.cn/", awsChinaRegion
+	}
+
+	return "https://ce." + awsDefaultRegion + ".amazonaws.com/", awsDefaultRegion
+}
+
+var awsHTTPClient = &http.Client{Timeout: awsRequestTimeout}
+
+// awsCostRequest is a GetCostAndUsage request
+type awsCostRequest struct {
+	TimePeriod  awsTimePeriod  `json:"TimePeriod"`
+	Granularity string         `json:"Granularity"`
+	Metrics     []string       `json:"Metrics"`
+	Filter      *awsExpression `json:"Filter,omitempty"`
+}
+
+type awsTimePeriod struct {
+	Start string `json:"Start"`
+	End   string `json:"End"`
+}
+
+type awsExpression struct {
+	Dimensions awsDimensionValues `json:"Dimensions"`
+}
+
+type awsDimensionValues struct {
+	Key    string   `json:"Key"`
+	Values []string `json:"Values"`
+}
+
+// awsCostResponse is the part of a GetCostAndUsage response holding the totals
+type awsCostResponse struct {
+	ResultsByTime []struct {
+		Total map[string]struct {
+			Amount string `json:"Amount"`
+			Unit   string `json:"Unit"`
+		} `json:"Total"`
+	} `json:"ResultsByTime"`
+}
+
+// awsError is the body of a failed AWS JSON API request
+type awsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// queryAWSSpend returns the month-to-date cost of an AWS account, including
+// the linked accounts of a management account, or of a single linked
+// account. AWS accounts are postpaid and have no balance, the cost is what
+// the account owes for the current month.
+func queryAWSSpend(account AccountConfig, linkedAccountID string, now time.Time) (float64, error) {
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	request := awsCostRequest{
+		// The end date is exclusive
+		TimePeriod: awsTimePeriod{
+			Start: monthStart.Format(time.DateOnly),
+			End:   now.AddDate(0, 0, 1).Format(time.DateOnly),
+		},
+		Granularity: "MONTHLY",
+		Metrics:     []string{awsCostMetric},
+	}
+
+	if linkedAccountID != "" {
+		request.Filter = &awsExpression{Dimensions: awsDimensionValues{
+			Key:    "LINKED_ACCOUNT",
+			Values: []string{linkedAccountID},
+		}}
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoint, region := awsEndpoint(account.RegionID)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", awsCostExplorerTarget)
+	signAWSRequest(req, body, account.AccessKeyID, account.AccessKeySecret, region, awsCostExplorerService, now)
+
+	resp, err := awsHTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query cost and usage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cost and usage: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr awsError
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			return 0, fmt.Errorf("query failed, Type: %s, Message: %s", apiErr.Type, apiErr.Message)
+		}
+
+		return 0, fmt.Errorf("query failed with status %d", resp.StatusCode)
+	}
+
+	var response awsCostResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return 0, fmt.Errorf("invalid cost and usage response: %w", err)
+	}
+
+	if len(response.ResultsByTime) == 0 {
+		return 0, errors.New("no cost data in response")
+	}
+
+	var spend float64
+
+	for _, result := range response.ResultsByTime {
+		total, ok := result.Total[awsCostMetric]
+		if !ok {
+			continue
+		}
+
+		amount, err := parseBalance(total.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cost %q: %w", total.Amount, err)
+		}
+
+		spend += amount
+	}
+
+	return spend, nil
+}
+
+// signAWSRequest signs a request with AWS Signature Version 4, all its
+// headers being signed
+func signAWSRequest(req *http.Request, body []byte, accessKeyID, secretKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,116 @@
+//nolint:testpackage // Tests need access to private functions
+package cloudbalance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAWSRequest(t *testing.T) {
+	// Example request of the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	signAWSRequest(
+		req,
+		nil,
+		"AKIDEXAMPLE",
+		"wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		"us-east-1",
+		"iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+	)
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Unexpected authorization header:\n got %s\nwant %s", got, expected)
+	}
+}
+
+func TestQueryAWSSpend(t *testing.T) {
+	var request awsCostRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != awsCostExplorerTarget {
+			t.Errorf("Unexpected target %q", target)
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("Expected a signed request, got %q", r.Header.Get("Authorization"))
+		}
+
+		request = awsCostRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Invalid request body: %v", err)
+		}
+
+		if request.Filter != nil && request.Filter.Dimensions.Values[0] == "denied" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"AccessDeniedException","message":"not authorized"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ResultsByTime":[{"Total":{"NetUnblendedCost":{"Amount":"1234.5678","Unit":"USD"}}}]}`))
+	}))
+	defer server.Close()
+
+	endpoint := awsEndpoint
+	awsEndpoint = func(string) (string, string) { return server.URL, awsDefaultRegion }
+
+	defer func() { awsEndpoint = endpoint }()
+
+	account := AccountConfig{Provider: AWS, AccountID: "111122223333", AccessKeyID: "AKID", AccessKeySecret: "secret"}
+	now := time.Date(2026, 3, 31, 18, 0, 0, 0, time.UTC)
+
+	spend, err := QueryAccountSpend(account, now)
+	if err != nil {
+		t.Fatalf("Failed to query spend: %v", err)
+	}
+
+	if spend != 1234.5678 {
+		t.Errorf("Expected spend 1234.5678, got %v", spend)
+	}
+
+	if request.TimePeriod.Start != "2026-03-01" || request.TimePeriod.End != "2026-04-01" {
+		t.Errorf("Expected the current month, got %+v", request.TimePeriod)
+	}
+
+	if request.Filter != nil {
+		t.Errorf("Expected no filter for the management account, got %+v", request.Filter)
+	}
+
+	if _, err := QuerySubAccountSpend(account, SubAccountConfig{ID: "444455556666"}, now); err != nil {
+		t.Fatalf("Failed to query linked account spend: %v", err)
+	}
+
+	if request.Filter == nil || request.Filter.Dimensions.Key != "LINKED_ACCOUNT" {
+		t.Errorf("Expected a linked account filter, got %+v", request.Filter)
+	}
+
+	_, err = QuerySubAccountSpend(account, SubAccountConfig{ID: "denied"}, now)
+	if err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+}
+
+func TestAWSEndpoint(t *testing.T) {
+	if endpoint, region := awsEndpoint("cn-north-1"); region != awsChinaRegion ||
+		endpoint != "https://ce.cn-northwest-1.amazonaws.com.cn/" {
+		t.Errorf("Unexpected China endpoint %s (%s)", endpoint, region)
+	}
+
+	if _, region := awsEndpoint("eu-west-1"); region != awsDefaultRegion {
+		t.Errorf("Expected the global endpoint region, got %s", region)
+	}
+}
@@ -0,0 +1,109 @@
+package cloudbalance
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// circuitBreaker stops querying an account after consecutive failures, so a
+// revoked credential does not keep hammering the provider API. Once opened,
+// the breaker lets a single trial query through after a backoff doubling on
+// every failed trial, up to a maximum.
+type circuitBreaker struct {
+	failures  int // consecutive failed queries
+	backoff   time.Duration
+	openUntil time.Time
+}
+
+// open returns whether the breaker is open, queries being skipped
+func (b *circuitBreaker) open(now time.Time) bool {
+	return now.Before(b.openUntil)
+}
+
+// record records the outcome of a query and returns whether the breaker
+// opened (again) because of it. A threshold of zero never opens the breaker.
+func (b *circuitBreaker) record(err error, now time.Time, threshold int, base, maxBackoff time.Duration) bool {
+	if err == nil {
+		*b = circuitBreaker{}
+		return false
+	}
+
+	b.failures++
+	if threshold <= 0 || b.failures < threshold {
+		return false
+	}
+
+	b.backoff = min(max(2*b.backoff, base), maxBackoff)
+	b.openUntil = now.Add(b.backoff)
+
+	return true
+}
+
+// breaker returns the breaker of an account, creating it if needed.
+// Must be called with c.mu held.
+func (c *Collector) breaker(key string) *circuitBreaker {
+	b, ok := c.breakers[key]
+	if !ok {
+		b = &circuitBreaker{}
+		c.breakers[key] = b
+	}
+
+	return b
+}
+
+// recordQuery records the outcome of a balance query in the breaker of the account
+func (c *Collector) recordQuery(account AccountConfig, key string, err error) {
+	c.mu.Lock()
+	b := c.breaker(key)
+	opened := b.record(err, time.Now(), c.config.BreakerThreshold, c.config.BreakerBackoff, c.config.BreakerMaxBackoff)
+	failures, backoff := b.failures, b.backoff
+	c.mu.Unlock()
+
+	if opened {
+		c.logger.WithFields(log.Fields{
+			"provider":   account.Provider,
+			"account_id": account.AccountID,
+			"failures":   failures,
+			"backoff":    backoff,
+		}).Warn("Circuit breaker opened after consecutive failures, suspending cloud account queries")
+	}
+}
+
+// collectBreakers emits the breaker state of every account.
+// Must be called with c.mu held.
+func (c *Collector) collectBreakers(ch chan<- prometheus.Metric, now time.Time) {
+	for _, account := range c.config.Accounts {
+		key := string(account.Provider) + ":" + account.AccountID
+
+		b, ok := c.breakers[key]
+		if !ok {
+			b = &circuitBreaker{}
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.breakerOpenGauge,
+			prometheus.GaugeValue,
+			boolToFloat64(b.open(now)),
+			string(account.Provider),
+			account.AccountID,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.failuresGauge,
+			prometheus.GaugeValue,
+			float64(b.failures),
+			string(account.Provider),
+			account.AccountID,
+		)
+	}
+}
+
+// boolToFloat64 converts a boolean to a float64
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+
+	return 0.0
+}
@@ -0,0 +1,66 @@
+//nolint:testpackage // Tests need access to private functions
+package cloudbalance
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var b circuitBreaker
+
+	errAPI := errors.New("InvalidAccessKeyId.NotFound")
+	now := time.Now()
+
+	// Opens on the third consecutive failure
+	for i := range 2 {
+		if b.record(errAPI, now, 3, time.Minute, 4*time.Minute) {
+			t.Fatalf("Breaker opened after %d failures", i+1)
+		}
+	}
+
+	if !b.record(errAPI, now, 3, time.Minute, 4*time.Minute) || !b.open(now) {
+		t.Fatal("Expected the breaker to open after 3 failures")
+	}
+
+	if !b.open(now.Add(30 * time.Second)) {
+		t.Error("Expected queries to be suspended during the backoff")
+	}
+
+	// Failed trials double the backoff up to the maximum
+	for _, backoff := range []time.Duration{2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		now = b.openUntil
+		if b.open(now) {
+			t.Fatal("Expected a trial query after the backoff")
+		}
+
+		b.record(errAPI, now, 3, time.Minute, 4*time.Minute)
+
+		if b.backoff != backoff {
+			t.Errorf("Expected a %s backoff, got %s", backoff, b.backoff)
+		}
+	}
+
+	// A successful trial closes the breaker
+	now = b.openUntil
+	b.record(nil, now, 3, time.Minute, 4*time.Minute)
+
+	if b.open(now) || b.failures != 0 || b.backoff != 0 {
+		t.Errorf("Expected the breaker to be reset, got %+v", b)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var b circuitBreaker
+
+	for range 10 {
+		if b.record(errors.New("failed"), time.Now(), 0, time.Minute, time.Hour) {
+			t.Fatal("Breaker with no threshold must never open")
+		}
+	}
+
+	if b.failures != 10 {
+		t.Errorf("Expected failures to be counted, got %d", b.failures)
+	}
+}
@@ -0,0 +1,139 @@
+package cloudbalance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// minForecastSpan is the minimum time covered by the balance samples of an
+// account before its exhaustion date is forecast
+const minForecastSpan = time.Hour
+
+// balanceSample is a balance observed by a successful query
+type balanceSample struct {
+	time    time.Time
+	balance float64
+}
+
+// QueryAccountSpend queries the month-to-date spend of an account, including
+// the members billed under a master account (the linked accounts of an AWS
+// management account)
+func QueryAccountSpend(account AccountConfig, now time.Time) (float64, error) {
+	switch account.Provider {
+	case AliCloud:
+		return queryAlibabaCloudSubAccountSpend(account, "", now.Format("2006-01"))
+	case TencentCloud:
+		return queryTencentCloudSubAccountSpend(account, "", now.Format("2006-01"))
+	case AWS:
+		return queryAWSSpend(account, "", now)
+	default:
+		return 0, fmt.Errorf("spend queries are not supported for provider %s", account.Provider)
+	}
+}
+
+// addBalanceSample records a balance and drops the samples older than the
+// forecast window.
+// Must be called with c.mu held.
+func (c *Collector) addBalanceSample(key string, balance float64, now time.Time) {
+	samples := append(c.history[key], balanceSample{time: now, balance: balance})
+
+	cutoff := now.Add(-c.config.ForecastWindow)
+
+	first := 0
+	for first < len(samples)-1 && samples[first].time.Before(cutoff) {
+		first++
+	}
+
+	c.history[key] = samples[first:]
+}
+
+// dailyBurn returns the balance spent per day over the samples, ignoring the
+// increases of top-ups, and whether the samples cover enough time
+func dailyBurn(samples []balanceSample) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	span := samples[len(samples)-1].time.Sub(samples[0].time)
+	if span < minForecastSpan {
+		return 0, false
+	}
+
+	var spent float64
+
+	for i := 1; i < len(samples); i++ {
+		if decrease := samples[i-1].balance - samples[i].balance; decrease > 0 {
+			spent += decrease
+		}
+	}
+
+	return spent / span.Hours() * 24, true
+}
+
+// exhaustionDays forecasts the days until a balance is exhausted at the
+// daily burn of its samples. It returns false when the samples do not cover
+// enough time or no spend was observed.
+func exhaustionDays(balance float64, samples []balanceSample) (float64, bool) {
+	if balance <= 0 {
+		return 0, true
+	}
+
+	burn, ok := dailyBurn(samples)
+	if !ok || burn <= 0 {
+		return 0, false
+	}
+
+	return balance / burn, true
+}
+
+// budgetPercent returns the share of a monthly budget consumed by a spend
+func budgetPercent(spend, budget float64) float64 {
+	return spend / budget * 100
+}
+
+// collectBudgets emits the forecast exhaustion date and the budget consumption
+// of the accounts, and the budget consumption of their sub-accounts.
+// Must be called with c.mu held.
+func (c *Collector) collectBudgets(ch chan<- prometheus.Metric, account AccountConfig, key string) {
+	provider := string(account.Provider)
+
+	if balance, ok := c.balances[key]; ok {
+		if days, ok := exhaustionDays(balance, c.history[key]); ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.exhaustionDaysGauge,
+				prometheus.GaugeValue,
+				days,
+				provider,
+				account.AccountID,
+			)
+		}
+	}
+
+	if spend, ok := c.spends[key]; ok && account.MonthlyBudget > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.budgetPercentGauge,
+			prometheus.GaugeValue,
+			budgetPercent(spend, account.MonthlyBudget),
+			provider,
+			account.AccountID,
+		)
+	}
+
+	for _, sub := range c.subAccounts[key] {
+		if sub.MonthlyBudget <= 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.subAccountBudgetPercentGauge,
+			prometheus.GaugeValue,
+			budgetPercent(sub.Spend, sub.MonthlyBudget),
+			provider,
+			account.AccountID,
+			sub.ID,
+			sub.Name,
+		)
+	}
+}
@@ -0,0 +1,75 @@
+//nolint:testpackage // Tests need access to private functions
+package cloudbalance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExhaustionDays(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	sample := func(hours, balance float64) balanceSample {
+		return balanceSample{time: start.Add(time.Duration(hours * float64(time.Hour))), balance: balance}
+	}
+
+	tests := []struct {
+		name     string
+		balance  float64
+		samples  []balanceSample
+		expected float64
+		ok       bool
+	}{
+		{
+			name:     "steady spend",
+			balance:  900,
+			samples:  []balanceSample{sample(0, 1000), sample(12, 950), sample(24, 900)},
+			expected: 9,
+			ok:       true,
+		},
+		{
+			// The top-up is not counted as negative spend
+			name:     "top-up",
+			balance:  1400,
+			samples:  []balanceSample{sample(0, 1000), sample(12, 950), sample(12.5, 1450), sample(24, 1400)},
+			expected: 14,
+			ok:       true,
+		},
+		{name: "too short", balance: 900, samples: []balanceSample{sample(0, 1000), sample(0.5, 900)}},
+		{name: "single sample", balance: 900, samples: []balanceSample{sample(0, 900)}},
+		{name: "no spend", balance: 900, samples: []balanceSample{sample(0, 900), sample(24, 900)}},
+		{name: "exhausted", balance: -5, samples: nil, expected: 0, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, ok := exhaustionDays(tt.balance, tt.samples)
+			if ok != tt.ok || math.Abs(days-tt.expected) > 1e-9 {
+				t.Errorf("exhaustionDays() = (%v, %v), expected (%v, %v)", days, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAddBalanceSample(t *testing.T) {
+	c := &Collector{
+		config:  &Config{ForecastWindow: 24 * time.Hour},
+		history: make(map[string][]balanceSample),
+	}
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for hour := range 48 {
+		c.addBalanceSample("alicloud:123456", float64(1000-hour), start.Add(time.Duration(hour)*time.Hour))
+	}
+
+	samples := c.history["alicloud:123456"]
+	if len(samples) != 25 {
+		t.Fatalf("Expected the samples of the last 24 hours, got %d", len(samples))
+	}
+
+	if first := samples[0].time; !first.Equal(start.Add(23 * time.Hour)) {
+		t.Errorf("Expected the oldest sample at the window start, got %v", first)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/faults"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Collector implements cloud balance monitoring
@@ -17,11 +19,40 @@ type Collector struct {
 	logger *log.Entry
 
 	// Prometheus metrics
-	balanceGauge *prometheus.Desc
+	balanceGauge           *prometheus.Desc
+	subAccountBalanceGauge *prometheus.Desc
+	subAccountSpendGauge   *prometheus.Desc
+	spendGauge             *prometheus.Desc
+	breakerOpenGauge       *prometheus.Desc
+	failuresGauge          *prometheus.Desc
+
+	exhaustionDaysGauge          *prometheus.Desc
+	budgetPercentGauge           *prometheus.Desc
+	subAccountBudgetPercentGauge *prometheus.Desc
 
 	// Internal state
-	mu       sync.RWMutex
-	balances map[string]float64 // key: provider:accountID
+	mu          sync.RWMutex
+	balances    map[string]float64           // key: provider:accountID
+	subAccounts map[string][]SubAccountUsage // key: provider:accountID
+	breakers    map[string]*circuitBreaker   // key: provider:accountID
+	spends      map[string]float64           // key: provider:accountID, month-to-date of postpaid accounts and accounts with a budget
+	history     map[string][]balanceSample   // key: provider:accountID, balances within the forecast window
+
+	// Informers of the Secrets holding account credentials
+	informers []cache.SharedIndexInformer
+	secrets   map[string]cache.Store // key: namespace/name
+	stopCh    chan struct{}
+}
+
+// SubAccountUsage holds the latest balance and spend of a sub-account
+type SubAccountUsage struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Spend      float64 `json:"spend"` // month-to-date
+	Balance    float64 `json:"balance"`
+	HasBalance bool    `json:"hasBalance"` // false when the sub-account shares the master balance
+
+	MonthlyBudget float64 `json:"monthlyBudget,omitempty"`
 }
 
 // initMetrics initializes Prometheus metric descriptors
@@ -33,12 +64,78 @@ func (c *Collector) initMetrics(namespace string) {
 		nil,
 	)
 
-	// Register descriptor
+	c.subAccountBalanceGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "sub_account_balance"),
+		"Current balance for each sub-account paying for itself",
+		[]string{"provider", "account_id", "sub_account_id", "sub_account_name"},
+		nil,
+	)
+	c.subAccountSpendGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "sub_account_spend"),
+		"Month-to-date spend for each sub-account billed under a master account",
+		[]string{"provider", "account_id", "sub_account_id", "sub_account_name"},
+		nil,
+	)
+
+	c.spendGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "spend"),
+		"Month-to-date spend for each postpaid cloud account and each account with a monthly budget",
+		[]string{"provider", "account_id"},
+		nil,
+	)
+
+	c.breakerOpenGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "circuit_open"),
+		"Whether queries of a cloud account are suspended after consecutive API failures (1=open, 0=closed)",
+		[]string{"provider", "account_id"},
+		nil,
+	)
+	c.failuresGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "consecutive_failures"),
+		"Number of consecutive failed balance queries of a cloud account",
+		[]string{"provider", "account_id"},
+		nil,
+	)
+
+	c.exhaustionDaysGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "forecast_exhaustion_days"),
+		"Days until the balance of a cloud account is exhausted at its recent daily spend",
+		[]string{"provider", "account_id"},
+		nil,
+	)
+	c.budgetPercentGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "budget_consumption_percent"),
+		"Month-to-date spend of a cloud account as a percentage of its monthly budget",
+		[]string{"provider", "account_id"},
+		nil,
+	)
+	c.subAccountBudgetPercentGauge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cloudbalance", "sub_account_budget_consumption_percent"),
+		"Month-to-date spend of a sub-account as a percentage of its monthly budget",
+		[]string{"provider", "account_id", "sub_account_id", "sub_account_name"},
+		nil,
+	)
+
+	// Register descriptors
 	c.MustRegisterDesc(c.balanceGauge)
+	c.MustRegisterDesc(c.subAccountBalanceGauge)
+	c.MustRegisterDesc(c.subAccountSpendGauge)
+	c.MustRegisterDesc(c.spendGauge)
+	c.MustRegisterDesc(c.breakerOpenGauge)
+	c.MustRegisterDesc(c.failuresGauge)
+	c.MustRegisterDesc(c.exhaustionDaysGauge)
+	c.MustRegisterDesc(c.budgetPercentGauge)
+	c.MustRegisterDesc(c.subAccountBudgetPercentGauge)
 }
 
-// HasSynced returns true (polling collector is always synced)
+// HasSynced returns true once the informers of the credential Secrets have synced
 func (c *Collector) HasSynced() bool {
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -50,7 +147,7 @@ func (c *Collector) Interval() time.Duration {
 // pollLoop periodically queries cloud balances
 func (c *Collector) pollLoop(ctx context.Context) {
 	// Initial poll
-	_ = c.Poll(ctx)
+	_ = c.PollOnce(ctx, c.Poll)
 	c.SetReady()
 
 	ticker := time.NewTicker(c.config.CheckInterval)
@@ -59,7 +156,7 @@ func (c *Collector) pollLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			if err := c.Poll(ctx); err != nil {
+			if err := c.PollOnce(ctx, c.Poll); err != nil {
 				c.logger.WithError(err).Error("Failed to poll cloud balances")
 			}
 		case <-ctx.Done():
@@ -79,14 +176,98 @@ func (c *Collector) Poll(ctx context.Context) error {
 	c.logger.WithField("count", len(c.config.Accounts)).Info("Starting cloud balance checks")
 
 	newBalances := make(map[string]float64)
+	newSubAccounts := make(map[string][]SubAccountUsage)
+	newSpends := make(map[string]float64)
+
 	for _, account := range c.config.Accounts {
 		select {
 		case <-ctx.Done():
+			c.RecordCanceled(ctx)
 			return ctx.Err()
 		default:
 		}
 
-		balance, err := QueryBalance(account)
+		key := string(account.Provider) + ":" + account.AccountID
+
+		c.mu.RLock()
+		b, tracked := c.breakers[key]
+		allowed := !tracked || !b.open(time.Now())
+		c.mu.RUnlock()
+
+		if !allowed {
+			c.logger.WithFields(log.Fields{
+				"provider":   account.Provider,
+				"account_id": account.AccountID,
+			}).Debug("Circuit breaker open, skipping cloud account")
+
+			continue
+		}
+
+		account, err := c.resolveCredentials(account)
+		if err != nil {
+			c.logger.WithFields(log.Fields{
+				"provider":   account.Provider,
+				"account_id": account.AccountID,
+			}).WithError(err).Error("Failed to load cloud account credentials")
+
+			continue
+		}
+
+		if len(account.SubAccounts) > 0 || account.DiscoverSubAccounts {
+			newSubAccounts[key] = c.pollSubAccounts(account)
+		}
+
+		// The spend of postpaid accounts is their main query below
+		if account.MonthlyBudget > 0 && !account.Provider.postpaid() {
+			spend, err := QueryAccountSpend(account, time.Now())
+			if err != nil {
+				c.logger.WithFields(log.Fields{
+					"provider":   account.Provider,
+					"account_id": account.AccountID,
+				}).WithError(err).Error("Failed to query cloud account spend")
+			} else {
+				newSpends[key] = spend
+			}
+		}
+
+		// Faults armed through the debug server fail (or slow down) the query
+		err = faults.Inject(ctx, collectorName, key)
+
+		if account.Provider.postpaid() {
+			var spend float64
+			if err == nil {
+				spend, err = QueryAccountSpend(account, time.Now())
+			}
+
+			c.recordQuery(account, key, err)
+
+			if err != nil {
+				c.logger.WithFields(log.Fields{
+					"provider":   account.Provider,
+					"account_id": account.AccountID,
+				}).WithError(err).Error("Failed to query cloud account spend")
+
+				continue
+			}
+
+			newSpends[key] = spend
+
+			c.logger.WithFields(log.Fields{
+				"provider":   account.Provider,
+				"account_id": account.AccountID,
+				"spend":      spend,
+			}).Debug("Cloud spend updated")
+
+			continue
+		}
+
+		var balance float64
+		if err == nil {
+			balance, err = QueryBalance(account)
+		}
+
+		c.recordQuery(account, key, err)
+
 		if err != nil {
 			c.logger.WithFields(log.Fields{
 				"provider":   account.Provider,
@@ -96,9 +277,12 @@ func (c *Collector) Poll(ctx context.Context) error {
 			continue
 		}
 
-		key := string(account.Provider) + ":" + account.AccountID
 		newBalances[key] = balance
 
+		c.mu.Lock()
+		c.addBalanceSample(key, balance, time.Now())
+		c.mu.Unlock()
+
 		c.logger.WithFields(log.Fields{
 			"provider":   account.Provider,
 			"account_id": account.AccountID,
@@ -108,11 +292,59 @@ func (c *Collector) Poll(ctx context.Context) error {
 
 	c.mu.Lock()
 	c.balances = newBalances
+	c.subAccounts = newSubAccounts
+	c.spends = newSpends
 	c.mu.Unlock()
 
 	return nil
 }
 
+// pollSubAccounts queries the balance and spend of every sub-account of a master account.
+// Sub-accounts whose spend cannot be queried are skipped.
+func (c *Collector) pollSubAccounts(account AccountConfig) []SubAccountUsage {
+	logger := c.logger.WithFields(log.Fields{
+		"provider":   account.Provider,
+		"account_id": account.AccountID,
+	})
+
+	subAccounts, err := ListSubAccounts(account)
+	if err != nil {
+		// Configured sub-accounts are still returned when discovery fails
+		logger.WithError(err).Error("Failed to discover sub-accounts")
+	}
+
+	now := time.Now()
+	usages := make([]SubAccountUsage, 0, len(subAccounts))
+
+	for _, sub := range subAccounts {
+		subLogger := logger.WithField("sub_account_id", sub.ID)
+
+		spend, err := QuerySubAccountSpend(account, sub, now)
+		if err != nil {
+			subLogger.WithError(err).Error("Failed to query sub-account spend")
+			continue
+		}
+
+		usage := SubAccountUsage{
+			ID:            sub.ID,
+			Name:          sub.Name,
+			Spend:         spend,
+			MonthlyBudget: sub.MonthlyBudget,
+		}
+
+		usage.Balance, usage.HasBalance, err = QuerySubAccountBalance(account, sub)
+		if err != nil {
+			subLogger.WithError(err).Error("Failed to query sub-account balance")
+		}
+
+		usages = append(usages, usage)
+	}
+
+	logger.WithField("count", len(usages)).Debug("Sub-account usage updated")
+
+	return usages
+}
+
 // collect implements the collect method for Prometheus
 func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	c.mu.RLock()
@@ -121,6 +353,42 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	for _, account := range c.config.Accounts {
 		key := string(account.Provider) + ":" + account.AccountID
 
+		for _, sub := range c.subAccounts[key] {
+			ch <- prometheus.MustNewConstMetric(
+				c.subAccountSpendGauge,
+				prometheus.GaugeValue,
+				sub.Spend,
+				string(account.Provider),
+				account.AccountID,
+				sub.ID,
+				sub.Name,
+			)
+
+			if sub.HasBalance {
+				ch <- prometheus.MustNewConstMetric(
+					c.subAccountBalanceGauge,
+					prometheus.GaugeValue,
+					sub.Balance,
+					string(account.Provider),
+					account.AccountID,
+					sub.ID,
+					sub.Name,
+				)
+			}
+		}
+
+		c.collectBudgets(ch, account, key)
+
+		if spend, exists := c.spends[key]; exists {
+			ch <- prometheus.MustNewConstMetric(
+				c.spendGauge,
+				prometheus.GaugeValue,
+				spend,
+				string(account.Provider),
+				account.AccountID,
+			)
+		}
+
 		balance, exists := c.balances[key]
 		if !exists {
 			continue
@@ -134,4 +402,6 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 			account.AccountID,
 		)
 	}
+
+	c.collectBreakers(ch, time.Now())
 }
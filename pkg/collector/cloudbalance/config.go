@@ -11,8 +11,15 @@ const (
 	AliCloud     CloudProvider = "alicloud"
 	VolcEngine   CloudProvider = "volcengine"
 	TencentCloud CloudProvider = "tencentcloud"
+	AWS          CloudProvider = "aws"
 )
 
+// postpaid reports whether the accounts of a provider are billed after use
+// and have no balance, their month-to-date spend is queried instead
+func (p CloudProvider) postpaid() bool {
+	return p == AWS
+}
+
 // AccountConfig holds configuration for a single cloud account
 type AccountConfig struct {
 	Provider        CloudProvider `yaml:"provider"        json:"provider"`
@@ -20,18 +27,71 @@ type AccountConfig struct {
 	AccessKeyID     string        `yaml:"accessKeyId"     json:"access_key_id"`
 	AccessKeySecret string        `yaml:"accessKeySecret" json:"access_key_secret"`
 	RegionID        string        `yaml:"regionId"        json:"region_id"`
+	// CredentialsSecret loads AccessKeyID and AccessKeySecret from a Kubernetes
+	// Secret, watched so rotated keys are used without a restart
+	CredentialsSecret *SecretRef `yaml:"credentialsSecret" json:"credentials_secret"`
+	// MonthlyBudget is the spend budget of the account per calendar month (0 = none)
+	MonthlyBudget float64 `yaml:"monthlyBudget" json:"monthly_budget"`
+
+	// SubAccounts lists linked sub-accounts billed under this (master) account
+	SubAccounts []SubAccountConfig `yaml:"subAccounts"         json:"sub_accounts"`
+	// DiscoverSubAccounts enumerates sub-accounts through the provider API
+	// (Alibaba Cloud only, from financial relations such as resource directory members).
+	// Explicitly listed sub-accounts take precedence over discovered ones.
+	DiscoverSubAccounts bool `yaml:"discoverSubAccounts" json:"discover_sub_accounts"`
+}
+
+// SecretRef references the credentials of an account in a Kubernetes Secret
+type SecretRef struct {
+	Namespace string `yaml:"namespace" json:"namespace"`
+	Name      string `yaml:"name"      json:"name"`
+	// AccessKeyIDKey and AccessKeySecretKey are the data keys holding the
+	// credentials (default: accessKeyId and accessKeySecret)
+	AccessKeyIDKey     string `yaml:"accessKeyIdKey"     json:"access_key_id_key"`
+	AccessKeySecretKey string `yaml:"accessKeySecretKey" json:"access_key_secret_key"`
+}
+
+// SubAccountConfig holds configuration for a sub-account of a master account
+type SubAccountConfig struct {
+	// ID is the Alibaba Cloud member account ID, the Tencent Cloud sub-user UIN
+	// or the AWS linked account ID
+	ID   string `yaml:"id"   json:"id"`
+	Name string `yaml:"name" json:"name"`
+	// AccessKeyID and AccessKeySecret are optional sub-account credentials,
+	// only needed to query the balance of sub-accounts paying for themselves
+	AccessKeyID     string `yaml:"accessKeyId"     json:"access_key_id"`
+	AccessKeySecret string `yaml:"accessKeySecret" json:"access_key_secret"`
+	// MonthlyBudget is the spend budget of the sub-account per calendar month (0 = none)
+	MonthlyBudget float64 `yaml:"monthlyBudget" json:"monthly_budget"`
 }
 
 // Config contains configuration for the CloudBalance collector
 type Config struct {
 	Accounts      []AccountConfig `yaml:"accounts"      env:"ACCOUNTS"       json:"accounts"`
 	CheckInterval time.Duration   `yaml:"checkInterval" env:"CHECK_INTERVAL" json:"check_interval"`
+	CycleTimeout  time.Duration   `yaml:"cycleTimeout"  env:"CYCLE_TIMEOUT"  json:"cycle_timeout"` // Collector-level timeout of a poll cycle (0 = global only)
+
+	// BreakerThreshold is the number of consecutive failed queries after which
+	// an account is no longer queried for BreakerBackoff (0 = never)
+	BreakerThreshold int `yaml:"breakerThreshold" env:"BREAKER_THRESHOLD" json:"breaker_threshold"`
+	// BreakerBackoff is how long queries are suspended when the breaker opens,
+	// doubled after every failed trial query up to BreakerMaxBackoff
+	BreakerBackoff    time.Duration `yaml:"breakerBackoff"    env:"BREAKER_BACKOFF"     json:"breaker_backoff"`
+	BreakerMaxBackoff time.Duration `yaml:"breakerMaxBackoff" env:"BREAKER_MAX_BACKOFF" json:"breaker_max_backoff"`
+
+	// ForecastWindow is the period of balance history the exhaustion date is
+	// forecast from
+	ForecastWindow time.Duration `yaml:"forecastWindow" env:"FORECAST_WINDOW" json:"forecast_window"`
 }
 
 // NewDefaultConfig returns the default configuration for CloudBalance collector
 func NewDefaultConfig() *Config {
 	return &Config{
-		Accounts:      []AccountConfig{},
-		CheckInterval: 5 * time.Minute,
+		Accounts:          []AccountConfig{},
+		CheckInterval:     5 * time.Minute,
+		BreakerThreshold:  3,
+		BreakerBackoff:    10 * time.Minute,
+		BreakerMaxBackoff: 6 * time.Hour,
+		ForecastWindow:    72 * time.Hour,
 	}
 }
@@ -0,0 +1,52 @@
+package cloudbalance
+
+import (
+	"net"
+	"net/url"
+	"slices"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/volcengine/volcengine-go-sdk/volcengine/volcengineutil"
+)
+
+// billingHost returns the billing API host queried for an account, empty
+// for unsupported providers
+func billingHost(account *AccountConfig) string {
+	switch account.Provider {
+	case AliCloud:
+		return alibabaCloudBillingHost
+	case TencentCloud:
+		return tencentCloudBillingHost
+	case VolcEngine:
+		// Resolved like the SDK resolves the endpoint of the session
+		return *volcengineutil.GetDefaultEndpointByServiceInfo("billing", account.RegionID, nil, nil, nil)
+	case AWS:
+		endpoint, _ := awsEndpoint(account.RegionID)
+		if u, err := url.Parse(endpoint); err == nil {
+			return u.Hostname()
+		}
+	}
+
+	return ""
+}
+
+// requiredEndpoints returns the billing APIs queried for the configured
+// accounts, verified by the preflight checks
+func requiredEndpoints(loader collector.ConfigLoader) ([]string, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.cloudbalance", cfg); err != nil {
+		return nil, err
+	}
+
+	var endpoints []string
+
+	for i := range cfg.Accounts {
+		if host := billingHost(&cfg.Accounts[i]); host != "" {
+			endpoints = append(endpoints, net.JoinHostPort(host, "443"))
+		}
+	}
+
+	slices.Sort(endpoints)
+
+	return slices.Compact(endpoints), nil
+}
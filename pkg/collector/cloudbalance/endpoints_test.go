@@ -0,0 +1,24 @@
+//nolint:testpackage // Tests need access to private functions
+package cloudbalance
+
+import "testing"
+
+func TestBillingHost(t *testing.T) {
+	tests := []struct {
+		account AccountConfig
+		want    string
+	}{
+		{AccountConfig{Provider: AliCloud, RegionID: "cn-hangzhou"}, "business.aliyuncs.com"},
+		{AccountConfig{Provider: TencentCloud}, "billing.tencentcloudapi.com"},
+		{AccountConfig{Provider: VolcEngine, RegionID: "cn-beijing"}, "open.volcengineapi.com"},
+		{AccountConfig{Provider: AWS}, "ce.us-east-1.amazonaws.com"},
+		{AccountConfig{Provider: AWS, RegionID: "cn-north-1"}, "ce.cn-northwest-1.amazonaws.com.cn"},
+		{AccountConfig{Provider: "unknown"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := billingHost(&tt.account); got != tt.want {
+			t.Errorf("billingHost(%s, %q) = %q, want %q", tt.account.Provider, tt.account.RegionID, got, tt.want)
+		}
+	}
+}
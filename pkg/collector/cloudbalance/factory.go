@@ -2,16 +2,27 @@ package cloudbalance
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
 const collectorName = "cloudbalance"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Cloud provider account balance monitoring"),
+		registry.WithStandalone(),
+		registry.WithConfigRBAC(requiredRBAC),
+		registry.WithConfigEndpoints(requiredEndpoints),
+	)
 }
 
 // NewCollector creates a new CloudBalance collector
@@ -26,15 +37,37 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			Debug("Failed to load cloudbalance collector config, using defaults")
 	}
 
+	if err := validateSecretRefs(cfg.Accounts); err != nil {
+		return nil, err
+	}
+
 	c := &Collector{
 		BaseCollector: base.NewBaseCollector(
 			collectorName,
 			factoryCtx.Logger,
 			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, cfg.CycleTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
 		),
-		config:   cfg,
-		balances: make(map[string]float64),
-		logger:   factoryCtx.Logger,
+		config:      cfg,
+		balances:    make(map[string]float64),
+		subAccounts: make(map[string][]SubAccountUsage),
+		breakers:    make(map[string]*circuitBreaker),
+		spends:      make(map[string]float64),
+		history:     make(map[string][]balanceSample),
+		stopCh:      make(chan struct{}),
+		logger:      factoryCtx.Logger,
+	}
+
+	// Credentials in Secrets need a Kubernetes client; inline credentials do not
+	var client kubernetes.Interface
+	if len(secretRefs(cfg.Accounts)) > 0 {
+		var err error
+
+		client, err = factoryCtx.GetClient()
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes client is required for credentials in Secrets but not available: %w", err)
+		}
 	}
 
 	c.initMetrics(factoryCtx.MetricsNamespace)
@@ -42,12 +75,31 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 	// Set lifecycle hooks
 	c.SetLifecycle(base.LifecycleFuncs{
 		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh to support restart
+			c.stopCh = make(chan struct{})
+
+			if client != nil {
+				c.startSecretInformers(client, factoryCtx.InformerResyncPeriod)
+
+				c.logger.Info("Waiting for credential secret informer cache sync")
+
+				if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+					return errors.New("failed to sync credential secret informer cache")
+				}
+
+				c.WatchInformers(c.informers...)
+			}
+
 			// Start background polling
 			go c.pollLoop(ctx)
 
 			c.logger.Info("CloudBalance collector started successfully")
 			return nil
 		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
 		CollectFunc: c.collect,
 	})
 
@@ -18,6 +18,12 @@ import (
 	"github.com/volcengine/volcengine-go-sdk/volcengine/session"
 )
 
+// Billing API hosts of the providers queried through their SDK
+const (
+	alibabaCloudBillingHost = "business.aliyuncs.com"
+	tencentCloudBillingHost = "billing.tencentcloudapi.com"
+)
+
 // QueryBalance queries balance based on provider
 func QueryBalance(account AccountConfig) (float64, error) {
 	var (
@@ -44,6 +50,8 @@ func QueryBalance(account AccountConfig) (float64, error) {
 			account.AccessKeySecret,
 			account.RegionID,
 		)
+	case AWS:
+		return 0, fmt.Errorf("provider %s is postpaid and has no balance", account.Provider)
 	default:
 		return 0, fmt.Errorf("unsupported provider: %s", account.Provider)
 	}
@@ -55,18 +63,28 @@ func QueryBalance(account AccountConfig) (float64, error) {
 	return parseBalance(balanceStr)
 }
 
-// queryAlibabaCloudBalance queries Alibaba Cloud balance
-func queryAlibabaCloudBalance(accessKeyID, accessKeySecret, regionID string) (string, error) {
+// newAlibabaCloudClient creates an Alibaba Cloud BSS (billing) client
+func newAlibabaCloudClient(accessKeyID, accessKeySecret, regionID string) (*bssclient.Client, error) {
 	config := &openapiclient.Config{
 		AccessKeyId:     tea.String(accessKeyID),
 		AccessKeySecret: tea.String(accessKeySecret),
 		RegionId:        tea.String(regionID),
-		Endpoint:        tea.String("business.aliyuncs.com"),
+		Endpoint:        tea.String(alibabaCloudBillingHost),
 	}
 
 	bssClient, err := bssclient.NewClient(config)
 	if err != nil {
-		return "", fmt.Errorf("failed to create client: %w", err)
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return bssClient, nil
+}
+
+// queryAlibabaCloudBalance queries Alibaba Cloud balance
+func queryAlibabaCloudBalance(accessKeyID, accessKeySecret, regionID string) (string, error) {
+	bssClient, err := newAlibabaCloudClient(accessKeyID, accessKeySecret, regionID)
+	if err != nil {
+		return "", err
 	}
 
 	response, err := bssClient.QueryAccountBalance()
@@ -119,15 +137,25 @@ func queryVolcEngineBalance(accessKeyID, accessKeySecret, regionID string) (stri
 	return *response.AvailableBalance, nil
 }
 
-// queryTencentCloudBalance queries Tencent Cloud balance
-func queryTencentCloudBalance(secretID, secretKey, regionID string) (string, error) {
+// newTencentCloudClient creates a Tencent Cloud billing client
+func newTencentCloudClient(secretID, secretKey, regionID string) (*billing2.Client, error) {
 	credential := common.NewCredential(secretID, secretKey)
 	cpf := profile.NewClientProfile()
-	cpf.HttpProfile.Endpoint = "billing.tencentcloudapi.com"
+	cpf.HttpProfile.Endpoint = tencentCloudBillingHost
 
 	client, err := billing2.NewClient(credential, regionID, cpf)
 	if err != nil {
-		return "", fmt.Errorf("failed to create client: %w", err)
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return client, nil
+}
+
+// queryTencentCloudBalance queries Tencent Cloud balance
+func queryTencentCloudBalance(secretID, secretKey, regionID string) (string, error) {
+	client, err := newTencentCloudClient(secretID, secretKey, regionID)
+	if err != nil {
+		return "", err
 	}
 
 	request := billing2.NewDescribeAccountBalanceRequest()
@@ -0,0 +1,223 @@
+package cloudbalance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Default data keys of the credentials in a Secret
+const (
+	defaultAccessKeyIDKey     = "accessKeyId"
+	defaultAccessKeySecretKey = "accessKeySecret"
+)
+
+// key returns the namespace/name key of the Secret
+func (r *SecretRef) key() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// dataKeys returns the data keys of the access key ID and secret
+func (r *SecretRef) dataKeys() (idKey, secretKey string) {
+	idKey, secretKey = r.AccessKeyIDKey, r.AccessKeySecretKey
+	if idKey == "" {
+		idKey = defaultAccessKeyIDKey
+	}
+
+	if secretKey == "" {
+		secretKey = defaultAccessKeySecretKey
+	}
+
+	return idKey, secretKey
+}
+
+// validateSecretRefs checks that every Secret reference names a Secret
+func validateSecretRefs(accounts []AccountConfig) error {
+	for _, account := range accounts {
+		ref := account.CredentialsSecret
+		if ref != nil && (ref.Namespace == "" || ref.Name == "") {
+			return fmt.Errorf(
+				"credentialsSecret of %s account %s requires a namespace and a name",
+				account.Provider,
+				account.AccountID,
+			)
+		}
+	}
+
+	return nil
+}
+
+// secretRefs returns the distinct Secrets referenced by the accounts
+func secretRefs(accounts []AccountConfig) []*SecretRef {
+	seen := make(map[string]bool)
+
+	var refs []*SecretRef
+
+	for _, account := range accounts {
+		ref := account.CredentialsSecret
+		if ref == nil || seen[ref.key()] {
+			continue
+		}
+
+		seen[ref.key()] = true
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// startSecretInformers watches each referenced Secret with an informer of
+// its own, selecting the Secret by name, so no other Secret is cached and
+// the permissions can be restricted to the referenced Secrets
+func (c *Collector) startSecretInformers(client kubernetes.Interface, resyncPeriod time.Duration) {
+	c.informers = nil
+	c.secrets = make(map[string]cache.Store)
+
+	for _, ref := range secretRefs(c.config.Accounts) {
+		factory := informers.NewSharedInformerFactoryWithOptions(
+			client,
+			resyncPeriod,
+			informers.WithNamespace(ref.Namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", ref.Name).String()
+			}),
+		)
+
+		informer := factory.Core().V1().Secrets().Informer()
+
+		c.InstrumentInformer("secrets", informer)
+
+		//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+		informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: c.handleSecretUpdate,
+		}))
+
+		c.informers = append(c.informers, informer)
+		c.secrets[ref.key()] = informer.GetStore()
+
+		factory.Start(c.stopCh)
+	}
+}
+
+// handleSecretUpdate resets the circuit breakers of the accounts using
+// rotated credentials, so the new keys are tried at the next poll
+func (c *Collector) handleSecretUpdate(oldObj, newObj any) {
+	oldSecret, ok := oldObj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+
+	newSecret, ok := newObj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+
+	key := newSecret.Namespace + "/" + newSecret.Name
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, account := range c.config.Accounts {
+		ref := account.CredentialsSecret
+		if ref == nil || ref.key() != key {
+			continue
+		}
+
+		idKey, secretKey := ref.dataKeys()
+		if bytes.Equal(oldSecret.Data[idKey], newSecret.Data[idKey]) &&
+			bytes.Equal(oldSecret.Data[secretKey], newSecret.Data[secretKey]) {
+			continue
+		}
+
+		delete(c.breakers, string(account.Provider)+":"+account.AccountID)
+
+		c.logger.WithFields(log.Fields{
+			"provider":   account.Provider,
+			"account_id": account.AccountID,
+			"secret":     key,
+		}).Info("Cloud account credentials rotated")
+	}
+}
+
+// resolveCredentials returns the account with the credentials of its Secret.
+// Accounts without a Secret reference are returned as configured.
+func (c *Collector) resolveCredentials(account AccountConfig) (AccountConfig, error) {
+	ref := account.CredentialsSecret
+	if ref == nil {
+		return account, nil
+	}
+
+	store, ok := c.secrets[ref.key()]
+	if !ok {
+		return account, fmt.Errorf("secret %s is not watched", ref.key())
+	}
+
+	obj, exists, err := store.GetByKey(ref.key())
+	if err != nil {
+		return account, err
+	}
+
+	if !exists {
+		return account, fmt.Errorf("secret %s not found", ref.key())
+	}
+
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return account, errors.New("unexpected object in secret store")
+	}
+
+	idKey, secretKey := ref.dataKeys()
+
+	accessKeyID := string(secret.Data[idKey])
+	accessKeySecret := string(secret.Data[secretKey])
+
+	if accessKeyID == "" || accessKeySecret == "" {
+		return account, fmt.Errorf("secret %s has no %s or %s key", ref.key(), idKey, secretKey)
+	}
+
+	account.AccessKeyID = accessKeyID
+	account.AccessKeySecret = accessKeySecret
+
+	return account, nil
+}
+
+// requiredRBAC returns the permissions to watch the referenced Secrets;
+// accounts with inline credentials need none
+func requiredRBAC(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.cloudbalance", cfg); err != nil {
+		return nil, err
+	}
+
+	refs := secretRefs(cfg.Accounts)
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	return []rbacv1.PolicyRule{{
+		APIGroups:     []string{""},
+		Resources:     []string{"secrets"},
+		ResourceNames: names,
+		Verbs:         []string{"get", "list", "watch"},
+	}}, nil
+}
@@ -0,0 +1,120 @@
+//nolint:testpackage // Tests need access to private functions
+package cloudbalance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSecretCredentials(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "aliyun"},
+		Data: map[string][]byte{
+			"id":              []byte("LTAI-old"),
+			"accessKeySecret": []byte("old-secret"),
+		},
+	}
+	client := fake.NewClientset(secret)
+
+	account := AccountConfig{
+		Provider:  AliCloud,
+		AccountID: "123456",
+		CredentialsSecret: &SecretRef{
+			Namespace:      "billing",
+			Name:           "aliyun",
+			AccessKeyIDKey: "id",
+		},
+	}
+	missing := AccountConfig{
+		Provider:          TencentCloud,
+		AccountID:         "987654",
+		CredentialsSecret: &SecretRef{Namespace: "billing", Name: "tencent"},
+	}
+
+	logger := log.NewEntry(log.StandardLogger())
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        &Config{Accounts: []AccountConfig{account, missing}},
+		breakers:      make(map[string]*circuitBreaker),
+		stopCh:        make(chan struct{}),
+		logger:        logger,
+	}
+
+	defer close(c.stopCh)
+
+	c.startSecretInformers(client, 0)
+
+	if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+		t.Fatal("Failed to sync secret informers")
+	}
+
+	resolved, err := c.resolveCredentials(account)
+	if err != nil {
+		t.Fatalf("Failed to resolve credentials: %v", err)
+	}
+
+	if resolved.AccessKeyID != "LTAI-old" || resolved.AccessKeySecret != "old-secret" {
+		t.Errorf("Unexpected credentials %q/%q", resolved.AccessKeyID, resolved.AccessKeySecret)
+	}
+
+	if _, err := c.resolveCredentials(missing); err == nil {
+		t.Error("Expected an error for a missing secret")
+	}
+
+	// Rotating the keys resets the breaker so the new keys are tried
+	c.breaker("alicloud:123456").failures = 5
+
+	rotated := secret.DeepCopy()
+	rotated.Data["id"] = []byte("LTAI-new")
+
+	_, err = client.CoreV1().Secrets("billing").Update(context.Background(), rotated, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resolved, err = c.resolveCredentials(account)
+		if err == nil && resolved.AccessKeyID == "LTAI-new" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the rotated credentials, got %q (%v)", resolved.AccessKeyID, err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c.mu.RLock()
+	_, tracked := c.breakers["alicloud:123456"]
+	c.mu.RUnlock()
+
+	if tracked {
+		t.Error("Expected the breaker to be reset after the rotation")
+	}
+}
+
+func TestSecretRBAC(t *testing.T) {
+	accounts := []AccountConfig{
+		{Provider: AliCloud, AccountID: "1", CredentialsSecret: &SecretRef{Namespace: "a", Name: "cloud"}},
+		{Provider: AliCloud, AccountID: "2", CredentialsSecret: &SecretRef{Namespace: "a", Name: "cloud"}},
+		{Provider: AliCloud, AccountID: "3", AccessKeyID: "inline", AccessKeySecret: "inline"},
+	}
+
+	if refs := secretRefs(accounts); len(refs) != 1 {
+		t.Errorf("Expected one distinct secret, got %d", len(refs))
+	}
+
+	if err := validateSecretRefs([]AccountConfig{{CredentialsSecret: &SecretRef{Name: "cloud"}}}); err == nil {
+		t.Error("Expected an error for a secret without namespace")
+	}
+}
@@ -0,0 +1,70 @@
+package cloudbalance
+
+import "time"
+
+// Status is the structured state of the CloudBalance collector
+type Status struct {
+	Accounts []AccountStatus `json:"accounts"`
+}
+
+// AccountStatus is the latest balance of a cloud account
+type AccountStatus struct {
+	Provider    CloudProvider     `json:"provider"`
+	AccountID   string            `json:"accountId"`
+	Balance     *float64          `json:"balance"`         // nil when the last query failed or the account is postpaid
+	Spend       *float64          `json:"spend,omitempty"` // month-to-date, see the spend metric
+	SubAccounts []SubAccountUsage `json:"subAccounts,omitempty"`
+
+	// Budget state, see the forecast_exhaustion_days and budget_consumption_percent metrics
+	ExhaustionDays           *float64 `json:"exhaustionDays,omitempty"`
+	BudgetConsumptionPercent *float64 `json:"budgetConsumptionPercent,omitempty"`
+
+	// Circuit breaker state, see the circuit_open metric
+	CircuitOpen         bool `json:"circuitOpen"`
+	ConsecutiveFailures int  `json:"consecutiveFailures"`
+}
+
+// Status returns the latest balances in configuration order
+func (c *Collector) Status() any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	status := Status{Accounts: make([]AccountStatus, 0, len(c.config.Accounts))}
+
+	for _, account := range c.config.Accounts {
+		key := string(account.Provider) + ":" + account.AccountID
+
+		accountStatus := AccountStatus{
+			Provider:    account.Provider,
+			AccountID:   account.AccountID,
+			SubAccounts: c.subAccounts[key],
+		}
+
+		if balance, exists := c.balances[key]; exists {
+			accountStatus.Balance = &balance
+
+			if days, ok := exhaustionDays(balance, c.history[key]); ok {
+				accountStatus.ExhaustionDays = &days
+			}
+		}
+
+		if spend, exists := c.spends[key]; exists {
+			accountStatus.Spend = &spend
+
+			if account.MonthlyBudget > 0 {
+				percent := budgetPercent(spend, account.MonthlyBudget)
+				accountStatus.BudgetConsumptionPercent = &percent
+			}
+		}
+
+		if b, tracked := c.breakers[key]; tracked {
+			accountStatus.CircuitOpen = b.open(now)
+			accountStatus.ConsecutiveFailures = b.failures
+		}
+
+		status.Accounts = append(status.Accounts, accountStatus)
+	}
+
+	return status
+}
@@ -0,0 +1,283 @@
+package cloudbalance
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	bssclient "github.com/alibabacloud-go/bssopenapi-20171214/client"
+	"github.com/alibabacloud-go/tea/tea"
+	billing2 "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/billing/v20180709"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+// alibabaCloudPageSize is the page size used for paginated Alibaba Cloud queries
+const alibabaCloudPageSize = 100
+
+// errSubAccountsUnsupported is returned for providers without sub-account support
+var errSubAccountsUnsupported = errors.New("sub-accounts are not supported for this provider")
+
+// errSubAccountDiscoveryUnsupported is returned when discovery is enabled for a
+// provider whose sub-accounts must be listed explicitly
+var errSubAccountDiscoveryUnsupported = errors.New(
+	"sub-account discovery is not supported for this provider",
+)
+
+// ListSubAccounts returns the sub-accounts of a master account: the explicitly
+// configured ones, followed by the discovered ones when discovery is enabled
+func ListSubAccounts(account AccountConfig) ([]SubAccountConfig, error) {
+	subAccounts := append([]SubAccountConfig(nil), account.SubAccounts...)
+	if !account.DiscoverSubAccounts {
+		return subAccounts, nil
+	}
+
+	var (
+		discovered []SubAccountConfig
+		err        error
+	)
+
+	switch account.Provider {
+	case AliCloud:
+		discovered, err = listAlibabaCloudSubAccounts(account)
+	default:
+		return subAccounts, fmt.Errorf("%w: %s", errSubAccountDiscoveryUnsupported, account.Provider)
+	}
+
+	if err != nil {
+		return subAccounts, err
+	}
+
+	configured := make(map[string]bool, len(subAccounts))
+	for _, sub := range subAccounts {
+		configured[sub.ID] = true
+	}
+
+	for _, sub := range discovered {
+		if !configured[sub.ID] {
+			subAccounts = append(subAccounts, sub)
+		}
+	}
+
+	return subAccounts, nil
+}
+
+// QuerySubAccountSpend queries the month-to-date spend of a sub-account
+// using the master account credentials
+func QuerySubAccountSpend(account AccountConfig, sub SubAccountConfig, now time.Time) (float64, error) {
+	switch account.Provider {
+	case AliCloud:
+		return queryAlibabaCloudSubAccountSpend(account, sub.ID, now.Format("2006-01"))
+	case TencentCloud:
+		return queryTencentCloudSubAccountSpend(account, sub.ID, now.Format("2006-01"))
+	case AWS:
+		return queryAWSSpend(account, sub.ID, now)
+	default:
+		return 0, fmt.Errorf("%w: %s", errSubAccountsUnsupported, account.Provider)
+	}
+}
+
+// QuerySubAccountBalance queries the balance of a sub-account using its own
+// credentials. Sub-accounts without credentials share the master balance,
+// and the accounts of postpaid providers have none.
+func QuerySubAccountBalance(account AccountConfig, sub SubAccountConfig) (float64, bool, error) {
+	if sub.AccessKeyID == "" || sub.AccessKeySecret == "" || account.Provider.postpaid() {
+		return 0, false, nil
+	}
+
+	balance, err := QueryBalance(AccountConfig{
+		Provider:        account.Provider,
+		AccountID:       sub.ID,
+		AccessKeyID:     sub.AccessKeyID,
+		AccessKeySecret: sub.AccessKeySecret,
+		RegionID:        account.RegionID,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return balance, true, nil
+}
+
+// listAlibabaCloudSubAccounts lists the member accounts linked to an Alibaba
+// Cloud master account through financial relations (e.g. resource directory members)
+func listAlibabaCloudSubAccounts(account AccountConfig) ([]SubAccountConfig, error) {
+	bssClient, err := newAlibabaCloudClient(
+		account.AccessKeyID,
+		account.AccessKeySecret,
+		account.RegionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var subAccounts []SubAccountConfig
+
+	for page := int32(1); ; page++ {
+		request := &bssclient.QueryRelationListRequest{
+			PageNum:    tea.Int32(page),
+			PageSize:   tea.Int32(alibabaCloudPageSize),
+			StatusList: tea.StringSlice([]string{"RELATED"}),
+		}
+
+		if userID, err := strconv.ParseInt(account.AccountID, 10, 64); err == nil {
+			request.UserId = tea.Int64(userID)
+		}
+
+		response, err := bssClient.QueryRelationList(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query relation list: %w", err)
+		}
+
+		if !tea.BoolValue(response.Body.Success) {
+			return nil, fmt.Errorf("query failed, Code: %s, Message: %s, RequestId: %s",
+				tea.StringValue(response.Body.Code),
+				tea.StringValue(response.Body.Message),
+				tea.StringValue(response.Body.RequestId))
+		}
+
+		if response.Body.Data == nil {
+			break
+		}
+
+		for _, relation := range response.Body.Data.FinancialRelationInfoList {
+			if relation.AccountId == nil {
+				continue
+			}
+
+			name := tea.StringValue(relation.AccountNickName)
+			if name == "" {
+				name = tea.StringValue(relation.AccountName)
+			}
+
+			subAccounts = append(subAccounts, SubAccountConfig{
+				ID:   strconv.FormatInt(tea.Int64Value(relation.AccountId), 10),
+				Name: name,
+			})
+		}
+
+		if int(page)*alibabaCloudPageSize >= int(tea.Int32Value(response.Body.Data.TotalCount)) {
+			break
+		}
+	}
+
+	return subAccounts, nil
+}
+
+// queryAlibabaCloudSubAccountSpend sums the pretax bill amount of a member account
+// for the given billing cycle (YYYY-MM). An empty member account ID sums the
+// bills of the account and of its members.
+func queryAlibabaCloudSubAccountSpend(
+	account AccountConfig,
+	subAccountID, billingCycle string,
+) (float64, error) {
+	var billOwnerID *int64
+
+	if subAccountID != "" {
+		id, err := strconv.ParseInt(subAccountID, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid sub-account ID %q: %w", subAccountID, err)
+		}
+
+		billOwnerID = tea.Int64(id)
+	}
+
+	bssClient, err := newAlibabaCloudClient(
+		account.AccessKeyID,
+		account.AccessKeySecret,
+		account.RegionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var spend float64
+
+	for page := int32(1); ; page++ {
+		response, err := bssClient.QueryAccountBill(&bssclient.QueryAccountBillRequest{
+			BillingCycle: tea.String(billingCycle),
+			BillOwnerId:  billOwnerID,
+			PageNum:      tea.Int32(page),
+			PageSize:     tea.Int32(alibabaCloudPageSize),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to query account bill: %w", err)
+		}
+
+		if !tea.BoolValue(response.Body.Success) {
+			return 0, fmt.Errorf("query failed, Code: %s, Message: %s, RequestId: %s",
+				tea.StringValue(response.Body.Code),
+				tea.StringValue(response.Body.Message),
+				tea.StringValue(response.Body.RequestId))
+		}
+
+		data := response.Body.Data
+		if data == nil || data.Items == nil {
+			break
+		}
+
+		for _, item := range data.Items.Item {
+			spend += float64(tea.Float32Value(item.PretaxAmount))
+		}
+
+		if int(page)*alibabaCloudPageSize >= int(tea.Int32Value(data.TotalCount)) {
+			break
+		}
+	}
+
+	return spend, nil
+}
+
+// queryTencentCloudSubAccountSpend sums the discounted cost of the resources
+// operated by a sub-user for the given month (YYYY-MM). An empty sub-user UIN
+// sums the cost of the whole account.
+func queryTencentCloudSubAccountSpend(
+	account AccountConfig,
+	subAccountUin, month string,
+) (float64, error) {
+	client, err := newTencentCloudClient(
+		account.AccessKeyID,
+		account.AccessKeySecret,
+		account.RegionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	request := billing2.NewDescribeBillSummaryRequest()
+	request.Month = common.StringPtr(month)
+	request.GroupType = common.StringPtr("payMode")
+	if subAccountUin != "" {
+		request.OperateUin = common.StringPtr(subAccountUin)
+	}
+
+	response, err := client.DescribeBillSummary(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query bill summary: %w", err)
+	}
+
+	if response.Response == nil {
+		return 0, errors.New("no bill data in response")
+	}
+
+	if response.Response.Ready == nil || *response.Response.Ready != 1 {
+		return 0, errors.New("bill data is not ready yet")
+	}
+
+	var spend float64
+
+	for _, detail := range response.Response.SummaryDetail {
+		if detail.RealTotalCost == nil {
+			continue
+		}
+
+		cost, err := parseBalance(*detail.RealTotalCost)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cost %q: %w", *detail.RealTotalCost, err)
+		}
+
+		spend += cost
+	}
+
+	return spend, nil
+}
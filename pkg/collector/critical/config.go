@@ -0,0 +1,28 @@
+package critical
+
+import "time"
+
+// Resource identifies a critical resource whose deletion must be detected
+type Resource struct {
+	Group     string `yaml:"group"`     // API group (empty for the core group)
+	Version   string `yaml:"version"`   // API version (defaults to v1)
+	Resource  string `yaml:"resource"`  // Plural resource name, e.g. namespaces or customresourcedefinitions
+	Namespace string `yaml:"namespace"` // Namespace of namespaced resources (empty for cluster-scoped ones)
+	Name      string `yaml:"name"`
+}
+
+// Config contains configuration for the critical resources collector
+type Config struct {
+	CheckInterval time.Duration `yaml:"checkInterval" env:"CHECK_INTERVAL"`
+	// Resources must exist and be ready; only configurable through the config file
+	Resources []Resource `yaml:"resources"`
+}
+
+// NewDefaultConfig returns the default configuration for the critical resources collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		CheckInterval: time.Minute,
+		Resources:     []Resource{},
+	}
+}
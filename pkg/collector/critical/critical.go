@@ -0,0 +1,236 @@
+package critical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// readyConditions are the condition types telling whether a resource is ready,
+// in order of preference (e.g. Established for CRDs, Available for APIServices)
+var readyConditions = []string{"Ready", "Established", "Available"}
+
+// readyPhases are the status phases of ready resources (e.g. Active for namespaces)
+var readyPhases = map[string]bool{
+	"Active":    true,
+	"Bound":     true,
+	"Running":   true,
+	"Available": true,
+	"Ready":     true,
+}
+
+// state is the last known state of a critical resource
+type state struct {
+	exists bool
+	ready  bool
+}
+
+// Collector checks that critical resources exist and are ready
+type Collector struct {
+	*base.BaseCollector
+
+	client dynamic.Interface
+	config *Config
+	logger *log.Entry
+
+	mu     sync.RWMutex
+	states map[Resource]state
+
+	// Metrics
+	resourceExists *prometheus.Desc
+	resourceReady  *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	labels := []string{"group", "resource", "namespace", "name"}
+
+	c.resourceExists = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "critical", "resource_exists"),
+		"Whether a critical resource exists (1=exists, 0=missing)",
+		labels,
+		nil,
+	)
+	c.resourceReady = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "critical", "resource_ready"),
+		"Whether a critical resource exists, is not being deleted and reports a ready condition or phase (1=ready, 0=not)",
+		labels,
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.resourceExists)
+	c.MustRegisterDesc(c.resourceReady)
+}
+
+// HasSynced returns true (polling collector is always synced)
+func (c *Collector) HasSynced() bool {
+	return true
+}
+
+// Interval returns the polling interval
+func (c *Collector) Interval() time.Duration {
+	return c.config.CheckInterval
+}
+
+// Poll checks every critical resource. Resources that cannot be checked keep
+// their previous state.
+func (c *Collector) Poll(ctx context.Context) error {
+	var errs []error
+
+	for _, resource := range c.config.Resources {
+		current, err := c.check(ctx, resource)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", resourceKey(resource), err))
+			continue
+		}
+
+		c.mu.Lock()
+		previous, known := c.states[resource]
+		c.states[resource] = current
+		c.mu.Unlock()
+
+		if known && previous.exists && !current.exists {
+			c.logger.WithField("resource", resourceKey(resource)).Warn("Critical resource is missing")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// check returns the state of a critical resource
+func (c *Collector) check(ctx context.Context, resource Resource) (state, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    resource.Group,
+		Version:  resource.Version,
+		Resource: resource.Resource,
+	}
+
+	var client dynamic.ResourceInterface = c.client.Resource(gvr)
+	if resource.Namespace != "" {
+		client = c.client.Resource(gvr).Namespace(resource.Namespace)
+	}
+
+	obj, err := client.Get(ctx, resource.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return state{}, nil
+	}
+
+	if err != nil {
+		return state{}, err
+	}
+
+	return state{exists: true, ready: isReady(obj)}, nil
+}
+
+// isReady returns whether a resource is not being deleted and reports a ready
+// condition or phase. Resources without conditions nor phase (e.g. Secrets)
+// are ready as soon as they exist.
+func isReady(obj *unstructured.Unstructured) bool {
+	if obj.GetDeletionTimestamp() != nil {
+		return false
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, conditionType := range readyConditions {
+		for _, item := range conditions {
+			condition, ok := item.(map[string]any)
+			if !ok || condition["type"] != conditionType {
+				continue
+			}
+
+			return condition["status"] == "True"
+		}
+	}
+
+	if phase, found, _ := unstructured.NestedString(obj.Object, "status", "phase"); found {
+		return readyPhases[phase]
+	}
+
+	return true
+}
+
+// resourceKey returns a readable identifier of a resource for logs
+func resourceKey(resource Resource) string {
+	key := resource.Resource
+	if resource.Group != "" {
+		key += "." + resource.Group
+	}
+
+	if resource.Namespace != "" {
+		return key + " " + resource.Namespace + "/" + resource.Name
+	}
+
+	return key + " " + resource.Name
+}
+
+// pollLoop runs the polling loop
+func (c *Collector) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.config.CheckInterval)
+	defer ticker.Stop()
+
+	// Do initial check
+	c.pollOnce(ctx)
+
+	// Mark as ready after first poll completes
+	c.SetReady()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.pollOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollOnce runs one poll cycle and logs the resources that could not be checked
+func (c *Collector) pollOnce(ctx context.Context) {
+	if err := c.PollOnce(ctx, c.Poll); err != nil {
+		c.logger.WithError(err).Warn("Failed to check critical resources")
+	}
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for resource, current := range c.states {
+		labels := []string{resource.Group, resource.Resource, resource.Namespace, resource.Name}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.resourceExists,
+			prometheus.GaugeValue,
+			boolToFloat64(current.exists),
+			labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.resourceReady,
+			prometheus.GaugeValue,
+			boolToFloat64(current.ready),
+			labels...,
+		)
+	}
+}
+
+// boolToFloat64 converts a boolean to a float64
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+
+	return 0.0
+}
@@ -0,0 +1,132 @@
+//nolint:testpackage // Tests need access to private functions
+package critical
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func object(apiVersion, kind, namespace, name string, status map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name},
+	}}
+
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+
+	if status != nil {
+		obj.Object["status"] = status
+	}
+
+	return obj
+}
+
+func TestPoll(t *testing.T) {
+	crdGVR := schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			crdGVR:                                  "CustomResourceDefinitionList",
+			{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+			{Version: "v1", Resource: "secrets"}:    "SecretList",
+		},
+		object("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "accounts.account.sealos.io",
+			map[string]any{"conditions": []any{
+				map[string]any{"type": "NamesAccepted", "status": "True"},
+				map[string]any{"type": "Established", "status": "True"},
+			}}),
+		object("v1", "Namespace", "", "sealos-system", map[string]any{"phase": "Terminating"}),
+		object("v1", "Secret", "sealos-system", "desktop-frontend-secret", nil),
+	)
+
+	resources := []Resource{
+		{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Name: "accounts.account.sealos.io"},
+		{Resource: "namespaces", Name: "sealos-system"},
+		{Resource: "secrets", Namespace: "sealos-system", Name: "desktop-frontend-secret"},
+		{Resource: "secrets", Namespace: "sealos-system", Name: "deleted-secret"},
+	}
+
+	if err := validateResources(resources); err != nil {
+		t.Fatalf("validateResources() error = %v", err)
+	}
+
+	c := &Collector{
+		client: client,
+		config: &Config{Resources: resources},
+		states: make(map[Resource]state),
+		logger: log.NewEntry(log.StandardLogger()),
+	}
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	expected := []state{
+		{exists: true, ready: true},
+		{exists: true, ready: false}, // terminating namespace
+		{exists: true, ready: true},
+		{exists: false, ready: false},
+	}
+
+	for i, resource := range resources {
+		if got := c.states[resource]; got != expected[i] {
+			t.Errorf("%s: expected %+v, got %+v", resourceKey(resource), expected[i], got)
+		}
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	deleting := object("v1", "Secret", "ns", "s", nil)
+	now := metav1.Now()
+	deleting.SetDeletionTimestamp(&now)
+
+	tests := []struct {
+		name  string
+		obj   *unstructured.Unstructured
+		ready bool
+	}{
+		{name: "no status", obj: object("v1", "Secret", "ns", "s", nil), ready: true},
+		{name: "being deleted", obj: deleting, ready: false},
+		{name: "active namespace", obj: object("v1", "Namespace", "", "n", map[string]any{"phase": "Active"}), ready: true},
+		{
+			name: "ready condition false",
+			obj: object("apps/v1", "Deployment", "ns", "d", map[string]any{"conditions": []any{
+				map[string]any{"type": "Available", "status": "False"},
+			}}),
+			ready: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReady(tt.obj); got != tt.ready {
+				t.Errorf("isReady() = %v, want %v", got, tt.ready)
+			}
+		})
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	if err := validateResources(nil); err == nil {
+		t.Error("Expected an error without resources")
+	}
+
+	if err := validateResources([]Resource{{Resource: "namespaces"}}); err == nil {
+		t.Error("Expected an error without name")
+	}
+}
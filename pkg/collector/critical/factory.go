@@ -0,0 +1,132 @@
+package critical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const collectorName = "critical"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Existence and readiness of critical resources (namespaces, CRDs, secrets, ...)"),
+		registry.WithConfigRBAC(requiredRBAC),
+	)
+}
+
+// NewCollector creates a new critical resources collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.critical", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load critical collector config, using defaults")
+	}
+
+	if err := validateResources(cfg.Resources); err != nil {
+		return nil, err
+	}
+
+	restConfig, err := factoryCtx.GetRestConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, 0),
+		),
+		client: client,
+		config: cfg,
+		states: make(map[Resource]state),
+		logger: factoryCtx.Logger,
+	}
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			c.mu.Lock()
+			c.states = make(map[Resource]state)
+			c.mu.Unlock()
+
+			// Start polling goroutine
+			go c.pollLoop(ctx)
+
+			c.logger.WithField("resources", len(c.config.Resources)).
+				Info("Critical resources collector started successfully")
+
+			return nil
+		},
+		StopFunc: func() error {
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
+
+// requiredRBAC returns the permission to get each configured resource by name
+func requiredRBAC(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.critical", cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateResources(cfg.Resources); err != nil {
+		return nil, err
+	}
+
+	rules := make([]rbacv1.PolicyRule, 0, len(cfg.Resources))
+	for _, resource := range cfg.Resources {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{resource.Group},
+			Resources:     []string{resource.Resource},
+			ResourceNames: []string{resource.Name},
+			Verbs:         []string{"get"},
+		})
+	}
+
+	return rules, nil
+}
+
+// validateResources checks the configured resources and defaults their version
+func validateResources(resources []Resource) error {
+	if len(resources) == 0 {
+		return errors.New("no critical resources configured")
+	}
+
+	for i := range resources {
+		if resources[i].Resource == "" || resources[i].Name == "" {
+			return fmt.Errorf("critical resource %d: resource and name are required", i)
+		}
+
+		if resources[i].Version == "" {
+			resources[i].Version = "v1"
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package dbprobe
+
+import "time"
+
+// Config contains configuration for the database probe collector
+type Config struct {
+	// Namespaces to discover KubeBlocks database Services in (empty = all namespaces)
+	Namespaces    []string      `yaml:"namespaces"    env:"NAMESPACES"     envSeparator:","`
+	CheckInterval time.Duration `yaml:"checkInterval" env:"CHECK_INTERVAL"`
+	CheckTimeout  time.Duration `yaml:"checkTimeout"  env:"CHECK_TIMEOUT"` // Per-probe timeout (connection and handshake)
+	Concurrency   int           `yaml:"concurrency"   env:"CONCURRENCY"`   // Maximum number of concurrent probes
+}
+
+// NewDefaultConfig returns the default configuration for the database probe collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces:    []string{},
+		CheckInterval: time.Minute,
+		CheckTimeout:  3 * time.Second,
+		Concurrency:   10,
+	}
+}
@@ -0,0 +1,294 @@
+package dbprobe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KubeBlocks labels of the Services created for database clusters
+const (
+	managedByLabel      = "app.kubernetes.io/managed-by"
+	managedByKubeBlocks = "kubeblocks"
+	instanceLabel       = "app.kubernetes.io/instance"
+	componentLabel      = "apps.kubeblocks.io/component-name"
+)
+
+// defaultPorts maps the default port of each protocol, used when the Service
+// port name does not tell the protocol
+var defaultPorts = map[int32]string{
+	3306: ProtocolMySQL,
+	5432: ProtocolPostgres,
+	6379: ProtocolRedis,
+}
+
+// target is a database endpoint to probe
+type target struct {
+	namespace string
+	cluster   string
+	component string
+	service   string
+	protocol  string
+	address   string
+}
+
+// result is the last probe result of a target
+type result struct {
+	target
+	ProbeResult
+}
+
+// Collector probes the database endpoints of KubeBlocks clusters
+type Collector struct {
+	*base.BaseCollector
+
+	client kubernetes.Interface
+	config *Config
+	logger *log.Entry
+
+	mu      sync.RWMutex
+	results map[string]*result // key: address
+
+	// Metrics
+	probeUp      *prometheus.Desc
+	probeLatency *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.probeUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "database", "probe_up"),
+		"Whether the database endpoint completed a protocol handshake (1=up, 0=down), "+
+			"reason is timeout, refused, unreachable, rejected or protocol when down",
+		[]string{"namespace", "cluster", "component", "service", "protocol", "reason"},
+		nil,
+	)
+	c.probeLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "database", "probe_latency_seconds"),
+		"Time to connect to the database endpoint and complete the protocol handshake",
+		[]string{"namespace", "cluster", "component", "service", "protocol"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.probeUp)
+	c.MustRegisterDesc(c.probeLatency)
+}
+
+// HasSynced returns true (polling collector is always synced)
+func (c *Collector) HasSynced() bool {
+	return true
+}
+
+// Interval returns the polling interval
+func (c *Collector) Interval() time.Duration {
+	return c.config.CheckInterval
+}
+
+// Poll discovers the database endpoints and probes them
+func (c *Collector) Poll(ctx context.Context) error {
+	targets, err := c.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := make(map[string]*result, len(targets))
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(c.config.Concurrency, 1))
+	)
+
+	for _, t := range targets {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res := probe(ctx, t.address, t.protocol, c.config.CheckTimeout)
+			if !res.OK {
+				c.logger.WithError(res.Err).WithFields(log.Fields{
+					"namespace": t.namespace,
+					"service":   t.service,
+					"protocol":  t.protocol,
+					"reason":    res.Reason,
+				}).Debug("Database probe failed")
+			}
+
+			mu.Lock()
+			results[t.address] = &result{target: t, ProbeResult: res}
+			mu.Unlock()
+		})
+	}
+
+	wg.Wait()
+
+	c.mu.Lock()
+	c.results = results
+	c.mu.Unlock()
+
+	c.logger.WithField("count", len(targets)).Debug("Database probes completed")
+
+	return nil
+}
+
+// discover returns the database endpoints of the KubeBlocks Services in the
+// configured namespaces
+func (c *Collector) discover(ctx context.Context) ([]target, error) {
+	namespaces := c.config.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var targets []target
+
+	for _, namespace := range namespaces {
+		services, err := c.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: managedByLabel + "=" + managedByKubeBlocks,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+
+		for i := range services.Items {
+			targets = append(targets, serviceTargets(&services.Items[i])...)
+		}
+	}
+
+	return targets, nil
+}
+
+// serviceTargets returns the database endpoints of a Service, one per port
+// speaking a supported protocol. Headless Services are skipped, their pods
+// are reached through the cluster IP Service of the component.
+func serviceTargets(service *corev1.Service) []target {
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == corev1.ClusterIPNone {
+		return nil
+	}
+
+	var targets []target
+
+	for _, port := range service.Spec.Ports {
+		if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+			continue
+		}
+
+		protocol := portProtocol(port)
+		if protocol == "" {
+			continue
+		}
+
+		targets = append(targets, target{
+			namespace: service.Namespace,
+			cluster:   service.Labels[instanceLabel],
+			component: service.Labels[componentLabel],
+			service:   service.Name,
+			protocol:  protocol,
+			address:   net.JoinHostPort(service.Spec.ClusterIP, strconv.Itoa(int(port.Port))),
+		})
+	}
+
+	return targets
+}
+
+// portProtocol returns the protocol of a Service port from its name (e.g.
+// "mysql", "tcp-postgresql", "redis"), or from its number when the name does
+// not tell, or "" when the protocol is not supported
+func portProtocol(port corev1.ServicePort) string {
+	name := strings.ToLower(port.Name)
+
+	switch {
+	case strings.Contains(name, "sentinel"):
+		// Redis Sentinel does not serve data
+		return ""
+	case strings.Contains(name, "mysql"):
+		return ProtocolMySQL
+	case strings.Contains(name, "postgres"):
+		return ProtocolPostgres
+	case strings.Contains(name, "redis"):
+		return ProtocolRedis
+	}
+
+	return defaultPorts[port.Port]
+}
+
+// pollLoop runs the polling loop
+func (c *Collector) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.config.CheckInterval)
+	defer ticker.Stop()
+
+	// Do initial check
+	c.pollOnce(ctx)
+
+	// Mark as ready after first poll completes
+	c.SetReady()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.pollOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollOnce runs one poll cycle, keeping the previous results when it fails
+func (c *Collector) pollOnce(ctx context.Context) {
+	if err := c.PollOnce(ctx, c.Poll); err != nil {
+		c.logger.WithError(err).Warn("Failed to probe databases")
+	}
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, res := range c.results {
+		ch <- prometheus.MustNewConstMetric(
+			c.probeUp,
+			prometheus.GaugeValue,
+			boolToFloat64(res.OK),
+			res.namespace,
+			res.cluster,
+			res.component,
+			res.service,
+			res.protocol,
+			res.Reason,
+		)
+
+		if res.OK {
+			ch <- prometheus.MustNewConstMetric(
+				c.probeLatency,
+				prometheus.GaugeValue,
+				res.Latency.Seconds(),
+				res.namespace,
+				res.cluster,
+				res.component,
+				res.service,
+				res.protocol,
+			)
+		}
+	}
+}
+
+// boolToFloat64 converts a boolean to a float64
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+
+	return 0.0
+}
@@ -0,0 +1,77 @@
+package dbprobe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+)
+
+const collectorName = "dbprobe"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Credential-less MySQL, PostgreSQL and Redis handshake probes of KubeBlocks databases"),
+		registry.WithRBAC([]string{""}, []string{"services"}, []string{"list"}),
+	)
+}
+
+// NewCollector creates a new database probe collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.dbprobe", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load dbprobe collector config, using defaults")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, 0),
+		),
+		client:  client,
+		config:  cfg,
+		results: make(map[string]*result),
+		logger:  factoryCtx.Logger,
+	}
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			c.mu.Lock()
+			c.results = make(map[string]*result)
+			c.mu.Unlock()
+
+			// Start polling goroutine
+			go c.pollLoop(ctx)
+
+			c.logger.Info("Database probe collector started successfully")
+
+			return nil
+		},
+		StopFunc: func() error {
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
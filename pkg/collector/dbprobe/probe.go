@@ -0,0 +1,193 @@
+package dbprobe
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Protocols probed without credentials
+const (
+	ProtocolMySQL    = "mysql"
+	ProtocolPostgres = "postgresql"
+	ProtocolRedis    = "redis"
+)
+
+// Probe failure reasons
+const (
+	ReasonTimeout     = "timeout"
+	ReasonRefused     = "refused"
+	ReasonUnreachable = "unreachable"
+	ReasonRejected    = "rejected" // the server answered with an error (e.g. too many connections)
+	ReasonProtocol    = "protocol" // the response does not match the protocol
+)
+
+// errRejected is returned when the server answers with a protocol error
+var errRejected = errors.New("server rejected the connection")
+
+// prober performs the protocol handshake on an established connection
+type prober func(rw *bufio.ReadWriter) error
+
+// probers maps each protocol to its handshake
+var probers = map[string]prober{
+	ProtocolMySQL:    probeMySQL,
+	ProtocolPostgres: probePostgres,
+	ProtocolRedis:    probeRedis,
+}
+
+// ProbeResult is the outcome of probing one database endpoint
+type ProbeResult struct {
+	OK      bool
+	Reason  string
+	Latency time.Duration
+	Err     error
+}
+
+// probe connects to address and performs the handshake of protocol. The
+// latency covers the connection and the handshake.
+func probe(ctx context.Context, address, protocol string, timeout time.Duration) ProbeResult {
+	handshake, ok := probers[protocol]
+	if !ok {
+		return ProbeResult{Reason: ReasonProtocol, Err: fmt.Errorf("unsupported protocol %q", protocol)}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return ProbeResult{Reason: classifyError(err), Err: err}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	if err := handshake(rw); err != nil {
+		return ProbeResult{Reason: classifyError(err), Err: err}
+	}
+
+	return ProbeResult{OK: true, Latency: time.Since(start)}
+}
+
+// classifyError maps a probe error to a failure reason
+func classifyError(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, errRejected):
+		return ReasonRejected
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ReasonTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ReasonRefused
+	case errors.As(err, &netErr):
+		return ReasonUnreachable
+	default:
+		return ReasonProtocol
+	}
+}
+
+// probeMySQL reads the initial handshake packet the server sends on connect:
+// a 3 bytes payload length, a sequence number, then the protocol version (10)
+// or an error packet (0xff)
+func probeMySQL(rw *bufio.ReadWriter) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(rw, header); err != nil {
+		return fmt.Errorf("failed to read handshake header: %w", err)
+	}
+
+	if header[0] == 0 && header[1] == 0 && header[2] == 0 {
+		return errors.New("empty handshake packet")
+	}
+
+	marker, err := rw.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read handshake: %w", err)
+	}
+
+	switch marker {
+	case 0x0a:
+		return nil
+	case 0xff:
+		return errRejected
+	default:
+		return fmt.Errorf("unexpected protocol version %d", marker)
+	}
+}
+
+// postgresSSLRequestCode is the SSLRequest startup message code
+const postgresSSLRequestCode = 80877103
+
+// probePostgres sends an SSLRequest startup message, which the server answers
+// with a single byte (S or N) before any authentication
+func probePostgres(rw *bufio.ReadWriter) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+
+	if _, err := rw.Write(request); err != nil {
+		return fmt.Errorf("failed to send SSL request: %w", err)
+	}
+
+	if err := rw.Flush(); err != nil {
+		return fmt.Errorf("failed to send SSL request: %w", err)
+	}
+
+	answer, err := rw.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read SSL answer: %w", err)
+	}
+
+	switch answer {
+	case 'S', 'N':
+		return nil
+	case 'E':
+		return errRejected
+	default:
+		return fmt.Errorf("unexpected SSL answer %q", answer)
+	}
+}
+
+// probeRedis sends an inline PING. Without credentials, a server requiring
+// authentication answers NOAUTH, which still proves it serves requests.
+func probeRedis(rw *bufio.ReadWriter) error {
+	if _, err := rw.WriteString("PING\r\n"); err != nil {
+		return fmt.Errorf("failed to send PING: %w", err)
+	}
+
+	if err := rw.Flush(); err != nil {
+		return fmt.Errorf("failed to send PING: %w", err)
+	}
+
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read PING reply: %w", err)
+	}
+
+	line = strings.TrimSpace(line)
+
+	switch {
+	case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-NOAUTH"):
+		return nil
+	case strings.HasPrefix(line, "-"):
+		return fmt.Errorf("%w: %s", errRejected, line)
+	default:
+		return fmt.Errorf("unexpected PING reply %q", line)
+	}
+}
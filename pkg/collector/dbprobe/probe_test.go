@@ -0,0 +1,178 @@
+//nolint:testpackage // Tests need access to private functions
+package dbprobe
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeServer accepts one connection and answers it with serve
+func fakeServer(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		serve(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+// reply answers with response once the client sent request bytes
+func reply(requestSize int, response string) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		if requestSize > 0 {
+			buf := make([]byte, requestSize)
+			if _, err := bufio.NewReader(conn).Read(buf); err != nil {
+				return
+			}
+		}
+
+		_, _ = conn.Write([]byte(response))
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		serve    func(conn net.Conn)
+		reason   string
+	}{
+		{
+			name:     "mysql handshake",
+			protocol: ProtocolMySQL,
+			serve:    reply(0, "\x4a\x00\x00\x00\x0a8.0.36\x00"),
+		},
+		{
+			name:     "mysql error packet",
+			protocol: ProtocolMySQL,
+			serve:    reply(0, "\x17\x00\x00\x00\xff\x10\x04Too many connections"),
+			reason:   ReasonRejected,
+		},
+		{
+			name:     "postgres without tls",
+			protocol: ProtocolPostgres,
+			serve:    reply(8, "N"),
+		},
+		{
+			name:     "redis pong",
+			protocol: ProtocolRedis,
+			serve:    reply(6, "+PONG\r\n"),
+		},
+		{
+			name:     "redis requiring auth",
+			protocol: ProtocolRedis,
+			serve:    reply(6, "-NOAUTH Authentication required.\r\n"),
+		},
+		{
+			name:     "redis loading",
+			protocol: ProtocolRedis,
+			serve:    reply(6, "-LOADING Redis is loading the dataset in memory\r\n"),
+			reason:   ReasonRejected,
+		},
+		{
+			name:     "wrong protocol",
+			protocol: ProtocolPostgres,
+			serve:    reply(8, "HTTP/1.1 400 Bad Request\r\n"),
+			reason:   ReasonProtocol,
+		},
+		{
+			name:     "silent server",
+			protocol: ProtocolMySQL,
+			serve:    func(net.Conn) { time.Sleep(time.Second) },
+			reason:   ReasonTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := fakeServer(t, tt.serve)
+
+			res := probe(context.Background(), address, tt.protocol, 200*time.Millisecond)
+			if res.OK != (tt.reason == "") || res.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got ok=%v reason=%q err=%v", tt.reason, res.OK, res.Reason, res.Err)
+			}
+		})
+	}
+}
+
+func TestProbeRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	address := listener.Addr().String()
+	listener.Close()
+
+	if res := probe(context.Background(), address, ProtocolRedis, time.Second); res.Reason != ReasonRefused {
+		t.Errorf("Expected reason %q, got %q (%v)", ReasonRefused, res.Reason, res.Err)
+	}
+}
+
+func TestServiceTargets(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-user1",
+			Name:      "cache-redis",
+			Labels: map[string]string{
+				instanceLabel:  "cache",
+				componentLabel: "redis",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.96.0.10",
+			Ports: []corev1.ServicePort{
+				{Name: "redis", Port: 6379},
+				{Name: "redis-sentinel", Port: 26379},
+				{Name: "metrics", Port: 9121},
+				{Name: "db", Port: 5432},
+			},
+		},
+	}
+
+	targets := serviceTargets(service)
+	if len(targets) != 2 {
+		t.Fatalf("Expected 2 targets, got %+v", targets)
+	}
+
+	want := target{
+		namespace: "ns-user1",
+		cluster:   "cache",
+		component: "redis",
+		service:   "cache-redis",
+		protocol:  ProtocolRedis,
+		address:   "10.96.0.10:6379",
+	}
+	if targets[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, targets[0])
+	}
+
+	if targets[1].protocol != ProtocolPostgres {
+		t.Errorf("Expected the default port to tell the protocol, got %+v", targets[1])
+	}
+
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	if targets := serviceTargets(service); len(targets) != 0 {
+		t.Errorf("Expected headless services to be skipped, got %+v", targets)
+	}
+}
@@ -0,0 +1,375 @@
+package domain
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// acmeChallengePrefix is the path prefix of HTTP-01 challenges
+	acmeChallengePrefix = "/.well-known/acme-challenge/"
+	// acmeSolverLabel marks the Ingresses created by cert-manager to solve HTTP-01 challenges
+	acmeSolverLabel = "acme.cert-manager.io/http01-solver"
+)
+
+// acmeAnnotations request a certificate from cert-manager on an Ingress
+var acmeAnnotations = []string{
+	"cert-manager.io/cluster-issuer",
+	"cert-manager.io/issuer",
+	"kubernetes.io/tls-acme",
+}
+
+// Reasons a certificate is pending
+const (
+	certPendingMissing      = "missing"
+	certPendingInvalid      = "invalid"
+	certPendingExpired      = "expired"
+	certPendingHostMismatch = "host_mismatch"
+)
+
+// Reasons a challenge is not reachable
+const (
+	challengeNoSolver    = "no_challenge"
+	challengeDNS         = "dns"
+	challengeNotFound    = "not_found"
+	challengeRedirect    = "redirect"
+	challengeTimeout     = "timeout"
+	challengeUnreachable = "unreachable"
+	challengeHTTPError   = "http_error"
+)
+
+// ACMEStatus is the state of a pending certificate of an Ingress host
+// managed by cert-manager
+type ACMEStatus struct {
+	Namespace string
+	Ingress   string
+	Host      string
+	Secret    string
+	// CertReason explains why the certificate is pending (missing, invalid, expired, host_mismatch)
+	CertReason string
+	// ChallengeReason explains why the challenge is not reachable (empty when reachable)
+	ChallengeReason string
+}
+
+// acmeProbe requests a challenge URL with header and returns the response status code
+type acmeProbe func(ctx context.Context, url string, header http.Header) (int, error)
+
+// acmeClient does not follow redirects, so they are reported as such
+var acmeClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// probeChallenge requests a challenge URL with a GET, as the ACME server does
+func probeChallenge(ctx context.Context, url string, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := acmeClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	_ = resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// checkACME finds the hosts of cert-manager annotated Ingresses whose
+// certificate is missing or invalid, and probes their HTTP-01 challenges.
+// The previous results are kept when Ingresses cannot be listed.
+func (c *Collector) checkACME(ctx context.Context) {
+	ingresses, err := c.client.NetworkingV1().Ingresses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to list ingresses, keeping previous ACME challenge results")
+		return
+	}
+
+	solvers := acmeSolverPaths(ingresses.Items)
+	secrets := make(map[string]*corev1.Secret) // key: namespace/secret
+
+	var statuses []*ACMEStatus
+
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !acmeManaged(ingress) {
+			continue
+		}
+
+		for _, tls := range ingress.Spec.TLS {
+			for _, host := range tls.Hosts {
+				host = strings.ToLower(host)
+
+				reason, err := c.pendingReason(ctx, secrets, ingress.Namespace, tls.SecretName, host)
+				if err != nil {
+					c.logger.WithError(err).WithFields(log.Fields{
+						"namespace": ingress.Namespace,
+						"secret":    tls.SecretName,
+					}).Warn("Failed to get certificate secret")
+
+					continue
+				}
+
+				if reason == "" {
+					continue
+				}
+
+				statuses = append(statuses, &ACMEStatus{
+					Namespace:       ingress.Namespace,
+					Ingress:         ingress.Name,
+					Host:            host,
+					Secret:          tls.SecretName,
+					CertReason:      reason,
+					ChallengeReason: c.challengeReason(ctx, host, solvers[host]),
+				})
+			}
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+
+		if statuses[i].Ingress != statuses[j].Ingress {
+			return statuses[i].Ingress < statuses[j].Ingress
+		}
+
+		return statuses[i].Host < statuses[j].Host
+	})
+
+	c.mu.Lock()
+	c.acme = statuses
+	c.mu.Unlock()
+}
+
+// acmeManaged returns whether cert-manager issues the certificates of an Ingress
+func acmeManaged(ingress *networkingv1.Ingress) bool {
+	if ingress.Labels[acmeSolverLabel] == "true" {
+		return false
+	}
+
+	for _, annotation := range acmeAnnotations {
+		if value, ok := ingress.Annotations[annotation]; ok && value != "" && value != "false" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// acmeSolverPaths returns the challenge paths served by the solver Ingresses, per host
+func acmeSolverPaths(ingresses []networkingv1.Ingress) map[string][]string {
+	paths := make(map[string][]string)
+
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		if ingress.Labels[acmeSolverLabel] != "true" {
+			continue
+		}
+
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+
+			host := strings.ToLower(rule.Host)
+			for _, path := range rule.HTTP.Paths {
+				if strings.HasPrefix(path.Path, acmeChallengePrefix) {
+					paths[host] = append(paths[host], path.Path)
+				}
+			}
+		}
+	}
+
+	return paths
+}
+
+// pendingReason returns why the certificate of a host is pending, or an empty
+// string when the secret holds a valid certificate for the host. Secrets are
+// fetched once per cycle and cached in secrets (nil when missing).
+func (c *Collector) pendingReason(
+	ctx context.Context,
+	secrets map[string]*corev1.Secret,
+	namespace, secretName, host string,
+) (string, error) {
+	if secretName == "" {
+		return certPendingMissing, nil
+	}
+
+	key := namespace + "/" + secretName
+
+	secret, ok := secrets[key]
+	if !ok {
+		var err error
+
+		secret, err = c.client.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			secret = nil
+		} else if err != nil {
+			return "", err
+		}
+
+		secrets[key] = secret
+	}
+
+	if secret == nil {
+		return certPendingMissing, nil
+	}
+
+	return secretReason(secret, host, time.Now()), nil
+}
+
+// secretReason returns why the certificate stored in a TLS secret is not
+// valid for host at now, or an empty string when it is
+func secretReason(secret *corev1.Secret, host string, now time.Time) string {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return certPendingInvalid
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return certPendingInvalid
+	}
+
+	if now.After(cert.NotAfter) {
+		return certPendingExpired
+	}
+
+	if cert.VerifyHostname(host) != nil {
+		return certPendingHostMismatch
+	}
+
+	return ""
+}
+
+// challengeReason probes the challenge paths of a host and returns why the
+// challenge is not reachable, or an empty string when every path answers
+func (c *Collector) challengeReason(ctx context.Context, host string, paths []string) string {
+	if len(paths) == 0 {
+		return challengeNoSolver
+	}
+
+	for _, path := range paths {
+		var (
+			status int
+			err    error
+		)
+
+		url := "http://" + host + path
+		header, requestID := c.checker.probeHeader()
+		start := time.Now()
+
+		c.checker.runCheck(ctx, func(checkCtx context.Context) {
+			status, err = c.acmeProbe(checkCtx, url, header)
+		})
+
+		record := auditRecord{
+			requestID:  requestID,
+			request:    requestACME,
+			target:     url,
+			duration:   time.Since(start),
+			success:    err == nil,
+			statusCode: status,
+		}
+		if err != nil {
+			record.err = err.Error()
+		}
+
+		c.checker.recordRequest(record)
+
+		if reason := classifyChallenge(status, err); reason != "" {
+			c.logger.WithFields(log.Fields{
+				"host":   host,
+				"path":   path,
+				"status": status,
+				"error":  err,
+			}).Debug("ACME challenge not reachable")
+
+			return reason
+		}
+	}
+
+	return ""
+}
+
+// classifyChallenge classifies the result of a challenge probe
+func classifyChallenge(status int, err error) string {
+	if err != nil {
+		var dnsErr *net.DNSError
+
+		switch {
+		case errors.As(err, &dnsErr):
+			return challengeDNS
+		case errors.Is(err, context.DeadlineExceeded) || isTimeout(err):
+			return challengeTimeout
+		default:
+			return challengeUnreachable
+		}
+	}
+
+	switch {
+	case status >= 200 && status < 300:
+		return ""
+	case status == http.StatusNotFound:
+		return challengeNotFound
+	case status >= 300 && status < 400:
+		return challengeRedirect
+	default:
+		return challengeHTTPError
+	}
+}
+
+// isTimeout returns whether err is a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// collectACME emits the pending certificates and the reachability of their challenges.
+// Must be called with c.mu held.
+func (c *Collector) collectACME(ch chan<- prometheus.Metric) {
+	for _, status := range c.acme {
+		ch <- prometheus.MustNewConstMetric(
+			c.acmeCertPending,
+			prometheus.GaugeValue,
+			1,
+			status.Namespace,
+			status.Ingress,
+			status.Host,
+			status.Secret,
+			status.CertReason,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.acmeChallengeReachable,
+			prometheus.GaugeValue,
+			boolToFloat64(status.ChallengeReason == ""),
+			status.Namespace,
+			status.Ingress,
+			status.Host,
+			status.ChallengeReason,
+		)
+	}
+}
@@ -0,0 +1,153 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// tlsSecret returns a TLS secret holding a self-signed certificate for host
+func tlsSecret(t *testing.T, namespace, name, host string) *corev1.Secret {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		},
+	}
+}
+
+func TestCheckACME(t *testing.T) {
+	client := fake.NewClientset(
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns-user1",
+				Name:        "web",
+				Annotations: map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{
+					{Hosts: []string{"pending.example.com"}, SecretName: "pending-tls"},
+					{Hosts: []string{"valid.example.com"}, SecretName: "valid-tls"},
+					{Hosts: []string{"other.example.com"}, SecretName: "valid-tls"},
+				},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns-user1",
+				Name:      "cm-acme-http-solver-abcde",
+				Labels:    map[string]string{acmeSolverLabel: "true"},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "pending.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{Path: acmeChallengePrefix + "token"}},
+					}},
+				}},
+			},
+		},
+		tlsSecret(t, "ns-user1", "valid-tls", "valid.example.com"),
+	)
+
+	var probed []string
+
+	c := &Collector{
+		config:  &Config{ACMECheck: true},
+		client:  client,
+		checker: NewDomainChecker(time.Second, false, true, false),
+		acmeProbe: func(_ context.Context, url string, _ http.Header) (int, error) {
+			probed = append(probed, url)
+			return http.StatusFound, nil
+		},
+		logger: log.NewEntry(log.StandardLogger()),
+	}
+
+	c.checkACME(context.Background())
+
+	expected := []ACMEStatus{
+		{
+			Namespace: "ns-user1", Ingress: "web", Host: "other.example.com", Secret: "valid-tls",
+			CertReason: certPendingHostMismatch, ChallengeReason: challengeNoSolver,
+		},
+		{
+			Namespace: "ns-user1", Ingress: "web", Host: "pending.example.com", Secret: "pending-tls",
+			CertReason: certPendingMissing, ChallengeReason: challengeRedirect,
+		},
+	}
+
+	if len(c.acme) != len(expected) {
+		t.Fatalf("Expected %d pending certificates, got %d", len(expected), len(c.acme))
+	}
+
+	for i, want := range expected {
+		if *c.acme[i] != want {
+			t.Errorf("Expected %+v, got %+v", want, *c.acme[i])
+		}
+	}
+
+	if len(probed) != 1 || probed[0] != "http://pending.example.com"+acmeChallengePrefix+"token" {
+		t.Errorf("Unexpected probed URLs %v", probed)
+	}
+}
+
+func TestClassifyChallenge(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   string
+	}{
+		{name: "reachable", status: http.StatusOK, want: ""},
+		{name: "not found", status: http.StatusNotFound, want: challengeNotFound},
+		{name: "redirect", status: http.StatusMovedPermanently, want: challengeRedirect},
+		{name: "server error", status: http.StatusBadGateway, want: challengeHTTPError},
+		{name: "dns", err: &net.DNSError{Err: "no such host", IsNotFound: true}, want: challengeDNS},
+		{name: "timeout", err: context.DeadlineExceeded, want: challengeTimeout},
+		{name: "refused", err: errors.New("connection refused"), want: challengeUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyChallenge(tt.status, tt.err); got != tt.want {
+				t.Errorf("classifyChallenge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// Outbound request types reported by the audit records
+const (
+	requestHTTP = "http" // HTTP check of a domain IP
+	requestTLS  = "tls"  // TLS handshake reading the domain certificate
+	requestACME = "acme" // HTTP-01 challenge probe
+)
+
+// auditRecord is the record of an outbound request, logged for egress accountability
+type auditRecord struct {
+	requestID  string
+	request    string // http, tls or acme
+	target     string // URL or host:port requested
+	ip         string // IP dialed, empty when resolved by the request
+	duration   time.Duration
+	success    bool
+	statusCode int
+	err        string
+}
+
+// probeHeader returns the headers of an outbound probe request along with
+// its request ID
+func (dc *DomainChecker) probeHeader() (http.Header, string) {
+	requestID := string(uuid.NewUUID())
+
+	header := http.Header{}
+	if dc.userAgent != "" {
+		header.Set("User-Agent", dc.userAgent)
+	}
+
+	if dc.requestIDHeader != "" {
+		header.Set(dc.requestIDHeader, requestID)
+	}
+
+	return header, requestID
+}
+
+// recordRequest passes the record of an outbound request to the audit sink, if any
+func (dc *DomainChecker) recordRequest(record auditRecord) {
+	if dc.audit != nil {
+		dc.audit(record)
+	}
+}
+
+// auditRequest logs the structured audit record of an outbound request
+func (c *Collector) auditRequest(record auditRecord) {
+	outcome := "success"
+	if !record.success {
+		outcome = "failure"
+	}
+
+	fields := log.Fields{
+		"audit":     "egress",
+		"requestID": record.requestID,
+		"request":   record.request,
+		"target":    record.target,
+		"source":    c.identity,
+		"duration":  record.duration.Seconds(),
+		"outcome":   outcome,
+	}
+
+	if record.ip != "" {
+		fields["ip"] = record.ip
+	}
+
+	if record.statusCode != 0 {
+		fields["statusCode"] = record.statusCode
+	}
+
+	if record.err != "" {
+		fields["error"] = record.err
+	}
+
+	c.logger.WithFields(fields).Info("Outbound probe request")
+}
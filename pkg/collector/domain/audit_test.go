@@ -0,0 +1,71 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestProbeHeader(t *testing.T) {
+	dc := &DomainChecker{userAgent: "sealos-state-metrics", requestIDHeader: "X-Request-Id"}
+
+	header, requestID := dc.probeHeader()
+	if requestID == "" || header.Get("X-Request-Id") != requestID {
+		t.Errorf("Expected the request ID header to carry %q, got %v", requestID, header)
+	}
+
+	if header.Get("User-Agent") != "sealos-state-metrics" {
+		t.Errorf("Expected the configured User-Agent, got %v", header)
+	}
+
+	if _, other := dc.probeHeader(); other == requestID {
+		t.Error("Expected a new request ID per probe")
+	}
+
+	header, _ = (&DomainChecker{}).probeHeader()
+	if len(header) != 0 {
+		t.Errorf("Expected no header when neither is configured, got %v", header)
+	}
+}
+
+func TestAuditRequest(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	c := &Collector{identity: "node-1", logger: log.NewEntry(logger)}
+
+	c.auditRequest(auditRecord{
+		requestID:  "42",
+		request:    requestHTTP,
+		target:     "https://example.com/",
+		ip:         "10.0.0.1",
+		duration:   1500 * time.Millisecond,
+		statusCode: 503,
+		err:        "",
+	})
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("Expected an audit record to be logged")
+	}
+
+	expected := log.Fields{
+		"audit":      "egress",
+		"requestID":  "42",
+		"request":    requestHTTP,
+		"target":     "https://example.com/",
+		"source":     "node-1",
+		"ip":         "10.0.0.1",
+		"duration":   1.5,
+		"outcome":    "failure",
+		"statusCode": 503,
+	}
+
+	for key, value := range expected {
+		if entry.Data[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, entry.Data[key])
+		}
+	}
+}
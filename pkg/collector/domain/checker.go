@@ -2,20 +2,28 @@ package domain
 
 import (
 	"context"
+	"net"
 	"time"
 
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/faults"
 	"github.com/labring/sealos-state-metrics/pkg/util"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/uuid"
 )
 
 // DomainHealth represents the overall health status of a domain
 type DomainHealth struct {
 	Domain       string
-	ResolveOk    bool // Whether DNS resolution succeeded
-	IPCount      int  // Number of IPs resolved
-	HealthyIPs   int  // Number of healthy IPs (HTTP and/or Cert checks passed)
-	UnhealthyIPs int  // Number of unhealthy IPs
-	LastChecked  time.Time
+	ResolveOk    bool          // Whether DNS resolution succeeded
+	ResolveTime  time.Duration // Time spent resolving the domain (zero when not resolved)
+	IPCount      int           // Number of IPs resolved
+	HealthyIPs   int           // Number of healthy IPs (HTTP and/or Cert checks passed)
+	UnhealthyIPs int           // Number of unhealthy IPs
+	// TLSPorts is the outcome of the certificate check on each TLS port
+	// declared for the domain besides 443
+	TLSPorts    []PortCert
+	LastChecked time.Time
 }
 
 // IPHealth represents the health status of a specific IP for a domain
@@ -27,24 +35,65 @@ type IPHealth struct {
 	HTTPOk        bool
 	HTTPError     string
 	HTTPErrorType ErrorType // Classified error type
+	// HTTPCriterion is the success criterion failed by the response (status,
+	// body or redirects), empty when it succeeded or no response was received
+	HTTPCriterion string
 	ResponseTime  time.Duration
 
+	// Phases of the HTTP check, the IP being dialed directly
+	ConnectTime      time.Duration
+	TLSHandshakeTime time.Duration
+	FirstByteTime    time.Duration
+
+	// TLS posture observed by the HTTP check
+	TLSVersion  string
+	CipherSuite string
+	HSTS        bool
+
 	// Certificate check
 	CertOk        bool
 	CertError     string
 	CertErrorType ErrorType // Classified error type
 	CertExpiry    time.Duration
+	// CertChainExpiry is the time left before the first certificate of the
+	// presented chain expires, intermediates included
+	CertChainExpiry time.Duration
+	// CertNotAfter and CertFingerprint identify the presented leaf, set
+	// whenever a certificate was presented, valid or not
+	CertNotAfter    time.Time
+	CertFingerprint string
+
+	// Ports is the outcome of the HTTP check on each probed port. The first
+	// port fills the HTTP fields above; a failure on another port fails the IP.
+	Ports []PortHealth
 
 	LastChecked time.Time
 }
 
 // DomainChecker performs health checks on domains
 type DomainChecker struct {
-	timeout    time.Duration
+	timeout    time.Duration // per-check timeout, nested in the cycle deadline
 	checkHTTP  bool
 	checkDNS   bool
 	checkCert  bool
 	classifier *ErrorClassifier
+
+	// criteria are the HTTP success criteria of the hosts not overriding them
+	criteria *httpCriteria
+
+	// onCanceled is called with the context of every finished check so
+	// checks canceled by a deadline can be counted (optional)
+	onCanceled func(ctx context.Context)
+
+	// userAgent and requestIDHeader are set on outbound HTTP probes (optional)
+	userAgent       string
+	requestIDHeader string
+
+	// audit is called with the record of every outbound request (optional)
+	audit func(record auditRecord)
+
+	// dnsServer (host:port) answers the DNS record queries
+	dnsServer string
 }
 
 // NewDomainChecker creates a new domain checker
@@ -55,13 +104,20 @@ func NewDomainChecker(timeout time.Duration, checkHTTP, checkDNS, checkCert bool
 		checkDNS:   checkDNS,
 		checkCert:  checkCert,
 		classifier: NewErrorClassifier(),
+		criteria:   &httpCriteria{maxRedirects: util.DefaultMaxRedirects},
 	}
 }
 
-// CheckIPs performs all enabled checks on a domain for each of its IPs
+// CheckIPs performs all enabled checks on a domain for each of its IPs. The
+// HTTP check runs on each endpoint, or on https:443 when none is given, and
+// succeeds on the criteria of the domain (nil = checker criteria). The
+// certificate is checked on 443 and on each of tlsPorts.
 func (dc *DomainChecker) CheckIPs(
 	ctx context.Context,
 	domain string,
+	endpoints []probeEndpoint,
+	tlsPorts []string,
+	criteria *httpCriteria,
 	logger *log.Entry,
 ) (*DomainHealth, []*IPHealth) {
 	now := time.Now()
@@ -72,10 +128,31 @@ func (dc *DomainChecker) CheckIPs(
 		LastChecked: now,
 	}
 
+	// Faults armed through the debug server fail (or delay) the check
+	if err := faults.Inject(ctx, collectorName, domain); err != nil {
+		logger.WithError(err).WithField("domain", domain).Warn("Domain check failed by fault injection")
+
+		return domainHealth, []*IPHealth{
+			{
+				Domain:        domain,
+				IP:            "",
+				HTTPOk:        false,
+				HTTPError:     err.Error(),
+				HTTPErrorType: dc.classifier.ClassifyHTTPError(err.Error()),
+				LastChecked:   now,
+			},
+		}
+	}
+
 	// First, get the IPs for the domain
 	var ips []string
 	if dc.checkDNS || dc.checkHTTP {
-		dnsResult := util.CheckDNS(ctx, domain, dc.timeout)
+		var dnsResult *util.DNSCheckResult
+
+		dc.runCheck(ctx, func(checkCtx context.Context) {
+			dnsResult = util.CheckDNS(checkCtx, domain)
+		})
+
 		if !dnsResult.Success {
 			logger.WithFields(log.Fields{
 				"domain": domain,
@@ -101,6 +178,7 @@ func (dc *DomainChecker) CheckIPs(
 		}
 
 		ips = dnsResult.IPs
+		domainHealth.ResolveTime = dnsResult.Duration
 
 		// Check if IP list is empty
 		if len(ips) == 0 {
@@ -133,69 +211,17 @@ func (dc *DomainChecker) CheckIPs(
 	)
 
 	if dc.checkCert {
-		certInfo, certErr = util.GetTLSCert(domain, dc.timeout)
+		certInfo, certErr = dc.fetchCert(ctx, domain, "", defaultEndpoint.port)
+
+		for _, port := range tlsPorts {
+			domainHealth.TLSPorts = append(domainHealth.TLSPorts, dc.checkPortCert(ctx, domain, port, logger))
+		}
 	}
 
 	// Check each IP individually
 	results := make([]*IPHealth, 0, len(ips))
 	for _, ip := range ips {
-		health := &IPHealth{
-			Domain:      domain,
-			IP:          ip,
-			LastChecked: now,
-		}
-
-		// HTTP check for this specific IP
-		if dc.checkHTTP {
-			result := util.CheckHTTPWithIP(ctx, domain, ip, dc.timeout)
-			health.HTTPOk = result.Success
-			health.HTTPError = result.Error
-			health.ResponseTime = result.ResponseTime
-
-			// Classify HTTP error
-			if !health.HTTPOk && health.HTTPError != "" {
-				health.HTTPErrorType = dc.classifier.ClassifyHTTPError(health.HTTPError)
-			} else {
-				health.HTTPErrorType = ErrorTypeNone
-			}
-
-			logger.WithFields(log.Fields{
-				"domain":       domain,
-				"ip":           ip,
-				"success":      health.HTTPOk,
-				"errorType":    health.HTTPErrorType,
-				"responseTime": health.ResponseTime,
-			}).Debug("HTTP check completed")
-		}
-
-		// Certificate check (same for all IPs)
-		if dc.checkCert {
-			if certErr != nil {
-				health.CertOk = false
-				health.CertError = certErr.Error()
-				health.CertErrorType = dc.classifier.ClassifyCertError(health.CertError)
-			} else {
-				health.CertOk = certInfo.IsValid
-				health.CertExpiry = certInfo.ExpiresIn
-
-				if !certInfo.IsValid {
-					health.CertError = "certificate expired or not yet valid"
-					health.CertErrorType = ErrorTypeCertExpired
-				} else {
-					health.CertErrorType = ErrorTypeNone
-				}
-			}
-
-			logger.WithFields(log.Fields{
-				"domain":    domain,
-				"ip":        ip,
-				"success":   health.CertOk,
-				"errorType": health.CertErrorType,
-				"expiresIn": health.CertExpiry,
-			}).Debug("Certificate check completed")
-		}
-
-		results = append(results, health)
+		results = append(results, dc.checkIP(ctx, domain, ip, endpoints, criteria, now, certInfo, certErr, logger))
 	}
 
 	// Calculate domain-level health metrics
@@ -223,3 +249,199 @@ func (dc *DomainChecker) CheckIPs(
 
 	return domainHealth, results
 }
+
+// fetchCert retrieves the certificate presented for a domain on a port,
+// through a specific IP when ip is set, and records the request
+func (dc *DomainChecker) fetchCert(ctx context.Context, domain, ip, port string) (*util.CertInfo, error) {
+	var (
+		certInfo *util.CertInfo
+		certErr  error
+	)
+
+	start := time.Now()
+
+	dc.runCheck(ctx, func(checkCtx context.Context) {
+		if ip != "" {
+			certInfo, certErr = util.GetTLSCertWithIP(checkCtx, domain, ip, port)
+		} else {
+			certInfo, certErr = util.GetTLSCert(checkCtx, domain, port)
+		}
+	})
+
+	record := auditRecord{
+		requestID: string(uuid.NewUUID()),
+		request:   requestTLS,
+		target:    net.JoinHostPort(domain, port),
+		ip:        ip,
+		duration:  time.Since(start),
+		success:   certErr == nil,
+	}
+	if certErr != nil {
+		record.err = certErr.Error()
+	}
+
+	dc.recordRequest(record)
+
+	return certInfo, certErr
+}
+
+// checkIP performs the enabled HTTP check of a domain through a specific IP
+// on each endpoint (https:443 when none is given), and reports the
+// certificate check outcome (certInfo or certErr)
+func (dc *DomainChecker) checkIP(
+	ctx context.Context,
+	domain, ip string,
+	endpoints []probeEndpoint,
+	criteria *httpCriteria,
+	now time.Time,
+	certInfo *util.CertInfo,
+	certErr error,
+	logger *log.Entry,
+) *IPHealth {
+	health := &IPHealth{
+		Domain:      domain,
+		IP:          ip,
+		LastChecked: now,
+	}
+
+	// HTTP check for this specific IP
+	if dc.checkHTTP {
+		if len(endpoints) == 0 {
+			endpoints = []probeEndpoint{defaultEndpoint}
+		}
+
+		if criteria == nil {
+			criteria = dc.criteria
+		}
+
+		for i, endpoint := range endpoints {
+			result, criterion := dc.probeHTTP(ctx, domain, ip, endpoint, criteria)
+
+			// Classify HTTP error
+			errorType := ErrorTypeNone
+
+			switch {
+			case criterion != "":
+				errorType = criterionErrorType(criterion, result.StatusCode)
+			case !result.Success && result.Error != "":
+				errorType = dc.classifier.ClassifyHTTPError(result.Error)
+			}
+
+			health.Ports = append(health.Ports, PortHealth{
+				Port:          endpoint.port,
+				HTTPOk:        result.Success,
+				HTTPErrorType: errorType,
+			})
+
+			switch {
+			case i == 0:
+				health.HTTPOk = result.Success
+				health.HTTPError = result.Error
+				health.HTTPErrorType = errorType
+				health.HTTPCriterion = criterion
+				health.ResponseTime = result.ResponseTime
+				health.ConnectTime = result.Phases.Connect
+				health.TLSHandshakeTime = result.Phases.TLSHandshake
+				health.FirstByteTime = result.Phases.FirstByte
+				health.TLSVersion = result.TLSVersion
+				health.CipherSuite = result.CipherSuite
+				health.HSTS = result.HSTS
+			case !result.Success && health.HTTPOk:
+				health.HTTPOk = false
+				health.HTTPError = result.Error
+				health.HTTPErrorType = errorType
+				health.HTTPCriterion = criterion
+			}
+
+			logger.WithFields(log.Fields{
+				"domain":       domain,
+				"ip":           ip,
+				"port":         endpoint.port,
+				"success":      result.Success,
+				"errorType":    errorType,
+				"criterion":    criterion,
+				"responseTime": result.ResponseTime,
+				"connect":      result.Phases.Connect,
+				"tlsHandshake": result.Phases.TLSHandshake,
+				"firstByte":    result.Phases.FirstByte,
+			}).Debug("HTTP check completed")
+		}
+	}
+
+	// Certificate check (same for all IPs)
+	if dc.checkCert {
+		if certErr != nil {
+			health.CertOk = false
+			health.CertError = certErr.Error()
+			health.CertErrorType = dc.classifier.ClassifyCertError(health.CertError)
+		} else {
+			health.CertOk = certInfo.IsValid
+			health.CertExpiry = certInfo.ExpiresIn
+			health.CertChainExpiry = certInfo.ChainExpiresIn
+			health.CertNotAfter = certInfo.NotAfter
+			health.CertFingerprint = certInfo.Fingerprint
+
+			if !certInfo.IsValid {
+				health.CertError = "certificate expired or not yet valid"
+				health.CertErrorType = ErrorTypeCertExpired
+			} else {
+				health.CertErrorType = ErrorTypeNone
+			}
+		}
+
+		logger.WithFields(log.Fields{
+			"domain":    domain,
+			"ip":        ip,
+			"success":   health.CertOk,
+			"errorType": health.CertErrorType,
+			"expiresIn": health.CertExpiry,
+		}).Debug("Certificate check completed")
+	}
+
+	return health
+}
+
+// probeHTTP performs the HTTP check of a domain through a specific IP on an
+// endpoint, applies the success criteria and records the request. The failing
+// criterion is returned along with the result.
+func (dc *DomainChecker) probeHTTP(
+	ctx context.Context,
+	domain, ip string,
+	endpoint probeEndpoint,
+	criteria *httpCriteria,
+) (*util.HTTPCheckResult, string) {
+	var result *util.HTTPCheckResult
+
+	header, requestID := dc.probeHeader()
+
+	dc.runCheck(ctx, func(checkCtx context.Context) {
+		result = util.CheckHTTPWithIPPort(checkCtx, endpoint.scheme, domain, ip, endpoint.port, criteria.options(header))
+	})
+
+	criterion := criteria.evaluate(result)
+
+	dc.recordRequest(auditRecord{
+		requestID:  requestID,
+		request:    requestHTTP,
+		target:     endpoint.scheme + "://" + util.HostPort(endpoint.scheme, domain, endpoint.port) + "/",
+		ip:         ip,
+		duration:   result.ResponseTime,
+		success:    result.Success,
+		statusCode: result.StatusCode,
+		err:        result.Error,
+	})
+
+	return result, criterion
+}
+
+// runCheck runs a single check bounded by the per-check timeout
+func (dc *DomainChecker) runCheck(ctx context.Context, check func(checkCtx context.Context)) {
+	checkCtx, cancel := base.WithCheckTimeout(ctx, dc.timeout)
+	defer cancel()
+
+	check(checkCtx)
+
+	if dc.onCanceled != nil {
+		dc.onCanceled(checkCtx)
+	}
+}
@@ -11,13 +11,14 @@ type ErrorType string
 
 const (
 	// HTTP errors
-	ErrorTypeConnectionRefused ErrorType = "ConnectionRefused"
-	ErrorTypeTimeout           ErrorType = "Timeout"
-	ErrorTypeDNSError          ErrorType = "DNSError"
-	ErrorTypeHTTPClientError   ErrorType = "HTTPClientError" // 4xx
-	ErrorTypeHTTPServerError   ErrorType = "HTTPServerError" // 5xx
-	ErrorTypeSSLError          ErrorType = "SSLError"
-	ErrorTypeNetworkError      ErrorType = "NetworkError"
+	ErrorTypeConnectionRefused  ErrorType = "ConnectionRefused"
+	ErrorTypeTimeout            ErrorType = "Timeout"
+	ErrorTypeDNSError           ErrorType = "DNSError"
+	ErrorTypeHTTPClientError    ErrorType = "HTTPClientError" // 4xx
+	ErrorTypeHTTPServerError    ErrorType = "HTTPServerError" // 5xx
+	ErrorTypeSSLError           ErrorType = "SSLError"
+	ErrorTypeNetworkError       ErrorType = "NetworkError"
+	ErrorTypeUnexpectedResponse ErrorType = "UnexpectedResponse" // Expected status, body or redirects not met
 
 	// Certificate errors
 	ErrorTypeCertExpired          ErrorType = "CertExpired"
@@ -38,7 +39,7 @@ var (
 
 	timeoutPatterns = []*regexp.Regexp{
 		regexp.MustCompile(`(?i)timeout`),
-		regexp.MustCompile(`(?i)context deadline exceeded`),
+		regexp.MustCompile(`(?i)deadline exceeded`), // context and per-check deadlines
 		regexp.MustCompile(`(?i)i/o timeout`),
 		regexp.MustCompile(`(?i)request timeout`),
 	}
@@ -2,25 +2,105 @@ package domain
 
 import (
 	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
 )
 
 // Config contains configuration for the Domain collector
 type Config struct {
 	Domains          []string      `yaml:"domains"          env:"DOMAINS"            envSeparator:","`
-	CheckTimeout     time.Duration `yaml:"checkTimeout"     env:"CHECK_TIMEOUT"`
+	CheckTimeout     time.Duration `yaml:"checkTimeout"     env:"CHECK_TIMEOUT"` // Per-check (DNS, HTTP, TLS) timeout
+	CycleTimeout     time.Duration `yaml:"cycleTimeout"     env:"CYCLE_TIMEOUT"` // Collector-level timeout of a check cycle (0 = global only)
 	CheckInterval    time.Duration `yaml:"checkInterval"    env:"CHECK_INTERVAL"`
 	IncludeCertCheck bool          `yaml:"includeCertCheck" env:"INCLUDE_CERT_CHECK"`
 	IncludeHTTPCheck bool          `yaml:"includeHTTPCheck" env:"INCLUDE_HTTP_CHECK"`
+	HistorySize      int           `yaml:"historySize"      env:"HISTORY_SIZE"` // Check results kept per domain for /api/v1/history (0 = disabled)
+
+	// VIPs are gateway IPs every domain is also checked through, bypassing
+	// DNS, with the domain as SNI and Host header
+	VIPs []string `yaml:"vips" env:"VIPS" envSeparator:","`
+
+	// UserAgent is the User-Agent of the outbound HTTP probes
+	UserAgent string `yaml:"userAgent"       env:"USER_AGENT"`
+	// RequestIDHeader carries a unique ID per outbound HTTP probe (empty = not sent)
+	RequestIDHeader string `yaml:"requestIDHeader" env:"REQUEST_ID_HEADER"`
+	// ExpectedStatus lists the status codes and ranges the HTTP check
+	// succeeds on (e.g. 200-299,401; empty = any status below 500)
+	ExpectedStatus string `yaml:"expectedStatus"  env:"EXPECTED_STATUS"`
+	// ExpectedBody is a regular expression the start of the response body
+	// must match (empty = body not checked)
+	ExpectedBody string `yaml:"expectedBody"    env:"EXPECTED_BODY"`
+	// MaxRedirects is the number of redirects the HTTP check follows; a
+	// response still redirecting after them fails the check
+	MaxRedirects int `yaml:"maxRedirects"    env:"MAX_REDIRECTS"`
+	// AuditLog logs a structured record of every outbound request (target,
+	// source, duration and outcome)
+	AuditLog bool `yaml:"auditLog"        env:"AUDIT_LOG"`
+
+	// DiscoverServices also checks the hosts of the URLs annotated on Services (probe.sealos.io/url)
+	DiscoverServices bool `yaml:"discoverServices"    env:"DISCOVER_SERVICES"`
+	// DiscoveryConfigMaps are ConfigMaps (namespace/name) listing URLs to check, one per line
+	DiscoveryConfigMaps []string `yaml:"discoveryConfigMaps" env:"DISCOVERY_CONFIG_MAPS" envSeparator:","`
+	// DiscoverIngresses also checks the rule hosts of Ingresses. A host shared by
+	// several Ingresses is checked once and its result reported for each of them.
+	DiscoverIngresses bool `yaml:"discoverIngresses"   env:"DISCOVER_INGRESSES"`
+	// InferIngressPorts checks the IPs of Ingress hosts on the ports of their
+	// backend Services, over https for TLS hosts, instead of 443
+	InferIngressPorts bool `yaml:"inferIngressPorts"   env:"INFER_INGRESS_PORTS"`
+	// MaintenanceMode applies the maintenance windows annotated on Ingresses
+	// (sealos.io/maintenance-window) to the series of their hosts: "label"
+	// adds in_maintenance="true", "suppress" drops them
+	MaintenanceMode string `yaml:"maintenanceMode"     env:"MAINTENANCE_MODE"`
+
+	// ACMECheck probes the HTTP-01 challenges of cert-manager annotated Ingresses
+	// whose certificate is missing or invalid
+	ACMECheck bool `yaml:"acmeCheck" env:"ACME_CHECK"`
+
+	// NamespaceIntervals overrides the check interval of the hosts discovered
+	// in a namespace (key: namespace)
+	NamespaceIntervals map[string]time.Duration `yaml:"namespaceIntervals" env:"NAMESPACE_INTERVALS"`
+	// FailureRetryInterval re-checks the failing domains more often than their
+	// interval (0 = disabled)
+	FailureRetryInterval time.Duration `yaml:"failureRetryInterval" env:"FAILURE_RETRY_INTERVAL"`
+	// FailureRetryBudget bounds the number of failing domains re-checked per
+	// retry (0 = unbounded)
+	FailureRetryBudget int `yaml:"failureRetryBudget"   env:"FAILURE_RETRY_BUDGET"`
+
+	// DNSRecords are record checks of the targets, e.g. a CNAME to the
+	// gateway; hosts of Ingresses may also annotate them
+	// (probe.sealos.io/dns-records). Only configurable through the config file.
+	DNSRecords []DNSRecordCheck `yaml:"dnsRecords"`
+	// DNSServer (host:port) answers the record queries (empty = first
+	// nameserver of /etc/resolv.conf)
+	DNSServer string `yaml:"dnsServer" env:"DNS_SERVER"`
+
+	// Quorum runs the checks on every instance instead of the leader only, and
+	// marks a domain down only when a quorum of instances agree. Instances
+	// exchange their results through ConfigMaps in QuorumNamespace.
+	Quorum          bool          `yaml:"quorum"          env:"QUORUM"`
+	QuorumNamespace string        `yaml:"quorumNamespace" env:"QUORUM_NAMESPACE"`
+	QuorumSize      int           `yaml:"quorumSize"      env:"QUORUM_SIZE"`    // Down votes needed (0 = majority of reporting instances)
+	QuorumMaxAge    time.Duration `yaml:"quorumMaxAge"    env:"QUORUM_MAX_AGE"` // Reports older than this are ignored (0 = 3x checkInterval)
 }
 
 // NewDefaultConfig returns the default configuration for Domain collector
 // This function only returns hard-coded defaults without any env parsing
 func NewDefaultConfig() *Config {
 	return &Config{
-		Domains:          []string{},
-		CheckTimeout:     5 * time.Second,
-		CheckInterval:    5 * time.Minute,
-		IncludeCertCheck: true,
-		IncludeHTTPCheck: true,
+		Domains:             []string{},
+		CheckTimeout:        5 * time.Second,
+		CheckInterval:       5 * time.Minute,
+		IncludeCertCheck:    true,
+		IncludeHTTPCheck:    true,
+		HistorySize:         20,
+		VIPs:                []string{},
+		UserAgent:           "sealos-state-metrics",
+		RequestIDHeader:     "X-Request-Id",
+		MaxRedirects:        util.DefaultMaxRedirects,
+		DiscoveryConfigMaps: []string{},
+		NamespaceIntervals:  map[string]time.Duration{},
+		MaintenanceMode:     maintenanceModeLabel,
+		FailureRetryBudget:  20,
+		DNSRecords:          []DNSRecordCheck{},
 	}
 }
@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	log "github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// Annotations overriding the HTTP success criteria of the hosts of an Ingress
+const (
+	// ExpectedStatusAnnotation lists the status codes and ranges the HTTP
+	// check succeeds on (e.g. 200-299,401)
+	ExpectedStatusAnnotation = "probe.sealos.io/expected-status"
+	// ExpectedBodyAnnotation is a regular expression the start of the
+	// response body must match
+	ExpectedBodyAnnotation = "probe.sealos.io/expected-body"
+	// MaxRedirectsAnnotation is the number of redirects the HTTP check follows
+	MaxRedirectsAnnotation = "probe.sealos.io/max-redirects"
+)
+
+// Success criteria failing an HTTP check, reported by the criterion label
+const (
+	criterionStatus    = "status"
+	criterionBody      = "body"
+	criterionRedirects = "redirects"
+)
+
+// maxBodyBytes bounds the start of the response body matched against the
+// expected body
+const maxBodyBytes = 64 << 10
+
+// statusRange is an inclusive range of HTTP status codes
+type statusRange struct {
+	low, high int
+}
+
+// httpCriteria are the success criteria of the HTTP check
+type httpCriteria struct {
+	statuses     []statusRange  // empty = any status below 500
+	body         *regexp.Regexp // nil = body not checked
+	maxRedirects int
+}
+
+// newHTTPCriteria parses the success criteria of the HTTP check
+func newHTTPCriteria(expectedStatus, expectedBody string, maxRedirects int) (*httpCriteria, error) {
+	statuses, err := parseStatusRanges(expectedStatus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected status %q: %w", expectedStatus, err)
+	}
+
+	if maxRedirects < 0 {
+		return nil, fmt.Errorf("invalid max redirects %d: must not be negative", maxRedirects)
+	}
+
+	criteria := &httpCriteria{statuses: statuses, maxRedirects: maxRedirects}
+
+	if expectedBody != "" {
+		criteria.body, err = regexp.Compile(expectedBody)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected body: %w", err)
+		}
+	}
+
+	return criteria, nil
+}
+
+// parseStatusRanges parses a comma separated list of status codes and
+// ranges (200-299,401)
+func parseStatusRanges(value string) ([]statusRange, error) {
+	var ranges []statusRange
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lowRaw, highRaw, isRange := strings.Cut(part, "-")
+
+		low, err := strconv.Atoi(strings.TrimSpace(lowRaw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", lowRaw)
+		}
+
+		high := low
+
+		if isRange {
+			high, err = strconv.Atoi(strings.TrimSpace(highRaw))
+			if err != nil {
+				return nil, fmt.Errorf("invalid status code %q", highRaw)
+			}
+		}
+
+		if low < 100 || high > 599 || low > high {
+			return nil, errors.New("status codes must be within 100-599")
+		}
+
+		ranges = append(ranges, statusRange{low: low, high: high})
+	}
+
+	return ranges, nil
+}
+
+// options returns the options of an HTTP check sending header
+func (hc *httpCriteria) options(header http.Header) util.HTTPCheckOptions {
+	opts := util.HTTPCheckOptions{
+		Header:       header,
+		MaxRedirects: hc.maxRedirects,
+	}
+
+	if hc.body != nil {
+		opts.BodyLimit = maxBodyBytes
+	}
+
+	return opts
+}
+
+// evaluate applies the criteria to the result of a completed request and
+// returns the failing criterion, empty when the check succeeds or the
+// request did not complete
+func (hc *httpCriteria) evaluate(result *util.HTTPCheckResult) string {
+	switch {
+	case result.TooManyRedirects:
+		result.Success = false
+		result.Error = fmt.Sprintf("stopped after %d redirects (status %d)", hc.maxRedirects, result.StatusCode)
+
+		return criterionRedirects
+	case result.Error != "" || result.StatusCode == 0:
+		return ""
+	case !hc.expectedStatus(result.StatusCode):
+		result.Success = false
+		result.Error = fmt.Sprintf("unexpected status code %d", result.StatusCode)
+
+		return criterionStatus
+	case hc.body != nil && !hc.body.Match(result.Body):
+		result.Success = false
+		result.Error = fmt.Sprintf("response body does not match %q", hc.body.String())
+
+		return criterionBody
+	}
+
+	result.Success = true
+
+	return ""
+}
+
+// expectedStatus returns whether the status code satisfies the criteria
+func (hc *httpCriteria) expectedStatus(code int) bool {
+	if len(hc.statuses) == 0 {
+		return code < 500
+	}
+
+	for _, r := range hc.statuses {
+		if code >= r.low && code <= r.high {
+			return true
+		}
+	}
+
+	return false
+}
+
+// criterionErrorType returns the error type of a check failing a criterion:
+// the HTTP error class of 4xx and 5xx status codes, UnexpectedResponse otherwise
+func criterionErrorType(criterion string, statusCode int) ErrorType {
+	if criterion == criterionStatus {
+		switch {
+		case statusCode >= 500:
+			return ErrorTypeHTTPServerError
+		case statusCode >= 400:
+			return ErrorTypeHTTPClientError
+		}
+	}
+
+	return ErrorTypeUnexpectedResponse
+}
+
+// ingressCriteria returns the success criteria of an Ingress: the
+// collector criteria overridden by the annotated ones, nil when none is
+// annotated. Invalid annotations are ignored.
+func (c *Collector) ingressCriteria(ingress *networkingv1.Ingress) *httpCriteria {
+	status, hasStatus := ingress.Annotations[ExpectedStatusAnnotation]
+	body, hasBody := ingress.Annotations[ExpectedBodyAnnotation]
+	redirectsRaw, hasRedirects := ingress.Annotations[MaxRedirectsAnnotation]
+
+	if !hasStatus && !hasBody && !hasRedirects {
+		return nil
+	}
+
+	if !hasStatus {
+		status = c.config.ExpectedStatus
+	}
+
+	if !hasBody {
+		body = c.config.ExpectedBody
+	}
+
+	redirects := c.config.MaxRedirects
+
+	var err error
+	if hasRedirects {
+		redirects, err = strconv.Atoi(redirectsRaw)
+	}
+
+	var criteria *httpCriteria
+	if err == nil {
+		criteria, err = newHTTPCriteria(status, body, redirects)
+	}
+
+	if err != nil {
+		c.logger.WithError(err).WithFields(log.Fields{
+			"namespace": ingress.Namespace,
+			"ingress":   ingress.Name,
+		}).Warn("Ignoring invalid HTTP success criteria annotations")
+
+		return nil
+	}
+
+	return criteria
+}
+
+// hostCriteria returns the success criteria annotated on the first
+// Ingress listing a host, nil to apply the collector criteria
+func (c *Collector) hostCriteria(domain string) *httpCriteria {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, discovered := range c.discovered[sourceIngress] {
+		if discovered.host == domain && discovered.criteria != nil {
+			return discovered.criteria
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	ranges, err := parseStatusRanges("200-299, 401,418")
+	if err != nil {
+		t.Fatalf("Failed to parse status ranges: %v", err)
+	}
+
+	criteria := &httpCriteria{statuses: ranges}
+	for code, expected := range map[int]bool{200: true, 299: true, 301: false, 401: true, 404: false, 418: true} {
+		if got := criteria.expectedStatus(code); got != expected {
+			t.Errorf("expectedStatus(%d) = %v, want %v", code, got, expected)
+		}
+	}
+
+	for _, invalid := range []string{"2xx", "300-200", "99", "200-600"} {
+		if _, err := parseStatusRanges(invalid); err == nil {
+			t.Errorf("Expected error for %q, got nil", invalid)
+		}
+	}
+}
+
+// TestCheckIPCriteria verifies the failing criterion of each HTTP check
+func TestCheckIPCriteria(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			http.Redirect(w, r, "/app", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("<title>Maintenance</title>"))
+		}
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	endpoints := []probeEndpoint{{scheme: "http", port: port}}
+
+	tests := []struct {
+		name              string
+		expectedStatus    string
+		expectedBody      string
+		maxRedirects      int
+		expectedOk        bool
+		expectedCriterion string
+		expectedErrorType ErrorType
+	}{
+		{"defaults", "", "", 10, true, "", ErrorTypeNone},
+		{"redirects", "", "", 1, false, criterionRedirects, ErrorTypeUnexpectedResponse},
+		{"status", "204", "", 10, false, criterionStatus, ErrorTypeUnexpectedResponse},
+		{"body", "200", "Welcome", 10, false, criterionBody, ErrorTypeUnexpectedResponse},
+		{"all met", "200", "Maintenance", 10, true, "", ErrorTypeNone},
+	}
+
+	dc := NewDomainChecker(time.Second, true, true, false)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria, err := newHTTPCriteria(tt.expectedStatus, tt.expectedBody, tt.maxRedirects)
+			if err != nil {
+				t.Fatalf("Failed to parse criteria: %v", err)
+			}
+
+			health := dc.checkIP(
+				context.Background(),
+				"app.example.com",
+				"127.0.0.1",
+				endpoints,
+				criteria,
+				time.Now(),
+				nil,
+				nil,
+				log.NewEntry(log.StandardLogger()),
+			)
+
+			if health.HTTPOk != tt.expectedOk || health.HTTPCriterion != tt.expectedCriterion ||
+				health.HTTPErrorType != tt.expectedErrorType {
+				t.Errorf("Expected ok=%v criterion=%q error type %s, got ok=%v criterion=%q error type %s (%s)",
+					tt.expectedOk, tt.expectedCriterion, tt.expectedErrorType,
+					health.HTTPOk, health.HTTPCriterion, health.HTTPErrorType, health.HTTPError)
+			}
+		})
+	}
+}
+
+func TestCriterionErrorType(t *testing.T) {
+	if got := criterionErrorType(criterionStatus, 503); got != ErrorTypeHTTPServerError {
+		t.Errorf("Expected HTTPServerError for 503, got %s", got)
+	}
+
+	if got := criterionErrorType(criterionStatus, 404); got != ErrorTypeHTTPClientError {
+		t.Errorf("Expected HTTPClientError for 404, got %s", got)
+	}
+
+	if got := criterionErrorType(criterionStatus, 302); got != ErrorTypeUnexpectedResponse {
+		t.Errorf("Expected UnexpectedResponse for 302, got %s", got)
+	}
+}
+
+func TestIngressCriteria(t *testing.T) {
+	c := &Collector{
+		config: &Config{ExpectedStatus: "200-399", MaxRedirects: 10},
+		logger: log.NewEntry(log.StandardLogger()),
+	}
+
+	ingress := func(annotations map[string]string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns-a",
+			Name:        "web",
+			Annotations: annotations,
+		}}
+	}
+
+	if criteria := c.ingressCriteria(ingress(nil)); criteria != nil {
+		t.Errorf("Expected the collector criteria without annotations, got %+v", criteria)
+	}
+
+	criteria := c.ingressCriteria(ingress(map[string]string{
+		ExpectedBodyAnnotation: "ok",
+		MaxRedirectsAnnotation: "0",
+	}))
+	if criteria == nil {
+		t.Fatal("Expected criteria from the annotations")
+	}
+
+	if criteria.maxRedirects != 0 || criteria.body.String() != "ok" || !criteria.expectedStatus(302) || criteria.expectedStatus(404) {
+		t.Errorf("Expected the annotations to override the collector criteria, got %+v", criteria)
+	}
+
+	if criteria := c.ingressCriteria(ingress(map[string]string{MaxRedirectsAnnotation: "many"})); criteria != nil {
+		t.Errorf("Expected invalid annotations to be ignored, got %+v", criteria)
+	}
+}
@@ -0,0 +1,401 @@
+package domain
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProbeURLAnnotation marks Services to probe. The value is a URL, or a
+// comma-separated list of URLs, e.g. probe.sealos.io/url: https://api.example.com/healthz
+const ProbeURLAnnotation = "probe.sealos.io/url"
+
+// Discovery sources reported by the discovered targets metric
+const (
+	sourceService   = "service"
+	sourceConfigMap = "configmap"
+	sourceIngress   = "ingress"
+)
+
+// discoveredHost is a host discovered from the cluster, along with the
+// namespace of the Service, ConfigMap or Ingress listing it
+type discoveredHost struct {
+	host      string
+	namespace string
+	ingress   string // only set for the ingress source
+	// tlsSecret is the secret the Ingress serves the host with, only set for
+	// the ingress source
+	tlsSecret string
+	// endpoints are the ports inferred from the backend Services of the
+	// Ingress, only set with inferIngressPorts
+	endpoints []probeEndpoint
+	// maintenance are the windows annotated on the Ingress, only set for the
+	// ingress source
+	maintenance []maintenanceWindow
+	// criteria are the HTTP success criteria annotated on the Ingress, only
+	// set for the ingress source
+	criteria *httpCriteria
+	// dnsRecords are the DNS record checks annotated on the Ingress, only
+	// set for the ingress source
+	dnsRecords []DNSRecordCheck
+	// tlsPorts are the TLS ports annotated on the Ingress, only set for the
+	// ingress source
+	tlsPorts []string
+}
+
+// discoveryEnabled returns whether targets are discovered from the cluster
+func (c *Collector) discoveryEnabled() bool {
+	return c.config.DiscoverServices || len(c.config.DiscoveryConfigMaps) > 0 || c.config.DiscoverIngresses
+}
+
+// targets returns the sorted, deduplicated domains to check: the configured
+// domains and the hosts discovered from annotated Services, ConfigMaps and
+// Ingresses. A failing source keeps its last discovered hosts.
+func (c *Collector) targets(ctx context.Context) []string {
+	if c.discoveryEnabled() {
+		c.discover(ctx)
+	}
+
+	seen := make(map[string]struct{}, len(c.config.Domains))
+	for _, domain := range c.config.Domains {
+		seen[domain] = struct{}{}
+	}
+
+	c.mu.RLock()
+	for _, hosts := range c.discovered {
+		for _, discovered := range hosts {
+			seen[discovered.host] = struct{}{}
+		}
+	}
+	c.mu.RUnlock()
+
+	domains := make([]string, 0, len(seen))
+	for domain := range seen {
+		domains = append(domains, domain)
+	}
+
+	sort.Strings(domains)
+
+	return domains
+}
+
+// discover refreshes the hosts discovered from each source
+func (c *Collector) discover(ctx context.Context) {
+	if c.config.DiscoverServices {
+		hosts, err := c.discoverServices(ctx)
+		c.setDiscovered(sourceService, hosts, err)
+	}
+
+	if len(c.config.DiscoveryConfigMaps) > 0 {
+		hosts, err := c.discoverConfigMaps(ctx)
+		c.setDiscovered(sourceConfigMap, hosts, err)
+	}
+
+	if c.config.DiscoverIngresses {
+		hosts, err := c.discoverIngresses(ctx)
+		c.setDiscovered(sourceIngress, hosts, err)
+	}
+}
+
+// setDiscovered records the hosts discovered from a source, unless discovery failed
+func (c *Collector) setDiscovered(source string, hosts []discoveredHost, err error) {
+	if err != nil {
+		c.logger.WithError(err).WithField("source", source).
+			Warn("Target discovery failed, keeping previously discovered targets")
+
+		return
+	}
+
+	c.mu.Lock()
+	c.discovered[source] = hosts
+	c.mu.Unlock()
+}
+
+// collectDiscovered emits the number of hosts discovered per source.
+// Must be called with c.mu held.
+func (c *Collector) collectDiscovered(ch chan<- prometheus.Metric) {
+	sources := []string{}
+	if c.config.DiscoverServices {
+		sources = append(sources, sourceService)
+	}
+
+	if len(c.config.DiscoveryConfigMaps) > 0 {
+		sources = append(sources, sourceConfigMap)
+	}
+
+	if c.config.DiscoverIngresses {
+		sources = append(sources, sourceIngress)
+	}
+
+	for _, source := range sources {
+		ch <- prometheus.MustNewConstMetric(
+			c.discoveredTargets,
+			prometheus.GaugeValue,
+			float64(len(c.discovered[source])),
+			source,
+		)
+	}
+}
+
+// discoverServices returns the hosts of the URLs annotated on Services
+func (c *Collector) discoverServices(ctx context.Context) ([]discoveredHost, error) {
+	services, err := c.client.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var hosts []discoveredHost
+
+	for i := range services.Items {
+		service := &services.Items[i]
+
+		value, ok := service.Annotations[ProbeURLAnnotation]
+		if !ok {
+			continue
+		}
+
+		for _, rawURL := range strings.Split(value, ",") {
+			host, err := targetHost(rawURL)
+			if err != nil {
+				c.logger.WithError(err).WithFields(log.Fields{
+					"namespace": service.Namespace,
+					"service":   service.Name,
+				}).Debug("Ignoring invalid probe URL annotation")
+
+				continue
+			}
+
+			hosts = append(hosts, discoveredHost{host: host, namespace: service.Namespace})
+		}
+	}
+
+	return hosts, nil
+}
+
+// discoverConfigMaps returns the hosts of the URLs listed in the configured
+// ConfigMaps, one per line in any data key. Empty lines and # comments are ignored.
+func (c *Collector) discoverConfigMaps(ctx context.Context) ([]discoveredHost, error) {
+	var hosts []discoveredHost
+
+	for _, ref := range c.config.DiscoveryConfigMaps {
+		namespace, name, ok := strings.Cut(ref, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid ConfigMap reference %q (expected namespace/name)", ref)
+		}
+
+		configMap, err := c.client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s: %w", ref, err)
+		}
+
+		for _, data := range configMap.Data {
+			scanner := bufio.NewScanner(strings.NewReader(data))
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+
+				host, err := targetHost(line)
+				if err != nil {
+					c.logger.WithError(err).WithField("configMap", ref).
+						Debug("Ignoring invalid probe URL")
+
+					continue
+				}
+
+				hosts = append(hosts, discoveredHost{host: host, namespace: namespace})
+			}
+		}
+	}
+
+	return hosts, nil
+}
+
+// discoverIngresses returns the rule hosts of the Ingresses, once per Ingress.
+// Wildcard hosts cannot be checked and the solver Ingresses of cert-manager
+// are skipped.
+func (c *Collector) discoverIngresses(ctx context.Context) ([]discoveredHost, error) {
+	ingresses, err := c.client.NetworkingV1().Ingresses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	var hosts []discoveredHost
+
+	services := make(map[string]*corev1.Service)
+
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if ingress.Labels[acmeSolverLabel] == "true" {
+			continue
+		}
+
+		seen := make(map[string]bool, len(ingress.Spec.Rules))
+		windows := c.ingressMaintenance(ingress)
+		criteria := c.ingressCriteria(ingress)
+		records := c.ingressDNSRecords(ingress)
+		tlsPorts := c.ingressTLSPorts(ingress)
+
+		for _, rule := range ingress.Spec.Rules {
+			host := strings.ToLower(rule.Host)
+			if host == "" || strings.HasPrefix(host, "*") || seen[host] {
+				continue
+			}
+
+			seen[host] = true
+
+			discovered := discoveredHost{
+				host:        host,
+				namespace:   ingress.Namespace,
+				ingress:     ingress.Name,
+				tlsSecret:   ingressTLSSecret(ingress, host),
+				maintenance: windows,
+				criteria:    criteria,
+				dnsRecords:  records,
+				tlsPorts:    tlsPorts,
+			}
+			if c.config.InferIngressPorts {
+				discovered.endpoints = c.ingressEndpoints(ctx, ingress, rule, services)
+			}
+
+			hosts = append(hosts, discovered)
+		}
+	}
+
+	return hosts, nil
+}
+
+// ingressTLSSecret returns the secret an Ingress terminates TLS for host
+// with, empty when the host is not covered by its TLS section. Wildcard TLS
+// hosts cover a single label.
+func ingressTLSSecret(ingress *networkingv1.Ingress, host string) string {
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == "" {
+			continue
+		}
+
+		for _, tlsHost := range tls.Hosts {
+			tlsHost = strings.ToLower(tlsHost)
+			if tlsHost == host {
+				return tls.SecretName
+			}
+
+			if suffix, ok := strings.CutPrefix(tlsHost, "*."); ok {
+				if label, parent, found := strings.Cut(host, "."); found && label != "" && parent == suffix {
+					return tls.SecretName
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// ingressMaintenance returns the maintenance windows annotated on an
+// Ingress. Invalid annotations are ignored.
+func (c *Collector) ingressMaintenance(ingress *networkingv1.Ingress) []maintenanceWindow {
+	value, ok := ingress.Annotations[MaintenanceWindowAnnotation]
+	if !ok {
+		return nil
+	}
+
+	windows, err := parseMaintenanceWindows(value)
+	if err != nil {
+		c.logger.WithError(err).WithFields(log.Fields{
+			"namespace": ingress.Namespace,
+			"ingress":   ingress.Name,
+		}).Warn("Ignoring invalid maintenance window annotation")
+
+		return nil
+	}
+
+	return windows
+}
+
+// collectIngresses fans the result of each checked host out to the Ingresses
+// listing it. Hosts not checked yet are skipped.
+// Must be called with c.mu held.
+func (c *Collector) collectIngresses(ch chan<- prometheus.Metric) {
+	for _, discovered := range c.discovered[sourceIngress] {
+		domainHealth, ok := c.domains[discovered.host]
+		if !ok {
+			continue
+		}
+
+		up := domainHealth.ResolveOk && domainHealth.IPCount > 0 && domainHealth.UnhealthyIPs == 0
+
+		ch <- prometheus.MustNewConstMetric(
+			c.ingressUp,
+			prometheus.GaugeValue,
+			boolToFloat64(up),
+			discovered.namespace,
+			discovered.ingress,
+			discovered.host,
+		)
+
+		if expiry, ok := minCertExpiry(c.ips[discovered.host]); ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.ingressCertExpiry,
+				prometheus.GaugeValue,
+				expiry.Seconds(),
+				discovered.namespace,
+				discovered.ingress,
+				discovered.host,
+			)
+		}
+	}
+}
+
+// minCertExpiry returns the earliest expiry of the valid certificates served
+// by the IPs of a domain
+func minCertExpiry(ips map[string]*IPHealth) (time.Duration, bool) {
+	var (
+		earliest time.Duration
+		found    bool
+	)
+
+	for _, ipHealth := range ips {
+		if !ipHealth.CertOk || ipHealth.CertExpiry <= 0 {
+			continue
+		}
+
+		if !found || ipHealth.CertExpiry < earliest {
+			earliest = ipHealth.CertExpiry
+			found = true
+		}
+	}
+
+	return earliest, found
+}
+
+// targetHost returns the host checked for a discovered URL. URLs without a
+// scheme are treated as host names; the port and path are not used by the checks.
+func targetHost(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return "", fmt.Errorf("no host in URL %q", rawURL)
+	}
+
+	return strings.ToLower(host), nil
+}
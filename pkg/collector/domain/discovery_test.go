@@ -0,0 +1,175 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTargets(t *testing.T) {
+	client := fake.NewClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-a",
+			Name:      "api",
+			Annotations: map[string]string{
+				ProbeURLAnnotation: "https://API.example.com:8443/healthz, status.example.com",
+			},
+		}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "plain"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "probe-targets"},
+			Data: map[string]string{
+				"urls": "# third-party dependencies\nhttps://pay.example.net/ping\n\nexample.com\n",
+			},
+		},
+	)
+
+	c := &Collector{
+		config: &Config{
+			Domains:             []string{"example.com"},
+			DiscoverServices:    true,
+			DiscoveryConfigMaps: []string{"monitoring/probe-targets"},
+		},
+		client:     client,
+		discovered: make(map[string][]discoveredHost),
+		logger:     log.NewEntry(log.StandardLogger()),
+	}
+
+	expected := []string{"api.example.com", "example.com", "pay.example.net", "status.example.com"}
+	if got := c.targets(context.Background()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected targets %v, got %v", expected, got)
+	}
+
+	// A failing source keeps its last discovered hosts
+	c.config.DiscoveryConfigMaps = []string{"monitoring/missing"}
+	if got := c.targets(context.Background()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected targets %v after failed discovery, got %v", expected, got)
+	}
+}
+
+func TestIngressFanOut(t *testing.T) {
+	ingress := func(namespace, name string, labels map[string]string, hosts ...string) *networkingv1.Ingress {
+		ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{Host: host})
+		}
+
+		return ing
+	}
+
+	client := fake.NewClientset(
+		ingress("ns-a", "web", nil, "App.example.com", "app.example.com", "*.example.com"),
+		ingress("ns-b", "web-canary", nil, "app.example.com"),
+		ingress("ns-b", "api", nil, "api.example.com", ""),
+		ingress("ns-b", "cm-acme-http-solver", map[string]string{acmeSolverLabel: "true"}, "app.example.com"),
+	)
+
+	logger := log.NewEntry(log.StandardLogger())
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        &Config{DiscoverIngresses: true},
+		client:        client,
+		discovered:    make(map[string][]discoveredHost),
+		logger:        logger,
+	}
+	c.initMetrics("sealos")
+
+	// The host shared by two Ingresses is checked once
+	expected := []string{"api.example.com", "app.example.com"}
+	if got := c.targets(context.Background()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected targets %v, got %v", expected, got)
+	}
+
+	if got := len(c.discovered[sourceIngress]); got != 3 {
+		t.Errorf("Expected 3 ingress hosts, got %d", got)
+	}
+
+	c.domains = map[string]*DomainHealth{
+		"app.example.com": {Domain: "app.example.com", ResolveOk: true, IPCount: 2, HealthyIPs: 2},
+		"api.example.com": {Domain: "api.example.com", ResolveOk: true, IPCount: 1, UnhealthyIPs: 1},
+	}
+	c.ips = make(util.Index[*IPHealth])
+	c.ips.Set("app.example.com", "10.0.0.1", &IPHealth{CertOk: true, CertExpiry: 48 * time.Hour})
+	c.ips.Set("app.example.com", "10.0.0.2", &IPHealth{CertOk: true, CertExpiry: 24 * time.Hour})
+
+	ch := make(chan prometheus.Metric, 10)
+	c.collectIngresses(ch)
+	close(ch)
+
+	up := make(map[string]float64)
+	expiry := make(map[string]float64)
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+
+		labels := make(map[string]string)
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		key := labels["namespace"] + "/" + labels["ingress"] + "/" + labels["domain"]
+		if metric.Desc() == c.ingressUp {
+			up[key] = m.GetGauge().GetValue()
+		} else {
+			expiry[key] = m.GetGauge().GetValue()
+		}
+	}
+
+	expectedUp := map[string]float64{
+		"ns-a/web/app.example.com":        1,
+		"ns-b/web-canary/app.example.com": 1,
+		"ns-b/api/api.example.com":        0,
+	}
+	if !reflect.DeepEqual(up, expectedUp) {
+		t.Errorf("Expected ingress_up %v, got %v", expectedUp, up)
+	}
+
+	expectedExpiry := map[string]float64{
+		"ns-a/web/app.example.com":        (24 * time.Hour).Seconds(),
+		"ns-b/web-canary/app.example.com": (24 * time.Hour).Seconds(),
+	}
+	if !reflect.DeepEqual(expiry, expectedExpiry) {
+		t.Errorf("Expected ingress_cert_expiry_seconds %v, got %v", expectedExpiry, expiry)
+	}
+}
+
+func TestIngressTLSSecret(t *testing.T) {
+	ingress := &networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"api.example.com"}},
+				{Hosts: []string{"App.example.com"}, SecretName: "app-tls"},
+				{Hosts: []string{"*.example.net"}, SecretName: "wildcard-tls"},
+			},
+		},
+	}
+
+	tests := map[string]string{
+		"app.example.com":     "app-tls",
+		"pay.example.net":     "wildcard-tls",
+		"api.example.com":     "",
+		"a.pay.example.net":   "",
+		"unknown.example.com": "",
+	}
+
+	for host, expected := range tests {
+		if got := ingressTLSSecret(ingress, host); got != expected {
+			t.Errorf("ingressTLSSecret(%q) = %q, expected %q", host, got, expected)
+		}
+	}
+}
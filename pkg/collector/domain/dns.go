@@ -0,0 +1,439 @@
+package domain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/dns/dnsmessage"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// DNSRecordsAnnotation lists the DNS records the hosts of an Ingress must
+// have, separated by semicolons: TYPE to require a record of the type,
+// TYPE=value,value to also require the values (e.g.
+// "CNAME=gateway.example.com;TXT")
+const DNSRecordsAnnotation = "probe.sealos.io/dns-records"
+
+// DNS record types that can be checked
+const (
+	recordA     = "A"
+	recordAAAA  = "AAAA"
+	recordCNAME = "CNAME"
+	recordTXT   = "TXT"
+	recordCAA   = "CAA"
+	recordNS    = "NS"
+)
+
+// recordTypes maps the checked record types to their query type
+var recordTypes = map[string]dnsmessage.Type{
+	recordA:     dnsmessage.TypeA,
+	recordAAAA:  dnsmessage.TypeAAAA,
+	recordCNAME: dnsmessage.TypeCNAME,
+	recordTXT:   dnsmessage.TypeTXT,
+	recordCAA:   typeCAA,
+	recordNS:    dnsmessage.TypeNS,
+}
+
+// typeCAA is the CAA record type (RFC 8659), unknown to dnsmessage
+const typeCAA dnsmessage.Type = 257
+
+// resolvConf is the resolver configuration the DNS server is read from
+const resolvConf = "/etc/resolv.conf"
+
+// maxUDPSize is the UDP payload size advertised to the DNS server
+const maxUDPSize = 4096
+
+// DNSRecordCheck asserts the records of a type a domain has
+type DNSRecordCheck struct {
+	Domain string `yaml:"domain"`
+	Type   string `yaml:"type"` // A, AAAA, CNAME, TXT, CAA or NS
+	// Expected are values that must all be among the records (empty = any
+	// record). Names are compared without case and trailing dot, CAA records
+	// are written "tag value" (e.g. "issue letsencrypt.org").
+	Expected []string `yaml:"expected"`
+}
+
+// DNSRecordResult is the outcome of the check of the records of a type
+type DNSRecordResult struct {
+	Domain  string
+	Type    string
+	Ok      bool
+	Records []string
+	Missing []string // expected values not found
+	Error   string
+}
+
+// validateDNSRecords checks the configured record checks
+func validateDNSRecords(checks []DNSRecordCheck) error {
+	for _, check := range checks {
+		if check.Domain == "" {
+			return errors.New("invalid dnsRecords entry: domain is required")
+		}
+
+		if _, ok := recordTypes[strings.ToUpper(check.Type)]; !ok {
+			return fmt.Errorf("invalid dnsRecords type %q of %s (expected A, AAAA, CNAME, TXT, CAA or NS)",
+				check.Type, check.Domain)
+		}
+	}
+
+	return nil
+}
+
+// parseDNSRecords parses the value of the DNS records annotation
+func parseDNSRecords(value string) ([]DNSRecordCheck, error) {
+	var checks []DNSRecordCheck
+
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		recordType, valuesRaw, _ := strings.Cut(part, "=")
+		recordType = strings.ToUpper(strings.TrimSpace(recordType))
+
+		if _, ok := recordTypes[recordType]; !ok {
+			return nil, fmt.Errorf("invalid record type %q", recordType)
+		}
+
+		check := DNSRecordCheck{Type: recordType}
+
+		for _, expected := range strings.Split(valuesRaw, ",") {
+			if expected = strings.TrimSpace(expected); expected != "" {
+				check.Expected = append(check.Expected, expected)
+			}
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// ingressDNSRecords returns the record checks annotated on an Ingress.
+// Invalid annotations are ignored.
+func (c *Collector) ingressDNSRecords(ingress *networkingv1.Ingress) []DNSRecordCheck {
+	value, ok := ingress.Annotations[DNSRecordsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	checks, err := parseDNSRecords(value)
+	if err != nil {
+		c.logger.WithError(err).WithFields(log.Fields{
+			"namespace": ingress.Namespace,
+			"ingress":   ingress.Name,
+		}).Warn("Ignoring invalid DNS records annotation")
+
+		return nil
+	}
+
+	return checks
+}
+
+// hostDNSRecords returns the record checks of a domain, configured or
+// annotated on the Ingresses listing it, with the expected values of a type
+// merged
+func (c *Collector) hostDNSRecords(domain string) map[string][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	records := make(map[string][]string)
+
+	add := func(check DNSRecordCheck) {
+		recordType := strings.ToUpper(check.Type)
+
+		expected := records[recordType]
+		for _, value := range check.Expected {
+			if !slices.Contains(expected, value) {
+				expected = append(expected, value)
+			}
+		}
+
+		records[recordType] = expected
+	}
+
+	for _, check := range c.config.DNSRecords {
+		if strings.EqualFold(check.Domain, domain) {
+			add(check)
+		}
+	}
+
+	for _, discovered := range c.discovered[sourceIngress] {
+		if discovered.host != domain {
+			continue
+		}
+
+		for _, check := range discovered.dnsRecords {
+			add(check)
+		}
+	}
+
+	return records
+}
+
+// dnsRecordsEnabled returns whether DNS records may be checked, configured
+// or annotated on Ingresses
+func (c *Collector) dnsRecordsEnabled() bool {
+	return len(c.config.DNSRecords) > 0 || c.config.DiscoverIngresses
+}
+
+// defaultDNSServer returns the first name server of the resolver
+// configuration, the local one when there is none
+func defaultDNSServer() string {
+	content, err := os.ReadFile(resolvConf)
+	if err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				return net.JoinHostPort(fields[1], "53")
+			}
+		}
+	}
+
+	return "127.0.0.1:53"
+}
+
+// CheckDNSRecords checks the records of each type of a domain contain the
+// expected values
+func (dc *DomainChecker) CheckDNSRecords(
+	ctx context.Context,
+	domain string,
+	expected map[string][]string,
+) []*DNSRecordResult {
+	results := make([]*DNSRecordResult, 0, len(expected))
+
+	for recordType, values := range expected {
+		result := &DNSRecordResult{Domain: domain, Type: recordType}
+
+		dc.runCheck(ctx, func(checkCtx context.Context) {
+			result.Records, result.Error = dc.lookupRecords(checkCtx, domain, recordType)
+		})
+
+		result.Missing = missingRecords(recordType, result.Records, values)
+		result.Ok = result.Error == "" && len(result.Records) > 0 && len(result.Missing) == 0
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Type < results[j].Type
+	})
+
+	return results
+}
+
+// missingRecords returns the expected values not among the records
+func missingRecords(recordType string, records, expected []string) []string {
+	var missing []string
+
+	for _, value := range expected {
+		value = normalizeRecord(recordType, value)
+		if !slices.Contains(records, value) {
+			missing = append(missing, value)
+		}
+	}
+
+	return missing
+}
+
+// normalizeRecord lowercases names and drops their trailing dot
+func normalizeRecord(recordType, value string) string {
+	switch recordType {
+	case recordCNAME, recordNS:
+		return strings.ToLower(strings.TrimSuffix(value, "."))
+	case recordCAA:
+		tag, rest, _ := strings.Cut(strings.TrimSpace(value), " ")
+		return strings.ToLower(tag) + " " + strings.TrimSpace(rest)
+	case recordA, recordAAAA:
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	}
+
+	return value
+}
+
+// lookupRecords queries the records of a type of domain, returning them
+// normalized, and the error when the query failed
+func (dc *DomainChecker) lookupRecords(ctx context.Context, domain, recordType string) ([]string, string) {
+	response, err := dc.queryDNS(ctx, domain, recordTypes[recordType])
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+	default:
+		return nil, "server returned " + response.RCode.String()
+	}
+
+	var records []string
+
+	for _, answer := range response.Answers {
+		// Answers also hold the CNAME chain leading to the records
+		if answer.Header.Type != recordTypes[recordType] {
+			continue
+		}
+
+		var value string
+
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			value = net.IP(body.A[:]).String()
+		case *dnsmessage.AAAAResource:
+			value = net.IP(body.AAAA[:]).String()
+		case *dnsmessage.CNAMEResource:
+			value = body.CNAME.String()
+		case *dnsmessage.NSResource:
+			value = body.NS.String()
+		case *dnsmessage.TXTResource:
+			value = strings.Join(body.TXT, "")
+		case *dnsmessage.UnknownResource:
+			tag, caaValue, ok := parseCAA(body.Data)
+			if !ok {
+				continue
+			}
+
+			value = tag + " " + caaValue
+		default:
+			continue
+		}
+
+		records = append(records, normalizeRecord(recordType, value))
+	}
+
+	return records, ""
+}
+
+// parseCAA parses the data of a CAA record: flags, tag length, tag and value
+func parseCAA(data []byte) (string, string, bool) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return "", "", false
+	}
+
+	tagEnd := 2 + int(data[1])
+
+	return string(data[2:tagEnd]), string(data[tagEnd:]), true
+}
+
+// queryDNS sends a recursive query to the DNS server over UDP, retrying over
+// TCP when the response is truncated
+func (dc *DomainChecker) queryDNS(
+	ctx context.Context,
+	domain string,
+	queryType dnsmessage.Type,
+) (*dnsmessage.Message, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(domain, ".") + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain: %w", err)
+	}
+
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(time.Now().UnixNano()), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: queryType, Class: dnsmessage.ClassINET}},
+	}
+
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(maxUDPSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+
+	query.Additionals = []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}}
+
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack DNS query: %w", err)
+	}
+
+	response, err := exchangeDNS(ctx, "udp", dc.dnsServer, packet)
+	if err == nil && response.Truncated {
+		response, err = exchangeDNS(ctx, "tcp", dc.dnsServer, packet)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.ID != query.ID {
+		return nil, errors.New("DNS response ID mismatch")
+	}
+
+	return response, nil
+}
+
+// exchangeDNS sends a query packet to server and parses the response
+func exchangeDNS(ctx context.Context, network, server string, packet []byte) (*dnsmessage.Message, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DNS server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	buf := make([]byte, maxUDPSize)
+
+	if network == "tcp" {
+		// Messages are prefixed with their length over TCP
+		packet = append(binary.BigEndian.AppendUint16(make([]byte, 0, len(packet)+2), uint16(len(packet))), packet...)
+	}
+
+	if _, err := conn.Write(packet); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %w", err)
+	}
+
+	var n int
+
+	if network == "tcp" {
+		var length [2]byte
+		if _, err = io.ReadFull(conn, length[:]); err == nil {
+			buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+			n, err = io.ReadFull(conn, buf)
+		}
+	} else {
+		n, err = conn.Read(buf)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %w", err)
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buf[:n]); err != nil {
+		return nil, fmt.Errorf("invalid DNS response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// collectDNSRecords emits the outcome of the DNS record checks.
+// Must be called with c.mu held.
+func (c *Collector) collectDNSRecords(ch chan<- prometheus.Metric) {
+	for _, results := range c.dnsRecords {
+		for _, result := range results {
+			ch <- prometheus.MustNewConstMetric(
+				c.dnsRecordOk,
+				prometheus.GaugeValue,
+				boolToFloat64(result.Ok),
+				result.Domain,
+				result.Type,
+			)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS answers the queries received on conn with the records of zone
+// (key: question type), and NXDOMAIN for the other names
+func serveDNS(t *testing.T, conn net.PacketConn, name string, zone map[dnsmessage.Type][]dnsmessage.Resource) {
+	t.Helper()
+
+	buf := make([]byte, maxUDPSize)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil {
+			continue
+		}
+
+		question := query.Questions[0]
+		response := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true},
+			Questions: query.Questions,
+		}
+
+		if question.Name.String() == name {
+			response.Answers = zone[question.Type]
+		} else {
+			response.RCode = dnsmessage.RCodeNameError
+		}
+
+		packet, err := response.Pack()
+		if err != nil {
+			t.Errorf("Failed to pack response: %v", err)
+			return
+		}
+
+		_, _ = conn.WriteTo(packet, addr)
+	}
+}
+
+func TestCheckDNSRecords(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	name := dnsmessage.MustNewName("app.customer.com.")
+	header := func(recordType dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Type: recordType, Class: dnsmessage.ClassINET, TTL: 60}
+	}
+
+	go serveDNS(t, conn, name.String(), map[dnsmessage.Type][]dnsmessage.Resource{
+		dnsmessage.TypeCNAME: {{
+			Header: header(dnsmessage.TypeCNAME),
+			Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("Gateway.Sealos.io.")},
+		}},
+		dnsmessage.TypeTXT: {{
+			Header: header(dnsmessage.TypeTXT),
+			Body:   &dnsmessage.TXTResource{TXT: []string{"verify=abc"}},
+		}},
+		typeCAA: {{
+			Header: header(typeCAA),
+			Body:   &dnsmessage.UnknownResource{Type: typeCAA, Data: append([]byte{0, 5}, "issueletsencrypt.org"...)},
+		}},
+	})
+
+	checker := NewDomainChecker(time.Second, false, true, false)
+	checker.dnsServer = conn.LocalAddr().String()
+
+	results := checker.CheckDNSRecords(context.Background(), "app.customer.com", map[string][]string{
+		recordCNAME: {"gateway.sealos.io."},
+		recordTXT:   {"verify=abc", "verify=def"},
+		recordCAA:   {"issue letsencrypt.org"},
+		recordA:     nil,
+	})
+
+	expected := map[string]bool{recordA: false, recordCAA: true, recordCNAME: true, recordTXT: false}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+
+	for _, result := range results {
+		if result.Ok != expected[result.Type] {
+			t.Errorf("Expected %s ok=%v, got %+v", result.Type, expected[result.Type], result)
+		}
+	}
+
+	// A deleted record is reported like a missing one
+	missing := checker.CheckDNSRecords(context.Background(), "other.customer.com", map[string][]string{
+		recordCNAME: {"gateway.sealos.io"},
+	})
+	if len(missing) != 1 || missing[0].Ok || missing[0].Error != "" {
+		t.Errorf("Expected a missing CNAME without error, got %+v", missing[0])
+	}
+}
+
+func TestParseDNSRecords(t *testing.T) {
+	checks, err := parseDNSRecords("cname=gateway.sealos.io; TXT ;CAA=issue letsencrypt.org, issuewild letsencrypt.org")
+	if err != nil {
+		t.Fatalf("parseDNSRecords() error = %v", err)
+	}
+
+	expected := []DNSRecordCheck{
+		{Type: recordCNAME, Expected: []string{"gateway.sealos.io"}},
+		{Type: recordTXT},
+		{Type: recordCAA, Expected: []string{"issue letsencrypt.org", "issuewild letsencrypt.org"}},
+	}
+	if !reflect.DeepEqual(checks, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, checks)
+	}
+
+	if _, err := parseDNSRecords("MX=mail.example.com"); err == nil {
+		t.Error("Expected error for an unsupported record type, got nil")
+	}
+}
+
+func TestHostDNSRecords(t *testing.T) {
+	c := &Collector{
+		config: &Config{DNSRecords: []DNSRecordCheck{
+			{Domain: "App.customer.com", Type: "cname", Expected: []string{"gateway.sealos.io"}},
+		}},
+		discovered: map[string][]discoveredHost{
+			sourceIngress: {{
+				host: "app.customer.com",
+				dnsRecords: []DNSRecordCheck{
+					{Type: recordCNAME, Expected: []string{"gateway.sealos.io", "lb.sealos.io"}},
+					{Type: recordTXT},
+				},
+			}},
+		},
+	}
+
+	expected := map[string][]string{
+		recordCNAME: {"gateway.sealos.io", "lb.sealos.io"},
+		recordTXT:   nil,
+	}
+	if got := c.hostDNSRecords("app.customer.com"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
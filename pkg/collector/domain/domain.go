@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 // Collector collects domain metrics
@@ -16,17 +18,57 @@ type Collector struct {
 
 	config  *Config
 	checker *DomainChecker
+	client  kubernetes.Interface // only set when target discovery, the ACME check or the quorum is enabled
 	logger  *log.Entry
 
-	mu      sync.RWMutex
-	ips     map[string]*IPHealth     // key: domain/ip
-	domains map[string]*DomainHealth // key: domain
+	// identity names the report of this instance in the quorum
+	identity string
+
+	// acmeProbe requests ACME challenge URLs (replaced in tests)
+	acmeProbe acmeProbe
+
+	mu         sync.RWMutex
+	ips        util.Index[*IPHealth]         // key: domain, then ip
+	vips       util.Index[*IPHealth]         // key: domain, then vip
+	domains    map[string]*DomainHealth      // key: domain
+	history    map[string]*historyRing       // key: domain
+	discovered map[string][]discoveredHost   // key: discovery source
+	acme       []*ACMEStatus                 // pending certificates of cert-manager managed Ingresses
+	quorum     map[string]*QuorumStatus      // key: domain
+	dnsRecords map[string][]*DNSRecordResult // key: domain
 
 	// Metrics
 	domainHealth       *prometheus.Desc
 	domainStatus       *prometheus.Desc
 	domainCertExpiry   *prometheus.Desc
+	domainChainExpiry  *prometheus.Desc
 	domainResponseTime *prometheus.Desc
+	domainResolveTime  *prometheus.Desc
+	domainConnectTime  *prometheus.Desc
+	domainTLSTime      *prometheus.Desc
+	domainFirstByte    *prometheus.Desc
+	domainTLSInfo      *prometheus.Desc
+	domainHSTS         *prometheus.Desc
+	ipTimeout          *prometheus.Desc
+	portCertStatus     *prometheus.Desc
+	portCertExpiry     *prometheus.Desc
+	discoveredTargets  *prometheus.Desc
+
+	ingressUp         *prometheus.Desc
+	ingressCertExpiry *prometheus.Desc
+
+	vipStatus       *prometheus.Desc
+	vipResponseTime *prometheus.Desc
+
+	acmeCertPending        *prometheus.Desc
+	acmeChallengeReachable *prometheus.Desc
+
+	quorumUp        *prometheus.Desc
+	quorumDownVotes *prometheus.Desc
+	quorumReports   *prometheus.Desc
+	quorumReportUp  *prometheus.Desc
+
+	dnsRecordOk *prometheus.Desc
 }
 
 // initMetrics initializes Prometheus metric descriptors
@@ -39,8 +81,9 @@ func (c *Collector) initMetrics(namespace string) {
 	)
 	c.domainStatus = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "domain", "status"),
-		"Domain IP status (1=ok, 0=error)",
-		[]string{"domain", "ip", "check_type", "error_type"},
+		"Domain IP status (1=ok, 0=error), criterion is the HTTP success criterion failed by the response "+
+			"(status, body or redirects)",
+		[]string{"domain", "ip", "check_type", "error_type", "criterion"},
 		nil,
 	)
 	c.domainCertExpiry = prometheus.NewDesc(
@@ -49,18 +92,202 @@ func (c *Collector) initMetrics(namespace string) {
 		[]string{"domain", "ip", "error_type"},
 		nil,
 	)
+	c.domainChainExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "cert_chain_expiry_seconds"),
+		"Seconds before the first certificate of the chain presented by the domain expires, intermediates included",
+		[]string{"domain", "ip"},
+		nil,
+	)
 	c.domainResponseTime = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "domain", "response_time_seconds"),
 		"Domain IP response time in seconds",
 		[]string{"domain", "ip"},
 		nil,
 	)
+	c.domainResolveTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "dns_resolution_seconds"),
+		"Time spent resolving the domain in seconds",
+		[]string{"domain"},
+		nil,
+	)
+	c.domainConnectTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "connect_seconds"),
+		"Time spent establishing the TCP connection to the domain IP in seconds",
+		[]string{"domain", "ip"},
+		nil,
+	)
+	c.domainTLSTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "tls_handshake_seconds"),
+		"Time spent in the TLS handshake with the domain IP in seconds",
+		[]string{"domain", "ip"},
+		nil,
+	)
+	c.domainFirstByte = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "ttfb_seconds"),
+		"Time from the request being sent to the first response byte of the domain IP in seconds",
+		[]string{"domain", "ip"},
+		nil,
+	)
+
+	c.domainTLSInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "tls_info"),
+		"Negotiated TLS protocol version and cipher suite per domain IP (always 1)",
+		[]string{"domain", "ip", "version", "cipher"},
+		nil,
+	)
+	c.domainHSTS = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "hsts_enabled"),
+		"Whether the domain IP returns a Strict-Transport-Security header (1=present, 0=absent)",
+		[]string{"domain", "ip"},
+		nil,
+	)
+	c.ipTimeout = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "ip_timeout"),
+		"Whether the HTTP check of the domain IP timed out on the port (1=timeout, 0=no timeout)",
+		[]string{"domain", "ip", "port"},
+		nil,
+	)
+	c.portCertStatus = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "port_cert_status"),
+		"Certificate status of the domain on a TLS port besides 443, declared on or inferred from its Ingresses "+
+			"(1=ok, 0=error)",
+		[]string{"domain", "port", "error_type"},
+		nil,
+	)
+	c.portCertExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "port_cert_expiry_seconds"),
+		"Certificate expiry of the domain on a TLS port besides 443 in seconds",
+		[]string{"domain", "port"},
+		nil,
+	)
+	c.discoveredTargets = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "discovered_targets"),
+		"Number of hosts discovered from annotated Services, ConfigMap-listed URLs or Ingresses",
+		[]string{"source"},
+		nil,
+	)
+	c.ingressUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "ingress_up"),
+		"Whether the host of an Ingress resolved and all its IPs are healthy (1=up, 0=down), "+
+			"checked once per host however many Ingresses list it",
+		[]string{"namespace", "ingress", "domain"},
+		nil,
+	)
+	c.ingressCertExpiry = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "ingress_cert_expiry_seconds"),
+		"Earliest certificate expiry among the IPs of the host of an Ingress",
+		[]string{"namespace", "ingress", "domain"},
+		nil,
+	)
+
+	c.vipStatus = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "vip_status"),
+		"Domain status when checked through a gateway VIP, bypassing DNS (1=ok, 0=error)",
+		[]string{"domain", "vip", "check_type", "error_type", "criterion"},
+		nil,
+	)
+	c.vipResponseTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "vip_response_time_seconds"),
+		"Domain HTTP response time when checked through a gateway VIP",
+		[]string{"domain", "vip"},
+		nil,
+	)
+
+	c.acmeCertPending = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "acme_certificate_pending"),
+		"Ingress hosts managed by cert-manager whose certificate is missing or invalid (always 1), "+
+			"reason is missing, invalid, expired or host_mismatch",
+		[]string{"namespace", "ingress", "host", "secret", "reason"},
+		nil,
+	)
+	c.acmeChallengeReachable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "acme_challenge_reachable"),
+		"Whether the HTTP-01 challenge of a pending certificate is reachable (1=reachable, 0=not), "+
+			"reason explains why not (no_challenge, dns, not_found, redirect, timeout, unreachable, http_error)",
+		[]string{"namespace", "ingress", "host", "reason"},
+		nil,
+	)
+
+	c.quorumUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "quorum_up"),
+		"Domain state agreed by the instances (1=up, 0=down when a quorum of instances report it down)",
+		[]string{"domain"},
+		nil,
+	)
+	c.quorumDownVotes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "quorum_down_votes"),
+		"Number of instances reporting the domain down",
+		[]string{"domain"},
+		nil,
+	)
+	c.quorumReports = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "quorum_reports"),
+		"Number of instances with a recent report of the domain",
+		[]string{"domain"},
+		nil,
+	)
+	c.quorumReportUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "quorum_report_up"),
+		"Domain state reported by each instance (1=up, 0=down)",
+		[]string{"domain", "reporter"},
+		nil,
+	)
+
+	c.dnsRecordOk = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "domain", "dns_record_ok"),
+		"Whether the domain has DNS records of the type with all the expected values (1=ok, 0=missing or lookup error)",
+		[]string{"domain", "type"},
+		nil,
+	)
 
 	// Register descriptors
 	c.MustRegisterDesc(c.domainHealth)
 	c.MustRegisterDesc(c.domainStatus)
 	c.MustRegisterDesc(c.domainCertExpiry)
+	c.MustRegisterDesc(c.domainChainExpiry)
 	c.MustRegisterDesc(c.domainResponseTime)
+	c.MustRegisterDesc(c.domainResolveTime)
+	c.MustRegisterDesc(c.domainConnectTime)
+	c.MustRegisterDesc(c.domainTLSTime)
+	c.MustRegisterDesc(c.domainFirstByte)
+	c.MustRegisterDesc(c.domainTLSInfo)
+	c.MustRegisterDesc(c.domainHSTS)
+	c.MustRegisterDesc(c.ipTimeout)
+
+	if c.config.IncludeCertCheck {
+		c.MustRegisterDesc(c.portCertStatus)
+		c.MustRegisterDesc(c.portCertExpiry)
+	}
+
+	if c.discoveryEnabled() {
+		c.MustRegisterDesc(c.discoveredTargets)
+	}
+
+	if c.config.DiscoverIngresses {
+		c.MustRegisterDesc(c.ingressUp)
+		c.MustRegisterDesc(c.ingressCertExpiry)
+	}
+
+	if len(c.config.VIPs) > 0 {
+		c.MustRegisterDesc(c.vipStatus)
+		c.MustRegisterDesc(c.vipResponseTime)
+	}
+
+	if c.config.ACMECheck {
+		c.MustRegisterDesc(c.acmeCertPending)
+		c.MustRegisterDesc(c.acmeChallengeReachable)
+	}
+
+	if c.config.Quorum {
+		c.MustRegisterDesc(c.quorumUp)
+		c.MustRegisterDesc(c.quorumDownVotes)
+		c.MustRegisterDesc(c.quorumReports)
+		c.MustRegisterDesc(c.quorumReportUp)
+	}
+
+	if c.dnsRecordsEnabled() {
+		c.MustRegisterDesc(c.dnsRecordOk)
+	}
 }
 
 // HasSynced returns true (polling collector is always synced)
@@ -70,40 +297,97 @@ func (c *Collector) HasSynced() bool {
 
 // Interval returns the polling interval
 func (c *Collector) Interval() time.Duration {
-	return c.config.CheckInterval
+	return c.tick()
 }
 
-// Poll performs one check cycle
+// Poll performs one check cycle, checking the domains that are due. The
+// results of the other targets are kept, the ones of removed targets dropped.
 func (c *Collector) Poll(ctx context.Context) error {
-	if len(c.config.Domains) == 0 {
-		c.logger.Debug("No domains configured for monitoring")
+	if c.config.ACMECheck {
+		c.checkACME(ctx)
+	}
+
+	targets := c.targets(ctx)
+	if len(targets) == 0 {
+		c.logger.Debug("No domains configured or discovered for monitoring")
+	}
+
+	due := c.dueTargets(targets, time.Now())
+	if len(due) == 0 {
+		if len(targets) > 0 {
+			c.logger.Debug("No domains due for a check")
+		}
+
+		// Removed targets are dropped on every cycle
+		c.mu.Lock()
+		c.storeResults(targets, make(map[string]*DomainHealth), make(util.Index[*IPHealth]),
+			make(util.Index[*IPHealth]), make(map[string][]*DNSRecordResult), nil)
+		c.mu.Unlock()
+
 		return nil
 	}
 
-	c.logger.WithField("count", len(c.config.Domains)).Info("Starting domain health checks")
+	c.logger.WithFields(log.Fields{
+		"count":   len(due),
+		"targets": len(targets),
+	}).Info("Starting domain health checks")
 
 	// Create new maps to store results
-	newIPs := make(map[string]*IPHealth)
+	newIPs := make(util.Index[*IPHealth])
+	newVIPs := make(util.Index[*IPHealth])
 	newDomains := make(map[string]*DomainHealth)
+	newRecords := make(map[string][]*DNSRecordResult)
+
+	entries := make([]HistoryEntry, 0, len(due))
 
 	var mu sync.Mutex
 
 	// Check domains concurrently
 	var wg sync.WaitGroup
-	for _, domain := range c.config.Domains {
+	for _, domain := range due {
 		wg.Go(func() {
-			domainHealth, ipHealths := c.checker.CheckIPs(ctx, domain, c.logger)
+			start := time.Now()
+			criteria := c.hostCriteria(domain)
+			domainHealth, ipHealths := c.checker.CheckIPs(
+				ctx,
+				domain,
+				c.hostEndpoints(domain),
+				c.hostTLSPorts(domain),
+				criteria,
+				c.logger,
+			)
+			entry := newHistoryEntry(domainHealth, ipHealths, time.Since(start))
+
+			var vipHealths []*IPHealth
+			if len(c.config.VIPs) > 0 {
+				vipHealths = c.checker.CheckVIPs(ctx, domain, c.config.VIPs, criteria, c.logger)
+			}
+
+			var recordResults []*DNSRecordResult
+			if c.dnsRecordsEnabled() {
+				if expected := c.hostDNSRecords(domain); len(expected) > 0 {
+					recordResults = c.checker.CheckDNSRecords(ctx, domain, expected)
+				}
+			}
 
 			// Add results to new maps
 			mu.Lock()
 
 			// Store domain-level health
 			newDomains[domain] = domainHealth
+			entries = append(entries, entry)
 
 			// Store IP-level health
 			for _, ipHealth := range ipHealths {
-				key := ipKey(ipHealth.Domain, ipHealth.IP)
-				newIPs[key] = ipHealth
+				newIPs.Set(ipHealth.Domain, ipHealth.IP, ipHealth)
+			}
+
+			for _, vipHealth := range vipHealths {
+				newVIPs.Set(vipHealth.Domain, vipHealth.IP, vipHealth)
+			}
+
+			if len(recordResults) > 0 {
+				newRecords[domain] = recordResults
 			}
 
 			mu.Unlock()
@@ -114,22 +398,25 @@ func (c *Collector) Poll(ctx context.Context) error {
 
 	// Atomically replace the old maps with the new ones
 	c.mu.Lock()
-	c.ips = newIPs
-	c.domains = newDomains
+	c.storeResults(targets, newDomains, newIPs, newVIPs, newRecords, entries)
 	c.mu.Unlock()
 
-	c.logger.WithField("count", len(c.config.Domains)).Info("Domain health checks completed")
+	if c.config.Quorum {
+		c.syncQuorum(ctx, newDomains)
+	}
+
+	c.logger.WithField("count", len(due)).Info("Domain health checks completed")
 
 	return nil
 }
 
 // pollLoop runs the polling loop
 func (c *Collector) pollLoop(ctx context.Context) {
-	ticker := time.NewTicker(c.config.CheckInterval)
+	ticker := time.NewTicker(c.tick())
 	defer ticker.Stop()
 
 	// Do initial check
-	_ = c.Poll(ctx)
+	_ = c.PollOnce(ctx, c.Poll)
 
 	// Mark as ready after first poll completes
 	c.SetReady()
@@ -137,7 +424,7 @@ func (c *Collector) pollLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			_ = c.Poll(ctx)
+			_ = c.PollOnce(ctx, c.Poll)
 		case <-ctx.Done():
 			return
 		}
@@ -149,6 +436,24 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	// Series of hosts in maintenance are labelled or dropped on the way out
+	if hosts := c.maintenanceHosts(time.Now()); len(hosts) > 0 {
+		source := make(chan prometheus.Metric)
+		done := make(chan struct{})
+
+		go func(dest chan<- prometheus.Metric) {
+			defer close(done)
+			c.forwardMaintenance(source, dest, hosts)
+		}(ch)
+
+		defer func() {
+			close(source)
+			<-done
+		}()
+
+		ch = source
+	}
+
 	// Emit domain-level health metrics
 	for _, domainHealth := range c.domains {
 		// Resolve status (1=success, 0=failure)
@@ -160,6 +465,15 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 			"resolve",
 		)
 
+		if domainHealth.ResolveOk && domainHealth.ResolveTime > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.domainResolveTime,
+				prometheus.GaugeValue,
+				domainHealth.ResolveTime.Seconds(),
+				domainHealth.Domain,
+			)
+		}
+
 		// IP count
 		ch <- prometheus.MustNewConstMetric(
 			c.domainHealth,
@@ -186,62 +500,150 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 			domainHealth.Domain,
 			"unhealthy_ips",
 		)
+
+		if c.config.IncludeCertCheck {
+			c.collectTLSPorts(ch, domainHealth)
+		}
 	}
 
 	// Emit IP-level metrics
-	for _, ipHealth := range c.ips {
-		// HTTP status
-		if c.config.IncludeHTTPCheck {
-			ch <- prometheus.MustNewConstMetric(
-				c.domainStatus,
-				prometheus.GaugeValue,
-				boolToFloat64(ipHealth.HTTPOk),
-				ipHealth.Domain,
-				ipHealth.IP,
-				"http",
-				string(ipHealth.HTTPErrorType),
-			)
-
-			if ipHealth.HTTPOk {
+	for _, domainIPs := range c.ips {
+		for _, ipHealth := range domainIPs {
+			// HTTP status
+			if c.config.IncludeHTTPCheck {
 				ch <- prometheus.MustNewConstMetric(
-					c.domainResponseTime,
+					c.domainStatus,
 					prometheus.GaugeValue,
-					ipHealth.ResponseTime.Seconds(),
+					boolToFloat64(ipHealth.HTTPOk),
 					ipHealth.Domain,
 					ipHealth.IP,
+					"http",
+					string(ipHealth.HTTPErrorType),
+					ipHealth.HTTPCriterion,
 				)
-			}
-		}
 
-		// Certificate status
-		if c.config.IncludeCertCheck {
-			ch <- prometheus.MustNewConstMetric(
-				c.domainStatus,
-				prometheus.GaugeValue,
-				boolToFloat64(ipHealth.CertOk),
-				ipHealth.Domain,
-				ipHealth.IP,
-				"cert",
-				string(ipHealth.CertErrorType),
-			)
+				c.collectPortTimeouts(ch, ipHealth)
+
+				if ipHealth.HTTPOk {
+					ch <- prometheus.MustNewConstMetric(
+						c.domainResponseTime,
+						prometheus.GaugeValue,
+						ipHealth.ResponseTime.Seconds(),
+						ipHealth.Domain,
+						ipHealth.IP,
+					)
+
+					c.collectPhases(ch, ipHealth)
+				}
+
+				// TLS posture is known whenever a response was received over TLS
+				if ipHealth.TLSVersion != "" {
+					ch <- prometheus.MustNewConstMetric(
+						c.domainTLSInfo,
+						prometheus.GaugeValue,
+						1,
+						ipHealth.Domain,
+						ipHealth.IP,
+						ipHealth.TLSVersion,
+						ipHealth.CipherSuite,
+					)
+					ch <- prometheus.MustNewConstMetric(
+						c.domainHSTS,
+						prometheus.GaugeValue,
+						boolToFloat64(ipHealth.HSTS),
+						ipHealth.Domain,
+						ipHealth.IP,
+					)
+				}
+			}
 
-			if ipHealth.CertOk && ipHealth.CertExpiry > 0 {
+			// Certificate status
+			if c.config.IncludeCertCheck {
 				ch <- prometheus.MustNewConstMetric(
-					c.domainCertExpiry,
+					c.domainStatus,
 					prometheus.GaugeValue,
-					ipHealth.CertExpiry.Seconds(),
+					boolToFloat64(ipHealth.CertOk),
 					ipHealth.Domain,
 					ipHealth.IP,
+					"cert",
 					string(ipHealth.CertErrorType),
+					"",
 				)
+
+				if ipHealth.CertOk && ipHealth.CertExpiry > 0 {
+					ch <- prometheus.MustNewConstMetric(
+						c.domainCertExpiry,
+						prometheus.GaugeValue,
+						ipHealth.CertExpiry.Seconds(),
+						ipHealth.Domain,
+						ipHealth.IP,
+						string(ipHealth.CertErrorType),
+					)
+				}
+
+				if ipHealth.CertOk && ipHealth.CertChainExpiry > 0 {
+					ch <- prometheus.MustNewConstMetric(
+						c.domainChainExpiry,
+						prometheus.GaugeValue,
+						ipHealth.CertChainExpiry.Seconds(),
+						ipHealth.Domain,
+						ipHealth.IP,
+					)
+				}
 			}
 		}
 	}
+
+	if len(c.config.VIPs) > 0 {
+		c.collectVIPs(ch)
+	}
+
+	if c.discoveryEnabled() {
+		c.collectDiscovered(ch)
+	}
+
+	if c.config.DiscoverIngresses {
+		c.collectIngresses(ch)
+	}
+
+	if c.config.ACMECheck {
+		c.collectACME(ch)
+	}
+
+	if c.config.Quorum {
+		c.collectQuorum(ch)
+	}
+
+	if c.dnsRecordsEnabled() {
+		c.collectDNSRecords(ch)
+	}
 }
 
-// ipKey generates a unique key for an IP
-func ipKey(domain, ip string) string {
-	return domain + "/" + ip
+// collectPhases emits the duration of each phase of a successful HTTP check.
+// Phases that did not happen (e.g. a reused connection) are skipped.
+func (c *Collector) collectPhases(ch chan<- prometheus.Metric, ipHealth *IPHealth) {
+	phases := []struct {
+		desc     *prometheus.Desc
+		duration time.Duration
+	}{
+		{c.domainConnectTime, ipHealth.ConnectTime},
+		{c.domainTLSTime, ipHealth.TLSHandshakeTime},
+		{c.domainFirstByte, ipHealth.FirstByteTime},
+	}
+
+	for _, phase := range phases {
+		if phase.duration <= 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			phase.desc,
+			prometheus.GaugeValue,
+			phase.duration.Seconds(),
+			ipHealth.Domain,
+			ipHealth.IP,
+		)
+	}
 }
 
 // boolToFloat64 converts a boolean to a float64
@@ -2,16 +2,27 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	rbacv1 "k8s.io/api/rbac/v1"
 )
 
 const collectorName = "domain"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Domain health and certificate monitoring"),
+		registry.WithStandalone(),
+		registry.WithConfigRBAC(requiredRBAC),
+	)
 }
 
 // NewCollector creates a new Domain collector
@@ -26,15 +37,64 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			Debug("Failed to load domain collector config, using defaults")
 	}
 
+	if cfg.Quorum && cfg.QuorumNamespace == "" {
+		return nil, errors.New("quorumNamespace is required when the quorum is enabled")
+	}
+
+	if cfg.InferIngressPorts && !cfg.DiscoverIngresses {
+		return nil, errors.New("inferIngressPorts requires discoverIngresses")
+	}
+
+	if cfg.MaintenanceMode != maintenanceModeLabel && cfg.MaintenanceMode != maintenanceModeSuppress {
+		return nil, fmt.Errorf("invalid maintenanceMode %q (expected %s or %s)",
+			cfg.MaintenanceMode, maintenanceModeLabel, maintenanceModeSuppress)
+	}
+
+	if err := validateVIPs(cfg.VIPs); err != nil {
+		return nil, err
+	}
+
+	if err := validateDNSRecords(cfg.DNSRecords); err != nil {
+		return nil, err
+	}
+
+	criteria, err := newHTTPCriteria(cfg.ExpectedStatus, cfg.ExpectedBody, cfg.MaxRedirects)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Collector{
 		BaseCollector: base.NewBaseCollector(
 			collectorName,
 			factoryCtx.Logger,
 			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, cfg.CycleTimeout),
+			// With a quorum every instance checks the domains and publishes its results
+			base.WithLeaderElection(!cfg.Quorum),
 		),
-		config: cfg,
-		ips:    make(map[string]*IPHealth),
-		logger: factoryCtx.Logger,
+		config:     cfg,
+		identity:   factoryCtx.Identity,
+		ips:        make(util.Index[*IPHealth]),
+		vips:       make(util.Index[*IPHealth]),
+		history:    make(map[string]*historyRing),
+		discovered: make(map[string][]discoveredHost),
+		quorum:     make(map[string]*QuorumStatus),
+		dnsRecords: make(map[string][]*DNSRecordResult),
+		acmeProbe:  probeChallenge,
+		logger:     factoryCtx.Logger,
+	}
+
+	// Target discovery, the ACME check and the quorum need a Kubernetes client; static domains do not
+	if c.discoveryEnabled() || cfg.ACMECheck || cfg.Quorum {
+		client, err := factoryCtx.GetClient()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"kubernetes client is required for target discovery, the ACME check and the quorum but not available: %w",
+				err,
+			)
+		}
+
+		c.client = client
 	}
 
 	// Create checker
@@ -44,6 +104,19 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 		true, // checkDNS is always true as we need IPs
 		cfg.IncludeCertCheck,
 	)
+	c.checker.criteria = criteria
+	c.checker.onCanceled = c.RecordCanceled
+	c.checker.userAgent = cfg.UserAgent
+	c.checker.requestIDHeader = cfg.RequestIDHeader
+
+	c.checker.dnsServer = cfg.DNSServer
+	if c.checker.dnsServer == "" {
+		c.checker.dnsServer = defaultDNSServer()
+	}
+
+	if cfg.AuditLog {
+		c.checker.audit = c.auditRequest
+	}
 
 	c.initMetrics(factoryCtx.MetricsNamespace)
 
@@ -57,6 +130,13 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			return nil
 		},
 		StopFunc: func() error {
+			if cfg.Quorum {
+				ctx, cancel := context.WithTimeout(context.Background(), cfg.CheckTimeout)
+				defer cancel()
+
+				c.deleteReport(ctx)
+			}
+
 			return nil
 		},
 		CollectFunc: c.collect,
@@ -64,3 +144,78 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 
 	return c, nil
 }
+
+// requiredRBAC returns the permissions of the enabled target discovery, ACME
+// check and quorum; static domains need none
+func requiredRBAC(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.domain", cfg); err != nil {
+		return nil, err
+	}
+
+	var rules []rbacv1.PolicyRule
+
+	if cfg.DiscoverServices {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"services"},
+			Verbs:     []string{"list"},
+		})
+	}
+
+	for _, ref := range cfg.DiscoveryConfigMaps {
+		_, name, ok := strings.Cut(ref, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid ConfigMap reference %q (expected namespace/name)", ref)
+		}
+
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{name},
+			Verbs:         []string{"get"},
+		})
+	}
+
+	if cfg.DiscoverIngresses {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{"networking.k8s.io"},
+			Resources: []string{"ingresses"},
+			Verbs:     []string{"list"},
+		})
+
+		// Named backend ports are resolved through the Service
+		if cfg.InferIngressPorts {
+			rules = append(rules, rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"services"},
+				Verbs:     []string{"get"},
+			})
+		}
+	}
+
+	if cfg.ACMECheck {
+		rules = append(rules,
+			rbacv1.PolicyRule{
+				APIGroups: []string{"networking.k8s.io"},
+				Resources: []string{"ingresses"},
+				Verbs:     []string{"list"},
+			},
+			rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get"},
+			},
+		)
+	}
+
+	if cfg.Quorum {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"list", "create", "update", "delete"},
+		})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,124 @@
+package domain
+
+import "time"
+
+// HistoryEntry is the result of one check of a domain
+type HistoryEntry struct {
+	DomainStatus
+
+	// DurationSeconds is how long checking the domain and all its IPs took
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// History is the check history of a domain, oldest entry first
+type History struct {
+	Domain  string         `json:"domain"`
+	Size    int            `json:"size"`
+	Entries []HistoryEntry `json:"entries"`
+}
+
+// historyRing keeps the last results of a domain in a fixed-size ring buffer
+type historyRing struct {
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+// newHistoryRing creates a ring buffer holding up to size entries
+func newHistoryRing(size int) *historyRing {
+	return &historyRing{entries: make([]HistoryEntry, size)}
+}
+
+// add records an entry, overwriting the oldest one when the buffer is full
+func (r *historyRing) add(entry HistoryEntry) {
+	r.entries[r.next] = entry
+
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns the recorded entries, oldest first
+func (r *historyRing) list() []HistoryEntry {
+	if !r.full {
+		return append([]HistoryEntry(nil), r.entries[:r.next]...)
+	}
+
+	entries := make([]HistoryEntry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+
+	return append(entries, r.entries[:r.next]...)
+}
+
+// newHistoryEntry converts the result of a domain check
+func newHistoryEntry(
+	domainHealth *DomainHealth,
+	ipHealths []*IPHealth,
+	duration time.Duration,
+) HistoryEntry {
+	entry := HistoryEntry{
+		DomainStatus:    newDomainStatus(domainHealth),
+		DurationSeconds: duration.Seconds(),
+	}
+
+	for _, ipHealth := range ipHealths {
+		entry.IPs = append(entry.IPs, newIPStatus(ipHealth))
+	}
+
+	sortIPs(entry.IPs)
+
+	return entry
+}
+
+// recordHistory appends the result of a domain check to its history.
+// Must be called with c.mu held.
+func (c *Collector) recordHistory(entry HistoryEntry) {
+	if c.config.HistorySize <= 0 {
+		return
+	}
+
+	ring, ok := c.history[entry.Domain]
+	if !ok {
+		ring = newHistoryRing(c.config.HistorySize)
+		c.history[entry.Domain] = ring
+	}
+
+	ring.add(entry)
+}
+
+// pruneHistory drops the history of the domains no longer monitored.
+// Must be called with c.mu held.
+func (c *Collector) pruneHistory(targets []string) {
+	if len(c.history) == 0 {
+		return
+	}
+
+	monitored := make(map[string]bool, len(targets))
+	for _, domain := range targets {
+		monitored[domain] = true
+	}
+
+	for domain := range c.history {
+		if !monitored[domain] {
+			delete(c.history, domain)
+		}
+	}
+}
+
+// History returns the last check results of a domain (served by /api/v1/history/domain/{name})
+func (c *Collector) History(domain string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ring, ok := c.history[domain]
+	if !ok {
+		return nil, false
+	}
+
+	return History{
+		Domain:  domain,
+		Size:    c.config.HistorySize,
+		Entries: ring.list(),
+	}, true
+}
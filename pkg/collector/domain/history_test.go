@@ -0,0 +1,61 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestHistoryRing(t *testing.T) {
+	ring := newHistoryRing(3)
+
+	if got := ring.list(); len(got) != 0 {
+		t.Fatalf("Expected empty history, got %d entries", len(got))
+	}
+
+	for i := 1; i <= 5; i++ {
+		ring.add(HistoryEntry{DurationSeconds: float64(i)})
+
+		want := min(i, 3)
+		if got := len(ring.list()); got != want {
+			t.Fatalf("After %d entries: expected %d, got %d", i, want, got)
+		}
+	}
+
+	entries := ring.list()
+	for i, want := range []float64{3, 4, 5} {
+		if entries[i].DurationSeconds != want {
+			t.Errorf("Entry %d: expected %v, got %v", i, want, entries[i].DurationSeconds)
+		}
+	}
+}
+
+func TestPollPrunesHistory(t *testing.T) {
+	c := &Collector{
+		config: &Config{Domains: []string{"kept.example.com"}, CheckInterval: 10 * time.Minute, HistorySize: 3},
+		logger: log.NewEntry(log.New()),
+		domains: map[string]*DomainHealth{
+			"kept.example.com": {Domain: "kept.example.com", ResolveOk: true, LastChecked: time.Now()},
+		},
+		history: make(map[string]*historyRing),
+	}
+
+	for _, domain := range []string{"kept.example.com", "removed.example.com"} {
+		c.recordHistory(HistoryEntry{DomainStatus: DomainStatus{Domain: domain}})
+	}
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	if _, ok := c.History("kept.example.com"); !ok {
+		t.Error("Expected the history of a monitored domain to be kept")
+	}
+
+	if _, ok := c.History("removed.example.com"); ok {
+		t.Error("Expected the history of a removed domain to be dropped")
+	}
+}
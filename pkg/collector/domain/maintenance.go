@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MaintenanceWindowAnnotation declares the expected downtime windows of the
+// hosts of an Ingress. The value is a ';' separated list of windows, each
+// either an RFC 3339 interval (2026-10-20T01:00:00Z/2026-10-20T03:00:00Z) or
+// a 5-field cron expression in UTC followed by the window duration
+// (0 2 * * 6 2h: every Saturday from 02:00 to 04:00).
+const MaintenanceWindowAnnotation = "sealos.io/maintenance-window"
+
+const (
+	// maintenanceModeLabel adds an in_maintenance="true" label to the series
+	// of hosts in maintenance
+	maintenanceModeLabel = "label"
+	// maintenanceModeSuppress drops the series of hosts in maintenance
+	maintenanceModeSuppress = "suppress"
+
+	// inMaintenanceLabel is the label added in maintenanceModeLabel
+	inMaintenanceLabel = "in_maintenance"
+
+	// maxCronWindow bounds the duration of cron windows, whose activity is
+	// checked minute by minute
+	maxCronWindow = 7 * 24 * time.Hour
+)
+
+// maintenanceWindow is an expected downtime window parsed from the annotation
+type maintenanceWindow struct {
+	// start and end bound an interval window
+	start, end time.Time
+	// cron and duration describe a recurring window
+	cron     *cronSchedule
+	duration time.Duration
+}
+
+// parseMaintenanceWindows parses the value of MaintenanceWindowAnnotation
+func parseMaintenanceWindows(value string) ([]maintenanceWindow, error) {
+	var windows []maintenanceWindow
+
+	for _, raw := range strings.Split(value, ";") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		window, err := parseMaintenanceWindow(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", raw, err)
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+// parseMaintenanceWindow parses an interval or cron window
+func parseMaintenanceWindow(raw string) (maintenanceWindow, error) {
+	if startRaw, endRaw, ok := strings.Cut(raw, "/"); ok && !strings.Contains(raw, " ") {
+		start, err := time.Parse(time.RFC3339, startRaw)
+		if err != nil {
+			return maintenanceWindow{}, fmt.Errorf("invalid start: %w", err)
+		}
+
+		end, err := time.Parse(time.RFC3339, endRaw)
+		if err != nil {
+			return maintenanceWindow{}, fmt.Errorf("invalid end: %w", err)
+		}
+
+		if !end.After(start) {
+			return maintenanceWindow{}, errors.New("end must be after start")
+		}
+
+		return maintenanceWindow{start: start, end: end}, nil
+	}
+
+	fields := strings.Fields(raw)
+	if len(fields) != 6 {
+		return maintenanceWindow{}, errors.New("expected an RFC 3339 interval or a cron expression and a duration")
+	}
+
+	duration, err := time.ParseDuration(fields[5])
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("invalid duration: %w", err)
+	}
+
+	if duration <= 0 || duration > maxCronWindow {
+		return maintenanceWindow{}, fmt.Errorf("duration must be positive and at most %s", maxCronWindow)
+	}
+
+	cron, err := parseCron(fields[:5])
+	if err != nil {
+		return maintenanceWindow{}, err
+	}
+
+	return maintenanceWindow{cron: cron, duration: duration}, nil
+}
+
+// isActive returns whether the window is active at now
+func (w *maintenanceWindow) isActive(now time.Time) bool {
+	if w.cron == nil {
+		return !now.Before(w.start) && now.Before(w.end)
+	}
+
+	// Active when the window started within the last duration
+	now = now.UTC()
+	for start := now.Truncate(time.Minute); now.Sub(start) < w.duration; start = start.Add(-time.Minute) {
+		if w.cron.matches(start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cronSchedule is a parsed 5-field cron expression
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64 // bit sets of the allowed values
+	// domAny and dowAny record a '*' day field: when both day fields are
+	// restricted, a day matching either matches (as in cron)
+	domAny, dowAny bool
+}
+
+// cronFields are the bounds of the cron fields
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are Sunday
+}
+
+// parseCron parses the minute, hour, day of month, month and day of week
+// fields. Each field is '*' or a list of values, ranges (a-b) and steps (*/n, a-b/n).
+func parseCron(fields []string) (*cronSchedule, error) {
+	sets := make([]uint64, len(cronFields))
+
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", cronFields[i].name, field, err)
+		}
+
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the bit set of the values of a cron field
+func parseCronField(field string, low, high int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := low, high
+
+		if rangePart != "*" {
+			startRaw, endRaw, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+
+			start, err = strconv.Atoi(startRaw)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", startRaw)
+			}
+
+			end = start
+
+			if isRange {
+				end, err = strconv.Atoi(endRaw)
+				if err != nil {
+					return 0, fmt.Errorf("invalid value %q", endRaw)
+				}
+			} else if hasStep {
+				end = high
+			}
+		}
+
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("values must be within %d-%d", low, high)
+		}
+
+		for value := start; value <= end; value += step {
+			set |= 1 << value
+		}
+	}
+
+	return set, nil
+}
+
+// matches returns whether the minute of t matches the schedule
+func (s *cronSchedule) matches(t time.Time) bool {
+	if s.minute&(1<<t.Minute()) == 0 || s.hour&(1<<t.Hour()) == 0 || s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// maintenanceHosts returns the Ingress hosts with an active maintenance window.
+// Must be called with c.mu held.
+func (c *Collector) maintenanceHosts(now time.Time) map[string]bool {
+	var hosts map[string]bool
+
+	for _, discovered := range c.discovered[sourceIngress] {
+		for i := range discovered.maintenance {
+			if !discovered.maintenance[i].isActive(now) {
+				continue
+			}
+
+			if hosts == nil {
+				hosts = make(map[string]bool)
+			}
+
+			hosts[discovered.host] = true
+
+			break
+		}
+	}
+
+	return hosts
+}
+
+// forwardMaintenance copies metrics from source to dest until source is
+// closed, labelling or dropping the series of the hosts in maintenance
+func (c *Collector) forwardMaintenance(
+	source <-chan prometheus.Metric,
+	dest chan<- prometheus.Metric,
+	hosts map[string]bool,
+) {
+	for metric := range source {
+		var out dto.Metric
+		if err := metric.Write(&out); err != nil || !hosts[domainLabel(out.GetLabel())] {
+			dest <- metric
+			continue
+		}
+
+		if c.config.MaintenanceMode == maintenanceModeSuppress {
+			continue
+		}
+
+		dest <- &maintenanceMetric{Metric: metric}
+	}
+}
+
+// domainLabel returns the value of the domain label, empty if none
+func domainLabel(labels []*dto.LabelPair) string {
+	for _, label := range labels {
+		if label.GetName() == "domain" {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+// maintenanceMetric wraps the metric of a host in maintenance and adds the
+// in_maintenance label
+type maintenanceMetric struct {
+	prometheus.Metric
+}
+
+// Write implements prometheus.Metric by adding the label
+func (m *maintenanceMetric) Write(out *dto.Metric) error {
+	if err := m.Metric.Write(out); err != nil {
+		return err
+	}
+
+	name, value := inMaintenanceLabel, "true"
+	out.Label = append(out.Label, &dto.LabelPair{Name: &name, Value: &value})
+
+	return nil
+}
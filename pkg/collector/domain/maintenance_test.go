@@ -0,0 +1,129 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	windows, err := parseMaintenanceWindows(
+		"2026-10-20T01:00:00Z/2026-10-20T03:00:00Z; 0 2 * * 6 2h; 30 22 1-7 * 1 90m",
+	)
+	if err != nil {
+		t.Fatalf("Failed to parse windows: %v", err)
+	}
+
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(windows))
+	}
+
+	tests := []struct {
+		window int
+		at     string
+		active bool
+	}{
+		{0, "2026-10-20T01:00:00Z", true},
+		{0, "2026-10-20T03:00:00Z", false},
+		// Saturday 2026-10-17
+		{1, "2026-10-17T02:00:00Z", true},
+		{1, "2026-10-17T03:59:00Z", true},
+		{1, "2026-10-17T04:00:00Z", false},
+		{1, "2026-10-18T02:30:00Z", false},
+		// Both day fields restricted: the 1st-7th or any Monday
+		{2, "2026-10-03T23:00:00Z", true},
+		{2, "2026-10-19T23:59:00Z", true},
+		{2, "2026-10-20T00:00:00Z", false},
+		{2, "2026-10-21T23:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := windows[tt.window].isActive(at); got != tt.active {
+			t.Errorf("Window %d at %s: expected active=%v, got %v", tt.window, tt.at, tt.active, got)
+		}
+	}
+}
+
+func TestParseMaintenanceWindowsInvalid(t *testing.T) {
+	for _, value := range []string{
+		"2026-10-20T03:00:00Z/2026-10-20T01:00:00Z",
+		"0 2 * * 6",
+		"0 24 * * * 1h",
+		"*/0 * * * * 1h",
+		"0 2 * * 6 30d",
+		"0 2 * * 6 720h",
+	} {
+		if _, err := parseMaintenanceWindows(value); err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestCollectMaintenance(t *testing.T) {
+	now := time.Now()
+
+	collect := func(mode string) map[string]string {
+		logger := log.NewEntry(log.StandardLogger())
+		c := &Collector{
+			BaseCollector: base.NewBaseCollector(collectorName, logger),
+			config:        &Config{DiscoverIngresses: true, MaintenanceMode: mode},
+			discovered: map[string][]discoveredHost{sourceIngress: {
+				{host: "app.example.com", namespace: "ns-a", ingress: "web", maintenance: []maintenanceWindow{
+					{start: now.Add(-time.Minute), end: now.Add(time.Hour)},
+				}},
+				{host: "api.example.com", namespace: "ns-a", ingress: "api"},
+			}},
+			domains: map[string]*DomainHealth{
+				"app.example.com": {Domain: "app.example.com"},
+				"api.example.com": {Domain: "api.example.com", ResolveOk: true},
+			},
+			logger: logger,
+		}
+		c.initMetrics("sealos")
+
+		ch := make(chan prometheus.Metric, 100)
+		c.collect(ch)
+		close(ch)
+
+		// key: domain, value: in_maintenance label of its resolve series
+		resolve := make(map[string]string)
+
+		for metric := range ch {
+			if metric.Desc() != c.domainHealth {
+				continue
+			}
+
+			var m dto.Metric
+			if err := metric.Write(&m); err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["type"] == "resolve" {
+				resolve[labels["domain"]] = labels[inMaintenanceLabel]
+			}
+		}
+
+		return resolve
+	}
+
+	resolve := collect(maintenanceModeLabel)
+	if len(resolve) != 2 || resolve["app.example.com"] != "true" || resolve["api.example.com"] != "" {
+		t.Errorf("Expected only the host in maintenance to be labelled, got %v", resolve)
+	}
+
+	resolve = collect(maintenanceModeSuppress)
+	if _, ok := resolve["app.example.com"]; ok || len(resolve) != 1 {
+		t.Errorf("Expected the host in maintenance to be suppressed, got %v", resolve)
+	}
+}
@@ -0,0 +1,333 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TLSPortsAnnotation declares the TLS ports of the hosts of an Ingress whose
+// certificate is checked besides 443, as a comma-separated list,
+// e.g. probe.sealos.io/tls-ports: "8443,9443"
+const TLSPortsAnnotation = "probe.sealos.io/tls-ports"
+
+// probeEndpoint is a scheme and port the IPs of a domain are checked on
+type probeEndpoint struct {
+	scheme string
+	port   string
+}
+
+// defaultEndpoint is checked when no port is inferred for a domain
+var defaultEndpoint = probeEndpoint{scheme: "https", port: "443"}
+
+// PortHealth is the outcome of the HTTP check of an IP on a single port
+type PortHealth struct {
+	Port          string
+	HTTPOk        bool
+	HTTPErrorType ErrorType
+}
+
+// PortCert is the outcome of the certificate check of a domain on a TLS port
+type PortCert struct {
+	Port          string
+	CertOk        bool
+	CertErrorType ErrorType
+	CertExpiry    time.Duration
+}
+
+// ingressEndpoints returns the endpoints of an Ingress rule: the ports of its
+// backend Services, over https when the Ingress terminates TLS for the host.
+// Named ports are resolved through the Service, cached in services (key:
+// namespace/name, nil when the Service cannot be read).
+func (c *Collector) ingressEndpoints(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	rule networkingv1.IngressRule,
+	services map[string]*corev1.Service,
+) []probeEndpoint {
+	scheme := "http"
+
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			if strings.EqualFold(host, rule.Host) {
+				scheme = "https"
+			}
+		}
+	}
+
+	var backends []networkingv1.IngressBackend
+	if rule.HTTP != nil {
+		for _, path := range rule.HTTP.Paths {
+			backends = append(backends, path.Backend)
+		}
+	}
+
+	if len(backends) == 0 && ingress.Spec.DefaultBackend != nil {
+		backends = append(backends, *ingress.Spec.DefaultBackend)
+	}
+
+	var endpoints []probeEndpoint
+
+	for _, backend := range backends {
+		if backend.Service == nil {
+			continue
+		}
+
+		port := c.backendPort(ctx, ingress.Namespace, backend.Service, services)
+		if port == 0 {
+			continue
+		}
+
+		endpoints = append(endpoints, probeEndpoint{scheme: scheme, port: strconv.Itoa(int(port))})
+	}
+
+	return sortEndpoints(endpoints)
+}
+
+// backendPort returns the port number of an Ingress backend, 0 when a named
+// port cannot be resolved
+func (c *Collector) backendPort(
+	ctx context.Context,
+	namespace string,
+	backend *networkingv1.IngressServiceBackend,
+	services map[string]*corev1.Service,
+) int32 {
+	if backend.Port.Number != 0 {
+		return backend.Port.Number
+	}
+
+	key := namespace + "/" + backend.Name
+
+	service, ok := services[key]
+	if !ok {
+		var err error
+
+		service, err = c.client.CoreV1().Services(namespace).Get(ctx, backend.Name, metav1.GetOptions{})
+		if err != nil {
+			c.logger.WithError(err).WithFields(log.Fields{
+				"namespace": namespace,
+				"service":   backend.Name,
+			}).Debug("Failed to get Ingress backend Service, port not inferred")
+
+			service = nil
+		}
+
+		services[key] = service
+	}
+
+	if service == nil {
+		return 0
+	}
+
+	for _, port := range service.Spec.Ports {
+		if port.Name == backend.Port.Name {
+			return port.Port
+		}
+	}
+
+	return 0
+}
+
+// hostEndpoints returns the endpoints inferred for a domain from the
+// Ingresses listing it, nil when none is inferred
+func (c *Collector) hostEndpoints(domain string) []probeEndpoint {
+	if !c.config.InferIngressPorts {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var endpoints []probeEndpoint
+
+	for _, discovered := range c.discovered[sourceIngress] {
+		if discovered.host == domain {
+			endpoints = append(endpoints, discovered.endpoints...)
+		}
+	}
+
+	return sortEndpoints(endpoints)
+}
+
+// sortEndpoints sorts endpoints by port and scheme and removes duplicates
+func sortEndpoints(endpoints []probeEndpoint) []probeEndpoint {
+	sort.Slice(endpoints, func(i, j int) bool {
+		pi, _ := strconv.Atoi(endpoints[i].port)
+		pj, _ := strconv.Atoi(endpoints[j].port)
+
+		if pi != pj {
+			return pi < pj
+		}
+
+		return endpoints[i].scheme < endpoints[j].scheme
+	})
+
+	unique := endpoints[:0]
+	for i, endpoint := range endpoints {
+		if i == 0 || endpoint != endpoints[i-1] {
+			unique = append(unique, endpoint)
+		}
+	}
+
+	return unique
+}
+
+// collectPortTimeouts emits whether the HTTP check of each IP timed out on
+// each probed port.
+// Must be called with c.mu held.
+func (c *Collector) collectPortTimeouts(ch chan<- prometheus.Metric, ipHealth *IPHealth) {
+	for _, port := range ipHealth.Ports {
+		ch <- prometheus.MustNewConstMetric(
+			c.ipTimeout,
+			prometheus.GaugeValue,
+			boolToFloat64(port.HTTPErrorType == ErrorTypeTimeout),
+			ipHealth.Domain,
+			ipHealth.IP,
+			port.Port,
+		)
+	}
+}
+
+// parseTLSPorts parses the value of the TLS ports annotation
+func parseTLSPorts(value string) ([]string, error) {
+	var ports []string
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		port, err := strconv.Atoi(field)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %q", field)
+		}
+
+		ports = append(ports, strconv.Itoa(port))
+	}
+
+	return ports, nil
+}
+
+// ingressTLSPorts returns the TLS ports annotated on an Ingress. Invalid
+// annotations are ignored.
+func (c *Collector) ingressTLSPorts(ingress *networkingv1.Ingress) []string {
+	value, ok := ingress.Annotations[TLSPortsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	ports, err := parseTLSPorts(value)
+	if err != nil {
+		c.logger.WithError(err).WithFields(log.Fields{
+			"namespace": ingress.Namespace,
+			"ingress":   ingress.Name,
+		}).Warn("Ignoring invalid TLS ports annotation")
+
+		return nil
+	}
+
+	return ports
+}
+
+// hostTLSPorts returns the ports besides 443 the certificate of a domain is
+// checked on: the TLS ports annotated on the Ingresses listing it and the
+// https endpoints inferred from their backends, sorted
+func (c *Collector) hostTLSPorts(domain string) []string {
+	if !c.config.IncludeCertCheck {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var endpoints []probeEndpoint
+
+	for _, discovered := range c.discovered[sourceIngress] {
+		if discovered.host != domain {
+			continue
+		}
+
+		for _, port := range discovered.tlsPorts {
+			endpoints = append(endpoints, probeEndpoint{scheme: "https", port: port})
+		}
+
+		for _, endpoint := range discovered.endpoints {
+			if endpoint.scheme == "https" {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+
+	var ports []string
+
+	for _, endpoint := range sortEndpoints(endpoints) {
+		if endpoint.port != defaultEndpoint.port {
+			ports = append(ports, endpoint.port)
+		}
+	}
+
+	return ports
+}
+
+// checkPortCert checks the certificate presented by a domain on a TLS port
+func (dc *DomainChecker) checkPortCert(ctx context.Context, domain, port string, logger *log.Entry) PortCert {
+	result := PortCert{Port: port, CertErrorType: ErrorTypeNone}
+
+	certInfo, err := dc.fetchCert(ctx, domain, "", port)
+
+	switch {
+	case err != nil:
+		result.CertErrorType = dc.classifier.ClassifyCertError(err.Error())
+	case !certInfo.IsValid:
+		result.CertErrorType = ErrorTypeCertExpired
+	default:
+		result.CertOk = true
+		result.CertExpiry = certInfo.ExpiresIn
+	}
+
+	logger.WithFields(log.Fields{
+		"domain":    domain,
+		"port":      port,
+		"success":   result.CertOk,
+		"errorType": result.CertErrorType,
+		"expiresIn": result.CertExpiry,
+	}).Debug("TLS port certificate check completed")
+
+	return result
+}
+
+// collectTLSPorts emits the outcome of the certificate check of a domain on
+// each of its TLS ports besides 443.
+// Must be called with c.mu held.
+func (c *Collector) collectTLSPorts(ch chan<- prometheus.Metric, domainHealth *DomainHealth) {
+	for _, port := range domainHealth.TLSPorts {
+		ch <- prometheus.MustNewConstMetric(
+			c.portCertStatus,
+			prometheus.GaugeValue,
+			boolToFloat64(port.CertOk),
+			domainHealth.Domain,
+			port.Port,
+			string(port.CertErrorType),
+		)
+
+		if port.CertOk && port.CertExpiry > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.portCertExpiry,
+				prometheus.GaugeValue,
+				port.CertExpiry.Seconds(),
+				domainHealth.Domain,
+				port.Port,
+			)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIngressEndpoints(t *testing.T) {
+	backend := func(service string, port networkingv1.ServiceBackendPort) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{Backend: networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{Name: service, Port: port},
+		}}
+	}
+
+	client := fake.NewClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "gateway"},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+				{Name: "http", Port: 8080},
+				{Name: "https", Port: 8443},
+			}},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "web"},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"app.example.com"}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "app.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							backend("gateway", networkingv1.ServiceBackendPort{Name: "https"}),
+							backend("missing", networkingv1.ServiceBackendPort{Name: "https"}),
+						},
+					}},
+				}},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "web-plain"},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "app.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							backend("gateway", networkingv1.ServiceBackendPort{Number: 8080}),
+						},
+					}},
+				}},
+			},
+		},
+	)
+
+	c := &Collector{
+		config:     &Config{DiscoverIngresses: true, InferIngressPorts: true},
+		client:     client,
+		discovered: make(map[string][]discoveredHost),
+		logger:     log.NewEntry(log.StandardLogger()),
+	}
+
+	c.targets(context.Background())
+
+	expected := []probeEndpoint{{scheme: "http", port: "8080"}, {scheme: "https", port: "8443"}}
+	if got := c.hostEndpoints("app.example.com"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected endpoints %v, got %v", expected, got)
+	}
+
+	if got := c.hostEndpoints("other.example.com"); len(got) != 0 {
+		t.Errorf("Expected no endpoint for an unknown host, got %v", got)
+	}
+}
+
+func TestCheckIPPorts(t *testing.T) {
+	release := make(chan struct{})
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fast.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	port := func(server *httptest.Server) string {
+		_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		return port
+	}
+
+	dc := NewDomainChecker(200*time.Millisecond, true, true, false)
+	health := dc.checkIP(
+		context.Background(),
+		"app.example.com",
+		"127.0.0.1",
+		[]probeEndpoint{{scheme: "http", port: port(fast)}, {scheme: "http", port: port(slow)}},
+		nil,
+		time.Now(),
+		nil,
+		nil,
+		log.NewEntry(log.StandardLogger()),
+	)
+
+	if health.HTTPOk || health.HTTPErrorType != ErrorTypeTimeout {
+		t.Errorf("Expected the timeout of the second port to fail the IP, got %v (%s)", health.HTTPOk, health.HTTPErrorType)
+	}
+
+	if health.ResponseTime <= 0 {
+		t.Error("Expected the response time of the first port")
+	}
+
+	expected := []PortHealth{
+		{Port: port(fast), HTTPOk: true, HTTPErrorType: ErrorTypeNone},
+		{Port: port(slow), HTTPOk: false, HTTPErrorType: ErrorTypeTimeout},
+	}
+	if !reflect.DeepEqual(health.Ports, expected) {
+		t.Errorf("Expected ports %+v, got %+v", expected, health.Ports)
+	}
+}
+
+func TestHostTLSPorts(t *testing.T) {
+	ingress := func(name, ports string, backendPort int32) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns-a",
+				Name:        name,
+				Annotations: map[string]string{TLSPortsAnnotation: ports},
+			},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"app.example.com"}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "app.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: "gateway",
+								Port: networkingv1.ServiceBackendPort{Number: backendPort},
+							},
+						}}},
+					}},
+				}},
+			},
+		}
+	}
+
+	client := fake.NewClientset(
+		ingress("web", "9443, 443,8443", 443),
+		ingress("web-admin", "8443", 10443),
+		ingress("web-invalid", "8443,https", 443),
+	)
+
+	c := &Collector{
+		config: &Config{
+			DiscoverIngresses: true,
+			InferIngressPorts: true,
+			IncludeCertCheck:  true,
+		},
+		client:     client,
+		discovered: make(map[string][]discoveredHost),
+		logger:     log.NewEntry(log.StandardLogger()),
+	}
+
+	c.targets(context.Background())
+
+	// 443 is always checked, the ports of the other Ingresses are merged
+	expected := []string{"8443", "9443", "10443"}
+	if got := c.hostTLSPorts("app.example.com"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected TLS ports %v, got %v", expected, got)
+	}
+
+	c.config.IncludeCertCheck = false
+
+	if got := c.hostTLSPorts("app.example.com"); len(got) != 0 {
+		t.Errorf("Expected no TLS port without certificate checks, got %v", got)
+	}
+}
+
+func TestParseTLSPorts(t *testing.T) {
+	ports, err := parseTLSPorts(" 8443,,09443 ")
+	if err != nil {
+		t.Fatalf("parseTLSPorts() error = %v", err)
+	}
+
+	if expected := []string{"8443", "9443"}; !reflect.DeepEqual(ports, expected) {
+		t.Errorf("Expected %v, got %v", expected, ports)
+	}
+
+	for _, value := range []string{"https", "0", "65536", "-1"} {
+		if _, err := parseTLSPorts(value); err == nil {
+			t.Errorf("Expected error for %q, got nil", value)
+		}
+	}
+}
+
+func TestCheckPortCert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("SplitHostPort() error = %v", err)
+	}
+
+	dc := NewDomainChecker(time.Second, false, false, true)
+
+	var records []auditRecord
+
+	dc.audit = func(record auditRecord) { records = append(records, record) }
+
+	// The test server certificate is not trusted
+	result := dc.checkPortCert(context.Background(), host, port, log.NewEntry(log.StandardLogger()))
+	if result.Port != port || result.CertOk || result.CertErrorType == ErrorTypeNone {
+		t.Errorf("Expected an untrusted certificate on port %s, got %+v", port, result)
+	}
+
+	// The handshake reached the port
+	if len(records) != 1 || records[0].target != net.JoinHostPort(host, port) ||
+		!strings.Contains(records[0].err, "x509") {
+		t.Errorf("Expected a handshake with %s, got %+v", net.JoinHostPort(host, port), records)
+	}
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// quorumLabel marks the ConfigMaps holding the check reports of instances
+	quorumLabel = "state-metrics.sealos.io/domain-quorum"
+	// quorumNamePrefix prefixes the report ConfigMap name of each instance
+	quorumNamePrefix = "sealos-state-metrics-domain-"
+	// quorumDataKey is the ConfigMap key of the JSON encoded report
+	quorumDataKey = "report.json"
+)
+
+// quorumReport holds the domain check results published by one instance
+type quorumReport struct {
+	Instance  string          `json:"instance"`
+	CheckedAt time.Time       `json:"checkedAt"`
+	Domains   map[string]bool `json:"domains"` // key: domain, value: up
+}
+
+// QuorumStatus is the state of a domain consolidated across instances
+type QuorumStatus struct {
+	Domain string
+	// Reporters is the state reported by each instance (key: instance, value: up)
+	Reporters map[string]bool
+	// DownVotes is the number of instances reporting the domain down
+	DownVotes int
+	// Quorum is the number of down votes needed to mark the domain down
+	Quorum int
+	// Down is true when a quorum of instances reports the domain down
+	Down bool
+}
+
+// domainUp returns whether a domain resolves and has at least one healthy IP
+func domainUp(health *DomainHealth) bool {
+	return health.ResolveOk && health.HealthyIPs > 0
+}
+
+// quorumMaxAge returns the age after which reports of other instances are ignored
+func (c *Collector) quorumMaxAge() time.Duration {
+	if c.config.QuorumMaxAge > 0 {
+		return c.config.QuorumMaxAge
+	}
+
+	return 3 * c.config.CheckInterval
+}
+
+// syncQuorum publishes the results of this instance, then consolidates the
+// reports of all instances. The previous consolidated state is kept when the
+// reports cannot be listed.
+func (c *Collector) syncQuorum(ctx context.Context, domains map[string]*DomainHealth) {
+	report := quorumReport{
+		Instance:  c.identity,
+		CheckedAt: time.Now(),
+		Domains:   make(map[string]bool, len(domains)),
+	}
+
+	for domain, health := range domains {
+		report.Domains[domain] = domainUp(health)
+	}
+
+	if err := c.publishReport(ctx, report); err != nil {
+		c.logger.WithError(err).Warn("Failed to publish domain check report")
+	}
+
+	reports, err := c.listReports(ctx)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to list domain check reports, keeping previous quorum results")
+		return
+	}
+
+	statuses := consolidate(reports, time.Now(), c.quorumMaxAge(), c.config.QuorumSize)
+
+	c.mu.Lock()
+	previous := c.quorum
+	c.quorum = statuses
+	c.mu.Unlock()
+
+	c.logQuorumChange(previous, statuses)
+}
+
+// reportName returns the name of the report ConfigMap of an instance
+func reportName(instance string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, instance)
+
+	name = strings.Trim(quorumNamePrefix+name, "-.")
+
+	const maxNameLength = 253
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-.")
+	}
+
+	return name
+}
+
+// publishReport creates or updates the report ConfigMap of this instance
+func (c *Collector) publishReport(ctx context.Context, report quorumReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reportName(report.Instance),
+			Namespace: c.config.QuorumNamespace,
+			Labels:    map[string]string{quorumLabel: "true"},
+		},
+		Data: map[string]string{quorumDataKey: string(data)},
+	}
+
+	configMaps := c.client.CoreV1().ConfigMaps(c.config.QuorumNamespace)
+
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	}
+
+	return err
+}
+
+// deleteReport removes the report ConfigMap of this instance, so it stops
+// counting towards the quorum before its results expire
+func (c *Collector) deleteReport(ctx context.Context) {
+	err := c.client.CoreV1().ConfigMaps(c.config.QuorumNamespace).
+		Delete(ctx, reportName(c.identity), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		c.logger.WithError(err).Warn("Failed to delete domain check report")
+	}
+}
+
+// listReports returns the reports published by all instances
+func (c *Collector) listReports(ctx context.Context) ([]quorumReport, error) {
+	configMaps, err := c.client.CoreV1().ConfigMaps(c.config.QuorumNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: quorumLabel + "=true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]quorumReport, 0, len(configMaps.Items))
+
+	for i := range configMaps.Items {
+		var report quorumReport
+		if err := json.Unmarshal([]byte(configMaps.Items[i].Data[quorumDataKey]), &report); err != nil {
+			c.logger.WithError(err).WithField("configmap", configMaps.Items[i].Name).
+				Debug("Ignoring invalid domain check report")
+
+			continue
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// consolidate computes the state of each domain from the reports checked within
+// maxAge. A domain is down when at least size instances report it down, or a
+// majority of the instances reporting it when size is 0.
+func consolidate(reports []quorumReport, now time.Time, maxAge time.Duration, size int) map[string]*QuorumStatus {
+	statuses := make(map[string]*QuorumStatus)
+
+	for _, report := range reports {
+		if now.Sub(report.CheckedAt) > maxAge {
+			continue
+		}
+
+		for domain, up := range report.Domains {
+			status, ok := statuses[domain]
+			if !ok {
+				status = &QuorumStatus{Domain: domain, Reporters: make(map[string]bool)}
+				statuses[domain] = status
+			}
+
+			status.Reporters[report.Instance] = up
+
+			if !up {
+				status.DownVotes++
+			}
+		}
+	}
+
+	for _, status := range statuses {
+		status.Quorum = size
+		if status.Quorum <= 0 {
+			status.Quorum = len(status.Reporters)/2 + 1
+		}
+
+		status.Down = status.DownVotes >= status.Quorum
+	}
+
+	return statuses
+}
+
+// collectQuorum emits the consolidated and per-instance state of each domain.
+// Must be called with c.mu held.
+func (c *Collector) collectQuorum(ch chan<- prometheus.Metric) {
+	for _, status := range c.quorum {
+		ch <- prometheus.MustNewConstMetric(
+			c.quorumUp,
+			prometheus.GaugeValue,
+			boolToFloat64(!status.Down),
+			status.Domain,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.quorumDownVotes,
+			prometheus.GaugeValue,
+			float64(status.DownVotes),
+			status.Domain,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.quorumReports,
+			prometheus.GaugeValue,
+			float64(len(status.Reporters)),
+			status.Domain,
+		)
+
+		for reporter, up := range status.Reporters {
+			ch <- prometheus.MustNewConstMetric(
+				c.quorumReportUp,
+				prometheus.GaugeValue,
+				boolToFloat64(up),
+				status.Domain,
+				reporter,
+			)
+		}
+	}
+}
+
+// logQuorumChange logs domains whose consolidated state changed
+func (c *Collector) logQuorumChange(previous, current map[string]*QuorumStatus) {
+	for domain, status := range current {
+		old, ok := previous[domain]
+		if ok && old.Down == status.Down {
+			continue
+		}
+
+		c.logger.WithFields(log.Fields{
+			"domain":    domain,
+			"down":      status.Down,
+			"downVotes": status.DownVotes,
+			"reports":   len(status.Reporters),
+		}).Info("Domain quorum state changed")
+	}
+}
@@ -0,0 +1,135 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConsolidate(t *testing.T) {
+	now := time.Now()
+
+	reports := []quorumReport{
+		{
+			Instance:  "node-a",
+			CheckedAt: now,
+			Domains:   map[string]bool{"a.example.com": false, "b.example.com": false},
+		},
+		{
+			Instance:  "node-b",
+			CheckedAt: now.Add(-time.Minute),
+			Domains:   map[string]bool{"a.example.com": true, "b.example.com": false},
+		},
+		{
+			Instance:  "node-c",
+			CheckedAt: now,
+			Domains:   map[string]bool{"a.example.com": true, "b.example.com": true},
+		},
+		// Stale reports do not vote
+		{
+			Instance:  "node-d",
+			CheckedAt: now.Add(-time.Hour),
+			Domains:   map[string]bool{"a.example.com": false},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		size      int
+		domain    string
+		downVotes int
+		quorum    int
+		down      bool
+	}{
+		{name: "single bad egress", domain: "a.example.com", downVotes: 1, quorum: 2, down: false},
+		{name: "majority down", domain: "b.example.com", downVotes: 2, quorum: 2, down: true},
+		{name: "explicit size", size: 3, domain: "b.example.com", downVotes: 2, quorum: 3, down: false},
+		{name: "explicit size of one", size: 1, domain: "a.example.com", downVotes: 1, quorum: 1, down: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := consolidate(reports, now, 10*time.Minute, tt.size)
+
+			status, ok := statuses[tt.domain]
+			if !ok {
+				t.Fatalf("Expected a status for %s", tt.domain)
+			}
+
+			if len(status.Reporters) != 3 {
+				t.Errorf("Expected 3 reporters, got %v", status.Reporters)
+			}
+
+			if status.DownVotes != tt.downVotes || status.Quorum != tt.quorum || status.Down != tt.down {
+				t.Errorf("Expected downVotes=%d quorum=%d down=%v, got %+v",
+					tt.downVotes, tt.quorum, tt.down, *status)
+			}
+		})
+	}
+}
+
+func TestSyncQuorum(t *testing.T) {
+	client := fake.NewClientset()
+	config := &Config{Quorum: true, QuorumNamespace: "monitoring", CheckInterval: time.Minute}
+
+	newCollector := func(identity string) *Collector {
+		return &Collector{
+			config:   config,
+			client:   client,
+			identity: identity,
+			logger:   log.NewEntry(log.StandardLogger()),
+		}
+	}
+
+	a := newCollector("Node-A")
+	b := newCollector("node-b")
+	ctx := context.Background()
+
+	down := map[string]*DomainHealth{"example.com": {Domain: "example.com", ResolveOk: true}}
+	up := map[string]*DomainHealth{"example.com": {Domain: "example.com", ResolveOk: true, HealthyIPs: 1}}
+
+	a.syncQuorum(ctx, down)
+
+	if status := a.quorum["example.com"]; status == nil || !status.Down {
+		t.Fatalf("Expected the only reporter to mark the domain down, got %+v", status)
+	}
+
+	// Republishing updates the report in place
+	a.syncQuorum(ctx, down)
+	b.syncQuorum(ctx, up)
+
+	status := b.quorum["example.com"]
+	if status == nil || status.Down || status.DownVotes != 1 || len(status.Reporters) != 2 {
+		t.Fatalf("Expected one down vote out of two reporters, got %+v", status)
+	}
+
+	if status.Reporters["Node-A"] || !status.Reporters["node-b"] {
+		t.Errorf("Unexpected reporters %v", status.Reporters)
+	}
+
+	a.deleteReport(ctx)
+	b.syncQuorum(ctx, up)
+
+	if status := b.quorum["example.com"]; len(status.Reporters) != 1 {
+		t.Errorf("Expected the deleted report to be ignored, got %v", status.Reporters)
+	}
+}
+
+func TestReportName(t *testing.T) {
+	tests := map[string]string{
+		"node-a":           "sealos-state-metrics-domain-node-a",
+		"Node_A:8080":      "sealos-state-metrics-domain-node-a-8080",
+		"10.0.0.1":         "sealos-state-metrics-domain-10.0.0.1",
+		"pod-1.svc.local-": "sealos-state-metrics-domain-pod-1.svc.local",
+	}
+
+	for instance, want := range tests {
+		if got := reportName(instance); got != want {
+			t.Errorf("reportName(%q) = %q, want %q", instance, got, want)
+		}
+	}
+}
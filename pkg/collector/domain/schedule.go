@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+)
+
+// tick returns the polling interval: the shortest of the check interval, the
+// namespace intervals and the failure retry interval
+func (c *Collector) tick() time.Duration {
+	tick := c.config.CheckInterval
+
+	for _, interval := range c.config.NamespaceIntervals {
+		if interval > 0 && interval < tick {
+			tick = interval
+		}
+	}
+
+	if retry := c.config.FailureRetryInterval; retry > 0 && retry < tick {
+		tick = retry
+	}
+
+	return tick
+}
+
+// targetIntervals returns the check interval of each target: the shortest
+// interval of the namespaces it was discovered in, or the check interval.
+// Must be called with c.mu held.
+func (c *Collector) targetIntervals(targets []string) map[string]time.Duration {
+	intervals := make(map[string]time.Duration, len(targets))
+	for _, domain := range targets {
+		intervals[domain] = c.config.CheckInterval
+	}
+
+	if len(c.config.NamespaceIntervals) == 0 {
+		return intervals
+	}
+
+	// Configured domains have no namespace, they keep the check interval
+	static := make(map[string]bool, len(c.config.Domains))
+	for _, domain := range c.config.Domains {
+		static[domain] = true
+	}
+
+	overridden := make(map[string]bool)
+
+	for _, hosts := range c.discovered {
+		for _, discovered := range hosts {
+			interval, ok := c.config.NamespaceIntervals[discovered.namespace]
+			if !ok || interval <= 0 || static[discovered.host] {
+				continue
+			}
+
+			if !overridden[discovered.host] || interval < intervals[discovered.host] {
+				intervals[discovered.host] = interval
+				overridden[discovered.host] = true
+			}
+		}
+	}
+
+	return intervals
+}
+
+// domainFailing returns whether the last check of a domain failed, on DNS or
+// on any of its IPs
+func domainFailing(health *DomainHealth) bool {
+	return !health.ResolveOk || health.UnhealthyIPs > 0
+}
+
+// dueTargets returns the targets to check now: the ones never checked or
+// whose interval elapsed, then the failing ones whose retry interval elapsed,
+// the least recently checked first, up to the failure retry budget.
+func (c *Collector) dueTargets(targets []string, now time.Time) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	intervals := c.targetIntervals(targets)
+
+	// Ticks are not exactly aligned with the previous checks
+	slack := c.tick() / 2
+
+	var due, retries []string
+
+	for _, domain := range targets {
+		health, ok := c.domains[domain]
+		if !ok {
+			due = append(due, domain)
+			continue
+		}
+
+		elapsed := now.Sub(health.LastChecked)
+
+		switch {
+		case elapsed >= intervals[domain]-slack:
+			due = append(due, domain)
+		case c.config.FailureRetryInterval > 0 && domainFailing(health) &&
+			elapsed >= c.config.FailureRetryInterval-slack:
+			retries = append(retries, domain)
+		}
+	}
+
+	sort.SliceStable(retries, func(i, j int) bool {
+		return c.domains[retries[i]].LastChecked.Before(c.domains[retries[j]].LastChecked)
+	})
+
+	if budget := c.config.FailureRetryBudget; budget > 0 && len(retries) > budget {
+		retries = retries[:budget]
+	}
+
+	return append(due, retries...)
+}
+
+// keepResults copies into the new result maps the previous results of the
+// targets not checked in this cycle. Must be called with c.mu held.
+func (c *Collector) keepResults(
+	targets []string,
+	newDomains map[string]*DomainHealth,
+	newIPs util.Index[*IPHealth],
+	newVIPs util.Index[*IPHealth],
+	newRecords map[string][]*DNSRecordResult,
+) {
+	for _, domain := range targets {
+		if _, checked := newDomains[domain]; checked {
+			continue
+		}
+
+		if health, ok := c.domains[domain]; ok {
+			newDomains[domain] = health
+		}
+
+		if ips, ok := c.ips[domain]; ok {
+			newIPs[domain] = ips
+		}
+
+		if vips, ok := c.vips[domain]; ok {
+			newVIPs[domain] = vips
+		}
+
+		if records, ok := c.dnsRecords[domain]; ok {
+			newRecords[domain] = records
+		}
+	}
+}
+
+// storeResults replaces the results with the ones of this cycle, keeping the
+// previous results of the targets not checked and dropping the results and
+// history of the removed targets. Must be called with c.mu held.
+func (c *Collector) storeResults(
+	targets []string,
+	newDomains map[string]*DomainHealth,
+	newIPs util.Index[*IPHealth],
+	newVIPs util.Index[*IPHealth],
+	newRecords map[string][]*DNSRecordResult,
+	entries []HistoryEntry,
+) {
+	c.keepResults(targets, newDomains, newIPs, newVIPs, newRecords)
+	c.ips = newIPs
+	c.vips = newVIPs
+	c.domains = newDomains
+	c.dnsRecords = newRecords
+
+	for _, entry := range entries {
+		c.recordHistory(entry)
+	}
+
+	c.pruneHistory(targets)
+}
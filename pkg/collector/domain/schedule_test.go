@@ -0,0 +1,168 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestDueTargets(t *testing.T) {
+	now := time.Now()
+	checked := func(ago time.Duration, failing bool) *DomainHealth {
+		health := &DomainHealth{ResolveOk: true, HealthyIPs: 1, LastChecked: now.Add(-ago)}
+		if failing {
+			health.HealthyIPs, health.UnhealthyIPs = 0, 1
+		}
+
+		return health
+	}
+
+	c := &Collector{
+		config: &Config{
+			Domains:              []string{"static.example.com"},
+			CheckInterval:        10 * time.Minute,
+			NamespaceIntervals:   map[string]time.Duration{"critical": time.Minute},
+			FailureRetryInterval: 2 * time.Minute,
+			FailureRetryBudget:   1,
+		},
+		discovered: map[string][]discoveredHost{
+			sourceService: {
+				{host: "critical.example.com", namespace: "critical"},
+				{host: "static.example.com", namespace: "critical"},
+				{host: "failing.example.com", namespace: "other"},
+				{host: "stale-failing.example.com", namespace: "other"},
+				{host: "new.example.com", namespace: "other"},
+			},
+		},
+		domains: map[string]*DomainHealth{
+			"static.example.com":        checked(2*time.Minute, false),
+			"critical.example.com":      checked(time.Minute, false),
+			"failing.example.com":       checked(3*time.Minute, true),
+			"stale-failing.example.com": checked(5*time.Minute, true),
+		},
+	}
+
+	if tick := c.tick(); tick != time.Minute {
+		t.Errorf("Expected a 1m tick, got %s", tick)
+	}
+
+	targets := []string{
+		"critical.example.com",
+		"failing.example.com",
+		"new.example.com",
+		"stale-failing.example.com",
+		"static.example.com",
+	}
+
+	// The configured domain keeps the check interval, the budget only lets
+	// the least recently checked failing domain be retried
+	expected := []string{"critical.example.com", "new.example.com", "stale-failing.example.com"}
+	if got := c.dueTargets(targets, now); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected due targets %v, got %v", expected, got)
+	}
+}
+
+func TestKeepResults(t *testing.T) {
+	c := &Collector{
+		domains: map[string]*DomainHealth{
+			"kept.example.com":    {Domain: "kept.example.com"},
+			"removed.example.com": {Domain: "removed.example.com"},
+			"checked.example.com": {Domain: "checked.example.com"},
+		},
+		ips: util.Index[*IPHealth]{
+			"kept.example.com":    {"10.0.0.1": {Domain: "kept.example.com", IP: "10.0.0.1"}},
+			"removed.example.com": {"10.0.0.2": {Domain: "removed.example.com", IP: "10.0.0.2"}},
+			"checked.example.com": {"10.0.0.3": {Domain: "checked.example.com", IP: "10.0.0.3"}},
+		},
+		vips: util.Index[*IPHealth]{
+			"kept.example.com":    {"192.168.0.1": {Domain: "kept.example.com", IP: "192.168.0.1"}},
+			"removed.example.com": {"192.168.0.1": {Domain: "removed.example.com", IP: "192.168.0.1"}},
+		},
+		dnsRecords: map[string][]*DNSRecordResult{
+			"kept.example.com":    {{Domain: "kept.example.com", Type: recordCNAME, Ok: true}},
+			"removed.example.com": {{Domain: "removed.example.com", Type: recordCNAME, Ok: true}},
+		},
+	}
+
+	newDomains := map[string]*DomainHealth{"checked.example.com": {Domain: "checked.example.com"}}
+	newIPs := util.Index[*IPHealth]{
+		"checked.example.com": {"10.0.0.4": {Domain: "checked.example.com", IP: "10.0.0.4"}},
+	}
+
+	newVIPs := make(util.Index[*IPHealth])
+	newRecords := make(map[string][]*DNSRecordResult)
+
+	c.keepResults([]string{"checked.example.com", "kept.example.com"}, newDomains, newIPs, newVIPs, newRecords)
+
+	if len(newDomains) != 2 || newDomains["kept.example.com"] == nil {
+		t.Errorf("Expected checked and kept domains, got %v", newDomains)
+	}
+
+	if _, ok := newIPs.Get("kept.example.com", "10.0.0.1"); newIPs.Len() != 2 || !ok {
+		t.Errorf("Expected the new IPs of checked domains and the IPs of kept domains, got %v", newIPs)
+	}
+
+	if _, ok := newVIPs.Get("kept.example.com", "192.168.0.1"); newVIPs.Len() != 1 || !ok {
+		t.Errorf("Expected the VIP results of kept domains, got %v", newVIPs)
+	}
+
+	if len(newRecords) != 1 || newRecords["kept.example.com"] == nil {
+		t.Errorf("Expected the DNS record results of kept domains, got %v", newRecords)
+	}
+}
+
+func TestPollDropsRemovedTargets(t *testing.T) {
+	now := time.Now()
+	result := func(domain string) *DomainHealth {
+		return &DomainHealth{Domain: domain, ResolveOk: true, HealthyIPs: 1, LastChecked: now}
+	}
+
+	c := &Collector{
+		config: &Config{Domains: []string{"kept.example.com"}, CheckInterval: 10 * time.Minute},
+		logger: log.NewEntry(log.New()),
+		domains: map[string]*DomainHealth{
+			"kept.example.com":    result("kept.example.com"),
+			"removed.example.com": result("removed.example.com"),
+		},
+		ips: util.Index[*IPHealth]{
+			"kept.example.com":    {"10.0.0.1": {Domain: "kept.example.com", IP: "10.0.0.1"}},
+			"removed.example.com": {"10.0.0.2": {Domain: "removed.example.com", IP: "10.0.0.2"}},
+		},
+		vips: util.Index[*IPHealth]{
+			"removed.example.com": {"192.168.0.1": {Domain: "removed.example.com", IP: "192.168.0.1"}},
+		},
+		dnsRecords: map[string][]*DNSRecordResult{
+			"removed.example.com": {{Domain: "removed.example.com", Type: recordCNAME, Ok: true}},
+		},
+	}
+
+	// Nothing is due, the removed target is dropped anyway
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	if len(c.domains) != 1 || c.domains["kept.example.com"] == nil || c.ips.Len() != 1 {
+		t.Errorf("Expected only the results of the kept target, got %v and %v", c.domains, c.ips)
+	}
+
+	if c.vips.Len() != 0 || len(c.dnsRecords) != 0 {
+		t.Errorf("Expected the VIP and DNS record results of the removed target dropped, got %v and %v",
+			c.vips, c.dnsRecords)
+	}
+
+	// All targets removed
+	c.config.Domains = nil
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	if len(c.domains) != 0 || c.ips.Len() != 0 {
+		t.Errorf("Expected no results once all targets are removed, got %v and %v", c.domains, c.ips)
+	}
+}
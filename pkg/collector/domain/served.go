@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// ServedCertificates returns the certificates served by each IP of the hosts
+// of Ingresses terminating TLS with a secret, for the cert collector to
+// detect gateways serving a certificate older than the secret
+func (c *Collector) ServedCertificates() []collector.ServedCertificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var served []collector.ServedCertificate
+
+	for _, discovered := range c.discovered[sourceIngress] {
+		if discovered.tlsSecret == "" {
+			continue
+		}
+
+		for _, ipHealth := range c.ips[discovered.host] {
+			if ipHealth.CertNotAfter.IsZero() {
+				continue
+			}
+
+			served = append(served, collector.ServedCertificate{
+				Domain:      discovered.host,
+				IP:          ipHealth.IP,
+				Namespace:   discovered.namespace,
+				Secret:      discovered.tlsSecret,
+				NotAfter:    ipHealth.CertNotAfter,
+				Fingerprint: ipHealth.CertFingerprint,
+			})
+		}
+	}
+
+	return served
+}
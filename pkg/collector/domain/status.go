@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Status is the structured state of the Domain collector
+type Status struct {
+	Domains []DomainStatus `json:"domains"`
+}
+
+// DomainStatus is the structured health of a single domain
+type DomainStatus struct {
+	Domain             string     `json:"domain"`
+	ResolveOk          bool       `json:"resolveOk"`
+	ResolveTimeSeconds float64    `json:"resolveTimeSeconds"`
+	IPCount            int        `json:"ipCount"`
+	HealthyIPs         int        `json:"healthyIPs"`
+	UnhealthyIPs       int        `json:"unhealthyIPs"`
+	LastChecked        time.Time  `json:"lastChecked"`
+	IPs                []IPStatus `json:"ips"`
+}
+
+// IPStatus is the structured health of a single IP of a domain
+type IPStatus struct {
+	IP                     string       `json:"ip"`
+	HTTPOk                 bool         `json:"httpOk"`
+	HTTPError              string       `json:"httpError,omitempty"`
+	HTTPErrorType          ErrorType    `json:"httpErrorType,omitempty"`
+	ResponseTimeSeconds    float64      `json:"responseTimeSeconds"`
+	ConnectSeconds         float64      `json:"connectSeconds"`
+	TLSHandshakeSeconds    float64      `json:"tlsHandshakeSeconds"`
+	FirstByteSeconds       float64      `json:"firstByteSeconds"`
+	TLSVersion             string       `json:"tlsVersion,omitempty"`
+	CipherSuite            string       `json:"cipherSuite,omitempty"`
+	HSTS                   bool         `json:"hsts"`
+	CertOk                 bool         `json:"certOk"`
+	CertError              string       `json:"certError,omitempty"`
+	CertErrorType          ErrorType    `json:"certErrorType,omitempty"`
+	CertExpirySeconds      float64      `json:"certExpirySeconds"`
+	CertChainExpirySeconds float64      `json:"certChainExpirySeconds"`
+	Ports                  []PortStatus `json:"ports,omitempty"`
+	LastChecked            time.Time    `json:"lastChecked"`
+}
+
+// PortStatus is the structured outcome of the HTTP check of an IP on a port
+type PortStatus struct {
+	Port          string    `json:"port"`
+	HTTPOk        bool      `json:"httpOk"`
+	HTTPErrorType ErrorType `json:"httpErrorType,omitempty"`
+}
+
+// Status returns the result of the latest check cycle, sorted by domain and IP
+func (c *Collector) Status() any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := Status{Domains: make([]DomainStatus, 0, len(c.domains))}
+
+	for _, domainHealth := range c.domains {
+		status.Domains = append(status.Domains, newDomainStatus(domainHealth))
+	}
+
+	sort.Slice(status.Domains, func(i, j int) bool {
+		return status.Domains[i].Domain < status.Domains[j].Domain
+	})
+
+	index := make(map[string]int, len(status.Domains))
+	for i, domainStatus := range status.Domains {
+		index[domainStatus.Domain] = i
+	}
+
+	for domain, domainIPs := range c.ips {
+		i, ok := index[domain]
+		if !ok {
+			continue
+		}
+
+		for _, ipHealth := range domainIPs {
+			status.Domains[i].IPs = append(status.Domains[i].IPs, newIPStatus(ipHealth))
+		}
+	}
+
+	for _, domainStatus := range status.Domains {
+		sortIPs(domainStatus.IPs)
+	}
+
+	return status
+}
+
+// newDomainStatus converts the domain-level health, without IPs
+func newDomainStatus(domainHealth *DomainHealth) DomainStatus {
+	return DomainStatus{
+		Domain:             domainHealth.Domain,
+		ResolveOk:          domainHealth.ResolveOk,
+		ResolveTimeSeconds: domainHealth.ResolveTime.Seconds(),
+		IPCount:            domainHealth.IPCount,
+		HealthyIPs:         domainHealth.HealthyIPs,
+		UnhealthyIPs:       domainHealth.UnhealthyIPs,
+		LastChecked:        domainHealth.LastChecked,
+		IPs:                []IPStatus{},
+	}
+}
+
+// newIPStatus converts the health of a single IP
+func newIPStatus(ipHealth *IPHealth) IPStatus {
+	ports := make([]PortStatus, 0, len(ipHealth.Ports))
+	for _, port := range ipHealth.Ports {
+		ports = append(ports, PortStatus{Port: port.Port, HTTPOk: port.HTTPOk, HTTPErrorType: port.HTTPErrorType})
+	}
+
+	return IPStatus{
+		IP:                     ipHealth.IP,
+		HTTPOk:                 ipHealth.HTTPOk,
+		HTTPError:              ipHealth.HTTPError,
+		HTTPErrorType:          ipHealth.HTTPErrorType,
+		ResponseTimeSeconds:    ipHealth.ResponseTime.Seconds(),
+		ConnectSeconds:         ipHealth.ConnectTime.Seconds(),
+		TLSHandshakeSeconds:    ipHealth.TLSHandshakeTime.Seconds(),
+		FirstByteSeconds:       ipHealth.FirstByteTime.Seconds(),
+		TLSVersion:             ipHealth.TLSVersion,
+		CipherSuite:            ipHealth.CipherSuite,
+		HSTS:                   ipHealth.HSTS,
+		CertOk:                 ipHealth.CertOk,
+		CertError:              ipHealth.CertError,
+		CertErrorType:          ipHealth.CertErrorType,
+		CertExpirySeconds:      ipHealth.CertExpiry.Seconds(),
+		CertChainExpirySeconds: ipHealth.CertChainExpiry.Seconds(),
+		Ports:                  ports,
+		LastChecked:            ipHealth.LastChecked,
+	}
+}
+
+// sortIPs sorts IP statuses by IP
+func sortIPs(ips []IPStatus) {
+	sort.Slice(ips, func(i, j int) bool {
+		return ips[i].IP < ips[j].IP
+	})
+}
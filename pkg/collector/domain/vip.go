@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// validateVIPs checks that the configured VIPs are IP addresses
+func validateVIPs(vips []string) error {
+	for _, vip := range vips {
+		if net.ParseIP(vip) == nil {
+			return fmt.Errorf("invalid VIP %q: not an IP address", vip)
+		}
+	}
+
+	return nil
+}
+
+// CheckVIPs performs the enabled HTTP and certificate checks of a domain
+// through each gateway VIP, bypassing DNS. The domain is sent as SNI and Host
+// header, so the VIP serves it like a client resolving the domain to it would.
+// Unlike the resolved IPs, every VIP presents its own certificate.
+func (dc *DomainChecker) CheckVIPs(
+	ctx context.Context,
+	domain string,
+	vips []string,
+	criteria *httpCriteria,
+	logger *log.Entry,
+) []*IPHealth {
+	now := time.Now()
+
+	results := make([]*IPHealth, 0, len(vips))
+	for _, vip := range vips {
+		var (
+			certInfo *util.CertInfo
+			certErr  error
+		)
+
+		if dc.checkCert {
+			certInfo, certErr = dc.fetchCert(ctx, domain, vip, defaultEndpoint.port)
+		}
+
+		results = append(results, dc.checkIP(ctx, domain, vip, nil, criteria, now, certInfo, certErr, logger))
+	}
+
+	return results
+}
+
+// collectVIPs emits the outcome of the checks of the domains through the
+// gateway VIPs.
+// Must be called with c.mu held.
+func (c *Collector) collectVIPs(ch chan<- prometheus.Metric) {
+	for _, domainVIPs := range c.vips {
+		for _, vipHealth := range domainVIPs {
+			if c.config.IncludeHTTPCheck {
+				ch <- prometheus.MustNewConstMetric(
+					c.vipStatus,
+					prometheus.GaugeValue,
+					boolToFloat64(vipHealth.HTTPOk),
+					vipHealth.Domain,
+					vipHealth.IP,
+					"http",
+					string(vipHealth.HTTPErrorType),
+					vipHealth.HTTPCriterion,
+				)
+
+				if vipHealth.HTTPOk {
+					ch <- prometheus.MustNewConstMetric(
+						c.vipResponseTime,
+						prometheus.GaugeValue,
+						vipHealth.ResponseTime.Seconds(),
+						vipHealth.Domain,
+						vipHealth.IP,
+					)
+				}
+			}
+
+			if c.config.IncludeCertCheck {
+				ch <- prometheus.MustNewConstMetric(
+					c.vipStatus,
+					prometheus.GaugeValue,
+					boolToFloat64(vipHealth.CertOk),
+					vipHealth.Domain,
+					vipHealth.IP,
+					"cert",
+					string(vipHealth.CertErrorType),
+					"",
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//nolint:testpackage // Tests need access to private functions
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestValidateVIPs(t *testing.T) {
+	if err := validateVIPs([]string{"10.0.0.1", "fd00::1"}); err != nil {
+		t.Errorf("Expected valid VIPs, got %v", err)
+	}
+
+	if err := validateVIPs([]string{"10.0.0.1", "gateway.example.com"}); err == nil {
+		t.Error("Expected error for a host name, got nil")
+	}
+}
+
+// TestCheckVIPs verifies every VIP gets its own HTTP and certificate outcome
+func TestCheckVIPs(t *testing.T) {
+	checker := NewDomainChecker(time.Second, true, true, true)
+
+	var requests []auditRecord
+
+	checker.audit = func(record auditRecord) {
+		requests = append(requests, record)
+	}
+
+	// Nothing listens on port 443 of the loopback addresses
+	results := checker.CheckVIPs(
+		context.Background(),
+		"example.com",
+		[]string{"127.0.0.1", "127.0.0.2"},
+		nil,
+		log.NewEntry(log.StandardLogger()),
+	)
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	for i, vip := range []string{"127.0.0.1", "127.0.0.2"} {
+		result := results[i]
+		if result.Domain != "example.com" || result.IP != vip {
+			t.Errorf("Expected result of example.com through %s, got %s through %s", vip, result.Domain, result.IP)
+		}
+
+		if result.HTTPOk || result.CertOk || result.HTTPErrorType == ErrorTypeNone || result.CertErrorType == ErrorTypeNone {
+			t.Errorf("Expected failed checks through %s, got %+v", vip, result)
+		}
+	}
+
+	// One TLS and one HTTP request per VIP, dialing the VIP
+	if len(requests) != 4 {
+		t.Fatalf("Expected 4 audited requests, got %d", len(requests))
+	}
+
+	for _, request := range requests {
+		if request.ip == "" {
+			t.Errorf("Expected request through a VIP, got %+v", request)
+		}
+	}
+}
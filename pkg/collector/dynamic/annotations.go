@@ -0,0 +1,161 @@
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// ConfigAnnotation is the CRD annotation holding the CRD configs of the
+// resource it defines, as YAML documents in the format of config files
+const ConfigAnnotation = "metrics.sealos.io/config"
+
+// LoadAnnotationConfigs appends the CRD configs advertised by the
+// ConfigAnnotation of the CRDs in the cluster to CRDs, so operators can ship
+// metric definitions with their CRDs. Exporter configs take precedence: an
+// annotation config named like an inline, file or previously loaded
+// annotation config is ignored. Invalid
+// annotations are logged and skipped, they do not fail the collector.
+func (c *CollectorConfig) LoadAnnotationConfigs(
+	ctx context.Context,
+	client dynamic.Interface,
+	logger *log.Entry,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, schemaTimeout)
+	defer cancel()
+
+	list, err := client.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list CRDs: %w", err)
+	}
+
+	names := make(map[string]bool, len(c.CRDs))
+	for i := range c.CRDs {
+		names[c.CRDs[i].Name] = true
+	}
+
+	for i := range list.Items {
+		crd := &list.Items[i]
+		if _, ok := crd.GetAnnotations()[ConfigAnnotation]; !ok {
+			continue
+		}
+
+		crdLogger := logger.WithField("crd", crd.GetName())
+
+		configs, err := annotationConfigs(crd)
+		if err != nil {
+			crdLogger.WithError(err).Warn("Ignoring invalid metrics annotation")
+			continue
+		}
+
+		for j := range configs {
+			if names[configs[j].Name] {
+				crdLogger.WithField("name", configs[j].Name).
+					Info("CRD config is already defined by the exporter, ignoring annotation")
+
+				continue
+			}
+
+			names[configs[j].Name] = true
+			c.CRDs = append(c.CRDs, configs[j])
+		}
+
+		crdLogger.Debug("Loaded CRD configs from metrics annotation")
+	}
+
+	return nil
+}
+
+// annotationConfigs decodes the CRD configs of the ConfigAnnotation of a CRD.
+// The name defaults to the singular resource name, and the GVR to the group, plural and
+// storage version of the CRD; configs may only target the resource the CRD
+// defines. Metrics without help text are described by the schema description
+// of their path.
+func annotationConfigs(crd *unstructured.Unstructured) ([]CRDConfig, error) {
+	configs, err := decodeCRDConfigs(strings.NewReader(crd.GetAnnotations()[ConfigAnnotation]))
+	if err != nil {
+		return nil, err
+	}
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+
+	singular, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "singular")
+	if singular == "" {
+		singular = plural
+	}
+
+	for i := range configs {
+		cfg := &configs[i]
+
+		if cfg.Name == "" {
+			cfg.Name = singular
+		}
+
+		if cfg.GVR.Group == "" && cfg.GVR.Resource == "" {
+			cfg.GVR.Group = group
+			cfg.GVR.Resource = plural
+		}
+
+		if cfg.GVR.Group != group || cfg.GVR.Resource != plural {
+			return nil, fmt.Errorf("CRD config %s targets %s.%s, not the annotated CRD",
+				cfg.Name, cfg.GVR.Resource, cfg.GVR.Group)
+		}
+
+		if cfg.GVR.Version == "" {
+			cfg.GVR.Version = storageVersion(crd.Object)
+		}
+
+		openAPISchema, err := crdVersionSchema(crd.Object, cfg.GVR.Version)
+		if err != nil {
+			return nil, fmt.Errorf("CRD config %s: version %q: %w", cfg.Name, cfg.GVR.Version, err)
+		}
+
+		cfg.describeMetrics(openAPISchema)
+	}
+
+	return configs, nil
+}
+
+// storageVersion returns the storage version of a CRD object
+func storageVersion(crd map[string]any) string {
+	versions, _, _ := unstructured.NestedSlice(crd, "spec", "versions")
+	for _, item := range versions {
+		version, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if storage, _ := version["storage"].(bool); storage {
+			name, _ := version["name"].(string)
+			return name
+		}
+	}
+
+	return ""
+}
+
+// describeMetrics sets the help text of the metrics without one to the schema
+// description of their path, if any, folded on a single line
+func (c *CRDConfig) describeMetrics(openAPISchema map[string]any) {
+	for i := range c.Metrics {
+		m := &c.Metrics[i]
+		if m.Help != "" || m.Path == "" || isJSONPath(m.Path) {
+			continue
+		}
+
+		node, err := resolveSchemaPath(openAPISchema, strings.Split(m.Path, "."))
+		if err != nil || node == nil {
+			continue
+		}
+
+		if description, _ := node["description"].(string); description != "" {
+			m.Help = strings.Join(strings.Fields(description), " ")
+		}
+	}
+}
@@ -0,0 +1,153 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+// annotatedCRD returns a CRD of the apps resource of group, with the metrics
+// annotation when not empty
+func annotatedCRD(name, group, annotation string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]any{
+			"name": name,
+		},
+		"spec": map[string]any{
+			"group": group,
+			"names": map[string]any{"plural": "apps", "singular": "app"},
+			"versions": []any{
+				map[string]any{
+					"name":   "v1beta1",
+					"schema": map[string]any{"openAPIV3Schema": testSchema},
+				},
+				map[string]any{
+					"name":    "v1",
+					"storage": true,
+					"schema": map[string]any{"openAPIV3Schema": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"spec": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"replicas": map[string]any{
+										"type":        "integer",
+										"description": "Desired number\n  of replicas.",
+									},
+								},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}}
+
+	if annotation != "" {
+		crd.SetAnnotations(map[string]string{ConfigAnnotation: annotation})
+	}
+
+	return crd
+}
+
+func TestAnnotationConfigs(t *testing.T) {
+	crd := annotatedCRD("apps.apps.example.com", "apps.example.com", `
+metrics:
+  - type: gauge
+    name: replicas
+    path: spec.replicas
+  - type: gauge
+    name: desired
+    help: "Desired replicas"
+    path: spec.replicas
+---
+name: apps-beta
+gvr:
+  group: apps.example.com
+  version: v1beta1
+  resource: apps
+`)
+
+	configs, err := annotationConfigs(crd)
+	if err != nil {
+		t.Fatalf("annotationConfigs() error = %v", err)
+	}
+
+	if len(configs) != 2 {
+		t.Fatalf("Expected 2 configs, got %d", len(configs))
+	}
+
+	apps := configs[0]
+	if apps.Name != "app" ||
+		apps.GVR != (GVRConfig{Group: "apps.example.com", Version: "v1", Resource: "apps"}) {
+		t.Errorf("Expected defaults from the CRD, got %s %+v", apps.Name, apps.GVR)
+	}
+
+	if apps.Metrics[0].Help != "Desired number of replicas." {
+		t.Errorf("Expected help from the schema description, got %q", apps.Metrics[0].Help)
+	}
+
+	if apps.Metrics[1].Help != "Desired replicas" {
+		t.Errorf("Expected configured help to be kept, got %q", apps.Metrics[1].Help)
+	}
+
+	if configs[1].GVR.Version != "v1beta1" {
+		t.Errorf("Expected configured version to be kept, got %q", configs[1].GVR.Version)
+	}
+}
+
+func TestAnnotationConfigsErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid yaml":    "name: [apps\n",
+		"other resource":  "gvr:\n  group: batch\n  resource: jobs\n",
+		"unknown version": "gvr:\n  group: apps.example.com\n  version: v2\n  resource: apps\n",
+	}
+
+	for name, annotation := range tests {
+		t.Run(name, func(t *testing.T) {
+			crd := annotatedCRD("apps.apps.example.com", "apps.example.com", annotation)
+			if _, err := annotationConfigs(crd); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadAnnotationConfigs(t *testing.T) {
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"},
+		annotatedCRD("apps.apps.example.com", "apps.example.com", "name: apps\n"),
+		annotatedCRD("apps.db.example.com", "db.example.com", "name: db-apps\n"),
+		annotatedCRD("apps.cache.example.com", "cache.example.com", "name: db-apps\n"),
+		annotatedCRD("apps.broken.example.com", "broken.example.com", "name: [apps\n"),
+		annotatedCRD("apps.plain.example.com", "plain.example.com", ""),
+	)
+
+	cfg := NewDefaultCollectorConfig()
+	cfg.CRDs = []CRDConfig{{Name: "apps", GVR: GVRConfig{Group: "apps.example.com", Version: "v1", Resource: "apps"}}}
+
+	if err := cfg.LoadAnnotationConfigs(context.Background(), client, log.NewEntry(log.StandardLogger())); err != nil {
+		t.Fatalf("LoadAnnotationConfigs() error = %v", err)
+	}
+
+	names := make([]string, 0, len(cfg.CRDs))
+	for i := range cfg.CRDs {
+		names = append(names, cfg.CRDs[i].Name)
+	}
+
+	// The exporter config and the first annotation take precedence, invalid
+	// and missing annotations are skipped
+	if got := strings.Join(names, ","); got != "apps,db-apps" {
+		t.Errorf("Unexpected CRD configs %s", got)
+	}
+}
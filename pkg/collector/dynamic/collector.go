@@ -29,6 +29,14 @@ type Config struct {
 
 	// MetricDescriptors are the Prometheus metric descriptors to register
 	MetricDescriptors []*prometheus.Desc
+
+	// Worker is an optional background function run while the collector is
+	// started; it must return once its context is canceled
+	Worker func(ctx context.Context)
+
+	// ValidateConfig is an optional function run on start, before the
+	// controller, to check the configuration (e.g. against the CRD schema)
+	ValidateConfig func(ctx context.Context)
 }
 
 // Collector is a generic dynamic client collector that watches CRDs
@@ -37,7 +45,8 @@ type Collector struct {
 
 	config        *Config
 	dynamicClient dynamic.Interface
-	controllers   []*Controller
+	controller    *Controller
+	cancelWorker  context.CancelFunc
 	logger        *log.Entry
 }
 
@@ -99,41 +108,44 @@ func NewCollector(
 	return c, nil
 }
 
-// start starts all controllers
+// start starts the controller watching the configured namespaces
 func (c *Collector) start(ctx context.Context) error {
-	namespaces := c.config.Namespaces
-	if len(namespaces) == 0 {
-		namespaces = []string{""} // Empty string means all namespaces
+	if c.config.ValidateConfig != nil {
+		c.config.ValidateConfig(ctx)
 	}
 
-	c.controllers = make([]*Controller, 0, len(namespaces))
+	// Start the worker before the controller so it observes the initial list
+	if c.config.Worker != nil {
+		workerCtx, cancel := context.WithCancel(ctx)
+		c.cancelWorker = cancel
 
-	for _, ns := range namespaces {
-		loggerWithNs := c.logger
-		if ns != "" {
-			loggerWithNs = c.logger.WithField("namespace", ns)
-		}
-
-		controllerConfig := &ControllerConfig{
-			GVR:          c.config.GVR,
-			Namespace:    ns,
-			ResyncPeriod: 0, // Use default
-			EventHandler: c.config.EventHandler,
-		}
+		go c.config.Worker(workerCtx)
+	}
 
-		controller, err := NewController(c.dynamicClient, controllerConfig, loggerWithNs)
-		if err != nil {
-			return fmt.Errorf("failed to create controller for namespace %s: %w", ns, err)
-		}
+	controllerConfig := &ControllerConfig{
+		GVR:                c.config.GVR,
+		Namespaces:         c.config.Namespaces,
+		ResyncPeriod:       0, // Use default
+		EventHandler:       c.config.EventHandler,
+		Instrument:         c.InstrumentHandler,
+		InstrumentInformer: c.InstrumentInformer,
+	}
 
-		if err := controller.Start(ctx); err != nil {
-			return fmt.Errorf("failed to start controller for namespace %s: %w", ns, err)
-		}
+	controller, err := NewController(c.dynamicClient, controllerConfig, c.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
 
-		c.controllers = append(c.controllers, controller)
+	if err := controller.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start controller: %w", err)
 	}
 
-	// Mark as ready after all controllers have synced
+	c.controller = controller
+
+	// Restart the collector when the watch of a namespace goes stale
+	c.WatchInformers(controller.Informers()...)
+
+	// Mark as ready after the informers of all namespaces have synced
 	c.SetReady()
 
 	c.logger.Info("Dynamic collector started successfully")
@@ -141,12 +153,24 @@ func (c *Collector) start(ctx context.Context) error {
 	return nil
 }
 
-// stop stops all controllers
+// HasSynced returns true if the informers of the controller have synced
+func (c *Collector) HasSynced() bool {
+	return c.controller != nil && c.controller.HasSynced()
+}
+
+// stop stops the controller
 func (c *Collector) stop() error {
-	for _, ctrl := range c.controllers {
-		if err := ctrl.Stop(); err != nil {
+	if c.cancelWorker != nil {
+		c.cancelWorker()
+		c.cancelWorker = nil
+	}
+
+	if c.controller != nil {
+		if err := c.controller.Stop(); err != nil {
 			c.logger.WithError(err).Warn("Failed to stop controller")
 		}
+
+		c.controller = nil
 	}
 
 	return nil
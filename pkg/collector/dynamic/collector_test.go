@@ -0,0 +1,99 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCollectorRestartsStaleWatch(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps.kubeblocks.io", Version: "v1", Resource: "clusters"}
+
+	// The watch of the fake client delivers nothing, like a watch left
+	// hanging by an API server restart
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ClusterList"},
+	)
+
+	c, err := NewCollector("dynamic-clusters", client, &Config{
+		GVR:          gvr,
+		EventHandler: EventHandlerFuncs{AddFunc: func(*unstructured.Unstructured) {}},
+	}, log.NewEntry(log.New()), base.WithStaleWatchTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewCollector() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.RuntimeStats().InformerRestarts == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the collector to restart on the stale watch")
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if !c.IsStarted() {
+		t.Error("Expected the collector to be started again")
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func TestCollectorInstrumentsInformers(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps.kubeblocks.io", Version: "v1", Resource: "clusters"}
+
+	cluster := func(namespace, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps.kubeblocks.io/v1",
+			"kind":       "Cluster",
+			"metadata":   map[string]any{"name": name, "namespace": namespace},
+		}}
+	}
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ClusterList"},
+		cluster("ns-a", "db-1"),
+		cluster("ns-b", "db-2"),
+		cluster("ns-c", "db-3"),
+	)
+
+	c, err := NewCollector("dynamic-clusters", client, &Config{
+		GVR:          gvr,
+		Namespaces:   []string{"ns-a", "ns-b"},
+		EventHandler: EventHandlerFuncs{AddFunc: func(*unstructured.Unstructured) {}},
+	}, log.NewEntry(log.New()))
+	if err != nil {
+		t.Fatalf("NewCollector() error = %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = c.Stop() })
+
+	// The informers of both namespaces are summed under the resource
+	stats := c.RuntimeStats()
+	if objects := stats.CacheObjects["clusters.apps.kubeblocks.io"]; objects != 2 {
+		t.Errorf("Expected 2 cached clusters, got %v", stats.CacheObjects)
+	}
+}
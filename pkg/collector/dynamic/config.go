@@ -1,11 +1,23 @@
 package dynamic
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // CollectorConfig is the top-level configuration for the configurable dynamic collector
 type CollectorConfig struct {
 	// CRDs defines the CRDs to monitor
 	CRDs []CRDConfig `yaml:"crds" env:"CRDS"`
+
+	// ConfigFiles are glob patterns of YAML files holding more CRD configs,
+	// one per document (e.g. "/etc/dynamic/*.yaml")
+	ConfigFiles []string `yaml:"configFiles" env:"CONFIG_FILES" envSeparator:","`
+
+	// DiscoverAnnotations also loads the CRD configs advertised by the
+	// metrics.sealos.io/config annotation of the CRDs in the cluster
+	DiscoverAnnotations bool `yaml:"discoverAnnotations" env:"DISCOVER_ANNOTATIONS"`
 }
 
 // CRDConfig defines configuration for monitoring a specific CRD
@@ -25,8 +37,35 @@ type CRDConfig struct {
 	// CommonLabels are labels extracted for all metrics from this CRD
 	CommonLabels map[string]string `yaml:"commonLabels"`
 
+	// InjectNamespace adds the resource namespace as the namespace label of
+	// the per-resource metrics, unless a common label already holds it
+	// (default: true)
+	InjectNamespace *bool `yaml:"injectNamespace"`
+
 	// Metrics defines what metrics to expose
 	Metrics []MetricConfig `yaml:"metrics"`
+
+	// Fetches defines additional GETs issued per tracked resource, for fields
+	// the informer objects omit (e.g. the /scale subresource or a related object)
+	Fetches []FetchConfig `yaml:"fetches"`
+
+	// FetchQPS limits the rate of additional GETs (default: 5)
+	FetchQPS float32 `yaml:"fetchQPS"`
+
+	// FetchBurst is the burst allowed above FetchQPS (default: 10)
+	FetchBurst int `yaml:"fetchBurst"`
+
+	// LabelDefaults maps label names to the value used when their path is missing or empty.
+	// Defaults take precedence over MissingLabelPolicy.
+	LabelDefaults map[string]string `yaml:"labelDefaults"`
+
+	// MissingLabelPolicy handles label paths that are missing or empty:
+	// empty (default), unknown or skip (drop the series)
+	MissingLabelPolicy string `yaml:"missingLabelPolicy"`
+
+	// expressions are the programs of the expr metrics compiled by
+	// ValidateExprMetrics, keyed by metric name
+	expressions map[string]*expression
 }
 
 // GVRConfig defines a GroupVersionResource
@@ -36,12 +75,89 @@ type GVRConfig struct {
 	Resource string `yaml:"resource"`
 }
 
+// Validate checks the CRD config and compiles the expressions of its expr
+// metrics. Every collector created from a CRD config validates it first.
+func (c *CRDConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if c.GVR.Resource == "" {
+		return errors.New("gvr.resource is required")
+	}
+
+	for i := range c.Fetches {
+		if err := c.Fetches[i].Validate(); err != nil {
+			return fmt.Errorf("fetch %d: %w", i, err)
+		}
+	}
+
+	validations := []func() error{
+		c.ValidateLabelPolicies,
+		c.ValidateLabelNames,
+		c.ValidateRatioMetrics,
+		c.ValidateHistogramMetrics,
+		c.ValidateExprMetrics,
+		c.ValidatePaths,
+	}
+
+	for _, validate := range validations {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchConfig defines an additional GET issued for each tracked resource.
+// The fetched object is stored under the top-level field As, so metric
+// paths can reference it (e.g. "scale.status.replicas").
+type FetchConfig struct {
+	// As is the top-level field the fetched object is stored under
+	As string `yaml:"as"`
+
+	// Subresource fetches a subresource of the tracked resource (e.g. "status", "scale")
+	Subresource string `yaml:"subresource"`
+
+	// GVR fetches a related object instead of a subresource
+	GVR *GVRConfig `yaml:"gvr"`
+
+	// NamePath is the path to the related object name (default: "metadata.name")
+	NamePath string `yaml:"namePath"`
+
+	// NamespacePath is the path to the related object namespace (default: "metadata.namespace")
+	NamespacePath string `yaml:"namespacePath"`
+}
+
+// Validate checks that exactly one fetch target is configured
+func (f *FetchConfig) Validate() error {
+	if f.As == "" {
+		return errors.New("as is required")
+	}
+
+	if (f.Subresource == "") == (f.GVR == nil) {
+		return errors.New("exactly one of subresource or gvr is required")
+	}
+
+	if f.GVR != nil && f.GVR.Resource == "" {
+		return errors.New("gvr.resource is required")
+	}
+
+	return nil
+}
+
 // MetricConfig defines a metric to expose
 type MetricConfig struct {
-	// Type is the metric type: info, count, gauge, map_state, map_gauge, conditions
+	// Type is the metric type: info, count, sum, min, max, avg, histogram, gauge, ratio, expr, map_state,
+	// map_gauge, conditions
 	// - info: Metadata labels (always value=1)
 	// - count: Aggregate count of resources by field value (value=count)
+	// - sum/min/max/avg: Aggregate of a numeric field across all resources (optionally grouped)
+	// - histogram: Distribution of a numeric field across all resources (optionally grouped)
 	// - gauge: Numeric value from each resource
+	// - ratio: Path divided by DenominatorPath for each resource
+	// - expr: Result of the Expr expression for each resource
 	// - map_state: Current state of each map entry (value=1)
 	// - map_gauge: Numeric value from each map entry
 	// - conditions: Kubernetes-style conditions
@@ -68,8 +184,29 @@ type MetricConfig struct {
 	// KeyLabel is the label name for the map key (for map metrics)
 	KeyLabel string `yaml:"keyLabel"`
 
+	// DenominatorPath is the path to the denominator, Path being the numerator (for ratio metrics)
+	DenominatorPath string `yaml:"denominatorPath"`
+
+	// DivideByZero handles a zero denominator (for ratio metrics):
+	// skip (default, no series), zero (emit 0) or nan (emit NaN)
+	DivideByZero string `yaml:"divideByZero"`
+
+	// Expr is the CEL expression evaluated against each resource
+	// (for expr metrics, e.g. "has(status.readyReplicas) ? double(status.readyReplicas) / double(spec.replicas) : 0.0")
+	Expr string `yaml:"expr"`
+
+	// GroupBy maps label names to paths used to group aggregate metrics (for sum/min/max/avg/histogram)
+	GroupBy map[string]string `yaml:"groupBy"`
+
+	// Buckets are the upper bounds of the histogram buckets, strictly increasing
+	// (for histogram metrics, default: the Prometheus default buckets)
+	Buckets []float64 `yaml:"buckets"`
+
 	// ConditionConfig defines how to parse conditions
 	Condition *ConditionConfig `yaml:"condition"`
+
+	// MissingLabelPolicy overrides the CRD missing label policy for this metric
+	MissingLabelPolicy string `yaml:"missingLabelPolicy"`
 }
 
 // ConditionConfig defines how to parse Kubernetes-style conditions
@@ -87,6 +224,7 @@ type ConditionConfig struct {
 // NewDefaultCollectorConfig creates a new CollectorConfig with default values
 func NewDefaultCollectorConfig() *CollectorConfig {
 	return &CollectorConfig{
-		CRDs: []CRDConfig{},
+		CRDs:        []CRDConfig{},
+		ConfigFiles: []string{},
 	}
 }
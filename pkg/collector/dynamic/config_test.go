@@ -74,13 +74,31 @@ func TestCRDConfig_Validation(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "invalid fetch",
+			config: CRDConfig{
+				Name:    "test-crd",
+				GVR:     GVRConfig{Resource: "applications"},
+				Fetches: []FetchConfig{{As: "scale"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid expr metric",
+			config: CRDConfig{
+				Name:    "test-crd",
+				GVR:     GVRConfig{Resource: "applications"},
+				Metrics: []MetricConfig{{Type: "expr", Name: "ready", Expr: "status.ready == true"}},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			hasError := tt.config.Name == "" || tt.config.GVR.Resource == ""
-			if hasError != tt.expectErr {
-				t.Errorf("Validation result = %v, expectErr = %v", hasError, tt.expectErr)
+			err := tt.config.Validate()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr = %v", err, tt.expectErr)
 			}
 		})
 	}
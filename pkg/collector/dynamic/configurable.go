@@ -1,13 +1,17 @@
 package dynamic
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
 
+	"github.com/labring/sealos-state-metrics/pkg/sampling"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/workqueue"
 )
 
 // ConfigurableCollector implements a configuration-driven CRD collector
@@ -16,11 +20,45 @@ type ConfigurableCollector struct {
 	crdConfig    *CRDConfig
 	metricPrefix string
 
-	mu        sync.RWMutex
-	resources map[string]*unstructured.Unstructured // key: namespace/name
+	// commonLabels are the common labels of the CRD, the injected namespace
+	// label included
+	commonLabels      map[string]string
+	namespaceInjected bool
+
+	// client fetches the CRD schema to validate the configuration (nil disables validation)
+	client dynamic.Interface
+
+	// fetcher issues the configured additional GETs (nil when none are configured)
+	fetcher *fetcher
+
+	mu         sync.RWMutex
+	resources  map[string]*unstructured.Unstructured // key: namespace/name
+	fetched    map[string]map[string]any             // key: namespace/name
+	fetchQueue workqueue.TypedRateLimitingInterface[string]
+
+	// schemaValidated is set once the configuration was checked against the
+	// CRD schema, configValid being the outcome
+	schemaValidated bool
+	configValid     bool
 
 	// Metric descriptors
-	descriptors map[string]*prometheus.Desc
+	descriptors     map[string]*prometheus.Desc
+	configValidDesc *prometheus.Desc
+}
+
+// ConfigurableCollectorOption configures a ConfigurableCollector
+type ConfigurableCollectorOption func(*ConfigurableCollector)
+
+// WithDynamicClient enables the additional GETs configured in CRDConfig.Fetches
+// and the validation of the configuration against the CRD schema
+func WithDynamicClient(client dynamic.Interface) ConfigurableCollectorOption {
+	return func(c *ConfigurableCollector) {
+		c.client = client
+
+		if client != nil && len(c.crdConfig.Fetches) > 0 {
+			c.fetcher = newFetcher(client, c.crdConfig)
+		}
+	}
 }
 
 // NewConfigurableCollector creates a new configurable collector for a CRD
@@ -28,13 +66,21 @@ func NewConfigurableCollector(
 	crdConfig *CRDConfig,
 	metricPrefix string,
 	logger *log.Entry,
+	opts ...ConfigurableCollectorOption,
 ) *ConfigurableCollector {
 	c := &ConfigurableCollector{
-		logger:       logger,
-		crdConfig:    crdConfig,
-		metricPrefix: metricPrefix,
-		resources:    make(map[string]*unstructured.Unstructured),
-		descriptors:  make(map[string]*prometheus.Desc),
+		logger:            logger,
+		crdConfig:         crdConfig,
+		metricPrefix:      metricPrefix,
+		commonLabels:      crdConfig.effectiveCommonLabels(),
+		namespaceInjected: crdConfig.namespaceInjected(),
+		resources:         make(map[string]*unstructured.Unstructured),
+		fetched:           make(map[string]map[string]any),
+		descriptors:       make(map[string]*prometheus.Desc),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
 
 	c.initMetrics()
@@ -76,8 +122,23 @@ func (c *ConfigurableCollector) initMetrics() {
 
 			labelNames = []string{valueLabel}
 
-		case "gauge":
-			// Gauge metrics have only common labels
+		case "sum", "min", "max", "avg", "histogram":
+			// Aggregate metrics fold a numeric field across all resources
+			// Only has the group-by labels (no per-resource labels)
+			labelNames = getSortedKeys(metricCfg.GroupBy)
+
+		case "gauge", "ratio":
+			// Gauge and ratio metrics have only common labels
+			labelNames = commonLabelNames
+
+		case "expr":
+			// Expr metrics have only common labels, their expression is
+			// compiled by ValidateExprMetrics
+			if _, ok := c.crdConfig.expressions[metricCfg.Name]; !ok {
+				c.logger.WithField("metric", metricCfg.Name).Warn("Expression not compiled, skipping metric")
+				continue
+			}
+
 			labelNames = commonLabelNames
 
 		case "map_state":
@@ -103,20 +164,34 @@ func (c *ConfigurableCollector) initMetrics() {
 		desc := prometheus.NewDesc(metricName, metricCfg.Help, labelNames, nil)
 		c.descriptors[metricCfg.Name] = desc
 	}
+
+	// The configuration is only validated with a dynamic client
+	if c.client != nil {
+		c.configValidDesc = prometheus.NewDesc(
+			prometheus.BuildFQName(prefix, "", "config_valid"),
+			"Whether the configured paths match the CRD schema (1=valid, 0=mismatches, see logs)",
+			nil,
+			nil,
+		)
+	}
 }
 
 // getCommonLabelNames returns sorted common label names
 func (c *ConfigurableCollector) getCommonLabelNames() []string {
-	return getSortedKeys(c.crdConfig.CommonLabels)
+	return getSortedKeys(c.commonLabels)
 }
 
 // GetMetricDescriptors returns all metric descriptors
 func (c *ConfigurableCollector) GetMetricDescriptors() []*prometheus.Desc {
-	descs := make([]*prometheus.Desc, 0, len(c.descriptors))
+	descs := make([]*prometheus.Desc, 0, len(c.descriptors)+1)
 	for _, desc := range c.descriptors {
 		descs = append(descs, desc)
 	}
 
+	if c.configValidDesc != nil {
+		descs = append(descs, c.configValidDesc)
+	}
+
 	return descs
 }
 
@@ -134,14 +209,39 @@ func (c *ConfigurableCollector) GetMetricsCollector() func(ch chan<- prometheus.
 	return c.collect
 }
 
+// GetWorker returns the background fetch worker, or nil when no fetches are configured
+func (c *ConfigurableCollector) GetWorker() func(ctx context.Context) {
+	if c.fetcher == nil {
+		return nil
+	}
+
+	return c.runFetcher
+}
+
+// GetConfigValidator returns the function validating the configuration against
+// the CRD schema, or nil without a dynamic client
+func (c *ConfigurableCollector) GetConfigValidator() func(ctx context.Context) {
+	if c.client == nil {
+		return nil
+	}
+
+	return c.validateConfig
+}
+
 // handleAdd processes add events
 func (c *ConfigurableCollector) handleAdd(obj *unstructured.Unstructured) {
+	sampling.Sample(collectorName+"-"+c.crdConfig.Name, obj)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	key := obj.GetNamespace() + "/" + obj.GetName()
 	c.resources[key] = obj
 
+	if c.fetchQueue != nil {
+		c.fetchQueue.Add(key)
+	}
+
 	c.logger.WithFields(log.Fields{
 		"namespace": obj.GetNamespace(),
 		"name":      obj.GetName(),
@@ -160,6 +260,7 @@ func (c *ConfigurableCollector) handleDelete(obj *unstructured.Unstructured) {
 
 	key := obj.GetNamespace() + "/" + obj.GetName()
 	delete(c.resources, key)
+	delete(c.fetched, key)
 
 	c.logger.WithFields(log.Fields{
 		"namespace": obj.GetNamespace(),
@@ -172,10 +273,16 @@ func (c *ConfigurableCollector) collect(ch chan<- prometheus.Metric) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	c.collectConfigValid(ch)
+
+	commonLabelNames := c.getCommonLabelNames()
+
 	// First pass: collect per-resource metrics
-	for _, obj := range c.resources {
+	for key, obj := range c.resources {
+		obj = c.withFetched(key, obj)
+
 		// Get common labels
-		commonLabels := c.extractCommonLabels(obj)
+		rawCommonLabels := c.extractCommonLabels(obj)
 
 		// Collect each configured metric
 		for _, metricCfg := range c.crdConfig.Metrics {
@@ -184,11 +291,24 @@ func (c *ConfigurableCollector) collect(ch chan<- prometheus.Metric) {
 				continue
 			}
 
+			commonLabels, ok := c.resolveLabels(
+				commonLabelNames,
+				rawCommonLabels,
+				c.missingLabelPolicy(&metricCfg),
+			)
+			if !ok {
+				continue
+			}
+
 			switch metricCfg.Type {
 			case "info":
 				c.collectInfoMetric(ch, desc, obj, &metricCfg, commonLabels)
 			case "gauge":
 				c.collectGaugeMetric(ch, desc, obj, &metricCfg, commonLabels)
+			case "ratio":
+				c.collectRatioMetric(ch, desc, obj, &metricCfg, commonLabels)
+			case "expr":
+				c.collectExprMetric(ch, desc, obj, &metricCfg, commonLabels)
 			case "map_state":
 				c.collectMapStateMetric(ch, desc, obj, &metricCfg, commonLabels)
 			case "map_gauge":
@@ -199,26 +319,29 @@ func (c *ConfigurableCollector) collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
-	// Second pass: collect aggregate metrics (count)
+	// Second pass: collect aggregate metrics (count, sum, min, max, avg, histogram)
 	for _, metricCfg := range c.crdConfig.Metrics {
-		if metricCfg.Type != "count" {
-			continue
-		}
-
 		desc, ok := c.descriptors[metricCfg.Name]
 		if !ok {
 			continue
 		}
 
-		c.collectCountMetric(ch, desc, &metricCfg)
+		switch metricCfg.Type {
+		case "count":
+			c.collectCountMetric(ch, desc, &metricCfg)
+		case "sum", "min", "max", "avg":
+			c.collectAggregateMetric(ch, desc, &metricCfg)
+		case "histogram":
+			c.collectHistogramMetric(ch, desc, &metricCfg)
+		}
 	}
 }
 
 // extractCommonLabels extracts common labels from an object
 func (c *ConfigurableCollector) extractCommonLabels(obj *unstructured.Unstructured) []string {
-	labels := make([]string, 0, len(c.crdConfig.CommonLabels))
+	labels := make([]string, 0, len(c.commonLabels))
 
-	for _, path := range getSortedValues(c.crdConfig.CommonLabels) {
+	for _, path := range getSortedValues(c.commonLabels) {
 		value := extractFieldString(obj, path)
 		labels = append(labels, value)
 	}
@@ -234,15 +357,21 @@ func (c *ConfigurableCollector) collectInfoMetric(
 	cfg *MetricConfig,
 	commonLabels []string,
 ) {
-	labels := make([]string, len(commonLabels), len(commonLabels)+len(cfg.Labels))
-	copy(labels, commonLabels)
-
-	// Add extra labels
+	// Extract extra labels
+	extraLabels := make([]string, 0, len(cfg.Labels))
 	for _, path := range getSortedValues(cfg.Labels) {
-		value := extractFieldString(obj, path)
-		labels = append(labels, value)
+		extraLabels = append(extraLabels, extractFieldString(obj, path))
+	}
+
+	extraLabels, ok := c.resolveLabels(getSortedKeys(cfg.Labels), extraLabels, c.missingLabelPolicy(cfg))
+	if !ok {
+		return
 	}
 
+	labels := make([]string, len(commonLabels), len(commonLabels)+len(extraLabels))
+	copy(labels, commonLabels)
+	labels = append(labels, extraLabels...)
+
 	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, labels...)
 }
 
@@ -256,8 +385,8 @@ func (c *ConfigurableCollector) collectCountMetric(
 	// Count resources by field value
 	valueCounts := make(map[string]float64)
 
-	for _, obj := range c.resources {
-		value := extractFieldString(obj, cfg.Path)
+	for key, obj := range c.resources {
+		value := extractFieldString(c.withFetched(key, obj), cfg.Path)
 		if value != "" {
 			valueCounts[value]++
 		}
@@ -269,6 +398,78 @@ func (c *ConfigurableCollector) collectCountMetric(
 	}
 }
 
+// aggregateGroup accumulates numeric values for one aggregate group
+type aggregateGroup struct {
+	labels []string
+	sum    float64
+	min    float64
+	max    float64
+	count  int
+}
+
+// collectAggregateMetric collects sum/min/max/avg metrics (aggregate)
+// Folds a numeric field across all resources, grouped by the configured group-by paths.
+// Resources where the field is missing are skipped.
+func (c *ConfigurableCollector) collectAggregateMetric(
+	ch chan<- prometheus.Metric,
+	desc *prometheus.Desc,
+	cfg *MetricConfig,
+) {
+	groupNames := getSortedKeys(cfg.GroupBy)
+	groupPaths := getSortedValues(cfg.GroupBy)
+	policy := c.missingLabelPolicy(cfg)
+	groups := make(map[string]*aggregateGroup)
+
+	for key, obj := range c.resources {
+		obj = c.withFetched(key, obj)
+
+		value, found := lookupFieldFloat(obj, cfg.Path)
+		if !found {
+			continue
+		}
+
+		labels := make([]string, 0, len(groupPaths))
+		for _, path := range groupPaths {
+			labels = append(labels, extractFieldString(obj, path))
+		}
+
+		labels, ok := c.resolveLabels(groupNames, labels, policy)
+		if !ok {
+			continue
+		}
+
+		key := strings.Join(labels, "\x00")
+
+		group, ok := groups[key]
+		if !ok {
+			group = &aggregateGroup{labels: labels, min: value, max: value}
+			groups[key] = group
+		}
+
+		group.sum += value
+		group.min = min(group.min, value)
+		group.max = max(group.max, value)
+		group.count++
+	}
+
+	for _, group := range groups {
+		var value float64
+
+		switch cfg.Type {
+		case "sum":
+			value = group.sum
+		case "min":
+			value = group.min
+		case "max":
+			value = group.max
+		case "avg":
+			value = group.sum / float64(group.count)
+		}
+
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, group.labels...)
+	}
+}
+
 // collectGaugeMetric collects a gauge metric
 func (c *ConfigurableCollector) collectGaugeMetric(
 	ch chan<- prometheus.Metric,
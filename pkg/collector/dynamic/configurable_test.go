@@ -2,8 +2,13 @@
 package dynamic
 
 import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/labring/sealos-state-metrics/pkg/identity"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	log "github.com/sirupsen/logrus"
@@ -713,3 +718,559 @@ func containsMiddle(s, substr string) bool {
 
 	return false
 }
+
+func TestConfigurableCollector_CollectAggregateMetrics(t *testing.T) {
+	logger := log.NewEntry(log.StandardLogger())
+	crdConfig := &CRDConfig{
+		Name: "test-crd",
+		Metrics: []MetricConfig{
+			{Type: "sum", Name: "cpu_sum", Path: "spec.cpu"},
+			{Type: "min", Name: "cpu_min", Path: "spec.cpu"},
+			{Type: "max", Name: "cpu_max", Path: "spec.cpu"},
+			{
+				Type:    "avg",
+				Name:    "cpu_avg_by_engine",
+				Path:    "spec.cpu",
+				GroupBy: map[string]string{"engine": "spec.engine"},
+			},
+		},
+	}
+
+	collector := NewConfigurableCollector(crdConfig, "test", logger)
+
+	resources := []struct {
+		name   string
+		engine string
+		cpu    any
+	}{
+		{"db-1", "mysql", "500m"},
+		{"db-2", "mysql", int64(2)},
+		{"db-3", "redis", 1.5},
+		{"db-4", "redis", nil}, // missing field is skipped
+	}
+
+	for _, r := range resources {
+		spec := map[string]any{"engine": r.engine}
+		if r.cpu != nil {
+			spec["cpu"] = r.cpu
+		}
+
+		collector.handleAdd(&unstructured.Unstructured{
+			Object: map[string]any{
+				"metadata": map[string]any{"name": r.name},
+				"spec":     spec,
+			},
+		})
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	go func() {
+		collector.collect(ch)
+		close(ch)
+	}()
+
+	// Ungrouped aggregates are keyed by descriptor, grouped ones by engine label
+	totals := make(map[string]float64)
+	avgByEngine := make(map[string]float64)
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		if len(m.GetLabel()) == 0 {
+			totals[metric.Desc().String()] = m.GetGauge().GetValue()
+			continue
+		}
+
+		avgByEngine[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+
+	expectedAvg := map[string]float64{"mysql": 1.25, "redis": 1.5}
+	for engine, expected := range expectedAvg {
+		if avgByEngine[engine] != expected {
+			t.Errorf("avg for %s: expected %v, got %v", engine, expected, avgByEngine[engine])
+		}
+	}
+
+	expectedTotals := map[string]float64{"cpu_sum": 4.0, "cpu_min": 0.5, "cpu_max": 2.0}
+	for name, expected := range expectedTotals {
+		found := false
+
+		for desc, value := range totals {
+			if !strings.Contains(desc, `"test_test_crd_`+name+`"`) {
+				continue
+			}
+
+			found = true
+
+			if value != expected {
+				t.Errorf("%s: expected %v, got %v", name, expected, value)
+			}
+		}
+
+		if !found {
+			t.Errorf("%s not found in metrics", name)
+		}
+	}
+}
+
+func TestConfigurableCollector_MissingLabelPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		crdPolicy     string
+		metricPolicy  string
+		labelDefaults map[string]string
+		wantVersions  []string
+	}{
+		{
+			name:         "empty by default",
+			wantVersions: []string{"", "v1"},
+		},
+		{
+			name:         "unknown",
+			crdPolicy:    MissingLabelUnknown,
+			wantVersions: []string{"unknown", "v1"},
+		},
+		{
+			name:         "metric overrides crd policy",
+			crdPolicy:    MissingLabelUnknown,
+			metricPolicy: MissingLabelSkip,
+			wantVersions: []string{"v1"},
+		},
+		{
+			name:          "label default takes precedence",
+			crdPolicy:     MissingLabelSkip,
+			labelDefaults: map[string]string{"version": "none"},
+			wantVersions:  []string{"none", "v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crdConfig := &CRDConfig{
+				Name:               "test-crd",
+				CommonLabels:       map[string]string{"name": "metadata.name"},
+				MissingLabelPolicy: tt.crdPolicy,
+				LabelDefaults:      tt.labelDefaults,
+				Metrics: []MetricConfig{
+					{
+						Type:               "info",
+						Name:               "info",
+						Help:               "Resource information",
+						Labels:             map[string]string{"version": "spec.version"},
+						MissingLabelPolicy: tt.metricPolicy,
+					},
+				},
+			}
+
+			if err := crdConfig.ValidateLabelPolicies(); err != nil {
+				t.Fatalf("ValidateLabelPolicies() error = %v", err)
+			}
+
+			collector := NewConfigurableCollector(crdConfig, "test", log.NewEntry(log.StandardLogger()))
+			collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+				"metadata": map[string]any{"name": "a"},
+			}})
+			collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+				"metadata": map[string]any{"name": "b"},
+				"spec":     map[string]any{"version": "v1"},
+			}})
+
+			ch := make(chan prometheus.Metric, 10)
+			go func() {
+				collector.collect(ch)
+				close(ch)
+			}()
+
+			var versions []string
+
+			for metric := range ch {
+				var m dto.Metric
+				if err := metric.Write(&m); err != nil {
+					t.Fatalf("Failed to write metric: %v", err)
+				}
+
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "version" {
+						versions = append(versions, label.GetValue())
+					}
+				}
+			}
+
+			sort.Strings(versions)
+
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("Expected versions %v, got %v", tt.wantVersions, versions)
+			}
+		})
+	}
+
+	invalid := &CRDConfig{MissingLabelPolicy: "drop"}
+	if err := invalid.ValidateLabelPolicies(); err == nil {
+		t.Error("Expected error for invalid missingLabelPolicy")
+	}
+}
+
+func TestConfigurableCollector_CollectRatioMetric(t *testing.T) {
+	tests := []struct {
+		name         string
+		divideByZero string
+		want         map[string]float64
+	}{
+		{
+			name: "skip by default",
+			want: map[string]float64{"half": 0.5, "none-ready": 0},
+		},
+		{
+			name:         "zero",
+			divideByZero: DivideByZeroZero,
+			want:         map[string]float64{"half": 0.5, "none-ready": 0, "scaled-down": 0},
+		},
+		{
+			name:         "nan",
+			divideByZero: DivideByZeroNaN,
+			want:         map[string]float64{"half": 0.5, "none-ready": 0, "scaled-down": math.NaN()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crdConfig := &CRDConfig{
+				Name:         "test-crd",
+				CommonLabels: map[string]string{"name": "metadata.name"},
+				Metrics: []MetricConfig{
+					{
+						Type:            "ratio",
+						Name:            "ready_ratio",
+						Path:            "status.readyReplicas",
+						DenominatorPath: "status.replicas",
+						DivideByZero:    tt.divideByZero,
+					},
+				},
+			}
+
+			if err := crdConfig.ValidateRatioMetrics(); err != nil {
+				t.Fatalf("ValidateRatioMetrics() error = %v", err)
+			}
+
+			collector := NewConfigurableCollector(crdConfig, "test", log.NewEntry(log.StandardLogger()))
+
+			for name, status := range map[string]map[string]any{
+				"half":        {"readyReplicas": int64(1), "replicas": int64(2)},
+				"none-ready":  {"replicas": int64(3)}, // missing numerator counts as zero
+				"scaled-down": {"replicas": int64(0)},
+				"no-status":   {}, // missing denominator is skipped
+			} {
+				collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+					"metadata": map[string]any{"name": name},
+					"status":   status,
+				}})
+			}
+
+			ch := make(chan prometheus.Metric, 10)
+			go func() {
+				collector.collect(ch)
+				close(ch)
+			}()
+
+			got := make(map[string]float64)
+
+			for metric := range ch {
+				var m dto.Metric
+				if err := metric.Write(&m); err != nil {
+					t.Fatalf("Failed to write metric: %v", err)
+				}
+
+				got[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+
+			for name, want := range tt.want {
+				value, ok := got[name]
+				if !ok || (value != want && !(math.IsNaN(want) && math.IsNaN(value))) {
+					t.Errorf("%s: expected %v, got %v", name, want, value)
+				}
+			}
+		})
+	}
+
+	for _, invalid := range []MetricConfig{
+		{Type: "ratio", Name: "no_denominator", Path: "status.readyReplicas"},
+		{Type: "ratio", Name: "bad_policy", Path: "a", DenominatorPath: "b", DivideByZero: "inf"},
+	} {
+		crdConfig := &CRDConfig{Metrics: []MetricConfig{invalid}}
+		if err := crdConfig.ValidateRatioMetrics(); err == nil {
+			t.Errorf("Expected error for %s", invalid.Name)
+		}
+	}
+}
+
+func TestConfigurableCollector_CollectHistogramMetric(t *testing.T) {
+	crdConfig := &CRDConfig{
+		Name:         "test-crd",
+		CommonLabels: map[string]string{"name": "metadata.name"},
+		Metrics: []MetricConfig{
+			{
+				Type:    "histogram",
+				Name:    "duration_seconds",
+				Path:    "status.durationSeconds",
+				Buckets: []float64{10, 60, 300},
+				GroupBy: map[string]string{"method": "spec.method"},
+			},
+		},
+	}
+
+	if err := crdConfig.ValidateHistogramMetrics(); err != nil {
+		t.Fatalf("ValidateHistogramMetrics() error = %v", err)
+	}
+
+	collector := NewConfigurableCollector(crdConfig, "test", log.NewEntry(log.StandardLogger()))
+
+	for name, resource := range map[string]struct {
+		method   string
+		duration any
+	}{
+		"fast":     {"full", int64(5)},
+		"medium":   {"full", 45.5},
+		"slow":     {"full", "600"}, // above every bucket, only in +Inf
+		"delta":    {"delta", int64(60)},
+		"unfinish": {"delta", nil}, // missing field is skipped
+	} {
+		status := map[string]any{}
+		if resource.duration != nil {
+			status["durationSeconds"] = resource.duration
+		}
+
+		collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+			"metadata": map[string]any{"name": name},
+			"spec":     map[string]any{"method": resource.method},
+			"status":   status,
+		}})
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	go func() {
+		collector.collect(ch)
+		close(ch)
+	}()
+
+	got := make(map[string]*dto.Histogram)
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		got[m.GetLabel()[0].GetValue()] = m.GetHistogram()
+	}
+
+	expected := map[string]struct {
+		count   uint64
+		sum     float64
+		buckets []uint64
+	}{
+		"full":  {count: 3, sum: 650.5, buckets: []uint64{1, 2, 2}},
+		"delta": {count: 1, sum: 60, buckets: []uint64{0, 1, 1}},
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d histograms, got %d", len(expected), len(got))
+	}
+
+	for method, want := range expected {
+		histogram, ok := got[method]
+		if !ok {
+			t.Fatalf("Expected a histogram for method %s", method)
+		}
+
+		if histogram.GetSampleCount() != want.count || histogram.GetSampleSum() != want.sum {
+			t.Errorf("%s: expected count %d and sum %v, got %d and %v",
+				method, want.count, want.sum, histogram.GetSampleCount(), histogram.GetSampleSum())
+		}
+
+		buckets := make([]uint64, 0, len(histogram.GetBucket()))
+		for _, bucket := range histogram.GetBucket() {
+			buckets = append(buckets, bucket.GetCumulativeCount())
+		}
+
+		if !reflect.DeepEqual(buckets, want.buckets) {
+			t.Errorf("%s: expected cumulative buckets %v, got %v", method, want.buckets, buckets)
+		}
+	}
+
+	for _, invalid := range []MetricConfig{
+		{Type: "histogram", Name: "no_path"},
+		{Type: "histogram", Name: "unsorted", Path: "a", Buckets: []float64{5, 1}},
+		{Type: "histogram", Name: "duplicate", Path: "a", Buckets: []float64{1, 1}},
+	} {
+		crdConfig := &CRDConfig{Metrics: []MetricConfig{invalid}}
+		if err := crdConfig.ValidateHistogramMetrics(); err == nil {
+			t.Errorf("Expected error for %s", invalid.Name)
+		}
+	}
+}
+
+// identityCollector exposes a ConfigurableCollector as a prometheus.Collector
+type identityCollector struct {
+	*ConfigurableCollector
+}
+
+func (c identityCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.GetMetricDescriptors() {
+		ch <- desc
+	}
+}
+
+func (c identityCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(ch)
+}
+
+func TestConfigurableCollector_ClusterIdentityLabels(t *testing.T) {
+	crdConfig := &CRDConfig{
+		Name:         "test-crd",
+		CommonLabels: map[string]string{"name": "metadata.name"},
+		Metrics:      []MetricConfig{{Type: "gauge", Name: "replicas", Path: "spec.replicas"}},
+	}
+
+	if err := crdConfig.ValidateLabelNames(); err != nil {
+		t.Fatalf("ValidateLabelNames() error = %v", err)
+	}
+
+	collector := NewConfigurableCollector(crdConfig, "test", log.NewEntry(log.StandardLogger()))
+	collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "a"},
+		"spec":     map[string]any{"replicas": int64(2)},
+	}})
+
+	// The server adds the cluster identity as target labels to every metric
+	cluster := identity.Cluster{Name: "hzh", Region: "cn-hangzhou"}
+	reg := prometheus.NewRegistry()
+	prometheus.WrapRegistererWith(cluster.Labels(), reg).MustRegister(identityCollector{collector})
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	if len(families) != 1 || len(families[0].GetMetric()) != 1 {
+		t.Fatalf("Expected a single series, got %v", families)
+	}
+
+	labels := make(map[string]string)
+	for _, label := range families[0].GetMetric()[0].GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+
+	// The namespace label is injected, empty for a cluster-scoped resource
+	expected := map[string]string{"name": "a", "namespace": "", "sealos_cluster": "hzh", "sealos_region": "cn-hangzhou"}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Expected labels %v, got %v", expected, labels)
+	}
+
+	for _, invalid := range []*CRDConfig{
+		{CommonLabels: map[string]string{"sealos_cluster": "metadata.labels.cluster"}},
+		{Metrics: []MetricConfig{{Name: "info", Type: "info", Labels: map[string]string{"sealos_zone": "spec.zone"}}}},
+		{Metrics: []MetricConfig{{Name: "total", Type: "sum", GroupBy: map[string]string{"sealos_region": "spec.region"}}}},
+		{Metrics: []MetricConfig{{Name: "count", Type: "count", ValueLabel: "sealos_cluster"}}},
+	} {
+		if err := invalid.ValidateLabelNames(); err == nil {
+			t.Errorf("Expected error for reserved label names in %+v", invalid)
+		}
+	}
+}
+
+func TestConfigurableCollector_InjectNamespace(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name         string
+		commonLabels map[string]string
+		inject       *bool
+		want         []string
+	}{
+		{
+			name:         "injected by default",
+			commonLabels: map[string]string{"name": "metadata.name"},
+			want:         []string{"name=a,namespace=ns-a", "name=b,namespace="},
+		},
+		{
+			name:         "disabled",
+			commonLabels: map[string]string{"name": "metadata.name"},
+			inject:       &disabled,
+			want:         []string{"name=a", "name=b"},
+		},
+		{
+			name:         "already a common label",
+			commonLabels: map[string]string{"name": "metadata.name", "ns": "metadata.namespace"},
+			want:         []string{"name=a,ns=ns-a", "name=b,ns="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crdConfig := &CRDConfig{
+				Name:            "test-crd",
+				CommonLabels:    tt.commonLabels,
+				InjectNamespace: tt.inject,
+				// The cluster-scoped resource keeps an empty namespace
+				MissingLabelPolicy: MissingLabelSkip,
+				LabelDefaults:      map[string]string{"ns": ""},
+				Metrics:            []MetricConfig{{Type: "gauge", Name: "replicas", Path: "spec.replicas"}},
+			}
+
+			collector := NewConfigurableCollector(crdConfig, "test", log.NewEntry(log.StandardLogger()))
+			collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+				"metadata": map[string]any{"name": "a", "namespace": "ns-a"},
+				"spec":     map[string]any{"replicas": int64(1)},
+			}})
+			collector.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+				"metadata": map[string]any{"name": "b"},
+				"spec":     map[string]any{"replicas": int64(1)},
+			}})
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.collect(ch)
+			close(ch)
+
+			var series []string
+
+			for metric := range ch {
+				var m dto.Metric
+				if err := metric.Write(&m); err != nil {
+					t.Fatalf("Failed to write metric: %v", err)
+				}
+
+				var labels []string
+				for _, label := range m.GetLabel() {
+					labels = append(labels, label.GetName()+"="+label.GetValue())
+				}
+
+				series = append(series, strings.Join(labels, ","))
+			}
+
+			sort.Strings(series)
+
+			if !reflect.DeepEqual(series, tt.want) {
+				t.Errorf("Expected series %v, got %v", tt.want, series)
+			}
+		})
+	}
+
+	conflicting := &CRDConfig{
+		Metrics: []MetricConfig{{Name: "info", Type: "info", Labels: map[string]string{"namespace": "spec.ns"}}},
+	}
+	if err := conflicting.ValidateLabelNames(); err == nil {
+		t.Error("Expected error for a metric label colliding with the injected namespace")
+	}
+
+	conflicting.InjectNamespace = &disabled
+	if err := conflicting.ValidateLabelNames(); err != nil {
+		t.Errorf("Expected no error with injectNamespace disabled, got %v", err)
+	}
+}
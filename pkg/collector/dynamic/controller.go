@@ -5,9 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -20,21 +22,31 @@ type ControllerConfig struct {
 	// GVR is the GroupVersionResource to watch
 	GVR schema.GroupVersionResource
 
-	// Namespace to watch (empty string for cluster-scoped or all namespaces)
-	Namespace string
+	// Namespaces to watch, one filtered informer each (empty for
+	// cluster-scoped resources or all namespaces)
+	Namespaces []string
 
 	// ResyncPeriod is the resync interval for the informer
 	ResyncPeriod time.Duration
 
 	// EventHandler is the callback interface for resource events
 	EventHandler EventHandler
+
+	// Instrument optionally wraps the informer event handlers, e.g. with
+	// BaseCollector.InstrumentHandler to count and queue the notifications
+	Instrument func(cache.ResourceEventHandlerFuncs) cache.ResourceEventHandlerFuncs
+
+	// InstrumentInformer is optionally called with each informer before it
+	// starts, e.g. BaseCollector.InstrumentInformer to count its watch errors
+	// and report its cache size
+	InstrumentInformer func(resource string, informer cache.SharedIndexInformer)
 }
 
 // Controller is a generic dynamic client controller that watches CRDs
 type Controller struct {
 	config         *ControllerConfig
 	dynamicClient  dynamic.Interface
-	informer       cache.SharedIndexInformer
+	informers      []cache.SharedIndexInformer
 	informerStopCh chan struct{}
 	logger         *log.Entry
 }
@@ -69,34 +81,85 @@ func NewController(
 	}, nil
 }
 
-// Start starts the controller and begins watching resources
+// watchNamespaces returns the namespaces to create an informer for, without
+// duplicates. A single empty namespace watches all namespaces; it is only used
+// when no namespace is listed, since a cluster-wide informer alongside
+// namespaced ones would deliver the objects of these namespaces twice.
+func watchNamespaces(namespaces []string) []string {
+	watched := make([]string, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		if namespace != "" && !slices.Contains(watched, namespace) {
+			watched = append(watched, namespace)
+		}
+	}
+
+	if len(watched) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+
+	return watched
+}
+
+// Start starts the controller and begins watching resources. The informers of
+// all namespaces are started together and waited for until they have synced.
 func (c *Controller) Start(ctx context.Context) error {
+	namespaces := watchNamespaces(c.config.Namespaces)
+
 	c.logger.WithFields(log.Fields{
-		"gvr":       c.config.GVR.String(),
-		"namespace": c.config.Namespace,
+		"gvr":        c.config.GVR.String(),
+		"namespaces": namespaces,
 	}).Info("Starting dynamic controller")
 
-	// Create dynamic informer factory
-	var factory dynamicinformer.DynamicSharedInformerFactory
-	if c.config.Namespace != "" {
-		factory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+	c.informers = make([]cache.SharedIndexInformer, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		// Create a dynamic informer factory filtered on the namespace
+		factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
 			c.dynamicClient,
 			c.config.ResyncPeriod,
-			c.config.Namespace,
+			namespace,
 			nil,
 		)
-	} else {
-		factory = dynamicinformer.NewDynamicSharedInformerFactory(
-			c.dynamicClient,
-			c.config.ResyncPeriod,
-		)
+
+		// Get informer for the specific GVR
+		informer := factory.ForResource(c.config.GVR).Informer()
+
+		if c.config.InstrumentInformer != nil {
+			c.config.InstrumentInformer(c.config.GVR.GroupResource().String(), informer)
+		}
+
+		if err := c.addEventHandler(informer); err != nil {
+			return fmt.Errorf("failed to add event handler: %w", err)
+		}
+
+		c.informers = append(c.informers, informer)
+	}
+
+	// Start informers
+	c.informerStopCh = make(chan struct{})
+	for _, informer := range c.informers {
+		go informer.Run(c.informerStopCh)
+	}
+
+	// Wait for caches to sync
+	c.logger.Info("Waiting for informer cache to sync")
+
+	if !cache.WaitForCacheSync(ctx.Done(), c.HasSynced) {
+		close(c.informerStopCh)
+		c.informerStopCh = nil
+
+		return errors.New("failed to sync informer cache")
 	}
 
-	// Get informer for the specific GVR
-	c.informer = factory.ForResource(c.config.GVR).Informer()
+	c.logger.Info("Dynamic controller started and cache synced")
+
+	return nil
+}
 
-	// Register event handlers
-	_, err := c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+// addEventHandler forwards the events of an informer to the event handler
+func (c *Controller) addEventHandler(informer cache.SharedIndexInformer) error {
+	handler := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			if u, ok := obj.(*unstructured.Unstructured); ok {
 				c.config.EventHandler.OnAdd(u)
@@ -130,26 +193,15 @@ func (c *Controller) Start(ctx context.Context) error {
 
 			c.config.EventHandler.OnDelete(u)
 		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to add event handler: %w", err)
 	}
 
-	// Start informer
-	c.informerStopCh = make(chan struct{})
-	go c.informer.Run(c.informerStopCh)
-
-	// Wait for cache to sync
-	c.logger.Info("Waiting for informer cache to sync")
-
-	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
-		close(c.informerStopCh)
-		return errors.New("failed to sync informer cache")
+	if c.config.Instrument != nil {
+		handler = c.config.Instrument(handler)
 	}
 
-	c.logger.Info("Dynamic controller started and cache synced")
+	_, err := informer.AddEventHandler(handler)
 
-	return nil
+	return err
 }
 
 // Stop stops the controller
@@ -164,18 +216,33 @@ func (c *Controller) Stop() error {
 	return nil
 }
 
-// HasSynced returns true if the informer cache has synced
+// HasSynced returns true if the informer caches of all namespaces have synced
 func (c *Controller) HasSynced() bool {
-	if c.informer == nil {
+	if len(c.informers) == 0 {
 		return false
 	}
-	return c.informer.HasSynced()
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
 }
 
-// GetStore returns the informer's store
-func (c *Controller) GetStore() cache.Store {
-	if c.informer == nil {
-		return nil
+// Informers returns the informers of the controller, one per watched
+// namespace, e.g. for BaseCollector.WatchInformers once they have synced
+func (c *Controller) Informers() []cache.SharedIndexInformer {
+	return c.informers
+}
+
+// GetStores returns the informer stores, one per watched namespace
+func (c *Controller) GetStores() []cache.Store {
+	stores := make([]cache.Store, 0, len(c.informers))
+	for _, informer := range c.informers {
+		stores = append(stores, informer.GetStore())
 	}
-	return c.informer.GetStore()
+
+	return stores
 }
@@ -0,0 +1,194 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestWatchNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		expected   []string
+	}{
+		{name: "all namespaces", namespaces: nil, expected: []string{""}},
+		{name: "single namespace", namespaces: []string{"ns-a"}, expected: []string{"ns-a"}},
+		{name: "duplicates", namespaces: []string{"ns-a", "ns-b", "ns-a"}, expected: []string{"ns-a", "ns-b"}},
+		{name: "empty entries", namespaces: []string{"", "ns-a", ""}, expected: []string{"ns-a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watchNamespaces(tt.namespaces); !slices.Equal(got, tt.expected) {
+				t.Errorf("watchNamespaces(%v) = %v, expected %v", tt.namespaces, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestController_MultipleNamespaces(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps.kubeblocks.io", Version: "v1", Resource: "clusters"}
+
+	cluster := func(namespace, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps.kubeblocks.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+		}}
+	}
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ClusterList"},
+		cluster("ns-a", "db-1"),
+		cluster("ns-b", "db-2"),
+		cluster("ns-c", "db-3"),
+	)
+
+	var (
+		mu    sync.Mutex
+		added []string
+	)
+
+	controller, err := NewController(client, &ControllerConfig{
+		GVR:        gvr,
+		Namespaces: []string{"ns-a", "ns-b", "ns-a"},
+		EventHandler: EventHandlerFuncs{
+			AddFunc: func(obj *unstructured.Unstructured) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				added = append(added, obj.GetNamespace()+"/"+obj.GetName())
+			},
+		},
+	}, log.NewEntry(log.New()))
+	if err != nil {
+		t.Fatalf("NewController() error = %v", err)
+	}
+
+	if err := controller.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = controller.Stop() }()
+
+	if !controller.HasSynced() {
+		t.Error("Expected the controller to have synced")
+	}
+
+	if stores := controller.GetStores(); len(stores) != 2 {
+		t.Errorf("Expected one store per namespace, got %d", len(stores))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	slices.Sort(added)
+
+	if expected := []string{"ns-a/db-1", "ns-b/db-2"}; !slices.Equal(added, expected) {
+		t.Errorf("Expected %v to be added, got %v", expected, added)
+	}
+}
+
+func TestMultiCollectorReadiness(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps.kubeblocks.io", Version: "v1", Resource: "clusters"}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ClusterList"},
+	)
+
+	mc := &multiCollector{logger: log.NewEntry(log.New())}
+
+	for _, name := range []string{"dynamic-a", "dynamic-b"} {
+		c, err := NewCollector(name, client, &Config{
+			GVR:          gvr,
+			EventHandler: EventHandlerFuncs{},
+		}, log.NewEntry(log.New()))
+		if err != nil {
+			t.Fatalf("NewCollector() error = %v", err)
+		}
+
+		mc.collectors = append(mc.collectors, c)
+	}
+
+	if liveness := mc.Liveness(); liveness.Started || liveness.Ready {
+		t.Errorf("Expected the collectors not to be started, got %+v", liveness)
+	}
+
+	if mc.HasSynced() {
+		t.Error("Expected no synced informers before start")
+	}
+
+	if err := mc.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = mc.Stop() }()
+
+	if liveness := mc.Liveness(); !liveness.Started || !liveness.Ready || liveness.StartedAt.IsZero() {
+		t.Errorf("Expected the collectors to be started and ready, got %+v", liveness)
+	}
+
+	if !mc.HasSynced() {
+		t.Error("Expected the informers of every collector to have synced")
+	}
+}
+
+func TestMultiCollectorRuntimeStats(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps.kubeblocks.io", Version: "v1", Resource: "clusters"}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ClusterList"},
+		&unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps.kubeblocks.io/v1",
+			"kind":       "Cluster",
+			"metadata":   map[string]any{"name": "db-1", "namespace": "ns-a"},
+		}},
+	)
+
+	mc := &multiCollector{logger: log.NewEntry(log.New())}
+
+	if stats := mc.RuntimeStats(); stats.Instrumented {
+		t.Errorf("Expected no runtime stats without collectors, got %+v", stats)
+	}
+
+	for _, name := range []string{"dynamic-a", "dynamic-b"} {
+		c, err := NewCollector(name, client, &Config{
+			GVR:          gvr,
+			EventHandler: EventHandlerFuncs{},
+		}, log.NewEntry(log.New()))
+		if err != nil {
+			t.Fatalf("NewCollector() error = %v", err)
+		}
+
+		mc.collectors = append(mc.collectors, c)
+	}
+
+	if err := mc.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = mc.Stop() }()
+
+	stats := mc.RuntimeStats()
+	if !stats.Instrumented {
+		t.Error("Expected the collectors to be instrumented")
+	}
+
+	if objects := stats.CacheObjects["clusters.apps.kubeblocks.io"]; objects != 2 {
+		t.Errorf("Expected the cached clusters of both collectors to be summed, got %v", stats.CacheObjects)
+	}
+
+	if _, ok := stats.LockWait["write"]; !ok {
+		t.Errorf("Expected the lock wait of the collectors to be merged, got %v", stats.LockWait)
+	}
+}
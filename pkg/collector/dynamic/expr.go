@@ -0,0 +1,165 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// selfVariable is the variable bound to the whole object in expressions
+const selfVariable = "self"
+
+// objectVariables are the top-level fields of objects bound to their own name
+// in expressions, along with the fields of the fetched objects
+var objectVariables = []string{"metadata", "spec", "status"}
+
+// expression is a compiled CEL expression of an expr metric
+type expression struct {
+	program   cel.Program
+	variables []string
+}
+
+// compileExpression compiles a CEL expression over the object variables and
+// the fetched objects, rejecting expressions whose result is not numeric
+func compileExpression(source string, fetched []string) (*expression, error) {
+	variables := slices.Clone(objectVariables)
+	for _, name := range fetched {
+		if !slices.Contains(variables, name) {
+			variables = append(variables, name)
+		}
+	}
+
+	opts := []cel.EnvOption{cel.Variable(selfVariable, cel.MapType(cel.StringType, cel.DynType))}
+	for _, name := range variables {
+		opts = append(opts, cel.Variable(name, cel.DynType))
+	}
+
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(source)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	// Fields are dyn, their expressions are checked when evaluated
+	switch ast.OutputType().Kind() {
+	case types.IntKind, types.UintKind, types.DoubleKind, types.DynKind:
+	default:
+		return nil, fmt.Errorf("expression returns %s, expected a number", ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &expression{program: program, variables: variables}, nil
+}
+
+// evalFloat evaluates the expression against an object. Selecting a field
+// the object lacks is an error.
+func (e *expression) evalFloat(obj map[string]any) (float64, error) {
+	vars := make(map[string]any, len(e.variables)+1)
+	vars[selfVariable] = obj
+
+	for _, name := range e.variables {
+		if value, ok := obj[name]; ok {
+			vars[name] = value
+		}
+	}
+
+	out, _, err := e.program.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := out.Value().(type) {
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("expression returned %s, expected a number", out.Type().TypeName())
+	}
+}
+
+// validateExprMetric compiles the expression of an expr metric
+func validateExprMetric(cfg *MetricConfig, fetched []string) (*expression, error) {
+	if cfg.Expr == "" {
+		return nil, errors.New("expr metrics require expr")
+	}
+
+	expr, err := compileExpression(cfg.Expr, fetched)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expr %q: %w", cfg.Expr, err)
+	}
+
+	return expr, nil
+}
+
+// ValidateExprMetrics compiles the expressions of the expr metrics of the
+// CRD, which are evaluated by the collectors created from the config
+func (c *CRDConfig) ValidateExprMetrics() error {
+	fetched := make([]string, 0, len(c.Fetches))
+	for i := range c.Fetches {
+		fetched = append(fetched, c.Fetches[i].As)
+	}
+
+	expressions := make(map[string]*expression)
+
+	for i := range c.Metrics {
+		if c.Metrics[i].Type != "expr" {
+			continue
+		}
+
+		expr, err := validateExprMetric(&c.Metrics[i], fetched)
+		if err != nil {
+			return fmt.Errorf("metric %s: %w", c.Metrics[i].Name, err)
+		}
+
+		expressions[c.Metrics[i].Name] = expr
+	}
+
+	c.expressions = expressions
+
+	return nil
+}
+
+// collectExprMetric collects an expr metric. Objects the expression fails on
+// (e.g. a missing field or a division by zero) are skipped.
+func (c *ConfigurableCollector) collectExprMetric(
+	ch chan<- prometheus.Metric,
+	desc *prometheus.Desc,
+	obj *unstructured.Unstructured,
+	cfg *MetricConfig,
+	commonLabels []string,
+) {
+	expr, ok := c.crdConfig.expressions[cfg.Name]
+	if !ok {
+		return
+	}
+
+	value, err := expr.evalFloat(obj.Object)
+	if err != nil {
+		c.logger.WithError(err).WithFields(log.Fields{
+			"metric":    cfg.Name,
+			"namespace": obj.GetNamespace(),
+			"name":      obj.GetName(),
+		}).Debug("Failed to evaluate expression, skipping")
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, commonLabels...)
+}
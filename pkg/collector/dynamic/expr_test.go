@@ -0,0 +1,218 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExpressionEval(t *testing.T) {
+	obj := map[string]any{
+		"metadata": map[string]any{
+			"name":   "app",
+			"labels": map[string]any{"app.kubernetes.io/tier": "db"},
+		},
+		"spec": map[string]any{
+			"replicas": int64(4),
+			"storage":  "10Gi",
+			"paused":   false,
+		},
+		"status": map[string]any{
+			"readyReplicas": int64(3),
+			"used":          "5Gi",
+			"phase":         "Running",
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "True"},
+			},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"double(status.readyReplicas) / double(spec.replicas)", 0.75},
+		// Integer fields divide as integers
+		{"status.readyReplicas / spec.replicas", 0},
+		{"has(status.readyReplicas) ? double(status.readyReplicas) / double(spec.replicas) : 0.0", 0.75},
+		{"has(status.missing) ? status.missing : -1", -1},
+		{"double(spec.replicas) + 0.5", 4.5},
+		{"spec.replicas - status.readyReplicas", 1},
+		{"uint(spec.replicas)", 4},
+		{"1 + 2 * 3 - 4 / 2", 5},
+		{"(1 + 2) * 3 % 4", 1},
+		{"int(7.9)", 7},
+		{"size(status.conditions)", 1},
+		{"size(metadata.name)", 3},
+		{"status.conditions.filter(c, c.type == 'Ready' && c.status == 'True').size()", 1},
+		{`metadata.labels["app.kubernetes.io/tier"] == "db" ? 1 : 0`, 1},
+		{"status.phase in ['Running', 'Succeeded'] ? 1 : 0", 1},
+		{"!spec.paused && status.readyReplicas >= 3 ? 1 : 0", 1},
+		{"self.spec.replicas", 4},
+		{"1.5e1", 15},
+		// The error of the missing field is absorbed by the decisive side
+		{"status.missing > 0 || true ? 1 : 0", 1},
+		{"false && status.missing > 0 ? 1 : 0", 0},
+	}
+
+	for _, tt := range tests {
+		expr, err := compileExpression(tt.expr, nil)
+		if err != nil {
+			t.Errorf("compileExpression(%q) error = %v", tt.expr, err)
+			continue
+		}
+
+		got, err := expr.evalFloat(obj)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.expr, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%q = %v, expected %v", tt.expr, got, tt.want)
+		}
+	}
+
+	// Evaluation errors skip the series
+	errorTests := []struct {
+		expr string
+		want string
+	}{
+		{"status.missing", "no such key"},
+		{"has(status.readyReplicas) ? spec.missing : 0", "no such key"},
+		// A fetch that failed leaves its variable unbound
+		{"scale.spec.replicas", "no such attribute"},
+		// Fields are dynamic, mixing an int field with a double fails when evaluated
+		{"spec.replicas + 0.5", "no such overload"},
+		{"status.readyReplicas / 0", "division by zero"},
+		{"status.phase", "expected a number"},
+		{"status.conditions[1].type == 'Ready' ? 1 : 0", "index out of bounds"},
+		{"status.missing > 0 && true ? 1 : 0", "no such key"},
+		{"size(spec.replicas)", "no such overload"},
+	}
+
+	for _, tt := range errorTests {
+		expr, err := compileExpression(tt.expr, []string{"scale"})
+		if err != nil {
+			t.Errorf("compileExpression(%q) error = %v", tt.expr, err)
+			continue
+		}
+
+		value, err := expr.evalFloat(obj)
+		if err == nil {
+			t.Errorf("%q: expected an error, got %v", tt.expr, value)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.expr, tt.want, err)
+		}
+	}
+}
+
+func TestValidateExprMetrics(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"status.replicas /",
+		"(1 + 2",
+		"status.",
+		"has(status)",
+		"unknown(status.replicas)",
+		"undeclared.field",
+		"'unterminated",
+		"a ? b",
+		"a # b",
+		// Literals are typed, an int and a double do not mix
+		"1 + 2.5",
+		// Non-numeric results
+		"status.phase == 'Running'",
+		"'Running'",
+		"[1, 2]",
+		"has(status.phase)",
+	} {
+		crdConfig := &CRDConfig{Metrics: []MetricConfig{{Type: "expr", Name: "bad", Expr: source}}}
+		if err := crdConfig.ValidateExprMetrics(); err == nil {
+			t.Errorf("Expected error for %q", source)
+		}
+	}
+
+	// Fetched objects are variables
+	crdConfig := &CRDConfig{
+		Fetches: []FetchConfig{{As: "scale", Subresource: "scale"}},
+		Metrics: []MetricConfig{{Type: "expr", Name: "desired", Expr: "scale.spec.replicas"}},
+	}
+	if err := crdConfig.ValidateExprMetrics(); err != nil {
+		t.Fatalf("ValidateExprMetrics() error = %v", err)
+	}
+
+	value, err := crdConfig.expressions["desired"].evalFloat(map[string]any{
+		"scale": map[string]any{"spec": map[string]any{"replicas": int64(2)}},
+	})
+	if err != nil || value != 2 {
+		t.Errorf("Expected 2 from the fetched object, got %v, %v", value, err)
+	}
+}
+
+func TestConfigurableCollector_CollectExprMetric(t *testing.T) {
+	crdConfig := &CRDConfig{
+		Name:         "test-crd",
+		CommonLabels: map[string]string{"name": "metadata.name"},
+		Metrics: []MetricConfig{
+			{
+				Type: "expr",
+				Name: "ready_ratio",
+				Expr: "spec.replicas == 0 ? 1.0 : " +
+					"(has(status.readyReplicas) ? double(status.readyReplicas) : 0.0) / double(spec.replicas)",
+			},
+		},
+	}
+
+	if err := crdConfig.ValidateExprMetrics(); err != nil {
+		t.Fatalf("ValidateExprMetrics() error = %v", err)
+	}
+
+	collector := NewConfigurableCollector(crdConfig, "test", log.NewEntry(log.StandardLogger()))
+
+	for name, obj := range map[string]map[string]any{
+		"half":        {"spec": map[string]any{"replicas": int64(2)}, "status": map[string]any{"readyReplicas": int64(1)}},
+		"none-ready":  {"spec": map[string]any{"replicas": int64(3)}, "status": map[string]any{}},
+		"scaled-down": {"spec": map[string]any{"replicas": int64(0)}, "status": map[string]any{}},
+		"no-spec":     {}, // the expression fails, the series is skipped
+	} {
+		obj["metadata"] = map[string]any{"name": name}
+		collector.handleAdd(&unstructured.Unstructured{Object: obj})
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	go func() {
+		collector.collect(ch)
+		close(ch)
+	}()
+
+	got := make(map[string]float64)
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		got[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+
+	want := map[string]float64{"half": 0.5, "none-ready": 0, "scaled-down": 1}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s: expected %v, got %v", name, value, got[name])
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -17,7 +19,12 @@ import (
 const collectorName = "dynamic"
 
 func init() {
-	registry.MustRegister(collectorName, NewConfigurableDynamicCollector)
+	registry.MustRegister(
+		collectorName,
+		NewConfigurableDynamicCollector,
+		registry.WithDescription("Configuration-driven metrics of custom resources"),
+		registry.WithConfigRBAC(requiredRBAC),
+	)
 }
 
 // NewConfigurableDynamicCollector creates configurable dynamic collectors from config
@@ -31,8 +38,12 @@ func NewConfigurableDynamicCollector(
 			Debug("Failed to load dynamic collector config, using defaults")
 	}
 
+	if err := cfg.LoadConfigFiles(); err != nil {
+		return nil, err
+	}
+
 	// 2. Check if any CRDs configured (no config = disabled)
-	if len(cfg.CRDs) == 0 {
+	if len(cfg.CRDs) == 0 && !cfg.DiscoverAnnotations {
 		factoryCtx.Logger.Debug("No CRDs configured for dynamic collector, skipping")
 		return nil, nil
 	}
@@ -49,7 +60,19 @@ func NewConfigurableDynamicCollector(
 		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
-	// 5. Create a multi-collector that manages multiple CRD collectors
+	// 5. Merge the CRD configs advertised by CRD annotations
+	if cfg.DiscoverAnnotations {
+		if err := cfg.LoadAnnotationConfigs(factoryCtx.Ctx, dynamicClient, factoryCtx.Logger); err != nil {
+			factoryCtx.Logger.WithError(err).Warn("Failed to discover CRD configs from annotations")
+		}
+
+		if len(cfg.CRDs) == 0 {
+			factoryCtx.Logger.Debug("No CRDs configured or annotated for dynamic collector, skipping")
+			return nil, nil
+		}
+	}
+
+	// 6. Create a multi-collector that manages multiple CRD collectors
 	return newMultiCollector(cfg, dynamicClient, factoryCtx)
 }
 
@@ -71,6 +94,7 @@ func NewConfigurableCollectorFromConfig(
 	metricsNamespace string,
 	restConfig *rest.Config,
 	logger *log.Entry,
+	opts ...base.BaseCollectorOption,
 ) (collector.Collector, error) {
 	if crdConfig == nil {
 		return nil, errors.New("crdConfig cannot be nil")
@@ -80,6 +104,10 @@ func NewConfigurableCollectorFromConfig(
 		return nil, errors.New("rest config cannot be nil")
 	}
 
+	if err := crdConfig.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Create dynamic client
 	dynamicClient, err := createDynamicClient(restConfig)
 	if err != nil {
@@ -87,7 +115,12 @@ func NewConfigurableCollectorFromConfig(
 	}
 
 	// Create configurable collector implementation
-	configurableCollector := NewConfigurableCollector(crdConfig, metricsNamespace, logger)
+	configurableCollector := NewConfigurableCollector(
+		crdConfig,
+		metricsNamespace,
+		logger,
+		WithDynamicClient(dynamicClient),
+	)
 
 	// Build GVR from config
 	gvr := schema.GroupVersionResource{
@@ -103,6 +136,8 @@ func NewConfigurableCollectorFromConfig(
 		EventHandler:      configurableCollector.GetEventHandler(),
 		MetricsCollector:  configurableCollector.GetMetricsCollector(),
 		MetricDescriptors: configurableCollector.GetMetricDescriptors(),
+		Worker:            configurableCollector.GetWorker(),
+		ValidateConfig:    configurableCollector.GetConfigValidator(),
 	}
 
 	// Create and return the dynamic collector
@@ -111,6 +146,7 @@ func NewConfigurableCollectorFromConfig(
 		dynamicClient,
 		dynamicConfig,
 		logger,
+		opts...,
 	)
 }
 
@@ -130,16 +166,21 @@ func newMultiCollector(
 		crdCfg := &cfg.CRDs[i]
 
 		// Validate CRD config
-		if crdCfg.Name == "" {
-			return nil, fmt.Errorf("CRD config %d: name is required", i)
-		}
+		if err := crdCfg.Validate(); err != nil {
+			if crdCfg.Name == "" {
+				return nil, fmt.Errorf("CRD config %d: %w", i, err)
+			}
 
-		if crdCfg.GVR.Resource == "" {
-			return nil, fmt.Errorf("CRD config %s: gvr.resource is required", crdCfg.Name)
+			return nil, fmt.Errorf("CRD config %s: %w", crdCfg.Name, err)
 		}
 
 		// Create collector implementation
-		impl := NewConfigurableCollector(crdCfg, factoryCtx.MetricsNamespace, factoryCtx.Logger)
+		impl := NewConfigurableCollector(
+			crdCfg,
+			factoryCtx.MetricsNamespace,
+			factoryCtx.Logger,
+			WithDynamicClient(dynamicClient),
+		)
 
 		// Create dynamic collector configuration
 		gvr := schema.GroupVersionResource{
@@ -154,6 +195,8 @@ func newMultiCollector(
 			EventHandler:      impl.GetEventHandler(),
 			MetricsCollector:  impl.GetMetricsCollector(),
 			MetricDescriptors: impl.GetMetricDescriptors(),
+			Worker:            impl.GetWorker(),
+			ValidateConfig:    impl.GetConfigValidator(),
 		}
 
 		// Create dynamic collector
@@ -162,6 +205,8 @@ func newMultiCollector(
 			dynamicClient,
 			dynamicCfg,
 			factoryCtx.Logger.WithField("crd", crdCfg.Name),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create collector for CRD %s: %w", crdCfg.Name, err)
@@ -218,6 +263,90 @@ func (mc *multiCollector) Health() error {
 	return nil
 }
 
+// HasSynced returns true if the informers of every CRD collector have synced
+func (mc *multiCollector) HasSynced() bool {
+	for _, c := range mc.collectors {
+		if informer, ok := c.(collector.InformerCollector); ok && !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Liveness combines the lifecycle state of the CRD collectors: started and
+// ready once all of them are, failed when one of them failed to start
+func (mc *multiCollector) Liveness() collector.Liveness {
+	liveness := collector.Liveness{Started: true, Ready: true}
+
+	for _, c := range mc.collectors {
+		reporter, ok := c.(collector.LivenessReporter)
+		if !ok {
+			continue
+		}
+
+		state := reporter.Liveness()
+		liveness.Started = liveness.Started && state.Started
+		liveness.Ready = liveness.Ready && state.Ready
+
+		if liveness.StartError == nil {
+			liveness.StartError = state.StartError
+		}
+
+		if state.StartedAt.After(liveness.StartedAt) {
+			liveness.StartedAt = state.StartedAt
+		}
+	}
+
+	return liveness
+}
+
+// RuntimeStats sums the runtime counters of the CRD collectors, instrumented
+// once one of them is
+func (mc *multiCollector) RuntimeStats() collector.RuntimeStats {
+	var stats collector.RuntimeStats
+
+	for _, c := range mc.collectors {
+		reporter, ok := c.(collector.RuntimeReporter)
+		if !ok {
+			continue
+		}
+
+		sub := reporter.RuntimeStats()
+		stats.Instrumented = stats.Instrumented || sub.Instrumented
+		stats.HandlerEvents += sub.HandlerEvents
+		stats.HandlerEventsByType = mergeCounts(stats.HandlerEventsByType, sub.HandlerEventsByType)
+		stats.HandlerPending += sub.HandlerPending
+		stats.HandlerDuration += sub.HandlerDuration
+		stats.LockWait = mergeCounts(stats.LockWait, sub.LockWait)
+		stats.InformerRestarts += sub.InformerRestarts
+		stats.WatchErrors += sub.WatchErrors
+		stats.CacheObjects = mergeCounts(stats.CacheObjects, sub.CacheObjects)
+		stats.QueueDepth += sub.QueueDepth
+		stats.QueueWait += sub.QueueWait
+		stats.QueueRetries += sub.QueueRetries
+	}
+
+	return stats
+}
+
+// mergeCounts adds the counts of src to dst, allocating dst when needed
+func mergeCounts[V int | uint64 | time.Duration](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		dst = make(map[string]V, len(src))
+	}
+
+	for key, count := range src {
+		dst[key] += count
+	}
+
+	return dst
+}
+
 func (mc *multiCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, c := range mc.collectors {
 		c.Describe(ch)
@@ -0,0 +1,241 @@
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	defaultFetchQPS   = 5
+	defaultFetchBurst = 10
+
+	// fetchTimeout bounds a single additional GET
+	fetchTimeout = 10 * time.Second
+)
+
+// fetcher issues the configured additional GETs for tracked resources
+type fetcher struct {
+	client  dynamic.Interface
+	gvr     schema.GroupVersionResource
+	fetches []FetchConfig
+	limiter flowcontrol.RateLimiter
+}
+
+// newFetcher creates a fetcher for a CRD config
+func newFetcher(client dynamic.Interface, crdConfig *CRDConfig) *fetcher {
+	qps := crdConfig.FetchQPS
+	if qps <= 0 {
+		qps = defaultFetchQPS
+	}
+
+	burst := crdConfig.FetchBurst
+	if burst <= 0 {
+		burst = defaultFetchBurst
+	}
+
+	return &fetcher{
+		client: client,
+		gvr: schema.GroupVersionResource{
+			Group:    crdConfig.GVR.Group,
+			Version:  crdConfig.GVR.Version,
+			Resource: crdConfig.GVR.Resource,
+		},
+		fetches: crdConfig.Fetches,
+		limiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+	}
+}
+
+// fetchAll runs every configured fetch for obj and returns the fetched
+// objects keyed by their As field. Fetches whose target does not exist are
+// omitted; the first other error aborts the remaining fetches.
+func (f *fetcher) fetchAll(ctx context.Context, obj *unstructured.Unstructured) (map[string]any, error) {
+	fetched := make(map[string]any, len(f.fetches))
+
+	for i := range f.fetches {
+		fetch := &f.fetches[i]
+
+		if err := f.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		result, err := f.fetchOne(ctx, obj, fetch)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", fetch.As, err)
+		}
+
+		fetched[fetch.As] = result.Object
+	}
+
+	return fetched, nil
+}
+
+// fetchOne issues a single GET for a subresource or related object
+func (f *fetcher) fetchOne(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	fetch *FetchConfig,
+) (*unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	if fetch.Subresource != "" {
+		return resourceClient(f.client, f.gvr, obj.GetNamespace()).
+			Get(ctx, obj.GetName(), metav1.GetOptions{}, fetch.Subresource)
+	}
+
+	namePath := fetch.NamePath
+	if namePath == "" {
+		namePath = "metadata.name"
+	}
+
+	namespacePath := fetch.NamespacePath
+	if namespacePath == "" {
+		namespacePath = "metadata.namespace"
+	}
+
+	name := extractFieldString(obj, namePath)
+	if name == "" {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: fetch.GVR.Resource}, "")
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    fetch.GVR.Group,
+		Version:  fetch.GVR.Version,
+		Resource: fetch.GVR.Resource,
+	}
+
+	return resourceClient(f.client, gvr, extractFieldString(obj, namespacePath)).
+		Get(ctx, name, metav1.GetOptions{})
+}
+
+// resourceClient returns a namespaced client, or a cluster-scoped one when namespace is empty
+func resourceClient(
+	client dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace string,
+) dynamic.ResourceInterface {
+	if namespace == "" {
+		return client.Resource(gvr)
+	}
+
+	return client.Resource(gvr).Namespace(namespace)
+}
+
+// runFetcher processes fetch requests until ctx is done. Resources are
+// re-fetched on every add/update event, including informer resyncs.
+func (c *ConfigurableCollector) runFetcher(ctx context.Context) {
+	queue := workqueue.NewTypedRateLimitingQueue(
+		workqueue.DefaultTypedControllerRateLimiter[string](),
+	)
+
+	c.mu.Lock()
+	c.fetchQueue = queue
+
+	for key := range c.resources {
+		queue.Add(key)
+	}
+	c.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+
+	for c.processNextFetch(ctx, queue) {
+	}
+
+	c.mu.Lock()
+	if c.fetchQueue == queue {
+		c.fetchQueue = nil
+	}
+	c.mu.Unlock()
+}
+
+// processNextFetch fetches the next queued resource and reports whether the queue is still running
+func (c *ConfigurableCollector) processNextFetch(
+	ctx context.Context,
+	queue workqueue.TypedRateLimitingInterface[string],
+) bool {
+	key, shutdown := queue.Get()
+	if shutdown {
+		return false
+	}
+	defer queue.Done(key)
+
+	if err := c.fetch(ctx, key); err != nil {
+		if ctx.Err() != nil {
+			return true
+		}
+
+		c.logger.WithError(err).WithField("resource", key).
+			Debug("Failed to fetch additional objects, retrying")
+		queue.AddRateLimited(key)
+
+		return true
+	}
+
+	queue.Forget(key)
+
+	return true
+}
+
+// fetch runs the configured fetches for a tracked resource and stores the results
+func (c *ConfigurableCollector) fetch(ctx context.Context, key string) error {
+	c.mu.RLock()
+	obj, ok := c.resources[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	fetched, err := c.fetcher.fetchAll(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The resource may have been deleted while fetching
+	if _, ok := c.resources[key]; ok {
+		c.fetched[key] = fetched
+	}
+
+	c.logger.WithFields(log.Fields{
+		"resource": key,
+		"fetched":  len(fetched),
+	}).Debug("Fetched additional objects")
+
+	return nil
+}
+
+// withFetched returns obj with the fetched objects merged in as top-level fields.
+// Must be called with c.mu held.
+func (c *ConfigurableCollector) withFetched(key string, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	fetched := c.fetched[key]
+	if len(fetched) == 0 {
+		return obj
+	}
+
+	merged := make(map[string]any, len(obj.Object)+len(fetched))
+	maps.Copy(merged, obj.Object)
+	maps.Copy(merged, fetched)
+
+	return &unstructured.Unstructured{Object: merged}
+}
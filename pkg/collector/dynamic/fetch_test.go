@@ -0,0 +1,157 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestFetchConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		fetch     FetchConfig
+		expectErr bool
+	}{
+		{name: "subresource", fetch: FetchConfig{As: "scale", Subresource: "scale"}},
+		{
+			name:  "related object",
+			fetch: FetchConfig{As: "svc", GVR: &GVRConfig{Version: "v1", Resource: "services"}},
+		},
+		{name: "missing as", fetch: FetchConfig{Subresource: "scale"}, expectErr: true},
+		{name: "no target", fetch: FetchConfig{As: "x"}, expectErr: true},
+		{
+			name: "both targets",
+			fetch: FetchConfig{
+				As:          "x",
+				Subresource: "status",
+				GVR:         &GVRConfig{Version: "v1", Resource: "services"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fetch.Validate()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Validate() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestConfigurableCollector_FetchRelatedObject(t *testing.T) {
+	service := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]any{
+			"name":      "backend",
+			"namespace": "default",
+		},
+		"spec": map[string]any{
+			"type": "ClusterIP",
+		},
+	}}
+
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), service)
+
+	crdConfig := &CRDConfig{
+		Name: "app",
+		GVR:  GVRConfig{Group: "apps.example.com", Version: "v1", Resource: "apps"},
+		CommonLabels: map[string]string{
+			"name": "metadata.name",
+		},
+		Fetches: []FetchConfig{
+			{
+				As:       "service",
+				GVR:      &GVRConfig{Version: "v1", Resource: "services"},
+				NamePath: "spec.serviceName",
+			},
+		},
+		Metrics: []MetricConfig{
+			{
+				Type:   "info",
+				Name:   "service",
+				Labels: map[string]string{"service_type": "service.spec.type"},
+			},
+		},
+	}
+
+	c := NewConfigurableCollector(
+		crdConfig,
+		"test",
+		log.NewEntry(log.StandardLogger()),
+		WithDynamicClient(client),
+	)
+
+	if c.GetWorker() == nil {
+		t.Fatal("Expected a fetch worker when fetches are configured")
+	}
+
+	c.handleAdd(&unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{
+			"name":      "web",
+			"namespace": "default",
+		},
+		"spec": map[string]any{
+			"serviceName": "backend",
+		},
+	}})
+
+	if err := c.fetch(context.Background(), "default/web"); err != nil {
+		t.Fatalf("fetch() error = %v", err)
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	c.collect(ch)
+	close(ch)
+
+	var labels []string
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		for _, label := range m.GetLabel() {
+			labels = append(labels, label.GetName()+"="+label.GetValue())
+		}
+	}
+
+	if got := strings.Join(labels, ","); got != "name=web,namespace=default,service_type=ClusterIP" {
+		t.Errorf("Expected labels from fetched service, got %q", got)
+	}
+
+	c.handleDelete(&unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{
+			"name":      "web",
+			"namespace": "default",
+		},
+	}})
+
+	if len(c.fetched) != 0 {
+		t.Errorf("Expected fetched objects to be dropped on delete, got %d", len(c.fetched))
+	}
+}
+
+func TestConfigurableCollector_NoFetchWorker(t *testing.T) {
+	c := NewConfigurableCollector(
+		&CRDConfig{Name: "app"},
+		"test",
+		log.NewEntry(log.StandardLogger()),
+		WithDynamicClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())),
+	)
+
+	if c.GetWorker() != nil {
+		t.Error("Expected no fetch worker without configured fetches")
+	}
+}
@@ -0,0 +1,97 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfigFiles appends the CRD configs of the files matching the
+// ConfigFiles glob patterns to CRDs. Each file holds one or more YAML
+// documents, each a CRD config, so teams can own their CRD metric definitions
+// in separate files. CRD names must be unique across the inline and file
+// configs.
+func (c *CollectorConfig) LoadConfigFiles() error {
+	seen := make(map[string]bool)
+
+	for _, pattern := range c.ConfigFiles {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid config file pattern %q: %w", pattern, err)
+		}
+
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+
+			seen[path] = true
+
+			crds, err := readCRDConfigs(path)
+			if err != nil {
+				return fmt.Errorf("failed to load CRD configs from %s: %w", path, err)
+			}
+
+			c.CRDs = append(c.CRDs, crds...)
+		}
+	}
+
+	names := make(map[string]bool, len(c.CRDs))
+	for i := range c.CRDs {
+		name := c.CRDs[i].Name
+		if name != "" && names[name] {
+			return fmt.Errorf("CRD config %s is defined more than once", name)
+		}
+
+		names[name] = true
+	}
+
+	return nil
+}
+
+// readCRDConfigs decodes the CRD configs of a multi-document YAML file
+func readCRDConfigs(path string) ([]CRDConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return decodeCRDConfigs(file)
+}
+
+// decodeCRDConfigs decodes the CRD configs of a multi-document YAML stream,
+// skipping empty documents
+func decodeCRDConfigs(r io.Reader) ([]CRDConfig, error) {
+	var crds []CRDConfig
+
+	decoder := yaml.NewDecoder(r)
+
+	for i := 0; ; i++ {
+		var node yaml.Node
+
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return crds, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+
+		if len(node.Content) == 0 || node.Content[0].Kind == yaml.ScalarNode && node.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		var crd CRDConfig
+		if err := node.Decode(&crd); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+
+		crds = append(crds, crd)
+	}
+}
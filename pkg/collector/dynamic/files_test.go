@@ -0,0 +1,97 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestLoadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfigFile(t, dir, "apps.yaml", `
+name: apps
+gvr:
+  group: apps.example.com
+  version: v1
+  resource: applications
+resyncPeriod: 30s
+metrics:
+  - type: info
+    name: info
+---
+---
+name: jobs
+gvr:
+  group: batch.example.com
+  version: v1
+  resource: jobs
+`)
+	writeConfigFile(t, dir, "db.yaml", `
+name: databases
+gvr:
+  group: db.example.com
+  version: v1
+  resource: databases
+`)
+	writeConfigFile(t, dir, "notes.txt", "not a config")
+
+	cfg := NewDefaultCollectorConfig()
+	cfg.CRDs = []CRDConfig{{Name: "inline"}}
+	cfg.ConfigFiles = []string{filepath.Join(dir, "*.yaml"), filepath.Join(dir, "apps.yaml")}
+
+	if err := cfg.LoadConfigFiles(); err != nil {
+		t.Fatalf("LoadConfigFiles() error = %v", err)
+	}
+
+	names := make([]string, 0, len(cfg.CRDs))
+	for i := range cfg.CRDs {
+		names = append(names, cfg.CRDs[i].Name)
+	}
+
+	if got := strings.Join(names, ","); got != "inline,apps,jobs,databases" {
+		t.Fatalf("Unexpected CRD configs %s", got)
+	}
+
+	apps := cfg.CRDs[1]
+	if apps.GVR.Resource != "applications" || apps.ResyncPeriod.Seconds() != 30 || len(apps.Metrics) != 1 {
+		t.Errorf("Unexpected apps config %+v", apps)
+	}
+}
+
+func TestLoadConfigFilesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		inline  []CRDConfig
+		content string
+	}{
+		{name: "duplicate name", inline: []CRDConfig{{Name: "apps"}}, content: "name: apps\n"},
+		{name: "invalid yaml", content: "name: [apps\n"},
+		{name: "not a CRD config", content: "- apps\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFile(t, dir, "crd.yaml", tt.content)
+
+			cfg := NewDefaultCollectorConfig()
+			cfg.CRDs = tt.inline
+			cfg.ConfigFiles = []string{filepath.Join(dir, "*.yaml")}
+
+			if err := cfg.LoadConfigFiles(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -5,15 +5,21 @@ import (
 	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-// extractFieldString extracts a string field from an unstructured object using a JSONPath
+// extractFieldString extracts a string field from an unstructured object using
+// a dot-separated path, or the text output of a JSONPath expression
 func extractFieldString(obj *unstructured.Unstructured, path string) string {
 	if path == "" {
 		return ""
 	}
 
+	if isJSONPath(path) {
+		return printJSONPath(obj.Object, path)
+	}
+
 	parts := strings.Split(path, ".")
 	value, _, _ := unstructured.NestedString(obj.Object, parts...)
 
@@ -22,18 +28,34 @@ func extractFieldString(obj *unstructured.Unstructured, path string) string {
 
 // extractFieldFloat extracts a float field from an unstructured object
 func extractFieldFloat(obj *unstructured.Unstructured, path string) float64 {
+	value, _ := lookupFieldFloat(obj, path)
+	return value
+}
+
+// lookupFieldFloat extracts a float field from an unstructured object and reports whether it was found
+func lookupFieldFloat(obj *unstructured.Unstructured, path string) (float64, bool) {
 	if path == "" {
-		return 0
+		return 0, false
+	}
+
+	// The first match of a JSONPath expression is the value
+	if isJSONPath(path) {
+		values := findJSONPath(obj.Object, path)
+		if len(values) == 0 {
+			return 0, false
+		}
+
+		return toFloat64(values[0]), true
 	}
 
 	parts := strings.Split(path, ".")
 
 	value, found, err := unstructured.NestedFieldNoCopy(obj.Object, parts...)
 	if err != nil || !found {
-		return 0
+		return 0, false
 	}
 
-	return toFloat64(value)
+	return toFloat64(value), true
 }
 
 // extractFieldMap extracts a map field from an unstructured object
@@ -42,6 +64,17 @@ func extractFieldMap(obj *unstructured.Unstructured, path string) map[string]any
 		return nil
 	}
 
+	if isJSONPath(path) {
+		values := findJSONPath(obj.Object, path)
+		if len(values) == 0 {
+			return nil
+		}
+
+		value, _ := values[0].(map[string]any)
+
+		return value
+	}
+
 	parts := strings.Split(path, ".")
 
 	value, found, err := unstructured.NestedMap(obj.Object, parts...)
@@ -58,6 +91,19 @@ func extractFieldSlice(obj *unstructured.Unstructured, path string) []any {
 		return nil
 	}
 
+	// A JSONPath expression matching a list returns it, one matching list
+	// items (e.g. a filter) returns the items matched
+	if isJSONPath(path) {
+		values := findJSONPath(obj.Object, path)
+		if len(values) == 1 {
+			if list, ok := values[0].([]any); ok {
+				return list
+			}
+		}
+
+		return values
+	}
+
 	parts := strings.Split(path, ".")
 
 	value, found, err := unstructured.NestedSlice(obj.Object, parts...)
@@ -93,8 +139,16 @@ func toFloat64(value any) float64 {
 		}
 		return 0.0
 	case string:
-		f, _ := strconv.ParseFloat(v, 64)
-		return f
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+
+		// Fall back to Kubernetes quantities such as "500m" or "2Gi"
+		if q, err := resource.ParseQuantity(v); err == nil {
+			return q.AsApproximateFloat64()
+		}
+
+		return 0
 	default:
 		return 0
 	}
@@ -131,6 +131,8 @@ func TestToFloat64(t *testing.T) {
 		{name: "bool false", value: false, expected: 0.0},
 		{name: "string number", value: "3.14", expected: 3.14},
 		{name: "string non-number", value: "abc", expected: 0.0},
+		{name: "string quantity milli", value: "500m", expected: 0.5},
+		{name: "string quantity binary", value: "1Ki", expected: 1024.0},
 		{name: "unsupported type", value: struct{}{}, expected: 0.0},
 	}
 
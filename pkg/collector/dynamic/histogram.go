@@ -0,0 +1,116 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// validateHistogramMetric checks the path and buckets of a histogram metric
+func validateHistogramMetric(cfg *MetricConfig) error {
+	if cfg.Path == "" {
+		return errors.New("histogram metrics require path")
+	}
+
+	for i := 1; i < len(cfg.Buckets); i++ {
+		if cfg.Buckets[i] <= cfg.Buckets[i-1] {
+			return fmt.Errorf("buckets must be strictly increasing, got %v", cfg.Buckets)
+		}
+	}
+
+	return nil
+}
+
+// ValidateHistogramMetrics checks the configuration of the histogram metrics of the CRD
+func (c *CRDConfig) ValidateHistogramMetrics() error {
+	for i := range c.Metrics {
+		if c.Metrics[i].Type != "histogram" {
+			continue
+		}
+
+		if err := validateHistogramMetric(&c.Metrics[i]); err != nil {
+			return fmt.Errorf("metric %s: %w", c.Metrics[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// histogramBuckets returns the upper bounds of a histogram metric, the
+// Prometheus default buckets when none are configured
+func histogramBuckets(cfg *MetricConfig) []float64 {
+	if len(cfg.Buckets) == 0 {
+		return prometheus.DefBuckets
+	}
+
+	return cfg.Buckets
+}
+
+// histogramGroup accumulates the observations of one histogram group
+type histogramGroup struct {
+	labels  []string
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64 // key: upper bound, value: cumulative count
+}
+
+// collectHistogramMetric collects a histogram metric (aggregate)
+// Buckets a numeric field across all resources, grouped by the configured
+// group-by paths. Resources where the field is missing are skipped.
+func (c *ConfigurableCollector) collectHistogramMetric(
+	ch chan<- prometheus.Metric,
+	desc *prometheus.Desc,
+	cfg *MetricConfig,
+) {
+	bounds := histogramBuckets(cfg)
+	groupNames := getSortedKeys(cfg.GroupBy)
+	groupPaths := getSortedValues(cfg.GroupBy)
+	policy := c.missingLabelPolicy(cfg)
+	groups := make(map[string]*histogramGroup)
+
+	for key, obj := range c.resources {
+		obj = c.withFetched(key, obj)
+
+		value, found := lookupFieldFloat(obj, cfg.Path)
+		if !found {
+			continue
+		}
+
+		labels := make([]string, 0, len(groupPaths))
+		for _, path := range groupPaths {
+			labels = append(labels, extractFieldString(obj, path))
+		}
+
+		labels, ok := c.resolveLabels(groupNames, labels, policy)
+		if !ok {
+			continue
+		}
+
+		key := strings.Join(labels, "\x00")
+
+		group, ok := groups[key]
+		if !ok {
+			group = &histogramGroup{labels: labels, buckets: make(map[float64]uint64, len(bounds))}
+			for _, bound := range bounds {
+				group.buckets[bound] = 0
+			}
+
+			groups[key] = group
+		}
+
+		group.count++
+		group.sum += value
+
+		for _, bound := range bounds {
+			if value <= bound {
+				group.buckets[bound]++
+			}
+		}
+	}
+
+	for _, group := range groups {
+		ch <- prometheus.MustNewConstHistogram(desc, group.count, group.sum, group.buckets, group.labels...)
+	}
+}
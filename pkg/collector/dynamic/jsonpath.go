@@ -0,0 +1,131 @@
+package dynamic
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// compiledJSONPath is a parsed JSONPath expression. A JSONPath keeps state
+// while it evaluates, so evaluations are serialized.
+type compiledJSONPath struct {
+	mu   sync.Mutex
+	path *jsonpath.JSONPath
+	err  error
+}
+
+// jsonPaths caches the parsed JSONPath expressions, keyed by expression
+var jsonPaths sync.Map // map[string]*compiledJSONPath
+
+// isJSONPath reports whether a configured path is a JSONPath expression
+// (e.g. {.status.conditions[?(@.type=="Ready")].status}) rather than a
+// dot-separated field path
+func isJSONPath(path string) bool {
+	return strings.HasPrefix(strings.TrimSpace(path), "{")
+}
+
+// parseJSONPath parses a JSONPath expression, missing keys matching nothing
+func parseJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	path := jsonpath.New("path").AllowMissingKeys(true)
+	if err := path.Parse(expression); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", expression, err)
+	}
+
+	return path, nil
+}
+
+// compileJSONPath returns the cached parsed expression
+func compileJSONPath(expression string) *compiledJSONPath {
+	if cached, ok := jsonPaths.Load(expression); ok {
+		return cached.(*compiledJSONPath)
+	}
+
+	compiled := &compiledJSONPath{}
+	compiled.path, compiled.err = parseJSONPath(expression)
+
+	cached, _ := jsonPaths.LoadOrStore(expression, compiled)
+
+	return cached.(*compiledJSONPath)
+}
+
+// findJSONPath returns the values matched by a JSONPath expression, in
+// order. Invalid expressions match nothing (they are rejected on startup).
+func findJSONPath(object map[string]any, expression string) []any {
+	compiled := compileJSONPath(expression)
+	if compiled.err != nil {
+		return nil
+	}
+
+	compiled.mu.Lock()
+	results, err := compiled.path.FindResults(object)
+	compiled.mu.Unlock()
+
+	if err != nil {
+		return nil
+	}
+
+	var values []any
+
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+
+	return values
+}
+
+// printJSONPath returns the text output of a JSONPath expression, formatted
+// as kubectl -o jsonpath does: several matches are separated by spaces, maps
+// and lists are printed as JSON
+func printJSONPath(object map[string]any, expression string) string {
+	compiled := compileJSONPath(expression)
+	if compiled.err != nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	compiled.mu.Lock()
+	err := compiled.path.Execute(&buf, object)
+	compiled.mu.Unlock()
+
+	if err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+// ValidatePaths checks that the JSONPath expressions of the CRD config parse
+func (c *CRDConfig) ValidatePaths() error {
+	var paths []schemaCheck
+
+	for _, check := range c.schemaChecks() {
+		if isJSONPath(check.path) {
+			paths = append(paths, check)
+		}
+	}
+
+	for i := range c.Fetches {
+		fetch := &c.Fetches[i]
+		for _, path := range []string{fetch.NamePath, fetch.NamespacePath} {
+			if isJSONPath(path) {
+				paths = append(paths, schemaCheck{field: "fetch " + fetch.As, path: path})
+			}
+		}
+	}
+
+	for _, check := range paths {
+		if _, err := parseJSONPath(check.path); err != nil {
+			return fmt.Errorf("%s: %w", check.field, err)
+		}
+	}
+
+	return nil
+}
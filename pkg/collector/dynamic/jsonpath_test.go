@@ -0,0 +1,92 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newJSONPathObject() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"spec": map[string]any{
+				"replicas": int64(3),
+			},
+			"status": map[string]any{
+				"conditions": []any{
+					map[string]any{"type": "Ready", "status": "True", "reason": "Available"},
+					map[string]any{"type": "Progressing", "status": "False", "reason": "Stalled"},
+				},
+				"components": map[string]any{
+					"mysql": map[string]any{"phase": "Running"},
+				},
+				"replicas": []any{
+					map[string]any{"name": "a", "ready": int64(1)},
+					map[string]any{"name": "b", "ready": int64(0)},
+				},
+			},
+		},
+	}
+}
+
+func TestExtractFieldJSONPath(t *testing.T) {
+	obj := newJSONPathObject()
+
+	texts := map[string]string{
+		`{.status.conditions[?(@.type=="Ready")].status}`:   "True",
+		`{.status.conditions[?(@.type=="Missing")].status}`: "",
+		`{.status.replicas[*].name}`:                        "a b",
+		`{.spec.replicas}`:                                  "3",
+		`{.status.missing.field}`:                           "",
+	}
+	for path, expected := range texts {
+		if got := extractFieldString(obj, path); got != expected {
+			t.Errorf("extractFieldString(%s) = %q, expected %q", path, got, expected)
+		}
+	}
+
+	if value, found := lookupFieldFloat(obj, `{.status.replicas[?(@.name=="a")].ready}`); !found || value != 1 {
+		t.Errorf("Expected ready=1 for replica a, got %v (found=%v)", value, found)
+	}
+
+	if _, found := lookupFieldFloat(obj, `{.status.replicas[?(@.name=="c")].ready}`); found {
+		t.Error("Expected no value for a missing replica")
+	}
+
+	components := extractFieldMap(obj, `{.status.components}`)
+	if !reflect.DeepEqual(components, obj.Object["status"].(map[string]any)["components"]) {
+		t.Errorf("Unexpected components %v", components)
+	}
+
+	// A filter returns the list items it matches
+	conditions := extractFieldSlice(obj, `{.status.conditions[?(@.status=="True")]}`)
+	if len(conditions) != 1 || conditions[0].(map[string]any)["type"] != "Ready" {
+		t.Errorf("Expected the Ready condition, got %v", conditions)
+	}
+
+	if all := extractFieldSlice(obj, `{.status.conditions}`); len(all) != 2 {
+		t.Errorf("Expected 2 conditions, got %v", all)
+	}
+}
+
+func TestValidatePaths(t *testing.T) {
+	valid := &CRDConfig{
+		CommonLabels: map[string]string{"ready": `{.status.conditions[?(@.type=="Ready")].status}`},
+		Metrics: []MetricConfig{
+			{Name: "replicas", Type: "gauge", Path: "spec.replicas"},
+			{Name: "ready", Type: "gauge", Path: `{.status.replicas[?(@.name=="a")].ready}`},
+		},
+	}
+	if err := valid.ValidatePaths(); err != nil {
+		t.Errorf("ValidatePaths() error = %v", err)
+	}
+
+	invalid := &CRDConfig{
+		Metrics: []MetricConfig{{Name: "ready", Type: "gauge", Path: `{.status.conditions[?(@.type=="Ready")}`}},
+	}
+	if err := invalid.ValidatePaths(); err == nil {
+		t.Error("Expected error for an unterminated filter, got nil")
+	}
+}
@@ -0,0 +1,175 @@
+package dynamic
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/labring/sealos-state-metrics/pkg/identity"
+)
+
+// Missing label policies, applied when a label path is missing or empty
+const (
+	// MissingLabelEmpty emits the label with an empty value (default)
+	MissingLabelEmpty = "empty"
+	// MissingLabelUnknown emits the label with the value "unknown"
+	MissingLabelUnknown = "unknown"
+	// MissingLabelSkip skips the series entirely
+	MissingLabelSkip = "skip"
+
+	// unknownLabelValue is the label value used by MissingLabelUnknown
+	unknownLabelValue = "unknown"
+)
+
+// namespaceLabel is the label the resource namespace is injected as, read
+// from namespacePath
+const (
+	namespaceLabel = "namespace"
+	namespacePath  = "metadata.namespace"
+)
+
+// namespaceInjected returns whether the namespace label is injected: unless
+// disabled, or a common label already holds the namespace
+func (c *CRDConfig) namespaceInjected() bool {
+	if c.InjectNamespace != nil && !*c.InjectNamespace {
+		return false
+	}
+
+	if _, ok := c.CommonLabels[namespaceLabel]; ok {
+		return false
+	}
+
+	for _, path := range c.CommonLabels {
+		if path == namespacePath {
+			return false
+		}
+	}
+
+	return true
+}
+
+// effectiveCommonLabels returns the common labels, the namespace label
+// included when injected
+func (c *CRDConfig) effectiveCommonLabels() map[string]string {
+	if !c.namespaceInjected() {
+		return c.CommonLabels
+	}
+
+	labels := make(map[string]string, len(c.CommonLabels)+1)
+	for name, path := range c.CommonLabels {
+		labels[name] = path
+	}
+
+	labels[namespaceLabel] = namespacePath
+
+	return labels
+}
+
+// validateMissingLabelPolicy checks that policy is empty or a known policy
+func validateMissingLabelPolicy(policy string) error {
+	switch policy {
+	case "", MissingLabelEmpty, MissingLabelUnknown, MissingLabelSkip:
+		return nil
+	default:
+		return fmt.Errorf("invalid missingLabelPolicy %q (expected %s, %s or %s)",
+			policy, MissingLabelEmpty, MissingLabelUnknown, MissingLabelSkip)
+	}
+}
+
+// ValidateLabelPolicies checks the missing label policies of the CRD and its metrics
+func (c *CRDConfig) ValidateLabelPolicies() error {
+	if err := validateMissingLabelPolicy(c.MissingLabelPolicy); err != nil {
+		return err
+	}
+
+	for i := range c.Metrics {
+		if err := validateMissingLabelPolicy(c.Metrics[i].MissingLabelPolicy); err != nil {
+			return fmt.Errorf("metric %s: %w", c.Metrics[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// reservedLabelNames are the cluster identity target labels the server adds to
+// every metric. Configured labels must not reuse them, since wrapping a
+// descriptor with a label it already has fails its registration.
+var reservedLabelNames = []string{identity.ClusterLabel, identity.RegionLabel, identity.ZoneLabel}
+
+// ValidateLabelNames checks that the label names of the CRD and its metrics
+// do not collide with the cluster identity target labels
+func (c *CRDConfig) ValidateLabelNames() error {
+	for name := range c.CommonLabels {
+		if slices.Contains(reservedLabelNames, name) {
+			return fmt.Errorf("commonLabels: label %s is reserved for the cluster identity", name)
+		}
+	}
+
+	injected := c.namespaceInjected()
+
+	for i := range c.Metrics {
+		m := &c.Metrics[i]
+
+		names := append(getSortedKeys(m.Labels), getSortedKeys(m.GroupBy)...)
+		names = append(names, m.KeyLabel, m.ValueLabel)
+
+		for _, name := range names {
+			if slices.Contains(reservedLabelNames, name) {
+				return fmt.Errorf("metric %s: label %s is reserved for the cluster identity", m.Name, name)
+			}
+		}
+
+		// Aggregates have no common labels, groupBy and valueLabel may use it
+		if injected && (slices.Contains(getSortedKeys(m.Labels), namespaceLabel) || m.KeyLabel == namespaceLabel) {
+			return fmt.Errorf("metric %s: label %s is injected from the resource namespace, "+
+				"drop it or set injectNamespace: false", m.Name, namespaceLabel)
+		}
+	}
+
+	return nil
+}
+
+// missingLabelPolicy returns the effective missing label policy of a metric
+func (c *ConfigurableCollector) missingLabelPolicy(cfg *MetricConfig) string {
+	if cfg.MissingLabelPolicy != "" {
+		return cfg.MissingLabelPolicy
+	}
+
+	if c.crdConfig.MissingLabelPolicy != "" {
+		return c.crdConfig.MissingLabelPolicy
+	}
+
+	return MissingLabelEmpty
+}
+
+// resolveLabels returns the label values with missing (empty) values replaced
+// by the label default, or else handled by policy. It returns false when the
+// series must be skipped.
+func (c *ConfigurableCollector) resolveLabels(names, values []string, policy string) ([]string, bool) {
+	resolved := make([]string, len(values))
+
+	for i, value := range values {
+		if value != "" {
+			resolved[i] = value
+			continue
+		}
+
+		// Cluster-scoped resources have no namespace to inject
+		if names[i] == namespaceLabel && c.namespaceInjected {
+			continue
+		}
+
+		if defaultValue, ok := c.crdConfig.LabelDefaults[names[i]]; ok {
+			resolved[i] = defaultValue
+			continue
+		}
+
+		switch policy {
+		case MissingLabelSkip:
+			return nil, false
+		case MissingLabelUnknown:
+			resolved[i] = unknownLabelValue
+		}
+	}
+
+	return resolved, true
+}
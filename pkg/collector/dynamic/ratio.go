@@ -0,0 +1,92 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Divide-by-zero policies of ratio metrics, applied when the denominator is zero
+const (
+	// DivideByZeroSkip skips the series (default)
+	DivideByZeroSkip = "skip"
+	// DivideByZeroZero emits 0
+	DivideByZeroZero = "zero"
+	// DivideByZeroNaN emits NaN
+	DivideByZeroNaN = "nan"
+)
+
+// validateRatioMetric checks the paths and divide-by-zero policy of a ratio metric
+func validateRatioMetric(cfg *MetricConfig) error {
+	if cfg.Path == "" || cfg.DenominatorPath == "" {
+		return errors.New("ratio metrics require path and denominatorPath")
+	}
+
+	switch cfg.DivideByZero {
+	case "", DivideByZeroSkip, DivideByZeroZero, DivideByZeroNaN:
+		return nil
+	default:
+		return fmt.Errorf("invalid divideByZero %q (expected %s, %s or %s)",
+			cfg.DivideByZero, DivideByZeroSkip, DivideByZeroZero, DivideByZeroNaN)
+	}
+}
+
+// ValidateRatioMetrics checks the configuration of the ratio metrics of the CRD
+func (c *CRDConfig) ValidateRatioMetrics() error {
+	for i := range c.Metrics {
+		if c.Metrics[i].Type != "ratio" {
+			continue
+		}
+
+		if err := validateRatioMetric(&c.Metrics[i]); err != nil {
+			return fmt.Errorf("metric %s: %w", c.Metrics[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ratioValue returns path / denominatorPath of an object and whether the
+// series must be emitted. A missing numerator counts as zero, since status
+// fields such as readyReplicas are omitted when zero; a missing denominator
+// skips the series.
+func ratioValue(obj *unstructured.Unstructured, cfg *MetricConfig) (float64, bool) {
+	denominator, found := lookupFieldFloat(obj, cfg.DenominatorPath)
+	if !found {
+		return 0, false
+	}
+
+	numerator, _ := lookupFieldFloat(obj, cfg.Path)
+
+	if denominator == 0 {
+		switch cfg.DivideByZero {
+		case DivideByZeroZero:
+			return 0, true
+		case DivideByZeroNaN:
+			return math.NaN(), true
+		default:
+			return 0, false
+		}
+	}
+
+	return numerator / denominator, true
+}
+
+// collectRatioMetric collects a ratio metric
+func (c *ConfigurableCollector) collectRatioMetric(
+	ch chan<- prometheus.Metric,
+	desc *prometheus.Desc,
+	obj *unstructured.Unstructured,
+	cfg *MetricConfig,
+	commonLabels []string,
+) {
+	value, ok := ratioValue(obj, cfg)
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, commonLabels...)
+}
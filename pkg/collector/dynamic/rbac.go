@@ -0,0 +1,78 @@
+package dynamic
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// requiredRBAC returns the permissions of the configured CRDs. The resources
+// of CRD configs advertised by annotations are only known in the cluster, so
+// only listing the CRDs is granted for them.
+func requiredRBAC(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+	cfg := NewDefaultCollectorConfig()
+	if err := loader.LoadModuleConfig("collectors.dynamic", cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.LoadConfigFiles(); err != nil {
+		return nil, err
+	}
+
+	var rules []rbacv1.PolicyRule
+
+	if cfg.DiscoverAnnotations {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{crdGVR.Group},
+			Resources: []string{crdGVR.Resource},
+			Verbs:     []string{"list"},
+		})
+	}
+
+	for i := range cfg.CRDs {
+		rules = append(rules, cfg.CRDs[i].RequiredRBAC()...)
+	}
+
+	return rules, nil
+}
+
+// RequiredRBAC returns the permissions needed to watch the resources of the
+// CRD config, fetch their related objects and validate the config against the
+// CRD schema
+func (c *CRDConfig) RequiredRBAC() []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{{
+		APIGroups: []string{c.GVR.Group},
+		Resources: []string{c.GVR.Resource},
+		Verbs:     []string{"list", "watch"},
+	}}
+
+	// Core resources are not defined by a CRD, their schema is not fetched
+	if c.GVR.Group != "" {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{crdGVR.Group},
+			Resources:     []string{crdGVR.Resource},
+			ResourceNames: []string{c.GVR.Resource + "." + c.GVR.Group},
+			Verbs:         []string{"get"},
+		})
+	}
+
+	for i := range c.Fetches {
+		fetch := &c.Fetches[i]
+
+		switch {
+		case fetch.Subresource != "":
+			rules = append(rules, rbacv1.PolicyRule{
+				APIGroups: []string{c.GVR.Group},
+				Resources: []string{c.GVR.Resource + "/" + fetch.Subresource},
+				Verbs:     []string{"get"},
+			})
+		case fetch.GVR != nil:
+			rules = append(rules, rbacv1.PolicyRule{
+				APIGroups: []string{fetch.GVR.Group},
+				Resources: []string{fetch.GVR.Resource},
+				Verbs:     []string{"get"},
+			})
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,36 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestCRDConfigRequiredRBAC(t *testing.T) {
+	cfg := CRDConfig{
+		Name: "apps",
+		GVR:  GVRConfig{Group: "apps.example.com", Version: "v1", Resource: "apps"},
+		Fetches: []FetchConfig{
+			{As: "scale", Subresource: "scale"},
+			{As: "config", GVR: &GVRConfig{Version: "v1", Resource: "configmaps"}},
+		},
+	}
+
+	expected := []rbacv1.PolicyRule{
+		{APIGroups: []string{"apps.example.com"}, Resources: []string{"apps"}, Verbs: []string{"list", "watch"}},
+		{
+			APIGroups:     []string{"apiextensions.k8s.io"},
+			Resources:     []string{"customresourcedefinitions"},
+			ResourceNames: []string{"apps.apps.example.com"},
+			Verbs:         []string{"get"},
+		},
+		{APIGroups: []string{"apps.example.com"}, Resources: []string{"apps/scale"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+	}
+
+	if rules := cfg.RequiredRBAC(); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected rules %+v, got %+v", expected, rules)
+	}
+}
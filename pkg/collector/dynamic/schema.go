@@ -0,0 +1,389 @@
+package dynamic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// schemaTimeout bounds the GET of the CRD schema
+const schemaTimeout = 10 * time.Second
+
+// entrySegment is the path segment standing for the entries of a map or the
+// items of an array (e.g. "status.conditions.*.type")
+const entrySegment = "*"
+
+// crdGVR is the GroupVersionResource of CustomResourceDefinitions
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// fieldKind is the kind of value a configured path must point to
+type fieldKind string
+
+const (
+	// kindString is read with NestedString, other types yield empty labels
+	kindString fieldKind = "string"
+	// kindNumber is converted by toFloat64 (numbers, booleans and quantities)
+	kindNumber fieldKind = "number"
+	// kindMap is read as a map of entries (for map metrics)
+	kindMap fieldKind = "object"
+	// kindList is read as a list of items (for conditions metrics)
+	kindList fieldKind = "array"
+)
+
+// stringSchema is the schema of string fields
+var stringSchema = map[string]any{"type": "string"}
+
+// metadataSchema describes the object metadata, which CRD schemas leave
+// unspecified
+var metadataSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"name":              stringSchema,
+		"generateName":      stringSchema,
+		"namespace":         stringSchema,
+		"uid":               stringSchema,
+		"resourceVersion":   stringSchema,
+		"creationTimestamp": stringSchema,
+		"deletionTimestamp": stringSchema,
+		"generation":        map[string]any{"type": "integer"},
+		"labels":            map[string]any{"type": "object", "additionalProperties": stringSchema},
+		"annotations":       map[string]any{"type": "object", "additionalProperties": stringSchema},
+		"ownerReferences":   map[string]any{"type": "array"},
+		"finalizers":        map[string]any{"type": "array"},
+	},
+}
+
+// schemaCheck is a configured path and the kind of value it must point to
+type schemaCheck struct {
+	field string // configuration field, e.g. "metric replicas path"
+	path  string
+	kind  fieldKind
+}
+
+// schemaMismatch is a configured path that does not match the CRD schema
+type schemaMismatch struct {
+	field string
+	path  string
+	err   error
+}
+
+// schemaChecks returns the paths of the CRD config along with the kind of
+// value the collector reads from them
+func (c *CRDConfig) schemaChecks() []schemaCheck {
+	var checks []schemaCheck
+
+	for _, name := range getSortedKeys(c.CommonLabels) {
+		checks = append(checks, schemaCheck{"commonLabels " + name, c.CommonLabels[name], kindString})
+	}
+
+	for i := range c.Metrics {
+		m := &c.Metrics[i]
+		prefix := "metric " + m.Name + " "
+
+		switch m.Type {
+		case "info":
+			for _, name := range getSortedKeys(m.Labels) {
+				checks = append(checks, schemaCheck{prefix + "label " + name, m.Labels[name], kindString})
+			}
+
+		case "count":
+			checks = append(checks, schemaCheck{prefix + "path", m.Path, kindString})
+
+		case "sum", "min", "max", "avg", "histogram":
+			checks = append(checks, schemaCheck{prefix + "path", m.Path, kindNumber})
+			for _, name := range getSortedKeys(m.GroupBy) {
+				checks = append(checks, schemaCheck{prefix + "groupBy " + name, m.GroupBy[name], kindString})
+			}
+
+		case "gauge":
+			checks = append(checks, schemaCheck{prefix + "path", m.Path, kindNumber})
+
+		case "ratio":
+			checks = append(checks,
+				schemaCheck{prefix + "path", m.Path, kindNumber},
+				schemaCheck{prefix + "denominatorPath", m.DenominatorPath, kindNumber},
+			)
+
+		case "map_state", "map_gauge":
+			valueKind := kindString
+			if m.Type == "map_gauge" {
+				valueKind = kindNumber
+			}
+
+			checks = append(checks, schemaCheck{prefix + "path", m.Path, kindMap})
+			if m.ValuePath != "" {
+				checks = append(checks, schemaCheck{
+					prefix + "valuePath",
+					m.Path + "." + entrySegment + "." + m.ValuePath,
+					valueKind,
+				})
+			}
+
+		case "conditions":
+			checks = append(checks, schemaCheck{prefix + "path", m.Path, kindList})
+
+			fields := map[string]string{"type": "type", "status": "status"}
+			if m.Condition != nil {
+				if m.Condition.TypeField != "" {
+					fields["type"] = m.Condition.TypeField
+				}
+
+				if m.Condition.StatusField != "" {
+					fields["status"] = m.Condition.StatusField
+				}
+
+				// The reason is optional in most condition schemas, only
+				// check it when configured
+				if m.Condition.ReasonField != "" {
+					fields["reason"] = m.Condition.ReasonField
+				}
+			}
+
+			for _, name := range getSortedKeys(fields) {
+				checks = append(checks, schemaCheck{
+					prefix + name + "Field",
+					m.Path + "." + entrySegment + "." + fields[name],
+					kindString,
+				})
+			}
+		}
+	}
+
+	return checks
+}
+
+// validateSchema checks the paths of the CRD config against the OpenAPI
+// schema of the CRD. Paths into fetched objects are not checked, their schema
+// is not known.
+func (c *CRDConfig) validateSchema(openAPISchema map[string]any) []schemaMismatch {
+	fetched := make(map[string]bool, len(c.Fetches))
+	for i := range c.Fetches {
+		fetched[c.Fetches[i].As] = true
+	}
+
+	var mismatches []schemaMismatch
+
+	for _, check := range c.schemaChecks() {
+		// JSONPath expressions are not resolved against the schema
+		if check.path == "" || isJSONPath(check.path) {
+			continue
+		}
+
+		parts := strings.Split(check.path, ".")
+		if fetched[parts[0]] {
+			continue
+		}
+
+		node, err := resolveSchemaPath(openAPISchema, parts)
+		if err == nil && node != nil && !schemaCompatible(node, check.kind) {
+			err = fmt.Errorf("field is %s, expected %s", schemaType(node), check.kind)
+		}
+
+		if err != nil {
+			mismatches = append(mismatches, schemaMismatch{field: check.field, path: check.path, err: err})
+		}
+	}
+
+	return mismatches
+}
+
+// resolveSchemaPath returns the schema of the field at path, or nil when the
+// schema does not describe it (preserved unknown fields)
+func resolveSchemaPath(root map[string]any, parts []string) (map[string]any, error) {
+	node := root
+
+	for i, part := range parts {
+		if i == 0 && part == "metadata" {
+			node = metadataSchema
+			continue
+		}
+
+		if properties, ok := node["properties"].(map[string]any); ok && part != entrySegment {
+			if child, ok := properties[part].(map[string]any); ok {
+				node = child
+				continue
+			}
+		}
+
+		if items, ok := node["items"].(map[string]any); ok && part == entrySegment {
+			node = items
+			continue
+		}
+
+		if entries, ok := node["additionalProperties"].(map[string]any); ok {
+			node = entries
+			continue
+		}
+
+		if preserve, _ := node["x-kubernetes-preserve-unknown-fields"].(bool); preserve {
+			return nil, nil
+		}
+
+		if additional, _ := node["additionalProperties"].(bool); additional {
+			return nil, nil
+		}
+
+		if schemaType(node) == "array" {
+			return nil, fmt.Errorf("%s is an array, paths cannot index into arrays",
+				strings.Join(parts[:i], "."))
+		}
+
+		return nil, fmt.Errorf("field %q not found in schema", strings.Join(parts[:i+1], "."))
+	}
+
+	return node, nil
+}
+
+// schemaType returns the type of a schema node
+func schemaType(node map[string]any) string {
+	if intOrString, _ := node["x-kubernetes-int-or-string"].(bool); intOrString {
+		return "int-or-string"
+	}
+
+	t, _ := node["type"].(string)
+
+	return t
+}
+
+// schemaCompatible returns whether the collector can read a value of kind
+// from a field of the node schema. Untyped fields are compatible.
+func schemaCompatible(node map[string]any, kind fieldKind) bool {
+	t := schemaType(node)
+	if t == "" {
+		return true
+	}
+
+	switch kind {
+	case kindString:
+		return t == "string" || t == "int-or-string"
+	case kindNumber:
+		return t == "integer" || t == "number" || t == "boolean" || t == "string" || t == "int-or-string"
+	case kindMap:
+		return t == "object"
+	case kindList:
+		return t == "array"
+	default:
+		return true
+	}
+}
+
+var (
+	// errNotCustomResource is returned by fetchSchema for resources not defined by a CRD
+	errNotCustomResource = errors.New("resource is not defined by a CRD")
+	// errNoSchema is returned by fetchSchema when the CRD version has no schema
+	errNoSchema = errors.New("CRD version has no schema")
+	// errUnknownVersion is returned by fetchSchema when the CRD does not define the version
+	errUnknownVersion = errors.New("version is not defined by the CRD")
+)
+
+// fetchSchema returns the OpenAPI schema of the CRD version of gvr
+func fetchSchema(ctx context.Context, client dynamic.Interface, gvr GVRConfig) (map[string]any, error) {
+	if gvr.Group == "" {
+		return nil, errNotCustomResource
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, schemaTimeout)
+	defer cancel()
+
+	crd, err := client.Resource(crdGVR).Get(ctx, gvr.Resource+"."+gvr.Group, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, errNotCustomResource
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return crdVersionSchema(crd.Object, gvr.Version)
+}
+
+// crdVersionSchema returns the OpenAPI schema of a version of a CRD object
+func crdVersionSchema(crd map[string]any, name string) (map[string]any, error) {
+	versions, _, _ := unstructured.NestedSlice(crd, "spec", "versions")
+	for _, item := range versions {
+		version, ok := item.(map[string]any)
+		if !ok || version["name"] != name {
+			continue
+		}
+
+		openAPISchema, found, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		if !found {
+			return nil, errNoSchema
+		}
+
+		return openAPISchema, nil
+	}
+
+	return nil, errUnknownVersion
+}
+
+// validateConfig fetches the CRD schema and checks the configured paths
+// against it, logging each mismatch. The outcome is exported by the
+// config_valid metric; nothing is exported when the schema is not available.
+func (c *ConfigurableCollector) validateConfig(ctx context.Context) {
+	openAPISchema, err := fetchSchema(ctx, c.client, c.crdConfig.GVR)
+	if errors.Is(err, errNotCustomResource) || errors.Is(err, errNoSchema) || apierrors.IsForbidden(err) {
+		c.logger.WithError(err).Debug("CRD schema not available, skipping config validation")
+		return
+	}
+
+	var mismatches []schemaMismatch
+
+	switch {
+	case errors.Is(err, errUnknownVersion):
+		mismatches = []schemaMismatch{{field: "gvr version", path: c.crdConfig.GVR.Version, err: err}}
+	case err != nil:
+		if ctx.Err() == nil {
+			c.logger.WithError(err).Warn("Failed to fetch CRD schema, skipping config validation")
+		}
+
+		return
+	default:
+		mismatches = c.crdConfig.validateSchema(openAPISchema)
+	}
+
+	for _, mismatch := range mismatches {
+		c.logger.WithFields(log.Fields{
+			"field": mismatch.field,
+			"path":  mismatch.path,
+		}).WithError(mismatch.err).Warn("Configured path does not match the CRD schema")
+	}
+
+	if len(mismatches) == 0 {
+		c.logger.Debug("Configured paths match the CRD schema")
+	}
+
+	c.mu.Lock()
+	c.schemaValidated = true
+	c.configValid = len(mismatches) == 0
+	c.mu.Unlock()
+}
+
+// collectConfigValid emits the outcome of the schema validation.
+// Must be called with c.mu held.
+func (c *ConfigurableCollector) collectConfigValid(ch chan<- prometheus.Metric) {
+	if !c.schemaValidated {
+		return
+	}
+
+	value := 0.0
+	if c.configValid {
+		value = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.configValidDesc, prometheus.GaugeValue, value)
+}
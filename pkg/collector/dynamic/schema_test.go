@@ -0,0 +1,240 @@
+//nolint:testpackage // Tests need access to private functions
+package dynamic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+// testSchema is the OpenAPI schema of a CRD with a few typical fields
+var testSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"metadata": map[string]any{"type": "object"},
+		"spec": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"replicas": map[string]any{"type": "integer"},
+				"version":  map[string]any{"type": "string"},
+				"extra": map[string]any{
+					"type":                                 "object",
+					"x-kubernetes-preserve-unknown-fields": true,
+				},
+			},
+		},
+		"status": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"phase":         map[string]any{"type": "string"},
+				"readyReplicas": map[string]any{"type": "integer"},
+				"components": map[string]any{
+					"type": "object",
+					"additionalProperties": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"phase": map[string]any{"type": "string"},
+						},
+					},
+				},
+				"conditions": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"type":   map[string]any{"type": "string"},
+							"status": map[string]any{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestCRDConfig_ValidateSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   CRDConfig
+		expected []string // paths of the expected mismatches
+	}{
+		{
+			name: "valid paths",
+			config: CRDConfig{
+				CommonLabels: map[string]string{
+					"name": "metadata.name",
+					"app":  "metadata.labels.app",
+				},
+				Fetches: []FetchConfig{{As: "scale", Subresource: "scale"}},
+				Metrics: []MetricConfig{
+					{Type: "info", Name: "info", Labels: map[string]string{"version": "spec.version"}},
+					{Type: "count", Name: "phase", Path: "status.phase"},
+					{Type: "gauge", Name: "replicas", Path: "spec.replicas"},
+					{Type: "gauge", Name: "scale", Path: "scale.status.replicas"},
+					{Type: "gauge", Name: "extra", Path: "spec.extra.anything"},
+					{Type: "ratio", Name: "ready", Path: "status.readyReplicas", DenominatorPath: "spec.replicas"},
+					{Type: "map_state", Name: "component", Path: "status.components", ValuePath: "phase"},
+					{Type: "conditions", Name: "condition", Path: "status.conditions"},
+				},
+			},
+		},
+		{
+			name: "typo",
+			config: CRDConfig{
+				Metrics: []MetricConfig{
+					{Type: "gauge", Name: "replicas", Path: "spec.replica"},
+				},
+			},
+			expected: []string{"spec.replica"},
+		},
+		{
+			name: "incompatible types",
+			config: CRDConfig{
+				CommonLabels: map[string]string{"replicas": "spec.replicas"},
+				Metrics: []MetricConfig{
+					{Type: "gauge", Name: "spec", Path: "spec"},
+					{Type: "map_state", Name: "replicas", Path: "spec.replicas"},
+				},
+			},
+			expected: []string{"spec.replicas", "spec", "spec.replicas"},
+		},
+		{
+			name: "array indexing",
+			config: CRDConfig{
+				Metrics: []MetricConfig{
+					{Type: "count", Name: "condition", Path: "status.conditions.type"},
+				},
+			},
+			expected: []string{"status.conditions.type"},
+		},
+		{
+			name: "condition fields",
+			config: CRDConfig{
+				Metrics: []MetricConfig{
+					{
+						Type:      "conditions",
+						Name:      "condition",
+						Path:      "status.conditions",
+						Condition: &ConditionConfig{ReasonField: "reason"},
+					},
+				},
+			},
+			expected: []string{"status.conditions.*.reason"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches := tt.config.validateSchema(testSchema)
+			if len(mismatches) != len(tt.expected) {
+				t.Fatalf("Expected %d mismatches, got %+v", len(tt.expected), mismatches)
+			}
+
+			for i, mismatch := range mismatches {
+				if mismatch.path != tt.expected[i] {
+					t.Errorf("Expected mismatch on %q, got %q (%v)", tt.expected[i], mismatch.path, mismatch.err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigurableCollector_ValidateConfig(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]any{
+			"name": "apps.apps.example.com",
+		},
+		"spec": map[string]any{
+			"versions": []any{
+				map[string]any{
+					"name":   "v1",
+					"schema": map[string]any{"openAPIV3Schema": testSchema},
+				},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		gvr      GVRConfig
+		path     string
+		expected []float64
+	}{
+		{
+			name:     "valid",
+			gvr:      GVRConfig{Group: "apps.example.com", Version: "v1", Resource: "apps"},
+			path:     "spec.replicas",
+			expected: []float64{1},
+		},
+		{
+			name:     "typo",
+			gvr:      GVRConfig{Group: "apps.example.com", Version: "v1", Resource: "apps"},
+			path:     "spec.replica",
+			expected: []float64{0},
+		},
+		{
+			name:     "unknown version",
+			gvr:      GVRConfig{Group: "apps.example.com", Version: "v2", Resource: "apps"},
+			path:     "spec.replicas",
+			expected: []float64{0},
+		},
+		{
+			name: "not a custom resource",
+			gvr:  GVRConfig{Group: "apps", Version: "v1", Resource: "deployments"},
+			path: "spec.replicas",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), crd.DeepCopy())
+
+			c := NewConfigurableCollector(
+				&CRDConfig{
+					Name: "app",
+					GVR:  tt.gvr,
+					Metrics: []MetricConfig{
+						{Type: "gauge", Name: "replicas", Path: tt.path},
+					},
+				},
+				"test",
+				log.NewEntry(log.StandardLogger()),
+				WithDynamicClient(client),
+			)
+
+			validate := c.GetConfigValidator()
+			if validate == nil {
+				t.Fatal("Expected a config validator with a dynamic client")
+			}
+
+			validate(context.Background())
+
+			ch := make(chan prometheus.Metric, 10)
+			c.collect(ch)
+			close(ch)
+
+			var values []float64
+
+			for metric := range ch {
+				var m dto.Metric
+				if err := metric.Write(&m); err != nil {
+					t.Fatalf("Failed to write metric: %v", err)
+				}
+
+				values = append(values, m.GetGauge().GetValue())
+			}
+
+			if len(values) != len(tt.expected) || (len(values) > 0 && values[0] != tt.expected[0]) {
+				t.Errorf("Expected config_valid %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
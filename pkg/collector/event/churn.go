@@ -0,0 +1,246 @@
+package event
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Churn event types
+const (
+	churnCreated = "created"
+	churnDeleted = "deleted"
+)
+
+// churnBuckets are the upper bounds of the histograms of the objects created
+// or deleted per namespace in a window
+var churnBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}
+
+// parseChurnResource parses a churn resource in the kubectl resource.version.group
+// form; core resources may omit the version (e.g. pods)
+func parseChurnResource(arg string) (schema.GroupVersionResource, error) {
+	gvr, gr := schema.ParseResourceArg(arg)
+	if gvr != nil {
+		return *gvr, nil
+	}
+
+	if gr.Resource == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid churn resource %q", arg)
+	}
+
+	if gr.Group != "" {
+		return schema.GroupVersionResource{}, fmt.Errorf(
+			"churn resource %q needs a version (e.g. jobs.v1.batch)", arg)
+	}
+
+	return schema.GroupVersionResource{Version: "v1", Resource: gr.Resource}, nil
+}
+
+// churnKey identifies the creations or deletions of a resource in a namespace
+type churnKey struct {
+	namespace string
+	resource  string
+	eventType string
+}
+
+// churnSeries identifies a churn histogram
+type churnSeries struct {
+	resource  string
+	eventType string
+}
+
+// churnHistogram is a cumulative histogram with churnBuckets bounds
+type churnHistogram struct {
+	buckets map[float64]uint64
+	count   uint64
+	sum     float64
+}
+
+// observe adds a value to the histogram
+func (h *churnHistogram) observe(value float64) {
+	for _, bound := range churnBuckets {
+		if value <= bound {
+			h.buckets[bound]++
+		}
+	}
+
+	h.count++
+	h.sum += value
+}
+
+// churnTracker counts the objects created and deleted per namespace in fixed
+// windows. When a window ends, the count of each namespace is observed by the
+// histogram of its resource and event type, and kept for the top namespaces.
+type churnTracker struct {
+	window      time.Duration
+	windowStart time.Time
+	current     map[churnKey]int
+	last        map[churnKey]int
+	histograms  map[churnSeries]*churnHistogram
+}
+
+func newChurnTracker(window time.Duration, now time.Time) *churnTracker {
+	return &churnTracker{
+		window:      window,
+		windowStart: now,
+		current:     make(map[churnKey]int),
+		last:        make(map[churnKey]int),
+		histograms:  make(map[churnSeries]*churnHistogram),
+	}
+}
+
+// add counts an object creation or deletion
+func (t *churnTracker) add(key churnKey, now time.Time) {
+	t.advance(now)
+	t.current[key]++
+}
+
+// advance closes the current window when it is over. Namespaces without
+// creations or deletions in a window are not observed.
+func (t *churnTracker) advance(now time.Time) {
+	elapsed := now.Sub(t.windowStart)
+	if elapsed < t.window {
+		return
+	}
+
+	for key, count := range t.current {
+		series := churnSeries{resource: key.resource, eventType: key.eventType}
+
+		histogram, ok := t.histograms[series]
+		if !ok {
+			histogram = &churnHistogram{buckets: make(map[float64]uint64, len(churnBuckets))}
+			t.histograms[series] = histogram
+		}
+
+		histogram.observe(float64(count))
+	}
+
+	t.last = t.current
+
+	// The last complete window is empty when several windows have elapsed
+	if elapsed >= 2*t.window {
+		t.last = make(map[churnKey]int)
+	}
+
+	t.current = make(map[churnKey]int)
+	t.windowStart = t.windowStart.Add(elapsed.Truncate(t.window))
+}
+
+// top returns the k namespaces with the most creations or deletions of each
+// resource and event type in the last complete window
+func (t *churnTracker) top(k int) map[churnKey]int {
+	bySeries := make(map[churnSeries][]churnKey)
+	for key := range t.last {
+		series := churnSeries{resource: key.resource, eventType: key.eventType}
+		bySeries[series] = append(bySeries[series], key)
+	}
+
+	top := make(map[churnKey]int)
+
+	for _, keys := range bySeries {
+		slices.SortFunc(keys, func(a, b churnKey) int {
+			if c := cmp.Compare(t.last[b], t.last[a]); c != 0 {
+				return c
+			}
+
+			return cmp.Compare(a.namespace, b.namespace)
+		})
+
+		for _, key := range keys[:min(k, len(keys))] {
+			top[key] = t.last[key]
+		}
+	}
+
+	return top
+}
+
+// churnHandler returns the handler counting the creations and deletions of a
+// resource. Objects created before the collector started are listed by the
+// initial sync, they are not counted as created.
+func (c *Collector) churnHandler(resource string, since time.Time) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			object, ok := obj.(metav1.Object)
+			if !ok || object.GetCreationTimestamp().Time.Before(since) {
+				return
+			}
+
+			c.recordChurn(churnKey{namespace: object.GetNamespace(), resource: resource, eventType: churnCreated})
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			object, ok := obj.(metav1.Object)
+			if !ok {
+				c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+				return
+			}
+
+			c.recordChurn(churnKey{namespace: object.GetNamespace(), resource: resource, eventType: churnDeleted})
+		},
+	}
+}
+
+// recordChurn counts an object creation or deletion
+func (c *Collector) recordChurn(key churnKey) {
+	c.mu.Lock()
+	c.churn.add(key, time.Now())
+	c.mu.Unlock()
+}
+
+// collectChurn emits the churn histograms and the churn of the top namespaces
+// of the last complete window.
+// Must be called with c.mu held.
+func (c *Collector) collectChurn(ch chan<- prometheus.Metric, now time.Time) {
+	c.churn.advance(now)
+
+	for series, histogram := range c.churn.histograms {
+		ch <- prometheus.MustNewConstHistogram(
+			c.eventObjectChurn,
+			histogram.count,
+			histogram.sum,
+			histogram.buckets,
+			series.resource,
+			series.eventType,
+		)
+	}
+
+	for key, count := range c.churn.top(c.config.ChurnTopK) {
+		ch <- prometheus.MustNewConstMetric(
+			c.eventObjectChurnTop,
+			prometheus.GaugeValue,
+			float64(count),
+			key.namespace,
+			key.resource,
+			key.eventType,
+		)
+	}
+}
+
+// trimObjectMetadata reduces memory by keeping only the metadata needed to
+// attribute churn
+func trimObjectMetadata(obj any) (any, error) {
+	object, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return obj, nil
+	}
+
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: object.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         object.Namespace,
+			Name:              object.Name,
+			UID:               object.UID,
+			ResourceVersion:   object.ResourceVersion,
+			CreationTimestamp: object.CreationTimestamp,
+		},
+	}, nil
+}
@@ -0,0 +1,81 @@
+//nolint:testpackage // Tests need access to the private churnTracker
+package event
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseChurnResource(t *testing.T) {
+	tests := map[string]schema.GroupVersionResource{
+		"pods":                {Version: "v1", Resource: "pods"},
+		"jobs.v1.batch":       {Group: "batch", Version: "v1", Resource: "jobs"},
+		"deployments.v1.apps": {Group: "apps", Version: "v1", Resource: "deployments"},
+	}
+
+	for arg, expected := range tests {
+		gvr, err := parseChurnResource(arg)
+		if err != nil || gvr != expected {
+			t.Errorf("parseChurnResource(%q) = %v, %v, expected %v", arg, gvr, err, expected)
+		}
+	}
+
+	for _, arg := range []string{"", "jobs.batch"} {
+		if _, err := parseChurnResource(arg); err == nil {
+			t.Errorf("parseChurnResource(%q): expected error", arg)
+		}
+	}
+}
+
+func TestChurnTracker(t *testing.T) {
+	start := time.Unix(0, 0)
+	tracker := newChurnTracker(time.Minute, start)
+
+	created := func(namespace string) churnKey {
+		return churnKey{namespace: namespace, resource: "pods", eventType: churnCreated}
+	}
+
+	for range 30 {
+		tracker.add(created("ci"), start.Add(10*time.Second))
+	}
+
+	tracker.add(created("web"), start.Add(20*time.Second))
+	tracker.add(created("api"), start.Add(30*time.Second))
+	tracker.add(created("api"), start.Add(30*time.Second))
+
+	// The window is not over yet
+	if len(tracker.top(2)) != 0 {
+		t.Fatal("Expected no complete window")
+	}
+
+	tracker.advance(start.Add(90 * time.Second))
+
+	histogram := tracker.histograms[churnSeries{resource: "pods", eventType: churnCreated}]
+	if histogram == nil || histogram.count != 3 || histogram.sum != 33 {
+		t.Fatalf("Expected 3 namespaces observed with 33 creations, got %+v", histogram)
+	}
+
+	if histogram.buckets[1] != 1 || histogram.buckets[5] != 2 || histogram.buckets[50] != 3 {
+		t.Errorf("Unexpected cumulative buckets %v", histogram.buckets)
+	}
+
+	top := tracker.top(2)
+	if len(top) != 2 || top[created("ci")] != 30 || top[created("api")] != 2 {
+		t.Errorf("Expected ci and api at the top, got %v", top)
+	}
+
+	// The window started at 1m gets a creation, then stays idle until 3m30s:
+	// the last complete window (2m-3m) is empty
+	tracker.add(created("web"), start.Add(100*time.Second))
+	tracker.advance(start.Add(210 * time.Second))
+
+	if len(tracker.top(2)) != 0 {
+		t.Errorf("Expected the last complete window to be empty, got %v", tracker.top(2))
+	}
+
+	if histogram.count != 4 {
+		t.Errorf("Expected the window with a creation to be observed, got %d observations", histogram.count)
+	}
+}
@@ -0,0 +1,59 @@
+package event
+
+import "time"
+
+// Config contains configuration for the Event collector
+type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces"              env:"NAMESPACES"                envSeparator:","`
+	// IncludeSystemNamespaces watches events in system namespaces (kube-system, sealos-system, ...)
+	// when Namespaces is empty; they are excluded by default
+	IncludeSystemNamespaces bool `yaml:"includeSystemNamespaces" env:"INCLUDE_SYSTEM_NAMESPACES"`
+	// Reasons narrows the watch to specific Warning reasons (empty = all Warning events)
+	Reasons []string `yaml:"reasons"                 env:"REASONS"                   envSeparator:","`
+	// TopK bounds the number of namespace/kind/reason series tracked; occurrences
+	// of the lower volume series are reported in an aggregated other bucket
+	TopK int `yaml:"topK"                    env:"TOP_K"`
+	// StormThreshold is the rate of Warning event occurrences per second above
+	// which the events of a namespace are aggregated into a single storm series
+	// (0 = disabled)
+	StormThreshold float64 `yaml:"stormThreshold"          env:"STORM_THRESHOLD"`
+	// StormCooldown is how long the rate of a namespace must stay below the
+	// threshold before its storm ends
+	StormCooldown time.Duration `yaml:"stormCooldown"           env:"STORM_COOLDOWN"`
+	// ChurnResources are the resources whose creations and deletions are
+	// counted per namespace, as resource.version.group (e.g. pods,
+	// jobs.v1.batch; empty = disabled). Only their metadata is watched.
+	ChurnResources []string `yaml:"churnResources"          env:"CHURN_RESOURCES"           envSeparator:","`
+	// ChurnWindow is the period over which the creations and deletions of
+	// each namespace are counted
+	ChurnWindow time.Duration `yaml:"churnWindow"             env:"CHURN_WINDOW"`
+	// ChurnTopK is the number of namespaces with the highest churn of the last
+	// window reported per resource and event type
+	ChurnTopK int `yaml:"churnTopK"               env:"CHURN_TOP_K"`
+	// FingerprintTopK is the number of message fingerprints reported, those
+	// seen in the most namespaces (0 = disabled). Messages are normalized
+	// (numbers and object names removed) before being fingerprinted.
+	FingerprintTopK int `yaml:"fingerprintTopK"         env:"FINGERPRINT_TOP_K"`
+	// FingerprintWindow is how long a fingerprint is reported after it was
+	// last seen
+	FingerprintWindow time.Duration `yaml:"fingerprintWindow"       env:"FINGERPRINT_WINDOW"`
+}
+
+// NewDefaultConfig returns the default configuration for Event collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces:     []string{},
+		Reasons:        []string{},
+		TopK:           500,
+		StormThreshold: 50,
+		StormCooldown:  5 * time.Minute,
+		ChurnResources: []string{},
+		ChurnWindow:    time.Minute,
+		ChurnTopK:      10,
+
+		FingerprintTopK:   10,
+		FingerprintWindow: time.Hour,
+	}
+}
@@ -0,0 +1,298 @@
+package event
+
+import (
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/sampling"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/cache"
+)
+
+// aggregateKey identifies a namespace/kind/reason series
+type aggregateKey struct {
+	namespace string
+	kind      string
+	reason    string
+}
+
+// Collector collects Warning event metrics
+type Collector struct {
+	*base.BaseCollector
+
+	client    kubernetes.Interface
+	metadata  metadata.Interface // only set with ChurnResources
+	config    *Config
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	mu     base.RWMutex
+	sketch *spaceSaving
+	storms *stormDetector
+	churn  *churnTracker
+
+	fingerprints *fingerprintTracker
+
+	// Metrics
+	eventWarnings     *prometheus.Desc
+	eventWarningsRest *prometheus.Desc
+	eventsTracked     *prometheus.Desc
+	eventStormActive  *prometheus.Desc
+
+	eventObjectChurn    *prometheus.Desc
+	eventObjectChurnTop *prometheus.Desc
+
+	eventFingerprintNamespaces *prometheus.Desc
+	eventFingerprintCount      *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.eventWarnings = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "warnings_total"),
+		"Number of Warning event occurrences since the collector started, "+
+			"for the top namespace/kind/reason series (lower bound)",
+		[]string{"namespace", "kind", "reason"},
+		nil,
+	)
+	c.eventWarningsRest = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "warnings_other_total"),
+		"Number of Warning event occurrences since the collector started not attributed to a top series",
+		nil,
+		nil,
+	)
+	c.eventsTracked = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "tracked"),
+		"Number of namespace/kind/reason series tracked by the top-K sketch",
+		nil,
+		nil,
+	)
+	c.eventStormActive = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "storm_active"),
+		"Whether the Warning events of a namespace exceed the storm threshold and are "+
+			"aggregated into a single series (1=storm)",
+		[]string{"namespace"},
+		nil,
+	)
+
+	c.eventObjectChurn = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "object_churn"),
+		"Objects created or deleted per namespace in each churn window, "+
+			"for the namespaces with at least one creation or deletion",
+		[]string{"resource", "type"},
+		nil,
+	)
+	c.eventObjectChurnTop = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "object_churn_top"),
+		"Objects created or deleted in the last complete churn window, for the namespaces with the highest churn",
+		[]string{"namespace", "resource", "type"},
+		nil,
+	)
+
+	c.eventFingerprintNamespaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "message_fingerprint_namespaces"),
+		"Number of namespaces with Warning events of the normalized message, "+
+			"for the fingerprints seen in the most namespaces",
+		[]string{"fingerprint", "reason", "message"},
+		nil,
+	)
+	c.eventFingerprintCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "event", "message_fingerprint_count"),
+		"Number of Warning event occurrences of the normalized message since it was first seen, "+
+			"for the fingerprints seen in the most namespaces",
+		[]string{"fingerprint", "reason", "message"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.eventWarnings)
+	c.MustRegisterDesc(c.eventWarningsRest)
+	c.MustRegisterDesc(c.eventsTracked)
+	c.MustRegisterDesc(c.eventStormActive)
+
+	if len(c.config.ChurnResources) > 0 {
+		c.MustRegisterDesc(c.eventObjectChurn)
+		c.MustRegisterDesc(c.eventObjectChurnTop)
+	}
+
+	if c.config.FingerprintTopK > 0 {
+		c.MustRegisterDesc(c.eventFingerprintNamespaces)
+		c.MustRegisterDesc(c.eventFingerprintCount)
+	}
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fieldSelectors returns the field selectors used to narrow the watch.
+// Only Warning events outside the excluded namespaces are ever requested from the
+// API server; when reasons are configured, one selector per reason is returned
+// since field selectors cannot OR.
+func fieldSelectors(reasons, excludedNamespaces []string) []string {
+	warning := fields.OneTermEqualSelector("type", corev1.EventTypeWarning)
+	if exclusion := util.NamespaceExclusionSelector(excludedNamespaces); exclusion != nil {
+		warning = fields.AndSelectors(warning, exclusion)
+	}
+
+	if len(reasons) == 0 {
+		return []string{warning.String()}
+	}
+
+	selectors := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		selectors = append(selectors, fields.AndSelectors(
+			warning,
+			fields.OneTermEqualSelector("reason", reason),
+		).String())
+	}
+
+	return selectors
+}
+
+// handleEvent counts the occurrences of a new event. The events of the
+// initial list carry the occurrences of their whole lifetime, they are kept
+// out of the storm detector.
+func (c *Collector) handleEvent(obj any, isInInitialList bool) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Event")
+		return
+	}
+
+	sampling.Sample(collectorName, event)
+
+	c.record(event, int64(eventCount(event)), !isInInitialList)
+}
+
+// handleEventUpdate counts the occurrences added to an existing event
+func (c *Collector) handleEventUpdate(oldObj, newObj any) {
+	event, ok := newObj.(*corev1.Event)
+	if !ok {
+		c.logger.WithField("object", newObj).Error("Failed to cast object to Event")
+		return
+	}
+
+	sampling.Sample(collectorName, event)
+
+	inc := int64(eventCount(event))
+	if old, ok := oldObj.(*corev1.Event); ok {
+		inc -= int64(eventCount(old))
+	}
+
+	// Resyncs and unrelated updates do not add occurrences
+	if inc <= 0 {
+		return
+	}
+
+	c.record(event, inc, true)
+}
+
+// record adds occurrences of an event to its namespace/kind/reason series, or
+// to the storm series of its namespace while the namespace is in storm.
+// Occurrences feed the storm detector only with detect.
+func (c *Collector) record(event *corev1.Event, inc int64, detect bool) {
+	key := aggregateKey{
+		namespace: event.Namespace,
+		kind:      event.InvolvedObject.Kind,
+		reason:    event.Reason,
+	}
+
+	now := time.Now()
+
+	var storm, started bool
+
+	c.mu.Lock()
+	if detect {
+		storm, started = c.storms.observe(event.Namespace, inc, now)
+	}
+
+	if storm {
+		key = stormKey(event.Namespace)
+	}
+
+	c.sketch.add(key, inc)
+
+	// Fingerprints correlate messages across namespaces, storms included
+	if c.config.FingerprintTopK > 0 {
+		c.fingerprints.add(event.Reason, event.Message, event.Namespace, inc, now)
+	}
+	c.mu.Unlock()
+
+	if started {
+		c.logger.WithField("namespace", event.Namespace).
+			Warn("Event storm detected, aggregating the namespace events into a single series")
+	}
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	// Collecting drops the rates of idle namespaces
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	other := c.sketch.each(func(key aggregateKey, count int64) {
+		ch <- prometheus.MustNewConstMetric(
+			c.eventWarnings,
+			prometheus.CounterValue,
+			float64(count),
+			key.namespace,
+			key.kind,
+			key.reason,
+		)
+	})
+
+	ch <- prometheus.MustNewConstMetric(
+		c.eventWarningsRest,
+		prometheus.CounterValue,
+		float64(other),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.eventsTracked,
+		prometheus.GaugeValue,
+		float64(c.sketch.len()),
+	)
+
+	now := time.Now()
+
+	c.collectStorms(ch, now)
+
+	if len(c.config.ChurnResources) > 0 {
+		c.collectChurn(ch, now)
+	}
+
+	if c.config.FingerprintTopK > 0 {
+		c.collectFingerprints(ch, now)
+	}
+}
+
+// eventCount returns the number of occurrences of an event
+func eventCount(event *corev1.Event) int32 {
+	if event.Series != nil && event.Series.Count > 0 {
+		return event.Series.Count
+	}
+
+	if event.Count > 0 {
+		return event.Count
+	}
+
+	return 1
+}
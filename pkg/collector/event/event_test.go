@@ -0,0 +1,110 @@
+//nolint:testpackage // Tests need access to private functions
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/config"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// fakeClientProvider returns a fake clientset
+type fakeClientProvider struct {
+	client kubernetes.Interface
+}
+
+func (p *fakeClientProvider) GetClient() (kubernetes.Interface, error) {
+	return p.client, nil
+}
+
+func (p *fakeClientProvider) GetRestConfig() (*rest.Config, error) {
+	return &rest.Config{}, nil
+}
+
+func warningEvent(name string, count int32) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "ns-user1", Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+		Reason:         "BackOff",
+		Type:           corev1.EventTypeWarning,
+		Count:          count,
+	}
+}
+
+// stormActive returns whether the namespace of the test events is in storm
+func stormActive(c *Collector) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, namespace := range c.storms.active(time.Now()) {
+		if namespace == "ns-user1" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestInitialListDoesNotStartStorm(t *testing.T) {
+	// Far above the storm threshold of 50/s over a minute
+	client := fake.NewClientset(warningEvent("web.1", 10000))
+
+	c, err := NewCollector(&collector.FactoryContext{
+		ClientProvider:    &fakeClientProvider{client: client},
+		ConfigLoader:      config.NewEnvConfigLoader(),
+		MetricsNamespace:  "sealos",
+		EventQueueWorkers: 1,
+		Logger:            log.NewEntry(log.New()),
+	})
+	if err != nil {
+		t.Fatalf("NewCollector() error = %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = c.Stop() })
+
+	events := c.(*Collector)
+
+	// Start returns once the initial list has been handled
+	if stormActive(events) {
+		t.Fatal("Expected the lifetime count of a listed event not to start a storm")
+	}
+
+	counts := make(map[aggregateKey]int64)
+
+	events.mu.Lock()
+	events.sketch.each(func(key aggregateKey, count int64) { counts[key] = count })
+	events.mu.Unlock()
+
+	if counts[aggregateKey{namespace: "ns-user1", kind: "Pod", reason: "BackOff"}] != 10000 {
+		t.Errorf("Expected the listed occurrences to be counted, got %v", counts)
+	}
+
+	// Occurrences reported by the watch feed the detector
+	_, err = client.CoreV1().Events("ns-user1").Update(
+		context.Background(), warningEvent("web.1", 15000), metav1.UpdateOptions{},
+	)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !stormActive(events) {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a storm from the occurrences added by the watch")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
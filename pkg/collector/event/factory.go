@@ -0,0 +1,290 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "event"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Warning event aggregation"),
+		registry.WithRBAC([]string{""}, []string{"events"}, []string{"list", "watch"}),
+		registry.WithConfigRBAC(requiredRBAC),
+	)
+}
+
+// requiredRBAC returns the permissions to watch the churn resources
+func requiredRBAC(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.event", cfg); err != nil {
+		return nil, err
+	}
+
+	rules := make([]rbacv1.PolicyRule, 0, len(cfg.ChurnResources))
+	for _, resource := range cfg.ChurnResources {
+		gvr, err := parseChurnResource(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{gvr.Group},
+			Resources: []string{gvr.Resource},
+			Verbs:     []string{"list", "watch"},
+		})
+	}
+
+	return rules, nil
+}
+
+// NewCollector creates a new Event collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.event", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load event collector config, using defaults")
+	}
+
+	churnResources := make([]schema.GroupVersionResource, 0, len(cfg.ChurnResources))
+	for _, resource := range cfg.ChurnResources {
+		gvr, err := parseChurnResource(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		churnResources = append(churnResources, gvr)
+	}
+
+	if len(churnResources) > 0 && cfg.ChurnWindow <= 0 {
+		return nil, errors.New("churnWindow must be positive when churn resources are configured")
+	}
+
+	if cfg.FingerprintTopK > 0 && cfg.FingerprintWindow <= 0 {
+		return nil, errors.New("fingerprintWindow must be positive when fingerprints are enabled")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client: client,
+		config: cfg,
+		sketch: newSpaceSaving(cfg.TopK),
+		storms: newStormDetector(cfg.StormThreshold, cfg.StormCooldown),
+		churn:  newChurnTracker(cfg.ChurnWindow, time.Now()),
+		stopCh: make(chan struct{}),
+		logger: factoryCtx.Logger,
+
+		fingerprints: newFingerprintTracker(cfg.FingerprintWindow),
+	}
+
+	// Churn is derived from metadata-only watches of the configured resources
+	if len(churnResources) > 0 {
+		restConfig, err := factoryCtx.GetRestConfig()
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes rest config is required for churn but not available: %w", err)
+		}
+
+		c.metadata, err = metadata.NewForConfig(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metadata client: %w", err)
+		}
+	}
+
+	c.mu.Instrument(c.BaseCollector)
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and state to support restart
+			c.stopCh = make(chan struct{})
+			c.informers = nil
+
+			c.mu.Lock()
+			c.sketch = newSpaceSaving(c.config.TopK)
+			c.storms = newStormDetector(c.config.StormThreshold, c.config.StormCooldown)
+			c.churn = newChurnTracker(c.config.ChurnWindow, time.Now())
+			c.fingerprints = newFingerprintTracker(c.config.FingerprintWindow)
+			c.mu.Unlock()
+
+			// One narrowed watch per namespace and field selector, so Normal
+			// events are never sent by the API server nor cached locally
+			var factories []informers.SharedInformerFactory
+
+			excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+
+			for _, selector := range fieldSelectors(c.config.Reasons, excluded) {
+				for _, factory := range util.NewInformerFactories(
+					c.client,
+					factoryCtx.InformerResyncPeriod,
+					c.config.Namespaces,
+					informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+						options.FieldSelector = selector
+					}),
+				) {
+					informer := factory.Core().V1().Events().Informer()
+
+					// Apply transform to reduce memory usage
+					// Only keep fields needed for aggregation
+					_ = informer.SetTransform(trimEvent(c.config.FingerprintTopK > 0))
+
+					c.InstrumentInformer("events", informer)
+
+					//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+					informer.AddEventHandler(c.InstrumentDetailedHandler(cache.ResourceEventHandlerDetailedFuncs{
+						AddFunc:    c.handleEvent,
+						UpdateFunc: c.handleEventUpdate,
+					}))
+
+					factories = append(factories, factory)
+					c.informers = append(c.informers, informer)
+				}
+			}
+
+			metadataFactories := c.newChurnInformers(churnResources, excluded, factoryCtx.InformerResyncPeriod)
+
+			// Start informers
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			for _, factory := range metadataFactories {
+				factory.Start(c.stopCh)
+			}
+
+			// Wait for cache sync
+			c.logger.WithField("watches", len(c.informers)).
+				Info("Waiting for event informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				return errors.New("failed to sync event informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			c.logger.Info("Event collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
+
+// newChurnInformers creates the metadata informers of the churn resources,
+// excluding the same namespaces as the event watch, and returns their
+// factories to be started
+func (c *Collector) newChurnInformers(
+	resources []schema.GroupVersionResource,
+	excluded []string,
+	resyncPeriod time.Duration,
+) []metadatainformer.SharedInformerFactory {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	var tweak metadatainformer.TweakListOptionsFunc
+	if exclusion := util.NamespaceExclusionSelector(excluded); exclusion != nil {
+		tweak = func(options *metav1.ListOptions) {
+			options.FieldSelector = exclusion.String()
+		}
+	}
+
+	factories := util.NewMetadataInformerFactories(c.metadata, resyncPeriod, c.config.Namespaces, tweak)
+
+	// Objects listed by the initial sync were not created since the start
+	since := time.Now()
+
+	for _, factory := range factories {
+		for _, gvr := range resources {
+			informer := factory.ForResource(gvr).Informer()
+			_ = informer.SetTransform(trimObjectMetadata)
+
+			c.InstrumentInformer(gvr.GroupResource().String(), informer)
+
+			//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+			informer.AddEventHandler(c.InstrumentHandler(c.churnHandler(gvr.GroupResource().String(), since)))
+
+			c.informers = append(c.informers, informer)
+		}
+	}
+
+	return factories
+}
+
+// trimEvent returns the transform reducing memory by keeping only the fields
+// needed for aggregation, and the normalized message with fingerprints
+func trimEvent(fingerprints bool) cache.TransformFunc {
+	return func(obj any) (any, error) {
+		event, ok := obj.(*corev1.Event)
+		if !ok {
+			return obj, nil
+		}
+
+		trimmed := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         event.Namespace,
+				Name:              event.Name,
+				UID:               event.UID,
+				CreationTimestamp: event.CreationTimestamp,
+			},
+			InvolvedObject: corev1.ObjectReference{
+				Kind: event.InvolvedObject.Kind,
+			},
+			Reason:        event.Reason,
+			Type:          event.Type,
+			Count:         event.Count,
+			LastTimestamp: event.LastTimestamp,
+			EventTime:     event.EventTime,
+			Series:        event.Series,
+		}
+
+		if fingerprints {
+			trimmed.Message = normalizeMessage(event)
+		}
+
+		return trimmed, nil
+	}
+}
@@ -0,0 +1,168 @@
+package event
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxFingerprints bounds the number of fingerprints tracked; the least
+// recently seen one is evicted when a new fingerprint comes in
+const maxFingerprints = 1000
+
+var (
+	// hexPattern matches numbers and hex identifiers (pod template hashes,
+	// container IDs), which differ between otherwise identical messages
+	hexPattern = regexp.MustCompile(`\b[0-9a-f]*[0-9][0-9a-f]*\b`)
+	// digitsPattern matches the numbers left inside words (v1, 3Gi)
+	digitsPattern = regexp.MustCompile(`[0-9]+`)
+)
+
+// normalizeMessage returns the message of an event with the namespace and
+// name of the involved object, numbers and hex identifiers replaced, so the
+// same failure reported in different namespaces yields the same message
+func normalizeMessage(event *corev1.Event) string {
+	message := event.Message
+
+	if name := event.InvolvedObject.Name; name != "" {
+		message = strings.ReplaceAll(message, name, "<name>")
+	}
+
+	if event.Namespace != "" {
+		message = strings.ReplaceAll(message, event.Namespace, "<namespace>")
+	}
+
+	message = hexPattern.ReplaceAllString(message, "#")
+	message = digitsPattern.ReplaceAllString(message, "#")
+
+	return strings.Join(strings.Fields(message), " ")
+}
+
+// fingerprintEntry counts the occurrences of a normalized message
+type fingerprintEntry struct {
+	fingerprint string
+	reason      string
+	message     string              // sanitized label value
+	namespaces  map[string]struct{} // namespaces the message was seen in
+	count       int64
+	lastSeen    time.Time
+}
+
+// fingerprintTracker counts the occurrences and namespaces of each
+// fingerprint. Fingerprints not seen for window are forgotten.
+type fingerprintTracker struct {
+	window  time.Duration
+	entries map[string]*fingerprintEntry // key: fingerprint
+}
+
+// newFingerprintTracker creates a tracker forgetting the fingerprints not
+// seen for window
+func newFingerprintTracker(window time.Duration) *fingerprintTracker {
+	return &fingerprintTracker{
+		window:  window,
+		entries: make(map[string]*fingerprintEntry),
+	}
+}
+
+// add counts inc occurrences of a normalized message in namespace
+func (t *fingerprintTracker) add(reason, message, namespace string, inc int64, now time.Time) {
+	if message == "" || inc <= 0 {
+		return
+	}
+
+	// The reason is part of the fingerprint, messages alone are often generic
+	fingerprint := util.ErrorHash(reason + "\x00" + message)
+
+	entry, ok := t.entries[fingerprint]
+	if !ok {
+		if len(t.entries) >= maxFingerprints {
+			t.evictOldest()
+		}
+
+		label, _ := util.SanitizeErrorLabel(message)
+		entry = &fingerprintEntry{
+			fingerprint: fingerprint,
+			reason:      reason,
+			message:     label,
+			namespaces:  make(map[string]struct{}),
+		}
+		t.entries[fingerprint] = entry
+	}
+
+	entry.namespaces[namespace] = struct{}{}
+	entry.count += inc
+	entry.lastSeen = now
+}
+
+// evictOldest removes the least recently seen fingerprint
+func (t *fingerprintTracker) evictOldest() {
+	var oldest *fingerprintEntry
+
+	for _, entry := range t.entries {
+		if oldest == nil || entry.lastSeen.Before(oldest.lastSeen) {
+			oldest = entry
+		}
+	}
+
+	if oldest != nil {
+		delete(t.entries, oldest.fingerprint)
+	}
+}
+
+// top forgets the expired fingerprints and returns the k seen in the most
+// namespaces, then with the most occurrences
+func (t *fingerprintTracker) top(k int, now time.Time) []*fingerprintEntry {
+	entries := make([]*fingerprintEntry, 0, len(t.entries))
+
+	for fingerprint, entry := range t.entries {
+		if now.Sub(entry.lastSeen) >= t.window {
+			delete(t.entries, fingerprint)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b *fingerprintEntry) int {
+		if c := cmp.Compare(len(b.namespaces), len(a.namespaces)); c != 0 {
+			return c
+		}
+
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.fingerprint, b.fingerprint)
+	})
+
+	return entries[:min(k, len(entries))]
+}
+
+// collectFingerprints emits the namespaces and occurrences of the top fingerprints.
+// Must be called with c.mu held.
+func (c *Collector) collectFingerprints(ch chan<- prometheus.Metric, now time.Time) {
+	for _, entry := range c.fingerprints.top(c.config.FingerprintTopK, now) {
+		ch <- prometheus.MustNewConstMetric(
+			c.eventFingerprintNamespaces,
+			prometheus.GaugeValue,
+			float64(len(entry.namespaces)),
+			entry.fingerprint,
+			entry.reason,
+			entry.message,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.eventFingerprintCount,
+			prometheus.GaugeValue,
+			float64(entry.count),
+			entry.fingerprint,
+			entry.reason,
+			entry.message,
+		)
+	}
+}
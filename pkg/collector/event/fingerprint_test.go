@@ -0,0 +1,92 @@
+//nolint:testpackage // Tests need access to the private fingerprintTracker
+package event
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	event := func(namespace, name, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Name: name},
+			Message:        message,
+		}
+	}
+
+	a := normalizeMessage(event("ns-a", "web-7d8f9c-x2k4p",
+		`Failed to pull image "registry.local/ns-a/web:v12": rpc error: code = NotFound, 10.0.3.4:5000`))
+	b := normalizeMessage(event("ns-b", "api-5c6b7a-pq9rs",
+		`Failed to pull image "registry.local/ns-b/web:v7": rpc error: code = NotFound,  10.0.8.1:5000`))
+
+	if a != b {
+		t.Errorf("Expected identical normalized messages, got %q and %q", a, b)
+	}
+
+	expected := `Failed to pull image "registry.local/<namespace>/web:v#": rpc error: code = NotFound, #.#.#.#:#`
+	if a != expected {
+		t.Errorf("Expected %q, got %q", expected, a)
+	}
+
+	scheduling := normalizeMessage(event("ns-a", "web-0", "0/3 nodes are available: 3 Insufficient cpu."))
+	if scheduling != "#/# nodes are available: # Insufficient cpu." {
+		t.Errorf("Unexpected normalized message %q", scheduling)
+	}
+}
+
+func TestFingerprintTracker(t *testing.T) {
+	start := time.Unix(0, 0)
+	tracker := newFingerprintTracker(time.Hour)
+
+	for _, namespace := range []string{"ns-a", "ns-b", "ns-c"} {
+		tracker.add("FailedMount", "MountVolume.SetUp failed: timeout", namespace, 2, start)
+	}
+
+	tracker.add("BackOff", "Back-off restarting failed container", "ns-a", 50, start)
+	tracker.add("BackOff", "Back-off restarting failed container", "ns-a", 1, start.Add(30*time.Minute))
+	// Same message, different reason
+	tracker.add("FailedMount", "Back-off restarting failed container", "ns-a", 1, start)
+
+	top := tracker.top(2, start.Add(time.Minute))
+	if len(top) != 2 {
+		t.Fatalf("Expected 2 fingerprints, got %d", len(top))
+	}
+
+	if top[0].reason != "FailedMount" || len(top[0].namespaces) != 3 || top[0].count != 6 {
+		t.Errorf("Expected the fingerprint seen in the most namespaces first, got %+v", top[0])
+	}
+
+	if top[1].reason != "BackOff" || top[1].count != 51 || len(top[1].fingerprint) != 12 {
+		t.Errorf("Expected the most frequent single-namespace fingerprint second, got %+v", top[1])
+	}
+
+	// Fingerprints not seen for the window are forgotten
+	top = tracker.top(10, start.Add(time.Hour+time.Minute))
+	if len(top) != 1 || top[0].reason != "BackOff" {
+		t.Errorf("Expected only the recently seen fingerprint, got %d", len(top))
+	}
+}
+
+func TestFingerprintTrackerEvictsOldest(t *testing.T) {
+	start := time.Unix(0, 0)
+	tracker := newFingerprintTracker(time.Hour)
+
+	for i := range maxFingerprints + 1 {
+		tracker.add("Failed", "message "+strconv.Itoa(i), "ns-a", 1, start.Add(time.Duration(i)*time.Second))
+	}
+
+	if len(tracker.entries) != maxFingerprints {
+		t.Errorf("Expected %d fingerprints, got %d", maxFingerprints, len(tracker.entries))
+	}
+
+	for _, entry := range tracker.entries {
+		if entry.lastSeen.Equal(start) {
+			t.Error("Expected the least recently seen fingerprint to be evicted")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package event
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// stormWindow is the window over which the event rate of a namespace is measured
+const stormWindow = time.Minute
+
+// stormKey returns the series aggregating the events of a namespace in storm
+func stormKey(namespace string) aggregateKey {
+	return aggregateKey{namespace: namespace, kind: "*", reason: "EventStorm"}
+}
+
+// namespaceRate is the event rate of a namespace over the current window
+type namespaceRate struct {
+	windowStart time.Time
+	count       int64
+	// stormUntil is the end of the storm, zero if the namespace never stormed
+	stormUntil time.Time
+}
+
+// stormDetector flags the namespaces whose Warning event rate exceeds a
+// threshold. A namespace stays in storm until its rate stayed below the
+// threshold for the cooldown.
+type stormDetector struct {
+	threshold float64 // occurrences per second, zero disables detection
+	cooldown  time.Duration
+	rates     map[string]*namespaceRate
+}
+
+// newStormDetector creates a detector flagging namespaces above threshold
+// occurrences per second
+func newStormDetector(threshold float64, cooldown time.Duration) *stormDetector {
+	return &stormDetector{
+		threshold: threshold,
+		cooldown:  cooldown,
+		rates:     make(map[string]*namespaceRate),
+	}
+}
+
+// observe counts inc occurrences in namespace and reports whether the
+// namespace is in storm, and whether the storm started with them
+func (d *stormDetector) observe(namespace string, inc int64, now time.Time) (storm, started bool) {
+	if d.threshold <= 0 {
+		return false, false
+	}
+
+	rate, ok := d.rates[namespace]
+	if !ok {
+		rate = &namespaceRate{windowStart: now}
+		d.rates[namespace] = rate
+	}
+
+	if now.Sub(rate.windowStart) >= stormWindow {
+		rate.windowStart = now
+		rate.count = 0
+	}
+
+	rate.count += inc
+
+	wasStorm := now.Before(rate.stormUntil)
+	if float64(rate.count) > d.threshold*stormWindow.Seconds() {
+		rate.stormUntil = now.Add(d.cooldown)
+	}
+
+	storm = now.Before(rate.stormUntil)
+
+	return storm, storm && !wasStorm
+}
+
+// active returns the namespaces in storm, dropping the rates of the idle
+// namespaces not in storm
+func (d *stormDetector) active(now time.Time) []string {
+	var namespaces []string
+
+	for namespace, rate := range d.rates {
+		if now.Before(rate.stormUntil) {
+			namespaces = append(namespaces, namespace)
+			continue
+		}
+
+		if now.Sub(rate.windowStart) >= stormWindow {
+			delete(d.rates, namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// collectStorms emits the namespaces in storm. Must be called with c.mu held.
+func (c *Collector) collectStorms(ch chan<- prometheus.Metric, now time.Time) {
+	for _, namespace := range c.storms.active(now) {
+		ch <- prometheus.MustNewConstMetric(
+			c.eventStormActive,
+			prometheus.GaugeValue,
+			1,
+			namespace,
+		)
+	}
+}
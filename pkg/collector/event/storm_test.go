@@ -0,0 +1,66 @@
+//nolint:testpackage // Tests need access to the private stormDetector
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStormDetectorThreshold(t *testing.T) {
+	d := newStormDetector(1, 5*time.Minute)
+	now := time.Unix(0, 0)
+
+	if storm, _ := d.observe("ns", 60, now); storm {
+		t.Fatal("namespace at the threshold must not be in storm")
+	}
+
+	storm, started := d.observe("ns", 1, now.Add(time.Second))
+	if !storm || !started {
+		t.Fatalf("expected storm to start, got storm=%v started=%v", storm, started)
+	}
+
+	storm, started = d.observe("ns", 1, now.Add(2*time.Second))
+	if !storm || started {
+		t.Fatalf("expected ongoing storm, got storm=%v started=%v", storm, started)
+	}
+
+	if storm, _ := d.observe("other", 1, now.Add(2*time.Second)); storm {
+		t.Fatal("storms must be tracked per namespace")
+	}
+}
+
+func TestStormDetectorCooldown(t *testing.T) {
+	d := newStormDetector(1, 5*time.Minute)
+	now := time.Unix(0, 0)
+
+	d.observe("ns", 100, now)
+
+	// Below the threshold, the storm lasts until the cooldown elapsed
+	if storm, _ := d.observe("ns", 1, now.Add(4*time.Minute)); !storm {
+		t.Fatal("expected storm during cooldown")
+	}
+
+	if active := d.active(now.Add(4 * time.Minute)); len(active) != 1 || active[0] != "ns" {
+		t.Fatalf("expected ns to be active, got %v", active)
+	}
+
+	if storm, _ := d.observe("ns", 1, now.Add(6*time.Minute)); storm {
+		t.Fatal("expected storm to end after cooldown")
+	}
+
+	if active := d.active(now.Add(8 * time.Minute)); len(active) != 0 {
+		t.Fatalf("expected no active storm, got %v", active)
+	}
+
+	if len(d.rates) != 0 {
+		t.Fatalf("expected idle namespaces to be dropped, got %d", len(d.rates))
+	}
+}
+
+func TestStormDetectorDisabled(t *testing.T) {
+	d := newStormDetector(0, time.Minute)
+
+	if storm, _ := d.observe("ns", 1_000_000, time.Unix(0, 0)); storm {
+		t.Fatal("disabled detector must never flag a storm")
+	}
+}
@@ -0,0 +1,119 @@
+package event
+
+import "container/heap"
+
+// sketchEntry is a series counted by the sketch
+type sketchEntry struct {
+	key   aggregateKey
+	count int64
+	// err is the count the series may have inherited from the evicted one,
+	// so its true count lies in [count-err, count]
+	err   int64
+	index int
+}
+
+// spaceSaving is a Space-Saving heavy hitters sketch: it counts at most
+// capacity series and, when full, replaces the smallest one. Any series whose
+// count exceeds total/capacity is guaranteed to be tracked, so the highest
+// volume series survive event storms regardless of arrival order.
+type spaceSaving struct {
+	capacity int
+	total    int64
+	entries  map[aggregateKey]*sketchEntry
+	heap     entryHeap
+}
+
+// newSpaceSaving creates a sketch tracking at most capacity series
+func newSpaceSaving(capacity int) *spaceSaving {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &spaceSaving{
+		capacity: capacity,
+		entries:  make(map[aggregateKey]*sketchEntry, capacity),
+	}
+}
+
+// add counts inc occurrences of a series
+func (s *spaceSaving) add(key aggregateKey, inc int64) {
+	if inc <= 0 {
+		return
+	}
+
+	s.total += inc
+
+	if entry, ok := s.entries[key]; ok {
+		entry.count += inc
+		heap.Fix(&s.heap, entry.index)
+
+		return
+	}
+
+	if len(s.entries) < s.capacity {
+		entry := &sketchEntry{key: key, count: inc}
+		s.entries[key] = entry
+		heap.Push(&s.heap, entry)
+
+		return
+	}
+
+	// Replace the smallest series, the new one inherits its count as error
+	minEntry := s.heap[0]
+	delete(s.entries, minEntry.key)
+
+	minEntry.key = key
+	minEntry.err = minEntry.count
+	minEntry.count += inc
+	s.entries[key] = minEntry
+	heap.Fix(&s.heap, minEntry.index)
+}
+
+// len returns the number of tracked series
+func (s *spaceSaving) len() int {
+	return len(s.entries)
+}
+
+// each calls fn with the guaranteed count (lower bound) of every tracked series
+// and returns the occurrences not attributed to any of them
+func (s *spaceSaving) each(fn func(key aggregateKey, count int64)) int64 {
+	other := s.total
+
+	for _, entry := range s.entries {
+		guaranteed := entry.count - entry.err
+		other -= guaranteed
+
+		fn(entry.key, guaranteed)
+	}
+
+	return other
+}
+
+// entryHeap is a min-heap of sketch entries by count
+type entryHeap []*sketchEntry
+
+func (h entryHeap) Len() int { return len(h) }
+
+func (h entryHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x any) {
+	entry, _ := x.(*sketchEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *entryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return entry
+}
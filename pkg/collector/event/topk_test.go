@@ -0,0 +1,79 @@
+//nolint:testpackage // Tests need access to the private spaceSaving sketch
+package event
+
+import "testing"
+
+func collectSketch(s *spaceSaving) (map[aggregateKey]int64, int64) {
+	counts := make(map[aggregateKey]int64)
+	other := s.each(func(key aggregateKey, count int64) {
+		counts[key] = count
+	})
+
+	return counts, other
+}
+
+func TestSpaceSavingExactUnderCapacity(t *testing.T) {
+	s := newSpaceSaving(3)
+
+	a := aggregateKey{namespace: "ns", kind: "Pod", reason: "BackOff"}
+	b := aggregateKey{namespace: "ns", kind: "Pod", reason: "FailedMount"}
+
+	s.add(a, 5)
+	s.add(b, 2)
+	s.add(a, 1)
+	s.add(b, 0)
+
+	counts, other := collectSketch(s)
+	if counts[a] != 6 || counts[b] != 2 || other != 0 {
+		t.Fatalf("unexpected counts %v, other %d", counts, other)
+	}
+
+	if s.len() != 2 {
+		t.Fatalf("expected 2 tracked series, got %d", s.len())
+	}
+}
+
+func TestSpaceSavingKeepsHeavyHitters(t *testing.T) {
+	s := newSpaceSaving(8)
+
+	heavy := []aggregateKey{
+		{namespace: "a", kind: "Pod", reason: "BackOff"},
+		{namespace: "b", kind: "Pod", reason: "FailedScheduling"},
+	}
+
+	// A storm of distinct low volume series interleaved with the heavy ones
+	for i := range 1000 {
+		s.add(aggregateKey{namespace: "storm", kind: "Pod", reason: string(rune('A' + i%200))}, 1)
+
+		if i%4 == 0 {
+			s.add(heavy[0], 3)
+			s.add(heavy[1], 2)
+		}
+	}
+
+	counts, other := collectSketch(s)
+
+	for _, key := range heavy {
+		if _, ok := counts[key]; !ok {
+			t.Fatalf("heavy hitter %v evicted, tracked %v", key, counts)
+		}
+	}
+
+	// Guaranteed counts are lower bounds of the true counts
+	if counts[heavy[0]] > 750 || counts[heavy[1]] > 500 {
+		t.Fatalf("guaranteed counts exceed true counts: %v", counts)
+	}
+
+	var attributed int64
+	for _, count := range counts {
+		attributed += count
+	}
+
+	if attributed+other != 2250 {
+		t.Fatalf("expected 2250 occurrences, got %d attributed and %d other", attributed, other)
+	}
+
+	if s.len() != 8 {
+		t.Fatalf("expected 8 tracked series, got %d", s.len())
+	}
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+)
+
+// GoroutineLabel is the pprof label set on the goroutines of a collector.
+// Goroutines started while it is set (informers, poll loops, ...) inherit it.
+const GoroutineLabel = "collector"
+
+// GoroutinesByCollector returns the number of live goroutines per collector,
+// based on the GoroutineLabel pprof label. It takes a goroutine profile, so
+// it should only be called at scrape time.
+func GoroutinesByCollector() map[string]int {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil
+	}
+
+	return parseGoroutineProfile(&buf)
+}
+
+// parseGoroutineProfile counts goroutines per collector label in a goroutine
+// profile written with debug=1, where each stack record starts with
+// "<count> @ <pcs>" and may be followed by "# labels: {"key":"value", ...}"
+func parseGoroutineProfile(profile *bytes.Buffer) map[string]int {
+	counts := make(map[string]int)
+	labelKey := strconv.Quote(GoroutineLabel) + ":"
+
+	var count int
+
+	scanner := bufio.NewScanner(profile)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if n, _, ok := strings.Cut(line, " @ "); ok {
+			count, _ = strconv.Atoi(n)
+			continue
+		}
+
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok {
+			continue
+		}
+
+		_, rest, ok := strings.Cut(labels, labelKey)
+		if !ok {
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			continue
+		}
+
+		if name, err := strconv.Unquote(quoted); err == nil {
+			counts[name] += count
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,34 @@
+package collector_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+func TestGoroutinesByCollector(t *testing.T) {
+	stop := make(chan struct{})
+	started := make(chan struct{})
+
+	pprof.Do(context.Background(), pprof.Labels(collector.GoroutineLabel, "test-collector"), func(context.Context) {
+		for range 3 {
+			go func() {
+				started <- struct{}{}
+				<-stop
+			}()
+		}
+	})
+
+	for range 3 {
+		<-started
+	}
+
+	counts := collector.GoroutinesByCollector()
+	close(stop)
+
+	if counts["test-collector"] != 3 {
+		t.Errorf("Expected 3 goroutines for test-collector, got %d (%v)", counts["test-collector"], counts)
+	}
+}
@@ -0,0 +1,18 @@
+package helm
+
+// Config contains configuration for the Helm collector
+type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces"              env:"NAMESPACES"                envSeparator:","`
+	// IncludeSystemNamespaces watches releases in system namespaces (kube-system, sealos-system, ...)
+	// when Namespaces is empty; they are excluded by default
+	IncludeSystemNamespaces bool `yaml:"includeSystemNamespaces" env:"INCLUDE_SYSTEM_NAMESPACES"`
+}
+
+// NewDefaultConfig returns the default configuration for Helm collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces: []string{},
+	}
+}
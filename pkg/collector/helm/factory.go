@@ -0,0 +1,143 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "helm"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Helm release status, revision and chart/app versions"),
+		registry.WithRBAC([]string{""}, []string{"secrets"}, []string{"list", "watch"}),
+	)
+}
+
+// NewCollector creates a new Helm collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.helm", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load helm collector config, using defaults")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client:    client,
+		config:    cfg,
+		revisions: make(map[string]*release),
+		invalid:   make(map[string]string),
+		stopCh:    make(chan struct{}),
+		logger:    factoryCtx.Logger,
+	}
+
+	c.mu.Instrument(c.BaseCollector)
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and state to support restart
+			c.stopCh = make(chan struct{})
+			c.informers = nil
+
+			c.mu.Lock()
+			c.revisions = make(map[string]*release)
+			c.invalid = make(map[string]string)
+			c.mu.Unlock()
+
+			// Only Helm release Secrets are sent by the API server
+			selector := fields.OneTermEqualSelector("type", string(releaseSecretType))
+
+			excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+			if exclusion := util.NamespaceExclusionSelector(excluded); exclusion != nil {
+				selector = fields.AndSelectors(selector, exclusion)
+			}
+
+			factories := util.NewInformerFactories(
+				c.client,
+				factoryCtx.InformerResyncPeriod,
+				c.config.Namespaces,
+				informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+					options.FieldSelector = selector.String()
+				}),
+			)
+
+			for _, factory := range factories {
+				informer := factory.Core().V1().Secrets().Informer()
+
+				// Apply transform to reduce memory usage
+				// Only keep the release summary, not manifests and values
+				_ = informer.SetTransform(trimSecret)
+
+				c.InstrumentInformer("secrets", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleSecret,
+					UpdateFunc: func(_, newObj any) { c.handleSecret(newObj) },
+					DeleteFunc: c.handleSecretDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			// Start informers
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			// Wait for cache sync
+			c.logger.Info("Waiting for helm informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				return errors.New("failed to sync helm informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			c.logger.Info("Helm collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
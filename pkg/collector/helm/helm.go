@@ -0,0 +1,217 @@
+package helm
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/sampling"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// releaseID identifies a Helm release
+type releaseID struct {
+	namespace string
+	name      string
+}
+
+// Collector collects Helm release metrics
+type Collector struct {
+	*base.BaseCollector
+
+	client    kubernetes.Interface
+	config    *Config
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	mu        base.RWMutex
+	revisions map[string]*release // key: namespace/secret, one per stored revision
+	invalid   map[string]string   // key: namespace/secret, value: namespace of undecodable secrets
+
+	// Metrics
+	releaseInfo         *prometheus.Desc
+	releaseRevision     *prometheus.Desc
+	releaseLastDeployed *prometheus.Desc
+	releaseDecodeErrors *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.releaseInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "helm", "release_info"),
+		"Status, chart and app version of the latest revision of a Helm release (always 1)",
+		[]string{"namespace", "release", "chart", "chart_version", "app_version", "status"},
+		nil,
+	)
+	c.releaseRevision = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "helm", "release_revision"),
+		"Latest revision of a Helm release",
+		[]string{"namespace", "release"},
+		nil,
+	)
+	c.releaseLastDeployed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "helm", "release_last_deployed_timestamp_seconds"),
+		"Time the latest revision of a Helm release was deployed, as a Unix timestamp",
+		[]string{"namespace", "release"},
+		nil,
+	)
+	c.releaseDecodeErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "helm", "release_decode_errors"),
+		"Number of Helm release Secrets that cannot be decoded",
+		[]string{"namespace"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.releaseInfo)
+	c.MustRegisterDesc(c.releaseRevision)
+	c.MustRegisterDesc(c.releaseLastDeployed)
+	c.MustRegisterDesc(c.releaseDecodeErrors)
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handleSecret records the release revision stored in a trimmed release Secret
+func (c *Collector) handleSecret(obj any) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Secret")
+		return
+	}
+
+	sampling.Sample(collectorName, secret)
+
+	key := objectKey(secret.Namespace, secret.Name)
+	rel, ok := releaseFromSecret(secret)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !ok {
+		c.logger.WithFields(log.Fields{
+			"namespace": secret.Namespace,
+			"secret":    secret.Name,
+		}).Debug("Failed to decode Helm release Secret")
+
+		delete(c.revisions, key)
+		c.invalid[key] = secret.Namespace
+
+		return
+	}
+
+	delete(c.invalid, key)
+	c.revisions[key] = rel
+}
+
+// handleSecretDelete removes a release revision
+func (c *Collector) handleSecretDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		secret, ok = tombstone.Obj.(*corev1.Secret)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a Secret")
+			return
+		}
+	}
+
+	key := objectKey(secret.Namespace, secret.Name)
+
+	c.mu.Lock()
+	delete(c.revisions, key)
+	delete(c.invalid, key)
+	c.mu.Unlock()
+}
+
+// latestReleases returns the latest stored revision of every release.
+// Must be called with c.mu held.
+func (c *Collector) latestReleases() map[releaseID]*release {
+	latest := make(map[releaseID]*release)
+
+	for _, rel := range c.revisions {
+		id := releaseID{namespace: rel.namespace, name: rel.name}
+		if current, ok := latest[id]; !ok || rel.revision > current.revision {
+			latest[id] = rel
+		}
+	}
+
+	return latest
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, rel := range c.latestReleases() {
+		ch <- prometheus.MustNewConstMetric(
+			c.releaseInfo,
+			prometheus.GaugeValue,
+			1,
+			rel.namespace,
+			rel.name,
+			rel.chart,
+			rel.chartVersion,
+			rel.appVersion,
+			rel.status,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.releaseRevision,
+			prometheus.GaugeValue,
+			float64(rel.revision),
+			rel.namespace,
+			rel.name,
+		)
+
+		if !rel.lastDeployed.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.releaseLastDeployed,
+				prometheus.GaugeValue,
+				float64(rel.lastDeployed.Unix()),
+				rel.namespace,
+				rel.name,
+			)
+		}
+	}
+
+	invalid := make(map[string]float64)
+	for _, namespace := range c.invalid {
+		invalid[namespace]++
+	}
+
+	for namespace, count := range invalid {
+		ch <- prometheus.MustNewConstMetric(
+			c.releaseDecodeErrors,
+			prometheus.GaugeValue,
+			count,
+			namespace,
+		)
+	}
+}
+
+// objectKey generates a unique key for a namespaced object
+func objectKey(namespace, name string) string {
+	return namespace + "/" + name
+}
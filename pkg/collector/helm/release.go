@@ -0,0 +1,159 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// releaseSecretType is the type of the Secrets storing Helm 3 releases
+	releaseSecretType corev1.SecretType = "helm.sh/release.v1"
+
+	// releaseKey holds the encoded release in a release Secret
+	releaseKey = "release"
+
+	// Summary keys kept in trimmed release Secrets
+	summaryRelease      = "name"
+	summaryRevision     = "revision"
+	summaryStatus       = "status"
+	summaryChart        = "chart"
+	summaryChartVersion = "chartVersion"
+	summaryAppVersion   = "appVersion"
+	summaryLastDeployed = "lastDeployed"
+)
+
+// gzipMagic prefixes gzip-compressed releases
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// helmRelease is the subset of a Helm release record used for metrics
+type helmRelease struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Info    struct {
+		Status       string    `json:"status"`
+		LastDeployed time.Time `json:"last_deployed"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// release is the summary of one revision of a Helm release
+type release struct {
+	namespace    string
+	name         string
+	revision     int
+	status       string
+	chart        string
+	chartVersion string
+	appVersion   string
+	lastDeployed time.Time
+}
+
+// decodeRelease decodes a release stored by Helm: base64-encoded,
+// usually gzip-compressed JSON
+func decodeRelease(data []byte) (*helmRelease, error) {
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+
+	n, err := base64.StdEncoding.Decode(decoded, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+
+	decoded = decoded[:n]
+
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+		defer reader.Close()
+
+		decoded, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+	}
+
+	var rel helmRelease
+	if err := json.Unmarshal(decoded, &rel); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release: %w", err)
+	}
+
+	return &rel, nil
+}
+
+// trimSecret reduces memory by replacing the encoded release (manifests,
+// values, hooks, ...) with the summary needed for metrics. Secrets that
+// cannot be decoded keep no data and are reported by the handler.
+func trimSecret(obj any) (any, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return obj, nil
+	}
+
+	transformed := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       secret.Namespace,
+			Name:            secret.Name,
+			UID:             secret.UID,
+			ResourceVersion: secret.ResourceVersion,
+		},
+		Type: secret.Type,
+	}
+
+	rel, err := decodeRelease(secret.Data[releaseKey])
+	if err != nil {
+		return transformed, nil
+	}
+
+	transformed.Data = map[string][]byte{
+		summaryRelease:      []byte(rel.Name),
+		summaryRevision:     []byte(strconv.Itoa(rel.Version)),
+		summaryStatus:       []byte(rel.Info.Status),
+		summaryChart:        []byte(rel.Chart.Metadata.Name),
+		summaryChartVersion: []byte(rel.Chart.Metadata.Version),
+		summaryAppVersion:   []byte(rel.Chart.Metadata.AppVersion),
+	}
+
+	if !rel.Info.LastDeployed.IsZero() {
+		transformed.Data[summaryLastDeployed] = []byte(rel.Info.LastDeployed.Format(time.RFC3339))
+	}
+
+	return transformed, nil
+}
+
+// releaseFromSecret returns the release summary of a trimmed release Secret
+func releaseFromSecret(secret *corev1.Secret) (*release, bool) {
+	name := string(secret.Data[summaryRelease])
+	if name == "" {
+		return nil, false
+	}
+
+	revision, _ := strconv.Atoi(string(secret.Data[summaryRevision]))
+	lastDeployed, _ := time.Parse(time.RFC3339, string(secret.Data[summaryLastDeployed]))
+
+	return &release{
+		namespace:    secret.Namespace,
+		name:         name,
+		revision:     revision,
+		status:       string(secret.Data[summaryStatus]),
+		chart:        string(secret.Data[summaryChart]),
+		chartVersion: string(secret.Data[summaryChartVersion]),
+		appVersion:   string(secret.Data[summaryAppVersion]),
+		lastDeployed: lastDeployed,
+	}, true
+}
@@ -0,0 +1,88 @@
+//nolint:testpackage // Tests need access to private functions trimSecret and releaseFromSecret
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// encodeRelease encodes a release record the way Helm stores it
+func encodeRelease(t *testing.T, record string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(record)); err != nil {
+		t.Fatalf("Failed to compress release: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to compress release: %v", err)
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+func TestTrimSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-user1", Name: "sh.helm.release.v1.blog.v3"},
+		Type:       releaseSecretType,
+		Data: map[string][]byte{
+			releaseKey: encodeRelease(t, `{
+				"name": "blog",
+				"version": 3,
+				"info": {"status": "failed", "last_deployed": "2026-01-02T03:04:05Z"},
+				"chart": {"metadata": {"name": "wordpress", "version": "19.0.1", "appVersion": "6.4.2"}},
+				"manifest": "apiVersion: v1\nkind: ConfigMap\n..."
+			}`),
+		},
+	}
+
+	trimmed, err := trimSecret(secret)
+	if err != nil {
+		t.Fatalf("trimSecret() error = %v", err)
+	}
+
+	trimmedSecret := trimmed.(*corev1.Secret)
+	if _, ok := trimmedSecret.Data[releaseKey]; ok {
+		t.Error("Expected the encoded release to be dropped")
+	}
+
+	rel, ok := releaseFromSecret(trimmedSecret)
+	if !ok {
+		t.Fatal("Expected release summary")
+	}
+
+	expected := release{
+		namespace:    "ns-user1",
+		name:         "blog",
+		revision:     3,
+		status:       "failed",
+		chart:        "wordpress",
+		chartVersion: "19.0.1",
+		appVersion:   "6.4.2",
+		lastDeployed: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	if *rel != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *rel)
+	}
+
+	invalid := &corev1.Secret{Data: map[string][]byte{releaseKey: []byte("not base64!")}}
+
+	trimmed, err = trimSecret(invalid)
+	if err != nil {
+		t.Fatalf("trimSecret() error = %v", err)
+	}
+
+	if _, ok := releaseFromSecret(trimmed.(*corev1.Secret)); ok {
+		t.Error("Expected no release summary for an undecodable release")
+	}
+}
@@ -6,15 +6,23 @@ import (
 
 // Config contains configuration for the ImagePull collector
 type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	// When set, one namespaced informer is created per namespace so only namespaced RBAC is needed
+	Namespaces        []string      `yaml:"namespaces"        env:"NAMESPACES"          envSeparator:","`
 	SlowPullThreshold time.Duration `yaml:"slowPullThreshold" env:"SLOW_PULL_THRESHOLD"`
 	EventRetention    time.Duration `yaml:"eventRetention"    env:"EVENT_RETENTION"`
+	// NodeRuntime labels pull metrics with the container runtime version of the node.
+	// Requires a cluster-wide node watch, so it is ignored when Namespaces is set.
+	NodeRuntime bool `yaml:"nodeRuntime" env:"NODE_RUNTIME"`
 }
 
 // NewDefaultConfig returns the default configuration for ImagePull collector
 // This function only returns hard-coded defaults without any env parsing
 func NewDefaultConfig() *Config {
 	return &Config{
+		Namespaces:        []string{},
 		SlowPullThreshold: 5 * time.Minute,
 		EventRetention:    1 * time.Hour,
+		NodeRuntime:       true,
 	}
 }
@@ -9,16 +9,22 @@ import (
 	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 )
 
 const collectorName = "imagepull"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Container image pull performance tracking"),
+		registry.WithRBAC([]string{""}, []string{"pods"}, []string{"get", "list", "watch"}),
+		registry.WithRBAC([]string{""}, []string{"nodes"}, []string{"list", "watch"}),
+	)
 }
 
 // NewCollector creates a new ImagePull collector
@@ -44,82 +50,102 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			collectorName,
 			factoryCtx.Logger,
 			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
 		),
 		client:     client,
 		config:     cfg,
 		classifier: NewFailureClassifier(),
-		failures:   make(map[string]*PullFailureInfo),
-		slowPulls:  make(map[string]*SlowPullInfo),
-		slowTimers: make(map[string]*time.Timer),
+		failures:   make(util.Index[*PullFailureInfo]),
+		slowPulls:  make(util.Index[*SlowPullInfo]),
+		slowTimers: make(util.Index[*time.Timer]),
+		pulls:      newPullTracker(),
 		stopCh:     make(chan struct{}),
 		logger:     factoryCtx.Logger,
+
+		nodeRuntimes: make(map[string]string),
 	}
 
+	c.mu.Instrument(c.BaseCollector)
+
 	c.initMetrics(factoryCtx.MetricsNamespace)
 
+	if c.config.NodeRuntime && len(c.config.Namespaces) > 0 {
+		c.logger.Warn("Container runtime labels require a cluster-wide node watch, " +
+			"disabled because namespaces are configured")
+	}
+
 	// Set lifecycle hooks
 	c.SetLifecycle(base.LifecycleFuncs{
 		StartFunc: func(ctx context.Context) error {
-			// Recreate stopCh to support restart
+			// Recreate stopCh and node state to support restart
 			c.stopCh = make(chan struct{})
+			c.nodeInformer = nil
+
+			c.mu.Lock()
+			c.nodeRuntimes = make(map[string]string)
+			c.pulls = newPullTracker()
+			c.mu.Unlock()
+
+			// Create one informer factory per configured namespace (or a single cluster-wide one)
+			factories := util.NewInformerFactories(c.client, 10*time.Minute, c.config.Namespaces)
+
+			c.podInformers = make([]cache.SharedIndexInformer, 0, len(factories))
+
+			for _, factory := range factories {
+				podInformer := factory.Core().V1().Pods().Informer()
+
+				// Apply transform to reduce memory usage
+				// Only keep necessary fields for image pull monitoring
+				_ = podInformer.SetTransform(trimPod)
+
+				c.InstrumentInformer("pods", podInformer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				podInformer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    func(obj any) { c.handlePodAdd(ctx, obj) },
+					UpdateFunc: func(oldObj, newObj any) { c.handlePodUpdate(ctx, oldObj, newObj) },
+					DeleteFunc: c.handlePodDelete,
+				}))
+
+				c.podInformers = append(c.podInformers, podInformer)
+			}
 
-			// Create informer factory
-			factory := informers.NewSharedInformerFactory(c.client, 10*time.Minute)
-
-			// Create pod informer
-			c.podInformer = factory.Core().V1().Pods().Informer()
-
-			// Apply transform to reduce memory usage
-			// Only keep necessary fields for image pull monitoring
-			_ = c.podInformer.SetTransform(func(obj any) (any, error) {
-				pod, ok := obj.(*corev1.Pod)
-				if !ok {
-					return obj, nil
-				}
-
-				// Create a minimal pod object with only required fields
-				transformed := &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{
-						Namespace: pod.Namespace,
-						Name:      pod.Name,
-						// Keep UID for proper object tracking
-						UID: pod.UID,
-					},
-					Spec: corev1.PodSpec{
-						// Only keep node name
-						NodeName: pod.Spec.NodeName,
-					},
-					Status: corev1.PodStatus{
-						// Only keep container statuses
-						InitContainerStatuses: trimContainerStatuses(
-							pod.Status.InitContainerStatuses,
-						),
-						ContainerStatuses: trimContainerStatuses(
-							pod.Status.ContainerStatuses,
-						),
-					},
-				}
-
-				return transformed, nil
-			})
-
-			//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
-			c.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-				AddFunc:    func(obj any) { c.handlePodAdd(ctx, obj) },
-				UpdateFunc: func(oldObj, newObj any) { c.handlePodUpdate(ctx, oldObj, newObj) },
-				DeleteFunc: c.handlePodDelete,
-			})
+			// Pull failures are labelled with the node container runtime so
+			// runtime-specific issues can be isolated across the fleet.
+			// Factories are cluster-wide here, since namespaces are not configured.
+			if c.nodeRuntimeEnabled() {
+				c.nodeInformer = factories[0].Core().V1().Nodes().Informer()
+				_ = c.nodeInformer.SetTransform(trimNode)
+
+				c.InstrumentInformer("nodes", c.nodeInformer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				c.nodeInformer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleNode,
+					UpdateFunc: func(_, newObj any) { c.handleNode(newObj) },
+					DeleteFunc: c.handleNodeDelete,
+				}))
+			}
 
 			// Start informers
-			factory.Start(c.stopCh)
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
 
 			// Wait for cache sync
-			c.logger.Info("Waiting for imagepull informer cache sync")
+			c.logger.WithField("informers", len(c.podInformers)).
+				Info("Waiting for imagepull informer cache sync")
 
-			if !cache.WaitForCacheSync(c.stopCh, c.podInformer.HasSynced) {
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
 				return errors.New("failed to sync imagepull informer cache")
 			}
 
+			c.WatchInformers(c.podInformers...)
+			if c.nodeInformer != nil {
+				c.WatchInformers(c.nodeInformer)
+			}
+
 			c.logger.Info("ImagePull collector started successfully")
 
 			c.SetReady()
@@ -136,6 +162,38 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 	return c, nil
 }
 
+// trimPod creates a minimal pod object with only the fields required for image pull monitoring
+func trimPod(obj any) (any, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+
+	transformed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			// Keep UID for proper object tracking
+			UID: pod.UID,
+		},
+		Spec: corev1.PodSpec{
+			// Only keep node name
+			NodeName: pod.Spec.NodeName,
+		},
+		Status: corev1.PodStatus{
+			// Only keep container statuses
+			InitContainerStatuses: trimContainerStatuses(
+				pod.Status.InitContainerStatuses,
+			),
+			ContainerStatuses: trimContainerStatuses(
+				pod.Status.ContainerStatuses,
+			),
+		},
+	}
+
+	return transformed, nil
+}
+
 // trimContainerStatuses reduces memory by keeping only necessary container status fields
 func trimContainerStatuses(statuses []corev1.ContainerStatus) []corev1.ContainerStatus {
 	if len(statuses) == 0 {
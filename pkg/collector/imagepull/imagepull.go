@@ -3,10 +3,10 @@ package imagepull
 import (
 	"context"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -40,21 +40,30 @@ type SlowPullInfo struct {
 type Collector struct {
 	*base.BaseCollector
 
-	client      kubernetes.Interface
-	config      *Config
-	podInformer cache.SharedIndexInformer
-	classifier  *FailureClassifier
-	stopCh      chan struct{}
-	logger      *log.Entry
-
-	mu         sync.RWMutex
-	failures   map[string]*PullFailureInfo // key: namespace/pod/container
-	slowPulls  map[string]*SlowPullInfo    // key: namespace/pod/container
-	slowTimers map[string]*time.Timer      // key: namespace/pod/container
+	client       kubernetes.Interface
+	config       *Config
+	podInformers []cache.SharedIndexInformer
+	nodeInformer cache.SharedIndexInformer
+	classifier   *FailureClassifier
+	stopCh       chan struct{}
+	logger       *log.Entry
+
+	mu         base.RWMutex
+	failures   util.Index[*PullFailureInfo] // key: namespace/pod, then container
+	slowPulls  util.Index[*SlowPullInfo]    // key: namespace/pod, then container
+	slowTimers util.Index[*time.Timer]      // key: namespace/pod, then container
+	// nodeRuntimes maps node names to their container runtime version
+	nodeRuntimes map[string]string
+	// pulls tracks pull outcomes per namespace
+	pulls *pullTracker
 
 	// Metrics
 	imagePullFailures *prometheus.Desc
 	imagePullSlow     *prometheus.Desc
+
+	imagePullOutcomes     *prometheus.Desc
+	imagePullSuccessRatio *prometheus.Desc
+	imagePullRecovery     *prometheus.Desc
 }
 
 // initMetrics initializes Prometheus metric descriptors
@@ -62,24 +71,57 @@ func (c *Collector) initMetrics(namespace string) {
 	c.imagePullFailures = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "image", "pull_failures"),
 		"Image pull failures",
-		[]string{"namespace", "pod", "node", "registry", "image", "reason"},
+		[]string{"namespace", "pod", "node", "container_runtime", "registry", "image", "reason"},
 		nil,
 	)
 	c.imagePullSlow = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "image", "pull_slow"),
 		"Slow image pulls (duration > threshold)",
-		[]string{"namespace", "pod", "node", "registry", "image"},
+		[]string{"namespace", "pod", "node", "container_runtime", "registry", "image"},
+		nil,
+	)
+
+	c.imagePullOutcomes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "image", "pull_outcomes"),
+		"Number of observed image pulls per outcome since the collector started "+
+			"(succeeded, recovered after failures, abandoned while failing)",
+		[]string{"namespace", "outcome"},
+		nil,
+	)
+	c.imagePullSuccessRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "image", "pull_success_ratio"),
+		"Fraction of observed image pulls that succeeded without any failure",
+		[]string{"namespace"},
+		nil,
+	)
+	c.imagePullRecovery = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "image", "pull_recovery_seconds"),
+		"Mean time from the first pull failure to the container start of recovered pulls",
+		[]string{"namespace"},
 		nil,
 	)
 
 	// Register descriptors
 	c.MustRegisterDesc(c.imagePullFailures)
 	c.MustRegisterDesc(c.imagePullSlow)
+	c.MustRegisterDesc(c.imagePullOutcomes)
+	c.MustRegisterDesc(c.imagePullSuccessRatio)
+	c.MustRegisterDesc(c.imagePullRecovery)
 }
 
-// HasSynced returns true if the informer has synced
+// HasSynced returns true if all pod informers (and the node informer, if any) have synced
 func (c *Collector) HasSynced() bool {
-	return c.podInformer != nil && c.podInformer.HasSynced()
+	if len(c.podInformers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.podInformers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return c.nodeInformer == nil || c.nodeInformer.HasSynced()
 }
 
 // handlePodAdd handles pod add events
@@ -126,25 +168,15 @@ func (c *Collector) handlePodDelete(obj any) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Clean up all failures, slow pulls and timers for this pod
-	prefix := pod.Namespace + "/" + pod.Name + "/"
-	for key := range c.failures {
-		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
-			delete(c.failures, key)
-		}
-	}
+	// Clean up all failures, slow pulls, timers and pull attempts for this pod
+	key := podKey(pod.Namespace, pod.Name)
 
-	for key := range c.slowPulls {
-		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
-			delete(c.slowPulls, key)
-		}
-	}
+	c.pulls.removePod(key)
+	c.failures.DeleteParent(key)
+	c.slowPulls.DeleteParent(key)
 
-	for key, timer := range c.slowTimers {
-		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
-			timer.Stop()
-			delete(c.slowTimers, key)
-		}
+	for _, timer := range c.slowTimers.DeleteParent(key) {
+		timer.Stop()
 	}
 }
 
@@ -159,22 +191,28 @@ func (c *Collector) processPod(ctx context.Context, pod *corev1.Pod) {
 	defer c.mu.Unlock()
 
 	nodeName := pod.Spec.NodeName
+	key := podKey(pod.Namespace, pod.Name)
+	now := time.Now()
 
 	// Process init containers and regular containers
 	allStatuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
 	allStatuses = append(allStatuses, pod.Status.ContainerStatuses...)
 
 	for _, containerStatus := range allStatuses {
-		key := pullInfoKey(pod.Namespace, pod.Name, containerStatus.Name)
+		container := containerStatus.Name
+
+		failing := containerStatus.State.Waiting != nil &&
+			c.isImagePullFailure(containerStatus.State.Waiting.Reason)
+
+		c.pulls.observe(key, container, pod.Namespace, containerStatus.ContainerID != "", failing, now)
 
 		// Check for image pull failures
-		if containerStatus.State.Waiting != nil &&
-			c.isImagePullFailure(containerStatus.State.Waiting.Reason) {
+		if failing {
 			waiting := containerStatus.State.Waiting
 			reason := c.classifier.Classify(waiting.Reason, waiting.Message)
 			registry := parseRegistry(containerStatus.Image)
 
-			c.failures[key] = &PullFailureInfo{
+			c.failures.Set(key, container, &PullFailureInfo{
 				Namespace: pod.Namespace,
 				Pod:       pod.Name,
 				Container: containerStatus.Name,
@@ -182,16 +220,16 @@ func (c *Collector) processPod(ctx context.Context, pod *corev1.Pod) {
 				Node:      nodeName,
 				Registry:  registry,
 				Reason:    reason,
-			}
+			})
 
 			// Clean up slow pull state if in failure state
-			c.cleanupSlowPull(key)
+			c.cleanupSlowPull(key, container)
 
 			continue
 		}
 
 		// Clean up failure if no longer failing
-		delete(c.failures, key)
+		c.failures.Delete(key, container)
 
 		// Check for slow pull (container is waiting in ContainerCreating state)
 		if containerStatus.ContainerID == "" &&
@@ -202,7 +240,7 @@ func (c *Collector) processPod(ctx context.Context, pod *corev1.Pod) {
 			)
 		} else {
 			// Clean up slow pull state if container started or failed
-			c.cleanupSlowPull(key)
+			c.cleanupSlowPull(key, container)
 		}
 	}
 }
@@ -226,7 +264,7 @@ func (c *Collector) checkSlowPull(
 	nodeName string,
 ) {
 	// Check if timer already exists
-	if _, exists := c.slowTimers[key]; exists {
+	if _, exists := c.slowTimers.Get(key, cs.Name); exists {
 		return
 	}
 
@@ -237,7 +275,7 @@ func (c *Collector) checkSlowPull(
 		)
 	})
 
-	c.slowTimers[key] = timer
+	c.slowTimers.Set(key, cs.Name, timer)
 
 	c.logger.WithFields(log.Fields{
 		"pod":       pod.Namespace + "/" + pod.Name,
@@ -255,7 +293,7 @@ func (c *Collector) handleSlowPullTimer(
 	defer c.mu.Unlock()
 
 	// Remove timer
-	delete(c.slowTimers, key)
+	c.slowTimers.Delete(key, containerName)
 
 	// Re-check pod status
 	pod, err := c.client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
@@ -283,14 +321,14 @@ func (c *Collector) handleSlowPullTimer(
 			cs.State.Waiting.Reason == "ContainerCreating" {
 			registry := parseRegistry(image)
 
-			c.slowPulls[key] = &SlowPullInfo{
+			c.slowPulls.Set(key, containerName, &SlowPullInfo{
 				Namespace: namespace,
 				Pod:       podName,
 				Container: containerName,
 				Image:     image,
 				Node:      nodeName,
 				Registry:  registry,
-			}
+			})
 
 			c.logger.WithFields(log.Fields{
 				"pod":       namespace + "/" + podName,
@@ -305,14 +343,14 @@ func (c *Collector) handleSlowPullTimer(
 }
 
 // cleanupSlowPull cleans up slow pull state
-func (c *Collector) cleanupSlowPull(key string) {
+func (c *Collector) cleanupSlowPull(key, container string) {
 	// Clean up slow pull info
-	delete(c.slowPulls, key)
+	c.slowPulls.Delete(key, container)
 
 	// Stop and remove timer
-	if timer, exists := c.slowTimers[key]; exists {
+	if timer, exists := c.slowTimers.Get(key, container); exists {
 		timer.Stop()
-		delete(c.slowTimers, key)
+		c.slowTimers.Delete(key, container)
 	}
 }
 
@@ -322,38 +360,46 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	defer c.mu.RUnlock()
 
 	// Collect pull failures
-	for _, info := range c.failures {
-		ch <- prometheus.MustNewConstMetric(
-			c.imagePullFailures,
-			prometheus.GaugeValue,
-			1,
-			info.Namespace,
-			info.Pod,
-			info.Node,
-			info.Registry,
-			info.Image,
-			string(info.Reason),
-		)
+	for _, pullInfos := range c.failures {
+		for _, info := range pullInfos {
+			ch <- prometheus.MustNewConstMetric(
+				c.imagePullFailures,
+				prometheus.GaugeValue,
+				1,
+				info.Namespace,
+				info.Pod,
+				info.Node,
+				c.nodeRuntime(info.Node),
+				info.Registry,
+				info.Image,
+				string(info.Reason),
+			)
+		}
 	}
 
 	// Collect slow pulls
-	for _, info := range c.slowPulls {
-		ch <- prometheus.MustNewConstMetric(
-			c.imagePullSlow,
-			prometheus.GaugeValue,
-			1,
-			info.Namespace,
-			info.Pod,
-			info.Node,
-			info.Registry,
-			info.Image,
-		)
+	for _, pullInfos := range c.slowPulls {
+		for _, info := range pullInfos {
+			ch <- prometheus.MustNewConstMetric(
+				c.imagePullSlow,
+				prometheus.GaugeValue,
+				1,
+				info.Namespace,
+				info.Pod,
+				info.Node,
+				c.nodeRuntime(info.Node),
+				info.Registry,
+				info.Image,
+			)
+		}
 	}
+
+	c.collectReliability(ch)
 }
 
-// pullInfoKey generates a unique key for pull info
-func pullInfoKey(namespace, pod, container string) string {
-	return namespace + "/" + pod + "/" + container
+// podKey generates the key of the pull info of a pod
+func podKey(namespace, pod string) string {
+	return namespace + "/" + pod
 }
 
 // parseRegistry extracts registry from image name
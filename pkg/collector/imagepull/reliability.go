@@ -0,0 +1,148 @@
+package imagepull
+
+import (
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Pull outcomes reported by the outcomes metric
+const (
+	outcomeSucceeded = "succeeded" // the container started without a pull failure
+	outcomeRecovered = "recovered" // the container started after one or more pull failures
+	outcomeAbandoned = "abandoned" // the pod was deleted while its pull was failing
+)
+
+// pullAttempt is the pull of a container image, tracked from the first time the
+// container is seen waiting until it starts or its pod is deleted
+type pullAttempt struct {
+	namespace    string
+	firstFailure time.Time // zero while the pull has not failed
+}
+
+// pullStats holds the pull outcomes of a namespace since the collector started
+type pullStats struct {
+	succeeded int
+	recovered int
+	abandoned int
+	// recoveryTime is the total time from the first failure to the start of
+	// recovered pulls
+	recoveryTime time.Duration
+}
+
+// successRatio returns the fraction of pulls that succeeded without failing
+func (s *pullStats) successRatio() float64 {
+	total := s.succeeded + s.recovered + s.abandoned
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.succeeded) / float64(total)
+}
+
+// pullTracker derives per-namespace pull reliability from container states
+type pullTracker struct {
+	attempts util.Index[*pullAttempt] // key: namespace/pod, then container
+	stats    map[string]*pullStats    // key: namespace
+}
+
+// newPullTracker creates an empty tracker
+func newPullTracker() *pullTracker {
+	return &pullTracker{
+		attempts: make(util.Index[*pullAttempt]),
+		stats:    make(map[string]*pullStats),
+	}
+}
+
+// namespaceStats returns the stats of a namespace, creating them if needed
+func (t *pullTracker) namespaceStats(namespace string) *pullStats {
+	stats, ok := t.stats[namespace]
+	if !ok {
+		stats = &pullStats{}
+		t.stats[namespace] = stats
+	}
+
+	return stats
+}
+
+// observe records the state of a container. Containers already started when
+// first seen (e.g. on startup) are ignored, since their pull was not observed.
+func (t *pullTracker) observe(key, container, namespace string, started, failing bool, now time.Time) {
+	attempt, tracked := t.attempts.Get(key, container)
+
+	if started {
+		if !tracked {
+			return
+		}
+
+		t.attempts.Delete(key, container)
+
+		stats := t.namespaceStats(namespace)
+		if attempt.firstFailure.IsZero() {
+			stats.succeeded++
+			return
+		}
+
+		stats.recovered++
+		stats.recoveryTime += now.Sub(attempt.firstFailure)
+
+		return
+	}
+
+	if !tracked {
+		attempt = &pullAttempt{namespace: namespace}
+		t.attempts.Set(key, container, attempt)
+	}
+
+	if failing && attempt.firstFailure.IsZero() {
+		attempt.firstFailure = now
+	}
+}
+
+// removePod drops the attempts of a deleted pod, counting failing ones as abandoned
+func (t *pullTracker) removePod(key string) {
+	for _, attempt := range t.attempts.DeleteParent(key) {
+		if !attempt.firstFailure.IsZero() {
+			t.namespaceStats(attempt.namespace).abandoned++
+		}
+	}
+}
+
+// collectReliability emits the pull outcomes, success ratio and mean recovery
+// time of each namespace. Must be called with c.mu held.
+func (c *Collector) collectReliability(ch chan<- prometheus.Metric) {
+	for namespace, stats := range c.pulls.stats {
+		outcomes := map[string]int{
+			outcomeSucceeded: stats.succeeded,
+			outcomeRecovered: stats.recovered,
+			outcomeAbandoned: stats.abandoned,
+		}
+
+		for outcome, count := range outcomes {
+			ch <- prometheus.MustNewConstMetric(
+				c.imagePullOutcomes,
+				prometheus.GaugeValue,
+				float64(count),
+				namespace,
+				outcome,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.imagePullSuccessRatio,
+			prometheus.GaugeValue,
+			stats.successRatio(),
+			namespace,
+		)
+
+		if stats.recovered > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.imagePullRecovery,
+				prometheus.GaugeValue,
+				stats.recoveryTime.Seconds()/float64(stats.recovered),
+				namespace,
+			)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+//nolint:testpackage // Tests need access to private functions
+package imagepull
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPullTracker(t *testing.T) {
+	tracker := newPullTracker()
+	start := time.Now()
+
+	// Already running when first seen: not counted
+	tracker.observe("ns-a/old", "app", "ns-a", true, false, start)
+
+	// Clean pull
+	tracker.observe("ns-a/web", "app", "ns-a", false, false, start)
+	tracker.observe("ns-a/web", "app", "ns-a", true, false, start.Add(time.Second))
+
+	// Recovered after 2 minutes, repeated failures keep the first failure time
+	tracker.observe("ns-a/api", "app", "ns-a", false, true, start)
+	tracker.observe("ns-a/api", "app", "ns-a", false, true, start.Add(time.Minute))
+	tracker.observe("ns-a/api", "app", "ns-a", true, false, start.Add(2*time.Minute))
+
+	// Recovered after 4 minutes
+	tracker.observe("ns-a/job", "app", "ns-a", false, false, start)
+	tracker.observe("ns-a/job", "app", "ns-a", false, true, start.Add(time.Minute))
+	tracker.observe("ns-a/job", "app", "ns-a", true, false, start.Add(5*time.Minute))
+
+	// Deleted while failing, and deleted while creating
+	tracker.observe("ns-a/bad", "app", "ns-a", false, true, start)
+	tracker.observe("ns-a/bad", "sidecar", "ns-a", false, false, start)
+	tracker.removePod("ns-a/bad")
+
+	if len(tracker.attempts) != 0 {
+		t.Errorf("Expected no pending attempts, got %d", len(tracker.attempts))
+	}
+
+	stats := tracker.stats["ns-a"]
+	if stats == nil {
+		t.Fatal("Expected stats for ns-a")
+	}
+
+	if stats.succeeded != 1 || stats.recovered != 2 || stats.abandoned != 1 {
+		t.Errorf("Unexpected outcomes %+v", *stats)
+	}
+
+	if ratio := stats.successRatio(); ratio != 0.25 {
+		t.Errorf("Expected success ratio 0.25, got %v", ratio)
+	}
+
+	if mean := stats.recoveryTime / time.Duration(stats.recovered); mean != 3*time.Minute {
+		t.Errorf("Expected mean recovery time 3m, got %v", mean)
+	}
+}
@@ -0,0 +1,82 @@
+package imagepull
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// unknownRuntime labels pulls on nodes whose container runtime is not known
+const unknownRuntime = "unknown"
+
+// nodeRuntimeEnabled returns whether pull metrics are labelled with the node container runtime.
+// Watching nodes requires cluster-wide RBAC, so it is disabled for namespaced watches.
+func (c *Collector) nodeRuntimeEnabled() bool {
+	return c.config.NodeRuntime && len(c.config.Namespaces) == 0
+}
+
+// handleNode records the container runtime version of a node
+func (c *Collector) handleNode(obj any) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Node")
+		return
+	}
+
+	c.mu.Lock()
+	c.nodeRuntimes[node.Name] = node.Status.NodeInfo.ContainerRuntimeVersion
+	c.mu.Unlock()
+}
+
+// handleNodeDelete forgets the container runtime version of a deleted node
+func (c *Collector) handleNodeDelete(obj any) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		node, ok = tombstone.Obj.(*corev1.Node)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a Node")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.nodeRuntimes, node.Name)
+	c.mu.Unlock()
+}
+
+// nodeRuntime returns the container runtime version of a node (e.g. containerd://1.7.13).
+// Must be called with c.mu held.
+func (c *Collector) nodeRuntime(nodeName string) string {
+	if runtime := c.nodeRuntimes[nodeName]; runtime != "" {
+		return runtime
+	}
+
+	return unknownRuntime
+}
+
+// trimNode keeps only the fields needed to label pulls with the container runtime
+func trimNode(obj any) (any, error) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+			UID:  node.UID,
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{
+				ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+			},
+		},
+	}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/labring/sealos-state-metrics/pkg/identity"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
@@ -59,6 +60,152 @@ type PollingCollector interface {
 	Poll(ctx context.Context) error
 }
 
+// CancellationReporter is implemented by collectors counting checks canceled
+// by the timeout hierarchy (global -> collector -> check)
+type CancellationReporter interface {
+	// CanceledChecks returns the number of canceled checks per deadline level
+	CanceledChecks() map[string]uint64
+}
+
+// StatusReporter is implemented by collectors that expose their cached state
+// as structured data (served by the /api/v1/status endpoint)
+type StatusReporter interface {
+	// Status returns a JSON-serializable snapshot of the collector state
+	Status() any
+}
+
+// HistoryReporter is implemented by collectors keeping the last results of
+// each target (served by the /api/v1/history/{collector}/{name} endpoint)
+type HistoryReporter interface {
+	// History returns a JSON-serializable history of the named target,
+	// or false if the target is unknown
+	History(name string) (any, bool)
+}
+
+// Certificate is a certificate tracked by a collector, as exported by the
+// /api/v1/certs endpoint
+type Certificate struct {
+	Namespace  string    `json:"namespace"`
+	Secret     string    `json:"secret"`
+	CommonName string    `json:"commonName,omitempty"`
+	SANs       []string  `json:"sans,omitempty"`
+	Issuer     string    `json:"issuer,omitempty"`
+	NotBefore  time.Time `json:"notBefore,omitzero"`
+	NotAfter   time.Time `json:"notAfter,omitzero"`
+	// Fingerprint is the SHA-256 fingerprint of the leaf, identical for
+	// secrets storing the same certificate
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Error is set when the certificate is missing or cannot be parsed, the
+	// other fields are then empty
+	Error string `json:"error,omitempty"`
+}
+
+// CertificateReporter is implemented by collectors tracking certificates
+// (served by the /api/v1/certs endpoint)
+type CertificateReporter interface {
+	// Certificates returns the tracked certificates
+	Certificates() []Certificate
+}
+
+// ServedCertificate is the certificate served live for a host through one of
+// its IPs, along with the TLS secret its Ingress references
+type ServedCertificate struct {
+	Domain string
+	IP     string
+	// Namespace and Secret name the TLS secret referenced by the Ingress
+	Namespace string
+	Secret    string
+	NotAfter  time.Time
+	// Fingerprint is the SHA-256 fingerprint of the served leaf
+	Fingerprint string
+}
+
+// ServedCertificateReporter is implemented by collectors checking the
+// certificates served by Ingress hosts, to tell whether the gateway serves
+// the certificate stored in the referenced secret
+type ServedCertificateReporter interface {
+	// ServedCertificates returns the certificates served by the hosts of
+	// Ingresses referencing a TLS secret
+	ServedCertificates() []ServedCertificate
+}
+
+// RuntimeStats are the cumulative runtime counters of a collector, used to
+// attribute CPU spikes (e.g. during informer resyncs) to a collector
+type RuntimeStats struct {
+	// Instrumented is false when the collector does not instrument its
+	// event handlers nor its state lock, the other fields are then zero
+	Instrumented bool
+	// HandlerEvents is the number of informer notifications handled
+	HandlerEvents uint64
+	// HandlerEventsByType is the number of informer notifications handled,
+	// per event type (add, update, delete)
+	HandlerEventsByType map[string]uint64
+	// HandlerPending is the number of notifications currently being handled
+	HandlerPending int64
+	// HandlerDuration is the total time spent in event handlers
+	HandlerDuration time.Duration
+	// LockWait is the total time spent waiting for the collector state lock,
+	// per lock mode (read, write)
+	LockWait map[string]time.Duration
+	// InformerRestarts is the number of restarts of the collector caused by
+	// a stale informer watch
+	InformerRestarts uint64
+	// WatchErrors is the number of failed informer watches (other than
+	// closed or expired watches), each followed by a relist
+	WatchErrors uint64
+	// CacheObjects is the number of objects cached by the informers of the
+	// running collector, per resource
+	CacheObjects map[string]int
+	// QueueDepth is the number of objects whose notifications wait in the
+	// event queue of the running collector
+	QueueDepth int
+	// QueueWait is the total time notifications waited in the event queue
+	// before being handled
+	QueueWait time.Duration
+	// QueueRetries is the number of notifications queued again after their
+	// handler failed
+	QueueRetries uint64
+}
+
+// CollectionStats describe the last collections of a collector by the
+// registry, during metrics scrapes
+type CollectionStats struct {
+	// Duration is the duration of the last collection
+	Duration time.Duration
+	// LastSuccess is the time of the last collection that did not panic,
+	// zero if none did
+	LastSuccess time.Time
+}
+
+// RuntimeReporter is implemented by collectors instrumenting their event
+// handlers and state lock
+type RuntimeReporter interface {
+	// RuntimeStats returns a snapshot of the runtime counters
+	RuntimeStats() RuntimeStats
+}
+
+// Liveness is the lifecycle state of a collector, telling whether it works
+type Liveness struct {
+	// Started is true while the collector runs
+	Started bool
+	// StartError is the error of the last start, nil once started or stopped
+	StartError error
+	// Ready is true once the collector synced or completed its first poll
+	Ready bool
+	// StartedAt is the time of the last successful start
+	StartedAt time.Time
+	// LastPollSuccess is the time of the last successful poll cycle since
+	// the collector started, zero if none succeeded (polling collectors only)
+	LastPollSuccess time.Time
+}
+
+// LivenessReporter is implemented by collectors reporting their lifecycle
+// state (exported by the collector_up metric)
+type LivenessReporter interface {
+	// Liveness returns a snapshot of the lifecycle state
+	Liveness() Liveness
+}
+
 // ConfigLoader defines the interface for loading module-specific configuration
 type ConfigLoader interface {
 	LoadModuleConfig(moduleKey string, target any) error
@@ -70,6 +217,7 @@ type FactoryContext struct {
 	//nolint:containedctx // Context is part of factory parameters struct, passed to factory functions
 	Ctx          context.Context
 	ConfigLoader ConfigLoader // Loader for module-specific configuration (never nil, use NullLoader as fallback)
+	Instance     string       // Instance name (e.g. "internal" for "domain:internal"), empty for the default instance
 
 	// Global configs that all collectors might need
 	Identity             string // Instance identity (defaults to NodeName > PodName > auto-detected)
@@ -77,12 +225,23 @@ type FactoryContext struct {
 	PodName              string // Pod name (from POD_NAME env var)
 	MetricsNamespace     string
 	InformerResyncPeriod time.Duration
+	CollectionTimeout    time.Duration    // Global upper bound of a poll cycle (0 = unbounded)
+	StaleWatchTimeout    time.Duration    // Restart informer collectors whose watch is stale for this long (0 = disabled)
+	EventQueueWorkers    int              // Workers handling the queued informer notifications (0 = no queue)
+	EventQueueMaxDepth   int              // Maximum number of objects with queued notifications (0 = unbounded)
+	Standalone           bool             // Running without Kubernetes, GetClient and GetRestConfig always fail
+	Cluster              identity.Cluster // Cluster name, region and zone (fields may be empty)
 
 	// Logger is the base logger, collectors should use Logger.WithField("collector", name) for component-specific logging
 	Logger *log.Entry
 
 	// ClientProvider for lazy Kubernetes client initialization (shared across all collectors)
 	ClientProvider ClientProvider
+
+	// Collectors returns the running collectors keyed by instance name, for
+	// collectors correlating their state with the one of other collectors.
+	// May be nil, and must not be called by the factory.
+	Collectors func() map[string]Collector
 }
 
 // ClientConfig holds Kubernetes client configuration
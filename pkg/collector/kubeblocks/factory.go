@@ -5,14 +5,28 @@ import (
 	"fmt"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	dynamiccollector "github.com/labring/sealos-state-metrics/pkg/collector/dynamic"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
+	rbacv1 "k8s.io/api/rbac/v1"
 )
 
 const collectorName = "kubeblocks"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("KubeBlocks cluster status"),
+		registry.WithRBAC([]string{"apps.kubeblocks.io"}, []string{"clusters"}, []string{"get", "list", "watch"}),
+		// Config validation against the CRD schema
+		registry.WithRBACRule(rbacv1.PolicyRule{
+			APIGroups:     []string{"apiextensions.k8s.io"},
+			Resources:     []string{"customresourcedefinitions"},
+			ResourceNames: []string{"clusters.apps.kubeblocks.io"},
+			Verbs:         []string{"get"},
+		}),
+	)
 }
 
 // NewCollector creates a new KubeBlocks Cluster collector using configuration-driven approach
@@ -42,6 +56,8 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 		factoryCtx.MetricsNamespace,
 		restConfig,
 		factoryCtx.Logger,
+		base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+		base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
 	)
 }
 
@@ -0,0 +1,102 @@
+package lvm
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+
+	"github.com/labring/sealos-state-metrics/pkg/lvm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Host capabilities required by the LVM checks
+const (
+	capabilityLinux        = "linux"         // LVM only exists on Linux nodes
+	capabilityLVMTools     = "lvm_tools"     // the vgs command is installed
+	capabilityDeviceMapper = "device_mapper" // the host /dev is mounted with device mapper nodes
+)
+
+// capabilities tells which host capabilities are available
+type capabilities map[string]bool
+
+// hostProbe abstracts the host lookups of the capability detection (replaced in tests)
+type hostProbe struct {
+	goos     string
+	lookPath func(file string) (string, error)
+	stat     func(name string) (os.FileInfo, error)
+}
+
+// localHost probes the host the collector runs on
+var localHost = hostProbe{
+	goos:     runtime.GOOS,
+	lookPath: exec.LookPath,
+	stat:     os.Stat,
+}
+
+// detectCapabilities detects the host capabilities required by the LVM checks.
+// Lookups that need Linux are skipped on other platforms (e.g. Windows nodes).
+func detectCapabilities(host hostProbe) capabilities {
+	caps := capabilities{
+		capabilityLinux:        host.goos == "linux",
+		capabilityLVMTools:     false,
+		capabilityDeviceMapper: false,
+	}
+
+	if !caps[capabilityLinux] {
+		return caps
+	}
+
+	if _, err := host.lookPath(lvm.VGList); err == nil {
+		caps[capabilityLVMTools] = true
+	}
+
+	if _, err := host.stat(lvm.DevMapperPath); err == nil {
+		caps[capabilityDeviceMapper] = true
+	}
+
+	return caps
+}
+
+// missing returns the unavailable capabilities, sorted
+func (caps capabilities) missing() []string {
+	var missing []string
+
+	for name, available := range caps {
+		if !available {
+			missing = append(missing, name)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// supported returns whether the LVM checks can run on the host
+func (caps capabilities) supported() bool {
+	return len(caps.missing()) == 0
+}
+
+// collectCapabilities emits the detected host capabilities
+func (c *Collector) collectCapabilities(ch chan<- prometheus.Metric) {
+	for name, available := range c.capabilities {
+		ch <- prometheus.MustNewConstMetric(
+			c.lvmCapability,
+			prometheus.GaugeValue,
+			boolToFloat64(available),
+			c.config.NodeName,
+			localHost.goos,
+			name,
+		)
+	}
+}
+
+// boolToFloat64 converts a boolean to a float64
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+
+	return 0.0
+}
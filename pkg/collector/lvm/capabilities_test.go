@@ -0,0 +1,56 @@
+//nolint:testpackage // Tests need access to private functions
+package lvm
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDetectCapabilities(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/sbin/vgs", nil }
+	notFound := func(string) (string, error) { return "", errors.New("not found") }
+	exists := func(string) (os.FileInfo, error) { return nil, nil }
+	missing := func(string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+
+	tests := []struct {
+		name    string
+		host    hostProbe
+		missing []string
+	}{
+		{
+			name: "linux with lvm",
+			host: hostProbe{goos: "linux", lookPath: found, stat: exists},
+		},
+		{
+			name:    "linux without lvm tools",
+			host:    hostProbe{goos: "linux", lookPath: notFound, stat: exists},
+			missing: []string{capabilityLVMTools},
+		},
+		{
+			name:    "linux without host dev",
+			host:    hostProbe{goos: "linux", lookPath: found, stat: missing},
+			missing: []string{capabilityDeviceMapper},
+		},
+		{
+			name:    "windows",
+			host:    hostProbe{goos: "windows", lookPath: found, stat: exists},
+			missing: []string{capabilityDeviceMapper, capabilityLinux, capabilityLVMTools},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := detectCapabilities(tt.host)
+
+			if got := caps.missing(); !reflect.DeepEqual(got, tt.missing) {
+				t.Errorf("Expected missing capabilities %v, got %v", tt.missing, got)
+			}
+
+			if caps.supported() != (len(tt.missing) == 0) {
+				t.Errorf("Unexpected supported() = %v", caps.supported())
+			}
+		})
+	}
+}
@@ -6,12 +6,18 @@ import (
 	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
+	log "github.com/sirupsen/logrus"
 )
 
 const collectorName = "lvm"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("LVM storage metrics (node-level)"),
+		registry.WithStandalone(),
+	)
 }
 
 // NewCollector creates a new LVM collector
@@ -51,6 +57,19 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			// Recreate stop channel to support restart
 			c.stopCh = make(chan struct{})
 
+			caps := detectCapabilities(localHost)
+
+			c.mu.Lock()
+			c.capabilities = caps
+			c.mu.Unlock()
+
+			if missing := caps.missing(); len(missing) > 0 {
+				c.logger.WithFields(log.Fields{
+					"os":      localHost.goos,
+					"missing": missing,
+				}).Info("Host lacks LVM capabilities, disabling LVM checks")
+			}
+
 			// Start metrics collection in background
 			go c.startMetricsCollection(ctx)
 
@@ -22,9 +22,13 @@ type Collector struct {
 	mu     sync.RWMutex
 	stopCh chan struct{}
 
+	// capabilities are the host capabilities detected at startup
+	capabilities capabilities
+
 	// Metrics descriptors
 	lvmVgsTotalCapacity *prometheus.Desc
 	lvmVgsTotalFree     *prometheus.Desc
+	lvmCapability       *prometheus.Desc
 
 	// Current metric values
 	totalCapacity float64
@@ -45,23 +49,35 @@ func (c *Collector) initMetrics(namespace string) {
 		[]string{"node"},
 		nil,
 	)
+	c.lvmCapability = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "lvm", "capability"),
+		"Host capabilities required by the LVM checks, detected at startup (1=available, 0=unavailable)",
+		[]string{"node", "os", "capability"},
+		nil,
+	)
 
 	// Register descriptors
 	c.MustRegisterDesc(c.lvmVgsTotalCapacity)
 	c.MustRegisterDesc(c.lvmVgsTotalFree)
+	c.MustRegisterDesc(c.lvmCapability)
 }
 
-// updateMetrics updates LVM metrics by querying the system
-func (c *Collector) updateMetrics() {
+// updateMetrics updates LVM metrics by querying the system. Nothing is
+// queried on hosts missing a capability.
+func (c *Collector) updateMetrics() error {
+	if !c.capabilities.supported() {
+		return nil
+	}
+
 	vgs, err := lvm.ListLVMVolumeGroup(false)
 	if err != nil {
 		c.logger.WithError(err).Error("Failed to list LVM volume groups")
-		return
+		return err
 	}
 
 	if len(vgs) == 0 {
 		c.logger.Debug("No LVM volume groups found")
-		return
+		return nil
 	}
 
 	vgAmountTotal := resource.NewQuantity(0, resource.BinarySI)
@@ -81,6 +97,8 @@ func (c *Collector) updateMetrics() {
 		"total_capacity": vgAmountTotal.String(),
 		"total_free":     vgFreeTotal.String(),
 	}).Debug("Updated LVM metrics")
+
+	return nil
 }
 
 // collect collects metrics
@@ -88,7 +106,9 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	if c.config.NodeName != "" {
+	c.collectCapabilities(ch)
+
+	if c.config.NodeName != "" && c.capabilities.supported() {
 		ch <- prometheus.MustNewConstMetric(
 			c.lvmVgsTotalCapacity,
 			prometheus.GaugeValue,
@@ -111,7 +131,7 @@ func (c *Collector) startMetricsCollection(ctx context.Context) {
 	defer ticker.Stop()
 
 	// Update metrics immediately on start
-	c.updateMetrics()
+	c.RecordPoll(c.updateMetrics())
 
 	// Mark as ready after first update
 	c.SetReady()
@@ -123,7 +143,7 @@ func (c *Collector) startMetricsCollection(ctx context.Context) {
 		case <-c.stopCh:
 			return
 		case <-ticker.C:
-			c.updateMetrics()
+			c.RecordPoll(c.updateMetrics())
 		}
 	}
 }
@@ -135,6 +155,5 @@ func (c *Collector) Interval() time.Duration {
 
 // Poll executes one polling cycle
 func (c *Collector) Poll(ctx context.Context) error {
-	c.updateMetrics()
-	return nil
+	return c.updateMetrics()
 }
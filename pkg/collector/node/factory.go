@@ -17,7 +17,12 @@ import (
 const collectorName = "node"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Kubernetes node metrics"),
+		registry.WithRBAC([]string{""}, []string{"nodes"}, []string{"list", "watch"}),
+	)
 }
 
 // NewCollector creates a new Node collector
@@ -43,6 +48,8 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			collectorName,
 			factoryCtx.Logger,
 			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
 		),
 		client: client,
 		config: cfg,
@@ -51,6 +58,8 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 		logger: factoryCtx.Logger,
 	}
 
+	c.mu.Instrument(c.BaseCollector)
+
 	c.initMetrics(factoryCtx.MetricsNamespace)
 
 	// Set lifecycle hooks
@@ -81,9 +90,18 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 						// Keep UID for proper object tracking
 						UID: node.UID,
 					},
+					Spec: corev1.NodeSpec{
+						Unschedulable: node.Spec.Unschedulable,
+						Taints:        node.Spec.Taints,
+					},
 					Status: corev1.NodeStatus{
-						// Only keep conditions
 						Conditions: node.Status.Conditions,
+						NodeInfo: corev1.NodeSystemInfo{
+							KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+							//nolint:staticcheck // Deprecated, still reported by older kubelets
+							KubeProxyVersion:        node.Status.NodeInfo.KubeProxyVersion,
+							ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+						},
 					},
 				}
 
@@ -91,8 +109,10 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			})
 
 			// Add event handlers
+			c.InstrumentInformer("nodes", c.informer)
+
 			//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
-			c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			c.informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
 				AddFunc: func(obj any) {
 					node, ok := obj.(*corev1.Node)
 					if !ok {
@@ -141,7 +161,7 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 					c.mu.Unlock()
 					c.logger.WithField("node", node.Name).Debug("Node deleted")
 				},
-			})
+			}))
 
 			// Start informer
 			factory.Start(c.stopCh)
@@ -153,6 +173,8 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 				return errors.New("failed to sync node informer cache")
 			}
 
+			c.WatchInformers(c.informer)
+
 			c.logger.Info("Node collector started successfully")
 
 			c.SetReady()
@@ -1,7 +1,6 @@
 package node
 
 import (
-	"sync"
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
@@ -22,12 +21,15 @@ type Collector struct {
 	stopCh   chan struct{}
 	logger   *log.Entry
 
-	mu    sync.RWMutex
+	mu    base.RWMutex
 	nodes map[string]*corev1.Node
 
 	// Metrics
-	nodeHealthy   *prometheus.Desc
-	nodeCondition *prometheus.Desc
+	nodeHealthy       *prometheus.Desc
+	nodeCondition     *prometheus.Desc
+	nodeUnschedulable *prometheus.Desc
+	nodeTaint         *prometheus.Desc
+	nodeInfo          *prometheus.Desc
 }
 
 // initMetrics initializes Prometheus metric descriptors
@@ -45,9 +47,31 @@ func (c *Collector) initMetrics(namespace string) {
 		nil,
 	)
 
+	c.nodeUnschedulable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "unschedulable"),
+		"Whether the node is cordoned (1=unschedulable, 0=schedulable)",
+		[]string{"node"},
+		nil,
+	)
+	c.nodeTaint = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "taint"),
+		"Taint of the node (always 1)",
+		[]string{"node", "key", "value", "effect"},
+		nil,
+	)
+	c.nodeInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "info"),
+		"Versions of the kubelet, kube-proxy and container runtime of the node (always 1)",
+		[]string{"node", "kubelet_version", "kube_proxy_version", "container_runtime_version"},
+		nil,
+	)
+
 	// Register descriptors
 	c.MustRegisterDesc(c.nodeHealthy)
 	c.MustRegisterDesc(c.nodeCondition)
+	c.MustRegisterDesc(c.nodeUnschedulable)
+	c.MustRegisterDesc(c.nodeTaint)
+	c.MustRegisterDesc(c.nodeInfo)
 }
 
 // HasSynced returns true if the informer has synced
@@ -64,7 +88,9 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	ignoreThreshold := now.Add(-c.config.IgnoreNewNodeDuration)
 
 	for _, node := range c.nodes {
-		// Skip new nodes if configured
+		c.collectScheduling(ch, node)
+
+		// Skip the health of new nodes if configured
 		if node.CreationTimestamp.After(ignoreThreshold) {
 			c.logger.WithFields(log.Fields{
 				"node": node.Name,
@@ -117,6 +143,41 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// collectScheduling emits the cordon state, taints and versions of a node,
+// also reported for new nodes
+func (c *Collector) collectScheduling(ch chan<- prometheus.Metric, node *corev1.Node) {
+	ch <- prometheus.MustNewConstMetric(
+		c.nodeUnschedulable,
+		prometheus.GaugeValue,
+		boolToFloat64(node.Spec.Unschedulable),
+		node.Name,
+	)
+
+	for _, taint := range node.Spec.Taints {
+		ch <- prometheus.MustNewConstMetric(
+			c.nodeTaint,
+			prometheus.GaugeValue,
+			1,
+			node.Name,
+			taint.Key,
+			taint.Value,
+			string(taint.Effect),
+		)
+	}
+
+	info := node.Status.NodeInfo
+
+	ch <- prometheus.MustNewConstMetric(
+		c.nodeInfo,
+		prometheus.GaugeValue,
+		1,
+		node.Name,
+		info.KubeletVersion,
+		info.KubeProxyVersion, //nolint:staticcheck // Deprecated, still reported by older kubelets
+		info.ContainerRuntimeVersion,
+	)
+}
+
 // isNodeHealthy checks if a node is healthy
 // A node is considered healthy if:
 // - Ready condition is True
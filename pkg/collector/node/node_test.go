@@ -0,0 +1,74 @@
+//nolint:testpackage // Tests need access to private functions
+package node
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCollectScheduling(t *testing.T) {
+	logger := log.NewEntry(log.StandardLogger())
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        &Config{IgnoreNewNodeDuration: 30 * time.Minute},
+		logger:        logger,
+		nodes: map[string]*corev1.Node{
+			"worker-1": {
+				// New nodes still report their scheduling state
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-1", CreationTimestamp: metav1.Now()},
+				Spec: corev1.NodeSpec{
+					Unschedulable: true,
+					Taints: []corev1.Taint{
+						{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+						{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoExecute},
+					},
+				},
+				Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+					KubeletVersion:          "v1.30.2",
+					ContainerRuntimeVersion: "containerd://1.7.18",
+				}},
+			},
+		},
+	}
+	c.initMetrics("sealos")
+
+	ch := make(chan prometheus.Metric, 10)
+	c.collect(ch)
+	close(ch)
+
+	var series []string
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+
+		pairs := make([]string, 0, len(m.GetLabel()))
+		for _, label := range m.GetLabel() {
+			pairs = append(pairs, label.GetName()+"="+label.GetValue())
+		}
+
+		series = append(series, strings.Join(pairs, ",")+" "+strconv.FormatFloat(m.GetGauge().GetValue(), 'g', -1, 64))
+	}
+
+	expected := []string{
+		"node=worker-1 1",
+		"effect=NoSchedule,key=node.kubernetes.io/unschedulable,node=worker-1,value= 1",
+		"effect=NoExecute,key=dedicated,node=worker-1,value=gpu 1",
+		"container_runtime_version=containerd://1.7.18,kube_proxy_version=,kubelet_version=v1.30.2,node=worker-1 1",
+	}
+
+	if strings.Join(series, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected series:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(series, "\n"))
+	}
+}
@@ -0,0 +1,26 @@
+package openebs
+
+import "time"
+
+// Config contains configuration for the OpenEBS LVM collector
+type Config struct {
+	// Namespace of the LVMNode and LVMVolume resources (the OpenEBS install namespace)
+	Namespace string `yaml:"namespace" env:"NAMESPACE"`
+
+	// ResyncPeriod is the resync interval of the informers
+	ResyncPeriod time.Duration `yaml:"resyncPeriod" env:"RESYNC_PERIOD"`
+
+	// Volumes also watches the LVMVolumes to export the capacity provisioned
+	// per volume group and the thin pool overprovisioning ratio
+	Volumes bool `yaml:"volumes" env:"VOLUMES"`
+}
+
+// NewDefaultConfig returns the default configuration for the OpenEBS LVM collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespace:    "openebs",
+		ResyncPeriod: 10 * time.Minute,
+		Volumes:      true,
+	}
+}
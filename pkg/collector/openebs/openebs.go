@@ -0,0 +1,262 @@
+package openebs
+
+import (
+	"sync"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	dynamiccollector "github.com/labring/sealos-state-metrics/pkg/collector/dynamic"
+	"github.com/labring/sealos-state-metrics/pkg/lvm"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// volumeStateFailed is the state of LVMVolumes whose creation failed, which
+// hold no space
+const volumeStateFailed = "Failed"
+
+// thinProvisionYes marks thin provisioned LVMVolumes
+const thinProvisionYes = "yes"
+
+// volumeGroup is the state of a volume group reported by an LVMNode
+type volumeGroup struct {
+	name       string
+	size       float64
+	free       float64
+	lvCount    float64
+	missingPVs float64
+}
+
+// volume is the placement and capacity of an LVMVolume
+type volume struct {
+	node     string
+	vg       string
+	capacity float64
+	thin     bool
+}
+
+// poolKey identifies a volume group of a node
+type poolKey struct {
+	node string
+	vg   string
+}
+
+// Collector collects the volume groups of the OpenEBS LVM-LocalPV nodes
+type Collector struct {
+	*base.BaseCollector
+
+	client      dynamic.Interface
+	config      *Config
+	controllers []*dynamiccollector.Controller
+	logger      *log.Entry
+
+	mu      sync.RWMutex
+	nodes   map[string][]volumeGroup // key: node name
+	volumes map[string]volume        // key: namespace/name
+
+	// Metrics
+	vgSize                 *prometheus.Desc
+	vgFree                 *prometheus.Desc
+	vgLVCount              *prometheus.Desc
+	vgMissingPVs           *prometheus.Desc
+	vgProvisioned          *prometheus.Desc
+	vgThinOverprovisioning *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.vgSize = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "openebs_lvm", "vg_size_bytes"),
+		"Size of the volume group reported by the LVMNode in bytes",
+		[]string{"node", "vg"},
+		nil,
+	)
+	c.vgFree = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "openebs_lvm", "vg_free_bytes"),
+		"Free space of the volume group reported by the LVMNode in bytes",
+		[]string{"node", "vg"},
+		nil,
+	)
+	c.vgLVCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "openebs_lvm", "vg_lv_count"),
+		"Number of logical volumes in the volume group",
+		[]string{"node", "vg"},
+		nil,
+	)
+	c.vgMissingPVs = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "openebs_lvm", "vg_missing_pvs"),
+		"Number of physical volumes of the volume group that are missing",
+		[]string{"node", "vg"},
+		nil,
+	)
+	c.vgProvisioned = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "openebs_lvm", "vg_provisioned_bytes"),
+		"Capacity of the LVMVolumes provisioned in the volume group in bytes, thin is true for thin provisioned ones",
+		[]string{"node", "vg", "thin"},
+		nil,
+	)
+	c.vgThinOverprovisioning = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "openebs_lvm", "vg_thin_overprovisioning_ratio"),
+		"Capacity of the thin provisioned LVMVolumes of the volume group divided by its size",
+		[]string{"node", "vg"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.vgSize)
+	c.MustRegisterDesc(c.vgFree)
+	c.MustRegisterDesc(c.vgLVCount)
+	c.MustRegisterDesc(c.vgMissingPVs)
+
+	if c.config.Volumes {
+		c.MustRegisterDesc(c.vgProvisioned)
+		c.MustRegisterDesc(c.vgThinOverprovisioning)
+	}
+}
+
+// HasSynced returns true if the informers of all controllers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.controllers) == 0 {
+		return false
+	}
+
+	for _, controller := range c.controllers {
+		if !controller.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handleNode stores the volume groups of an LVMNode, named after its node
+func (c *Collector) handleNode(obj *unstructured.Unstructured) {
+	var node struct {
+		VolumeGroups []lvm.VolumeGroup `json:"volumeGroups"`
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &node); err != nil {
+		c.logger.WithError(err).WithField("node", obj.GetName()).Warn("Failed to decode LVMNode")
+		return
+	}
+
+	vgs := make([]volumeGroup, 0, len(node.VolumeGroups))
+	for _, vg := range node.VolumeGroups {
+		vgs = append(vgs, volumeGroup{
+			name:       vg.Name,
+			size:       float64(vg.Size.Value()),
+			free:       float64(vg.Free.Value()),
+			lvCount:    float64(vg.LVCount),
+			missingPVs: float64(vg.MissingPVCount),
+		})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nodes[obj.GetName()] = vgs
+}
+
+// handleNodeDelete removes the volume groups of a deleted LVMNode
+func (c *Collector) handleNodeDelete(obj *unstructured.Unstructured) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.nodes, obj.GetName())
+}
+
+// handleVolume stores the placement and capacity of an LVMVolume. Failed
+// volumes are dropped, since they hold no space.
+func (c *Collector) handleVolume(obj *unstructured.Unstructured) {
+	key := obj.GetNamespace() + "/" + obj.GetName()
+
+	var lvmVolume struct {
+		Spec   lvm.VolumeInfo `json:"spec"`
+		Status struct {
+			State string `json:"state"`
+		} `json:"status"`
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &lvmVolume); err != nil {
+		c.logger.WithError(err).WithField("volume", key).Warn("Failed to decode LVMVolume")
+		return
+	}
+
+	capacity, err := resource.ParseQuantity(lvmVolume.Spec.Capacity)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Volumes still being scheduled have no volume group yet
+	if err != nil || lvmVolume.Status.State == volumeStateFailed ||
+		lvmVolume.Spec.OwnerNodeID == "" || lvmVolume.Spec.VolGroup == "" {
+		delete(c.volumes, key)
+		return
+	}
+
+	c.volumes[key] = volume{
+		node:     lvmVolume.Spec.OwnerNodeID,
+		vg:       lvmVolume.Spec.VolGroup,
+		capacity: float64(capacity.Value()),
+		thin:     lvmVolume.Spec.ThinProvision == thinProvisionYes,
+	}
+}
+
+// handleVolumeDelete removes a deleted LVMVolume
+func (c *Collector) handleVolumeDelete(obj *unstructured.Unstructured) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.volumes, obj.GetNamespace()+"/"+obj.GetName())
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sizes := make(map[poolKey]float64)
+
+	for node, vgs := range c.nodes {
+		for _, vg := range vgs {
+			sizes[poolKey{node: node, vg: vg.name}] = vg.size
+
+			ch <- prometheus.MustNewConstMetric(c.vgSize, prometheus.GaugeValue, vg.size, node, vg.name)
+			ch <- prometheus.MustNewConstMetric(c.vgFree, prometheus.GaugeValue, vg.free, node, vg.name)
+			ch <- prometheus.MustNewConstMetric(c.vgLVCount, prometheus.GaugeValue, vg.lvCount, node, vg.name)
+			ch <- prometheus.MustNewConstMetric(c.vgMissingPVs, prometheus.GaugeValue, vg.missingPVs, node, vg.name)
+		}
+	}
+
+	if !c.config.Volumes {
+		return
+	}
+
+	thick := make(map[poolKey]float64)
+	thin := make(map[poolKey]float64)
+
+	for _, v := range c.volumes {
+		key := poolKey{node: v.node, vg: v.vg}
+		if v.thin {
+			thin[key] += v.capacity
+		} else {
+			thick[key] += v.capacity
+		}
+	}
+
+	for key, provisioned := range thick {
+		ch <- prometheus.MustNewConstMetric(c.vgProvisioned, prometheus.GaugeValue, provisioned, key.node, key.vg, "false")
+	}
+
+	for key, provisioned := range thin {
+		ch <- prometheus.MustNewConstMetric(c.vgProvisioned, prometheus.GaugeValue, provisioned, key.node, key.vg, "true")
+
+		// The ratio needs the size reported by the LVMNode
+		if size := sizes[key]; size > 0 {
+			ch <- prometheus.MustNewConstMetric(c.vgThinOverprovisioning, prometheus.GaugeValue, provisioned/size, key.node, key.vg)
+		}
+	}
+}
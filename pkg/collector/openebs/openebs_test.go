@@ -0,0 +1,117 @@
+//nolint:testpackage // Tests need access to private functions
+package openebs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestCollector() *Collector {
+	logger := log.NewEntry(log.New())
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        NewDefaultConfig(),
+		nodes:         make(map[string][]volumeGroup),
+		volumes:       make(map[string]volume),
+		logger:        logger,
+	}
+	c.initMetrics("sealos")
+
+	return c
+}
+
+// collectorFunc adapts a collect function to a prometheus.Collector
+type collectorFunc func(ch chan<- prometheus.Metric)
+
+func (f collectorFunc) Describe(ch chan<- *prometheus.Desc) { prometheus.DescribeByCollect(f, ch) }
+
+func (f collectorFunc) Collect(ch chan<- prometheus.Metric) { f(ch) }
+
+func lvmVolume(name, node, vg, capacity, thin, state string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"namespace": "openebs", "name": name},
+		"spec": map[string]any{
+			"ownerNodeID":   node,
+			"volGroup":      vg,
+			"capacity":      capacity,
+			"thinProvision": thin,
+		},
+		"status": map[string]any{"state": state},
+	}}
+}
+
+func TestCollectVolumeGroups(t *testing.T) {
+	c := newTestCollector()
+
+	c.handleNode(&unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"namespace": "openebs", "name": "node-1"},
+		"volumeGroups": []any{
+			map[string]any{
+				"name":           "lvmvg",
+				"uuid":           "abc",
+				"size":           "100Gi",
+				"free":           "40Gi",
+				"lvCount":        int64(3),
+				"pvCount":        int64(1),
+				"missingPvCount": int64(0),
+			},
+		},
+	}})
+
+	c.handleVolume(lvmVolume("pvc-a", "node-1", "lvmvg", "107374182400", "yes", "Ready"))
+	c.handleVolume(lvmVolume("pvc-b", "node-1", "lvmvg", "50Gi", "yes", "Ready"))
+	c.handleVolume(lvmVolume("pvc-c", "node-1", "lvmvg", "10Gi", "no", "Ready"))
+	c.handleVolume(lvmVolume("pvc-d", "node-1", "lvmvg", "10Gi", "no", volumeStateFailed))
+	// Pending volumes have no volume group yet
+	c.handleVolume(lvmVolume("pvc-e", "", "", "10Gi", "no", "Pending"))
+
+	expected := `
+# HELP sealos_openebs_lvm_vg_free_bytes Free space of the volume group reported by the LVMNode in bytes
+# TYPE sealos_openebs_lvm_vg_free_bytes gauge
+sealos_openebs_lvm_vg_free_bytes{node="node-1",vg="lvmvg"} 4.294967296e+10
+# HELP sealos_openebs_lvm_vg_provisioned_bytes Capacity of the LVMVolumes provisioned in the volume group in bytes, thin is true for thin provisioned ones
+# TYPE sealos_openebs_lvm_vg_provisioned_bytes gauge
+sealos_openebs_lvm_vg_provisioned_bytes{node="node-1",thin="false",vg="lvmvg"} 1.073741824e+10
+sealos_openebs_lvm_vg_provisioned_bytes{node="node-1",thin="true",vg="lvmvg"} 1.61061273600e+11
+# HELP sealos_openebs_lvm_vg_size_bytes Size of the volume group reported by the LVMNode in bytes
+# TYPE sealos_openebs_lvm_vg_size_bytes gauge
+sealos_openebs_lvm_vg_size_bytes{node="node-1",vg="lvmvg"} 1.073741824e+11
+# HELP sealos_openebs_lvm_vg_thin_overprovisioning_ratio Capacity of the thin provisioned LVMVolumes of the volume group divided by its size
+# TYPE sealos_openebs_lvm_vg_thin_overprovisioning_ratio gauge
+sealos_openebs_lvm_vg_thin_overprovisioning_ratio{node="node-1",vg="lvmvg"} 1.5
+`
+	if err := testutil.CollectAndCompare(collectorFunc(c.collect), strings.NewReader(expected),
+		"sealos_openebs_lvm_vg_free_bytes",
+		"sealos_openebs_lvm_vg_provisioned_bytes",
+		"sealos_openebs_lvm_vg_size_bytes",
+		"sealos_openebs_lvm_vg_thin_overprovisioning_ratio",
+	); err != nil {
+		t.Error(err)
+	}
+
+	// A volume failing after its creation no longer counts, and without the
+	// LVMNode the ratio cannot be computed
+	c.handleVolume(lvmVolume("pvc-c", "node-1", "lvmvg", "10Gi", "no", volumeStateFailed))
+	c.handleNodeDelete(&unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"namespace": "openebs", "name": "node-1"},
+	}})
+
+	expected = `
+# HELP sealos_openebs_lvm_vg_provisioned_bytes Capacity of the LVMVolumes provisioned in the volume group in bytes, thin is true for thin provisioned ones
+# TYPE sealos_openebs_lvm_vg_provisioned_bytes gauge
+sealos_openebs_lvm_vg_provisioned_bytes{node="node-1",thin="true",vg="lvmvg"} 1.61061273600e+11
+`
+	if err := testutil.CollectAndCompare(collectorFunc(c.collect), strings.NewReader(expected),
+		"sealos_openebs_lvm_vg_provisioned_bytes",
+		"sealos_openebs_lvm_vg_thin_overprovisioning_ratio",
+	); err != nil {
+		t.Error(err)
+	}
+}
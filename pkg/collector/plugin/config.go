@@ -0,0 +1,68 @@
+package plugin
+
+import "time"
+
+// Config contains configuration for the plugin collector
+type Config struct {
+	// Address of the plugin, host:port or unix:///path/to/socket
+	Address       string        `yaml:"address"       env:"ADDRESS"`
+	CheckInterval time.Duration `yaml:"checkInterval" env:"CHECK_INTERVAL"`
+	CycleTimeout  time.Duration `yaml:"cycleTimeout"  env:"CYCLE_TIMEOUT"` // Collector-level timeout of a poll cycle (0 = global only)
+	// LeaderElection collects the plugin only on the leader; disable it for
+	// node-level plugins run next to every exporter instance
+	LeaderElection bool `yaml:"leaderElection" env:"LEADER_ELECTION"`
+
+	// Endpoints are the plugins created when the collector is enabled as
+	// "plugin", each as a plugin:<name> instance with its own lifecycle and
+	// leader election. Fields an endpoint does not set default to the ones above.
+	Endpoints []Endpoint `yaml:"endpoints"`
+}
+
+// Endpoint is a plugin configured in the endpoints of the collector
+type Endpoint struct {
+	Name           string        `yaml:"name"`
+	Address        string        `yaml:"address"`
+	CheckInterval  time.Duration `yaml:"checkInterval"`
+	CycleTimeout   time.Duration `yaml:"cycleTimeout"`
+	LeaderElection *bool         `yaml:"leaderElection"`
+}
+
+// NewDefaultConfig returns the default configuration for the plugin collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		CheckInterval:  30 * time.Second,
+		CycleTimeout:   10 * time.Second,
+		LeaderElection: true,
+	}
+}
+
+// applyEndpoint overrides the configuration with the fields set by the
+// endpoint of the instance, if any. Instances configured in their own
+// plugin:<name> section have no endpoint.
+func (c *Config) applyEndpoint(instance string) {
+	for i := range c.Endpoints {
+		endpoint := &c.Endpoints[i]
+		if endpoint.Name != instance {
+			continue
+		}
+
+		if endpoint.Address != "" {
+			c.Address = endpoint.Address
+		}
+
+		if endpoint.CheckInterval > 0 {
+			c.CheckInterval = endpoint.CheckInterval
+		}
+
+		if endpoint.CycleTimeout > 0 {
+			c.CycleTimeout = endpoint.CycleTimeout
+		}
+
+		if endpoint.LeaderElection != nil {
+			c.LeaderElection = *endpoint.LeaderElection
+		}
+
+		return
+	}
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	pluginapi "github.com/labring/sealos-state-metrics/pkg/plugin"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+)
+
+const collectorName = "plugin"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Metrics of out-of-tree collectors served over the CollectorPlugin gRPC protocol"),
+		registry.WithStandalone(),
+		registry.WithConfigInstances(endpointNames),
+	)
+}
+
+// endpointNames returns the names of the configured endpoints, created as
+// instances of the collector
+func endpointNames(loader collector.ConfigLoader) ([]string, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.plugin", cfg); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(cfg.Endpoints))
+	for i := range cfg.Endpoints {
+		names = append(names, cfg.Endpoints[i].Name)
+	}
+
+	return names, nil
+}
+
+// NewCollector creates a new plugin collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.plugin", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load plugin collector config, using defaults")
+	}
+
+	cfg.applyEndpoint(factoryCtx.Instance)
+
+	if cfg.Address == "" {
+		return nil, errors.New("plugin address is required")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithLeaderElection(cfg.LeaderElection),
+			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, cfg.CycleTimeout),
+		),
+		config:    cfg,
+		namespace: factoryCtx.MetricsNamespace,
+		logger:    factoryCtx.Logger.WithField("address", cfg.Address),
+	}
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// The connection is closed on stop, a new one is made on each start
+			client, err := pluginapi.NewClient(cfg.Address)
+			if err != nil {
+				return err
+			}
+
+			c.mu.Lock()
+			c.client = client
+			c.metrics = nil
+			c.pluginErr = nil
+			c.mu.Unlock()
+
+			// Start polling goroutine
+			go c.pollLoop(ctx)
+
+			c.logger.Info("Plugin collector started successfully")
+
+			return nil
+		},
+		StopFunc: func() error {
+			c.mu.RLock()
+			client := c.client
+			c.mu.RUnlock()
+
+			return client.Close()
+		},
+		CollectFunc: c.collect,
+		HealthFunc:  c.health,
+	})
+
+	return c, nil
+}
@@ -0,0 +1,273 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	pluginapi "github.com/labring/sealos-state-metrics/pkg/plugin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Collector exposes the metric families of an external collector plugin
+type Collector struct {
+	*base.BaseCollector
+
+	config    *Config
+	namespace string
+	logger    *log.Entry
+
+	mu sync.RWMutex
+	// client of the plugin, created on start
+	client *pluginapi.Client
+	// metrics of the last successful poll, kept when the plugin is unreachable
+	metrics []prometheus.Metric
+	// pluginErr is the error of the last poll or the health reported by the plugin
+	pluginErr error
+}
+
+// HasSynced returns true (polling collector is always synced)
+func (c *Collector) HasSynced() bool {
+	return true
+}
+
+// Interval returns the polling interval
+func (c *Collector) Interval() time.Duration {
+	return c.config.CheckInterval
+}
+
+// Poll checks the health of the plugin and collects its metric families.
+// Families are described on every poll so plugin upgrades adding families
+// are picked up without restarting the exporter.
+func (c *Collector) Poll(ctx context.Context) error {
+	c.mu.RLock()
+	client := c.client
+	c.mu.RUnlock()
+
+	healthErr := client.Health(ctx)
+
+	metrics, err := c.collectPlugin(ctx, client)
+
+	c.mu.Lock()
+	c.pluginErr = errors.Join(healthErr, err)
+
+	if err == nil {
+		c.metrics = metrics
+	}
+	c.mu.Unlock()
+
+	if healthErr != nil {
+		c.logger.WithError(healthErr).Warn("Plugin is unhealthy")
+	}
+
+	return err
+}
+
+// collectPlugin returns the metrics of the described families collected by
+// the plugin. Invalid families and metrics are logged and dropped.
+func (c *Collector) collectPlugin(ctx context.Context, client *pluginapi.Client) ([]prometheus.Metric, error) {
+	described, err := client.Describe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe plugin metrics: %w", err)
+	}
+
+	collected, err := client.Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect plugin metrics: %w", err)
+	}
+
+	metrics, err := buildMetrics(c.namespace, described, collected)
+	if err != nil {
+		c.logger.WithError(err).Warn("Dropped invalid plugin metrics")
+	}
+
+	return metrics, nil
+}
+
+// pollLoop runs the polling loop
+func (c *Collector) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.config.CheckInterval)
+	defer ticker.Stop()
+
+	// Do initial poll
+	c.pollOnce(ctx)
+
+	// Mark as ready after first poll completes
+	c.SetReady()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.pollOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollOnce runs one poll cycle
+func (c *Collector) pollOnce(ctx context.Context) {
+	if err := c.PollOnce(ctx, c.Poll); err != nil {
+		c.logger.WithError(err).Warn("Failed to poll plugin")
+	}
+}
+
+// health returns the error of the last poll or the health reported by the plugin
+func (c *Collector) health() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.pluginErr
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// buildMetrics converts the collected families to const metrics named under
+// the metrics namespace. Families must be described, with the same type; the
+// help text is the described one.
+func buildMetrics(namespace string, described, collected []*dto.MetricFamily) ([]prometheus.Metric, error) {
+	descriptions := make(map[string]*dto.MetricFamily, len(described))
+	for _, family := range described {
+		descriptions[family.GetName()] = family
+	}
+
+	var (
+		metrics []prometheus.Metric
+		errs    []error
+	)
+
+	for _, family := range collected {
+		name := family.GetName()
+
+		description, ok := descriptions[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("family %s was not described", name))
+			continue
+		}
+
+		if family.GetType() != description.GetType() {
+			errs = append(errs, fmt.Errorf("family %s is described as %s but collected as %s",
+				name, description.GetType(), family.GetType()))
+
+			continue
+		}
+
+		fqName := prometheus.BuildFQName(namespace, "", name)
+
+		// Metrics of a family may have different label names
+		descs := make(map[string]*prometheus.Desc)
+
+		for _, m := range family.GetMetric() {
+			labelNames, labelValues := metricLabels(m)
+
+			key := strings.Join(labelNames, ",")
+
+			desc, ok := descs[key]
+			if !ok {
+				desc = prometheus.NewDesc(fqName, description.GetHelp(), labelNames, nil)
+				descs[key] = desc
+			}
+
+			metric, err := constMetric(desc, family.GetType(), m, labelValues)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("family %s: %w", name, err))
+				continue
+			}
+
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return metrics, errors.Join(errs...)
+}
+
+// metricLabels returns the label names of a metric, sorted, and their values
+func metricLabels(m *dto.Metric) (names, values []string) {
+	labels := slices.Clone(m.GetLabel())
+	slices.SortFunc(labels, func(a, b *dto.LabelPair) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	names = make([]string, 0, len(labels))
+	values = make([]string, 0, len(labels))
+
+	for _, label := range labels {
+		names = append(names, label.GetName())
+		values = append(values, label.GetValue())
+	}
+
+	return names, values
+}
+
+// constMetric converts a metric of the given type
+func constMetric(
+	desc *prometheus.Desc,
+	metricType dto.MetricType,
+	m *dto.Metric,
+	labelValues []string,
+) (prometheus.Metric, error) {
+	var (
+		metric prometheus.Metric
+		err    error
+	)
+
+	switch metricType {
+	case dto.MetricType_GAUGE:
+		metric, err = prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), labelValues...)
+	case dto.MetricType_COUNTER:
+		metric, err = prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), labelValues...)
+	case dto.MetricType_UNTYPED:
+		metric, err = prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), labelValues...)
+	case dto.MetricType_HISTOGRAM:
+		histogram := m.GetHistogram()
+
+		// The +Inf bucket is implied by the sample count
+		buckets := make(map[float64]uint64, len(histogram.GetBucket()))
+		for _, bucket := range histogram.GetBucket() {
+			if !math.IsInf(bucket.GetUpperBound(), 1) {
+				buckets[bucket.GetUpperBound()] = bucket.GetCumulativeCount()
+			}
+		}
+
+		metric, err = prometheus.NewConstHistogram(
+			desc, histogram.GetSampleCount(), histogram.GetSampleSum(), buckets, labelValues...)
+	case dto.MetricType_SUMMARY:
+		summary := m.GetSummary()
+
+		quantiles := make(map[float64]float64, len(summary.GetQuantile()))
+		for _, quantile := range summary.GetQuantile() {
+			quantiles[quantile.GetQuantile()] = quantile.GetValue()
+		}
+
+		metric, err = prometheus.NewConstSummary(
+			desc, summary.GetSampleCount(), summary.GetSampleSum(), quantiles, labelValues...)
+	default:
+		return nil, fmt.Errorf("unsupported metric type %s", metricType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m.TimestampMs != nil {
+		metric = prometheus.NewMetricWithTimestamp(time.UnixMilli(m.GetTimestampMs()), metric)
+	}
+
+	return metric, nil
+}
@@ -0,0 +1,261 @@
+//nolint:testpackage // Tests need access to private functions
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/config"
+	pluginapi "github.com/labring/sealos-state-metrics/pkg/plugin"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakePlugin struct {
+	described []*dto.MetricFamily
+	collected []*dto.MetricFamily
+	healthErr error
+}
+
+func (p *fakePlugin) Describe(context.Context) ([]*dto.MetricFamily, error) {
+	return p.described, nil
+}
+
+func (p *fakePlugin) Collect(context.Context) ([]*dto.MetricFamily, error) {
+	return p.collected, nil
+}
+
+func (p *fakePlugin) Health(context.Context) error {
+	return p.healthErr
+}
+
+// servePlugin serves a plugin on a local TCP listener and returns its address
+// and server
+func servePlugin(t *testing.T, p pluginapi.Plugin) (string, *grpc.Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	srv := pluginapi.NewServer(p)
+
+	go func() { _ = srv.Serve(listener) }()
+
+	t.Cleanup(srv.Stop)
+
+	return listener.Addr().String(), srv
+}
+
+func family(name string, metricType dto.MetricType, metrics ...*dto.Metric) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name:   proto.String(name),
+		Help:   proto.String("Help of " + name),
+		Type:   metricType.Enum(),
+		Metric: metrics,
+	}
+}
+
+func gauge(value float64, labels ...string) *dto.Metric {
+	m := &dto.Metric{Gauge: &dto.Gauge{Value: proto.Float64(value)}}
+	for i := 0; i+1 < len(labels); i += 2 {
+		m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(labels[i]), Value: proto.String(labels[i+1])})
+	}
+
+	return m
+}
+
+func TestBuildMetrics(t *testing.T) {
+	described := []*dto.MetricFamily{
+		family("billing_orders", dto.MetricType_GAUGE),
+		family("billing_latency_seconds", dto.MetricType_HISTOGRAM),
+		family("billing_payments_total", dto.MetricType_COUNTER),
+	}
+
+	collected := []*dto.MetricFamily{
+		family("billing_orders", dto.MetricType_GAUGE,
+			gauge(3, "zone", "b", "region", "hz"),
+			gauge(1),
+			gauge(2, "region", "hz", "region", "sh"),
+		),
+		family("billing_latency_seconds", dto.MetricType_HISTOGRAM, &dto.Metric{Histogram: &dto.Histogram{
+			SampleCount: proto.Uint64(3),
+			SampleSum:   proto.Float64(1.5),
+			Bucket: []*dto.Bucket{
+				{UpperBound: proto.Float64(0.5), CumulativeCount: proto.Uint64(2)},
+				{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(3)},
+			},
+		}}),
+		family("billing_payments_total", dto.MetricType_GAUGE, gauge(42)),
+		family("billing_undescribed", dto.MetricType_GAUGE, gauge(1)),
+	}
+
+	metrics, err := buildMetrics("sealos", described, collected)
+
+	for _, dropped := range []string{"duplicate label", "billing_payments_total", "billing_undescribed"} {
+		if err == nil || !strings.Contains(err.Error(), dropped) {
+			t.Errorf("Expected error about %s, got %v", dropped, err)
+		}
+	}
+
+	if len(metrics) != 3 {
+		t.Fatalf("Expected 3 metrics, got %d", len(metrics))
+	}
+
+	if desc := metrics[0].Desc().String(); !strings.Contains(desc, `"sealos_billing_orders"`) ||
+		!strings.Contains(desc, "{region,zone}") {
+		t.Errorf("Expected namespaced name and sorted labels, got %s", desc)
+	}
+
+	out := &dto.Metric{}
+	if err := metrics[0].Write(out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if out.GetGauge().GetValue() != 3 || out.GetLabel()[0].GetValue() != "hz" {
+		t.Errorf("Unexpected metric %v", out)
+	}
+
+	if err := metrics[2].Write(out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if out.GetHistogram().GetSampleCount() != 3 || len(out.GetHistogram().GetBucket()) != 2 {
+		t.Errorf("Unexpected histogram %v", out.GetHistogram())
+	}
+}
+
+func TestPoll(t *testing.T) {
+	p := &fakePlugin{
+		described: []*dto.MetricFamily{family("billing_orders", dto.MetricType_GAUGE)},
+		collected: []*dto.MetricFamily{family("billing_orders", dto.MetricType_GAUGE, gauge(3))},
+	}
+
+	address, srv := servePlugin(t, p)
+
+	client, err := pluginapi.NewClient(address)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	c := &Collector{
+		client:    client,
+		config:    NewDefaultConfig(),
+		namespace: "sealos",
+		logger:    log.NewEntry(log.StandardLogger()),
+	}
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	if len(c.metrics) != 1 || c.health() != nil {
+		t.Fatalf("Expected 1 metric and a healthy plugin, got %d, %v", len(c.metrics), c.health())
+	}
+
+	// An unhealthy plugin is still collected
+	p.healthErr = errors.New("queue is stuck")
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	if err := c.health(); err == nil || !strings.Contains(err.Error(), "queue is stuck") {
+		t.Errorf("Expected plugin health error, got %v", err)
+	}
+
+	// Metrics of the last successful poll are kept while the plugin is unreachable
+	srv.Stop()
+
+	if err := c.Poll(context.Background()); err == nil {
+		t.Fatal("Expected poll error with the plugin down")
+	}
+
+	if len(c.metrics) != 1 || c.health() == nil {
+		t.Errorf("Expected kept metrics and an error, got %d, %v", len(c.metrics), c.health())
+	}
+}
+
+func TestEndpointInstance(t *testing.T) {
+	p := &fakePlugin{
+		described: []*dto.MetricFamily{family("billing_orders", dto.MetricType_GAUGE)},
+		collected: []*dto.MetricFamily{family("billing_orders", dto.MetricType_GAUGE, gauge(3))},
+	}
+
+	address, _ := servePlugin(t, p)
+
+	content := []byte(`
+collectors:
+  plugin:
+    endpoints:
+      - name: billing
+        address: ` + address + `
+      - name: node-agent
+        address: unix:///var/run/node-agent/metrics.sock
+        leaderElection: false
+`)
+	loader := config.NewModuleConfigLoader(content)
+
+	names, err := endpointNames(loader)
+	if err != nil || len(names) != 2 || names[0] != "billing" || names[1] != "node-agent" {
+		t.Fatalf("Expected the endpoint names, got %v, %v", names, err)
+	}
+
+	newInstance := func(instance string) *Collector {
+		t.Helper()
+
+		c, err := NewCollector(&collector.FactoryContext{
+			ConfigLoader:     config.NewInstanceConfigLoader(loader, "collectors.plugin", "collectors.plugin:"+instance),
+			Instance:         instance,
+			MetricsNamespace: "sealos",
+			Logger:           log.NewEntry(log.StandardLogger()),
+		})
+		if err != nil {
+			t.Fatalf("NewCollector(%s) error = %v", instance, err)
+		}
+
+		return c.(*Collector)
+	}
+
+	if newInstance("node-agent").RequiresLeaderElection() {
+		t.Error("Expected node-agent to run without leader election")
+	}
+
+	c := newInstance("billing")
+	if !c.RequiresLeaderElection() {
+		t.Error("Expected billing to default to leader election")
+	}
+
+	// A new connection is made on each start, e.g. when the leadership is regained
+	for range 2 {
+		if err := c.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := c.WaitReady(ctx)
+
+		cancel()
+
+		if err != nil {
+			t.Fatalf("WaitReady() error = %v", err)
+		}
+
+		if err := c.Health(); err != nil {
+			t.Errorf("Health() error = %v", err)
+		}
+
+		if err := c.Stop(); err != nil {
+			t.Fatalf("Stop() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package pod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// nodeAllocation accumulates the requests and limits of the pods scheduled on a node
+type nodeAllocation struct {
+	requests map[corev1.ResourceName]float64
+	limits   map[corev1.ResourceName]float64
+}
+
+// nodeCapacityEnabled returns whether node capacity metrics are collected.
+// Namespaced pod watches only see part of each node, so they are excluded.
+func (c *Collector) nodeCapacityEnabled() bool {
+	return c.config.NodeCapacity && len(c.config.Namespaces) == 0
+}
+
+// handleNode records or updates a tracked node
+func (c *Collector) handleNode(obj any) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Node")
+		return
+	}
+
+	c.mu.Lock()
+	c.nodes[node.Name] = node
+	c.mu.Unlock()
+}
+
+// handleNodeDelete removes a tracked node
+func (c *Collector) handleNodeDelete(obj any) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		node, ok = tombstone.Obj.(*corev1.Node)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a Node")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.nodes, node.Name)
+	c.mu.Unlock()
+}
+
+// collectNodeCapacity emits per-node capacity metrics.
+// Must be called with c.mu held.
+func (c *Collector) collectNodeCapacity(ch chan<- prometheus.Metric) {
+	allocations := make(map[string]*nodeAllocation, len(c.nodes))
+
+	for _, pod := range c.pods {
+		if !occupiesNode(pod) {
+			continue
+		}
+
+		if _, ok := c.nodes[pod.Spec.NodeName]; !ok {
+			continue
+		}
+
+		alloc, ok := allocations[pod.Spec.NodeName]
+		if !ok {
+			alloc = &nodeAllocation{
+				requests: make(map[corev1.ResourceName]float64),
+				limits:   make(map[corev1.ResourceName]float64),
+			}
+			allocations[pod.Spec.NodeName] = alloc
+		}
+
+		for name, quantity := range podRequests(pod) {
+			alloc.requests[name] += quantityValue(name, quantity)
+		}
+
+		for name, quantity := range podLimits(pod) {
+			alloc.limits[name] += quantityValue(name, quantity)
+		}
+
+		alloc.requests[corev1.ResourcePods]++
+		alloc.limits[corev1.ResourcePods]++
+	}
+
+	for _, node := range c.nodes {
+		alloc := allocations[node.Name]
+
+		for _, resourceName := range c.config.NodeCapacityResources {
+			name := corev1.ResourceName(resourceName)
+
+			allocatableQuantity, ok := node.Status.Allocatable[name]
+			if !ok {
+				continue
+			}
+
+			allocatable := quantityValue(name, allocatableQuantity)
+
+			var requests, limits float64
+			if alloc != nil {
+				requests = alloc.requests[name]
+				limits = alloc.limits[name]
+			}
+
+			if capacity, ok := node.Status.Capacity[name]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					c.nodeCapacity,
+					prometheus.GaugeValue,
+					quantityValue(name, capacity),
+					node.Name,
+					resourceName,
+				)
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.nodeAllocatable,
+				prometheus.GaugeValue,
+				allocatable,
+				node.Name,
+				resourceName,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.nodeAllocated,
+				prometheus.GaugeValue,
+				requests,
+				node.Name,
+				resourceName,
+				"requests",
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.nodeAllocated,
+				prometheus.GaugeValue,
+				limits,
+				node.Name,
+				resourceName,
+				"limits",
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.nodeSchedulable,
+				prometheus.GaugeValue,
+				max(allocatable-requests, 0),
+				node.Name,
+				resourceName,
+			)
+
+			if allocatable > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					c.nodeOvercommit,
+					prometheus.GaugeValue,
+					requests/allocatable,
+					node.Name,
+					resourceName,
+					"requests",
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.nodeOvercommit,
+					prometheus.GaugeValue,
+					limits/allocatable,
+					node.Name,
+					resourceName,
+					"limits",
+				)
+			}
+		}
+	}
+}
+
+// occupiesNode returns whether a pod holds resources on its node.
+// Terminal pods no longer count against node allocatable.
+func occupiesNode(pod *corev1.Pod) bool {
+	return pod.Spec.NodeName != "" &&
+		pod.Status.Phase != corev1.PodSucceeded &&
+		pod.Status.Phase != corev1.PodFailed
+}
+
+// podRequests returns the effective resource requests of a pod, as computed by the scheduler
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	return effectiveResources(pod, func(r corev1.ResourceRequirements) corev1.ResourceList {
+		return r.Requests
+	})
+}
+
+// podLimits returns the effective resource limits of a pod.
+// Containers without a limit do not contribute.
+func podLimits(pod *corev1.Pod) corev1.ResourceList {
+	return effectiveResources(pod, func(r corev1.ResourceRequirements) corev1.ResourceList {
+		return r.Limits
+	})
+}
+
+// effectiveResources returns the larger of the sum of app and sidecar containers
+// and the peak of any init container (plus the sidecars started before it),
+// including pod overhead
+func effectiveResources(
+	pod *corev1.Pod,
+	get func(corev1.ResourceRequirements) corev1.ResourceList,
+) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(result, get(container.Resources))
+	}
+
+	sidecars := corev1.ResourceList{}
+	initPeak := corev1.ResourceList{}
+
+	for _, container := range pod.Spec.InitContainers {
+		if container.RestartPolicy != nil &&
+			*container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(sidecars, get(container.Resources))
+			addResources(result, get(container.Resources))
+
+			continue
+		}
+
+		step := sidecars.DeepCopy()
+		addResources(step, get(container.Resources))
+		maxResources(initPeak, step)
+	}
+
+	maxResources(result, initPeak)
+	addResources(result, pod.Spec.Overhead)
+
+	return result
+}
+
+// addResources adds every quantity of src to dst
+func addResources(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		if current, ok := dst[name]; ok {
+			current.Add(quantity)
+			dst[name] = current
+		} else {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// maxResources raises every quantity of dst to at least the one in src
+func maxResources(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		if current, ok := dst[name]; !ok || quantity.Cmp(current) > 0 {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// quantityValue converts a quantity to its metric value: cores for CPU, base units otherwise
+func quantityValue(name corev1.ResourceName, quantity resource.Quantity) float64 {
+	if name == corev1.ResourceCPU {
+		return float64(quantity.MilliValue()) / 1000
+	}
+
+	return quantity.AsApproximateFloat64()
+}
+
+// trimResources keeps only the container fields needed to compute pod requests and limits
+func trimResources(pod *corev1.Pod, transformed *corev1.Pod) {
+	transformed.Spec.Overhead = pod.Spec.Overhead
+
+	for _, container := range pod.Spec.Containers {
+		transformed.Spec.Containers = append(transformed.Spec.Containers, corev1.Container{
+			Resources: corev1.ResourceRequirements{
+				Requests: container.Resources.Requests,
+				Limits:   container.Resources.Limits,
+			},
+		})
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		transformed.Spec.InitContainers = append(transformed.Spec.InitContainers, corev1.Container{
+			RestartPolicy: container.RestartPolicy,
+			Resources: corev1.ResourceRequirements{
+				Requests: container.Resources.Requests,
+				Limits:   container.Resources.Limits,
+			},
+		})
+	}
+}
+
+// trimNode keeps only the fields needed for node capacity metrics
+func trimNode(obj any) (any, error) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: node.Name,
+			UID:  node.UID,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    node.Status.Capacity,
+			Allocatable: node.Status.Allocatable,
+		},
+	}, nil
+}
@@ -0,0 +1,73 @@
+//nolint:testpackage // Tests need access to private function podRequests
+package pod
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TestPodRequests verifies effective requests with init containers, sidecars and overhead
+func TestPodRequests(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+
+	requests := func(cpu string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		spec     corev1.PodSpec
+		expected float64
+	}{
+		{
+			name: "containers are summed",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Resources: requests("500m")},
+					{Resources: requests("250m")},
+				},
+			},
+			expected: 0.75,
+		},
+		{
+			name: "init container peak wins",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Resources: requests("2")}},
+				Containers:     []corev1.Container{{Resources: requests("500m")}},
+			},
+			expected: 2,
+		},
+		{
+			name: "sidecars run alongside containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Resources: requests("100m"), RestartPolicy: &always},
+					{Resources: requests("1")},
+				},
+				Containers: []corev1.Container{{Resources: requests("500m")}},
+			},
+			expected: 1.1,
+		},
+		{
+			name: "overhead is added",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Resources: requests("1")}},
+				Overhead:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			},
+			expected: 1.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu := podRequests(&corev1.Pod{Spec: tt.spec})[corev1.ResourceCPU]
+			if got := quantityValue(corev1.ResourceCPU, cpu); got != tt.expected {
+				t.Errorf("Expected %v cores, got %v", tt.expected, got)
+			}
+		})
+	}
+}
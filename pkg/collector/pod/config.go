@@ -0,0 +1,56 @@
+package pod
+
+import (
+	"time"
+)
+
+// Config contains configuration for the Pod collector
+type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	// When set, one namespaced informer is created per namespace so only namespaced RBAC is needed
+	Namespaces []string `yaml:"namespaces" env:"NAMESPACES" envSeparator:","`
+	// IncludeSystemNamespaces reports pods in system namespaces (kube-system, sealos-system, ...)
+	// when Namespaces is empty; they are excluded by default
+	IncludeSystemNamespaces bool `yaml:"includeSystemNamespaces" env:"INCLUDE_SYSTEM_NAMESPACES"`
+	// StuckTerminatingThreshold is how long a pod may stay terminating before it is reported
+	StuckTerminatingThreshold time.Duration `yaml:"stuckTerminatingThreshold" env:"STUCK_TERMINATING_THRESHOLD"`
+	// NodeCapacity exports per-node capacity, allocatable, allocated and overcommit metrics.
+	// Requires a cluster-wide pod watch, so it is ignored when Namespaces is set.
+	NodeCapacity bool `yaml:"nodeCapacity" env:"NODE_CAPACITY"`
+	// NodeCapacityResources are the resources reported by the node capacity metrics
+	NodeCapacityResources []string `yaml:"nodeCapacityResources" env:"NODE_CAPACITY_RESOURCES" envSeparator:","`
+	// ImageInventory exports the image, tag, digest and pull policy of the containers of
+	// running pods. Container images are then kept in the trimmed pod cache.
+	ImageInventory bool `yaml:"imageInventory" env:"IMAGE_INVENTORY"`
+	// VolumeFailures reports pods stuck before starting their containers because
+	// a volume failed to attach or mount, from the FailedAttachVolume, FailedMount
+	// and FailedMapVolume events. Starts event and PVC informers.
+	VolumeFailures bool `yaml:"volumeFailures" env:"VOLUME_FAILURES"`
+	// VolumeFailureWindow is how long after its last event a volume failure is
+	// still reported; the kubelet reports failing mounts at least every few minutes
+	VolumeFailureWindow time.Duration `yaml:"volumeFailureWindow" env:"VOLUME_FAILURE_WINDOW"`
+	// ContainerRestarts exports the restart count and last termination reason
+	// and exit code of each container, and counts the OOM kills observed since
+	// start per workload container. Container statuses are then kept in the
+	// trimmed pod cache.
+	ContainerRestarts bool `yaml:"containerRestarts" env:"CONTAINER_RESTARTS"`
+	// NodeLocal only watches the pods scheduled on NodeName, so a DaemonSet
+	// deployment shards the pods by node. The collector then runs on every
+	// instance instead of the leader only.
+	NodeLocal bool `yaml:"nodeLocal" env:"NODE_LOCAL"`
+	// NodeName is the node watched in node-local mode (defaults to the global
+	// nodeName, usually set from the downward API)
+	NodeName string `yaml:"nodeName" env:"NODE_NAME"`
+}
+
+// NewDefaultConfig returns the default configuration for Pod collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces:                []string{},
+		StuckTerminatingThreshold: 10 * time.Minute,
+		NodeCapacity:              true,
+		NodeCapacityResources:     []string{"cpu", "memory", "pods"},
+		VolumeFailureWindow:       5 * time.Minute,
+	}
+}
@@ -0,0 +1,404 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "pod"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Pod phase counts, stuck-terminating pods and node overcommit"),
+		registry.WithRBAC([]string{""}, []string{"pods"}, []string{"list", "watch"}),
+		registry.WithRBAC([]string{""}, []string{"nodes"}, []string{"list", "watch"}),
+		registry.WithConfigRBAC(requiredRBAC),
+	)
+}
+
+// NewCollector creates a new Pod collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.pod", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load pod collector config, using defaults")
+	}
+
+	// 3. Use global NodeName if not set in collector config
+	if cfg.NodeName == "" {
+		cfg.NodeName = factoryCtx.NodeName
+	}
+
+	if cfg.NodeLocal && cfg.NodeName == "" {
+		return nil, errors.New("nodeName is required in node-local mode (set NODE_NAME from the downward API)")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			// In node-local mode every instance watches the pods of its own node
+			base.WithLeaderElection(!cfg.NodeLocal),
+			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client:         client,
+		config:         cfg,
+		pods:           make(map[string]*corev1.Pod),
+		nodes:          make(map[string]*corev1.Node),
+		pvcs:           make(map[string]*corev1.PersistentVolumeClaim),
+		volumeFailures: make(map[types.UID]map[string]volumeFailure),
+		oomKills:       make(map[oomKey]float64),
+		stopCh:         make(chan struct{}),
+		logger:         factoryCtx.Logger,
+	}
+
+	c.excluded = make(map[string]struct{})
+	for _, namespace := range util.ExcludedNamespaces(cfg.Namespaces, cfg.IncludeSystemNamespaces) {
+		c.excluded[namespace] = struct{}{}
+	}
+
+	c.mu.Instrument(c.BaseCollector)
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	if c.config.NodeCapacity && len(c.config.Namespaces) > 0 {
+		c.logger.Warn("Node capacity metrics require a cluster-wide pod watch, " +
+			"disabled because namespaces are configured")
+	}
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and state to support restart
+			c.stopCh = make(chan struct{})
+			c.informers = nil
+
+			c.mu.Lock()
+			c.pods = make(map[string]*corev1.Pod)
+			c.nodes = make(map[string]*corev1.Node)
+			c.pvcs = make(map[string]*corev1.PersistentVolumeClaim)
+			c.volumeFailures = make(map[types.UID]map[string]volumeFailure)
+			c.mu.Unlock()
+
+			// Create one informer factory per namespace (or a single cluster-wide one)
+			// Without node capacity metrics, excluded namespaces are not even watched
+			var opts []informers.SharedInformerOption
+
+			if selector := c.podFieldSelector(); selector != nil {
+				opts = append(opts, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+					options.FieldSelector = selector.String()
+				}))
+			}
+
+			factories := util.NewInformerFactories(
+				c.client,
+				factoryCtx.InformerResyncPeriod,
+				c.config.Namespaces,
+				opts...,
+			)
+			claimFactories := factories
+
+			for _, factory := range factories {
+				informer := factory.Core().V1().Pods().Informer()
+
+				// Apply transform to reduce memory usage
+				// Only keep necessary fields for pod state monitoring
+				_ = informer.SetTransform(func(obj any) (any, error) {
+					return trimPod(obj, podTrimOptions{
+						resources: c.nodeCapacityEnabled(),
+						images:    c.config.ImageInventory,
+						volumes:   c.config.VolumeFailures,
+						restarts:  c.config.ContainerRestarts,
+					})
+				})
+
+				c.InstrumentInformer("pods", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handlePod,
+					UpdateFunc: func(_, newObj any) { c.handlePod(newObj) },
+					DeleteFunc: c.handlePodDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			// Node capacity metrics combine the pod cache with node allocatable.
+			// Factories are cluster-wide here, since namespaces are not configured.
+			if c.nodeCapacityEnabled() {
+				nodeFactory := factories[0]
+
+				// The pod watch is narrowed to the node, which does not apply to nodes
+				if c.config.NodeLocal {
+					nodeFactory = informers.NewSharedInformerFactoryWithOptions(
+						c.client,
+						factoryCtx.InformerResyncPeriod,
+						informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+							options.FieldSelector = fields.OneTermEqualSelector("metadata.name", c.config.NodeName).String()
+						}),
+					)
+					factories = append(factories, nodeFactory)
+				}
+
+				informer := nodeFactory.Core().V1().Nodes().Informer()
+				_ = informer.SetTransform(trimNode)
+
+				c.InstrumentInformer("nodes", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleNode,
+					UpdateFunc: func(_, newObj any) { c.handleNode(newObj) },
+					DeleteFunc: c.handleNodeDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			if c.config.VolumeFailures {
+				// Claims are watched by the pod factories, except in node-local
+				// mode where claims are not bound to the node
+				if c.config.NodeLocal {
+					claimFactories = c.newClaimFactories(factoryCtx.InformerResyncPeriod)
+					factories = append(factories, claimFactories...)
+				}
+
+				factories = append(factories, c.newVolumeInformers(claimFactories, factoryCtx.InformerResyncPeriod)...)
+			}
+
+			// Start informers
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			// Wait for cache sync
+			c.logger.Info("Waiting for pod informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				return errors.New("failed to sync pod informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			c.logger.Info("Pod collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
+
+// podFieldSelector returns the field selector of the pod watches, nil when
+// every pod is watched. Excluded namespaces are filtered unless node capacity
+// metrics need every pod of the nodes, and other nodes in node-local mode.
+func (c *Collector) podFieldSelector() fields.Selector {
+	var selectors []fields.Selector
+
+	if !c.nodeCapacityEnabled() {
+		excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+		if selector := util.NamespaceExclusionSelector(excluded); selector != nil {
+			selectors = append(selectors, selector)
+		}
+	}
+
+	if c.config.NodeLocal {
+		selectors = append(selectors, fields.OneTermEqualSelector("spec.nodeName", c.config.NodeName))
+	}
+
+	if len(selectors) == 0 {
+		return nil
+	}
+
+	return fields.AndSelectors(selectors...)
+}
+
+// newClaimFactories returns the informer factories of the claim watches in
+// node-local mode, filtering the excluded namespaces only
+func (c *Collector) newClaimFactories(resync time.Duration) []informers.SharedInformerFactory {
+	var opts []informers.SharedInformerOption
+
+	excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+	if selector := util.NamespaceExclusionSelector(excluded); selector != nil {
+		opts = append(opts, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = selector.String()
+		}))
+	}
+
+	return util.NewInformerFactories(c.client, resync, c.config.Namespaces, opts...)
+}
+
+// podTrimOptions selects the optional pod fields kept in the cache
+type podTrimOptions struct {
+	// resources keeps container resources for node capacity metrics
+	resources bool
+	// images keeps container images for image inventory metrics
+	images bool
+	// volumes keeps volumes and container states for volume failure metrics
+	volumes bool
+	// restarts keeps container restart counts and terminations for container restart metrics
+	restarts bool
+}
+
+// trimPod reduces memory by keeping only the fields needed for pod state
+// monitoring, and the optional fields selected by opts
+func trimPod(obj any, opts podTrimOptions) (any, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+
+	transformed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			// Keep UID for proper object tracking
+			UID:               pod.UID,
+			DeletionTimestamp: pod.DeletionTimestamp,
+			Finalizers:        pod.Finalizers,
+			OwnerReferences:   pod.OwnerReferences,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:          pod.Spec.NodeName,
+			PriorityClassName: pod.Spec.PriorityClassName,
+		},
+		Status: corev1.PodStatus{
+			Phase:    pod.Status.Phase,
+			QOSClass: pod.Status.QOSClass,
+		},
+	}
+
+	if opts.resources {
+		trimResources(pod, transformed)
+	}
+
+	if opts.images {
+		trimImages(pod, transformed)
+	}
+
+	if opts.volumes {
+		trimVolumes(pod, transformed)
+	}
+
+	// After volumes, whose trimmed terminated states it completes
+	if opts.restarts {
+		trimRestarts(pod, transformed)
+	}
+
+	// Only keep the label needed to resolve Deployments from ReplicaSets
+	if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		transformed.Labels = map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash}
+	}
+
+	return transformed, nil
+}
+
+// newVolumeInformers registers the PVC informers of the claim informer factories
+// and returns the factories of the narrowed volume failure event watches, one
+// per namespace and reason
+func (c *Collector) newVolumeInformers(
+	claimFactories []informers.SharedInformerFactory,
+	resync time.Duration,
+) []informers.SharedInformerFactory {
+	for _, factory := range claimFactories {
+		informer := factory.Core().V1().PersistentVolumeClaims().Informer()
+		_ = informer.SetTransform(trimPVC)
+
+		c.InstrumentInformer("persistentvolumeclaims", informer)
+
+		//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+		informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.handlePVC,
+			UpdateFunc: func(_, newObj any) { c.handlePVC(newObj) },
+			DeleteFunc: c.handlePVCDelete,
+		}))
+
+		c.informers = append(c.informers, informer)
+	}
+
+	var factories []informers.SharedInformerFactory
+
+	excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+
+	for _, selector := range volumeEventSelectors(excluded) {
+		for _, factory := range util.NewInformerFactories(
+			c.client,
+			resync,
+			c.config.Namespaces,
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = selector
+			}),
+		) {
+			informer := factory.Core().V1().Events().Informer()
+			_ = informer.SetTransform(trimVolumeEvent)
+
+			c.InstrumentInformer("events", informer)
+
+			//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+			informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc:    c.handleVolumeEvent,
+				UpdateFunc: func(_, newObj any) { c.handleVolumeEvent(newObj) },
+			}))
+
+			factories = append(factories, factory)
+			c.informers = append(c.informers, informer)
+		}
+	}
+
+	return factories
+}
+
+// requiredRBAC returns the permissions of the volume failure metrics
+func requiredRBAC(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+	cfg := NewDefaultConfig()
+	if err := loader.LoadModuleConfig("collectors.pod", cfg); err != nil {
+		return nil, err
+	}
+
+	if !cfg.VolumeFailures {
+		return nil, nil
+	}
+
+	return []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"events", "persistentvolumeclaims"},
+		Verbs:     []string{"list", "watch"},
+	}}, nil
+}
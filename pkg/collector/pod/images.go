@@ -0,0 +1,157 @@
+package pod
+
+import (
+	"strings"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// imageKey identifies a container image of a workload
+type imageKey struct {
+	namespace    string
+	workloadKind string
+	workload     string
+	container    string
+	image        string
+	tag          string
+	digest       string
+	pullPolicy   corev1.PullPolicy
+}
+
+// imageRef identifies an image across the cluster
+type imageRef struct {
+	image  string
+	tag    string
+	digest string
+}
+
+// parseImage splits an image reference into the image name, tag and digest.
+// The tag defaults to "latest" when neither a tag nor a digest is set:
+//   - "nginx" -> ("nginx", "latest", "")
+//   - "registry:5000/app:v1" -> ("registry:5000/app", "v1", "")
+//   - "app@sha256:abc" -> ("app", "", "sha256:abc")
+func parseImage(image string) (name, tag, digest string) {
+	name, digest, _ = strings.Cut(image, "@")
+
+	// A colon after the last slash separates the tag, others are registry ports
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+
+	return name, tag, digest
+}
+
+// imageIDDigest returns the digest of a container status image ID
+// (e.g. "docker-pullable://nginx@sha256:abc" or "sha256:abc")
+func imageIDDigest(imageID string) string {
+	if _, digest, ok := strings.Cut(imageID, "@"); ok {
+		return digest
+	}
+
+	if strings.HasPrefix(imageID, "sha256:") {
+		return imageID
+	}
+
+	return ""
+}
+
+// trimImages keeps the container names, images and pull policies, and the
+// image IDs of the container statuses, needed for image inventory metrics.
+// Containers already kept for node capacity metrics are completed in place.
+func trimImages(pod *corev1.Pod, transformed *corev1.Pod) {
+	for i, container := range pod.Spec.Containers {
+		if i == len(transformed.Spec.Containers) {
+			transformed.Spec.Containers = append(transformed.Spec.Containers, corev1.Container{})
+		}
+
+		transformed.Spec.Containers[i].Name = container.Name
+		transformed.Spec.Containers[i].Image = container.Image
+		transformed.Spec.Containers[i].ImagePullPolicy = container.ImagePullPolicy
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		transformed.Status.ContainerStatuses = append(transformed.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:    status.Name,
+			ImageID: status.ImageID,
+		})
+	}
+}
+
+// collectImages emits the image of each container of running pods, once per
+// workload, and the number of running containers per image across the cluster.
+// Must be called with c.mu held.
+func (c *Collector) collectImages(ch chan<- prometheus.Metric) {
+	infos := make(map[imageKey]struct{})
+	counts := make(map[imageRef]float64)
+
+	for _, pod := range c.pods {
+		if _, ok := c.excluded[pod.Namespace]; ok {
+			continue
+		}
+
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		kind, workload := util.WorkloadOf(pod)
+
+		imageIDs := make(map[string]string, len(pod.Status.ContainerStatuses))
+		for _, status := range pod.Status.ContainerStatuses {
+			imageIDs[status.Name] = status.ImageID
+		}
+
+		for _, container := range pod.Spec.Containers {
+			image, tag, digest := parseImage(container.Image)
+			if digest == "" {
+				// Resolved by the container runtime for tag references
+				digest = imageIDDigest(imageIDs[container.Name])
+			}
+
+			infos[imageKey{
+				namespace:    pod.Namespace,
+				workloadKind: kind,
+				workload:     workload,
+				container:    container.Name,
+				image:        image,
+				tag:          tag,
+				digest:       digest,
+				pullPolicy:   container.ImagePullPolicy,
+			}] = struct{}{}
+
+			counts[imageRef{image: image, tag: tag, digest: digest}]++
+		}
+	}
+
+	for key := range infos {
+		ch <- prometheus.MustNewConstMetric(
+			c.containerImage,
+			prometheus.GaugeValue,
+			1,
+			key.namespace,
+			key.workloadKind,
+			key.workload,
+			key.container,
+			key.image,
+			key.tag,
+			key.digest,
+			string(key.pullPolicy),
+		)
+	}
+
+	for ref, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.imageContainers,
+			prometheus.GaugeValue,
+			count,
+			ref.image,
+			ref.tag,
+			ref.digest,
+		)
+	}
+}
@@ -0,0 +1,100 @@
+//nolint:testpackage // Tests need access to private functions parseImage, imageIDDigest and trimPod
+package pod
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TestParseImage verifies image references are split into name, tag and digest
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image, name, tag, digest string
+	}{
+		{"nginx", "nginx", "latest", ""},
+		{"nginx:1.27", "nginx", "1.27", ""},
+		{"registry:5000/team/app", "registry:5000/team/app", "latest", ""},
+		{"registry:5000/team/app:v2", "registry:5000/team/app", "v2", ""},
+		{"app@sha256:abc", "app", "", "sha256:abc"},
+		{"ghcr.io/app:v1@sha256:abc", "ghcr.io/app", "v1", "sha256:abc"},
+	}
+
+	for _, tt := range tests {
+		name, tag, digest := parseImage(tt.image)
+		if name != tt.name || tag != tt.tag || digest != tt.digest {
+			t.Errorf("parseImage(%q) = (%q, %q, %q), expected (%q, %q, %q)",
+				tt.image, name, tag, digest, tt.name, tt.tag, tt.digest)
+		}
+	}
+}
+
+// TestImageIDDigest verifies digests are extracted from container status image IDs
+func TestImageIDDigest(t *testing.T) {
+	tests := map[string]string{
+		"docker-pullable://nginx@sha256:abc": "sha256:abc",
+		"docker.io/library/nginx@sha256:def": "sha256:def",
+		"sha256:123":                         "sha256:123",
+		"":                                   "",
+	}
+
+	for imageID, expected := range tests {
+		if got := imageIDDigest(imageID); got != expected {
+			t.Errorf("imageIDDigest(%q) = %q, expected %q", imageID, got, expected)
+		}
+	}
+}
+
+// TestTrimPodImages verifies images are merged into the containers kept for node capacity
+func TestTrimPodImages(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:            "app",
+					Image:           "app:v1",
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+					Command: []string{"/app"},
+				},
+				{Name: "sidecar", Image: "proxy:latest", ImagePullPolicy: corev1.PullAlways},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ImageID: "app@sha256:abc", RestartCount: 3},
+			},
+		},
+	}
+
+	for _, keepResources := range []bool{false, true} {
+		obj, _ := trimPod(pod, podTrimOptions{resources: keepResources, images: true})
+		trimmed, _ := obj.(*corev1.Pod)
+
+		if len(trimmed.Spec.Containers) != 2 {
+			t.Fatalf("Expected 2 containers, got %d", len(trimmed.Spec.Containers))
+		}
+
+		app := trimmed.Spec.Containers[0]
+		if app.Name != "app" || app.Image != "app:v1" || app.ImagePullPolicy != corev1.PullIfNotPresent {
+			t.Errorf("Unexpected trimmed container %+v", app)
+		}
+
+		if app.Command != nil {
+			t.Error("Expected command to be trimmed")
+		}
+
+		if hasRequests := app.Resources.Requests != nil; hasRequests != keepResources {
+			t.Errorf("Expected requests kept = %v", keepResources)
+		}
+
+		if len(trimmed.Status.ContainerStatuses) != 1 ||
+			trimmed.Status.ContainerStatuses[0].ImageID != "app@sha256:abc" ||
+			trimmed.Status.ContainerStatuses[0].RestartCount != 0 {
+			t.Errorf("Unexpected trimmed container statuses %+v", trimmed.Status.ContainerStatuses)
+		}
+	}
+}
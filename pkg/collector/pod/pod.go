@@ -0,0 +1,363 @@
+package pod
+
+import (
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/sampling"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// phaseKey identifies a namespace/phase series
+type phaseKey struct {
+	namespace string
+	phase     corev1.PodPhase
+}
+
+// Collector collects pod metrics
+type Collector struct {
+	*base.BaseCollector
+
+	client    kubernetes.Interface
+	config    *Config
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	// excluded holds the system namespaces left out of pod metrics
+	excluded map[string]struct{}
+
+	mu    base.RWMutex
+	pods  map[string]*corev1.Pod  // key: namespace/name
+	nodes map[string]*corev1.Node // key: name, only tracked for node capacity metrics
+	// pvcs and volumeFailures are only tracked for volume failure metrics
+	pvcs           map[string]*corev1.PersistentVolumeClaim // key: namespace/name
+	volumeFailures map[types.UID]map[string]volumeFailure   // key: pod UID, then volume named by the event
+	// oomKills counts the OOM kills observed since start, only tracked for container restart metrics
+	oomKills map[oomKey]float64
+
+	// Metrics
+	podPhase              *prometheus.Desc
+	podStuckTerminating   *prometheus.Desc
+	podQOSClass           *prometheus.Desc
+	nodePodQOSClass       *prometheus.Desc
+	nodeCapacity          *prometheus.Desc
+	nodeAllocatable       *prometheus.Desc
+	nodeAllocated         *prometheus.Desc
+	nodeSchedulable       *prometheus.Desc
+	nodeOvercommit        *prometheus.Desc
+	containerImage        *prometheus.Desc
+	imageContainers       *prometheus.Desc
+	podVolumeFailure      *prometheus.Desc
+	podVolumeFailureCount *prometheus.Desc
+
+	containerRestarts               *prometheus.Desc
+	containerLastTerminatedReason   *prometheus.Desc
+	containerLastTerminatedExitCode *prometheus.Desc
+	containerOOMKills               *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.podPhase = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "phase_count"),
+		"Number of pods per namespace and phase",
+		[]string{"namespace", "phase"},
+		nil,
+	)
+	c.podStuckTerminating = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "stuck_terminating_seconds"),
+		"Time since deletion was requested for pods terminating longer than the threshold, "+
+			"one series per finalizer blocking deletion (empty when none)",
+		[]string{"namespace", "pod", "node", "workload_kind", "workload", "finalizer"},
+		nil,
+	)
+	c.podQOSClass = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "qos_class_count"),
+		"Number of non-terminal pods per namespace, QoS class and priority class",
+		[]string{"namespace", "qos_class", "priority_class"},
+		nil,
+	)
+	c.nodePodQOSClass = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "pod_qos_class_count"),
+		"Number of non-terminal pods scheduled on the node per QoS class and priority class",
+		[]string{"node", "qos_class", "priority_class"},
+		nil,
+	)
+
+	c.nodeCapacity = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "resource_capacity"),
+		"Node resource capacity (CPU in cores, memory and storage in bytes)",
+		[]string{"node", "resource"},
+		nil,
+	)
+	c.nodeAllocatable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "resource_allocatable"),
+		"Node resource allocatable to pods (CPU in cores, memory and storage in bytes)",
+		[]string{"node", "resource"},
+		nil,
+	)
+	c.nodeAllocated = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "resource_allocated"),
+		"Sum of the requests or limits of the non-terminal pods scheduled on the node",
+		[]string{"node", "resource", "type"},
+		nil,
+	)
+	c.nodeSchedulable = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "resource_schedulable"),
+		"Allocatable resources not yet requested by pods, i.e. remaining schedulable capacity",
+		[]string{"node", "resource"},
+		nil,
+	)
+	c.nodeOvercommit = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "node", "resource_overcommit_ratio"),
+		"Ratio of pod requests or limits to node allocatable (above 1 means overcommitted)",
+		[]string{"node", "resource", "type"},
+		nil,
+	)
+
+	c.containerImage = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "container_image_info"),
+		"Image of the containers of running pods, one series per workload container and image",
+		[]string{"namespace", "workload_kind", "workload", "container", "image", "tag", "digest", "pull_policy"},
+		nil,
+	)
+	c.imageContainers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "image", "running_containers"),
+		"Number of containers of running pods using an image",
+		[]string{"image", "tag", "digest"},
+		nil,
+	)
+
+	c.podVolumeFailure = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "volume_failure_seconds"),
+		"Time since scheduling of pods whose containers have not started while one of their volumes "+
+			"recently failed to attach or mount",
+		[]string{"namespace", "pod", "node", "volume", "pvc", "storage_class", "reason"},
+		nil,
+	)
+	c.podVolumeFailureCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "volume_failure_count"),
+		"Number of volumes of pods waiting to start that recently failed to attach or mount, "+
+			"per storage class and reason",
+		[]string{"storage_class", "reason"},
+		nil,
+	)
+
+	c.containerRestarts = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "container_restarts_total"),
+		"Number of restarts of the container",
+		[]string{"namespace", "pod", "container"},
+		nil,
+	)
+	c.containerLastTerminatedReason = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "container_last_terminated_reason"),
+		"Reason of the last termination of the container (OOMKilled, Error, Completed, ...), "+
+			"its current one when stopped",
+		[]string{"namespace", "pod", "container", "reason"},
+		nil,
+	)
+	c.containerLastTerminatedExitCode = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "container_last_terminated_exit_code"),
+		"Exit code of the last termination of the container, its current one when stopped",
+		[]string{"namespace", "pod", "container"},
+		nil,
+	)
+	c.containerOOMKills = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pod", "container_oom_kills_total"),
+		"Number of OOM kills of the containers of a workload observed since the collector started",
+		[]string{"namespace", "workload_kind", "workload", "container"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.podPhase)
+	c.MustRegisterDesc(c.podStuckTerminating)
+	c.MustRegisterDesc(c.podQOSClass)
+	c.MustRegisterDesc(c.nodePodQOSClass)
+
+	if c.nodeCapacityEnabled() {
+		c.MustRegisterDesc(c.nodeCapacity)
+		c.MustRegisterDesc(c.nodeAllocatable)
+		c.MustRegisterDesc(c.nodeAllocated)
+		c.MustRegisterDesc(c.nodeSchedulable)
+		c.MustRegisterDesc(c.nodeOvercommit)
+	}
+
+	if c.config.ImageInventory {
+		c.MustRegisterDesc(c.containerImage)
+		c.MustRegisterDesc(c.imageContainers)
+	}
+
+	if c.config.VolumeFailures {
+		c.MustRegisterDesc(c.podVolumeFailure)
+		c.MustRegisterDesc(c.podVolumeFailureCount)
+	}
+
+	if c.config.ContainerRestarts {
+		c.MustRegisterDesc(c.containerRestarts)
+		c.MustRegisterDesc(c.containerLastTerminatedReason)
+		c.MustRegisterDesc(c.containerLastTerminatedExitCode)
+		c.MustRegisterDesc(c.containerOOMKills)
+	}
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handlePod records or updates a tracked pod
+func (c *Collector) handlePod(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Pod")
+		return
+	}
+
+	sampling.Sample(collectorName, pod)
+
+	key := podKey(pod.Namespace, pod.Name)
+
+	c.mu.Lock()
+
+	if _, excluded := c.excluded[pod.Namespace]; c.config.ContainerRestarts && !excluded {
+		c.recordOOMKills(c.pods[key], pod)
+	}
+
+	c.pods[key] = pod
+	c.forgetVolumeFailures(pod, false)
+	c.mu.Unlock()
+}
+
+// handlePodDelete removes a tracked pod
+func (c *Collector) handlePodDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		pod, ok = tombstone.Obj.(*corev1.Pod)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a Pod")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.pods, podKey(pod.Namespace, pod.Name))
+	c.forgetVolumeFailures(pod, true)
+	c.mu.Unlock()
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	phases := make(map[phaseKey]float64)
+
+	for _, pod := range c.pods {
+		// Excluded pods stay cached since they still count against node capacity
+		if _, ok := c.excluded[pod.Namespace]; ok {
+			continue
+		}
+
+		phases[phaseKey{namespace: pod.Namespace, phase: pod.Status.Phase}]++
+
+		terminating, stuck := stuckTerminating(pod, now, c.config.StuckTerminatingThreshold)
+		if !stuck {
+			continue
+		}
+
+		kind, name := util.WorkloadOf(pod)
+
+		finalizers := pod.Finalizers
+		if len(finalizers) == 0 {
+			// No finalizer: deletion is blocked by the kubelet (e.g. unreachable node)
+			finalizers = []string{""}
+		}
+
+		for _, finalizer := range finalizers {
+			ch <- prometheus.MustNewConstMetric(
+				c.podStuckTerminating,
+				prometheus.GaugeValue,
+				terminating.Seconds(),
+				pod.Namespace,
+				pod.Name,
+				pod.Spec.NodeName,
+				kind,
+				name,
+				finalizer,
+			)
+		}
+	}
+
+	for key, count := range phases {
+		ch <- prometheus.MustNewConstMetric(
+			c.podPhase,
+			prometheus.GaugeValue,
+			count,
+			key.namespace,
+			string(key.phase),
+		)
+	}
+
+	c.collectQOS(ch)
+
+	if c.nodeCapacityEnabled() {
+		c.collectNodeCapacity(ch)
+	}
+
+	if c.config.ImageInventory {
+		c.collectImages(ch)
+	}
+
+	if c.config.VolumeFailures {
+		c.collectVolumeFailures(ch, now)
+	}
+
+	if c.config.ContainerRestarts {
+		c.collectRestarts(ch)
+	}
+}
+
+// stuckTerminating returns how long a pod has been terminating and whether
+// that exceeds the threshold
+func stuckTerminating(pod *corev1.Pod, now time.Time, threshold time.Duration) (time.Duration, bool) {
+	if pod.DeletionTimestamp == nil {
+		return 0, false
+	}
+
+	// The deletion timestamp already includes the grace period
+	terminating := now.Sub(pod.DeletionTimestamp.Time)
+
+	return terminating, terminating > threshold
+}
+
+// podKey generates a unique key for a pod
+func podKey(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,102 @@
+//nolint:testpackage // Tests need access to private functions stuckTerminating and podFieldSelector
+package pod
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// TestStuckTerminating verifies the stuck terminating threshold
+func TestStuckTerminating(t *testing.T) {
+	now := time.Now()
+	threshold := 10 * time.Minute
+
+	running := &corev1.Pod{}
+	if _, stuck := stuckTerminating(running, now, threshold); stuck {
+		t.Error("Expected running pod not to be stuck")
+	}
+
+	recent := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &metav1.Time{Time: now.Add(-time.Minute)},
+		},
+	}
+	if _, stuck := stuckTerminating(recent, now, threshold); stuck {
+		t.Error("Expected recently deleted pod not to be stuck")
+	}
+
+	old := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &metav1.Time{Time: now.Add(-time.Hour)},
+		},
+	}
+
+	terminating, stuck := stuckTerminating(old, now, threshold)
+	if !stuck {
+		t.Error("Expected pod terminating for 1h to be stuck")
+	}
+
+	if terminating != time.Hour {
+		t.Errorf("Expected 1h terminating, got %v", terminating)
+	}
+}
+
+// TestPodFieldSelector verifies the pod watch is narrowed to the node in node-local mode
+func TestPodFieldSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected string
+	}{
+		{
+			name:     "all pods",
+			config:   &Config{Namespaces: []string{"ns-a"}},
+			expected: "",
+		},
+		{
+			name:     "node-local with node capacity",
+			config:   &Config{NodeCapacity: true, NodeLocal: true, NodeName: "node-1"},
+			expected: "spec.nodeName=node-1",
+		},
+		{
+			name:     "node-local in namespaces",
+			config:   &Config{Namespaces: []string{"ns-a"}, NodeLocal: true, NodeName: "node-1"},
+			expected: "spec.nodeName=node-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collector{config: tt.config}
+
+			got := ""
+			if selector := c.podFieldSelector(); selector != nil {
+				got = selector.String()
+			}
+
+			if got != tt.expected {
+				t.Errorf("podFieldSelector() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	// Without node capacity, excluded namespaces are filtered along with the node
+	c := &Collector{config: &Config{NodeLocal: true, NodeName: "node-1"}}
+
+	selector := c.podFieldSelector()
+	if selector == nil ||
+		!selector.Matches(fieldSet("default", "node-1")) ||
+		selector.Matches(fieldSet("kube-system", "node-1")) ||
+		selector.Matches(fieldSet("default", "node-2")) {
+		t.Errorf("Expected pods of node-1 outside system namespaces, got %v", selector)
+	}
+}
+
+// fieldSet returns the fields of a pod filtered by podFieldSelector
+func fieldSet(namespace, node string) fields.Set {
+	return fields.Set{"metadata.namespace": namespace, "spec.nodeName": node}
+}
@@ -0,0 +1,74 @@
+package pod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// qosKey identifies a QoS class and priority class series of a namespace or node
+type qosKey struct {
+	scope         string // namespace or node name
+	qosClass      corev1.PodQOSClass
+	priorityClass string
+}
+
+// qosCounts counts the non-terminal pods per namespace and per scheduled node,
+// by QoS class and priority class
+func qosCounts(pods map[string]*corev1.Pod, excluded map[string]struct{}) (namespaces, nodes map[qosKey]float64) {
+	namespaces = make(map[qosKey]float64)
+	nodes = make(map[qosKey]float64)
+
+	for _, pod := range pods {
+		if _, ok := excluded[pod.Namespace]; ok {
+			continue
+		}
+
+		// Terminal pods are no longer at risk of eviction
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		key := qosKey{
+			scope:         pod.Namespace,
+			qosClass:      pod.Status.QOSClass,
+			priorityClass: pod.Spec.PriorityClassName,
+		}
+		namespaces[key]++
+
+		if pod.Spec.NodeName != "" {
+			key.scope = pod.Spec.NodeName
+			nodes[key]++
+		}
+	}
+
+	return namespaces, nodes
+}
+
+// collectQOS emits the number of pods per QoS class and priority class, per
+// namespace and per node.
+// Must be called with c.mu held.
+func (c *Collector) collectQOS(ch chan<- prometheus.Metric) {
+	namespaces, nodes := qosCounts(c.pods, c.excluded)
+
+	for key, count := range namespaces {
+		ch <- prometheus.MustNewConstMetric(
+			c.podQOSClass,
+			prometheus.GaugeValue,
+			count,
+			key.scope,
+			string(key.qosClass),
+			key.priorityClass,
+		)
+	}
+
+	for key, count := range nodes {
+		ch <- prometheus.MustNewConstMetric(
+			c.nodePodQOSClass,
+			prometheus.GaugeValue,
+			count,
+			key.scope,
+			string(key.qosClass),
+			key.priorityClass,
+		)
+	}
+}
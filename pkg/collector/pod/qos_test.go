@@ -0,0 +1,60 @@
+//nolint:testpackage // Tests need access to private function qosCounts
+package pod
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestQOSCounts verifies pods are counted per namespace and node, skipping
+// terminal pods and excluded namespaces
+func TestQOSCounts(t *testing.T) {
+	pod := func(namespace, node string, phase corev1.PodPhase, qos corev1.PodQOSClass, priority string) *corev1.Pod {
+		p := &corev1.Pod{}
+		p.Namespace = namespace
+		p.Spec.NodeName = node
+		p.Spec.PriorityClassName = priority
+		p.Status.Phase = phase
+		p.Status.QOSClass = qos
+
+		return p
+	}
+
+	pods := map[string]*corev1.Pod{
+		"a": pod("ns-a", "worker-1", corev1.PodRunning, corev1.PodQOSGuaranteed, "high"),
+		"b": pod("ns-a", "worker-1", corev1.PodRunning, corev1.PodQOSGuaranteed, "high"),
+		"c": pod("ns-a", "worker-2", corev1.PodRunning, corev1.PodQOSBestEffort, ""),
+		"d": pod("ns-b", "", corev1.PodPending, corev1.PodQOSBurstable, ""),
+		"e": pod("ns-b", "worker-1", corev1.PodSucceeded, corev1.PodQOSBurstable, ""),
+		"f": pod("kube-system", "worker-1", corev1.PodRunning, corev1.PodQOSBurstable, "system-node-critical"),
+	}
+
+	namespaces, nodes := qosCounts(pods, map[string]struct{}{"kube-system": {}})
+
+	expectedNamespaces := map[qosKey]float64{
+		{scope: "ns-a", qosClass: corev1.PodQOSGuaranteed, priorityClass: "high"}: 2,
+		{scope: "ns-a", qosClass: corev1.PodQOSBestEffort}:                        1,
+		{scope: "ns-b", qosClass: corev1.PodQOSBurstable}:                         1,
+	}
+
+	expectedNodes := map[qosKey]float64{
+		{scope: "worker-1", qosClass: corev1.PodQOSGuaranteed, priorityClass: "high"}: 2,
+		{scope: "worker-2", qosClass: corev1.PodQOSBestEffort}:                        1,
+	}
+
+	for name, c := range map[string]struct{ got, expected map[qosKey]float64 }{
+		"namespace": {namespaces, expectedNamespaces},
+		"node":      {nodes, expectedNodes},
+	} {
+		if len(c.got) != len(c.expected) {
+			t.Errorf("Expected %d %s series, got %v", len(c.expected), name, c.got)
+		}
+
+		for key, count := range c.expected {
+			if c.got[key] != count {
+				t.Errorf("Expected %v pods for %s %+v, got %v", count, name, key, c.got[key])
+			}
+		}
+	}
+}
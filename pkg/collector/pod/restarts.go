@@ -0,0 +1,179 @@
+package pod
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reasonOOMKilled is the termination reason of containers killed for
+// exceeding their memory limit
+const reasonOOMKilled = "OOMKilled"
+
+// oomKey identifies the OOM kill counter of a workload container
+type oomKey struct {
+	namespace    string
+	workloadKind string
+	workload     string
+	container    string
+}
+
+// terminationKey identifies a single termination of a container
+type terminationKey struct {
+	container   string
+	containerID string
+	finishedAt  int64
+}
+
+// trimRestarts keeps the restart count and the current and last termination
+// of the containers, merged into the statuses kept for other metrics
+func trimRestarts(pod *corev1.Pod, transformed *corev1.Pod) {
+	for i, status := range pod.Status.ContainerStatuses {
+		if i == len(transformed.Status.ContainerStatuses) {
+			transformed.Status.ContainerStatuses = append(transformed.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name: status.Name,
+			})
+		}
+
+		trimmed := &transformed.Status.ContainerStatuses[i]
+		trimmed.RestartCount = status.RestartCount
+		trimmed.LastTerminationState.Terminated = trimTermination(status.LastTerminationState.Terminated)
+
+		if status.State.Terminated != nil {
+			trimmed.State.Terminated = trimTermination(status.State.Terminated)
+		}
+	}
+}
+
+// trimTermination keeps the reason, exit code and identity of a termination
+func trimTermination(terminated *corev1.ContainerStateTerminated) *corev1.ContainerStateTerminated {
+	if terminated == nil {
+		return nil
+	}
+
+	return &corev1.ContainerStateTerminated{
+		Reason:      terminated.Reason,
+		ExitCode:    terminated.ExitCode,
+		ContainerID: terminated.ContainerID,
+		FinishedAt:  terminated.FinishedAt,
+	}
+}
+
+// lastTermination returns the termination of a stopped container, or else
+// its termination before the last restart, nil when it never terminated
+func lastTermination(status *corev1.ContainerStatus) *corev1.ContainerStateTerminated {
+	if status.State.Terminated != nil {
+		return status.State.Terminated
+	}
+
+	return status.LastTerminationState.Terminated
+}
+
+// oomKills returns the OOM kills found in the current and last states of the
+// containers of a pod
+func oomKills(pod *corev1.Pod) map[terminationKey]struct{} {
+	kills := make(map[terminationKey]struct{})
+
+	for _, status := range pod.Status.ContainerStatuses {
+		for _, terminated := range []*corev1.ContainerStateTerminated{
+			status.State.Terminated,
+			status.LastTerminationState.Terminated,
+		} {
+			if terminated == nil || terminated.Reason != reasonOOMKilled {
+				continue
+			}
+
+			kills[terminationKey{
+				container:   status.Name,
+				containerID: terminated.ContainerID,
+				finishedAt:  terminated.FinishedAt.Unix(),
+			}] = struct{}{}
+		}
+	}
+
+	return kills
+}
+
+// recordOOMKills counts the OOM kills of pod not seen in old, its previous
+// version. Pods seen for the first time are not counted, since their kills
+// may predate the exporter. Must be called with c.mu held.
+func (c *Collector) recordOOMKills(old, pod *corev1.Pod) {
+	if old == nil {
+		return
+	}
+
+	seen := oomKills(old)
+	kind, name := util.WorkloadOf(pod)
+
+	for kill := range oomKills(pod) {
+		if _, ok := seen[kill]; ok {
+			continue
+		}
+
+		c.oomKills[oomKey{
+			namespace:    pod.Namespace,
+			workloadKind: kind,
+			workload:     name,
+			container:    kill.container,
+		}]++
+	}
+}
+
+// collectRestarts emits the restart count and last termination of each
+// container, and the OOM kills observed per workload container. Must be
+// called with c.mu held.
+func (c *Collector) collectRestarts(ch chan<- prometheus.Metric) {
+	for _, pod := range c.pods {
+		if _, ok := c.excluded[pod.Namespace]; ok {
+			continue
+		}
+
+		for i := range pod.Status.ContainerStatuses {
+			status := &pod.Status.ContainerStatuses[i]
+
+			ch <- prometheus.MustNewConstMetric(
+				c.containerRestarts,
+				prometheus.CounterValue,
+				float64(status.RestartCount),
+				pod.Namespace,
+				pod.Name,
+				status.Name,
+			)
+
+			terminated := lastTermination(status)
+			if terminated == nil {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.containerLastTerminatedReason,
+				prometheus.GaugeValue,
+				1,
+				pod.Namespace,
+				pod.Name,
+				status.Name,
+				terminated.Reason,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerLastTerminatedExitCode,
+				prometheus.GaugeValue,
+				float64(terminated.ExitCode),
+				pod.Namespace,
+				pod.Name,
+				status.Name,
+			)
+		}
+	}
+
+	for key, count := range c.oomKills {
+		ch <- prometheus.MustNewConstMetric(
+			c.containerOOMKills,
+			prometheus.CounterValue,
+			count,
+			key.namespace,
+			key.workloadKind,
+			key.workload,
+			key.container,
+		)
+	}
+}
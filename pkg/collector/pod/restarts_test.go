@@ -0,0 +1,127 @@
+//nolint:testpackage // Tests need access to private functions recordOOMKills, lastTermination and trimPod
+package pod
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// restartedPod returns a pod whose app container restarted after the given last termination
+func restartedPod(restarts int32, last *corev1.ContainerStateTerminated) *corev1.Pod {
+	controller := true
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-a",
+			Name:      "web-0",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "StatefulSet", Name: "web", Controller: &controller},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "app",
+					RestartCount:         restarts,
+					State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					LastTerminationState: corev1.ContainerState{Terminated: last},
+				},
+			},
+		},
+	}
+}
+
+// TestRecordOOMKills verifies each OOM kill is counted once per workload container
+func TestRecordOOMKills(t *testing.T) {
+	c := &Collector{oomKills: make(map[oomKey]float64)}
+	finished := metav1.NewTime(time.Unix(1700000000, 0))
+
+	oom := &corev1.ContainerStateTerminated{
+		Reason:      reasonOOMKilled,
+		ExitCode:    137,
+		ContainerID: "containerd://a",
+		FinishedAt:  finished,
+	}
+	first := restartedPod(1, oom)
+
+	// A pod seen for the first time may have been killed before the exporter started
+	c.recordOOMKills(nil, first)
+
+	// Resyncs deliver the same kill again
+	c.recordOOMKills(first, first)
+
+	if len(c.oomKills) != 0 {
+		t.Fatalf("Expected no OOM kill counted, got %v", c.oomKills)
+	}
+
+	second := restartedPod(2, &corev1.ContainerStateTerminated{
+		Reason:      reasonOOMKilled,
+		ExitCode:    137,
+		ContainerID: "containerd://b",
+		FinishedAt:  metav1.NewTime(finished.Add(time.Minute)),
+	})
+	c.recordOOMKills(first, second)
+
+	// An error termination is not an OOM kill
+	third := restartedPod(3, &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1, ContainerID: "containerd://c"})
+	c.recordOOMKills(second, third)
+
+	key := oomKey{namespace: "ns-a", workloadKind: "StatefulSet", workload: "web", container: "app"}
+	if len(c.oomKills) != 1 || c.oomKills[key] != 1 {
+		t.Errorf("Expected a single OOM kill of %v, got %v", key, c.oomKills)
+	}
+}
+
+// TestLastTermination verifies stopped containers report their current termination
+func TestLastTermination(t *testing.T) {
+	previous := &corev1.ContainerStateTerminated{Reason: reasonOOMKilled, ExitCode: 137}
+	current := &corev1.ContainerStateTerminated{Reason: "Completed"}
+
+	running := corev1.ContainerStatus{LastTerminationState: corev1.ContainerState{Terminated: previous}}
+	if got := lastTermination(&running); got != previous {
+		t.Errorf("Expected the previous termination of a running container, got %v", got)
+	}
+
+	stopped := running
+	stopped.State.Terminated = current
+
+	if got := lastTermination(&stopped); got != current {
+		t.Errorf("Expected the current termination of a stopped container, got %v", got)
+	}
+
+	if got := lastTermination(&corev1.ContainerStatus{}); got != nil {
+		t.Errorf("Expected no termination, got %v", got)
+	}
+}
+
+// TestTrimPodRestarts verifies terminations are kept in full alongside volume failure states
+func TestTrimPodRestarts(t *testing.T) {
+	pod := restartedPod(4, &corev1.ContainerStateTerminated{
+		Reason:      reasonOOMKilled,
+		ExitCode:    137,
+		ContainerID: "containerd://a",
+		Message:     "dropped",
+	})
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 2},
+	}
+
+	obj, _ := trimPod(pod, podTrimOptions{volumes: true, restarts: true})
+	status := obj.(*corev1.Pod).Status.ContainerStatuses[0]
+
+	if status.Name != "app" || status.RestartCount != 4 {
+		t.Errorf("Expected app with 4 restarts, got %s with %d", status.Name, status.RestartCount)
+	}
+
+	if last := status.LastTerminationState.Terminated; last == nil ||
+		last.Reason != reasonOOMKilled || last.ExitCode != 137 || last.Message != "" {
+		t.Errorf("Unexpected trimmed last termination: %+v", last)
+	}
+
+	if current := status.State.Terminated; current == nil || current.Reason != "Error" || current.ExitCode != 2 {
+		t.Errorf("Unexpected trimmed current termination: %+v", current)
+	}
+}
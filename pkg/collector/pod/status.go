@@ -0,0 +1,88 @@
+package pod
+
+import (
+	"sort"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Status is the structured state of the Pod collector
+type Status struct {
+	// AbnormalPods lists pods that are pending, failed, unknown or stuck terminating
+	AbnormalPods []AbnormalPod `json:"abnormalPods"`
+}
+
+// AbnormalPod describes a single abnormal pod
+type AbnormalPod struct {
+	Namespace          string          `json:"namespace"`
+	Name               string          `json:"name"`
+	Node               string          `json:"node,omitempty"`
+	Phase              corev1.PodPhase `json:"phase"`
+	WorkloadKind       string          `json:"workloadKind,omitempty"`
+	Workload           string          `json:"workload,omitempty"`
+	StuckTerminating   bool            `json:"stuckTerminating"`
+	TerminatingSeconds float64         `json:"terminatingSeconds,omitempty"`
+	Finalizers         []string        `json:"finalizers,omitempty"`
+}
+
+// Status returns the currently abnormal pods, sorted by namespace and name
+func (c *Collector) Status() any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	status := Status{AbnormalPods: []AbnormalPod{}}
+
+	for _, pod := range c.pods {
+		if _, ok := c.excluded[pod.Namespace]; ok {
+			continue
+		}
+
+		terminating, stuck := stuckTerminating(pod, now, c.config.StuckTerminatingThreshold)
+		if !stuck && !abnormalPhase(pod.Status.Phase) {
+			continue
+		}
+
+		kind, name := util.WorkloadOf(pod)
+
+		abnormal := AbnormalPod{
+			Namespace:        pod.Namespace,
+			Name:             pod.Name,
+			Node:             pod.Spec.NodeName,
+			Phase:            pod.Status.Phase,
+			WorkloadKind:     kind,
+			Workload:         name,
+			StuckTerminating: stuck,
+		}
+
+		if stuck {
+			abnormal.TerminatingSeconds = terminating.Seconds()
+			abnormal.Finalizers = pod.Finalizers
+		}
+
+		status.AbnormalPods = append(status.AbnormalPods, abnormal)
+	}
+
+	sort.Slice(status.AbnormalPods, func(i, j int) bool {
+		a, b := status.AbnormalPods[i], status.AbnormalPods[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+
+		return a.Name < b.Name
+	})
+
+	return status
+}
+
+// abnormalPhase returns whether a pod phase indicates a problem
+func abnormalPhase(phase corev1.PodPhase) bool {
+	switch phase {
+	case corev1.PodPending, corev1.PodFailed, corev1.PodUnknown:
+		return true
+	default:
+		return false
+	}
+}
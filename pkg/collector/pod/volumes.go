@@ -0,0 +1,441 @@
+package pod
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/sampling"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// volumeFailureReasons are the reasons of the Warning events reported for a
+// pod when one of its volumes cannot be attached, mounted or mapped
+var volumeFailureReasons = []string{"FailedAttachVolume", "FailedMount", "FailedMapVolume"}
+
+var (
+	// eventVolumePattern matches the volume of messages such as
+	// `MountVolume.SetUp failed for volume "data" : ...` or
+	// `AttachVolume.Attach failed for volume "pvc-0a1b" : ...`
+	eventVolumePattern = regexp.MustCompile(`for volume "([^"]+)"`)
+	// eventUnmountedPattern matches the volumes of messages such as
+	// `Unable to attach or mount volumes: unmounted volumes=[data], unattached volumes=[data kube-api-access-x]: ...`
+	eventUnmountedPattern = regexp.MustCompile(`unmounted volumes=\[([^\]]*)\]`)
+)
+
+// volumeFailure is the last failure reported for a volume of a pod
+type volumeFailure struct {
+	reason   string
+	lastSeen time.Time
+}
+
+// podVolumeFailure identifies a failing volume of a pod
+type podVolumeFailure struct {
+	volume string
+	claim  string
+	reason string
+}
+
+// volumeFailureKey identifies a storage class series of pods waiting for volumes
+type volumeFailureKey struct {
+	storageClass string
+	reason       string
+}
+
+// volumeEventSelectors returns the field selectors narrowing the event watch
+// to the volume failures of pods, one per reason since field selectors cannot OR
+func volumeEventSelectors(excludedNamespaces []string) []string {
+	pods := fields.OneTermEqualSelector("involvedObject.kind", "Pod")
+	if exclusion := util.NamespaceExclusionSelector(excludedNamespaces); exclusion != nil {
+		pods = fields.AndSelectors(pods, exclusion)
+	}
+
+	selectors := make([]string, 0, len(volumeFailureReasons))
+	for _, reason := range volumeFailureReasons {
+		selectors = append(selectors, fields.AndSelectors(
+			pods,
+			fields.OneTermEqualSelector("reason", reason),
+		).String())
+	}
+
+	return selectors
+}
+
+// eventVolumes returns the volumes named by a volume failure message, as pod
+// volume names or persistent volume names. A message naming no volume returns
+// a single empty name.
+func eventVolumes(message string) []string {
+	if match := eventVolumePattern.FindStringSubmatch(message); match != nil {
+		return []string{match[1]}
+	}
+
+	if match := eventUnmountedPattern.FindStringSubmatch(message); match != nil {
+		if volumes := strings.Fields(match[1]); len(volumes) > 0 {
+			return volumes
+		}
+	}
+
+	return []string{""}
+}
+
+// eventLastSeen returns when an event last occurred
+func eventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// trimVolumeEvent keeps only the fields needed to attribute volume failures
+func trimVolumeEvent(obj any) (any, error) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         event.Namespace,
+			Name:              event.Name,
+			UID:               event.UID,
+			ResourceVersion:   event.ResourceVersion,
+			CreationTimestamp: event.CreationTimestamp,
+		},
+		InvolvedObject: corev1.ObjectReference{UID: event.InvolvedObject.UID},
+		Reason:         event.Reason,
+		Message:        event.Message,
+		LastTimestamp:  event.LastTimestamp,
+		EventTime:      event.EventTime,
+		Series:         event.Series,
+	}, nil
+}
+
+// trimVolumes keeps the persistent volume claims of the pod volumes, the
+// scheduling condition and whether containers started, needed for volume
+// failure metrics. Container statuses already kept for image inventory
+// metrics are completed in place.
+func trimVolumes(pod *corev1.Pod, transformed *corev1.Pod) {
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			transformed.Spec.Volumes = append(transformed.Spec.Volumes, corev1.Volume{
+				Name: volume.Name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: volume.PersistentVolumeClaim.ClaimName,
+					},
+				},
+			})
+		case volume.Ephemeral != nil:
+			transformed.Spec.Volumes = append(transformed.Spec.Volumes, corev1.Volume{
+				Name:         volume.Name,
+				VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}},
+			})
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled {
+			transformed.Status.Conditions = []corev1.PodCondition{{
+				Type:               condition.Type,
+				Status:             condition.Status,
+				LastTransitionTime: condition.LastTransitionTime,
+			}}
+		}
+	}
+
+	for i, status := range pod.Status.ContainerStatuses {
+		if i == len(transformed.Status.ContainerStatuses) {
+			transformed.Status.ContainerStatuses = append(transformed.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name: status.Name,
+			})
+		}
+
+		transformed.Status.ContainerStatuses[i].State = trimContainerState(status.State)
+	}
+
+	for _, status := range pod.Status.InitContainerStatuses {
+		transformed.Status.InitContainerStatuses = append(transformed.Status.InitContainerStatuses, corev1.ContainerStatus{
+			Name:  status.Name,
+			State: trimContainerState(status.State),
+		})
+	}
+}
+
+// trimContainerState keeps whether a container is running or terminated
+func trimContainerState(state corev1.ContainerState) corev1.ContainerState {
+	var trimmed corev1.ContainerState
+
+	if state.Running != nil {
+		trimmed.Running = &corev1.ContainerStateRunning{}
+	}
+
+	if state.Terminated != nil {
+		trimmed.Terminated = &corev1.ContainerStateTerminated{}
+	}
+
+	return trimmed
+}
+
+// trimPVC keeps only the fields needed to resolve the storage class of a volume
+func trimPVC(obj any) (any, error) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       pvc.Namespace,
+			Name:            pvc.Name,
+			UID:             pvc.UID,
+			ResourceVersion: pvc.ResourceVersion,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeName:       pvc.Spec.VolumeName,
+		},
+	}, nil
+}
+
+// waitingForVolumes returns whether a pod is scheduled but none of its
+// containers started yet, the state of pods whose volumes are not ready
+func waitingForVolumes(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName == "" {
+		return false
+	}
+
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Running != nil || status.State.Terminated != nil {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// scheduledAt returns when a pod was scheduled, zero when unknown
+func scheduledAt(pod *corev1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+
+	return time.Time{}
+}
+
+// claimName returns the persistent volume claim of a pod volume, empty for
+// other volumes. Generic ephemeral volumes use the claim created for the pod.
+func claimName(pod *corev1.Pod, volume corev1.Volume) string {
+	switch {
+	case volume.PersistentVolumeClaim != nil:
+		return volume.PersistentVolumeClaim.ClaimName
+	case volume.Ephemeral != nil:
+		return pod.Name + "-" + volume.Name
+	default:
+		return ""
+	}
+}
+
+// handleVolumeEvent records the volumes of a pod reported by a volume failure event
+func (c *Collector) handleVolumeEvent(obj any) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to Event")
+		return
+	}
+
+	sampling.Sample(collectorName, event)
+
+	uid := event.InvolvedObject.UID
+	lastSeen := eventLastSeen(event)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneVolumeFailures(time.Now())
+
+	failures, ok := c.volumeFailures[uid]
+	if !ok {
+		failures = make(map[string]volumeFailure)
+		c.volumeFailures[uid] = failures
+	}
+
+	for _, volume := range eventVolumes(event.Message) {
+		if previous, ok := failures[volume]; ok && previous.lastSeen.After(lastSeen) {
+			continue
+		}
+
+		failures[volume] = volumeFailure{reason: event.Reason, lastSeen: lastSeen}
+	}
+}
+
+// handlePVC records or updates a tracked persistent volume claim
+func (c *Collector) handlePVC(obj any) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to PersistentVolumeClaim")
+		return
+	}
+
+	c.mu.Lock()
+	c.pvcs[podKey(pvc.Namespace, pvc.Name)] = pvc
+	c.mu.Unlock()
+}
+
+// handlePVCDelete removes a tracked persistent volume claim
+func (c *Collector) handlePVCDelete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.pvcs, podKey(pvc.Namespace, pvc.Name))
+	c.mu.Unlock()
+}
+
+// pruneVolumeFailures drops the failures older than the failure window, e.g.
+// of pods deleted before their events were received.
+// Must be called with c.mu held.
+func (c *Collector) pruneVolumeFailures(now time.Time) {
+	for uid, failures := range c.volumeFailures {
+		for volume, failure := range failures {
+			if now.Sub(failure.lastSeen) > c.config.VolumeFailureWindow {
+				delete(failures, volume)
+			}
+		}
+
+		if len(failures) == 0 {
+			delete(c.volumeFailures, uid)
+		}
+	}
+}
+
+// forgetVolumeFailures drops the volume failures of a pod whose containers
+// started or that is no longer pending.
+// Must be called with c.mu held.
+func (c *Collector) forgetVolumeFailures(pod *corev1.Pod, deleted bool) {
+	if deleted || !waitingForVolumes(pod) {
+		delete(c.volumeFailures, pod.UID)
+	}
+}
+
+// resolveVolume returns the pod volume and the persistent volume claim of a
+// volume named by a failure event, either a pod volume or the persistent
+// volume bound to one of its claims. Unknown volumes are returned as is.
+// Must be called with c.mu held.
+func (c *Collector) resolveVolume(pod *corev1.Pod, name string) (volume, claim string) {
+	for _, podVolume := range pod.Spec.Volumes {
+		claim := claimName(pod, podVolume)
+		if podVolume.Name == name {
+			return podVolume.Name, claim
+		}
+
+		if claim == "" {
+			continue
+		}
+
+		if pvc, ok := c.pvcs[podKey(pod.Namespace, claim)]; ok && pvc.Spec.VolumeName == name {
+			return podVolume.Name, claim
+		}
+	}
+
+	return name, ""
+}
+
+// storageClassOf returns the storage class of a persistent volume claim,
+// empty when unknown
+// Must be called with c.mu held.
+func (c *Collector) storageClassOf(namespace, claim string) string {
+	pvc, ok := c.pvcs[podKey(namespace, claim)]
+	if claim == "" || !ok || pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+
+	return *pvc.Spec.StorageClassName
+}
+
+// collectVolumeFailures emits the pods waiting for volumes that recently
+// failed to attach or mount, and their number per storage class.
+// Must be called with c.mu held.
+func (c *Collector) collectVolumeFailures(ch chan<- prometheus.Metric, now time.Time) {
+	counts := make(map[volumeFailureKey]float64)
+
+	for _, pod := range c.pods {
+		if _, ok := c.excluded[pod.Namespace]; ok {
+			continue
+		}
+
+		failures := c.volumeFailures[pod.UID]
+		if len(failures) == 0 || !waitingForVolumes(pod) {
+			continue
+		}
+
+		var waiting time.Duration
+		if scheduled := scheduledAt(pod); !scheduled.IsZero() {
+			waiting = now.Sub(scheduled)
+		}
+
+		// A volume may be named both by its pod volume name and by its
+		// persistent volume name
+		volumes := make(map[podVolumeFailure]struct{}, len(failures))
+
+		for name, failure := range failures {
+			if now.Sub(failure.lastSeen) > c.config.VolumeFailureWindow {
+				continue
+			}
+
+			volume, claim := c.resolveVolume(pod, name)
+			volumes[podVolumeFailure{volume: volume, claim: claim, reason: failure.reason}] = struct{}{}
+		}
+
+		for failure := range volumes {
+			storageClass := c.storageClassOf(pod.Namespace, failure.claim)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.podVolumeFailure,
+				prometheus.GaugeValue,
+				waiting.Seconds(),
+				pod.Namespace,
+				pod.Name,
+				pod.Spec.NodeName,
+				failure.volume,
+				failure.claim,
+				storageClass,
+				failure.reason,
+			)
+
+			counts[volumeFailureKey{storageClass: storageClass, reason: failure.reason}]++
+		}
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.podVolumeFailureCount,
+			prometheus.GaugeValue,
+			count,
+			key.storageClass,
+			key.reason,
+		)
+	}
+}
@@ -0,0 +1,115 @@
+//nolint:testpackage // Tests need access to private functions eventVolumes, waitingForVolumes and resolveVolume
+package pod
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestEventVolumes verifies the volumes named by volume failure messages are extracted
+func TestEventVolumes(t *testing.T) {
+	tests := map[string][]string{
+		`MountVolume.SetUp failed for volume "data" : rpc error: code = Internal`: {"data"},
+		`AttachVolume.Attach failed for volume "pvc-0a1b" : timed out`:            {"pvc-0a1b"},
+		"Unable to attach or mount volumes: unmounted volumes=[data logs], " +
+			"unattached volumes=[data logs kube-api-access-x]: timed out": {"data", "logs"},
+		"Unable to attach or mount volumes: unmounted volumes=[], timed out": {""},
+		"unexpected message": {""},
+	}
+
+	for message, expected := range tests {
+		if got := eventVolumes(message); !slices.Equal(got, expected) {
+			t.Errorf("eventVolumes(%q) = %v, expected %v", message, got, expected)
+		}
+	}
+}
+
+// TestWaitingForVolumes verifies only scheduled pending pods without started
+// containers are considered waiting for volumes
+func TestWaitingForVolumes(t *testing.T) {
+	pod := func(phase corev1.PodPhase, node string, state corev1.ContainerState) *corev1.Pod {
+		p := &corev1.Pod{}
+		p.Spec.NodeName = node
+		p.Status.Phase = phase
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", State: state}}
+
+		return p
+	}
+
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+
+	deleting := pod(corev1.PodPending, "worker-1", waiting)
+	deleting.DeletionTimestamp = &metav1.Time{}
+
+	tests := map[string]struct {
+		pod      *corev1.Pod
+		expected bool
+	}{
+		"waiting":     {pod(corev1.PodPending, "worker-1", waiting), true},
+		"unscheduled": {pod(corev1.PodPending, "", waiting), false},
+		"started":     {pod(corev1.PodPending, "worker-1", running), false},
+		"running":     {pod(corev1.PodRunning, "worker-1", running), false},
+		"deleting":    {deleting, false},
+	}
+
+	for name, tt := range tests {
+		if got := waitingForVolumes(tt.pod); got != tt.expected {
+			t.Errorf("%s: waitingForVolumes() = %v, expected %v", name, got, tt.expected)
+		}
+	}
+}
+
+// TestResolveVolume verifies volumes named by persistent volume names are
+// resolved to their pod volume, claim and storage class
+func TestResolveVolume(t *testing.T) {
+	storageClass := "ssd"
+
+	c := &Collector{
+		pvcs: map[string]*corev1.PersistentVolumeClaim{
+			"ns-a/data": {
+				Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass, VolumeName: "pvc-0a1b"},
+			},
+			"ns-a/app-0-cache": {
+				Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-2c3d"},
+			},
+		},
+	}
+
+	pod := &corev1.Pod{}
+	pod.Namespace = "ns-a"
+	pod.Name = "app-0"
+	pod.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+			},
+		},
+		{Name: "cache", VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}}},
+		{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+	}
+
+	tests := []struct {
+		name, volume, claim, storageClass string
+	}{
+		{"data", "data", "data", "ssd"},
+		{"pvc-0a1b", "data", "data", "ssd"},
+		{"pvc-2c3d", "cache", "app-0-cache", ""},
+		{"config", "config", "", ""},
+		{"unknown", "unknown", "", ""},
+	}
+
+	for _, tt := range tests {
+		volume, claim := c.resolveVolume(pod, tt.name)
+		storageClass := c.storageClassOf(pod.Namespace, claim)
+
+		if volume != tt.volume || claim != tt.claim || storageClass != tt.storageClass {
+			t.Errorf("resolveVolume(%q) = (%q, %q, %q), expected (%q, %q, %q)",
+				tt.name, volume, claim, storageClass, tt.volume, tt.claim, tt.storageClass)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxBodyBytes is the number of bytes of the HTTP body searched by the
+// bodyContains assertion
+const maxBodyBytes = 64 << 10
+
+// Failure reasons reported by the success metric
+const (
+	reasonTimeout    = "timeout"     // the probe did not complete within its timeout
+	reasonRefused    = "refused"     // the connection was refused
+	reasonBlocked    = "blocked"     // the target resolves to a private address
+	reasonDNS        = "dns"         // the name could not be resolved
+	reasonError      = "error"       // any other connection or protocol error
+	reasonStatusCode = "status_code" // the HTTP status code was not accepted
+	reasonBody       = "body"        // the HTTP body did not contain the expected text
+	reasonLatency    = "latency"     // the probe was slower than the maximum latency
+	reasonAddresses  = "addresses"   // the DNS lookup did not return the expected addresses
+)
+
+// errBlockedTarget is returned when dialing a private address is not allowed
+var errBlockedTarget = errors.New("connecting to private addresses is not allowed")
+
+// result is the outcome of a probe run
+type result struct {
+	ok         bool
+	reason     string // empty when ok
+	err        error
+	duration   time.Duration
+	statusCode int // HTTP probes only
+	time       time.Time
+}
+
+// prober runs probes
+type prober struct {
+	allowPrivate bool
+	resolver     *net.Resolver
+}
+
+// run runs a probe, bounded by its timeout
+func (p *prober) run(ctx context.Context, spec *probeSpec) result {
+	ctx, cancel := context.WithTimeout(ctx, spec.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var res result
+
+	switch spec.probeType {
+	case typeHTTP:
+		res = p.checkHTTP(ctx, spec)
+	case typeTCP:
+		res = p.checkTCP(ctx, spec)
+	case typeDNS:
+		res = p.checkDNS(ctx, spec)
+	}
+
+	res.duration = time.Since(start)
+	res.time = start
+
+	if res.err != nil {
+		res.reason = failureReason(ctx, res.err)
+		return res
+	}
+
+	if res.reason == "" && spec.assertions.maxLatency > 0 && res.duration > spec.assertions.maxLatency {
+		res.reason = reasonLatency
+	}
+
+	res.ok = res.reason == ""
+
+	return res
+}
+
+// dialer returns a dialer refusing private addresses unless they are allowed
+func (p *prober) dialer() *net.Dialer {
+	dialer := &net.Dialer{Resolver: p.resolver}
+	if p.allowPrivate {
+		return dialer
+	}
+
+	dialer.Control = func(_, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+
+		if ip := net.ParseIP(host); ip != nil && isPrivate(ip) {
+			return fmt.Errorf("%w: %s", errBlockedTarget, ip)
+		}
+
+		return nil
+	}
+
+	return dialer
+}
+
+// isPrivate returns whether an address is not publicly routable
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// checkHTTP requests the URL and checks the status code and body
+func (p *prober) checkHTTP(ctx context.Context, spec *probeSpec) result {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:       p.dialer().DialContext,
+			DisableKeepAlives: true,
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spec.target, nil)
+	if err != nil {
+		return result{err: err}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result{err: err}
+	}
+	defer resp.Body.Close()
+
+	res := result{statusCode: resp.StatusCode}
+
+	if !statusAccepted(resp.StatusCode, spec.assertions.statusCodes) {
+		res.reason = reasonStatusCode
+		return res
+	}
+
+	if spec.assertions.bodyContains == "" {
+		return res
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	if !strings.Contains(string(body), spec.assertions.bodyContains) {
+		res.reason = reasonBody
+	}
+
+	return res
+}
+
+// statusAccepted returns whether a status code is accepted, any status below
+// 400 being accepted when no codes are configured
+func statusAccepted(code int, accepted []int) bool {
+	if len(accepted) == 0 {
+		return code < http.StatusBadRequest
+	}
+
+	return slices.Contains(accepted, code)
+}
+
+// checkTCP opens a connection to the target
+func (p *prober) checkTCP(ctx context.Context, spec *probeSpec) result {
+	conn, err := p.dialer().DialContext(ctx, "tcp", spec.target)
+	if err != nil {
+		return result{err: err}
+	}
+
+	_ = conn.Close()
+
+	return result{}
+}
+
+// checkDNS resolves the target and checks the expected addresses are returned
+func (p *prober) checkDNS(ctx context.Context, spec *probeSpec) result {
+	resolver := p.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	addresses, err := resolver.LookupHost(ctx, spec.target)
+	if err != nil {
+		return result{err: err}
+	}
+
+	for _, expected := range spec.assertions.addresses {
+		if !slices.Contains(addresses, expected) {
+			return result{reason: reasonAddresses}
+		}
+	}
+
+	return result{}
+}
+
+// failureReason classifies the error of a failed probe
+func failureReason(ctx context.Context, err error) string {
+	var dnsErr *net.DNSError
+
+	switch {
+	case errors.Is(err, errBlockedTarget):
+		return reasonBlocked
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return reasonTimeout
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout {
+			return reasonTimeout
+		}
+
+		return reasonDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return reasonRefused
+	default:
+		return reasonError
+	}
+}
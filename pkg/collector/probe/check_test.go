@@ -0,0 +1,99 @@
+//nolint:testpackage // Tests need access to private functions
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte("status: ok"))
+	}))
+	defer server.Close()
+
+	allowed := &prober{allowPrivate: true}
+
+	tests := []struct {
+		name           string
+		prober         *prober
+		path           string
+		assertions     assertions
+		expectedOK     bool
+		expectedReason string
+	}{
+		{name: "success", prober: allowed, path: "/", expectedOK: true},
+		{name: "body found", prober: allowed, path: "/", assertions: assertions{bodyContains: "ok"}, expectedOK: true},
+		{name: "body missing", prober: allowed, path: "/", assertions: assertions{bodyContains: "ready"}, expectedReason: reasonBody},
+		{name: "error status", prober: allowed, path: "/missing", expectedReason: reasonStatusCode},
+		{name: "accepted status", prober: allowed, path: "/missing", assertions: assertions{statusCodes: []int{404}}, expectedOK: true},
+		{name: "latency", prober: allowed, path: "/", assertions: assertions{maxLatency: time.Nanosecond}, expectedReason: reasonLatency},
+		{name: "private target", prober: &prober{}, path: "/", expectedReason: reasonBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := tt.prober.run(context.Background(), &probeSpec{
+				probeType:  typeHTTP,
+				target:     server.URL + tt.path,
+				timeout:    5 * time.Second,
+				assertions: tt.assertions,
+			})
+
+			if res.ok != tt.expectedOK || res.reason != tt.expectedReason {
+				t.Errorf("Expected ok=%v reason=%q, got ok=%v reason=%q (err: %v)",
+					tt.expectedOK, tt.expectedReason, res.ok, res.reason, res.err)
+			}
+		})
+	}
+}
+
+func TestCheckTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	address := listener.Addr().String()
+	p := &prober{allowPrivate: true}
+	spec := &probeSpec{probeType: typeTCP, target: address, timeout: 5 * time.Second}
+
+	if res := p.run(context.Background(), spec); !res.ok {
+		t.Errorf("Expected the probe to succeed, got reason %q (err: %v)", res.reason, res.err)
+	}
+
+	listener.Close()
+
+	if res := p.run(context.Background(), spec); res.reason != reasonRefused {
+		t.Errorf("Expected a refused connection, got reason %q (err: %v)", res.reason, res.err)
+	}
+}
+
+func TestCheckDNS(t *testing.T) {
+	p := &prober{}
+	spec := &probeSpec{
+		probeType:  typeDNS,
+		target:     "localhost",
+		timeout:    5 * time.Second,
+		assertions: assertions{addresses: []string{"127.0.0.1"}},
+	}
+
+	if res := p.run(context.Background(), spec); !res.ok {
+		t.Errorf("Expected localhost to resolve to 127.0.0.1, got reason %q (err: %v)", res.reason, res.err)
+	}
+
+	spec.assertions.addresses = []string{"192.0.2.1"}
+
+	if res := p.run(context.Background(), spec); res.reason != reasonAddresses {
+		t.Errorf("Expected a missing address, got reason %q (err: %v)", res.reason, res.err)
+	}
+}
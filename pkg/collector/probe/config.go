@@ -0,0 +1,37 @@
+package probe
+
+import "time"
+
+// Config contains configuration for the probe collector
+type Config struct {
+	// Namespaces to watch Probe resources in (empty = all namespaces)
+	Namespaces      []string      `yaml:"namespaces"      env:"NAMESPACES"       envSeparator:","`
+	DefaultInterval time.Duration `yaml:"defaultInterval" env:"DEFAULT_INTERVAL"` // Interval of probes not setting one
+	MinInterval     time.Duration `yaml:"minInterval"     env:"MIN_INTERVAL"`     // Shorter probe intervals are raised to it
+	Timeout         time.Duration `yaml:"timeout"         env:"TIMEOUT"`          // Default and maximum timeout of a probe
+	Concurrency     int           `yaml:"concurrency"     env:"CONCURRENCY"`      // Maximum number of concurrent probes
+	// MaxProbesPerNamespace is the quota of probes run per namespace, in name
+	// order (0 = unlimited)
+	MaxProbesPerNamespace int `yaml:"maxProbesPerNamespace" env:"MAX_PROBES_PER_NAMESPACE"`
+	// AllowPrivateTargets allows HTTP and TCP probes to connect to loopback,
+	// private and link-local addresses (e.g. cluster IPs, cloud metadata)
+	AllowPrivateTargets bool `yaml:"allowPrivateTargets" env:"ALLOW_PRIVATE_TARGETS"`
+	// SummaryWindow exports the min, max, avg and last success and duration
+	// of the runs of each probe over this sliding window, so flaps between
+	// scrapes are captured (0 = disabled). Set it to the scrape interval.
+	SummaryWindow time.Duration `yaml:"summaryWindow" env:"SUMMARY_WINDOW"`
+}
+
+// NewDefaultConfig returns the default configuration for the probe collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces:            []string{},
+		DefaultInterval:       time.Minute,
+		MinInterval:           30 * time.Second,
+		Timeout:               10 * time.Second,
+		Concurrency:           10,
+		MaxProbesPerNamespace: 10,
+		AllowPrivateTargets:   false,
+	}
+}
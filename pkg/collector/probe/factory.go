@@ -0,0 +1,172 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "probe"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("HTTP, TCP and DNS uptime checks declared by tenants with Probe resources"),
+		registry.WithRBAC([]string{probeGVR.Group}, []string{probeGVR.Resource}, []string{"list", "watch"}),
+	)
+}
+
+// NewCollector creates a new probe collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.probe", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load probe collector config, using defaults")
+	}
+
+	restConfig, err := factoryCtx.GetRestConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, 0),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client: client,
+		config: cfg,
+		prober: &prober{allowPrivate: cfg.AllowPrivateTargets},
+		probes: make(map[string]*probeState),
+		stopCh: make(chan struct{}),
+		logger: factoryCtx.Logger,
+	}
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and probe state to support restart
+			c.stopCh = make(chan struct{})
+
+			c.mu.Lock()
+			c.probes = make(map[string]*probeState)
+			c.mu.Unlock()
+
+			// Fail fast when the CRD is not installed, the informers would
+			// never sync
+			_, err := c.client.Resource(probeGVR).Namespace(c.firstNamespace()).
+				List(ctx, metav1.ListOptions{Limit: 1})
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("probe CRD %s is not installed: %w", probeGVR.GroupResource(), err)
+			}
+
+			factories := c.informerFactories()
+
+			c.informers = make([]cache.SharedIndexInformer, 0, len(factories))
+
+			for _, factory := range factories {
+				informer := factory.ForResource(probeGVR).Informer()
+
+				c.InstrumentInformer(probeGVR.GroupResource().String(), informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleProbe,
+					UpdateFunc: func(_, newObj any) { c.handleProbe(newObj) },
+					DeleteFunc: c.handleProbeDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			c.logger.WithField("informers", len(c.informers)).
+				Info("Waiting for probe informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				close(c.stopCh)
+				return errors.New("failed to sync probe informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			go c.pollLoop(ctx)
+
+			c.logger.Info("Probe collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
+
+// firstNamespace returns the first watched namespace, or all namespaces
+func (c *Collector) firstNamespace() string {
+	if len(c.config.Namespaces) == 0 {
+		return metav1.NamespaceAll
+	}
+
+	return c.config.Namespaces[0]
+}
+
+// informerFactories returns one informer factory per configured namespace, or
+// a single cluster-wide one
+func (c *Collector) informerFactories() []dynamicinformer.DynamicSharedInformerFactory {
+	namespaces := c.config.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	seen := make(map[string]bool, len(namespaces))
+	factories := make([]dynamicinformer.DynamicSharedInformerFactory, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		if seen[namespace] {
+			continue
+		}
+
+		seen[namespace] = true
+		factories = append(factories, dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+			c.client, 10*time.Minute, namespace, nil,
+		))
+	}
+
+	return factories
+}
@@ -0,0 +1,456 @@
+package probe
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// scheduleTick is the interval at which due probes are looked for
+const scheduleTick = 5 * time.Second
+
+// Rejection reasons reported by the rejected metric
+const (
+	rejectedInvalid = "invalid" // the spec could not be parsed
+	rejectedQuota   = "quota"   // the namespace has more probes than its quota
+)
+
+// probeState is a Probe resource and its last result
+type probeState struct {
+	namespace string
+	name      string
+	spec      *probeSpec
+	err       error // set when the spec is invalid
+	nextRun   time.Time
+	result    *result
+
+	// Results of the runs over the summary window (nil when disabled)
+	successes *util.Window
+	durations *util.Window
+}
+
+// Collector runs the probes declared by Probe resources
+type Collector struct {
+	*base.BaseCollector
+
+	client    dynamic.Interface
+	config    *Config
+	prober    *prober
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	mu     sync.RWMutex
+	probes map[string]*probeState // key: namespace/name
+
+	// Metrics
+	probeSuccess    *prometheus.Desc
+	probeRejected   *prometheus.Desc
+	probeDuration   *prometheus.Desc
+	probeStatusCode *prometheus.Desc
+	probeLastRun    *prometheus.Desc
+
+	probeSuccessWindow  *prometheus.Desc
+	probeDurationWindow *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	labels := []string{"namespace", "name", "type", "target"}
+
+	c.probeSuccess = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "success"),
+		"Whether the probe succeeded (1=success, 0=failure), reason is timeout, refused, blocked, dns, error, "+
+			"status_code, body, latency or addresses on failure",
+		append(labels, "reason"),
+		nil,
+	)
+	c.probeDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "duration_seconds"),
+		"Duration of the last probe run",
+		labels,
+		nil,
+	)
+	c.probeStatusCode = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "http_status_code"),
+		"HTTP status code returned to the last run of a url probe",
+		labels,
+		nil,
+	)
+	c.probeLastRun = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "last_run_timestamp_seconds"),
+		"Unix timestamp of the last probe run",
+		labels,
+		nil,
+	)
+	c.probeSuccessWindow = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "success_window"),
+		"Min, max, avg or last success (1=success, 0=failure) of the probe runs over the summary window",
+		append(labels, "stat"),
+		nil,
+	)
+	c.probeDurationWindow = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "duration_seconds_window"),
+		"Min, max, avg or last duration of the probe runs over the summary window",
+		append(labels, "stat"),
+		nil,
+	)
+	c.probeRejected = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "probe", "rejected"),
+		"Probe resources not run, because their spec is invalid or their namespace exceeds its quota",
+		[]string{"namespace", "name", "reason"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.probeSuccess)
+	c.MustRegisterDesc(c.probeDuration)
+	c.MustRegisterDesc(c.probeStatusCode)
+	c.MustRegisterDesc(c.probeLastRun)
+	c.MustRegisterDesc(c.probeRejected)
+
+	if c.config.SummaryWindow > 0 {
+		c.MustRegisterDesc(c.probeSuccessWindow)
+		c.MustRegisterDesc(c.probeDurationWindow)
+	}
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Interval returns the polling interval: due probes are looked for every
+// scheduleTick, a cycle lasting at most the probe timeout
+func (c *Collector) Interval() time.Duration {
+	return max(scheduleTick, c.config.Timeout)
+}
+
+// handleProbe parses an added or updated Probe. Changing the spec resets the
+// result and runs the probe on the next cycle.
+func (c *Collector) handleProbe(obj any) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	spec, err := parseSpec(u, c.config)
+	key := u.GetNamespace() + "/" + u.GetName()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if state, ok := c.probes[key]; ok && reflect.DeepEqual(state.spec, spec) && sameError(state.err, err) {
+		return
+	}
+
+	if err != nil {
+		c.logger.WithError(err).WithField("probe", key).Warn("Invalid probe spec")
+	}
+
+	state := &probeState{
+		namespace: u.GetNamespace(),
+		name:      u.GetName(),
+		spec:      spec,
+		err:       err,
+	}
+
+	if c.config.SummaryWindow > 0 {
+		state.successes = util.NewWindow(c.config.SummaryWindow)
+		state.durations = util.NewWindow(c.config.SummaryWindow)
+	}
+
+	c.probes[key] = state
+}
+
+// handleProbeDelete drops a deleted Probe
+func (c *Collector) handleProbeDelete(obj any) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		u, ok = tombstone.Obj.(*unstructured.Unstructured)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not Unstructured")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.probes, u.GetNamespace()+"/"+u.GetName())
+}
+
+// sameError returns whether two spec errors are equal
+func sameError(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Error() == b.Error()
+}
+
+// overQuota returns the keys of the valid probes exceeding the quota of their
+// namespace, the first probes in name order being admitted.
+// Must be called with c.mu held.
+func (c *Collector) overQuota() map[string]bool {
+	if c.config.MaxProbesPerNamespace <= 0 {
+		return nil
+	}
+
+	byNamespace := make(map[string][]string)
+
+	for key, state := range c.probes {
+		if state.err == nil {
+			byNamespace[state.namespace] = append(byNamespace[state.namespace], key)
+		}
+	}
+
+	rejected := make(map[string]bool)
+
+	for _, keys := range byNamespace {
+		if len(keys) <= c.config.MaxProbesPerNamespace {
+			continue
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys[c.config.MaxProbesPerNamespace:] {
+			rejected[key] = true
+		}
+	}
+
+	return rejected
+}
+
+// Poll runs the probes that are due, within the quota of their namespace
+func (c *Collector) Poll(ctx context.Context) error {
+	now := time.Now()
+
+	type run struct {
+		state *probeState
+		spec  *probeSpec
+	}
+
+	var due []run
+
+	c.mu.Lock()
+
+	rejected := c.overQuota()
+
+	for key, state := range c.probes {
+		if state.err != nil || rejected[key] {
+			state.result = nil
+			continue
+		}
+
+		if now.Before(state.nextRun) {
+			continue
+		}
+
+		state.nextRun = now.Add(state.spec.interval)
+		due = append(due, run{state: state, spec: state.spec})
+	}
+
+	c.mu.Unlock()
+
+	if len(due) == 0 {
+		return nil
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(c.config.Concurrency, 1))
+	)
+
+	for _, r := range due {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res := c.prober.run(ctx, r.spec)
+			if !res.ok {
+				c.logger.WithError(res.err).WithFields(log.Fields{
+					"probe":  r.state.namespace + "/" + r.state.name,
+					"target": r.spec.target,
+					"reason": res.reason,
+				}).Debug("Probe failed")
+			}
+
+			// The state is replaced when the spec changes, the result of
+			// the previous spec is then dropped
+			c.mu.Lock()
+			r.state.result = &res
+			if r.state.successes != nil {
+				r.state.successes.Add(res.time, boolToFloat64(res.ok))
+				r.state.durations.Add(res.time, res.duration.Seconds())
+			}
+			c.mu.Unlock()
+		})
+	}
+
+	wg.Wait()
+
+	c.logger.WithField("count", len(due)).Debug("Probes completed")
+
+	return nil
+}
+
+// pollLoop runs the polling loop
+func (c *Collector) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	for {
+		c.pollOnce(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollOnce runs one poll cycle
+func (c *Collector) pollOnce(ctx context.Context) {
+	if err := c.PollOnce(ctx, c.Poll); err != nil {
+		c.logger.WithError(err).Warn("Failed to run probes")
+	}
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	rejected := c.overQuota()
+
+	for key, state := range c.probes {
+		if state.err != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.probeRejected, prometheus.GaugeValue, 1, state.namespace, state.name, rejectedInvalid,
+			)
+
+			continue
+		}
+
+		if rejected[key] {
+			ch <- prometheus.MustNewConstMetric(
+				c.probeRejected, prometheus.GaugeValue, 1, state.namespace, state.name, rejectedQuota,
+			)
+
+			continue
+		}
+
+		res := state.result
+		if res == nil {
+			continue
+		}
+
+		labels := []string{state.namespace, state.name, state.spec.probeType, state.spec.target}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.probeSuccess,
+			prometheus.GaugeValue,
+			boolToFloat64(res.ok),
+			append(labels, res.reason)...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.probeDuration,
+			prometheus.GaugeValue,
+			res.duration.Seconds(),
+			labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.probeLastRun,
+			prometheus.GaugeValue,
+			float64(res.time.Unix()),
+			labels...,
+		)
+
+		if res.statusCode != 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.probeStatusCode,
+				prometheus.GaugeValue,
+				float64(res.statusCode),
+				labels...,
+			)
+		}
+
+		if state.successes != nil {
+			c.collectWindow(ch, c.probeSuccessWindow, state.successes, now, labels)
+			c.collectWindow(ch, c.probeDurationWindow, state.durations, now, labels)
+		}
+	}
+}
+
+// collectWindow emits the summary of the runs of a probe over the summary
+// window, one series per statistic
+func (c *Collector) collectWindow(
+	ch chan<- prometheus.Metric,
+	desc *prometheus.Desc,
+	window *util.Window,
+	now time.Time,
+	labels []string,
+) {
+	summary, ok := window.Summary(now)
+	if !ok {
+		return
+	}
+
+	stats := []struct {
+		name  string
+		value float64
+	}{
+		{"min", summary.Min},
+		{"max", summary.Max},
+		{"avg", summary.Avg},
+		{"last", summary.Last},
+	}
+
+	for _, stat := range stats {
+		ch <- prometheus.MustNewConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			stat.value,
+			append(labels, stat.name)...,
+		)
+	}
+}
+
+// boolToFloat64 converts a boolean to a float64
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+
+	return 0.0
+}
@@ -0,0 +1,124 @@
+//nolint:testpackage // Tests need access to private functions
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestQuotaAndScheduling(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.MaxProbesPerNamespace = 2
+
+	c := &Collector{
+		config: cfg,
+		prober: &prober{},
+		probes: make(map[string]*probeState),
+		logger: log.NewEntry(log.StandardLogger()),
+	}
+
+	for _, name := range []string{"c", "a", "b"} {
+		c.handleProbe(newProbe("ns-a", name, map[string]any{"dns": "localhost"}))
+	}
+
+	c.handleProbe(newProbe("ns-a", "invalid", map[string]any{}))
+	c.handleProbe(newProbe("ns-b", "d", map[string]any{"dns": "localhost"}))
+
+	rejected := c.overQuota()
+	if len(rejected) != 1 || !rejected["ns-a/c"] {
+		t.Errorf("Expected ns-a/c over quota, got %v", rejected)
+	}
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Unexpected poll error: %v", err)
+	}
+
+	for key, expected := range map[string]bool{"ns-a/a": true, "ns-a/b": true, "ns-a/c": false, "ns-a/invalid": false, "ns-b/d": true} {
+		if ran := c.probes[key].result != nil; ran != expected {
+			t.Errorf("Expected %s run=%v, got %v", key, expected, ran)
+		}
+	}
+
+	// Probes are not run again before their interval
+	first := c.probes["ns-a/a"].result
+
+	if err := c.Poll(context.Background()); err != nil {
+		t.Fatalf("Unexpected poll error: %v", err)
+	}
+
+	if c.probes["ns-a/a"].result != first {
+		t.Error("Expected the probe not to run again before its interval")
+	}
+
+	// Unchanged specs keep their result, changed ones are reset
+	c.handleProbe(newProbe("ns-a", "a", map[string]any{"dns": "localhost"}))
+
+	if c.probes["ns-a/a"].result != first {
+		t.Error("Expected an unchanged spec to keep its result")
+	}
+
+	c.handleProbe(newProbe("ns-a", "a", map[string]any{"dns": "localhost", "interval": "5m"}))
+
+	if c.probes["ns-a/a"].result != nil {
+		t.Error("Expected a changed spec to reset the result")
+	}
+
+	c.handleProbeDelete(newProbe("ns-a", "a", nil))
+
+	if _, ok := c.probes["ns-a/a"]; ok {
+		t.Error("Expected the deleted probe to be dropped")
+	}
+}
+
+// TestSummaryWindow verifies every run is recorded in the summary window
+func TestSummaryWindow(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.SummaryWindow = time.Minute
+
+	c := &Collector{
+		config: cfg,
+		prober: &prober{},
+		probes: make(map[string]*probeState),
+		logger: log.NewEntry(log.StandardLogger()),
+	}
+
+	c.handleProbe(newProbe("ns-a", "a", map[string]any{"dns": "localhost"}))
+	state := c.probes["ns-a/a"]
+
+	for range 3 {
+		state.nextRun = time.Time{}
+
+		if err := c.Poll(context.Background()); err != nil {
+			t.Fatalf("Unexpected poll error: %v", err)
+		}
+	}
+
+	if summary, ok := state.successes.Summary(time.Now()); !ok || summary.Count != 3 {
+		t.Fatalf("Expected 3 runs in the window, got %+v", summary)
+	}
+
+	desc := prometheus.NewDesc("probe_success_window", "", []string{"namespace", "stat"}, nil)
+	ch := make(chan prometheus.Metric, 10)
+	c.collectWindow(ch, desc, state.successes, time.Now(), []string{"ns-a"})
+	close(ch)
+
+	stats := make(map[string]bool)
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		stats[m.GetLabel()[1].GetValue()] = true
+	}
+
+	if len(stats) != 4 || !stats["min"] || !stats["max"] || !stats["avg"] || !stats["last"] {
+		t.Errorf("Expected min, max, avg and last series, got %v", stats)
+	}
+}
@@ -0,0 +1,223 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// probeGVR is the GroupVersionResource of Probe resources
+var probeGVR = schema.GroupVersionResource{
+	Group:    "monitoring.sealos.io",
+	Version:  "v1alpha1",
+	Resource: "probes",
+}
+
+// Probe types, one per target field of the spec
+const (
+	typeHTTP = "http"
+	typeTCP  = "tcp"
+	typeDNS  = "dns"
+)
+
+// probeSpec is the parsed spec of a Probe resource
+type probeSpec struct {
+	probeType  string
+	target     string // URL, host:port or DNS name
+	interval   time.Duration
+	timeout    time.Duration
+	assertions assertions
+}
+
+// assertions are the conditions a probe result must meet to succeed
+type assertions struct {
+	// statusCodes are the accepted HTTP status codes, any status below 400
+	// when empty
+	statusCodes []int
+	// bodyContains must be found in the first maxBodyBytes of the HTTP body
+	bodyContains string
+	// maxLatency is the maximum probe duration (0 = unbounded)
+	maxLatency time.Duration
+	// addresses must all be returned by the DNS lookup
+	addresses []string
+}
+
+// parseSpec parses and validates the spec of a Probe resource. Intervals
+// below the minimum are raised to it, timeouts are bounded by the configured
+// timeout and the interval.
+func parseSpec(obj *unstructured.Unstructured, cfg *Config) (*probeSpec, error) {
+	spec := &probeSpec{
+		interval: cfg.DefaultInterval,
+		timeout:  cfg.Timeout,
+	}
+
+	targets := 0
+
+	for _, probeType := range []string{typeHTTP, typeTCP, typeDNS} {
+		field := probeType
+		if probeType == typeHTTP {
+			field = "url"
+		}
+
+		target, found, err := unstructured.NestedString(obj.Object, "spec", field)
+		if err != nil {
+			return nil, fmt.Errorf("spec.%s: %w", field, err)
+		}
+
+		if !found || target == "" {
+			continue
+		}
+
+		targets++
+		spec.probeType = probeType
+		spec.target = target
+	}
+
+	if targets != 1 {
+		return nil, errors.New("exactly one of spec.url, spec.tcp and spec.dns must be set")
+	}
+
+	if err := validateTarget(spec.probeType, spec.target); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if spec.interval, err = nestedDuration(obj, spec.interval, "spec", "interval"); err != nil {
+		return nil, err
+	}
+
+	spec.interval = max(spec.interval, cfg.MinInterval)
+
+	if spec.timeout, err = nestedDuration(obj, spec.timeout, "spec", "timeout"); err != nil {
+		return nil, err
+	}
+
+	spec.timeout = min(spec.timeout, cfg.Timeout, spec.interval)
+
+	if spec.assertions, err = parseAssertions(obj, spec.probeType); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// validateTarget checks the target of a probe
+func validateTarget(probeType, target string) error {
+	switch probeType {
+	case typeHTTP:
+		u, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("spec.url: %w", err)
+		}
+
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("spec.url: %q is not an absolute http or https URL", target)
+		}
+	case typeTCP:
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			return fmt.Errorf("spec.tcp: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parseAssertions parses spec.assertions, rejecting the assertions that do
+// not apply to the probe type
+func parseAssertions(obj *unstructured.Unstructured, probeType string) (assertions, error) {
+	var a assertions
+
+	codes, _, err := unstructured.NestedFieldNoCopy(obj.Object, "spec", "assertions", "statusCodes")
+	if err != nil {
+		return a, fmt.Errorf("spec.assertions.statusCodes: %w", err)
+	}
+
+	if codes != nil {
+		items, ok := codes.([]any)
+		if !ok {
+			return a, errors.New("spec.assertions.statusCodes: not a list")
+		}
+
+		for _, item := range items {
+			code, ok := toInt(item)
+			if !ok {
+				return a, fmt.Errorf("spec.assertions.statusCodes: %v is not a status code", item)
+			}
+
+			a.statusCodes = append(a.statusCodes, code)
+		}
+	}
+
+	if a.bodyContains, _, err = unstructured.NestedString(obj.Object, "spec", "assertions", "bodyContains"); err != nil {
+		return a, fmt.Errorf("spec.assertions.bodyContains: %w", err)
+	}
+
+	if a.maxLatency, err = nestedDuration(obj, 0, "spec", "assertions", "maxLatency"); err != nil {
+		return a, err
+	}
+
+	if a.addresses, _, err = unstructured.NestedStringSlice(obj.Object, "spec", "assertions", "addresses"); err != nil {
+		return a, fmt.Errorf("spec.assertions.addresses: %w", err)
+	}
+
+	if probeType != typeHTTP && (len(a.statusCodes) > 0 || a.bodyContains != "") {
+		return a, errors.New("spec.assertions: statusCodes and bodyContains only apply to url probes")
+	}
+
+	if probeType != typeDNS && len(a.addresses) > 0 {
+		return a, errors.New("spec.assertions: addresses only apply to dns probes")
+	}
+
+	return a, nil
+}
+
+// nestedDuration reads a duration string (e.g. "30s"), returning def when
+// the field is not set
+func nestedDuration(obj *unstructured.Unstructured, def time.Duration, fields ...string) (time.Duration, error) {
+	path := strings.Join(fields, ".")
+
+	value, found, err := unstructured.NestedString(obj.Object, fields...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if !found || value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("%s: must be positive", path)
+	}
+
+	return d, nil
+}
+
+// toInt converts an integer JSON number to an int
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case int:
+		return n, true
+	case float64:
+		if n != float64(int(n)) {
+			return 0, false
+		}
+
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,113 @@
+//nolint:testpackage // Tests need access to private functions
+package probe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newProbe returns a Probe resource with the given spec
+func newProbe(namespace, name string, spec map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+func TestParseSpec(t *testing.T) {
+	cfg := NewDefaultConfig()
+
+	tests := []struct {
+		name     string
+		spec     map[string]any
+		expected *probeSpec
+		wantErr  bool
+	}{
+		{
+			name: "url with assertions",
+			spec: map[string]any{
+				"url":      "https://api.example.com/healthz",
+				"interval": "2m",
+				"timeout":  "5s",
+				"assertions": map[string]any{
+					"statusCodes":  []any{int64(200), int64(204)},
+					"bodyContains": "ok",
+					"maxLatency":   "500ms",
+				},
+			},
+			expected: &probeSpec{
+				probeType: typeHTTP,
+				target:    "https://api.example.com/healthz",
+				interval:  2 * time.Minute,
+				timeout:   5 * time.Second,
+				assertions: assertions{
+					statusCodes:  []int{200, 204},
+					bodyContains: "ok",
+					maxLatency:   500 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name: "defaults and bounds",
+			spec: map[string]any{"tcp": "db.example.com:5432", "interval": "1s", "timeout": "1m"},
+			expected: &probeSpec{
+				probeType: typeTCP,
+				target:    "db.example.com:5432",
+				interval:  cfg.MinInterval,
+				timeout:   cfg.Timeout,
+			},
+		},
+		{
+			name: "dns addresses",
+			spec: map[string]any{
+				"dns":        "example.com",
+				"assertions": map[string]any{"addresses": []any{"192.0.2.1"}},
+			},
+			expected: &probeSpec{
+				probeType:  typeDNS,
+				target:     "example.com",
+				interval:   cfg.DefaultInterval,
+				timeout:    cfg.Timeout,
+				assertions: assertions{addresses: []string{"192.0.2.1"}},
+			},
+		},
+		{name: "no target", spec: map[string]any{}, wantErr: true},
+		{name: "two targets", spec: map[string]any{"url": "https://example.com", "dns": "example.com"}, wantErr: true},
+		{name: "relative url", spec: map[string]any{"url": "/healthz"}, wantErr: true},
+		{name: "tcp without port", spec: map[string]any{"tcp": "db.example.com"}, wantErr: true},
+		{name: "invalid interval", spec: map[string]any{"dns": "example.com", "interval": "often"}, wantErr: true},
+		{
+			name: "status codes on tcp",
+			spec: map[string]any{
+				"tcp":        "db.example.com:5432",
+				"assertions": map[string]any{"statusCodes": []any{int64(200)}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := parseSpec(newProbe("ns-a", "p", tt.spec), cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", spec)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(spec, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package pvc
+
+// Config contains configuration for the PVC collector
+type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces"              env:"NAMESPACES"                envSeparator:","`
+	// IncludeSystemNamespaces watches claims in system namespaces (kube-system, sealos-system, ...)
+	// when Namespaces is empty; they are excluded by default
+	IncludeSystemNamespaces bool `yaml:"includeSystemNamespaces" env:"INCLUDE_SYSTEM_NAMESPACES"`
+	// PersistentVolumes watches the cluster-scoped PersistentVolumes and
+	// reports their reclaim policy and phase
+	PersistentVolumes bool `yaml:"persistentVolumes"       env:"PERSISTENT_VOLUMES"`
+}
+
+// NewDefaultConfig returns the default configuration for PVC collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces:        []string{},
+		PersistentVolumes: true,
+	}
+}
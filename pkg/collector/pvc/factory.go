@@ -0,0 +1,162 @@
+package pvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "pvc"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("PersistentVolumeClaim phase, capacity and time pending, and PersistentVolume reclaim policy and phase"),
+		registry.WithRBAC([]string{""}, []string{"persistentvolumeclaims"}, []string{"list", "watch"}),
+		registry.WithRBAC([]string{""}, []string{"persistentvolumes"}, []string{"list", "watch"}),
+	)
+}
+
+// NewCollector creates a new PVC collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.pvc", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load pvc collector config, using defaults")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client: client,
+		config: cfg,
+		claims: make(map[string]*corev1.PersistentVolumeClaim),
+		pvs:    make(map[string]*corev1.PersistentVolume),
+		stopCh: make(chan struct{}),
+		logger: factoryCtx.Logger,
+	}
+
+	c.mu.Instrument(c.BaseCollector)
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and state to support restart
+			c.stopCh = make(chan struct{})
+			c.informers = nil
+
+			c.mu.Lock()
+			c.claims = make(map[string]*corev1.PersistentVolumeClaim)
+			c.pvs = make(map[string]*corev1.PersistentVolume)
+			c.mu.Unlock()
+
+			var opts []informers.SharedInformerOption
+
+			excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+			if exclusion := util.NamespaceExclusionSelector(excluded); exclusion != nil {
+				opts = append(opts, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+					options.FieldSelector = exclusion.String()
+				}))
+			}
+
+			factories := util.NewInformerFactories(
+				c.client,
+				factoryCtx.InformerResyncPeriod,
+				c.config.Namespaces,
+				opts...,
+			)
+
+			for _, factory := range factories {
+				informer := factory.Core().V1().PersistentVolumeClaims().Informer()
+
+				// Apply transform to reduce memory usage
+				_ = informer.SetTransform(trimPVC)
+
+				c.InstrumentInformer("persistentvolumeclaims", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handlePVC,
+					UpdateFunc: func(_, newObj any) { c.handlePVC(newObj) },
+					DeleteFunc: c.handlePVCDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			// PersistentVolumes are cluster-scoped and watched by their own factory
+			if c.config.PersistentVolumes {
+				pvFactory := informers.NewSharedInformerFactory(c.client, factoryCtx.InformerResyncPeriod)
+
+				informer := pvFactory.Core().V1().PersistentVolumes().Informer()
+				_ = informer.SetTransform(trimPV)
+
+				c.InstrumentInformer("persistentvolumes", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handlePV,
+					UpdateFunc: func(_, newObj any) { c.handlePV(newObj) },
+					DeleteFunc: c.handlePVDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+				factories = append(factories, pvFactory)
+			}
+
+			// Start informers
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			// Wait for cache sync
+			c.logger.Info("Waiting for pvc informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				return errors.New("failed to sync pvc informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			c.logger.Info("PVC collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
@@ -0,0 +1,363 @@
+package pvc
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Collector collects PersistentVolumeClaim and PersistentVolume metrics
+type Collector struct {
+	*base.BaseCollector
+
+	client    kubernetes.Interface
+	config    *Config
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	mu     base.RWMutex
+	claims map[string]*corev1.PersistentVolumeClaim // key: namespace/name
+	pvs    map[string]*corev1.PersistentVolume      // key: name
+
+	// Metrics
+	pvcInfo           *prometheus.Desc
+	pvcPhase          *prometheus.Desc
+	pvcRequestedBytes *prometheus.Desc
+	pvcCapacityBytes  *prometheus.Desc
+	pvcPendingSeconds *prometheus.Desc
+	pvInfo            *prometheus.Desc
+	pvPhase           *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.pvcInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pvc", "info"),
+		"Storage class, bound volume and access modes of a PersistentVolumeClaim (always 1)",
+		[]string{"namespace", "persistentvolumeclaim", "storageclass", "volume", "access_modes"},
+		nil,
+	)
+	c.pvcPhase = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pvc", "phase"),
+		"Phase of a PersistentVolumeClaim (always 1)",
+		[]string{"namespace", "persistentvolumeclaim", "phase"},
+		nil,
+	)
+	c.pvcRequestedBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pvc", "requested_bytes"),
+		"Storage requested by a PersistentVolumeClaim, in bytes",
+		[]string{"namespace", "persistentvolumeclaim", "storageclass"},
+		nil,
+	)
+	c.pvcCapacityBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pvc", "capacity_bytes"),
+		"Storage capacity of the volume bound to a PersistentVolumeClaim, in bytes",
+		[]string{"namespace", "persistentvolumeclaim", "storageclass"},
+		nil,
+	)
+	c.pvcPendingSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pvc", "pending_seconds"),
+		"Time since a Pending PersistentVolumeClaim was created, in seconds",
+		[]string{"namespace", "persistentvolumeclaim", "storageclass"},
+		nil,
+	)
+	c.pvInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pv", "info"),
+		"Storage class, reclaim policy and claim of a PersistentVolume (always 1)",
+		[]string{"persistentvolume", "storageclass", "reclaim_policy", "claim_namespace", "claim"},
+		nil,
+	)
+	c.pvPhase = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "pv", "phase"),
+		"Phase of a PersistentVolume (always 1)",
+		[]string{"persistentvolume", "phase"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.pvcInfo)
+	c.MustRegisterDesc(c.pvcPhase)
+	c.MustRegisterDesc(c.pvcRequestedBytes)
+	c.MustRegisterDesc(c.pvcCapacityBytes)
+	c.MustRegisterDesc(c.pvcPendingSeconds)
+
+	if c.config.PersistentVolumes {
+		c.MustRegisterDesc(c.pvInfo)
+		c.MustRegisterDesc(c.pvPhase)
+	}
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// trimPVC keeps only the fields of a PersistentVolumeClaim needed for metrics
+func trimPVC(obj any) (any, error) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         pvc.Namespace,
+			Name:              pvc.Name,
+			UID:               pvc.UID,
+			ResourceVersion:   pvc.ResourceVersion,
+			CreationTimestamp: pvc.CreationTimestamp,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeName:       pvc.Spec.VolumeName,
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: pvc.Spec.Resources.Requests,
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    pvc.Status.Phase,
+			Capacity: pvc.Status.Capacity,
+		},
+	}, nil
+}
+
+// trimPV keeps only the fields of a PersistentVolume needed for metrics
+func trimPV(obj any) (any, error) {
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		return obj, nil
+	}
+
+	transformed := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pv.Name,
+			UID:             pv.UID,
+			ResourceVersion: pv.ResourceVersion,
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			StorageClassName:              pv.Spec.StorageClassName,
+			PersistentVolumeReclaimPolicy: pv.Spec.PersistentVolumeReclaimPolicy,
+		},
+		Status: corev1.PersistentVolumeStatus{
+			Phase: pv.Status.Phase,
+		},
+	}
+
+	if ref := pv.Spec.ClaimRef; ref != nil {
+		transformed.Spec.ClaimRef = &corev1.ObjectReference{
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		}
+	}
+
+	return transformed, nil
+}
+
+// handlePVC records a PersistentVolumeClaim
+func (c *Collector) handlePVC(obj any) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to PersistentVolumeClaim")
+		return
+	}
+
+	c.mu.Lock()
+	c.claims[objectKey(pvc.Namespace, pvc.Name)] = pvc
+	c.mu.Unlock()
+}
+
+// handlePVCDelete removes a tracked PersistentVolumeClaim
+func (c *Collector) handlePVCDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		pvc, ok = tombstone.Obj.(*corev1.PersistentVolumeClaim)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a PersistentVolumeClaim")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.claims, objectKey(pvc.Namespace, pvc.Name))
+	c.mu.Unlock()
+}
+
+// handlePV records a PersistentVolume
+func (c *Collector) handlePV(obj any) {
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to PersistentVolume")
+		return
+	}
+
+	c.mu.Lock()
+	c.pvs[pv.Name] = pv
+	c.mu.Unlock()
+}
+
+// handlePVDelete removes a tracked PersistentVolume
+func (c *Collector) handlePVDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		pv, ok = tombstone.Obj.(*corev1.PersistentVolume)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a PersistentVolume")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.pvs, pv.Name)
+	c.mu.Unlock()
+}
+
+// accessModes returns the sorted, comma separated access modes of a claim
+func accessModes(pvc *corev1.PersistentVolumeClaim) string {
+	modes := make([]string, 0, len(pvc.Spec.AccessModes))
+	for _, mode := range pvc.Spec.AccessModes {
+		modes = append(modes, string(mode))
+	}
+
+	slices.Sort(modes)
+
+	return strings.Join(modes, ",")
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+
+	for _, pvc := range c.claims {
+		storageClass := ""
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.pvcInfo,
+			prometheus.GaugeValue,
+			1,
+			pvc.Namespace,
+			pvc.Name,
+			storageClass,
+			pvc.Spec.VolumeName,
+			accessModes(pvc),
+		)
+
+		phase := pvc.Status.Phase
+		if phase == "" {
+			phase = corev1.ClaimPending
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.pvcPhase,
+			prometheus.GaugeValue,
+			1,
+			pvc.Namespace,
+			pvc.Name,
+			string(phase),
+		)
+
+		if requested, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.pvcRequestedBytes,
+				prometheus.GaugeValue,
+				requested.AsApproximateFloat64(),
+				pvc.Namespace,
+				pvc.Name,
+				storageClass,
+			)
+		}
+
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.pvcCapacityBytes,
+				prometheus.GaugeValue,
+				capacity.AsApproximateFloat64(),
+				pvc.Namespace,
+				pvc.Name,
+				storageClass,
+			)
+		}
+
+		if phase == corev1.ClaimPending && !pvc.CreationTimestamp.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.pvcPendingSeconds,
+				prometheus.GaugeValue,
+				max(now.Sub(pvc.CreationTimestamp.Time).Seconds(), 0),
+				pvc.Namespace,
+				pvc.Name,
+				storageClass,
+			)
+		}
+	}
+
+	for _, pv := range c.pvs {
+		var claimNamespace, claim string
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			claimNamespace, claim = ref.Namespace, ref.Name
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.pvInfo,
+			prometheus.GaugeValue,
+			1,
+			pv.Name,
+			pv.Spec.StorageClassName,
+			string(pv.Spec.PersistentVolumeReclaimPolicy),
+			claimNamespace,
+			claim,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.pvPhase,
+			prometheus.GaugeValue,
+			1,
+			pv.Name,
+			string(pv.Status.Phase),
+		)
+	}
+}
+
+// objectKey generates a unique key for a namespaced object
+func objectKey(namespace, name string) string {
+	return namespace + "/" + name
+}
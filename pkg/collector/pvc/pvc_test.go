@@ -0,0 +1,203 @@
+//nolint:testpackage // Tests need access to private functions
+package pvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestCollector() *Collector {
+	logger := log.NewEntry(log.New())
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        NewDefaultConfig(),
+		claims:        make(map[string]*corev1.PersistentVolumeClaim),
+		pvs:           make(map[string]*corev1.PersistentVolume),
+		logger:        logger,
+	}
+	c.initMetrics("sealos")
+
+	return c
+}
+
+// collectMetrics returns the collected metrics by descriptor
+func collectMetrics(t *testing.T, c *Collector) map[*prometheus.Desc][]*dto.Metric {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 100)
+	c.collect(ch)
+	close(ch)
+
+	metrics := make(map[*prometheus.Desc][]*dto.Metric)
+
+	for metric := range ch {
+		var out dto.Metric
+		if err := metric.Write(&out); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		metrics[metric.Desc()] = append(metrics[metric.Desc()], &out)
+	}
+
+	return metrics
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+func TestTrimPVC(t *testing.T) {
+	storageClass := "openebs-backup"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns-user1",
+			Name:        "data-mysql-0",
+			Labels:      map[string]string{"app": "mysql"},
+			Annotations: map[string]string{"volume.kubernetes.io/selected-node": "node-1"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+
+	trimmed, err := trimPVC(pvc)
+	if err != nil {
+		t.Fatalf("trimPVC() error = %v", err)
+	}
+
+	trimmedPVC := trimmed.(*corev1.PersistentVolumeClaim)
+	if trimmedPVC.Labels != nil || trimmedPVC.Annotations != nil {
+		t.Error("Expected labels and annotations to be dropped")
+	}
+
+	if *trimmedPVC.Spec.StorageClassName != storageClass {
+		t.Errorf("StorageClassName = %q, want %q", *trimmedPVC.Spec.StorageClassName, storageClass)
+	}
+
+	if got := trimmedPVC.Spec.Resources.Requests.Storage().String(); got != "10Gi" {
+		t.Errorf("Requested storage = %s, want 10Gi", got)
+	}
+
+	if trimmedPVC.Status.Phase != corev1.ClaimPending {
+		t.Errorf("Phase = %s, want Pending", trimmedPVC.Status.Phase)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	c := newTestCollector()
+
+	storageClass := "openebs-backup"
+	c.claims["ns-user1/data-mysql-0"] = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-user1", Name: "data-mysql-0"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			VolumeName:       "pvc-1234",
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+				corev1.ReadOnlyMany,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")},
+		},
+	}
+	c.claims["ns-user2/data-redis-0"] = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns-user2",
+			Name:              "data-redis-0",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	c.pvs["pvc-1234"] = &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234"},
+		Spec: corev1.PersistentVolumeSpec{
+			StorageClassName:              storageClass,
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			ClaimRef:                      &corev1.ObjectReference{Namespace: "ns-user1", Name: "data-mysql-0"},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+
+	metrics := collectMetrics(t, c)
+
+	info := metrics[c.pvcInfo]
+	if len(info) != 2 {
+		t.Fatalf("Expected 2 pvc_info series, got %d", len(info))
+	}
+
+	for _, metric := range info {
+		if labelValue(metric, "persistentvolumeclaim") != "data-mysql-0" {
+			continue
+		}
+
+		if got := labelValue(metric, "access_modes"); got != "ReadOnlyMany,ReadWriteOnce" {
+			t.Errorf("access_modes = %q, want ReadOnlyMany,ReadWriteOnce", got)
+		}
+
+		if got := labelValue(metric, "volume"); got != "pvc-1234" {
+			t.Errorf("volume = %q, want pvc-1234", got)
+		}
+	}
+
+	capacity := metrics[c.pvcCapacityBytes]
+	if len(capacity) != 1 || capacity[0].GetGauge().GetValue() != 2*1024*1024*1024 {
+		t.Errorf("Expected the bound capacity of the bound claim only, got %v", capacity)
+	}
+
+	if requested := metrics[c.pvcRequestedBytes]; len(requested) != 2 {
+		t.Errorf("Expected 2 pvc_requested_bytes series, got %d", len(requested))
+	}
+
+	pending := metrics[c.pvcPendingSeconds]
+	if len(pending) != 1 {
+		t.Fatalf("Expected 1 pvc_pending_seconds series, got %d", len(pending))
+	}
+
+	if labelValue(pending[0], "persistentvolumeclaim") != "data-redis-0" || pending[0].GetGauge().GetValue() < 3600 {
+		t.Errorf("Unexpected pending series %v", pending[0])
+	}
+
+	pvInfo := metrics[c.pvInfo]
+	if len(pvInfo) != 1 {
+		t.Fatalf("Expected 1 pv_info series, got %d", len(pvInfo))
+	}
+
+	if got := labelValue(pvInfo[0], "reclaim_policy"); got != "Retain" {
+		t.Errorf("reclaim_policy = %q, want Retain", got)
+	}
+
+	if got := labelValue(pvInfo[0], "claim"); got != "data-mysql-0" {
+		t.Errorf("claim = %q, want data-mysql-0", got)
+	}
+}
@@ -0,0 +1,22 @@
+package quota
+
+// Config contains configuration for the quota collector
+type Config struct {
+	// Namespaces to watch (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces"              env:"NAMESPACES"                envSeparator:","`
+	// IncludeSystemNamespaces watches quotas in system namespaces (kube-system, sealos-system, ...)
+	// when Namespaces is empty; they are excluded by default
+	IncludeSystemNamespaces bool `yaml:"includeSystemNamespaces" env:"INCLUDE_SYSTEM_NAMESPACES"`
+	// LimitRanges watches the LimitRanges and reports their constraints and
+	// the defaults they set on containers
+	LimitRanges bool `yaml:"limitRanges"             env:"LIMIT_RANGES"`
+}
+
+// NewDefaultConfig returns the default configuration for quota collector
+// This function only returns hard-coded defaults without any env parsing
+func NewDefaultConfig() *Config {
+	return &Config{
+		Namespaces:  []string{},
+		LimitRanges: true,
+	}
+}
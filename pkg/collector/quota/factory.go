@@ -0,0 +1,160 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const collectorName = "quota"
+
+func init() {
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("ResourceQuota hard and used values, and LimitRange constraints and defaults, per namespace"),
+		registry.WithRBAC([]string{""}, []string{"resourcequotas"}, []string{"list", "watch"}),
+		registry.WithRBAC([]string{""}, []string{"limitranges"}, []string{"list", "watch"}),
+	)
+}
+
+// NewCollector creates a new quota collector
+func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, error) {
+	// Get Kubernetes client (lazy initialization)
+	client, err := factoryCtx.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes client is required but not available: %w", err)
+	}
+
+	// 1. Start with hard-coded defaults
+	cfg := NewDefaultConfig()
+
+	// 2. Load configuration from ConfigLoader pipe (file -> env)
+	// ConfigLoader is never nil and handles priority: defaults < file < env
+	if err := factoryCtx.ConfigLoader.LoadModuleConfig("collectors.quota", cfg); err != nil {
+		factoryCtx.Logger.WithError(err).
+			Debug("Failed to load quota collector config, using defaults")
+	}
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(
+			collectorName,
+			factoryCtx.Logger,
+			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+		),
+		client:      client,
+		config:      cfg,
+		quotas:      make(map[string]*corev1.ResourceQuota),
+		limitRanges: make(map[string]*corev1.LimitRange),
+		stopCh:      make(chan struct{}),
+		logger:      factoryCtx.Logger,
+	}
+
+	c.mu.Instrument(c.BaseCollector)
+
+	c.initMetrics(factoryCtx.MetricsNamespace)
+
+	// Set lifecycle hooks
+	c.SetLifecycle(base.LifecycleFuncs{
+		StartFunc: func(ctx context.Context) error {
+			// Recreate stopCh and state to support restart
+			c.stopCh = make(chan struct{})
+			c.informers = nil
+
+			c.mu.Lock()
+			c.quotas = make(map[string]*corev1.ResourceQuota)
+			c.limitRanges = make(map[string]*corev1.LimitRange)
+			c.mu.Unlock()
+
+			var opts []informers.SharedInformerOption
+
+			excluded := util.ExcludedNamespaces(c.config.Namespaces, c.config.IncludeSystemNamespaces)
+			if exclusion := util.NamespaceExclusionSelector(excluded); exclusion != nil {
+				opts = append(opts, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+					options.FieldSelector = exclusion.String()
+				}))
+			}
+
+			factories := util.NewInformerFactories(
+				c.client,
+				factoryCtx.InformerResyncPeriod,
+				c.config.Namespaces,
+				opts...,
+			)
+
+			for _, factory := range factories {
+				informer := factory.Core().V1().ResourceQuotas().Informer()
+
+				// Apply transform to reduce memory usage
+				_ = informer.SetTransform(trimResourceQuota)
+
+				c.InstrumentInformer("resourcequotas", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleResourceQuota,
+					UpdateFunc: func(_, newObj any) { c.handleResourceQuota(newObj) },
+					DeleteFunc: c.handleResourceQuotaDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+
+				if !c.config.LimitRanges {
+					continue
+				}
+
+				informer = factory.Core().V1().LimitRanges().Informer()
+				_ = informer.SetTransform(trimLimitRange)
+
+				c.InstrumentInformer("limitranges", informer)
+
+				//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
+				informer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    c.handleLimitRange,
+					UpdateFunc: func(_, newObj any) { c.handleLimitRange(newObj) },
+					DeleteFunc: c.handleLimitRangeDelete,
+				}))
+
+				c.informers = append(c.informers, informer)
+			}
+
+			// Start informers
+			for _, factory := range factories {
+				factory.Start(c.stopCh)
+			}
+
+			// Wait for cache sync
+			c.logger.Info("Waiting for quota informer cache sync")
+
+			if !cache.WaitForCacheSync(c.stopCh, c.HasSynced) {
+				return errors.New("failed to sync quota informer cache")
+			}
+
+			c.WatchInformers(c.informers...)
+
+			c.logger.Info("Quota collector started successfully")
+
+			c.SetReady()
+
+			return nil
+		},
+		StopFunc: func() error {
+			close(c.stopCh)
+			return nil
+		},
+		CollectFunc: c.collect,
+	})
+
+	return c, nil
+}
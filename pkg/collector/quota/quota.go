@@ -0,0 +1,309 @@
+package quota
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// LimitRange constraints reported in the constraint label
+const (
+	constraintMin                  = "min"
+	constraintMax                  = "max"
+	constraintDefault              = "default"
+	constraintDefaultRequest       = "defaultRequest"
+	constraintMaxLimitRequestRatio = "maxLimitRequestRatio"
+)
+
+// Collector collects ResourceQuota and LimitRange metrics
+type Collector struct {
+	*base.BaseCollector
+
+	client    kubernetes.Interface
+	config    *Config
+	informers []cache.SharedIndexInformer
+	stopCh    chan struct{}
+	logger    *log.Entry
+
+	mu          base.RWMutex
+	quotas      map[string]*corev1.ResourceQuota // key: namespace/name
+	limitRanges map[string]*corev1.LimitRange    // key: namespace/name
+
+	// Metrics
+	quotaHard       *prometheus.Desc
+	quotaUsed       *prometheus.Desc
+	quotaUsageRatio *prometheus.Desc
+	limitRange      *prometheus.Desc
+}
+
+// initMetrics initializes Prometheus metric descriptors
+func (c *Collector) initMetrics(namespace string) {
+	c.quotaHard = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "resourcequota", "hard"),
+		"Hard limit of a resource enforced by a ResourceQuota (cores for CPU, bytes for memory and storage, count otherwise)",
+		[]string{"namespace", "resourcequota", "resource"},
+		nil,
+	)
+	c.quotaUsed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "resourcequota", "used"),
+		"Usage of a resource counted by a ResourceQuota (cores for CPU, bytes for memory and storage, count otherwise)",
+		[]string{"namespace", "resourcequota", "resource"},
+		nil,
+	)
+	c.quotaUsageRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "resourcequota", "usage_ratio"),
+		"Usage of a resource divided by its hard limit in a ResourceQuota, for non-zero limits",
+		[]string{"namespace", "resourcequota", "resource"},
+		nil,
+	)
+	c.limitRange = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "limitrange", "value"),
+		"Constraint of a LimitRange on a resource (min, max, default, defaultRequest or maxLimitRequestRatio) "+
+			"for a type of object (Container, Pod or PersistentVolumeClaim)",
+		[]string{"namespace", "limitrange", "type", "resource", "constraint"},
+		nil,
+	)
+
+	// Register descriptors
+	c.MustRegisterDesc(c.quotaHard)
+	c.MustRegisterDesc(c.quotaUsed)
+	c.MustRegisterDesc(c.quotaUsageRatio)
+
+	if c.config.LimitRanges {
+		c.MustRegisterDesc(c.limitRange)
+	}
+}
+
+// HasSynced returns true if all informers have synced
+func (c *Collector) HasSynced() bool {
+	if len(c.informers) == 0 {
+		return false
+	}
+
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// trimResourceQuota keeps only the fields of a ResourceQuota needed for metrics
+func trimResourceQuota(obj any) (any, error) {
+	quota, ok := obj.(*corev1.ResourceQuota)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       quota.Namespace,
+			Name:            quota.Name,
+			UID:             quota.UID,
+			ResourceVersion: quota.ResourceVersion,
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: quota.Spec.Hard,
+		},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: quota.Status.Hard,
+			Used: quota.Status.Used,
+		},
+	}, nil
+}
+
+// trimLimitRange keeps only the fields of a LimitRange needed for metrics
+func trimLimitRange(obj any) (any, error) {
+	limitRange, ok := obj.(*corev1.LimitRange)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       limitRange.Namespace,
+			Name:            limitRange.Name,
+			UID:             limitRange.UID,
+			ResourceVersion: limitRange.ResourceVersion,
+		},
+		Spec: limitRange.Spec,
+	}, nil
+}
+
+// handleResourceQuota records a ResourceQuota
+func (c *Collector) handleResourceQuota(obj any) {
+	quota, ok := obj.(*corev1.ResourceQuota)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to ResourceQuota")
+		return
+	}
+
+	c.mu.Lock()
+	c.quotas[objectKey(quota.Namespace, quota.Name)] = quota
+	c.mu.Unlock()
+}
+
+// handleResourceQuotaDelete removes a tracked ResourceQuota
+func (c *Collector) handleResourceQuotaDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	quota, ok := obj.(*corev1.ResourceQuota)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		quota, ok = tombstone.Obj.(*corev1.ResourceQuota)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a ResourceQuota")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.quotas, objectKey(quota.Namespace, quota.Name))
+	c.mu.Unlock()
+}
+
+// handleLimitRange records a LimitRange
+func (c *Collector) handleLimitRange(obj any) {
+	limitRange, ok := obj.(*corev1.LimitRange)
+	if !ok {
+		c.logger.WithField("object", obj).Error("Failed to cast object to LimitRange")
+		return
+	}
+
+	c.mu.Lock()
+	c.limitRanges[objectKey(limitRange.Namespace, limitRange.Name)] = limitRange
+	c.mu.Unlock()
+}
+
+// handleLimitRangeDelete removes a tracked LimitRange
+func (c *Collector) handleLimitRangeDelete(obj any) {
+	// Handle DeletedFinalStateUnknown
+	limitRange, ok := obj.(*corev1.LimitRange)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.WithField("object", obj).Error("Failed to decode deleted object")
+			return
+		}
+
+		limitRange, ok = tombstone.Obj.(*corev1.LimitRange)
+		if !ok {
+			c.logger.WithField("object", tombstone.Obj).
+				Error("Tombstone contained object that is not a LimitRange")
+			return
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.limitRanges, objectKey(limitRange.Namespace, limitRange.Name))
+	c.mu.Unlock()
+}
+
+// quotaHard returns the hard limits of a quota: the enforced ones reported
+// in its status, or its spec until the quota controller processed it
+func quotaHard(quota *corev1.ResourceQuota) corev1.ResourceList {
+	if len(quota.Status.Hard) > 0 {
+		return quota.Status.Hard
+	}
+
+	return quota.Spec.Hard
+}
+
+// collect collects metrics
+func (c *Collector) collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, quota := range c.quotas {
+		for name, hard := range quotaHard(quota) {
+			hardValue := hard.AsApproximateFloat64()
+
+			ch <- prometheus.MustNewConstMetric(
+				c.quotaHard,
+				prometheus.GaugeValue,
+				hardValue,
+				quota.Namespace,
+				quota.Name,
+				string(name),
+			)
+
+			used, ok := quota.Status.Used[name]
+			if !ok {
+				continue
+			}
+
+			usedValue := used.AsApproximateFloat64()
+
+			ch <- prometheus.MustNewConstMetric(
+				c.quotaUsed,
+				prometheus.GaugeValue,
+				usedValue,
+				quota.Namespace,
+				quota.Name,
+				string(name),
+			)
+
+			if hardValue > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					c.quotaUsageRatio,
+					prometheus.GaugeValue,
+					usedValue/hardValue,
+					quota.Namespace,
+					quota.Name,
+					string(name),
+				)
+			}
+		}
+	}
+
+	if !c.config.LimitRanges {
+		return
+	}
+
+	for _, limitRange := range c.limitRanges {
+		for i := range limitRange.Spec.Limits {
+			item := &limitRange.Spec.Limits[i]
+
+			constraints := []struct {
+				name   string
+				values corev1.ResourceList
+			}{
+				{constraintMin, item.Min},
+				{constraintMax, item.Max},
+				{constraintDefault, item.Default},
+				{constraintDefaultRequest, item.DefaultRequest},
+				{constraintMaxLimitRequestRatio, item.MaxLimitRequestRatio},
+			}
+
+			for _, constraint := range constraints {
+				for name, value := range constraint.values {
+					ch <- prometheus.MustNewConstMetric(
+						c.limitRange,
+						prometheus.GaugeValue,
+						value.AsApproximateFloat64(),
+						limitRange.Namespace,
+						limitRange.Name,
+						string(item.Type),
+						string(name),
+						constraint.name,
+					)
+				}
+			}
+		}
+	}
+}
+
+// objectKey generates a unique key for a namespaced object
+func objectKey(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,226 @@
+//nolint:testpackage // Tests need access to private functions
+package quota
+
+import (
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestCollector() *Collector {
+	logger := log.NewEntry(log.New())
+
+	c := &Collector{
+		BaseCollector: base.NewBaseCollector(collectorName, logger),
+		config:        NewDefaultConfig(),
+		quotas:        make(map[string]*corev1.ResourceQuota),
+		limitRanges:   make(map[string]*corev1.LimitRange),
+		logger:        logger,
+	}
+	c.initMetrics("sealos")
+
+	return c
+}
+
+// collectMetrics returns the collected metrics by descriptor
+func collectMetrics(t *testing.T, c *Collector) map[*prometheus.Desc][]*dto.Metric {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 100)
+	c.collect(ch)
+	close(ch)
+
+	metrics := make(map[*prometheus.Desc][]*dto.Metric)
+
+	for metric := range ch {
+		var out dto.Metric
+		if err := metric.Write(&out); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+
+		metrics[metric.Desc()] = append(metrics[metric.Desc()], &out)
+	}
+
+	return metrics
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+// gaugeByLabel returns the gauge values keyed by the value of a label
+func gaugeByLabel(metrics []*dto.Metric, name string) map[string]float64 {
+	values := make(map[string]float64)
+	for _, metric := range metrics {
+		values[labelValue(metric, name)] = metric.GetGauge().GetValue()
+	}
+
+	return values
+}
+
+func TestCollectResourceQuota(t *testing.T) {
+	c := newTestCollector()
+
+	c.handleResourceQuota(&corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-user1", Name: "quota-ns-user1"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceLimitsCPU:    resource.MustParse("4"),
+				corev1.ResourceLimitsMemory: resource.MustParse("8Gi"),
+				corev1.ResourceServices:     resource.MustParse("0"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceLimitsCPU:    resource.MustParse("3500m"),
+				corev1.ResourceLimitsMemory: resource.MustParse("2Gi"),
+				corev1.ResourceServices:     resource.MustParse("0"),
+			},
+		},
+	})
+
+	metrics := collectMetrics(t, c)
+
+	hard := gaugeByLabel(metrics[c.quotaHard], "resource")
+	if hard["limits.cpu"] != 4 || hard["limits.memory"] != 8*1024*1024*1024 || hard["services"] != 0 {
+		t.Errorf("Unexpected hard values %v", hard)
+	}
+
+	used := gaugeByLabel(metrics[c.quotaUsed], "resource")
+	if used["limits.cpu"] != 3.5 || used["limits.memory"] != 2*1024*1024*1024 {
+		t.Errorf("Unexpected used values %v", used)
+	}
+
+	// No ratio for a zero hard limit
+	ratio := gaugeByLabel(metrics[c.quotaUsageRatio], "resource")
+	if len(ratio) != 2 || ratio["limits.cpu"] != 0.875 || ratio["limits.memory"] != 0.25 {
+		t.Errorf("Unexpected usage ratios %v", ratio)
+	}
+
+	metric := metrics[c.quotaHard][0]
+	if labelValue(metric, "namespace") != "ns-user1" || labelValue(metric, "resourcequota") != "quota-ns-user1" {
+		t.Errorf("Unexpected labels %v", metric.GetLabel())
+	}
+
+	c.handleResourceQuotaDelete(cache.DeletedFinalStateUnknown{
+		Key: "ns-user1/quota-ns-user1",
+		Obj: &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-user1", Name: "quota-ns-user1"}},
+	})
+
+	if metrics := collectMetrics(t, c); len(metrics) != 0 {
+		t.Errorf("Expected no metrics after delete, got %d descriptors", len(metrics))
+	}
+}
+
+func TestCollectResourceQuotaSpecFallback(t *testing.T) {
+	c := newTestCollector()
+
+	// Not yet processed by the quota controller
+	c.handleResourceQuota(&corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-user1", Name: "quota-ns-user1"},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+		},
+	})
+
+	metrics := collectMetrics(t, c)
+
+	if hard := gaugeByLabel(metrics[c.quotaHard], "resource"); hard["pods"] != 10 {
+		t.Errorf("Expected the spec hard limit, got %v", hard)
+	}
+
+	if len(metrics[c.quotaUsed]) != 0 || len(metrics[c.quotaUsageRatio]) != 0 {
+		t.Error("Expected no usage before the quota controller reports it")
+	}
+}
+
+func TestCollectLimitRange(t *testing.T) {
+	c := newTestCollector()
+
+	c.handleLimitRange(&corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-user1", Name: "limits"},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:           corev1.LimitTypeContainer,
+					Default:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+					DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("20m")},
+					Max:            corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+				{
+					Type: corev1.LimitTypePersistentVolumeClaim,
+					Min:  corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+	})
+
+	values := make(map[string]float64)
+	for _, metric := range collectMetrics(t, c)[c.limitRange] {
+		key := labelValue(metric, "type") + "/" + labelValue(metric, "resource") + "/" + labelValue(metric, "constraint")
+		values[key] = metric.GetGauge().GetValue()
+	}
+
+	expected := map[string]float64{
+		"Container/cpu/default":             0.05,
+		"Container/cpu/defaultRequest":      0.02,
+		"Container/memory/max":              1024 * 1024 * 1024,
+		"PersistentVolumeClaim/storage/min": 1024 * 1024 * 1024,
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, values)
+	}
+
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, values[key])
+		}
+	}
+
+	c.config.LimitRanges = false
+
+	if len(collectMetrics(t, c)[c.limitRange]) != 0 {
+		t.Error("Expected no LimitRange metrics when disabled")
+	}
+}
+
+func TestTrimResourceQuota(t *testing.T) {
+	obj, err := trimResourceQuota(&corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns-user1",
+			Name:        "quota-ns-user1",
+			Labels:      map[string]string{"owner": "user1"},
+			Annotations: map[string]string{"note": "large"},
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard:   corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+			Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotTerminating},
+		},
+		Status: corev1.ResourceQuotaStatus{
+			Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("trimResourceQuota() error = %v", err)
+	}
+
+	quota := obj.(*corev1.ResourceQuota)
+	if quota.Labels != nil || quota.Annotations != nil || quota.Spec.Scopes != nil {
+		t.Errorf("Expected unused fields to be trimmed, got %+v", quota)
+	}
+
+	if quota.Name != "quota-ns-user1" || len(quota.Spec.Hard) != 1 || len(quota.Status.Used) != 1 {
+		t.Errorf("Expected identity and values to be kept, got %+v", quota)
+	}
+}
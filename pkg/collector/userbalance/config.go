@@ -24,6 +24,7 @@ type Config struct {
 	DatabaseConfig DatabaseConfig `yaml:"database"      json:"database"`                            // Database configuration
 	UserConfig     []UserConfig   `yaml:"users"         json:"users"`                               // User configurations list
 	CheckInterval  time.Duration  `yaml:"checkInterval" json:"check_interval" env:"CHECK_INTERVAL"` // Check interval duration
+	CycleTimeout   time.Duration  `yaml:"cycleTimeout"  json:"cycle_timeout"  env:"CYCLE_TIMEOUT"`  // Collector-level timeout of a poll cycle (0 = global only)
 }
 
 // NewDefaultConfig returns the default configuration for UserBalance collector
@@ -13,7 +13,12 @@ import (
 const collectorName = "userbalance"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Sealos user account balance monitoring"),
+		registry.WithStandalone(),
+	)
 }
 
 // NewCollector creates a new UserBalance collector
@@ -33,6 +38,7 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			collectorName,
 			factoryCtx.Logger,
 			base.WithWaitReadyOnCollect(true),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, cfg.CycleTimeout),
 		),
 		config:   cfg,
 		balances: make(map[string]float64),
@@ -0,0 +1,40 @@
+package userbalance
+
+// Status is the structured state of the UserBalance collector
+type Status struct {
+	Users []UserStatus `json:"users"`
+}
+
+// UserStatus is the latest balance of a Sealos user
+type UserStatus struct {
+	Region  string   `json:"region"`
+	UUID    string   `json:"uuid"`
+	UID     string   `json:"uid"`
+	Owner   string   `json:"owner"`
+	Balance *float64 `json:"balance"` // nil when the last query failed
+}
+
+// Status returns the latest balances in configuration order
+func (c *Collector) Status() any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := Status{Users: make([]UserStatus, 0, len(c.config.UserConfig))}
+
+	for _, user := range c.config.UserConfig {
+		userStatus := UserStatus{
+			Region: user.Region,
+			UUID:   user.UUID,
+			UID:    user.UID,
+			Owner:  user.Owner,
+		}
+
+		if balance, exists := c.balances[user.Region+":"+user.UID]; exists {
+			userStatus.Balance = &balance
+		}
+
+		status.Users = append(status.Users, userStatus)
+	}
+
+	return status
+}
@@ -52,7 +52,7 @@ func (c *Collector) Interval() time.Duration {
 // pollLoop periodically queries user balances
 func (c *Collector) pollLoop(ctx context.Context) {
 	// Initial poll
-	_ = c.Poll(ctx)
+	_ = c.PollOnce(ctx, c.Poll)
 	c.SetReady()
 
 	ticker := time.NewTicker(c.config.CheckInterval)
@@ -61,7 +61,7 @@ func (c *Collector) pollLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			if err := c.Poll(ctx); err != nil {
+			if err := c.PollOnce(ctx, c.Poll); err != nil {
 				c.logger.WithError(err).Error("Failed to poll cloud balances")
 			}
 		case <-ctx.Done():
@@ -84,6 +84,7 @@ func (c *Collector) Poll(ctx context.Context) error {
 	for _, user := range c.config.UserConfig {
 		select {
 		case <-ctx.Done():
+			c.RecordCanceled(ctx)
 			return ctx.Err()
 		default:
 		}
@@ -7,6 +7,8 @@ import (
 // Config contains configuration for the Zombie collector
 type Config struct {
 	CheckInterval time.Duration `yaml:"checkInterval" env:"CHECK_INTERVAL"`
+	// CycleTimeout is the collector-level timeout of a check cycle (0 = global only)
+	CycleTimeout time.Duration `yaml:"cycleTimeout" env:"CYCLE_TIMEOUT"`
 }
 
 // NewDefaultConfig returns the default configuration for Zombie collector
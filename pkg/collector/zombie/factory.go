@@ -19,7 +19,13 @@ import (
 const collectorName = "zombie"
 
 func init() {
-	registry.MustRegister(collectorName, NewCollector)
+	registry.MustRegister(
+		collectorName,
+		NewCollector,
+		registry.WithDescription("Zombie process detection"),
+		registry.WithRBAC([]string{""}, []string{"nodes"}, []string{"list", "watch"}),
+		registry.WithRBAC([]string{"metrics.k8s.io"}, []string{"nodes"}, []string{"get", "list"}),
+	)
 }
 
 // NewCollector creates a new Zombie collector
@@ -56,6 +62,9 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 			collectorName,
 			factoryCtx.Logger,
 			base.WithWaitReadyOnCollect(true),
+			base.WithStaleWatchTimeout(factoryCtx.StaleWatchTimeout),
+			base.WithEventQueue(factoryCtx.EventQueueWorkers, factoryCtx.EventQueueMaxDepth),
+			base.WithCollectionTimeouts(factoryCtx.CollectionTimeout, cfg.CycleTimeout),
 		),
 		client:           client,
 		metricsClientset: metricsClientset,
@@ -66,6 +75,8 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 		logger:           factoryCtx.Logger,
 	}
 
+	c.mu.Instrument(c.BaseCollector)
+
 	c.initMetrics(factoryCtx.MetricsNamespace)
 
 	// Set lifecycle hooks
@@ -104,8 +115,10 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 				return transformed, nil
 			})
 
+			c.InstrumentInformer("nodes", c.podInformer)
+
 			//nolint:errcheck // AddEventHandler returns (registration, error) but error is always nil in client-go
-			c.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			c.podInformer.AddEventHandler(c.InstrumentHandler(cache.ResourceEventHandlerFuncs{
 				AddFunc: func(obj any) {
 					node, ok := obj.(*corev1.Node)
 					if !ok {
@@ -158,7 +171,7 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 
 					c.logger.WithField("node", node.Name).Debug("Node deleted")
 				},
-			})
+			}))
 
 			// Start informer
 			factory.Start(c.stopCh)
@@ -170,6 +183,8 @@ func NewCollector(factoryCtx *collector.FactoryContext) (collector.Collector, er
 				return errors.New("failed to sync zombie collector informer cache")
 			}
 
+			c.WatchInformers(c.podInformer)
+
 			// Start polling goroutine
 			go c.pollLoop(ctx)
 
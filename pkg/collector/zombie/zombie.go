@@ -2,7 +2,6 @@ package zombie
 
 import (
 	"context"
-	"sync"
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector/base"
@@ -26,7 +25,7 @@ type Collector struct {
 	stopCh           chan struct{}
 	logger           *log.Entry
 
-	mu             sync.RWMutex
+	mu             base.RWMutex
 	nodes          map[string]*corev1.Node // key: node name
 	nodeHasMetrics map[string]bool         // key: node name, value: has metrics
 
@@ -64,6 +63,7 @@ func (c *Collector) Poll(ctx context.Context) error {
 		NodeMetricses().
 		List(ctx, metav1.ListOptions{})
 	if err != nil {
+		c.RecordCanceled(ctx)
 		c.logger.WithError(err).Error("Failed to get node metrics")
 		return err
 	}
@@ -106,7 +106,7 @@ func (c *Collector) pollLoop(ctx context.Context) {
 	defer ticker.Stop()
 
 	// Do initial check
-	_ = c.Poll(ctx)
+	_ = c.PollOnce(ctx, c.Poll)
 
 	// Mark as ready after first poll completes
 	c.SetReady()
@@ -114,7 +114,7 @@ func (c *Collector) pollLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			_ = c.Poll(ctx)
+			_ = c.PollOnce(ctx, c.Poll)
 		case <-ctx.Done():
 			return
 		}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/caarlos0/env/v9"
 	"github.com/joho/godotenv"
+	"github.com/labring/sealos-state-metrics/pkg/identity"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
@@ -45,6 +47,18 @@ type GlobalConfig struct {
 	// Performance tuning
 	Performance PerformanceConfig `yaml:"performance" embed:"" prefix:"" envprefix:"PERFORMANCE_"`
 
+	// Heartbeat to an external dead man's switch (hot-reloadable)
+	Heartbeat HeartbeatConfig `yaml:"heartbeat" embed:"" prefix:"heartbeat-" envprefix:"HEARTBEAT_"`
+
+	// Periodic push of the metrics to VictoriaMetrics or InfluxDB (requires restart)
+	Push PushConfig `yaml:"push" embed:"" prefix:"push-" envprefix:"PUSH_"`
+
+	// Cluster identity added as target labels to all metrics (requires restart)
+	Cluster ClusterConfig `yaml:"cluster" embed:"" prefix:"cluster-" envprefix:"CLUSTER_"`
+
+	// Startup checks of the Kubernetes API, RBAC, DNS and cloud API reachability
+	Preflight PreflightConfig `yaml:"preflight" embed:"" prefix:"preflight-" envprefix:"PREFLIGHT_"`
+
 	// Enabled collectors (list of collector names)
 	EnabledCollectors []string `yaml:"enabledCollectors" help:"Comma-separated list of enabled collectors" default:"domain,node,pod,imagepull,zombie" env:"ENABLED_COLLECTORS" sep:","`
 
@@ -56,6 +70,18 @@ type GlobalConfig struct {
 
 	// Pod name (typically set via downward API)
 	PodName string `yaml:"podName" help:"Pod name" env:"POD_NAME"`
+
+	// Pod UID (typically set via downward API, used by the podUID identity strategy)
+	PodUID string `yaml:"podUID" help:"Pod UID" env:"POD_UID"`
+
+	// Standalone mode: run without Kubernetes (e.g. on bastion hosts monitoring cloud accounts)
+	Standalone bool `yaml:"standalone" help:"Run without Kubernetes, only collectors not requiring a Kubernetes client are enabled" env:"STANDALONE"`
+
+	// Batch mode: run all collectors for a single cycle, write metrics and exit
+	Once bool `yaml:"-" help:"Run all collectors for a single cycle, write metrics and exit" env:"ONCE"`
+
+	// Batch mode output and thresholds (only used with --once)
+	Batch BatchConfig `yaml:"batch" embed:"" prefix:"batch-" envprefix:"BATCH_"`
 }
 
 // ApplyHotReload applies hot-reloadable fields from newConfig
@@ -68,19 +94,24 @@ func (c *GlobalConfig) ApplyHotReload(newConfig *GlobalConfig) {
 	c.Metrics = newConfig.Metrics
 	c.LeaderElection = newConfig.LeaderElection
 	c.Performance = newConfig.Performance
+	c.Heartbeat = newConfig.Heartbeat
 	c.EnabledCollectors = newConfig.EnabledCollectors
 	c.Identity = newConfig.Identity
 	c.NodeName = newConfig.NodeName
 	c.PodName = newConfig.PodName
+	c.PodUID = newConfig.PodUID
 }
 
 // ServerConfig contains HTTP server configuration
 type ServerConfig struct {
-	Address     string     `yaml:"address"     name:"address"      env:"ADDRESS"      default:":9090"    help:"Server listen address"`
-	MetricsPath string     `yaml:"metricsPath" name:"metrics-path" env:"METRICS_PATH" default:"/metrics" help:"Metrics endpoint path"`
-	HealthPath  string     `yaml:"healthPath"  name:"health-path"  env:"HEALTH_PATH"  default:"/health"  help:"Health check endpoint path"`
-	TLS         TLSConfig  `yaml:"tls"                                                                                                     embed:"" prefix:"tls-"  envprefix:"TLS_"`
-	Auth        AuthConfig `yaml:"auth"                                                                                                    embed:"" prefix:"auth-" envprefix:"AUTH_"`
+	Address             string        `yaml:"address"             name:"address"               env:"ADDRESS"               default:":9090"    help:"Server listen address"`
+	MetricsPath         string        `yaml:"metricsPath"         name:"metrics-path"          env:"METRICS_PATH"          default:"/metrics" help:"Metrics endpoint path"`
+	HealthPath          string        `yaml:"healthPath"          name:"health-path"           env:"HEALTH_PATH"           default:"/health"  help:"Health check endpoint path"`
+	Compression         bool          `yaml:"compression"         name:"compression"           env:"COMPRESSION"           default:"true"     help:"Enable gzip for metrics"`
+	ScrapeTimeoutAware  bool          `yaml:"scrapeTimeoutAware"  name:"scrape-timeout-aware"  env:"SCRAPE_TIMEOUT_AWARE"  default:"true"     help:"Serve the last complete snapshot when gathering would exceed the X-Prometheus-Scrape-Timeout-Seconds header"`
+	ScrapeTimeoutOffset time.Duration `yaml:"scrapeTimeoutOffset" name:"scrape-timeout-offset" env:"SCRAPE_TIMEOUT_OFFSET" default:"500ms"    help:"Time reserved for encoding and the network, subtracted from the scrape timeout"`
+	TLS                 TLSConfig     `yaml:"tls"                                                                                                                                                                                                                embed:"" prefix:"tls-"  envprefix:"TLS_"`
+	Auth                AuthConfig    `yaml:"auth"                                                                                                                                                                                                               embed:"" prefix:"auth-" envprefix:"AUTH_"`
 }
 
 // Equal checks if two ServerConfig are equal
@@ -88,32 +119,47 @@ func (c ServerConfig) Equal(other ServerConfig) bool {
 	return c.Address == other.Address &&
 		c.MetricsPath == other.MetricsPath &&
 		c.HealthPath == other.HealthPath &&
+		c.Compression == other.Compression &&
+		c.ScrapeTimeoutAware == other.ScrapeTimeoutAware &&
+		c.ScrapeTimeoutOffset == other.ScrapeTimeoutOffset &&
 		c.TLS.Equal(other.TLS) &&
 		c.Auth.Equal(other.Auth)
 }
 
 // TLSConfig contains TLS configuration for the HTTP server
 type TLSConfig struct {
-	Enabled  bool   `yaml:"enabled"  name:"enabled"   env:"ENABLED"   default:"false"            help:"Enable TLS for the metrics server"`
-	CertFile string `yaml:"certFile" name:"cert-file" env:"CERT_FILE" default:"/etc/tls/tls.crt" help:"Path to TLS certificate file"      type:"path"`
-	KeyFile  string `yaml:"keyFile"  name:"key-file"  env:"KEY_FILE"  default:"/etc/tls/tls.key" help:"Path to TLS private key file"      type:"path"`
+	Enabled      bool   `yaml:"enabled"      name:"enabled"        env:"ENABLED"        default:"false"            help:"Enable TLS for the metrics server"`
+	CertFile     string `yaml:"certFile"     name:"cert-file"      env:"CERT_FILE"      default:"/etc/tls/tls.crt" help:"Path to TLS certificate file"                                            type:"path"`
+	KeyFile      string `yaml:"keyFile"      name:"key-file"       env:"KEY_FILE"       default:"/etc/tls/tls.key" help:"Path to TLS private key file"                                            type:"path"`
+	ClientCAFile string `yaml:"clientCAFile" name:"client-ca-file" env:"CLIENT_CA_FILE" default:""                 help:"Path to the CA bundle verifying client certificates, enables mutual TLS" type:"path"`
 }
 
 // Equal checks if two TLSConfig are equal
 func (c TLSConfig) Equal(other TLSConfig) bool {
 	return c.Enabled == other.Enabled &&
 		c.CertFile == other.CertFile &&
-		c.KeyFile == other.KeyFile
+		c.KeyFile == other.KeyFile &&
+		c.ClientCAFile == other.ClientCAFile
 }
 
 // AuthConfig contains authentication configuration for the metrics endpoint
 type AuthConfig struct {
-	Enabled bool `yaml:"enabled" name:"enabled" env:"ENABLED" default:"false" help:"Enable Kubernetes authentication for metrics endpoint"`
+	Enabled       bool   `yaml:"enabled"       name:"enabled"         env:"ENABLED"         default:"false" help:"Enable Kubernetes authentication for metrics endpoint"`
+	TokensFile    string `yaml:"tokensFile"    name:"tokens-file"     env:"TOKENS_FILE"     default:""      help:"Path to a file of static bearer tokens, one token,user per line"          type:"path"`
+	BasicAuthFile string `yaml:"basicAuthFile" name:"basic-auth-file" env:"BASIC_AUTH_FILE" default:""      help:"Path to a file of basic authentication users, one user:password per line" type:"path"`
 }
 
 // Equal checks if two AuthConfig are equal
 func (c AuthConfig) Equal(other AuthConfig) bool {
-	return c.Enabled == other.Enabled
+	return c.Enabled == other.Enabled &&
+		c.TokensFile == other.TokensFile &&
+		c.BasicAuthFile == other.BasicAuthFile
+}
+
+// Active reports whether requests are authenticated, with Kubernetes or
+// static credentials
+func (c AuthConfig) Active() bool {
+	return c.Enabled || c.TokensFile != "" || c.BasicAuthFile != ""
 }
 
 // DebugServerConfig contains debug server configuration for internal access without authentication
@@ -160,19 +206,74 @@ func (c KubernetesConfig) Equal(other KubernetesConfig) bool {
 		c.Burst == other.Burst
 }
 
+// metricNamespacePattern matches the namespaces yielding valid metric names
+var metricNamespacePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 // MetricsConfig contains Prometheus metrics configuration
 type MetricsConfig struct {
 	Namespace string `yaml:"namespace" name:"namespace" env:"NAMESPACE" help:"Prometheus metrics namespace (optional)"`
+
+	// Namespace migration: metrics are also emitted under the former namespace,
+	// so alerts and dashboards can move to the new names before the old ones disappear
+	LegacyNamespace  string   `yaml:"legacyNamespace"  name:"legacy-namespace"  env:"LEGACY_NAMESPACE"          help:"Former metrics namespace metrics are also emitted under (empty disables)"`
+	LegacyCollectors []string `yaml:"legacyCollectors" name:"legacy-collectors" env:"LEGACY_COLLECTORS" sep:"," help:"Collectors whose metrics are also emitted under the legacy namespace (empty = all)"`
+	LegacyUntil      string   `yaml:"legacyUntil"      name:"legacy-until"      env:"LEGACY_UNTIL"              help:"End of the legacy namespace emission, RFC 3339 time or date (included, UTC), empty for no end"`
+}
+
+// LegacyDeadline returns the end of the legacy namespace emission, zero when
+// it has no end. A date ends at the end of that day (UTC).
+func (c MetricsConfig) LegacyDeadline() (time.Time, error) {
+	if c.LegacyUntil == "" {
+		return time.Time{}, nil
+	}
+
+	if deadline, err := time.Parse(time.RFC3339, c.LegacyUntil); err == nil {
+		return deadline, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, c.LegacyUntil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid metrics.legacyUntil %q (expected RFC 3339 time or date)", c.LegacyUntil)
+	}
+
+	return day.AddDate(0, 0, 1), nil
 }
 
 // LeaderElectionConfig contains leader election configuration
 type LeaderElectionConfig struct {
-	Enabled       bool          `yaml:"enabled"       name:"enabled"        env:"ENABLED"        envDefault:"true"                default:"true"                help:"Enable leader election"`
-	Namespace     string        `yaml:"namespace"     name:"namespace"      env:"NAMESPACE"                                                                     help:"Namespace for leader election lease (empty disables LE)"`
-	LeaseName     string        `yaml:"leaseName"     name:"lease-name"     env:"LEASE_NAME"     envDefault:"sealos-state-metric" default:"sealos-state-metric" help:"Name of the leader election lease"`
-	LeaseDuration time.Duration `yaml:"leaseDuration" name:"lease-duration" env:"LEASE_DURATION" envDefault:"15s"                 default:"15s"                 help:"Leader election lease duration"`
-	RenewDeadline time.Duration `yaml:"renewDeadline" name:"renew-deadline" env:"RENEW_DEADLINE" envDefault:"10s"                 default:"10s"                 help:"Leader election renew deadline"`
-	RetryPeriod   time.Duration `yaml:"retryPeriod"   name:"retry-period"   env:"RETRY_PERIOD"   envDefault:"2s"                  default:"2s"                  help:"Leader election retry period"`
+	Enabled             bool          `yaml:"enabled"             name:"enabled"                env:"ENABLED"                envDefault:"true"                default:"true"                help:"Enable leader election"`
+	Namespace           string        `yaml:"namespace"           name:"namespace"              env:"NAMESPACE"                                                                             help:"Namespace for leader election lease (empty disables LE)"`
+	LeaseName           string        `yaml:"leaseName"           name:"lease-name"             env:"LEASE_NAME"             envDefault:"sealos-state-metric" default:"sealos-state-metric" help:"Name of the leader election lease"`
+	LeaseDuration       time.Duration `yaml:"leaseDuration"       name:"lease-duration"         env:"LEASE_DURATION"         envDefault:"15s"                 default:"15s"                 help:"Leader election lease duration"`
+	RenewDeadline       time.Duration `yaml:"renewDeadline"       name:"renew-deadline"         env:"RENEW_DEADLINE"         envDefault:"10s"                 default:"10s"                 help:"Leader election renew deadline"`
+	RetryPeriod         time.Duration `yaml:"retryPeriod"         name:"retry-period"           env:"RETRY_PERIOD"           envDefault:"2s"                  default:"2s"                  help:"Leader election retry period"`
+	IdentityStrategy    string        `yaml:"identityStrategy"    name:"identity-strategy"      env:"IDENTITY_STRATEGY"      envDefault:"auto"                default:"auto"                help:"Leader election identity strategy: auto, podUID or file"`
+	IdentityFile        string        `yaml:"identityFile"        name:"identity-file"          env:"IDENTITY_FILE"                                                                         help:"File storing the leader election identity of the file strategy (created if missing)"`
+	Sharding            bool          `yaml:"sharding"            name:"sharding"               env:"SHARDING"               envDefault:"false"               default:"false"               help:"Elect a leader per collector instead of one for all, spreading collectors across replicas"`
+	MaxLeasesPerReplica int           `yaml:"maxLeasesPerReplica" name:"max-leases-per-replica" env:"MAX_LEASES_PER_REPLICA" envDefault:"0"                   default:"0"                   help:"Maximum number of collector leases held by one replica with sharding (0 = unbounded)"`
+}
+
+// ClusterConfig contains the cluster identity configuration.
+// Values not set explicitly are detected from node labels or cloud metadata.
+type ClusterConfig struct {
+	Name          string        `yaml:"name"          name:"name"           env:"NAME"                                          help:"Cluster name (overrides detection)"`
+	Region        string        `yaml:"region"        name:"region"         env:"REGION"                                        help:"Cluster region (overrides detection)"`
+	Zone          string        `yaml:"zone"          name:"zone"           env:"ZONE"                                          help:"Cluster zone (overrides detection)"`
+	NameLabel     string        `yaml:"nameLabel"     name:"name-label"     env:"NAME_LABEL"                                    help:"Node label holding the cluster name"`
+	NodeLabels    bool          `yaml:"nodeLabels"    name:"node-labels"    env:"NODE_LABELS"    envDefault:"true"  default:"true"  help:"Detect region and zone from node topology labels"`
+	CloudMetadata bool          `yaml:"cloudMetadata" name:"cloud-metadata" env:"CLOUD_METADATA" envDefault:"false" default:"false" help:"Detect region and zone from the cloud instance metadata service (AWS, GCP, Alibaba Cloud)"`
+	Timeout       time.Duration `yaml:"timeout"       name:"timeout"        env:"TIMEOUT"        envDefault:"2s"    default:"2s"    help:"Timeout of each cloud metadata query"`
+	TargetLabels  bool          `yaml:"targetLabels"  name:"target-labels"  env:"TARGET_LABELS"  envDefault:"true"  default:"true"  help:"Add sealos_cluster, sealos_region and sealos_zone labels to all metrics"`
+}
+
+// PreflightConfig contains configuration of the checks run at startup, before
+// the collectors are created
+type PreflightConfig struct {
+	Enabled     bool          `yaml:"enabled"     name:"enabled"       env:"ENABLED"                        default:"true"  help:"Verify Kubernetes API access, collector RBAC, DNS and cloud API reachability at startup"`
+	FailOnError bool          `yaml:"failOnError" name:"fail-on-error" env:"FAIL_ON_ERROR"                  default:"true"  help:"Exit at startup when a preflight check fails, instead of starting the collectors" negatable:""`
+	ReportPath  string        `yaml:"reportPath"  name:"report-path"   env:"REPORT_PATH"                                    help:"File to write the JSON preflight report to (empty = not written)"`
+	DNSNames    []string      `yaml:"dnsNames"    name:"dns-names"     env:"DNS_NAMES"     envSeparator:","                 help:"Names resolved in addition to the cloud API hosts to verify DNS egress" sep:","`
+	Timeout     time.Duration `yaml:"timeout"     name:"timeout"       env:"TIMEOUT"                        default:"5s"    help:"Timeout of each preflight check"`
 }
 
 // LoggingConfig contains logging configuration
@@ -190,6 +291,47 @@ func (c *LoggingConfig) ToLoggerOptions() (debug bool, level, format string) {
 // PerformanceConfig contains performance tuning configuration
 type PerformanceConfig struct {
 	InformerResyncPeriod time.Duration `yaml:"informerResyncPeriod" name:"informer-resync-period" env:"INFORMER_RESYNC_PERIOD" envDefault:"10m" default:"10m" help:"Kubernetes informer resync period" hidden:""`
+	CollectionTimeout    time.Duration `yaml:"collectionTimeout"    name:"collection-timeout"     env:"COLLECTION_TIMEOUT"     envDefault:"5m"  default:"5m"  help:"Global upper bound of one poll cycle of any polling collector (0 = unbounded)"`
+	StaleWatchTimeout    time.Duration `yaml:"staleWatchTimeout"    name:"stale-watch-timeout"    env:"STALE_WATCH_TIMEOUT"    envDefault:"20m" default:"20m" help:"Restart informer-based collectors whose watch received no event nor bookmark for this long (0 = disabled)"`
+	EventQueueWorkers    int           `yaml:"eventQueueWorkers"    name:"event-queue-workers"    env:"EVENT_QUEUE_WORKERS"    envDefault:"1"   default:"1"   help:"Workers handling the queued informer notifications of each collector (0 = handle them on the informer goroutine)"`
+	EventQueueMaxDepth   int           `yaml:"eventQueueMaxDepth"   name:"event-queue-max-depth"  env:"EVENT_QUEUE_MAX_DEPTH"  envDefault:"10000" default:"10000" help:"Maximum number of objects with queued informer notifications per collector, informers wait above it (0 = unbounded)"`
+}
+
+// HeartbeatConfig contains configuration for pushing heartbeats to an external
+// dead man's switch (e.g. healthchecks.io) after successful collection cycles
+// This config is hot-reloadable
+type HeartbeatConfig struct {
+	Enabled        bool          `yaml:"enabled"        name:"enabled"         env:"ENABLED"                          default:"false" help:"Enable heartbeat push after successful collection cycles"`
+	URL            string        `yaml:"url"            name:"url"             env:"URL"                                              help:"URL to POST heartbeats to"`
+	Collectors     []string      `yaml:"collectors"     name:"collectors"      env:"COLLECTORS"      envSeparator:","                 help:"Polling collectors that must succeed before each heartbeat (empty = any)" sep:","`
+	Timeout        time.Duration `yaml:"timeout"        name:"timeout"         env:"TIMEOUT"                          default:"10s"   help:"Heartbeat request timeout"`
+	MinInterval    time.Duration `yaml:"minInterval"    name:"min-interval"    env:"MIN_INTERVAL"                     default:"30s"   help:"Minimum time between two heartbeats"`
+	ReportFailures bool          `yaml:"reportFailures" name:"report-failures" env:"REPORT_FAILURES"                  default:"false" help:"POST to <url>/fail when a designated collector fails"`
+}
+
+// PushConfig contains configuration for pushing the metrics to a remote
+// endpoint in the Influx line protocol or the Prometheus text format
+// (VictoriaMetrics import API). This config requires a restart.
+type PushConfig struct {
+	Enabled      bool          `yaml:"enabled"      name:"enabled"       env:"ENABLED"       default:"false"  help:"Push the metrics to a remote endpoint at every interval"`
+	URL          string        `yaml:"url"          name:"url"           env:"URL"                            help:"URL receiving the batches (e.g. http://victoria-metrics:8428/write)"`
+	Format       string        `yaml:"format"       name:"format"        env:"FORMAT"        default:"influx" help:"Format of the pushed samples (influx line protocol or prometheus text format)" enum:"influx,prometheus"`
+	Interval     time.Duration `yaml:"interval"     name:"interval"      env:"INTERVAL"      default:"30s"    help:"Interval between two pushes"`
+	Timeout      time.Duration `yaml:"timeout"      name:"timeout"       env:"TIMEOUT"       default:"10s"    help:"Push request timeout"`
+	BatchSize    int           `yaml:"batchSize"    name:"batch-size"    env:"BATCH_SIZE"    default:"10000"  help:"Maximum number of samples per push request"`
+	MaxRetries   int           `yaml:"maxRetries"   name:"max-retries"   env:"MAX_RETRIES"   default:"3"      help:"Retries of a failed batch before dropping it"`
+	RetryBackoff time.Duration `yaml:"retryBackoff" name:"retry-backoff" env:"RETRY_BACKOFF" default:"1s"     help:"Delay before the first retry, doubled after each retry"`
+	Username     string        `yaml:"username"     name:"username"      env:"USERNAME"                       help:"Basic authentication username"`
+	Password     string        `yaml:"password"     name:"password"      env:"PASSWORD"                       help:"Basic authentication password"`
+	BearerToken  string        `yaml:"bearerToken"  name:"bearer-token"  env:"BEARER_TOKEN"                   help:"Bearer token sent as Authorization header"`
+}
+
+// BatchConfig contains configuration for batch (--once) mode
+type BatchConfig struct {
+	Output           string        `yaml:"output"           name:"output"              env:"OUTPUT"              default:"-"    help:"File to write metrics to in batch mode (- for stdout)"`
+	Timeout          time.Duration `yaml:"timeout"          name:"timeout"             env:"TIMEOUT"             default:"2m"   help:"Maximum time to wait for collectors to complete a cycle"`
+	CertExpiryDays   int           `yaml:"certExpiryDays"   name:"cert-expiry-days"    env:"CERT_EXPIRY_DAYS"    default:"7"    help:"Fail if any certificate expires within this many days (0 disables)"`
+	FailOnDomainDown bool          `yaml:"failOnDomainDown" name:"fail-on-domain-down" env:"FAIL_ON_DOMAIN_DOWN" default:"true" help:"Fail if any monitored domain has no healthy IPs"`
 }
 
 // LoadEnvFile loads environment variables from a .env file
@@ -297,6 +439,19 @@ func (c *GlobalConfig) Validate() error {
 		return errors.New("server.address cannot be empty")
 	}
 
+	if c.Server.TLS.ClientCAFile != "" && !c.Server.TLS.Enabled {
+		return errors.New("server.tls.clientCAFile requires server.tls.enabled")
+	}
+
+	if c.Standalone {
+		if c.Server.Auth.Enabled {
+			return errors.New("server.auth requires Kubernetes and cannot be enabled in standalone mode")
+		}
+
+		// Leader election requires a Kubernetes lease
+		c.LeaderElection.Enabled = false
+	}
+
 	// Auto-disable leader election if namespace is empty
 	if c.LeaderElection.Namespace == "" {
 		if c.LeaderElection.Enabled {
@@ -309,6 +464,88 @@ func (c *GlobalConfig) Validate() error {
 		if c.LeaderElection.RenewDeadline >= c.LeaderElection.LeaseDuration {
 			return errors.New("leaderElection.renewDeadline must be less than leaseDuration")
 		}
+
+		switch c.LeaderElection.IdentityStrategy {
+		case "", identity.StrategyAuto:
+		case identity.StrategyPodUID:
+			if c.PodUID == "" {
+				return errors.New("leaderElection.identityStrategy podUID requires POD_UID to be set")
+			}
+		case identity.StrategyFile:
+			if c.LeaderElection.IdentityFile == "" {
+				return errors.New("leaderElection.identityFile cannot be empty with the file identity strategy")
+			}
+		default:
+			return fmt.Errorf("invalid leaderElection.identityStrategy: %s", c.LeaderElection.IdentityStrategy)
+		}
+
+		if c.LeaderElection.MaxLeasesPerReplica < 0 {
+			return errors.New("leaderElection.maxLeasesPerReplica cannot be negative")
+		}
+	}
+
+	if c.Metrics.LegacyNamespace != "" {
+		if !metricNamespacePattern.MatchString(c.Metrics.LegacyNamespace) {
+			return fmt.Errorf("invalid metrics.legacyNamespace: %s", c.Metrics.LegacyNamespace)
+		}
+
+		if c.Metrics.LegacyNamespace == c.Metrics.Namespace {
+			return errors.New("metrics.legacyNamespace must differ from metrics.namespace")
+		}
+
+		if _, err := c.Metrics.LegacyDeadline(); err != nil {
+			return err
+		}
+	}
+
+	if c.Heartbeat.Enabled && c.Heartbeat.URL == "" {
+		return errors.New("heartbeat.url cannot be empty when heartbeat is enabled")
+	}
+
+	if c.Push.Enabled {
+		if c.Push.URL == "" {
+			return errors.New("push.url cannot be empty when push is enabled")
+		}
+
+		if c.Push.Interval <= 0 || c.Push.BatchSize <= 0 {
+			return errors.New("push.interval and push.batchSize must be positive")
+		}
+
+		if c.Push.MaxRetries < 0 {
+			return errors.New("push.maxRetries cannot be negative")
+		}
+	}
+
+	if c.Server.ScrapeTimeoutOffset < 0 {
+		return errors.New("server.scrapeTimeoutOffset cannot be negative")
+	}
+
+	if c.Performance.CollectionTimeout < 0 {
+		return errors.New("performance.collectionTimeout cannot be negative")
+	}
+
+	if c.Performance.StaleWatchTimeout < 0 {
+		return errors.New("performance.staleWatchTimeout cannot be negative")
+	}
+
+	if c.Performance.EventQueueWorkers < 0 {
+		return errors.New("performance.eventQueueWorkers cannot be negative")
+	}
+
+	if c.Performance.EventQueueMaxDepth < 0 {
+		return errors.New("performance.eventQueueMaxDepth cannot be negative")
+	}
+
+	if c.Cluster.Timeout < 0 {
+		return errors.New("cluster.timeout cannot be negative")
+	}
+
+	if c.Preflight.Enabled && c.Preflight.Timeout <= 0 {
+		return errors.New("preflight.timeout must be positive")
+	}
+
+	if c.Once && c.Batch.Timeout <= 0 {
+		return errors.New("batch.timeout must be positive")
 	}
 
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
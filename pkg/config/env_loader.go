@@ -82,15 +82,17 @@ func NewEnvConfigLoader(opts ...EnvLoaderOption) *EnvConfigLoader {
 }
 
 // LoadModuleConfig loads configuration from environment variables
-// It derives the prefix from moduleKey by replacing dots with underscores:
+// It derives the prefix from moduleKey by replacing dots (and any other character
+// not allowed in environment variable names) with underscores:
 //   - moduleKey="collectors.node" -> "COLLECTORS_NODE_"
+//   - moduleKey="collectors.domain:internal" -> "COLLECTORS_DOMAIN_INTERNAL_"
 //   - options.Prefix="APP_" + moduleKey="collectors.node" -> "APP_COLLECTORS_NODE_"
 func (l *EnvConfigLoader) LoadModuleConfig(moduleKey string, target any) error {
 	// Copy options to avoid modifying the original
 	opts := l.options
 
 	// Auto-derive prefix from moduleKey
-	derivedPrefix := strings.ToUpper(strings.ReplaceAll(moduleKey, ".", "_")) + "_"
+	derivedPrefix := envPrefix(moduleKey)
 
 	// Prepend custom prefix if provided
 	if opts.Prefix != "" {
@@ -114,3 +116,16 @@ func (l *EnvConfigLoader) LoadModuleConfig(moduleKey string, target any) error {
 
 	return nil
 }
+
+// envPrefix derives the environment variable prefix of a module key
+func envPrefix(moduleKey string) string {
+	prefix := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '_'
+	}, moduleKey)
+
+	return strings.ToUpper(prefix) + "_"
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// InstanceConfigLoader loads the configuration of a named collector instance.
+// The collector type section is loaded first and then overridden by the
+// instance section, so instances only need to configure what differs:
+//
+//	collectors:
+//	  domain:
+//	    checkTimeout: 5s
+//	  domain:internal:
+//	    domains: [api.internal.example.com]
+//	    checkInterval: 1m
+type InstanceConfigLoader struct {
+	loader      collector.ConfigLoader
+	typeKey     string
+	instanceKey string
+}
+
+// NewInstanceConfigLoader creates a loader overriding typeKey with instanceKey
+func NewInstanceConfigLoader(
+	loader collector.ConfigLoader,
+	typeKey, instanceKey string,
+) *InstanceConfigLoader {
+	return &InstanceConfigLoader{
+		loader:      loader,
+		typeKey:     typeKey,
+		instanceKey: instanceKey,
+	}
+}
+
+// LoadModuleConfig loads moduleKey, then the instance section when moduleKey
+// is the collector type section
+func (l *InstanceConfigLoader) LoadModuleConfig(moduleKey string, target any) error {
+	if err := l.loader.LoadModuleConfig(moduleKey, target); err != nil {
+		return err
+	}
+
+	if moduleKey != l.typeKey {
+		return nil
+	}
+
+	return l.loader.LoadModuleConfig(l.instanceKey, target)
+}
@@ -84,7 +84,8 @@ func (l *ModuleConfigLoader) LoadModuleConfig(moduleKey string, target any) erro
 		return fmt.Errorf("failed to create decoder: %w", err)
 	}
 
-	if err := decoder.Decode(moduleData); err != nil {
+	// Resolve valueFrom file references (e.g. mounted secrets) before decoding
+	if err := decoder.Decode(resolveValueFrom(moduleData)); err != nil {
 		return fmt.Errorf("failed to decode module config: %w", err)
 	}
 
@@ -2,6 +2,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -299,6 +301,47 @@ server:
 	}
 }
 
+func TestModuleConfigLoader_ValueFromFile(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	yamlContent := `
+collectors:
+  node:
+    name:
+      valueFrom:
+        file: ` + secretFile + `
+    nested:
+      value:
+        valueFrom:
+          file: /nonexistent/secret
+      port: 8080
+`
+
+	loader := NewModuleConfigLoader(createTempYAML(t, yamlContent))
+
+	var config TestConfig
+	if err := loader.LoadModuleConfig("collectors.node", &config); err != nil {
+		t.Fatalf("LoadModuleConfig failed: %v", err)
+	}
+
+	if config.Name != "s3cr3t" {
+		t.Errorf("Expected Name resolved from file, got %q", config.Name)
+	}
+
+	// Unreadable files resolve to empty without failing the module config
+	if config.NestedConfig.Value != "" || config.NestedConfig.Port != 8080 {
+		t.Errorf("Expected empty Value and Port=8080, got %+v", config.NestedConfig)
+	}
+
+	files := ValueFromFiles(createTempYAML(t, yamlContent))
+	if !reflect.DeepEqual(files, []string{"/nonexistent/secret", secretFile}) {
+		t.Errorf("Unexpected valueFrom files: %v", files)
+	}
+}
+
 func TestSplitKey(t *testing.T) {
 	t.Helper()
 
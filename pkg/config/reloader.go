@@ -3,8 +3,10 @@ package config
 import (
 	"context"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -14,13 +16,15 @@ import (
 // ReloadCallback is called when configuration file changes
 type ReloadCallback func(configContent []byte) error
 
-// Reloader watches configuration file and triggers reload on changes
+// Reloader watches configuration file and triggers reload on changes, or
+// on SIGHUP
 type Reloader struct {
 	configPath string
 	callback   ReloadCallback
 	logger     *log.Entry
 
 	watcher  *fsnotify.Watcher
+	sighup   chan os.Signal
 	stopCh   chan struct{}
 	stopOnce sync.Once
 
@@ -28,6 +32,10 @@ type Reloader struct {
 	debounce time.Duration
 	mu       sync.Mutex
 	timer    *time.Timer
+
+	// Files referenced through valueFrom, whose changes also trigger a reload
+	valueFromFiles map[string]struct{}
+	watchedDirs    map[string]struct{}
 }
 
 // NewReloader creates a new configuration reloader
@@ -42,8 +50,12 @@ func NewReloader(configPath string, callback ReloadCallback) (*Reloader, error)
 		callback:   callback,
 		logger:     log.WithField("component", "config-reloader"),
 		watcher:    watcher,
+		sighup:     make(chan os.Signal, 1),
 		stopCh:     make(chan struct{}),
 		debounce:   3 * time.Second, // 3 second debounce for Kubernetes ConfigMap updates
+
+		valueFromFiles: make(map[string]struct{}),
+		watchedDirs:    make(map[string]struct{}),
 	}, nil
 }
 
@@ -66,6 +78,18 @@ func (r *Reloader) Start(ctx context.Context) error {
 		return err
 	}
 
+	r.mu.Lock()
+	r.watchedDirs[filepath.Clean(configDir)] = struct{}{}
+	r.mu.Unlock()
+
+	// Also watch the files referenced through valueFrom (e.g. mounted secrets)
+	if content, err := os.ReadFile(r.configPath); err == nil {
+		r.watchValueFromFiles(content)
+	}
+
+	// SIGHUP reloads at once, e.g. after an edit fsnotify cannot see
+	signal.Notify(r.sighup, syscall.SIGHUP)
+
 	r.logger.WithFields(log.Fields{
 		"config_path": r.configPath,
 		"watch_dir":   configDir,
@@ -79,6 +103,7 @@ func (r *Reloader) Start(ctx context.Context) error {
 // Stop stops the configuration reloader
 func (r *Reloader) Stop() error {
 	r.stopOnce.Do(func() {
+		signal.Stop(r.sighup)
 		close(r.stopCh)
 
 		if r.watcher != nil {
@@ -115,8 +140,9 @@ func (r *Reloader) watchLoop(ctx context.Context) {
 			configPath := filepath.Clean(r.configPath)
 			configBase := filepath.Base(r.configPath)
 
-			// Check if this event is related to our config file
-			isConfigFile := eventPath == configPath || filepath.Base(eventPath) == configBase
+			// Check if this event is related to our config file or a valueFrom file
+			isConfigFile := eventPath == configPath || filepath.Base(eventPath) == configBase ||
+				r.isValueFromFile(eventPath)
 			isDataSymlink := filepath.Base(eventPath) == "..data"
 
 			r.logger.WithFields(log.Fields{
@@ -143,6 +169,10 @@ func (r *Reloader) watchLoop(ctx context.Context) {
 				r.scheduleReload()
 			}
 
+		case <-r.sighup:
+			r.logger.Info("SIGHUP received, reloading configuration")
+			r.reloadNow()
+
 		case err, ok := <-r.watcher.Errors:
 			if !ok {
 				return
@@ -180,6 +210,21 @@ func (r *Reloader) scheduleReload() {
 	})
 }
 
+// reloadNow cancels the pending debounced reload, if any, and reloads
+func (r *Reloader) reloadNow() {
+	r.mu.Lock()
+
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+
+	r.mu.Unlock()
+
+	if err := r.reload(); err != nil {
+		r.logger.WithError(err).Error("Failed to reload configuration")
+	}
+}
+
 // reload reads the configuration file and triggers the callback
 func (r *Reloader) reload() error {
 	r.logger.Info("Reloading configuration")
@@ -190,6 +235,9 @@ func (r *Reloader) reload() error {
 		return err
 	}
 
+	// Pick up valueFrom files added by the new configuration
+	r.watchValueFromFiles(content)
+
 	// Trigger callback
 	if err := r.callback(content); err != nil {
 		r.logger.WithError(err).Error("Configuration reload callback failed")
@@ -200,3 +248,41 @@ func (r *Reloader) reload() error {
 
 	return nil
 }
+
+// watchValueFromFiles watches the directories of the files referenced through
+// valueFrom in the configuration content
+func (r *Reloader) watchValueFromFiles(content []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, file := range ValueFromFiles(content) {
+		file = filepath.Clean(file)
+		r.valueFromFiles[file] = struct{}{}
+
+		dir := filepath.Dir(file)
+		if _, ok := r.watchedDirs[dir]; ok {
+			continue
+		}
+
+		if err := r.watcher.Add(dir); err != nil {
+			r.logger.WithError(err).WithField("file", file).
+				Warn("Failed to watch valueFrom file, changes require a config reload")
+
+			continue
+		}
+
+		r.watchedDirs[dir] = struct{}{}
+
+		r.logger.WithField("watch_dir", dir).Info("Watching valueFrom file directory")
+	}
+}
+
+// isValueFromFile returns whether path is a file referenced through valueFrom
+func (r *Reloader) isValueFromFile(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.valueFromFiles[path]
+
+	return ok
+}
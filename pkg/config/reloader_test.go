@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -221,3 +222,41 @@ func TestReloaderSymlink(t *testing.T) {
 
 	t.Logf("Symlink test completed successfully. Total reloads: %d", count)
 }
+
+func TestReloaderSIGHUP(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("initial: config"), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	reloaded := make(chan string, 1)
+
+	reloader, err := config.NewReloader(configPath, func(content []byte) error {
+		reloaded <- string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+
+	// Only the signal can trigger the reload within the test
+	reloader.SetDebounce(time.Hour)
+
+	if err := reloader.Start(t.Context()); err != nil {
+		t.Fatalf("Failed to start reloader: %v", err)
+	}
+	defer func() { _ = reloader.Stop() }()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+
+	select {
+	case content := <-reloaded:
+		if content != "initial: config" {
+			t.Errorf("Expected the config content, got %q", content)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SIGHUP to trigger a reload")
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// valueFromKey marks a config value resolved from an external source, e.g.
+//
+//	accessKeySecret:
+//	  valueFrom:
+//	    file: /var/run/secrets/cloud/access-key-secret
+const valueFromKey = "valueFrom"
+
+// resolveValueFrom returns data with every {valueFrom: {file: path}} node
+// replaced by the trimmed content of the referenced file. Unreadable files
+// resolve to an empty string so the rest of the module config still loads.
+func resolveValueFrom(data any) any {
+	switch v := data.(type) {
+	case map[string]any:
+		if path, ok := valueFromFile(v); ok {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.WithError(err).WithField("file", path).
+					Warn("Failed to read config value from file")
+
+				return ""
+			}
+
+			return strings.TrimSpace(string(content))
+		}
+
+		resolved := make(map[string]any, len(v))
+		for key, value := range v {
+			resolved[key] = resolveValueFrom(value)
+		}
+
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, value := range v {
+			resolved[i] = resolveValueFrom(value)
+		}
+
+		return resolved
+	default:
+		return data
+	}
+}
+
+// valueFromFile returns the file referenced by a {valueFrom: {file: path}} node
+func valueFromFile(node map[string]any) (string, bool) {
+	if len(node) != 1 {
+		return "", false
+	}
+
+	source, ok := node[valueFromKey].(map[string]any)
+	if !ok {
+		return "", false
+	}
+
+	path, ok := source["file"].(string)
+
+	return path, ok && path != ""
+}
+
+// ValueFromFiles returns the sorted, deduplicated files referenced through
+// valueFrom anywhere in the YAML config content, so they can be watched for changes
+func ValueFromFiles(configContent []byte) []string {
+	var fullConfig map[string]any
+	if err := yaml.Unmarshal(configContent, &fullConfig); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	collectValueFromFiles(fullConfig, seen)
+
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+
+	return files
+}
+
+// collectValueFromFiles records every valueFrom file reference found in data
+func collectValueFromFiles(data any, seen map[string]struct{}) {
+	switch v := data.(type) {
+	case map[string]any:
+		if path, ok := valueFromFile(v); ok {
+			seen[path] = struct{}{}
+			return
+		}
+
+		for _, value := range v {
+			collectValueFromFiles(value, seen)
+		}
+	case []any:
+		for _, value := range v {
+			collectValueFromFiles(value, seen)
+		}
+	}
+}
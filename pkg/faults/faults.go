@@ -0,0 +1,281 @@
+// Package faults injects failures and latency into collector checks while a
+// fault is armed through the debug server, so alerting pipelines and
+// dashboards can be tested end to end in staging
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ModeFail makes the check fail
+	ModeFail = "fail"
+	// ModeDelay delays the check, e.g. to simulate a slow cloud API
+	ModeDelay = "delay"
+
+	// DefaultDuration is how long a fault stays armed when none is requested
+	DefaultDuration = 10 * time.Minute
+	// MaxDuration bounds how long a fault stays armed
+	MaxDuration = time.Hour
+	// DefaultCount is the number of checks affected when none is requested
+	DefaultCount = 1
+)
+
+// ErrInjected is returned by Inject for checks forced to fail
+var ErrInjected = errors.New("injected fault")
+
+// Rule selects the checks to inject a fault into
+type Rule struct {
+	// Collector whose checks are affected (required)
+	Collector string `json:"collector"`
+	// Target of the check: the domain for the domain collector,
+	// provider:accountID for the cloudbalance collector. Empty matches all.
+	Target string `json:"target,omitempty"`
+	// Mode is "fail" (default) or "delay"
+	Mode string `json:"mode"`
+	// Count is the number of checks affected
+	Count int `json:"count"`
+	// Delay added to the check in ModeDelay
+	Delay time.Duration `json:"-"`
+	// Duration the fault stays armed, even if fewer than Count checks ran
+	Duration time.Duration `json:"-"`
+}
+
+// Fault is an armed fault injection rule
+type Fault struct {
+	ID        int       `json:"id"`
+	Rule      Rule      `json:"rule"`
+	Delay     string    `json:"delay,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Injected  int       `json:"injected"`
+}
+
+// Injector holds the armed faults
+type Injector struct {
+	active atomic.Bool
+
+	mu     sync.Mutex
+	faults map[int]*Fault
+	nextID int
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+var (
+	defaultInjector *Injector
+	once            sync.Once
+)
+
+// Default returns the process-wide injector used by collectors
+func Default() *Injector {
+	once.Do(func() {
+		defaultInjector = NewInjector()
+	})
+
+	return defaultInjector
+}
+
+// NewInjector creates an injector without armed faults
+func NewInjector() *Injector {
+	return &Injector{
+		faults: make(map[int]*Fault),
+		nextID: 1,
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// Inject applies the first armed fault matching a check of the named collector.
+// It is a no-op unless a fault is armed, so collectors can call it before every check.
+func Inject(ctx context.Context, collectorName, target string) error {
+	return Default().Inject(ctx, collectorName, target)
+}
+
+// Arm validates a rule and arms it
+func (i *Injector) Arm(rule Rule) (Fault, error) {
+	if rule.Collector == "" {
+		return Fault{}, errors.New("collector is required")
+	}
+
+	switch rule.Mode {
+	case "":
+		rule.Mode = ModeFail
+	case ModeFail:
+	case ModeDelay:
+		if rule.Delay <= 0 {
+			return Fault{}, errors.New("delay is required in delay mode")
+		}
+	default:
+		return Fault{}, fmt.Errorf("invalid mode %q (expected %s or %s)", rule.Mode, ModeFail, ModeDelay)
+	}
+
+	if rule.Count <= 0 {
+		rule.Count = DefaultCount
+	}
+
+	if rule.Duration <= 0 {
+		rule.Duration = DefaultDuration
+	}
+
+	if rule.Duration > MaxDuration {
+		return Fault{}, fmt.Errorf("duration %s exceeds maximum of %s", rule.Duration, MaxDuration)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	fault := &Fault{
+		ID:        i.nextID,
+		Rule:      rule,
+		ExpiresAt: i.now().Add(rule.Duration),
+	}
+	if rule.Mode == ModeDelay {
+		fault.Delay = rule.Delay.String()
+	}
+
+	i.nextID++
+	i.faults[fault.ID] = fault
+	i.active.Store(true)
+
+	log.WithFields(log.Fields{
+		"id":        fault.ID,
+		"collector": rule.Collector,
+		"target":    rule.Target,
+		"mode":      rule.Mode,
+		"count":     rule.Count,
+		"delay":     rule.Delay,
+		"expiresAt": fault.ExpiresAt,
+	}).Warn("Fault injection armed")
+
+	return *fault, nil
+}
+
+// Disarm removes the fault with the given ID, or all faults when id is 0
+func (i *Injector) Disarm(id int) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if id == 0 {
+		clear(i.faults)
+	} else {
+		if _, ok := i.faults[id]; !ok {
+			return errors.New("fault not found")
+		}
+
+		delete(i.faults, id)
+	}
+
+	i.active.Store(len(i.faults) > 0)
+
+	log.WithField("id", id).Info("Fault injection disarmed")
+
+	return nil
+}
+
+// Faults returns the armed faults
+func (i *Injector) Faults() []Fault {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.expireLocked()
+
+	faults := make([]Fault, 0, len(i.faults))
+	for id := 1; id < i.nextID; id++ {
+		if fault, ok := i.faults[id]; ok {
+			faults = append(faults, *fault)
+		}
+	}
+
+	return faults
+}
+
+// Inject applies the first armed fault matching a check of the named collector:
+// ModeFail returns ErrInjected, ModeDelay waits for the delay (or ctx) first
+func (i *Injector) Inject(ctx context.Context, collectorName, target string) error {
+	if !i.active.Load() {
+		return nil
+	}
+
+	i.mu.Lock()
+	i.expireLocked()
+
+	var matched *Fault
+
+	for id := 1; id < i.nextID; id++ {
+		fault, ok := i.faults[id]
+		if ok && fault.matches(collectorName, target) {
+			matched = fault
+			break
+		}
+	}
+
+	if matched == nil {
+		i.mu.Unlock()
+		return nil
+	}
+
+	rule := matched.Rule
+	matched.Injected++
+
+	if matched.Injected >= rule.Count {
+		delete(i.faults, matched.ID)
+		i.active.Store(len(i.faults) > 0)
+	}
+	i.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"id":        matched.ID,
+		"collector": collectorName,
+		"target":    target,
+		"mode":      rule.Mode,
+	}).Warn("Injecting fault")
+
+	if rule.Mode == ModeDelay {
+		return i.sleep(ctx, rule.Delay)
+	}
+
+	return fmt.Errorf("%w %d", ErrInjected, matched.ID)
+}
+
+// expireLocked drops expired faults. Must be called with i.mu held.
+func (i *Injector) expireLocked() {
+	now := i.now()
+
+	for id, fault := range i.faults {
+		if now.After(fault.ExpiresAt) {
+			delete(i.faults, id)
+			log.WithFields(log.Fields{
+				"id":       id,
+				"injected": fault.Injected,
+			}).Info("Fault injection expired")
+		}
+	}
+
+	i.active.Store(len(i.faults) > 0)
+}
+
+// matches returns whether a check of the named collector is selected by the fault
+func (fault *Fault) matches(collectorName, target string) bool {
+	return fault.Rule.Collector == collectorName &&
+		(fault.Rule.Target == "" || fault.Rule.Target == target)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+//nolint:testpackage // Tests need access to private fields
+package faults
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInjector_Fail(t *testing.T) {
+	now := time.Now()
+	injector := NewInjector()
+	injector.now = func() time.Time { return now }
+
+	if err := injector.Inject(context.Background(), "domain", "example.com"); err != nil {
+		t.Fatalf("Expected no fault while none is armed, got %v", err)
+	}
+
+	if _, err := injector.Arm(Rule{Collector: "domain", Target: "example.com", Count: 2}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+
+	if err := injector.Inject(context.Background(), "domain", "other.com"); err != nil {
+		t.Errorf("Expected other targets to be unaffected, got %v", err)
+	}
+
+	for range 2 {
+		if err := injector.Inject(context.Background(), "domain", "example.com"); !errors.Is(err, ErrInjected) {
+			t.Errorf("Expected ErrInjected, got %v", err)
+		}
+	}
+
+	if err := injector.Inject(context.Background(), "domain", "example.com"); err != nil {
+		t.Errorf("Expected fault to be disarmed after count checks, got %v", err)
+	}
+
+	if injector.active.Load() {
+		t.Error("Expected injector to be inactive")
+	}
+
+	fault, err := injector.Arm(Rule{Collector: "domain"})
+	if err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+
+	now = fault.ExpiresAt.Add(time.Second)
+
+	if err := injector.Inject(context.Background(), "domain", "example.com"); err != nil {
+		t.Errorf("Expected expired fault to be ignored, got %v", err)
+	}
+}
+
+func TestInjector_Delay(t *testing.T) {
+	injector := NewInjector()
+
+	var slept time.Duration
+
+	injector.sleep = func(_ context.Context, d time.Duration) error {
+		slept = d
+		return nil
+	}
+
+	if _, err := injector.Arm(Rule{Collector: "cloudbalance", Mode: ModeDelay}); err == nil {
+		t.Error("Expected error for delay mode without delay")
+	}
+
+	if _, err := injector.Arm(Rule{Collector: "cloudbalance", Mode: ModeDelay, Delay: 30 * time.Second}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+
+	if err := injector.Inject(context.Background(), "cloudbalance", "alibaba:123"); err != nil {
+		t.Errorf("Expected delayed check to succeed, got %v", err)
+	}
+
+	if slept != 30*time.Second {
+		t.Errorf("Expected 30s delay, got %s", slept)
+	}
+}
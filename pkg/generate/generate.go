@@ -0,0 +1,201 @@
+// Package generate renders a Grafana dashboard, Prometheus alerting rules and RBAC manifests
+// for the enabled collectors, using the configured metrics namespace
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options selects what is generated
+type Options struct {
+	// Namespace is the metrics namespace (prefix) of the exporter
+	Namespace string
+	// Collectors are the enabled collectors; collectors without built-in panels
+	// or rules (e.g. dynamic) are skipped
+	Collectors []string
+	// Title of the generated dashboard
+	Title string
+}
+
+// specs returns the exporter spec followed by the specs of the enabled
+// collectors, in the order they are enabled
+func (o Options) specs() []spec {
+	all := collectorSpecs(o.Namespace)
+	specs := []spec{selfSpec(o.Namespace)}
+	seen := make(map[string]bool, len(o.Collectors))
+
+	for _, name := range o.Collectors {
+		s, ok := all[name]
+		if !ok || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		s.name = name
+
+		specs = append(specs, s)
+	}
+
+	return specs
+}
+
+// Dashboard panel grid layout (Grafana uses a 24 columns grid)
+const (
+	gridWidth   = 24
+	panelWidth  = 12
+	panelHeight = 8
+	rowHeight   = 1
+)
+
+// Dashboard returns the Grafana dashboard JSON, with one row per collector
+func Dashboard(opts Options) ([]byte, error) {
+	title := opts.Title
+	if title == "" {
+		title = "Sealos State Metrics"
+	}
+
+	datasource := map[string]string{"type": "prometheus", "uid": "${datasource}"}
+
+	var (
+		panels []map[string]any
+		id     int
+		y      int
+	)
+
+	for _, s := range opts.specs() {
+		id++
+
+		panels = append(panels, map[string]any{
+			"id":        id,
+			"type":      "row",
+			"title":     s.title,
+			"collapsed": false,
+			"gridPos":   map[string]int{"x": 0, "y": y, "w": gridWidth, "h": rowHeight},
+			"panels":    []any{},
+		})
+		y += rowHeight
+
+		for i, p := range s.panels {
+			id++
+
+			panelType := "timeseries"
+			if p.stat {
+				panelType = "stat"
+			}
+
+			unit := p.unit
+			if unit == "" {
+				unit = "short"
+			}
+
+			panels = append(panels, map[string]any{
+				"id":         id,
+				"type":       panelType,
+				"title":      p.title,
+				"datasource": datasource,
+				"gridPos": map[string]int{
+					"x": (i % 2) * panelWidth,
+					"y": y + (i/2)*panelHeight,
+					"w": panelWidth,
+					"h": panelHeight,
+				},
+				"fieldConfig": map[string]any{
+					"defaults":  map[string]any{"unit": unit},
+					"overrides": []any{},
+				},
+				"targets": []map[string]any{{
+					"refId":        "A",
+					"datasource":   datasource,
+					"expr":         p.expr,
+					"legendFormat": p.legend,
+				}},
+			})
+		}
+
+		y += (len(s.panels) + 1) / 2 * panelHeight
+	}
+
+	dashboard := map[string]any{
+		"title":         title,
+		"uid":           "sealos-state-metrics",
+		"tags":          []string{"sealos-state-metrics"},
+		"schemaVersion": 39,
+		"editable":      true,
+		"refresh":       "1m",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]any{
+			"list": []map[string]any{{
+				"name":  "datasource",
+				"label": "Data source",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": panels,
+	}
+
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dashboard: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// ruleFile is a Prometheus rule file
+type ruleFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string         `yaml:"name"`
+	Rules []alertingRule `yaml:"rules"`
+}
+
+type alertingRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Alerts returns a Prometheus rule file with one group per collector
+func Alerts(opts Options) ([]byte, error) {
+	var file ruleFile
+
+	for _, s := range opts.specs() {
+		if len(s.rules) == 0 {
+			continue
+		}
+
+		group := ruleGroup{Name: "sealos-state-metrics." + s.name}
+
+		for _, r := range s.rules {
+			group.Rules = append(group.Rules, alertingRule{
+				Alert:       r.alert,
+				Expr:        r.expr,
+				For:         r.forDuration,
+				Labels:      map[string]string{"severity": r.severity},
+				Annotations: map[string]string{"summary": r.summary},
+			})
+		}
+
+		file.Groups = append(file.Groups, group)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(file); err != nil {
+		return nil, fmt.Errorf("failed to encode alerting rules: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,168 @@
+package generate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/generate"
+	"gopkg.in/yaml.v3"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestDashboard(t *testing.T) {
+	data, err := generate.Dashboard(generate.Options{
+		Namespace:  "sealos",
+		Collectors: []string{"domain", "dynamic", "domain"},
+	})
+	if err != nil {
+		t.Fatalf("Dashboard() error = %v", err)
+	}
+
+	var dashboard struct {
+		Panels []struct {
+			Type    string `json:"type"`
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		t.Fatalf("Dashboard is not valid JSON: %v", err)
+	}
+
+	var rows []string
+
+	for _, panel := range dashboard.Panels {
+		if panel.Type == "row" {
+			rows = append(rows, panel.Title)
+			continue
+		}
+
+		for _, target := range panel.Targets {
+			if !strings.Contains(target.Expr, "sealos_") {
+				t.Errorf("Expected namespaced metrics in %q", target.Expr)
+			}
+		}
+	}
+
+	// Exporter first, unknown collectors skipped, duplicates once
+	if strings.Join(rows, ",") != "Exporter,Domains" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	data, err := generate.Alerts(generate.Options{Collectors: []string{"node", "userbalance"}})
+	if err != nil {
+		t.Fatalf("Alerts() error = %v", err)
+	}
+
+	var file struct {
+		Groups []struct {
+			Name  string `yaml:"name"`
+			Rules []struct {
+				Alert string `yaml:"alert"`
+				Expr  string `yaml:"expr"`
+			} `yaml:"rules"`
+		} `yaml:"groups"`
+	}
+
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Alerts are not valid YAML: %v", err)
+	}
+
+	// The userbalance collector has no rules, so it gets no group
+	if len(file.Groups) != 2 {
+		t.Fatalf("Expected exporter and node groups, got %+v", file.Groups)
+	}
+
+	rule := file.Groups[1].Rules[0]
+	if rule.Alert != "NodeUnhealthy" || rule.Expr != "node_healthy == 0" {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	data, err := generate.RBAC(generate.RBACOptions{
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+		},
+		LeaseNamespace: "sealos-system",
+		LeaseName:      "sealos-state-metric",
+	})
+	if err != nil {
+		t.Fatalf("RBAC() error = %v", err)
+	}
+
+	type role struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"metadata"`
+		Rules []struct {
+			APIGroups     []string `yaml:"apiGroups"`
+			Resources     []string `yaml:"resources"`
+			ResourceNames []string `yaml:"resourceNames"`
+			Verbs         []string `yaml:"verbs"`
+		} `yaml:"rules"`
+	}
+
+	var roles []role
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		var r role
+
+		err := decoder.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("RBAC manifests are not valid YAML: %v", err)
+		}
+
+		roles = append(roles, r)
+	}
+
+	if len(roles) != 2 {
+		t.Fatalf("Expected a ClusterRole and a Role, got %d manifests", len(roles))
+	}
+
+	clusterRole := roles[0]
+	if clusterRole.Kind != "ClusterRole" || clusterRole.Metadata.Name != "sealos-state-metrics" ||
+		len(clusterRole.Rules) != 1 || clusterRole.Rules[0].APIGroups[0] != "" {
+		t.Errorf("Unexpected ClusterRole %+v", clusterRole)
+	}
+
+	lease := roles[1]
+	if lease.Kind != "Role" || lease.Metadata.Namespace != "sealos-system" ||
+		len(lease.Rules) != 2 || lease.Rules[1].ResourceNames[0] != "sealos-state-metric" {
+		t.Errorf("Unexpected lease Role %+v", lease)
+	}
+
+	// Sharded leases are not restricted to a name
+	data, err = generate.RBAC(generate.RBACOptions{LeaseNamespace: "sealos-system"})
+	if err != nil {
+		t.Fatalf("RBAC() error = %v", err)
+	}
+
+	if strings.Contains(string(data), "resourceNames") {
+		t.Error("Expected the lease Role of sharding to grant access to every lease")
+	}
+
+	data, err = generate.RBAC(generate.RBACOptions{})
+	if err != nil {
+		t.Fatalf("RBAC() error = %v", err)
+	}
+
+	if strings.Contains(string(data), "kind: Role") {
+		t.Error("Expected no lease Role without leader election")
+	}
+}
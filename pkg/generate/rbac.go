@@ -0,0 +1,120 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// RBACOptions selects the generated RBAC manifests
+type RBACOptions struct {
+	// Name of the ClusterRole and leader election Role
+	Name string
+	// Rules are the cluster-wide permissions of the exporter, e.g. the merged
+	// permissions of the enabled collectors
+	Rules []rbacv1.PolicyRule
+	// LeaseNamespace is the namespace of the leader election lease; no Role is
+	// generated when empty (leader election disabled)
+	LeaseNamespace string
+	// LeaseName is the name of the leader election lease; access is granted
+	// to every lease of the namespace when empty (sharding, with one lease
+	// per collector)
+	LeaseName string
+}
+
+// rbacRole is a ClusterRole or Role manifest
+type rbacRole struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Metadata   rbacMetadata     `yaml:"metadata"`
+	Rules      []rbacPolicyRule `yaml:"rules"`
+}
+
+type rbacMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type rbacPolicyRule struct {
+	APIGroups     []string `yaml:"apiGroups"`
+	Resources     []string `yaml:"resources"`
+	ResourceNames []string `yaml:"resourceNames,omitempty"`
+	Verbs         []string `yaml:"verbs"`
+}
+
+// RBAC returns the ClusterRole granting the exporter permissions and, with
+// leader election, the Role granting access to the lease in its namespace
+func RBAC(opts RBACOptions) ([]byte, error) {
+	name := opts.Name
+	if name == "" {
+		name = "sealos-state-metrics"
+	}
+
+	roles := []rbacRole{{
+		APIVersion: rbacv1.SchemeGroupVersion.String(),
+		Kind:       "ClusterRole",
+		Metadata:   rbacMetadata{Name: name},
+		Rules:      policyRules(opts.Rules),
+	}}
+
+	if opts.LeaseNamespace != "" {
+		var leaseNames []string
+		if opts.LeaseName != "" {
+			leaseNames = []string{opts.LeaseName}
+		}
+
+		roles = append(roles, rbacRole{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "Role",
+			Metadata:   rbacMetadata{Name: name, Namespace: opts.LeaseNamespace},
+			// Creation cannot be restricted to a resource name
+			Rules: []rbacPolicyRule{
+				{
+					APIGroups: []string{"coordination.k8s.io"},
+					Resources: []string{"leases"},
+					Verbs:     []string{"create"},
+				},
+				{
+					APIGroups:     []string{"coordination.k8s.io"},
+					Resources:     []string{"leases"},
+					ResourceNames: leaseNames,
+					Verbs:         []string{"get", "update"},
+				},
+			},
+		})
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	for _, role := range roles {
+		if err := encoder.Encode(role); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", role.Kind, err)
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode RBAC manifests: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// policyRules converts Kubernetes policy rules to their manifest form
+func policyRules(rules []rbacv1.PolicyRule) []rbacPolicyRule {
+	result := make([]rbacPolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, rbacPolicyRule{
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+			Verbs:         rule.Verbs,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,675 @@
+package generate
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// panel is a Grafana time series or stat panel showing one query
+type panel struct {
+	title  string
+	expr   string
+	legend string
+	unit   string
+	stat   bool // stat panel instead of a time series
+}
+
+// rule is a Prometheus alerting rule
+type rule struct {
+	alert       string
+	expr        string
+	forDuration string
+	severity    string
+	summary     string
+}
+
+// spec holds the panels and alerting rules of a collector
+type spec struct {
+	name   string // collector name, set from the specs map key
+	title  string
+	panels []panel
+	rules  []rule
+}
+
+// selfSpec returns the panels and rules of the exporter self-metrics, generated
+// whatever the enabled collectors
+func selfSpec(namespace string) spec {
+	m := func(name string) string {
+		return prometheus.BuildFQName(namespace, "state_metric", name)
+	}
+
+	e := func(name string) string {
+		return prometheus.BuildFQName(namespace, "exporter", name)
+	}
+
+	up := prometheus.BuildFQName(namespace, "collector", "up")
+
+	return spec{
+		name:  "exporter",
+		title: "Exporter",
+		panels: []panel{
+			{title: "Collector success", expr: "min by (collector) (" + m("collector_success") + ")", legend: "{{collector}}"},
+			{
+				title:  "Collector duration",
+				expr:   "max by (collector) (" + m("collector_duration_seconds") + ")",
+				legend: "{{collector}}",
+				unit:   "s",
+			},
+			{
+				title:  "Scrape duration",
+				expr:   "sum by (server) (rate(" + m("scrape_duration_seconds_sum") + "[5m])) / sum by (server) (rate(" + m("scrape_duration_seconds_count") + "[5m]))",
+				legend: "{{server}}",
+				unit:   "s",
+			},
+			{
+				title:  "Informer watch errors",
+				expr:   "sum by (collector) (rate(" + e("informer_watch_errors_total") + "[5m]))",
+				legend: "{{collector}}",
+			},
+			{
+				title:  "Informer cache objects",
+				expr:   "max by (collector, resource) (" + e("informer_cache_objects") + ")",
+				legend: "{{collector}} {{resource}}",
+			},
+			{title: "Heap", expr: e("memory_heap_alloc_bytes"), legend: "{{instance}}", unit: "bytes"},
+		},
+		rules: []rule{
+			{
+				alert:       "StateMetricsCollectorFailing",
+				expr:        m("collector_success") + " == 0",
+				forDuration: "15m",
+				severity:    "warning",
+				summary:     "Collector {{ $labels.collector }} failed on {{ $labels.instance }}",
+			},
+			{
+				alert:       "StateMetricsCollectorDown",
+				expr:        up + " == 0",
+				forDuration: "10m",
+				severity:    "critical",
+				summary:     "Collector {{ $labels.collector }} is down on {{ $labels.instance }}",
+			},
+			{
+				alert:       "StateMetricsInformerWatchErrors",
+				expr:        "rate(" + e("informer_watch_errors_total") + "[10m]) > 0",
+				forDuration: "15m",
+				severity:    "warning",
+				summary:     "Informer watches of collector {{ $labels.collector }} keep failing on {{ $labels.instance }}",
+			},
+			{
+				alert:       "StateMetricsScrapeTimeoutServed",
+				expr:        "rate(" + m("scrape_timeout_served_total") + "[10m]) > 0",
+				forDuration: "15m",
+				severity:    "warning",
+				summary:     "Gathering exceeds the scrape timeout on {{ $labels.instance }}, scrapes are served the last snapshot",
+			},
+			{
+				alert:       "StateMetricsPushFailing",
+				expr:        "rate(" + m("push_samples_total") + "{result=\"dropped\"}[10m]) > 0",
+				forDuration: "15m",
+				severity:    "warning",
+				summary:     "Metrics pushed by {{ $labels.instance }} are dropped by the remote endpoint",
+			},
+		},
+	}
+}
+
+// collectorSpecs returns the panels and rules of each collector, keyed by
+// collector name. Metric names are built like the collectors build them.
+func collectorSpecs(namespace string) map[string]spec {
+	m := func(subsystem, name string) string {
+		return prometheus.BuildFQName(namespace, subsystem, name)
+	}
+
+	return map[string]spec{
+		"domain": {
+			title: "Domains",
+			panels: []panel{
+				{title: "Healthy IPs", expr: m("domain", "health") + `{type="healthy_ips"}`, legend: "{{domain}}"},
+				{title: "Unhealthy IPs", expr: m("domain", "health") + `{type="unhealthy_ips"}`, legend: "{{domain}}"},
+				{
+					title:  "Response time",
+					expr:   "max by (domain) (" + m("domain", "response_time_seconds") + ")",
+					legend: "{{domain}}",
+					unit:   "s",
+				},
+				{
+					title: "Response time by phase",
+					expr: "label_replace(max(" + m("domain", "dns_resolution_seconds") + `), "phase", "dns", "", "")` +
+						" or label_replace(max(" + m("domain", "connect_seconds") + `), "phase", "connect", "", "")` +
+						" or label_replace(max(" + m("domain", "tls_handshake_seconds") + `), "phase", "tls", "", "")` +
+						" or label_replace(max(" + m("domain", "ttfb_seconds") + `), "phase", "ttfb", "", "")`,
+					legend: "{{phase}}",
+					unit:   "s",
+				},
+				{
+					title:  "Certificate expiry",
+					expr:   "min by (domain) (" + m("domain", "cert_expiry_seconds") + ")",
+					legend: "{{domain}}",
+					unit:   "s",
+				},
+				{
+					title:  "Ingresses down",
+					expr:   "count by (namespace) (" + m("domain", "ingress_up") + " == 0)",
+					legend: "{{namespace}}",
+				},
+				{
+					title:  "IP timeouts by port",
+					expr:   "sum by (port) (" + m("domain", "ip_timeout") + ")",
+					legend: "{{port}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "DomainDown",
+					expr:        m("domain", "health") + `{type="healthy_ips"} == 0`,
+					forDuration: "10m",
+					severity:    "critical",
+					summary:     "Domain {{ $labels.domain }} has no healthy IP",
+				},
+				{
+					alert: "DomainBackendDown",
+					expr: m("domain", "status") + `{check_type="http"} == 0` +
+						" and on (domain) " + m("domain", "health") + `{type="healthy_ips"} > 0`,
+					forDuration: "10m",
+					severity:    "warning",
+					summary:     "IP {{ $labels.ip }} of {{ $labels.domain }} fails while the other IPs of the record serve it",
+				},
+				{
+					alert: "DomainPublicPathDown",
+					expr: m("domain", "health") + `{type="healthy_ips"} == 0` +
+						" and on (domain) max by (domain) (" + m("domain", "vip_status") + `{check_type="http"}) == 1`,
+					forDuration: "10m",
+					severity:    "critical",
+					summary:     "Domain {{ $labels.domain }} is served by the gateway but unreachable through public DNS",
+				},
+				{
+					alert:       "DomainDNSRecordMissing",
+					expr:        m("domain", "dns_record_ok") + " == 0",
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "{{ $labels.type }} record of {{ $labels.domain }} is missing or not the expected one",
+				},
+				{
+					alert:       "DomainCertificateExpiringSoon",
+					expr:        "min by (domain) (" + m("domain", "cert_expiry_seconds") + ") < 7 * 86400",
+					forDuration: "1h",
+					severity:    "warning",
+					summary:     "Certificate of {{ $labels.domain }} expires in less than 7 days",
+				},
+				{
+					alert:       "DomainPortCertificateExpiringSoon",
+					expr:        m("domain", "port_cert_expiry_seconds") + " < 7 * 86400",
+					forDuration: "1h",
+					severity:    "warning",
+					summary:     "Certificate of {{ $labels.domain }} on port {{ $labels.port }} expires in less than 7 days",
+				},
+				{
+					alert: "DomainCertificateChainExpiringSoon",
+					expr: "min by (domain) (" + m("domain", "cert_chain_expiry_seconds") + ")" +
+						" < min by (domain) (" + m("domain", "cert_expiry_seconds") + ")" +
+						" and min by (domain) (" + m("domain", "cert_chain_expiry_seconds") + ") < 7 * 86400",
+					forDuration: "1h",
+					severity:    "warning",
+					summary:     "An intermediate certificate presented by {{ $labels.domain }} expires in less than 7 days",
+				},
+			},
+		},
+		"node": {
+			title: "Nodes",
+			panels: []panel{
+				{title: "Unhealthy nodes", expr: "count(" + m("node", "healthy") + " == 0) or vector(0)", stat: true},
+				{title: "Abnormal conditions", expr: "sum by (condition) (" + m("node", "condition") + ")", legend: "{{condition}}"},
+				{title: "Cordoned nodes", expr: "sum(" + m("node", "unschedulable") + ") or vector(0)", stat: true},
+				{
+					title:  "Nodes by kubelet version",
+					expr:   "count by (kubelet_version) (" + m("node", "info") + ")",
+					legend: "{{kubelet_version}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "NodeUnhealthy",
+					expr:        m("node", "healthy") + " == 0",
+					forDuration: "5m",
+					severity:    "critical",
+					summary:     "Node {{ $labels.node }} is unhealthy",
+				},
+			},
+		},
+		"pod": {
+			title: "Pods",
+			panels: []panel{
+				{title: "Pods by phase", expr: "sum by (phase) (" + m("pod", "phase_count") + ")", legend: "{{phase}}"},
+				{
+					title:  "Pods by QoS class",
+					expr:   "sum by (qos_class) (" + m("pod", "qos_class_count") + ")",
+					legend: "{{qos_class}}",
+				},
+				{
+					title: "Stuck terminating pods",
+					expr:  "count(" + m("pod", "stuck_terminating_seconds") + ") or vector(0)",
+					stat:  true,
+				},
+				{
+					title:  "Volume failures by storage class",
+					expr:   "sum by (storage_class, reason) (" + m("pod", "volume_failure_count") + ")",
+					legend: "{{storage_class}} {{reason}}",
+				},
+				{
+					title:  "OOM kills by workload",
+					expr:   "sum by (namespace, workload) (increase(" + m("pod", "container_oom_kills_total") + "[1h]))",
+					legend: "{{namespace}}/{{workload}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "PodStuckTerminating",
+					expr:        m("pod", "stuck_terminating_seconds") + " > 3600",
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "Pod {{ $labels.namespace }}/{{ $labels.pod }} is stuck terminating",
+				},
+				{
+					alert:       "PodVolumeFailing",
+					expr:        m("pod", "volume_failure_seconds") + " > 600",
+					forDuration: "5m",
+					severity:    "warning",
+					summary:     "Pod {{ $labels.namespace }}/{{ $labels.pod }} cannot attach or mount volume {{ $labels.volume }} ({{ $labels.reason }})",
+				},
+				{
+					alert:       "PodContainerRestarting",
+					expr:        "increase(" + m("pod", "container_restarts_total") + "[15m]) > 3",
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "Container {{ $labels.container }} of pod {{ $labels.namespace }}/{{ $labels.pod }} is restarting repeatedly",
+				},
+				{
+					alert:    "PodContainerOOMKilled",
+					expr:     "increase(" + m("pod", "container_oom_kills_total") + "[15m]) > 0",
+					severity: "warning",
+					summary:  "Container {{ $labels.container }} of {{ $labels.workload_kind }} {{ $labels.namespace }}/{{ $labels.workload }} was OOM killed",
+				},
+			},
+		},
+		"imagepull": {
+			title: "Image pulls",
+			panels: []panel{
+				{title: "Pull failures", expr: "sum by (reason) (" + m("image", "pull_failures") + ")", legend: "{{reason}}"},
+				{title: "Slow pulls", expr: "sum by (registry) (" + m("image", "pull_slow") + ")", legend: "{{registry}}"},
+			},
+			rules: []rule{
+				{
+					alert:       "ImagePullFailing",
+					expr:        "sum by (namespace, pod, image, reason) (" + m("image", "pull_failures") + ") > 0",
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "Pod {{ $labels.namespace }}/{{ $labels.pod }} cannot pull {{ $labels.image }} ({{ $labels.reason }})",
+				},
+			},
+		},
+		"zombie": {
+			title: "Kubelet metrics",
+			panels: []panel{
+				{
+					title: "Nodes without kubelet metrics",
+					expr:  "count(" + m("node", "kubelet_metrics_available") + " == 0) or vector(0)",
+					stat:  true,
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "KubeletMetricsUnavailable",
+					expr:        m("node", "kubelet_metrics_available") + " == 0",
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "Kubelet metrics of node {{ $labels.node }} are unavailable",
+				},
+			},
+		},
+		"cert": {
+			title: "TLS secrets",
+			panels: []panel{
+				{
+					title:  "Time to expiry",
+					expr:   m("cert", "expiry_timestamp_seconds") + " - time()",
+					legend: "{{namespace}}/{{secret}}",
+					unit:   "s",
+				},
+				{title: "Unparsable secrets", expr: "count(" + m("cert", "parse_error") + ") or vector(0)", stat: true},
+				{
+					title:  "Secrets per shared certificate",
+					expr:   "topk(10, " + m("cert", "fingerprint_secrets") + ")",
+					legend: "{{common_name}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "CertificateExpiringSoon",
+					expr:        m("cert", "expiry_timestamp_seconds") + " - time() < 7 * 86400",
+					forDuration: "1h",
+					severity:    "warning",
+					summary:     "Certificate in secret {{ $labels.namespace }}/{{ $labels.secret }} expires in less than 7 days",
+				},
+				{
+					alert: "CertificateChainExpiringSoon",
+					expr: m("cert", "chain_expiry_timestamp_seconds") +
+						" < min by (namespace, secret) (" + m("cert", "expiry_timestamp_seconds") + ")" +
+						" and " + m("cert", "chain_expiry_timestamp_seconds") + " - time() < 7 * 86400",
+					forDuration: "1h",
+					severity:    "warning",
+					summary: "An intermediate certificate in secret {{ $labels.namespace }}/{{ $labels.secret }} " +
+						"expires in less than 7 days",
+				},
+				{
+					alert:       "CertificateServedMismatch",
+					expr:        m("cert", "served_mismatch") + " == 1",
+					forDuration: "30m",
+					severity:    "warning",
+					summary: "{{ $labels.domain }} ({{ $labels.ip }}) serves a certificate older than secret " +
+						"{{ $labels.namespace }}/{{ $labels.secret }}, the gateway has not reloaded it",
+				},
+			},
+		},
+		"event": {
+			title: "Warning events",
+			panels: []panel{
+				{
+					title:  "Warning events rate",
+					expr:   "topk(10, sum by (namespace, reason) (rate(" + m("event", "warnings_total") + "[5m])))",
+					legend: "{{namespace}} {{reason}}",
+				},
+				{title: "Namespaces in event storm", expr: "count(" + m("event", "storm_active") + " == 1)"},
+				{
+					title:  "Object churn of the top namespaces",
+					expr:   m("event", "object_churn_top"),
+					legend: "{{namespace}} {{resource}} {{type}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "EventStorm",
+					expr:        m("event", "storm_active") + " == 1",
+					forDuration: "10m",
+					severity:    "warning",
+					summary:     "Warning events of namespace {{ $labels.namespace }} exceed the storm threshold",
+				},
+			},
+		},
+		"helm": {
+			title: "Helm releases",
+			panels: []panel{
+				{title: "Releases by status", expr: "count by (status) (" + m("helm", "release_info") + ")", legend: "{{status}}"},
+			},
+			rules: []rule{
+				{
+					alert:       "HelmReleaseFailed",
+					expr:        m("helm", "release_info") + `{status="failed"}`,
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "Helm release {{ $labels.namespace }}/{{ $labels.release }} failed",
+				},
+			},
+		},
+		"kubeblocks": {
+			title: "KubeBlocks clusters",
+			panels: []panel{
+				{
+					title:  "Clusters by phase",
+					expr:   "sum by (phase) (" + m("kubeblocks_cluster", "phase_count") + ")",
+					legend: "{{phase}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "KubeBlocksClusterFailed",
+					expr:        m("kubeblocks_cluster", "info") + `{phase=~"Failed|Abnormal"}`,
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "KubeBlocks cluster {{ $labels.namespace }}/{{ $labels.cluster }} is {{ $labels.phase }}",
+				},
+			},
+		},
+		"critical": {
+			title: "Critical resources",
+			panels: []panel{
+				{
+					title: "Missing critical resources",
+					expr:  "count(" + m("critical", "resource_exists") + " == 0) or vector(0)",
+					stat:  true,
+				},
+				{
+					title: "Critical resources not ready",
+					expr:  "count(" + m("critical", "resource_ready") + " == 0) or vector(0)",
+					stat:  true,
+				},
+			},
+			rules: []rule{
+				{
+					alert:    "CriticalResourceMissing",
+					expr:     m("critical", "resource_exists") + " == 0",
+					severity: "critical",
+					summary:  "Critical {{ $labels.resource }} {{ $labels.namespace }}/{{ $labels.name }} was deleted",
+				},
+				{
+					alert:       "CriticalResourceNotReady",
+					expr:        m("critical", "resource_ready") + " == 0 and " + m("critical", "resource_exists") + " == 1",
+					forDuration: "10m",
+					severity:    "warning",
+					summary:     "Critical {{ $labels.resource }} {{ $labels.namespace }}/{{ $labels.name }} is not ready",
+				},
+			},
+		},
+		"probe": {
+			title: "Probes",
+			panels: []panel{
+				{
+					title: "Failing probes",
+					expr:  "count(" + m("probe", "success") + " == 0) or vector(0)",
+					stat:  true,
+				},
+				{
+					title:  "Probe duration",
+					expr:   m("probe", "duration_seconds"),
+					legend: "{{namespace}}/{{name}}",
+					unit:   "s",
+				},
+				{
+					title:  "Rejected probes",
+					expr:   "count by (reason) (" + m("probe", "rejected") + ")",
+					legend: "{{reason}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "ProbeFailing",
+					expr:        m("probe", "success") + " == 0",
+					forDuration: "5m",
+					severity:    "warning",
+					summary:     "Probe {{ $labels.namespace }}/{{ $labels.name }} of {{ $labels.target }} is failing: {{ $labels.reason }}",
+				},
+			},
+		},
+		"pvc": {
+			title: "Persistent volumes",
+			panels: []panel{
+				{
+					title:  "Claims by phase",
+					expr:   "count by (phase) (" + m("pvc", "phase") + ")",
+					legend: "{{phase}}",
+				},
+				{
+					title:  "Pending claims",
+					expr:   "topk(10, " + m("pvc", "pending_seconds") + ")",
+					legend: "{{namespace}}/{{persistentvolumeclaim}}",
+					unit:   "s",
+				},
+				{
+					title:  "Released and failed volumes",
+					expr:   "count by (phase) (" + m("pv", "phase") + `{phase=~"Released|Failed"})`,
+					legend: "{{phase}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "PersistentVolumeClaimPending",
+					expr:        m("pvc", "pending_seconds") + " > 900",
+					forDuration: "5m",
+					severity:    "warning",
+					summary:     "Claim {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} has been Pending for more than 15 minutes",
+				},
+				{
+					alert:       "PersistentVolumeClaimLost",
+					expr:        m("pvc", "phase") + `{phase="Lost"}`,
+					forDuration: "5m",
+					severity:    "critical",
+					summary:     "Claim {{ $labels.namespace }}/{{ $labels.persistentvolumeclaim }} lost its volume",
+				},
+			},
+		},
+		"quota": {
+			title: "Resource quotas",
+			panels: []panel{
+				{
+					title:  "Most used quotas",
+					expr:   "topk(10, " + m("resourcequota", "usage_ratio") + ")",
+					legend: "{{namespace}} {{resource}}",
+					unit:   "percentunit",
+				},
+				{
+					title: "Quotas above 90%",
+					expr:  "count(" + m("resourcequota", "usage_ratio") + " > 0.9) or vector(0)",
+					stat:  true,
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "ResourceQuotaAlmostExhausted",
+					expr:        m("resourcequota", "usage_ratio") + " > 0.9",
+					forDuration: "15m",
+					severity:    "warning",
+					summary:     "Namespace {{ $labels.namespace }} uses more than 90% of its {{ $labels.resource }} quota",
+				},
+			},
+		},
+		"dbprobe": {
+			title: "Databases",
+			panels: []panel{
+				{
+					title: "Databases down",
+					expr:  "count(" + m("database", "probe_up") + " == 0) or vector(0)",
+					stat:  true,
+				},
+				{
+					title:  "Handshake latency",
+					expr:   m("database", "probe_latency_seconds"),
+					legend: "{{namespace}}/{{cluster}} {{component}}",
+					unit:   "s",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "DatabaseUnreachable",
+					expr:        m("database", "probe_up") + " == 0",
+					forDuration: "5m",
+					severity:    "critical",
+					summary:     "Database {{ $labels.namespace }}/{{ $labels.cluster }} ({{ $labels.component }}) is down: {{ $labels.reason }}",
+				},
+			},
+		},
+		"lvm": {
+			title: "LVM",
+			panels: []panel{
+				{
+					title:  "Volume group usage",
+					expr:   "1 - " + m("lvm", "vgs_total_free") + " / " + m("lvm", "vgs_total_capacity"),
+					legend: "{{node}}",
+					unit:   "percentunit",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "LVMVolumeGroupAlmostFull",
+					expr:        m("lvm", "vgs_total_free") + " / " + m("lvm", "vgs_total_capacity") + " < 0.1",
+					forDuration: "30m",
+					severity:    "warning",
+					summary:     "LVM volume groups of node {{ $labels.node }} have less than 10% free space",
+				},
+			},
+		},
+		"openebs": {
+			title: "OpenEBS LVM",
+			panels: []panel{
+				{
+					title:  "Volume group usage",
+					expr:   "1 - " + m("openebs_lvm", "vg_free_bytes") + " / " + m("openebs_lvm", "vg_size_bytes"),
+					legend: "{{node}}/{{vg}}",
+					unit:   "percentunit",
+				},
+				{
+					title:  "Thin overprovisioning ratio",
+					expr:   m("openebs_lvm", "vg_thin_overprovisioning_ratio"),
+					legend: "{{node}}/{{vg}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "OpenEBSVolumeGroupAlmostFull",
+					expr:        m("openebs_lvm", "vg_free_bytes") + " / " + m("openebs_lvm", "vg_size_bytes") + " < 0.1",
+					forDuration: "30m",
+					severity:    "warning",
+					summary:     "Volume group {{ $labels.vg }} of node {{ $labels.node }} has less than 10% free space",
+				},
+				{
+					alert:       "OpenEBSVolumeGroupMissingPVs",
+					expr:        m("openebs_lvm", "vg_missing_pvs") + " > 0",
+					forDuration: "10m",
+					severity:    "critical",
+					summary:     "Volume group {{ $labels.vg }} of node {{ $labels.node }} has missing physical volumes",
+				},
+			},
+		},
+		"cloudbalance": {
+			title: "Cloud balances",
+			panels: []panel{
+				{title: "Account balance", expr: m("cloudbalance", "balance"), legend: "{{provider}} {{account_id}}"},
+				{title: "Month-to-date spend", expr: m("cloudbalance", "spend"), legend: "{{provider}} {{account_id}}"},
+				{
+					title:  "Days until balance exhaustion",
+					expr:   m("cloudbalance", "forecast_exhaustion_days"),
+					legend: "{{provider}} {{account_id}}",
+				},
+				{
+					title:  "Budget consumption",
+					expr:   m("cloudbalance", "budget_consumption_percent"),
+					legend: "{{provider}} {{account_id}}",
+				},
+			},
+			rules: []rule{
+				{
+					alert:       "CloudBalanceExhaustionForecast",
+					expr:        m("cloudbalance", "forecast_exhaustion_days") + " < 7",
+					forDuration: "1h",
+					severity:    "warning",
+					summary:     "Balance of {{ $labels.provider }} account {{ $labels.account_id }} runs out within a week",
+				},
+				{
+					alert:       "CloudBudgetExceeded",
+					expr:        m("cloudbalance", "budget_consumption_percent") + " > 100",
+					forDuration: "1h",
+					severity:    "warning",
+					summary:     "{{ $labels.provider }} account {{ $labels.account_id }} exceeded its monthly budget",
+				},
+				{
+					alert:       "CloudAccountCircuitOpen",
+					expr:        m("cloudbalance", "circuit_open") + " == 1",
+					forDuration: "30m",
+					severity:    "warning",
+					summary:     "Queries of {{ $labels.provider }} account {{ $labels.account_id }} keep failing, check its credentials",
+				},
+			},
+		},
+		"userbalance": {
+			title: "User balances",
+			panels: []panel{
+				{title: "User balance", expr: m("userbalance", "balance"), legend: "{{region}} {{owner}}"},
+			},
+		},
+	}
+}
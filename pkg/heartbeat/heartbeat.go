@@ -0,0 +1,155 @@
+// Package heartbeat publishes liveness pings to an external dead man's switch
+// (healthchecks.io style) after successful collection cycles
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Config contains heartbeat publisher configuration
+type Config struct {
+	// URL receives a POST after each successful cycle
+	URL string
+	// Collectors whose successful cycles trigger a heartbeat (empty = any polling collector)
+	Collectors []string
+	// Timeout for each heartbeat request
+	Timeout time.Duration
+	// MinInterval is the minimum time between two heartbeats
+	MinInterval time.Duration
+	// ReportFailures sends a POST to URL + "/fail" when a designated collector fails
+	ReportFailures bool
+}
+
+// Publisher sends heartbeats once every designated collector has completed
+// a successful cycle since the previous heartbeat
+type Publisher struct {
+	config Config
+	client *http.Client
+	logger *log.Entry
+
+	mu        sync.Mutex
+	succeeded map[string]bool
+	lastSent  time.Time
+	inFlight  bool
+}
+
+// NewPublisher creates a new heartbeat publisher
+func NewPublisher(cfg Config) *Publisher {
+	return &Publisher{
+		config:    cfg,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		logger:    log.WithField("component", "heartbeat"),
+		succeeded: make(map[string]bool),
+	}
+}
+
+// Observe records the result of a poll cycle and sends a heartbeat when due.
+// It matches the base.PollObserver signature and never blocks the caller.
+func (p *Publisher) Observe(collectorName string, err error) {
+	if !p.designated(collectorName) {
+		return
+	}
+
+	if err != nil {
+		if p.config.ReportFailures {
+			go p.send(strings.TrimSuffix(p.config.URL, "/")+"/fail", collectorName)
+		}
+
+		return
+	}
+
+	p.mu.Lock()
+
+	p.succeeded[collectorName] = true
+
+	if p.inFlight || !p.allSucceeded() ||
+		time.Since(p.lastSent) < p.config.MinInterval {
+		p.mu.Unlock()
+		return
+	}
+
+	p.inFlight = true
+	p.succeeded = make(map[string]bool)
+	p.mu.Unlock()
+
+	go func() {
+		ok := p.send(p.config.URL, collectorName)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.inFlight = false
+		if ok {
+			p.lastSent = time.Now()
+		}
+	}()
+}
+
+// designated returns whether heartbeats are driven by the given collector
+func (p *Publisher) designated(collectorName string) bool {
+	if len(p.config.Collectors) == 0 {
+		return true
+	}
+
+	for _, name := range p.config.Collectors {
+		if name == collectorName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allSucceeded returns whether every designated collector succeeded since the last heartbeat.
+// Must be called with p.mu held.
+func (p *Publisher) allSucceeded() bool {
+	for _, name := range p.config.Collectors {
+		if !p.succeeded[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// send POSTs to url and reports whether the endpoint accepted the request
+func (p *Publisher) send(url, collectorName string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	logger := p.logger.WithFields(log.Fields{
+		"url":       url,
+		"collector": collectorName,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, http.NoBody)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to create heartbeat request")
+		return false
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to send heartbeat")
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.WithError(fmt.Errorf("unexpected status code %d", resp.StatusCode)).
+			Warn("Heartbeat rejected")
+
+		return false
+	}
+
+	logger.Debug("Heartbeat sent")
+
+	return true
+}
@@ -0,0 +1,89 @@
+package heartbeat_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/heartbeat"
+)
+
+func TestPublisher_WaitsForAllDesignatedCollectors(t *testing.T) {
+	var pings, failures atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping/fail" {
+			failures.Add(1)
+		} else {
+			pings.Add(1)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p := heartbeat.NewPublisher(heartbeat.Config{
+		URL:            srv.URL + "/ping",
+		Collectors:     []string{"domain", "cloudbalance"},
+		Timeout:        time.Second,
+		ReportFailures: true,
+	})
+
+	// Non-designated collectors are ignored
+	p.Observe("zombie", nil)
+	// Only one of two designated collectors succeeded
+	p.Observe("domain", nil)
+	p.Observe("cloudbalance", errors.New("boom"))
+
+	waitFor(t, func() bool { return failures.Load() == 1 })
+
+	if got := pings.Load(); got != 0 {
+		t.Fatalf("Expected no heartbeat before all collectors succeeded, got %d", got)
+	}
+
+	p.Observe("cloudbalance", nil)
+
+	waitFor(t, func() bool { return pings.Load() == 1 })
+}
+
+func TestPublisher_MinInterval(t *testing.T) {
+	var pings atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		pings.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p := heartbeat.NewPublisher(heartbeat.Config{
+		URL:         srv.URL,
+		Timeout:     time.Second,
+		MinInterval: time.Hour,
+	})
+
+	p.Observe("domain", nil)
+	waitFor(t, func() bool { return pings.Load() == 1 })
+
+	p.Observe("domain", nil)
+	time.Sleep(50 * time.Millisecond)
+
+	if got := pings.Load(); got != 1 {
+		t.Fatalf("Expected heartbeats to be rate limited, got %d", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met before deadline")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,360 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Target labels added to all metrics to identify the cluster
+const (
+	ClusterLabel = "sealos_cluster"
+	RegionLabel  = "sealos_region"
+	ZoneLabel    = "sealos_zone"
+)
+
+// Node labels holding the region and zone, in order of preference
+var (
+	regionNodeLabels = []string{corev1.LabelTopologyRegion, corev1.LabelFailureDomainBetaRegion}
+	zoneNodeLabels   = []string{corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone}
+)
+
+// Cloud instance metadata endpoints (overridden in tests)
+var (
+	awsMetadataURL     = "http://169.254.169.254"
+	gcpMetadataURL     = "http://metadata.google.internal"
+	alibabaMetadataURL = "http://100.100.100.200"
+)
+
+// Cluster identifies the cluster, region and zone the exporter runs in.
+// Fields are empty when they could not be determined.
+type Cluster struct {
+	Name   string `json:"name,omitempty"`
+	Region string `json:"region,omitempty"`
+	Zone   string `json:"zone,omitempty"`
+}
+
+// complete returns whether all fields are set
+func (c Cluster) complete() bool {
+	return c.Name != "" && c.Region != "" && c.Zone != ""
+}
+
+// merge fills the empty fields of c from other
+func (c *Cluster) merge(other Cluster) {
+	if c.Name == "" {
+		c.Name = other.Name
+	}
+
+	if c.Region == "" {
+		c.Region = other.Region
+	}
+
+	if c.Zone == "" {
+		c.Zone = other.Zone
+	}
+}
+
+// Labels returns the target labels of the non-empty fields
+func (c Cluster) Labels() prometheus.Labels {
+	labels := prometheus.Labels{}
+
+	if c.Name != "" {
+		labels[ClusterLabel] = c.Name
+	}
+
+	if c.Region != "" {
+		labels[RegionLabel] = c.Region
+	}
+
+	if c.Zone != "" {
+		labels[ZoneLabel] = c.Zone
+	}
+
+	return labels
+}
+
+// ClusterOptions configures how the cluster identity is determined
+type ClusterOptions struct {
+	// Name, Region and Zone are explicitly configured values, they take
+	// precedence over detected ones
+	Name   string
+	Region string
+	Zone   string
+
+	// NodeLabels detects the region and zone from the topology labels of
+	// the node (Client must be set)
+	NodeLabels bool
+	// NodeName is the node whose labels are read (empty = any node)
+	NodeName string
+	// NameLabel is the node label holding the cluster name (empty = none)
+	NameLabel string
+	// Client reads node labels, nil disables node label detection
+	Client kubernetes.Interface
+
+	// CloudMetadata detects the region and zone from the cloud instance
+	// metadata service (AWS, GCP or Alibaba Cloud)
+	CloudMetadata bool
+	// Timeout bounds each metadata service query
+	Timeout time.Duration
+}
+
+// ResolveCluster determines the cluster identity from, in order of precedence,
+// the configuration, the node labels and the cloud instance metadata.
+// Detection failures are logged and leave the fields empty.
+func ResolveCluster(ctx context.Context, opts ClusterOptions) Cluster {
+	logger := log.WithField("module", "identity")
+
+	cluster := Cluster{Name: opts.Name, Region: opts.Region, Zone: opts.Zone}
+
+	if !cluster.complete() && opts.NodeLabels && opts.Client != nil {
+		fromNode, err := clusterFromNode(ctx, opts.Client, opts.NodeName, opts.NameLabel)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to detect cluster identity from node labels")
+		}
+
+		cluster.merge(fromNode)
+	}
+
+	if !cluster.complete() && opts.CloudMetadata {
+		fromCloud, err := clusterFromCloud(ctx, opts.Timeout)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to detect cluster identity from cloud metadata")
+		}
+
+		cluster.merge(fromCloud)
+	}
+
+	return cluster
+}
+
+// clusterFromNode reads the cluster identity from the labels of the named node,
+// or of any node when nodeName is empty
+func clusterFromNode(
+	ctx context.Context,
+	client kubernetes.Interface,
+	nodeName, nameLabel string,
+) (Cluster, error) {
+	var node *corev1.Node
+
+	if nodeName != "" {
+		var err error
+
+		node, err = client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return Cluster{}, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		}
+	} else {
+		nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
+		if err != nil {
+			return Cluster{}, fmt.Errorf("failed to list nodes: %w", err)
+		}
+
+		if len(nodes.Items) == 0 {
+			return Cluster{}, errors.New("no node found")
+		}
+
+		node = &nodes.Items[0]
+	}
+
+	cluster := Cluster{
+		Region: firstLabel(node.Labels, regionNodeLabels),
+		Zone:   firstLabel(node.Labels, zoneNodeLabels),
+	}
+
+	if nameLabel != "" {
+		cluster.Name = node.Labels[nameLabel]
+	}
+
+	return cluster, nil
+}
+
+// firstLabel returns the value of the first set label of keys
+func firstLabel(labels map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// cloudProvider queries the instance metadata service of a cloud
+type cloudProvider struct {
+	name  string
+	fetch func(ctx context.Context, client *http.Client) (Cluster, error)
+}
+
+// cloudProviders are tried in order until one answers
+var cloudProviders = []cloudProvider{
+	{name: "aws", fetch: fetchAWS},
+	{name: "gcp", fetch: fetchGCP},
+	{name: "alibaba", fetch: fetchAlibaba},
+}
+
+// clusterFromCloud returns the identity reported by the first cloud metadata
+// service answering
+func clusterFromCloud(ctx context.Context, timeout time.Duration) (Cluster, error) {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	client := &http.Client{Timeout: timeout}
+
+	var errs []error
+
+	for _, provider := range cloudProviders {
+		cluster, err := provider.fetch(ctx, client)
+		if err == nil {
+			log.WithFields(log.Fields{
+				"module":   "identity",
+				"provider": provider.name,
+			}).Debug("Detected cluster identity from cloud metadata")
+
+			return cluster, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.name, err))
+	}
+
+	return Cluster{}, errors.Join(errs...)
+}
+
+// fetchAWS queries the EC2 instance metadata service (IMDSv2)
+func fetchAWS(ctx context.Context, client *http.Client) (Cluster, error) {
+	token, err := metadataRequest(ctx, client, http.MethodPut, awsMetadataURL+"/latest/api/token",
+		map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	header := map[string]string{"X-aws-ec2-metadata-token": token}
+
+	region, err := metadataRequest(ctx, client, http.MethodGet,
+		awsMetadataURL+"/latest/meta-data/placement/region", header)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	zone, err := metadataRequest(ctx, client, http.MethodGet,
+		awsMetadataURL+"/latest/meta-data/placement/availability-zone", header)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	return Cluster{Region: region, Zone: zone}, nil
+}
+
+// fetchGCP queries the GCE metadata server, which also reports the GKE cluster name
+func fetchGCP(ctx context.Context, client *http.Client) (Cluster, error) {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+
+	// projects/<number>/zones/<zone>
+	zone, err := metadataRequest(ctx, client, http.MethodGet,
+		gcpMetadataURL+"/computeMetadata/v1/instance/zone", header)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	zone = zone[strings.LastIndex(zone, "/")+1:]
+
+	cluster := Cluster{Zone: zone}
+
+	// The region is the zone without its suffix (us-central1-a -> us-central1)
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		cluster.Region = zone[:i]
+	}
+
+	// Only set on GKE nodes
+	if name, err := metadataRequest(ctx, client, http.MethodGet,
+		gcpMetadataURL+"/computeMetadata/v1/instance/attributes/cluster-name", header); err == nil {
+		cluster.Name = name
+	}
+
+	return cluster, nil
+}
+
+// fetchAlibaba queries the Alibaba Cloud ECS metadata service
+func fetchAlibaba(ctx context.Context, client *http.Client) (Cluster, error) {
+	region, err := metadataRequest(ctx, client, http.MethodGet,
+		alibabaMetadataURL+"/latest/meta-data/region-id", nil)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	zone, err := metadataRequest(ctx, client, http.MethodGet,
+		alibabaMetadataURL+"/latest/meta-data/zone-id", nil)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	return Cluster{Region: region, Zone: zone}, nil
+}
+
+// metadataRequest sends a metadata service request and returns the trimmed response body
+func metadataRequest(
+	ctx context.Context,
+	client *http.Client,
+	method, url string,
+	header map[string]string,
+) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s returned status %d", method, url, resp.StatusCode)
+	}
+
+	value := strings.TrimSpace(string(body))
+	if value == "" {
+		return "", fmt.Errorf("%s %s returned an empty response", method, url)
+	}
+
+	return value, nil
+}
+
+// NewClusterInfoCollector returns a collector exposing the cluster identity
+// as the <namespace>_cluster_info metric
+func NewClusterInfoCollector(namespace string, cluster Cluster) prometheus.Collector {
+	info := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cluster",
+		Name:      "info",
+		Help:      "Identity of the cluster the exporter runs in (empty labels were not determined)",
+		ConstLabels: prometheus.Labels{
+			"cluster": cluster.Name,
+			"region":  cluster.Region,
+			"zone":    cluster.Zone,
+		},
+	})
+	info.Set(1)
+
+	return info
+}
@@ -0,0 +1,154 @@
+package identity //nolint:testpackage // Need to override the unexported metadata endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveClusterPrecedence(t *testing.T) {
+	client := fake.NewClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				corev1.LabelTopologyRegion: "cn-beijing",
+				corev1.LabelTopologyZone:   "cn-beijing-a",
+				"sealos.io/cluster":        "from-node",
+			},
+		},
+	})
+
+	cluster := ResolveCluster(context.Background(), ClusterOptions{
+		Name:       "configured",
+		NodeLabels: true,
+		NodeName:   "node-1",
+		NameLabel:  "sealos.io/cluster",
+		Client:     client,
+	})
+
+	want := Cluster{Name: "configured", Region: "cn-beijing", Zone: "cn-beijing-a"}
+	if cluster != want {
+		t.Errorf("Expected %+v, got %+v", want, cluster)
+	}
+}
+
+func TestResolveClusterBetaNodeLabels(t *testing.T) {
+	client := fake.NewClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				corev1.LabelFailureDomainBetaRegion: "us-east-1",
+				corev1.LabelFailureDomainBetaZone:   "us-east-1b",
+			},
+		},
+	})
+
+	// Any node is used when the node name is unknown
+	cluster := ResolveCluster(context.Background(), ClusterOptions{NodeLabels: true, Client: client})
+
+	want := Cluster{Region: "us-east-1", Zone: "us-east-1b"}
+	if cluster != want {
+		t.Errorf("Expected %+v, got %+v", want, cluster)
+	}
+}
+
+// setMetadataURLs points the cloud metadata endpoints to the given servers
+func setMetadataURLs(t *testing.T, aws, gcp, alibaba string) {
+	t.Helper()
+
+	oldAWS, oldGCP, oldAlibaba := awsMetadataURL, gcpMetadataURL, alibabaMetadataURL
+	awsMetadataURL, gcpMetadataURL, alibabaMetadataURL = aws, gcp, alibaba
+
+	t.Cleanup(func() {
+		awsMetadataURL, gcpMetadataURL, alibabaMetadataURL = oldAWS, oldGCP, oldAlibaba
+	})
+}
+
+func TestResolveClusterCloudMetadata(t *testing.T) {
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	gcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/zone":
+			_, _ = w.Write([]byte("projects/123/zones/us-central1-a"))
+		case "/computeMetadata/v1/instance/attributes/cluster-name":
+			_, _ = w.Write([]byte("gke-prod"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer gcp.Close()
+
+	setMetadataURLs(t, notFound.URL, gcp.URL, notFound.URL)
+
+	cluster := ResolveCluster(context.Background(), ClusterOptions{
+		Zone:          "configured-zone",
+		CloudMetadata: true,
+	})
+
+	want := Cluster{Name: "gke-prod", Region: "us-central1", Zone: "configured-zone"}
+	if cluster != want {
+		t.Errorf("Expected %+v, got %+v", want, cluster)
+	}
+}
+
+func TestResolveClusterAWSMetadata(t *testing.T) {
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	aws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			if r.Method != http.MethodPut {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			_, _ = w.Write([]byte("token"))
+
+			return
+		}
+
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/latest/meta-data/placement/region":
+			_, _ = w.Write([]byte("eu-west-1\n"))
+		case "/latest/meta-data/placement/availability-zone":
+			_, _ = w.Write([]byte("eu-west-1c\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer aws.Close()
+
+	setMetadataURLs(t, aws.URL, notFound.URL, notFound.URL)
+
+	cluster := ResolveCluster(context.Background(), ClusterOptions{CloudMetadata: true})
+
+	want := Cluster{Region: "eu-west-1", Zone: "eu-west-1c"}
+	if cluster != want {
+		t.Errorf("Expected %+v, got %+v", want, cluster)
+	}
+}
+
+func TestClusterLabels(t *testing.T) {
+	labels := Cluster{Name: "prod", Zone: "a"}.Labels()
+
+	if len(labels) != 2 || labels[ClusterLabel] != "prod" || labels[ZoneLabel] != "a" {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+}
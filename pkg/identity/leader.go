@@ -0,0 +1,158 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Leader election identity strategies
+const (
+	// StrategyAuto uses the configured identity, else the pod name, else the
+	// auto-detected identity with a random per-process suffix
+	StrategyAuto = "auto"
+	// StrategyPodUID uses the pod name and UID, unique across replicas and
+	// stable across container restarts
+	StrategyPodUID = "podUID"
+	// StrategyFile uses an identifier stored in a file, created on first use,
+	// stable across pod restarts when the file is on a per-node host path
+	StrategyFile = "file"
+)
+
+var (
+	suffixOnce sync.Once
+	suffix     string
+)
+
+// LeaderOptions configures how the leader election identity is determined
+type LeaderOptions struct {
+	// Strategy is one of StrategyAuto (default), StrategyPodUID or StrategyFile
+	Strategy string
+	// Identity is the explicitly configured identity, used as is by StrategyAuto
+	Identity string
+	NodeName string
+	PodName  string
+	// PodUID is required by StrategyPodUID
+	PodUID string
+	// File is required by StrategyFile
+	File string
+}
+
+// LeaderIdentity returns the identity of this instance in the leader
+// election. Replicas sharing an identity would all hold the lease, so
+// identities that may be shared (node name, IP or hostname of host network
+// pods) get a random suffix, kept for the lifetime of the process.
+func LeaderIdentity(opts LeaderOptions) (string, error) {
+	switch opts.Strategy {
+	case "", StrategyAuto:
+		if opts.Identity != "" {
+			return opts.Identity, nil
+		}
+
+		if opts.PodName != "" {
+			return opts.PodName, nil
+		}
+
+		base := opts.NodeName
+		if base == "" {
+			base = Get()
+		}
+
+		return base + "_" + processSuffix(), nil
+	case StrategyPodUID:
+		if opts.PodUID == "" {
+			return "", errors.New("the podUID identity strategy requires POD_UID to be set")
+		}
+
+		name := opts.PodName
+		if name == "" {
+			name = Get()
+		}
+
+		return name + "_" + opts.PodUID, nil
+	case StrategyFile:
+		if opts.File == "" {
+			return "", errors.New("the file identity strategy requires an identity file")
+		}
+
+		return fileIdentity(opts.File)
+	default:
+		return "", fmt.Errorf("unknown identity strategy %q", opts.Strategy)
+	}
+}
+
+// Hash returns a short, stable hash of an identity, exposed in metrics
+// instead of identities that may contain pod names or IPs
+func Hash(identity string) string {
+	if identity == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(identity))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// processSuffix returns the random suffix of this process
+func processSuffix() string {
+	suffixOnce.Do(func() {
+		suffix = strings.TrimPrefix(generateRandomID(), "instance-")
+	})
+
+	return suffix
+}
+
+// fileIdentity reads the identity stored in a file, creating the file with a
+// random identifier when it does not exist yet
+func fileIdentity(path string) (string, error) {
+	if id, err := readIdentityFile(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return id, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create identity file directory: %w", err)
+	}
+
+	// Written to a temporary file then linked, so instances creating the file
+	// concurrently never read a partial identifier: the first link wins
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".identity-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create identity file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(generateRandomID() + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to write identity file: %w", err)
+	}
+
+	if err := os.Link(tmp.Name(), path); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("failed to create identity file: %w", err)
+	}
+
+	return readIdentityFile(path)
+}
+
+// readIdentityFile returns the identity stored in a file
+func readIdentityFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	id := strings.TrimSpace(string(data))
+	if id == "" {
+		return "", fmt.Errorf("identity file %s is empty", path)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,93 @@
+package identity //nolint:testpackage // Need to check the unexported process suffix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLeaderIdentityAuto(t *testing.T) {
+	id, err := LeaderIdentity(LeaderOptions{Identity: "explicit", PodName: "pod-0"})
+	if err != nil || id != "explicit" {
+		t.Errorf("Expected the configured identity, got %q (%v)", id, err)
+	}
+
+	// The pod name is unique among replicas, unlike the node name
+	id, err = LeaderIdentity(LeaderOptions{Strategy: StrategyAuto, NodeName: "node-1", PodName: "pod-0"})
+	if err != nil || id != "pod-0" {
+		t.Errorf("Expected the pod name, got %q (%v)", id, err)
+	}
+
+	// Without a pod name, replicas on one node must not share the node name
+	id, err = LeaderIdentity(LeaderOptions{NodeName: "node-1"})
+	if err != nil || id != "node-1_"+processSuffix() {
+		t.Errorf("Expected the node name with the process suffix, got %q (%v)", id, err)
+	}
+
+	again, _ := LeaderIdentity(LeaderOptions{NodeName: "node-1"})
+	if again != id {
+		t.Errorf("Expected the suffix to be stable within the process, got %q then %q", id, again)
+	}
+}
+
+func TestLeaderIdentityPodUID(t *testing.T) {
+	id, err := LeaderIdentity(LeaderOptions{Strategy: StrategyPodUID, PodName: "pod-0", PodUID: "0a1b-2c3d"})
+	if err != nil || id != "pod-0_0a1b-2c3d" {
+		t.Errorf("Expected the pod name and UID, got %q (%v)", id, err)
+	}
+
+	if _, err := LeaderIdentity(LeaderOptions{Strategy: StrategyPodUID, PodName: "pod-0"}); err == nil {
+		t.Error("Expected an error without pod UID")
+	}
+}
+
+func TestLeaderIdentityFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "identity")
+
+	id, err := LeaderIdentity(LeaderOptions{Strategy: StrategyFile, File: path})
+	if err != nil {
+		t.Fatalf("Failed to create identity file: %v", err)
+	}
+
+	if !strings.HasPrefix(id, "instance-") {
+		t.Errorf("Expected a generated identity, got %q", id)
+	}
+
+	again, err := LeaderIdentity(LeaderOptions{Strategy: StrategyFile, File: path})
+	if err != nil || again != id {
+		t.Errorf("Expected the stored identity %q, got %q (%v)", id, again, err)
+	}
+
+	if err := os.WriteFile(path, []byte("  node-1-id\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if id, _ := LeaderIdentity(LeaderOptions{Strategy: StrategyFile, File: path}); id != "node-1-id" {
+		t.Errorf("Expected the trimmed identity of the file, got %q", id)
+	}
+
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LeaderIdentity(LeaderOptions{Strategy: StrategyFile, File: path}); err == nil {
+		t.Error("Expected an error for an empty identity file")
+	}
+}
+
+func TestLeaderIdentityUnknownStrategy(t *testing.T) {
+	if _, err := LeaderIdentity(LeaderOptions{Strategy: "hostname"}); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("") != "" {
+		t.Error("Expected an empty hash for an empty identity")
+	}
+
+	if h := Hash("pod-0"); len(h) != 12 || h != Hash("pod-0") || h == Hash("pod-1") {
+		t.Errorf("Expected a stable 12 character hash, got %q", h)
+	}
+}
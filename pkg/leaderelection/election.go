@@ -25,6 +25,9 @@ type Config struct {
 	// Identity is the unique identifier for this instance (typically pod name)
 	Identity string
 
+	// IdentityStrategy is the strategy the identity was determined with, for logs and metrics
+	IdentityStrategy string
+
 	// LeaseDuration is the duration that non-leader candidates will wait to force acquire leadership
 	LeaseDuration time.Duration
 
@@ -76,6 +79,7 @@ func NewLeaderElector(
 		"namespace": cfg.Namespace,
 		"leaseName": cfg.LeaseName,
 		"identity":  cfg.Identity,
+		"strategy":  cfg.IdentityStrategy,
 	}).Info("Creating leader elector")
 
 	return &LeaderElector{
@@ -98,6 +102,8 @@ func (le *LeaderElector) SetCallbacks(
 
 // Run starts the leader election process and blocks until context is cancelled
 func (le *LeaderElector) Run(ctx context.Context) error {
+	le.warnDuplicateIdentity(ctx)
+
 	// Create the resource lock
 	lock := &resourcelock.LeaseLock{
 		LeaseMeta: metav1.ObjectMeta{
@@ -171,6 +177,29 @@ func (le *LeaderElector) Run(ctx context.Context) error {
 	return nil
 }
 
+// warnDuplicateIdentity warns when the lease is already held by the identity
+// of this instance before it runs: either this instance restarted without
+// releasing the lease, or another replica shares its identity and both would
+// hold the lease
+func (le *LeaderElector) warnDuplicateIdentity(ctx context.Context) {
+	lease, err := le.client.CoordinationV1().Leases(le.config.Namespace).
+		Get(ctx, le.config.LeaseName, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity != le.config.Identity || spec.RenewTime == nil {
+		return
+	}
+
+	if time.Since(spec.RenewTime.Time) < le.config.LeaseDuration {
+		le.logger.WithField("identity", le.config.Identity).Warn(
+			"Lease is already held by this identity: if this instance did not just restart, another replica " +
+				"shares its identity; set POD_NAME or leaderElection.identityStrategy to avoid split brain")
+	}
+}
+
 // IsLeader returns true if this instance is currently the leader
 func (le *LeaderElector) IsLeader() bool {
 	return le.isLeader.Load()
@@ -0,0 +1,310 @@
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ShardedElector spreads leadership across replicas with one lease per shard
+// (e.g. per collector) instead of a single lease for all of them. Every
+// replica competes for the lease of each shard and runs the shards it holds.
+// A replica holding the maximum number of leases stops competing for the
+// others, so heavy shards end up on different replicas.
+type ShardedElector struct {
+	config    *Config
+	client    kubernetes.Interface
+	maxLeases int
+	logger    *log.Entry
+
+	mu      sync.Mutex
+	names   []string                  // shards competed for
+	shards  map[string]*LeaderElector // key: shard name
+	leading map[string]bool           // key: shard name
+
+	// Callbacks
+	onStartedLeading func(ctx context.Context, shard string)
+	onStoppedLeading func(shard string)
+}
+
+// NewShardedElector creates a ShardedElector. The lease of each shard is named
+// after cfg.LeaseName and the shard (see ShardLeaseName). maxLeases bounds the
+// number of leases held by this replica (0 = unbounded).
+func NewShardedElector(
+	cfg *Config,
+	client kubernetes.Interface,
+	maxLeases int,
+	logger *log.Entry,
+) (*ShardedElector, error) {
+	if cfg == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+
+	if cfg.Identity == "" {
+		return nil, errors.New("identity cannot be empty")
+	}
+
+	if client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+
+	if maxLeases < 0 {
+		return nil, errors.New("max leases cannot be negative")
+	}
+
+	if logger == nil {
+		logger = log.WithField("component", "leader-election")
+	}
+
+	return &ShardedElector{
+		config:    cfg,
+		client:    client,
+		maxLeases: maxLeases,
+		logger:    logger,
+		shards:    make(map[string]*LeaderElector),
+		leading:   make(map[string]bool),
+	}, nil
+}
+
+// SetCallbacks sets the callback functions called when this replica starts
+// and stops leading a shard. onStartedLeading receives a context cancelled
+// when the lease of the shard is lost.
+func (se *ShardedElector) SetCallbacks(
+	onStartedLeading func(ctx context.Context, shard string),
+	onStoppedLeading func(shard string),
+) {
+	se.onStartedLeading = onStartedLeading
+	se.onStoppedLeading = onStoppedLeading
+}
+
+// leaseNameInvalid matches the characters not allowed in lease names
+var leaseNameInvalid = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// ShardLeaseName returns the name of the lease of a shard, e.g.
+// sealos-state-metric-domain for the domain collector, or
+// sealos-state-metric-domain-internal for its internal instance
+func ShardLeaseName(prefix, shard string) string {
+	name := leaseNameInvalid.ReplaceAllString(strings.ToLower(shard), "-")
+	return prefix + "-" + strings.Trim(name, "-.")
+}
+
+// Run competes for the lease of each shard and blocks until ctx is
+// cancelled. The leases held are released on cancellation.
+func (se *ShardedElector) Run(ctx context.Context, shards []string) error {
+	if len(shards) == 0 {
+		se.logger.Info("No shard to lead")
+		<-ctx.Done()
+
+		return nil
+	}
+
+	se.logger.WithFields(log.Fields{
+		"shards":    shards,
+		"maxLeases": se.maxLeases,
+		"identity":  se.config.Identity,
+	}).Info("Starting sharded leader election")
+
+	se.mu.Lock()
+	se.names = slices.Clone(shards)
+	se.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	for _, shard := range shards {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			se.runShard(ctx, shard)
+		}()
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// runShard competes for the lease of a shard until ctx is cancelled,
+// standing back while this replica holds the maximum number of leases
+func (se *ShardedElector) runShard(ctx context.Context, shard string) {
+	cfg := *se.config
+	cfg.LeaseName = ShardLeaseName(se.config.LeaseName, shard)
+
+	logger := se.logger.WithField("shard", shard)
+
+	for ctx.Err() == nil {
+		if !se.canCompete(shard) {
+			if !sleep(ctx, se.config.RetryPeriod) {
+				return
+			}
+
+			continue
+		}
+
+		elector, err := NewLeaderElector(&cfg, se.client, logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to create shard leader elector")
+			return
+		}
+
+		candidateCtx, cancel := context.WithCancel(ctx)
+
+		elector.SetCallbacks(
+			func(ctx context.Context) {
+				// Shards acquired concurrently may exceed the maximum, the
+				// extra leases are released right away
+				if !se.acquire(shard) {
+					logger.Info("Holding the maximum number of leases, releasing the lease")
+					cancel()
+
+					return
+				}
+
+				if se.onStartedLeading != nil {
+					se.onStartedLeading(ctx, shard)
+				}
+			},
+			func() {
+				// Also called when the candidacy ends without leading
+				if !se.release(shard) {
+					return
+				}
+
+				if se.onStoppedLeading != nil {
+					se.onStoppedLeading(shard)
+				}
+			},
+			nil,
+		)
+
+		se.mu.Lock()
+		se.shards[shard] = elector
+		se.mu.Unlock()
+
+		go se.standBack(candidateCtx, cancel, shard, elector)
+
+		if err := elector.Run(candidateCtx); err != nil {
+			logger.WithError(err).Error("Shard leader election exited with error")
+		}
+
+		cancel()
+	}
+}
+
+// standBack ends the candidacy of a shard once this replica holds the
+// maximum number of leases, unless it leads the shard
+func (se *ShardedElector) standBack(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	shard string,
+	elector *LeaderElector,
+) {
+	if se.maxLeases == 0 {
+		return
+	}
+
+	for sleep(ctx, se.config.RetryPeriod) {
+		if !elector.IsLeader() && !se.canCompete(shard) {
+			se.logger.WithField("shard", shard).Debug("Holding the maximum number of leases, standing back")
+			cancel()
+
+			return
+		}
+	}
+}
+
+// canCompete returns whether this replica may compete for the lease of a shard
+func (se *ShardedElector) canCompete(shard string) bool {
+	if se.maxLeases == 0 {
+		return true
+	}
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	return se.leading[shard] || len(se.leading) < se.maxLeases
+}
+
+// acquire records that this replica leads a shard, unless it already holds
+// the maximum number of leases
+func (se *ShardedElector) acquire(shard string) bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	if se.maxLeases > 0 && !se.leading[shard] && len(se.leading) >= se.maxLeases {
+		return false
+	}
+
+	se.leading[shard] = true
+
+	return true
+}
+
+// release records that this replica stopped leading a shard and returns
+// whether it led it
+func (se *ShardedElector) release(shard string) bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	leading := se.leading[shard]
+	delete(se.leading, shard)
+
+	return leading
+}
+
+// IsLeader returns whether this replica currently leads a shard
+func (se *ShardedElector) IsLeader(shard string) bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	return se.leading[shard]
+}
+
+// Leaders returns the identity of the current leader of each shard whose
+// leader is known
+func (se *ShardedElector) Leaders() map[string]string {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	leaders := make(map[string]string, len(se.shards))
+	for shard, elector := range se.shards {
+		if leader := elector.GetLeader(); leader != "" {
+			leaders[shard] = leader
+		}
+	}
+
+	return leaders
+}
+
+// Shards returns the shards competed for
+func (se *ShardedElector) Shards() []string {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	return slices.Clone(se.names)
+}
+
+// GetIdentity returns the identity of this replica
+func (se *ShardedElector) GetIdentity() string {
+	return se.config.Identity
+}
+
+// sleep waits for d and returns false when ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
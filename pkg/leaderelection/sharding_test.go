@@ -0,0 +1,117 @@
+//nolint:testpackage // Tests need access to private functions
+package leaderelection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestShardLeaseName(t *testing.T) {
+	tests := map[string]string{
+		"domain":          "sealos-state-metric-domain",
+		"domain:internal": "sealos-state-metric-domain-internal",
+		"Dynamic_CRD":     "sealos-state-metric-dynamic-crd",
+	}
+
+	for shard, expected := range tests {
+		if got := ShardLeaseName("sealos-state-metric", shard); got != expected {
+			t.Errorf("ShardLeaseName(%q) = %q, expected %q", shard, got, expected)
+		}
+	}
+}
+
+func TestShardedElectorSpreadsShards(t *testing.T) {
+	client := fake.NewClientset()
+	shards := []string{"domain", "cert"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		started = make(map[string]string) // key: shard, value: identity
+	)
+
+	electors := make([]*ShardedElector, 0, 2)
+
+	for _, identity := range []string{"replica-a", "replica-b"} {
+		se, err := NewShardedElector(&Config{
+			Namespace:     "default",
+			LeaseName:     "sealos-state-metric",
+			Identity:      identity,
+			LeaseDuration: time.Second,
+			RenewDeadline: 500 * time.Millisecond,
+			RetryPeriod:   100 * time.Millisecond,
+		}, client, 1, log.NewEntry(log.StandardLogger()))
+		if err != nil {
+			t.Fatalf("Failed to create sharded elector: %v", err)
+		}
+
+		se.SetCallbacks(
+			func(_ context.Context, shard string) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				started[shard] = identity
+			},
+			func(shard string) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				delete(started, shard)
+			},
+		)
+
+		electors = append(electors, se)
+
+		go func() {
+			_ = se.Run(ctx, shards)
+		}()
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		spread := len(started) == 2 && started["domain"] != started["cert"]
+		mu.Unlock()
+
+		if spread {
+			break
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(started) != 2 || started["domain"] == started["cert"] {
+		t.Fatalf("Expected each replica to lead one shard, got %v", started)
+	}
+
+	for _, se := range electors {
+		leading := 0
+
+		for _, shard := range shards {
+			if se.IsLeader(shard) {
+				leading++
+			}
+		}
+
+		if leading != 1 {
+			t.Errorf("Expected %s to lead one shard, leads %d", se.GetIdentity(), leading)
+		}
+
+		// A replica standing back stops observing the other leases
+		for shard, leader := range se.Leaders() {
+			if se.IsLeader(shard) && leader != se.GetIdentity() {
+				t.Errorf("Expected %s to lead %s, lease held by %s", se.GetIdentity(), shard, leader)
+			}
+		}
+	}
+}
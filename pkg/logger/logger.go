@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	stdlog "log"
 	"os"
 	"strings"
@@ -14,6 +15,7 @@ type Options struct {
 	Debug  bool
 	Level  string
 	Format string
+	Output io.Writer
 }
 
 // Option is a function that configures Options
@@ -40,6 +42,13 @@ func WithFormat(format string) Option {
 	}
 }
 
+// WithOutput sets the log destination (defaults to stdout)
+func WithOutput(w io.Writer) Option {
+	return func(o *Options) {
+		o.Output = w
+	}
+}
+
 // InitLog initializes the logger with the given options
 func InitLog(opts ...Option) {
 	// Default options
@@ -47,6 +56,7 @@ func InitLog(opts ...Option) {
 		Debug:  false,
 		Level:  "info",
 		Format: "text",
+		Output: os.Stdout,
 	}
 
 	// Apply provided options
@@ -78,7 +88,7 @@ func InitLog(opts ...Option) {
 		l.SetReportCaller(false)
 	}
 
-	l.SetOutput(os.Stdout)
+	l.SetOutput(options.Output)
 	stdlog.SetOutput(l.Writer())
 
 	// Set formatter based on configuration
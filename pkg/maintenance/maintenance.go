@@ -0,0 +1,220 @@
+// Package maintenance silences collector metrics of namespaces and domains
+// during planned maintenance windows (e.g. Sealos upgrades), so alert
+// pipelines are not flooded with expected failures
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// ModeLabel adds a silenced="<window>" label to matching series
+	ModeLabel = "label"
+	// ModeSuppress drops matching series
+	ModeSuppress = "suppress"
+
+	// SilencedLabel is the label added to series silenced in ModeLabel
+	SilencedLabel = "silenced"
+)
+
+// Config contains the maintenance windows, loaded from the "maintenance" config section
+type Config struct {
+	Windows []WindowConfig `yaml:"windows"`
+}
+
+// WindowConfig describes one maintenance window
+type WindowConfig struct {
+	// Name identifies the window and is the value of the silenced label
+	Name string `yaml:"name"`
+	// Start and End of the window in RFC 3339 format
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Namespaces matched against the namespace label (glob patterns, e.g. ns-*)
+	Namespaces []string `yaml:"namespaces"`
+	// Domains matched against the domain label (glob patterns, e.g. *.cloud.sealos.io)
+	Domains []string `yaml:"domains"`
+	// Metrics restricts the window to these metric names (glob patterns, empty = all)
+	Metrics []string `yaml:"metrics"`
+	// Mode is "label" (default) or "suppress"
+	Mode string `yaml:"mode"`
+}
+
+// Window is a validated maintenance window
+type Window struct {
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Namespaces []string
+	Domains    []string
+	Metrics    []string
+	Mode       string
+}
+
+// Schedule holds the configured maintenance windows
+type Schedule struct {
+	windows []Window
+}
+
+// NewSchedule validates the configured windows and returns a schedule.
+// Invalid windows are skipped and reported in the returned error.
+func NewSchedule(cfg *Config) (*Schedule, error) {
+	schedule := &Schedule{}
+
+	var errs []error
+
+	names := make(map[string]struct{}, len(cfg.Windows))
+
+	for i := range cfg.Windows {
+		window, err := newWindow(&cfg.Windows[i])
+		if err == nil {
+			if _, ok := names[window.Name]; ok {
+				err = errors.New("duplicate name")
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("window %d (%s): %w", i, cfg.Windows[i].Name, err))
+			continue
+		}
+
+		names[window.Name] = struct{}{}
+		schedule.windows = append(schedule.windows, window)
+	}
+
+	return schedule, errors.Join(errs...)
+}
+
+// newWindow validates a window config
+func newWindow(cfg *WindowConfig) (Window, error) {
+	if cfg.Name == "" {
+		return Window{}, errors.New("name is required")
+	}
+
+	start, err := time.Parse(time.RFC3339, cfg.Start)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid start: %w", err)
+	}
+
+	end, err := time.Parse(time.RFC3339, cfg.End)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid end: %w", err)
+	}
+
+	if !end.After(start) {
+		return Window{}, errors.New("end must be after start")
+	}
+
+	if len(cfg.Namespaces) == 0 && len(cfg.Domains) == 0 {
+		return Window{}, errors.New("at least one namespace or domain is required")
+	}
+
+	for _, pattern := range slices.Concat(cfg.Namespaces, cfg.Domains, cfg.Metrics) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Window{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	mode := cfg.Mode
+	switch mode {
+	case "":
+		mode = ModeLabel
+	case ModeLabel, ModeSuppress:
+	default:
+		return Window{}, fmt.Errorf("invalid mode %q (expected %s or %s)", mode, ModeLabel, ModeSuppress)
+	}
+
+	return Window{
+		Name:       cfg.Name,
+		Start:      start,
+		End:        end,
+		Namespaces: cfg.Namespaces,
+		Domains:    cfg.Domains,
+		Metrics:    cfg.Metrics,
+		Mode:       mode,
+	}, nil
+}
+
+// Windows returns all configured windows
+func (s *Schedule) Windows() []Window {
+	if s == nil {
+		return nil
+	}
+
+	return s.windows
+}
+
+// Active returns the windows active at now
+func (s *Schedule) Active(now time.Time) []Window {
+	if s == nil {
+		return nil
+	}
+
+	var active []Window
+
+	for _, window := range s.windows {
+		if window.IsActive(now) {
+			active = append(active, window)
+		}
+	}
+
+	return active
+}
+
+// IsActive returns whether the window is active at now
+func (w *Window) IsActive(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// Match returns the first of windows matching a series of the named metric
+func Match(windows []Window, metricName string, labels []*dto.LabelPair) (*Window, bool) {
+	if len(windows) == 0 {
+		return nil, false
+	}
+
+	var namespace, domain string
+
+	for _, label := range labels {
+		switch label.GetName() {
+		case "namespace":
+			namespace = label.GetValue()
+		case "domain":
+			domain = label.GetValue()
+		}
+	}
+
+	if namespace == "" && domain == "" {
+		return nil, false
+	}
+
+	for i := range windows {
+		window := &windows[i]
+
+		if len(window.Metrics) > 0 && !matchAny(window.Metrics, metricName) {
+			continue
+		}
+
+		if (namespace != "" && matchAny(window.Namespaces, namespace)) ||
+			(domain != "" && matchAny(window.Domains, domain)) {
+			return window, true
+		}
+	}
+
+	return nil, false
+}
+
+// matchAny returns whether value matches one of the glob patterns
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+//nolint:testpackage // Tests need access to private functions
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func labelPairs(pairs ...string) []*dto.LabelPair {
+	labels := make([]*dto.LabelPair, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		name, value := pairs[i], pairs[i+1]
+		labels = append(labels, &dto.LabelPair{Name: &name, Value: &value})
+	}
+
+	return labels
+}
+
+func TestNewSchedule(t *testing.T) {
+	valid := WindowConfig{
+		Name:       "upgrade",
+		Start:      "2025-01-01T00:00:00Z",
+		End:        "2025-01-01T02:00:00Z",
+		Namespaces: []string{"ns-*"},
+	}
+
+	invalid := []WindowConfig{
+		{Start: valid.Start, End: valid.End, Namespaces: valid.Namespaces},
+		{Name: "bad-start", Start: "tomorrow", End: valid.End, Namespaces: valid.Namespaces},
+		{Name: "reversed", Start: valid.End, End: valid.Start, Namespaces: valid.Namespaces},
+		{Name: "no-target", Start: valid.Start, End: valid.End},
+		{Name: "bad-mode", Start: valid.Start, End: valid.End, Namespaces: valid.Namespaces, Mode: "drop"},
+		{Name: "bad-pattern", Start: valid.Start, End: valid.End, Domains: []string{"[a-"}},
+		valid,
+	}
+
+	schedule, err := NewSchedule(&Config{Windows: append([]WindowConfig{valid}, invalid...)})
+	if err == nil {
+		t.Error("Expected error for invalid windows")
+	}
+
+	windows := schedule.Windows()
+	if len(windows) != 1 {
+		t.Fatalf("Expected only the first valid window, got %d", len(windows))
+	}
+
+	if windows[0].Mode != ModeLabel {
+		t.Errorf("Expected default mode %q, got %q", ModeLabel, windows[0].Mode)
+	}
+
+	start := windows[0].Start
+	if len(schedule.Active(start.Add(-time.Second))) != 0 ||
+		len(schedule.Active(start)) != 1 ||
+		len(schedule.Active(windows[0].End)) != 0 {
+		t.Error("Expected window to be active from start (inclusive) to end (exclusive)")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	windows := []Window{
+		{Name: "domains", Domains: []string{"*.cloud.sealos.io"}, Metrics: []string{"sealos_domain_*"}},
+		{Name: "tenants", Namespaces: []string{"ns-*"}},
+	}
+
+	tests := []struct {
+		name   string
+		metric string
+		labels []*dto.LabelPair
+		want   string
+	}{
+		{"namespace", "sealos_pod_abnormal", labelPairs("namespace", "ns-user1"), "tenants"},
+		{"domain", "sealos_domain_health", labelPairs("domain", "app.cloud.sealos.io"), "domains"},
+		{"metric filter", "sealos_cert_expiry", labelPairs("domain", "app.cloud.sealos.io"), ""},
+		{"other namespace", "sealos_pod_abnormal", labelPairs("namespace", "kube-system"), ""},
+		{"no labels", "sealos_node_ready", labelPairs("node", "n1"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, ok := Match(windows, tt.metric, tt.labels)
+
+			got := ""
+			if ok {
+				got = window.Name
+			}
+
+			if got != tt.want {
+				t.Errorf("Match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
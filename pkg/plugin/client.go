@@ -0,0 +1,111 @@
+package plugin
+
+//go:generate sh -c "protoc -I . -I $(go list -m -f '{{.Dir}}' github.com/prometheus/client_model) --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative plugin.proto"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// unixPrefix prefixes the addresses of plugins listening on a unix socket
+const unixPrefix = "unix://"
+
+// Client calls a CollectorPlugin
+type Client struct {
+	conn   *grpc.ClientConn
+	plugin CollectorPluginClient
+}
+
+// NewClient creates a client of the plugin listening on address, either
+// host:port or unix:///path/to/socket. The connection is established on the
+// first call and re-established when lost.
+func NewClient(address string) (*Client, error) {
+	if address == "" {
+		return nil, errors.New("plugin address is required")
+	}
+
+	target := "dns:///" + address
+
+	if path, ok := strings.CutPrefix(address, unixPrefix); ok {
+		if path == "" {
+			return nil, fmt.Errorf("invalid plugin address %q: missing socket path", address)
+		}
+
+		target = address
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin address %q: %w", address, err)
+	}
+
+	return &Client{
+		conn:   conn,
+		plugin: NewCollectorPluginClient(conn),
+	}, nil
+}
+
+// Describe returns the metric families the plugin may collect
+func (c *Client) Describe(ctx context.Context) ([]*dto.MetricFamily, error) {
+	stream, err := c.plugin.Describe(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return receiveFamilies(stream)
+}
+
+// Collect returns the current metric families of the plugin
+func (c *Client) Collect(ctx context.Context) ([]*dto.MetricFamily, error) {
+	stream, err := c.plugin.Collect(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return receiveFamilies(stream)
+}
+
+// Health returns the error reported by the plugin, nil when it is healthy
+func (c *Client) Health(ctx context.Context) error {
+	resp, err := c.plugin.Health(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	if resp.GetError() != "" {
+		return errors.New(resp.GetError())
+	}
+
+	return nil
+}
+
+// Close closes the connection to the plugin
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// receiveFamilies reads a stream of metric families until its end
+func receiveFamilies(stream grpc.ServerStreamingClient[dto.MetricFamily]) ([]*dto.MetricFamily, error) {
+	var families []*dto.MetricFamily
+
+	for {
+		family, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return families, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		families = append(families, family)
+	}
+}
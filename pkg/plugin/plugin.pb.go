@@ -0,0 +1,144 @@
+// CollectorPlugin is the protocol spoken by external collectors. The exporter
+// is the client: it dials the plugin address over gRPC without TLS, on TCP or
+// a unix socket, and exposes the metric families of the plugin as its own.
+//
+// The Go code is generated with protoc-gen-go and protoc-gen-go-grpc, see the
+// go:generate directive of the package.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: plugin.proto
+
+package plugin
+
+import (
+	_go "github.com/prometheus/client_model/go"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HealthResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error describes why the plugin is unhealthy, empty when healthy.
+	Error         string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_plugin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HealthResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_plugin_proto protoreflect.FileDescriptor
+
+const file_plugin_proto_rawDesc = "" +
+	"\n" +
+	"\fplugin.proto\x12\x1dsealos.statemetrics.plugin.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\"io/prometheus/client/metrics.proto\"&\n" +
+	"\x0eHealthResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error2\xf5\x01\n" +
+	"\x0fCollectorPlugin\x12H\n" +
+	"\bDescribe\x12\x16.google.protobuf.Empty\x1a\".io.prometheus.client.MetricFamily0\x01\x12G\n" +
+	"\aCollect\x12\x16.google.protobuf.Empty\x1a\".io.prometheus.client.MetricFamily0\x01\x12O\n" +
+	"\x06Health\x12\x16.google.protobuf.Empty\x1a-.sealos.statemetrics.plugin.v1.HealthResponseB4Z2github.com/labring/sealos-state-metrics/pkg/pluginb\x06proto3"
+
+var (
+	file_plugin_proto_rawDescOnce sync.Once
+	file_plugin_proto_rawDescData []byte
+)
+
+func file_plugin_proto_rawDescGZIP() []byte {
+	file_plugin_proto_rawDescOnce.Do(func() {
+		file_plugin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_plugin_proto_rawDesc), len(file_plugin_proto_rawDesc)))
+	})
+	return file_plugin_proto_rawDescData
+}
+
+var file_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_plugin_proto_goTypes = []any{
+	(*HealthResponse)(nil),   // 0: sealos.statemetrics.plugin.v1.HealthResponse
+	(*emptypb.Empty)(nil),    // 1: google.protobuf.Empty
+	(*_go.MetricFamily)(nil), // 2: io.prometheus.client.MetricFamily
+}
+var file_plugin_proto_depIdxs = []int32{
+	1, // 0: sealos.statemetrics.plugin.v1.CollectorPlugin.Describe:input_type -> google.protobuf.Empty
+	1, // 1: sealos.statemetrics.plugin.v1.CollectorPlugin.Collect:input_type -> google.protobuf.Empty
+	1, // 2: sealos.statemetrics.plugin.v1.CollectorPlugin.Health:input_type -> google.protobuf.Empty
+	2, // 3: sealos.statemetrics.plugin.v1.CollectorPlugin.Describe:output_type -> io.prometheus.client.MetricFamily
+	2, // 4: sealos.statemetrics.plugin.v1.CollectorPlugin.Collect:output_type -> io.prometheus.client.MetricFamily
+	0, // 5: sealos.statemetrics.plugin.v1.CollectorPlugin.Health:output_type -> sealos.statemetrics.plugin.v1.HealthResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_plugin_proto_init() }
+func file_plugin_proto_init() {
+	if File_plugin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_plugin_proto_rawDesc), len(file_plugin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_plugin_proto_goTypes,
+		DependencyIndexes: file_plugin_proto_depIdxs,
+		MessageInfos:      file_plugin_proto_msgTypes,
+	}.Build()
+	File_plugin_proto = out.File
+	file_plugin_proto_goTypes = nil
+	file_plugin_proto_depIdxs = nil
+}
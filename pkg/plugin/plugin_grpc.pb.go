@@ -0,0 +1,227 @@
+// CollectorPlugin is the protocol spoken by external collectors. The exporter
+// is the client: it dials the plugin address over gRPC without TLS, on TCP or
+// a unix socket, and exposes the metric families of the plugin as its own.
+//
+// The Go code is generated with protoc-gen-go and protoc-gen-go-grpc, see the
+// go:generate directive of the package.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: plugin.proto
+
+package plugin
+
+import (
+	context "context"
+	_go "github.com/prometheus/client_model/go"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CollectorPlugin_Describe_FullMethodName = "/sealos.statemetrics.plugin.v1.CollectorPlugin/Describe"
+	CollectorPlugin_Collect_FullMethodName  = "/sealos.statemetrics.plugin.v1.CollectorPlugin/Collect"
+	CollectorPlugin_Health_FullMethodName   = "/sealos.statemetrics.plugin.v1.CollectorPlugin/Health"
+)
+
+// CollectorPluginClient is the client API for CollectorPlugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CollectorPluginClient interface {
+	// Describe streams the metric families the plugin may collect, with their
+	// name, help and type but without metrics. Families collected but not
+	// described are dropped. Called before each Collect.
+	Describe(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[_go.MetricFamily], error)
+	// Collect streams the current metric families. Called at the interval of
+	// the plugin collector instance, only on the leader when the instance
+	// requires leader election.
+	Collect(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[_go.MetricFamily], error)
+	// Health reports whether the plugin is healthy.
+	Health(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type collectorPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCollectorPluginClient(cc grpc.ClientConnInterface) CollectorPluginClient {
+	return &collectorPluginClient{cc}
+}
+
+func (c *collectorPluginClient) Describe(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[_go.MetricFamily], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollectorPlugin_ServiceDesc.Streams[0], CollectorPlugin_Describe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, _go.MetricFamily]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectorPlugin_DescribeClient = grpc.ServerStreamingClient[_go.MetricFamily]
+
+func (c *collectorPluginClient) Collect(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[_go.MetricFamily], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollectorPlugin_ServiceDesc.Streams[1], CollectorPlugin_Collect_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, _go.MetricFamily]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectorPlugin_CollectClient = grpc.ServerStreamingClient[_go.MetricFamily]
+
+func (c *collectorPluginClient) Health(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, CollectorPlugin_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CollectorPluginServer is the server API for CollectorPlugin service.
+// All implementations must embed UnimplementedCollectorPluginServer
+// for forward compatibility.
+type CollectorPluginServer interface {
+	// Describe streams the metric families the plugin may collect, with their
+	// name, help and type but without metrics. Families collected but not
+	// described are dropped. Called before each Collect.
+	Describe(*emptypb.Empty, grpc.ServerStreamingServer[_go.MetricFamily]) error
+	// Collect streams the current metric families. Called at the interval of
+	// the plugin collector instance, only on the leader when the instance
+	// requires leader election.
+	Collect(*emptypb.Empty, grpc.ServerStreamingServer[_go.MetricFamily]) error
+	// Health reports whether the plugin is healthy.
+	Health(context.Context, *emptypb.Empty) (*HealthResponse, error)
+	mustEmbedUnimplementedCollectorPluginServer()
+}
+
+// UnimplementedCollectorPluginServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCollectorPluginServer struct{}
+
+func (UnimplementedCollectorPluginServer) Describe(*emptypb.Empty, grpc.ServerStreamingServer[_go.MetricFamily]) error {
+	return status.Errorf(codes.Unimplemented, "method Describe not implemented")
+}
+func (UnimplementedCollectorPluginServer) Collect(*emptypb.Empty, grpc.ServerStreamingServer[_go.MetricFamily]) error {
+	return status.Errorf(codes.Unimplemented, "method Collect not implemented")
+}
+func (UnimplementedCollectorPluginServer) Health(context.Context, *emptypb.Empty) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedCollectorPluginServer) mustEmbedUnimplementedCollectorPluginServer() {}
+func (UnimplementedCollectorPluginServer) testEmbeddedByValue()                         {}
+
+// UnsafeCollectorPluginServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CollectorPluginServer will
+// result in compilation errors.
+type UnsafeCollectorPluginServer interface {
+	mustEmbedUnimplementedCollectorPluginServer()
+}
+
+func RegisterCollectorPluginServer(s grpc.ServiceRegistrar, srv CollectorPluginServer) {
+	// If the following call pancis, it indicates UnimplementedCollectorPluginServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CollectorPlugin_ServiceDesc, srv)
+}
+
+func _CollectorPlugin_Describe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectorPluginServer).Describe(m, &grpc.GenericServerStream[emptypb.Empty, _go.MetricFamily]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectorPlugin_DescribeServer = grpc.ServerStreamingServer[_go.MetricFamily]
+
+func _CollectorPlugin_Collect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectorPluginServer).Collect(m, &grpc.GenericServerStream[emptypb.Empty, _go.MetricFamily]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectorPlugin_CollectServer = grpc.ServerStreamingServer[_go.MetricFamily]
+
+func _CollectorPlugin_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorPluginServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorPlugin_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorPluginServer).Health(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CollectorPlugin_ServiceDesc is the grpc.ServiceDesc for CollectorPlugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CollectorPlugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sealos.statemetrics.plugin.v1.CollectorPlugin",
+	HandlerType: (*CollectorPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _CollectorPlugin_Health_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Describe",
+			Handler:       _CollectorPlugin_Describe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Collect",
+			Handler:       _CollectorPlugin_Collect_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "plugin.proto",
+}
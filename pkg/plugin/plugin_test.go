@@ -0,0 +1,171 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/plugin"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakePlugin struct {
+	families   []*dto.MetricFamily
+	collectErr error
+	healthErr  error
+}
+
+func (p *fakePlugin) Describe(context.Context) ([]*dto.MetricFamily, error) {
+	described := make([]*dto.MetricFamily, 0, len(p.families))
+	for _, family := range p.families {
+		described = append(described, &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type})
+	}
+
+	return described, nil
+}
+
+func (p *fakePlugin) Collect(context.Context) ([]*dto.MetricFamily, error) {
+	return p.families, p.collectErr
+}
+
+func (p *fakePlugin) Health(context.Context) error {
+	return p.healthErr
+}
+
+// servePlugin serves a plugin with NewServer on a listener of the network
+// and returns the address of the listener
+func servePlugin(t *testing.T, p plugin.Plugin, network, address string) string {
+	t.Helper()
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Skipf("%s listeners are not available: %v", network, err)
+	}
+
+	srv := plugin.NewServer(p)
+
+	go func() { _ = srv.Serve(listener) }()
+
+	t.Cleanup(srv.Stop)
+
+	return listener.Addr().String()
+}
+
+func newClient(t *testing.T, address string) *plugin.Client {
+	t.Helper()
+
+	client, err := plugin.NewClient(address)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func testFamilies() []*dto.MetricFamily {
+	return []*dto.MetricFamily{
+		{
+			Name: proto.String("billing_orders"),
+			Help: proto.String("Pending orders"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{{Name: proto.String("region"), Value: proto.String("hz")}},
+				Gauge: &dto.Gauge{Value: proto.Float64(3)},
+			}},
+		},
+		{
+			Name:   proto.String("billing_payments_total"),
+			Help:   proto.String("Payments"),
+			Type:   dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{Counter: &dto.Counter{Value: proto.Float64(42)}}},
+		},
+	}
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	p := &fakePlugin{families: testFamilies()}
+	client := newClient(t, servePlugin(t, p, "tcp", "127.0.0.1:0"))
+	ctx := context.Background()
+
+	described, err := client.Describe(ctx)
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+
+	if len(described) != 2 || described[0].GetName() != "billing_orders" || len(described[0].GetMetric()) != 0 {
+		t.Errorf("Unexpected described families %v", described)
+	}
+
+	collected, err := client.Collect(ctx)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(collected) != 2 || !proto.Equal(collected[0], p.families[0]) || !proto.Equal(collected[1], p.families[1]) {
+		t.Errorf("Unexpected collected families %v", collected)
+	}
+
+	if err := client.Health(ctx); err != nil {
+		t.Errorf("Health() error = %v", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	p := &fakePlugin{
+		collectErr: errors.New("database\nunavailable: 100%"),
+		healthErr:  errors.New("queue is stuck"),
+	}
+	client := newClient(t, servePlugin(t, p, "tcp", "127.0.0.1:0"))
+	ctx := context.Background()
+
+	_, err := client.Collect(ctx)
+	if st := status.Convert(err); st.Code() != codes.Unknown || st.Message() != "database\nunavailable: 100%" {
+		t.Errorf("Expected Unknown status with the plugin message, got %v", err)
+	}
+
+	if err := client.Health(ctx); err == nil || err.Error() != "queue is stuck" {
+		t.Errorf("Expected plugin health error, got %v", err)
+	}
+}
+
+func TestClientUnixSocket(t *testing.T) {
+	socket := servePlugin(t, &fakePlugin{families: testFamilies()}, "unix", filepath.Join(t.TempDir(), "plugin.sock"))
+
+	collected, err := newClient(t, "unix://"+socket).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if len(collected) != 2 {
+		t.Errorf("Expected 2 families, got %d", len(collected))
+	}
+}
+
+func TestNewClientInvalidAddress(t *testing.T) {
+	for _, address := range []string{"", "unix://"} {
+		if _, err := plugin.NewClient(address); err == nil {
+			t.Errorf("NewClient(%q) expected error", address)
+		}
+	}
+}
+
+func TestClientUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	if err := newClient(t, address).Health(context.Background()); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable with no plugin listening, got %v", err)
+	}
+}
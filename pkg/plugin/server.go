@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"context"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Plugin is implemented by Go collectors served with NewServer or NewService
+type Plugin interface {
+	// Describe returns the metric families the plugin may collect, without metrics
+	Describe(ctx context.Context) ([]*dto.MetricFamily, error)
+	// Collect returns the current metric families
+	Collect(ctx context.Context) ([]*dto.MetricFamily, error)
+	// Health returns an error when the plugin is unhealthy
+	Health(ctx context.Context) error
+}
+
+// NewServer returns a gRPC server serving a plugin, to be started with Serve
+// on a TCP or unix listener
+func NewServer(p Plugin, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	RegisterCollectorPluginServer(srv, NewService(p))
+
+	return srv
+}
+
+// NewService returns the CollectorPlugin service of a plugin, to register it
+// on an existing gRPC server with RegisterCollectorPluginServer
+func NewService(p Plugin) CollectorPluginServer {
+	return &service{plugin: p}
+}
+
+// service adapts a Plugin to the generated CollectorPluginServer. Errors of
+// the plugin are returned to the exporter with the Unknown code, unless they
+// carry a gRPC status.
+type service struct {
+	UnimplementedCollectorPluginServer
+
+	plugin Plugin
+}
+
+func (s *service) Describe(_ *emptypb.Empty, stream grpc.ServerStreamingServer[dto.MetricFamily]) error {
+	families, err := s.plugin.Describe(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return sendFamilies(stream, families)
+}
+
+func (s *service) Collect(_ *emptypb.Empty, stream grpc.ServerStreamingServer[dto.MetricFamily]) error {
+	families, err := s.plugin.Collect(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return sendFamilies(stream, families)
+}
+
+func (s *service) Health(ctx context.Context, _ *emptypb.Empty) (*HealthResponse, error) {
+	resp := &HealthResponse{}
+	if err := s.plugin.Health(ctx); err != nil {
+		resp.Error = err.Error()
+	}
+
+	return resp, nil
+}
+
+// sendFamilies streams metric families, one per message
+func sendFamilies(stream grpc.ServerStreamingServer[dto.MetricFamily], families []*dto.MetricFamily) error {
+	for _, family := range families {
+		if err := stream.Send(family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
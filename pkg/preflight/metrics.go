@@ -0,0 +1,68 @@
+package preflight
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsCollector exposes the results of the preflight report
+type metricsCollector struct {
+	current      func() *Report
+	passed       *prometheus.Desc
+	checkSuccess *prometheus.Desc
+}
+
+// NewMetricsCollector returns a collector exposing whether the preflight
+// checks passed, and the result of each check. current returns the report,
+// nil when the preflight phase is disabled.
+func NewMetricsCollector(namespace string, current func() *Report) prometheus.Collector {
+	return &metricsCollector{
+		current: current,
+		passed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "preflight_passed"),
+			"Whether all startup preflight checks passed (1) or not (0)",
+			nil,
+			nil,
+		),
+		checkSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "preflight_check_success"),
+			"Whether a startup preflight check passed (1) or not (0), per check (kube_api, rbac, dns, endpoint) and target",
+			[]string{"check", "target"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.passed
+	ch <- m.checkSuccess
+}
+
+// Collect implements prometheus.Collector
+func (m *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	report := m.current()
+	if report == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(m.passed, prometheus.GaugeValue, boolValue(report.Passed))
+
+	for _, result := range report.Results {
+		ch <- prometheus.MustNewConstMetric(
+			m.checkSuccess,
+			prometheus.GaugeValue,
+			boolValue(result.OK),
+			result.Check,
+			result.Target,
+		)
+	}
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,391 @@
+// Package preflight verifies at startup that the exporter can reach what its
+// enabled collectors need: the Kubernetes API, the permissions of the
+// collectors, DNS and the cloud APIs they query. Its report tells what to fix
+// instead of collectors half-starting with errors spread across the logs.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Checks run by the preflight phase
+const (
+	CheckKubeAPI  = "kube_api"
+	CheckRBAC     = "rbac"
+	CheckDNS      = "dns"
+	CheckEndpoint = "endpoint"
+)
+
+// Permission is a Kubernetes permission the exporter requires, namespaced
+// when Namespace is set
+type Permission struct {
+	Namespace string
+	Rule      rbacv1.PolicyRule
+}
+
+// Options selects the preflight checks
+type Options struct {
+	// Client returns the Kubernetes client, nil to skip the Kubernetes API
+	// and RBAC checks (standalone mode)
+	Client func() (kubernetes.Interface, error)
+	// Permissions are verified with SelfSubjectAccessReviews
+	Permissions []Permission
+	// Endpoints are the host:port addresses of the external APIs queried by
+	// the collectors, keyed by collector instance. Their hosts are resolved
+	// and a TCP connection is opened to each.
+	Endpoints map[string][]string
+	// DNSNames are resolved in addition to the endpoint hosts
+	DNSNames []string
+	// Timeout bounds each check
+	Timeout time.Duration
+}
+
+// Result is the result of a single check
+type Result struct {
+	Check  string `json:"check"`
+	Target string `json:"target"`
+	// Collectors are the collector instances needing the target, empty when
+	// the target is not specific to collectors
+	Collectors []string `json:"collectors,omitempty"`
+	OK         bool     `json:"ok"`
+	Error      string   `json:"error,omitempty"`
+	// Hint tells how to fix a failed check
+	Hint string `json:"hint,omitempty"`
+}
+
+// Report is the machine-readable result of the preflight phase
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Passed      bool      `json:"passed"`
+	Results     []Result  `json:"results"`
+}
+
+// Run runs the checks selected by opts and returns their report
+func Run(ctx context.Context, opts Options) *Report {
+	report := &Report{GeneratedAt: time.Now()}
+
+	if opts.Client != nil {
+		client, err := opts.Client()
+
+		result := checkKubeAPI(ctx, client, err, opts.Timeout)
+		report.Results = append(report.Results, result)
+
+		// Access reviews cannot succeed without the API server
+		if result.OK {
+			report.Results = append(report.Results, checkPermissions(ctx, client, opts.Permissions, opts.Timeout)...)
+		}
+	}
+
+	report.Results = append(report.Results, checkNetwork(ctx, opts.Endpoints, opts.DNSNames, opts.Timeout)...)
+
+	report.Passed = !slices.ContainsFunc(report.Results, func(r Result) bool { return !r.OK })
+
+	return report
+}
+
+// Failures returns the failed checks
+func (r *Report) Failures() []Result {
+	var failures []Result
+
+	for _, result := range r.Results {
+		if !result.OK {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// Err returns an error listing the failed checks and how to fix them, nil
+// when all checks passed
+func (r *Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(failures))
+	for _, failure := range failures {
+		errs = append(errs, fmt.Errorf("%s %s: %s (%s)", failure.Check, failure.Target, failure.Error, failure.Hint))
+	}
+
+	return fmt.Errorf("%d preflight check(s) failed: %w", len(failures), errors.Join(errs...))
+}
+
+// WriteFile writes the report as JSON to path
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preflight report: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // The report holds no secrets
+		return fmt.Errorf("failed to write preflight report: %w", err)
+	}
+
+	return nil
+}
+
+// checkKubeAPI verifies that the client was created (clientErr is nil) and
+// that the API server answers
+func checkKubeAPI(ctx context.Context, client kubernetes.Interface, clientErr error, timeout time.Duration) Result {
+	result := Result{Check: CheckKubeAPI, Target: "version"}
+
+	err := clientErr
+	if err == nil {
+		// The discovery client has no context, bound it with the timeout
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.Discovery().ServerVersion()
+			done <- err
+		}()
+
+		select {
+		case err = <-done:
+		case <-time.After(timeout):
+			err = fmt.Errorf("no answer within %s", timeout)
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		result.Hint = "check the kubeconfig or the in-cluster service account, and the network path to the API server"
+
+		return result
+	}
+
+	result.OK = true
+
+	return result
+}
+
+// checkPermissions verifies each permission with a SelfSubjectAccessReview
+func checkPermissions(
+	ctx context.Context,
+	client kubernetes.Interface,
+	permissions []Permission,
+	timeout time.Duration,
+) []Result {
+	var results []Result
+
+	// Rules on overlapping API groups may grant the same permission twice
+	seen := make(map[authorizationv1.ResourceAttributes]bool)
+
+	for _, permission := range permissions {
+		for _, attributes := range resourceAttributes(permission) {
+			if seen[attributes] {
+				continue
+			}
+
+			seen[attributes] = true
+			results = append(results, checkAccess(ctx, client, attributes, timeout))
+		}
+	}
+
+	return results
+}
+
+// resourceAttributes expands a permission to the attributes of each group,
+// resource, verb and resource name it grants
+func resourceAttributes(permission Permission) []authorizationv1.ResourceAttributes {
+	names := permission.Rule.ResourceNames
+	if len(names) == 0 {
+		names = []string{""}
+	}
+
+	var attributes []authorizationv1.ResourceAttributes
+
+	for _, group := range permission.Rule.APIGroups {
+		for _, resource := range permission.Rule.Resources {
+			resource, subresource, _ := strings.Cut(resource, "/")
+
+			for _, verb := range permission.Rule.Verbs {
+				for _, name := range names {
+					attributes = append(attributes, authorizationv1.ResourceAttributes{
+						Namespace:   permission.Namespace,
+						Verb:        verb,
+						Group:       group,
+						Resource:    resource,
+						Subresource: subresource,
+						Name:        name,
+					})
+				}
+			}
+		}
+	}
+
+	return attributes
+}
+
+// checkAccess verifies a single permission
+func checkAccess(
+	ctx context.Context,
+	client kubernetes.Interface,
+	attributes authorizationv1.ResourceAttributes,
+	timeout time.Duration,
+) Result {
+	result := Result{Check: CheckRBAC, Target: permissionTarget(attributes)}
+
+	reviewCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(
+		reviewCtx,
+		&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+		},
+		metav1.CreateOptions{},
+	)
+
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		result.Hint = "allow the service account to create selfsubjectaccessreviews (granted to authenticated users by default)"
+	case !review.Status.Allowed:
+		result.Error = "forbidden"
+		if review.Status.Reason != "" {
+			result.Error += ": " + review.Status.Reason
+		}
+
+		result.Hint = "grant the permission to the service account, " +
+			"e.g. with the roles printed by `sealos-state-metric generate rbac -c <config>`"
+	default:
+		result.OK = true
+	}
+
+	return result
+}
+
+// permissionTarget describes a permission as "<verb> <resource>[.<group>][/<subresource>][ <name>][ in <namespace>]"
+func permissionTarget(attributes authorizationv1.ResourceAttributes) string {
+	target := attributes.Verb + " " + attributes.Resource
+	if attributes.Group != "" {
+		target += "." + attributes.Group
+	}
+
+	if attributes.Subresource != "" {
+		target += "/" + attributes.Subresource
+	}
+
+	if attributes.Name != "" {
+		target += " " + attributes.Name
+	}
+
+	if attributes.Namespace != "" {
+		target += " in " + attributes.Namespace
+	}
+
+	return target
+}
+
+// checkNetwork resolves the endpoint hosts and the DNS names, and opens a
+// TCP connection to each endpoint
+func checkNetwork(ctx context.Context, endpoints map[string][]string, dnsNames []string, timeout time.Duration) []Result {
+	// Collectors needing each endpoint and each host
+	endpointCollectors := make(map[string][]string)
+	hostCollectors := make(map[string][]string)
+
+	for _, name := range dnsNames {
+		hostCollectors[name] = nil
+	}
+
+	for collector, addresses := range endpoints {
+		for _, address := range addresses {
+			endpointCollectors[address] = append(endpointCollectors[address], collector)
+
+			if host, _, err := net.SplitHostPort(address); err == nil && net.ParseIP(host) == nil {
+				hostCollectors[host] = append(hostCollectors[host], collector)
+			}
+		}
+	}
+
+	var results []Result
+
+	for _, host := range sortedKeys(hostCollectors) {
+		result := checkDNS(ctx, host, timeout)
+		result.Collectors = sortedUnique(hostCollectors[host])
+		results = append(results, result)
+	}
+
+	for _, address := range sortedKeys(endpointCollectors) {
+		result := checkEndpoint(ctx, address, timeout)
+		result.Collectors = sortedUnique(endpointCollectors[address])
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// checkDNS verifies that host resolves
+func checkDNS(ctx context.Context, host string, timeout time.Duration) Result {
+	result := Result{Check: CheckDNS, Target: host}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if _, err := net.DefaultResolver.LookupHost(lookupCtx, host); err != nil {
+		result.Error = err.Error()
+		result.Hint = "check the DNS configuration of the pod (dnsPolicy) and that network policies allow DNS egress (port 53)"
+
+		return result
+	}
+
+	result.OK = true
+
+	return result
+}
+
+// checkEndpoint verifies that a TCP connection to address can be opened
+func checkEndpoint(ctx context.Context, address string, timeout time.Duration) Result {
+	result := Result{Check: CheckEndpoint, Target: address}
+
+	dialer := net.Dialer{Timeout: timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		result.Error = err.Error()
+		result.Hint = "check that network policies, firewalls or the egress proxy allow connections to " + address
+
+		return result
+	}
+
+	_ = conn.Close()
+	result.OK = true
+
+	return result
+}
+
+// sortedKeys returns the keys of m, sorted
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// sortedUnique returns the sorted distinct values
+func sortedUnique(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+
+	return slices.Compact(values)
+}
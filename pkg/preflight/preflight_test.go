@@ -0,0 +1,166 @@
+package preflight_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/preflight"
+	"github.com/prometheus/client_golang/prometheus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newClient returns a fake client allowing every access except to secrets
+func newClient() kubernetes.Interface {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review, _ := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = review.Spec.ResourceAttributes.Resource != "secrets"
+
+			return true, review, nil
+		})
+
+	return client
+}
+
+// results returns the results keyed by check and target
+func results(report *preflight.Report) map[string]preflight.Result {
+	byTarget := make(map[string]preflight.Result, len(report.Results))
+	for _, result := range report.Results {
+		byTarget[result.Check+" "+result.Target] = result
+	}
+
+	return byTarget
+}
+
+func TestRun(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// A port nothing listens on anymore
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	report := preflight.Run(context.Background(), preflight.Options{
+		Client: func() (kubernetes.Interface, error) { return newClient(), nil },
+		Permissions: []preflight.Permission{
+			{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods", "secrets"}, Verbs: []string{"list"}}},
+			// Granted again by an overlapping rule
+			{Rule: rbacv1.PolicyRule{APIGroups: []string{"", "apps"}, Resources: []string{"pods"}, Verbs: []string{"list"}}},
+			{Namespace: "sealos", Rule: rbacv1.PolicyRule{
+				APIGroups:     []string{"coordination.k8s.io"},
+				Resources:     []string{"leases"},
+				ResourceNames: []string{"exporter"},
+				Verbs:         []string{"get"},
+			}},
+		},
+		Endpoints: map[string][]string{
+			"cloudbalance":       {listener.Addr().String(), closedAddress},
+			"cloudbalance:other": {listener.Addr().String()},
+		},
+		DNSNames: []string{"localhost"},
+		Timeout:  2 * time.Second,
+	})
+
+	if report.Passed {
+		t.Error("Expected the report to fail")
+	}
+
+	got := results(report)
+
+	for key, ok := range map[string]bool{
+		"kube_api version":    true,
+		"rbac list pods":      true,
+		"rbac list pods.apps": true,
+		"rbac list secrets":   false,
+		"rbac get leases.coordination.k8s.io exporter in sealos": true,
+		"dns localhost":                        true,
+		"endpoint " + listener.Addr().String(): true,
+		"endpoint " + closedAddress:            false,
+	} {
+		result, found := got[key]
+		if !found {
+			t.Errorf("Missing result %q", key)
+			continue
+		}
+
+		if result.OK != ok {
+			t.Errorf("Expected %q ok=%v, got %+v", key, ok, result)
+		}
+
+		if !result.OK && result.Hint == "" {
+			t.Errorf("Expected a hint for the failed %q", key)
+		}
+	}
+
+	if len(report.Results) != 8 {
+		t.Errorf("Expected 8 results, got %d: %+v", len(report.Results), report.Results)
+	}
+
+	if collectors := got["endpoint "+listener.Addr().String()].Collectors; len(collectors) != 2 {
+		t.Errorf("Expected the endpoint to list both collectors, got %v", collectors)
+	}
+
+	if err := report.Err(); err == nil {
+		t.Error("Expected an error listing the failed checks")
+	}
+}
+
+func TestRunClientError(t *testing.T) {
+	report := preflight.Run(context.Background(), preflight.Options{
+		Client: func() (kubernetes.Interface, error) { return nil, errors.New("no kubeconfig") },
+		Permissions: []preflight.Permission{
+			{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}}},
+		},
+		Timeout: time.Second,
+	})
+
+	if report.Passed || len(report.Results) != 1 || report.Results[0].Check != preflight.CheckKubeAPI {
+		t.Errorf("Expected only a failed kube_api check, got %+v", report.Results)
+	}
+}
+
+func TestMetricsCollector(t *testing.T) {
+	var report *preflight.Report
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(preflight.NewMetricsCollector("sealos", func() *preflight.Report { return report }))
+
+	if families, err := reg.Gather(); err != nil || len(families) != 0 {
+		t.Fatalf("Expected no metrics without a report, got %v (%v)", families, err)
+	}
+
+	report = preflight.Run(context.Background(), preflight.Options{DNSNames: []string{"localhost"}, Timeout: time.Second})
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			values[family.GetName()] += metric.GetGauge().GetValue()
+		}
+	}
+
+	if values["sealos_state_metric_preflight_passed"] != 1 || values["sealos_state_metric_preflight_check_success"] != 1 {
+		t.Errorf("Unexpected preflight metrics %v", values)
+	}
+}
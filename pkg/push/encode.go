@@ -0,0 +1,197 @@
+package push
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Formats of the pushed samples
+const (
+	// FormatInflux is the Influx line protocol, accepted by the /write
+	// endpoint of InfluxDB and VictoriaMetrics
+	FormatInflux = "influx"
+	// FormatPrometheus is the Prometheus text format, accepted by the
+	// /api/v1/import/prometheus endpoint of VictoriaMetrics
+	FormatPrometheus = "prometheus"
+)
+
+// sample is a single value of a metric family, histograms and summaries being
+// flattened to their _bucket, _sum and _count series like in the text format
+type sample struct {
+	name   string
+	labels []*dto.LabelPair
+	value  float64
+}
+
+// flatten returns the samples of families
+func flatten(families []*dto.MetricFamily) []sample {
+	var samples []sample
+
+	for _, family := range families {
+		name := family.GetName()
+
+		for _, metric := range family.GetMetric() {
+			labels := metric.GetLabel()
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				samples = append(samples, sample{name, labels, metric.GetCounter().GetValue()})
+			case dto.MetricType_GAUGE:
+				samples = append(samples, sample{name, labels, metric.GetGauge().GetValue()})
+			case dto.MetricType_UNTYPED:
+				samples = append(samples, sample{name, labels, metric.GetUntyped().GetValue()})
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				for _, q := range summary.GetQuantile() {
+					samples = append(samples, sample{
+						name,
+						withLabel(labels, "quantile", formatFloat(q.GetQuantile())),
+						q.GetValue(),
+					})
+				}
+
+				samples = append(samples,
+					sample{name + "_sum", labels, summary.GetSampleSum()},
+					sample{name + "_count", labels, float64(summary.GetSampleCount())},
+				)
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				for _, b := range histogram.GetBucket() {
+					samples = append(samples, sample{
+						name + "_bucket",
+						withLabel(labels, "le", formatFloat(b.GetUpperBound())),
+						float64(b.GetCumulativeCount()),
+					})
+				}
+
+				samples = append(samples,
+					sample{
+						name + "_bucket",
+						withLabel(labels, "le", "+Inf"),
+						float64(histogram.GetSampleCount()),
+					},
+					sample{name + "_sum", labels, histogram.GetSampleSum()},
+					sample{name + "_count", labels, float64(histogram.GetSampleCount())},
+				)
+			}
+		}
+	}
+
+	return samples
+}
+
+// withLabel returns labels with an additional label
+func withLabel(labels []*dto.LabelPair, name, value string) []*dto.LabelPair {
+	result := make([]*dto.LabelPair, 0, len(labels)+1)
+	result = append(result, labels...)
+
+	return append(result, &dto.LabelPair{Name: &name, Value: &value})
+}
+
+// encode returns the line of a sample in format, false when the format cannot
+// represent it (non-finite values in the Influx line protocol)
+func encode(format string, s sample, timestamp time.Time) (string, bool) {
+	if format == FormatInflux {
+		return encodeInflux(s, timestamp)
+	}
+
+	return encodePrometheus(s, timestamp), true
+}
+
+// encodeInflux encodes a sample as "name,label=value value=<v> <ns>". Labels
+// with empty values are left out, since the line protocol has no empty tags.
+func encodeInflux(s sample, timestamp time.Time) (string, bool) {
+	if math.IsNaN(s.value) || math.IsInf(s.value, 0) {
+		return "", false
+	}
+
+	var b strings.Builder
+
+	b.WriteString(influxEscaper.Replace(s.name))
+
+	for _, label := range sortedLabels(s.labels) {
+		if label.GetValue() == "" {
+			continue
+		}
+
+		b.WriteByte(',')
+		b.WriteString(influxEscaper.Replace(label.GetName()))
+		b.WriteByte('=')
+		b.WriteString(influxEscaper.Replace(label.GetValue()))
+	}
+
+	b.WriteString(" value=")
+	b.WriteString(formatFloat(s.value))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(timestamp.UnixNano(), 10))
+
+	return b.String(), true
+}
+
+// encodePrometheus encodes a sample as `name{label="value"} <v> <ms>`
+func encodePrometheus(s sample, timestamp time.Time) string {
+	var b strings.Builder
+
+	b.WriteString(s.name)
+
+	if len(s.labels) > 0 {
+		b.WriteByte('{')
+
+		for i, label := range sortedLabels(s.labels) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(label.GetName())
+			b.WriteString(`="`)
+			b.WriteString(prometheusEscaper.Replace(label.GetValue()))
+			b.WriteByte('"')
+		}
+
+		b.WriteByte('}')
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(s.value))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(timestamp.UnixMilli(), 10))
+
+	return b.String()
+}
+
+// influxEscaper escapes measurement names, tag keys and tag values
+var influxEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+
+// prometheusEscaper escapes label values
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// sortedLabels returns labels sorted by name
+func sortedLabels(labels []*dto.LabelPair) []*dto.LabelPair {
+	sorted := make([]*dto.LabelPair, len(labels))
+	copy(sorted, labels)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+
+	return sorted
+}
+
+// formatFloat formats a value like the Prometheus text format
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
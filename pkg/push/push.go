@@ -0,0 +1,265 @@
+// Package push periodically pushes the gathered metrics to a remote endpoint
+// in the Influx line protocol or the Prometheus text format, for installs
+// aggregating metrics into VictoriaMetrics or InfluxDB without a scraper
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// Results of pushed samples and push requests
+const (
+	resultSent    = "sent"
+	resultDropped = "dropped"
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+// Config contains push configuration
+type Config struct {
+	// URL receives the batches, e.g. http://victoria-metrics:8428/write for
+	// the Influx line protocol or .../api/v1/import/prometheus for the
+	// Prometheus text format
+	URL string
+	// Format of the samples, FormatInflux or FormatPrometheus
+	Format string
+	// Interval between two pushes
+	Interval time.Duration
+	// Timeout of each request
+	Timeout time.Duration
+	// BatchSize is the maximum number of samples per request
+	BatchSize int
+	// MaxRetries is the number of retries of a failed batch before dropping it
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled after each retry
+	RetryBackoff time.Duration
+	// Username and Password set basic authentication when Username is set
+	Username string
+	Password string
+	// BearerToken is sent as Authorization header when set
+	BearerToken string
+}
+
+// Pusher pushes the metrics of a gatherer at every interval. It is a
+// prometheus.Collector exposing the results of its pushes.
+type Pusher struct {
+	config   Config
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	logger   *log.Entry
+
+	samples     *prometheus.CounterVec
+	requests    *prometheus.CounterVec
+	lastSuccess prometheus.Gauge
+}
+
+// NewPusher creates a pusher of the metrics of gatherer, its own metrics
+// being named <namespace>_state_metric_push_*
+func NewPusher(cfg Config, gatherer prometheus.Gatherer, namespace string) *Pusher {
+	return &Pusher{
+		config:   cfg,
+		gatherer: gatherer,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		logger:   log.WithField("component", "push"),
+		samples: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "state_metric",
+				Name:      "push_samples_total",
+				Help:      "Number of samples pushed to the remote endpoint by result (sent, dropped)",
+			},
+			[]string{"result"},
+		),
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "state_metric",
+				Name:      "push_requests_total",
+				Help:      "Number of push requests, retries included, by result (success, error)",
+			},
+			[]string{"result"},
+		),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "state_metric",
+			Name:      "push_last_success_timestamp_seconds",
+			Help:      "Unix time of the last push whose batches were all accepted",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (p *Pusher) Describe(ch chan<- *prometheus.Desc) {
+	p.samples.Describe(ch)
+	p.requests.Describe(ch)
+	p.lastSuccess.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (p *Pusher) Collect(ch chan<- prometheus.Metric) {
+	p.samples.Collect(ch)
+	p.requests.Collect(ch)
+	p.lastSuccess.Collect(ch)
+}
+
+// Run pushes the metrics at every interval until ctx is done
+func (p *Pusher) Run(ctx context.Context) {
+	p.logger.WithFields(log.Fields{
+		"url":      p.config.URL,
+		"format":   p.config.Format,
+		"interval": p.config.Interval,
+	}).Info("Metrics push enabled")
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.Push(ctx); err != nil && ctx.Err() == nil {
+				p.logger.WithError(err).Warn("Failed to push metrics")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Push gathers the metrics and pushes them in batches of at most BatchSize
+// samples. Batches still failing after the retries are dropped.
+func (p *Pusher) Push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		// Gather returns what it could gather along with the error
+		p.logger.WithError(err).Warn("Metrics gathered with errors, pushing the gathered ones")
+	}
+
+	now := time.Now()
+
+	var (
+		lines [][]byte
+		errs  []error
+	)
+
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+
+		result := resultSent
+		if err := p.send(ctx, bytes.Join(lines, []byte("\n"))); err != nil {
+			result = resultDropped
+
+			errs = append(errs, err)
+		}
+
+		p.samples.WithLabelValues(result).Add(float64(len(lines)))
+		lines = lines[:0]
+	}
+
+	for _, s := range flatten(families) {
+		line, ok := encode(p.config.Format, s, now)
+		if !ok {
+			continue
+		}
+
+		lines = append(lines, []byte(line))
+		if len(lines) >= p.config.BatchSize {
+			flush()
+		}
+	}
+
+	flush()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d batch(es) dropped: %w", len(errs), errors.Join(errs...))
+	}
+
+	p.lastSuccess.Set(float64(now.Unix()))
+
+	return nil
+}
+
+// send posts a batch, retrying network errors, throttling and server errors
+// with an exponential backoff
+func (p *Pusher) send(ctx context.Context, batch []byte) error {
+	var compressed bytes.Buffer
+
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(append(batch, '\n')); err != nil {
+		return fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	backoff := p.config.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		retryable, err := p.post(ctx, compressed.Bytes())
+		if err == nil {
+			p.requests.WithLabelValues(resultSuccess).Inc()
+			return nil
+		}
+
+		p.requests.WithLabelValues(resultError).Inc()
+
+		if !retryable || attempt >= p.config.MaxRetries {
+			return err
+		}
+
+		p.logger.WithError(err).WithField("retryIn", backoff).Debug("Push request failed, retrying")
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff *= 2
+	}
+}
+
+// post sends a compressed batch and returns whether a failure may be retried
+func (p *Pusher) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create push request: %w", err)
+	}
+
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	switch {
+	case p.config.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+p.config.BearerToken)
+	case p.config.Username != "":
+		req.SetBasicAuth(p.config.Username, p.config.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send push request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+	return retryable, fmt.Errorf("push rejected with status %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+}
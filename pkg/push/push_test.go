@@ -0,0 +1,194 @@
+package push_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/push"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// receiver records the lines of the batches it accepts, rejecting the first
+// requests with the given statuses
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	batches  [][]string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	gz, err := gzip.NewReader(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, _ := io.ReadAll(gz)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		w.WriteHeader(status)
+
+		return
+	}
+
+	r.batches = append(r.batches, strings.Split(strings.TrimSpace(string(body)), "\n"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newRegistry returns a registry with a gauge, a counter with an empty label
+// and a histogram
+func newRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "domain_health"}, []string{"domain", "ip"})
+	gauge.WithLabelValues("example.com", "10.0.0.1").Set(1)
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "errors_total"}, []string{"reason"})
+	counter.WithLabelValues("").Add(3)
+
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Buckets: []float64{0.5}})
+	histogram.Observe(0.2)
+
+	reg.MustRegister(gauge, counter, histogram)
+
+	return reg
+}
+
+func newPusher(url, format string, reg *prometheus.Registry) *push.Pusher {
+	return push.NewPusher(push.Config{
+		URL:          url,
+		Format:       format,
+		Timeout:      time.Second,
+		BatchSize:    100,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, reg, "sealos")
+}
+
+// stripTimestamp removes the trailing timestamp of a line
+func stripTimestamp(line string) string {
+	return line[:strings.LastIndexByte(line, ' ')]
+}
+
+func TestPushFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: push.FormatInflux,
+			want: []string{
+				"domain_health,domain=example.com,ip=10.0.0.1 value=1",
+				"errors_total value=3",
+				"latency_seconds_bucket,le=0.5 value=1",
+				"latency_seconds_bucket,le=+Inf value=1",
+				"latency_seconds_sum value=0.2",
+				"latency_seconds_count value=1",
+			},
+		},
+		{
+			format: push.FormatPrometheus,
+			want: []string{
+				`domain_health{domain="example.com",ip="10.0.0.1"} 1`,
+				`errors_total{reason=""} 3`,
+				`latency_seconds_bucket{le="0.5"} 1`,
+				`latency_seconds_bucket{le="+Inf"} 1`,
+				"latency_seconds_sum 0.2",
+				"latency_seconds_count 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			recv := &receiver{}
+
+			srv := httptest.NewServer(recv)
+			defer srv.Close()
+
+			if err := newPusher(srv.URL, tt.format, newRegistry()).Push(context.Background()); err != nil {
+				t.Fatalf("Push() error = %v", err)
+			}
+
+			if len(recv.batches) != 1 {
+				t.Fatalf("Expected 1 batch, got %d", len(recv.batches))
+			}
+
+			var got []string
+			for _, line := range recv.batches[0] {
+				got = append(got, stripTimestamp(line))
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Unexpected lines:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestPushBatchingAndRetries(t *testing.T) {
+	// The first batch is throttled then accepted, the second is rejected
+	recv := &receiver{statuses: []int{http.StatusTooManyRequests}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recv.mu.Lock()
+		second := len(recv.batches) == 1
+		recv.mu.Unlock()
+
+		if second {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		recv.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	reg := newRegistry()
+	pusher := push.NewPusher(push.Config{
+		URL:          srv.URL,
+		Format:       push.FormatInflux,
+		Timeout:      time.Second,
+		BatchSize:    4,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, reg, "sealos")
+
+	if err := pusher.Push(context.Background()); err == nil {
+		t.Fatal("Expected an error for the rejected batch")
+	}
+
+	if len(recv.batches) != 1 || len(recv.batches[0]) != 4 {
+		t.Fatalf("Expected a single accepted batch of 4 samples, got %v", recv.batches)
+	}
+
+	// 429 then success for the first batch, a single non-retried 400 for the second
+	want := `
+# HELP sealos_state_metric_push_requests_total Number of push requests, retries included, by result (success, error)
+# TYPE sealos_state_metric_push_requests_total counter
+sealos_state_metric_push_requests_total{result="error"} 2
+sealos_state_metric_push_requests_total{result="success"} 1
+# HELP sealos_state_metric_push_samples_total Number of samples pushed to the remote endpoint by result (sent, dropped)
+# TYPE sealos_state_metric_push_samples_total counter
+sealos_state_metric_push_samples_total{result="dropped"} 2
+sealos_state_metric_push_samples_total{result="sent"} 4
+`
+	if err := testutil.CollectAndCompare(pusher, strings.NewReader(want),
+		"sealos_state_metric_push_requests_total", "sealos_state_metric_push_samples_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,10 +1,14 @@
 package registry
 
 import (
+	"context"
+	"maps"
+	"runtime/pprof"
 	"sync"
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/maintenance"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	log "github.com/sirupsen/logrus"
@@ -12,9 +16,12 @@ import (
 
 // collectorResult holds the result of a collector execution
 type collectorResult struct {
-	name     string
-	duration time.Duration
-	success  bool
+	name       string
+	duration   time.Duration
+	success    bool
+	duplicates int
+	// firstDuplicate describes the first dropped duplicate series
+	firstDuplicate string
 }
 
 // PrometheusCollector wraps the registry as a prometheus.Collector.
@@ -25,6 +32,25 @@ type PrometheusCollector struct {
 	// Duration metrics
 	collectorDuration *prometheus.Desc
 	collectorSuccess  *prometheus.Desc
+	collectorUp       *prometheus.Desc
+	checksCanceled    *prometheus.Desc
+	duplicateSeries   *prometheus.Desc
+	maintenanceActive *prometheus.Desc
+
+	// Runtime metrics
+	collectorGoroutines *prometheus.Desc
+	handlerEvents       *prometheus.Desc
+	handlerPending      *prometheus.Desc
+	handlerSeconds      *prometheus.Desc
+	lockWait            *prometheus.Desc
+	informerRestarts    *prometheus.Desc
+	queueDepth          *prometheus.Desc
+	queueWait           *prometheus.Desc
+	queueRetries        *prometheus.Desc
+
+	// duplicates counts the duplicate series dropped per collector
+	duplicatesMu sync.Mutex
+	duplicates   map[string]float64
 }
 
 // NewPrometheusCollector creates a new PrometheusCollector
@@ -43,6 +69,85 @@ func NewPrometheusCollector(registry *Registry, namespace string) *PrometheusCol
 			[]string{"collector", "instance"},
 			nil,
 		),
+		collectorUp: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "collector", "up"),
+			"Whether collector works (1=up, 0=start failed, informers not synced or no successful poll for 3 intervals)",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		checksCanceled: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "checks_canceled_total"),
+			"Number of checks canceled by each level of the timeout hierarchy (global, collector, check)",
+			[]string{"collector", "level", "instance"},
+			nil,
+		),
+		duplicateSeries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "duplicate_series_dropped_total"),
+			"Number of metrics dropped because the collector emitted the same series twice in one collection",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		maintenanceActive: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "maintenance_window_active"),
+			"Whether a configured maintenance window is active (1=active, 0=inactive)",
+			[]string{"window", "mode", "instance"},
+			nil,
+		),
+		collectorGoroutines: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_goroutines"),
+			"Number of goroutines started by the collector (informers, poll loops and running collections)",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		handlerEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_event_handler_events_total"),
+			"Number of informer notifications handled by the collector",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		handlerPending: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_event_handler_pending"),
+			"Number of informer notifications currently being handled by the collector",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		handlerSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_event_handler_seconds_total"),
+			"Total time spent by the collector handling informer notifications",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		lockWait: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_lock_wait_seconds_total"),
+			"Total time spent waiting for the collector state lock, per lock mode (read, write)",
+			[]string{"collector", "mode", "instance"},
+			nil,
+		),
+		informerRestarts: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_informer_restarts_total"),
+			"Number of restarts of the collector caused by an informer watch receiving no event nor bookmark",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		queueDepth: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_event_queue_depth"),
+			"Number of objects whose informer notifications wait in the event queue of the collector",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		queueWait: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_event_queue_wait_seconds_total"),
+			"Total time informer notifications waited in the event queue of the collector before being handled",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		queueRetries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "state_metric", "collector_event_queue_retries_total"),
+			"Number of informer notifications queued again after their handler failed",
+			[]string{"collector", "instance"},
+			nil,
+		),
+		duplicates: make(map[string]float64),
 	}
 }
 
@@ -56,36 +161,90 @@ func (pc *PrometheusCollector) getInstance() string {
 
 // Describe implements prometheus.Collector
 func (pc *PrometheusCollector) Describe(ch chan<- *prometheus.Desc) {
-	pc.registry.mu.RLock()
-	collectors := pc.registry.collectors
-	pc.registry.mu.RUnlock()
+	collectors := pc.registry.GetAllCollectors()
 
 	// Describe our own metrics
 	ch <- pc.collectorDuration
 
 	ch <- pc.collectorSuccess
 
-	// Describe all collectors concurrently
+	ch <- pc.collectorUp
+
+	ch <- pc.checksCanceled
+
+	ch <- pc.duplicateSeries
+
+	ch <- pc.maintenanceActive
+
+	ch <- pc.collectorGoroutines
+
+	ch <- pc.handlerEvents
+
+	ch <- pc.handlerPending
+
+	ch <- pc.handlerSeconds
+
+	ch <- pc.lockWait
+
+	ch <- pc.informerRestarts
+
+	ch <- pc.queueDepth
+
+	ch <- pc.queueWait
+
+	ch <- pc.queueRetries
+
+	// Describe all collectors concurrently. Instances of the same collector
+	// type share their descriptors, which must only be sent once.
+	descCh := make(chan *prometheus.Desc, 100)
+
 	var wg sync.WaitGroup
 	for _, c := range collectors {
 		wg.Go(func() {
-			c.Describe(ch)
+			c.Describe(descCh)
 		})
 	}
 
-	wg.Wait()
+	go func() {
+		wg.Wait()
+		close(descCh)
+	}()
+
+	seen := make(map[string]struct{})
+	for desc := range descCh {
+		key := desc.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		ch <- desc
+	}
 }
 
-// collectFromCollector executes a single collector and returns the result
+// collectFromCollector executes a single collector and returns the result.
+// Metrics pass through a series guard that drops duplicate series, applies
+// the active maintenance windows and adds the legacy copies of a migration.
 func collectFromCollector(
 	name string,
 	col collector.Collector,
+	windows []maintenance.Window,
+	migration *namespaceMigration,
 	ch chan<- prometheus.Metric,
 	logger *log.Entry,
 ) collectorResult {
 	start := time.Now()
 	success := true
 
+	guard := newSeriesGuard(windows)
+	guard.migration = migration
+	guardCh := make(chan prometheus.Metric, 100)
+
+	var guardWg sync.WaitGroup
+	guardWg.Go(func() {
+		guard.forward(guardCh, ch)
+	})
+
 	// Collect metrics with panic recovery
 	func() {
 		defer func() {
@@ -99,9 +258,15 @@ func collectFromCollector(
 			}
 		}()
 
-		col.Collect(ch)
+		// Label the collection so its goroutines count towards the collector
+		pprof.Do(context.Background(), pprof.Labels(collector.GoroutineLabel, name), func(context.Context) {
+			col.Collect(guardCh)
+		})
 	}()
 
+	close(guardCh)
+	guardWg.Wait()
+
 	duration := time.Since(start)
 
 	// Log slow collectors
@@ -113,9 +278,12 @@ func collectFromCollector(
 	}
 
 	return collectorResult{
-		name:     name,
-		duration: duration,
-		success:  success,
+		name:       name,
+		duration:   duration,
+		success:    success,
+		duplicates: guard.dropped,
+
+		firstDuplicate: guard.firstDuplicate,
 	}
 }
 
@@ -153,11 +321,19 @@ func (pc *PrometheusCollector) emitCollectorMetrics(
 func (pc *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
 	// Copy collectors map and instance to reduce lock contention
 	pc.registry.mu.RLock()
-	collectors := pc.registry.collectors
+	collectors := maps.Clone(pc.registry.collectors)
 	instance := pc.registry.instance
+	schedule := pc.registry.maintenance
+	migration := pc.registry.migration
 	pc.registry.mu.RUnlock()
 
 	logger := log.WithField("module", "registry")
+	now := time.Now()
+	windows := schedule.Active(now)
+
+	if !migration.active(now) {
+		migration = nil
+	}
 
 	// Setup metric wrapper if instance is configured
 	metricCh := ch
@@ -179,8 +355,13 @@ func (pc *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
 	resultCh := make(chan collectorResult, len(collectors))
 
 	for name, c := range collectors {
+		collectorMigration := migration
+		if migration != nil && !migration.covers(name) {
+			collectorMigration = nil
+		}
+
 		collectWg.Go(func() {
-			result := collectFromCollector(name, c, metricCh, logger)
+			result := collectFromCollector(name, c, windows, collectorMigration, metricCh, logger)
 			resultCh <- result
 		})
 	}
@@ -201,7 +382,210 @@ func (pc *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
 		results = append(results, result)
 	}
 
-	pc.emitCollectorMetrics(results, ch)
+	pc.registry.recordCollections(results, time.Now())
+
+	// The own metrics of the registry are always migrated
+	ownCh := ch
+
+	if migration != nil {
+		migrationCh := make(chan prometheus.Metric, 100)
+		ownCh = migrationCh
+
+		var migrationWg sync.WaitGroup
+		migrationWg.Go(func() {
+			migration.forward(migrationCh, ch)
+		})
+
+		defer func() {
+			close(migrationCh)
+			migrationWg.Wait()
+		}()
+	}
+
+	pc.emitCollectorMetrics(results, ownCh)
+	pc.emitCollectorUp(collectors, now, instance, ownCh)
+	pc.emitDuplicateSeries(results, instance, ownCh, logger)
+	pc.emitCanceledChecks(collectors, instance, ownCh)
+	pc.emitMaintenanceWindows(schedule, now, instance, ownCh)
+	pc.emitRuntimeStats(collectors, instance, ownCh)
+}
+
+// emitRuntimeStats emits the goroutine counts of all collectors, and the event
+// handler and lock counters of collectors instrumenting them
+func (pc *PrometheusCollector) emitRuntimeStats(
+	collectors map[string]collector.Collector,
+	instance string,
+	ch chan<- prometheus.Metric,
+) {
+	goroutines := collector.GoroutinesByCollector()
+
+	for name, c := range collectors {
+		ch <- prometheus.MustNewConstMetric(
+			pc.collectorGoroutines,
+			prometheus.GaugeValue,
+			float64(goroutines[name]),
+			name,
+			instance,
+		)
+
+		reporter, ok := c.(collector.RuntimeReporter)
+		if !ok {
+			continue
+		}
+
+		stats := reporter.RuntimeStats()
+		if !stats.Instrumented {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			pc.handlerEvents,
+			prometheus.CounterValue,
+			float64(stats.HandlerEvents),
+			name,
+			instance,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			pc.handlerPending,
+			prometheus.GaugeValue,
+			float64(stats.HandlerPending),
+			name,
+			instance,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			pc.handlerSeconds,
+			prometheus.CounterValue,
+			stats.HandlerDuration.Seconds(),
+			name,
+			instance,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			pc.informerRestarts,
+			prometheus.CounterValue,
+			float64(stats.InformerRestarts),
+			name,
+			instance,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			pc.queueDepth,
+			prometheus.GaugeValue,
+			float64(stats.QueueDepth),
+			name,
+			instance,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			pc.queueWait,
+			prometheus.CounterValue,
+			stats.QueueWait.Seconds(),
+			name,
+			instance,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			pc.queueRetries,
+			prometheus.CounterValue,
+			float64(stats.QueueRetries),
+			name,
+			instance,
+		)
+
+		for mode, wait := range stats.LockWait {
+			ch <- prometheus.MustNewConstMetric(
+				pc.lockWait,
+				prometheus.CounterValue,
+				wait.Seconds(),
+				name,
+				mode,
+				instance,
+			)
+		}
+	}
+}
+
+// emitMaintenanceWindows emits whether each configured maintenance window is active
+func (pc *PrometheusCollector) emitMaintenanceWindows(
+	schedule *maintenance.Schedule,
+	now time.Time,
+	instance string,
+	ch chan<- prometheus.Metric,
+) {
+	for _, window := range schedule.Windows() {
+		active := 0.0
+		if window.IsActive(now) {
+			active = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			pc.maintenanceActive,
+			prometheus.GaugeValue,
+			active,
+			window.Name,
+			window.Mode,
+			instance,
+		)
+	}
+}
+
+// emitDuplicateSeries accumulates and emits the duplicate series dropped per collector.
+// A warning is logged the first time a collector emits duplicates.
+func (pc *PrometheusCollector) emitDuplicateSeries(
+	results []collectorResult,
+	instance string,
+	ch chan<- prometheus.Metric,
+	logger *log.Entry,
+) {
+	pc.duplicatesMu.Lock()
+	defer pc.duplicatesMu.Unlock()
+
+	for _, result := range results {
+		if result.duplicates > 0 && pc.duplicates[result.name] == 0 {
+			logger.WithFields(log.Fields{
+				"collector":  result.name,
+				"duplicates": result.duplicates,
+				"series":     result.firstDuplicate,
+			}).Warn("Collector emitted duplicate series, dropping duplicates")
+		}
+
+		pc.duplicates[result.name] += float64(result.duplicates)
+
+		ch <- prometheus.MustNewConstMetric(
+			pc.duplicateSeries,
+			prometheus.CounterValue,
+			pc.duplicates[result.name],
+			result.name,
+			instance,
+		)
+	}
+}
+
+// emitCanceledChecks emits the canceled checks counters of polling collectors
+func (pc *PrometheusCollector) emitCanceledChecks(
+	collectors map[string]collector.Collector,
+	instance string,
+	ch chan<- prometheus.Metric,
+) {
+	for name, c := range collectors {
+		if _, ok := c.(collector.PollingCollector); !ok {
+			continue
+		}
+
+		reporter, ok := c.(collector.CancellationReporter)
+		if !ok {
+			continue
+		}
+
+		for level, count := range reporter.CanceledChecks() {
+			ch <- prometheus.MustNewConstMetric(
+				pc.checksCanceled,
+				prometheus.CounterValue,
+				float64(count),
+				name,
+				level,
+				instance,
+			)
+		}
+	}
 }
 
 // wrapMetricsWithInstance wraps metrics by adding instance label
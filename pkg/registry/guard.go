@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/labring/sealos-state-metrics/pkg/maintenance"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// labelValueSeparator cannot appear in valid UTF-8 label values
+const labelValueSeparator = "\xff"
+
+// seriesGuard drops metrics whose descriptor and label values were already
+// emitted by the same collector during the current collection. Duplicates
+// would otherwise fail the whole scrape with a gathering error.
+// It also silences or suppresses series matched by a maintenance window, and
+// adds the legacy copy of each series during a namespace migration.
+type seriesGuard struct {
+	seen  map[*prometheus.Desc]map[string]struct{}
+	names map[*prometheus.Desc]string
+
+	// windows are the maintenance windows active for this collection
+	windows []maintenance.Window
+	// migration duplicates the series under the legacy namespace (nil = none)
+	migration *namespaceMigration
+
+	// dropped is the number of duplicates dropped
+	dropped int
+	// firstDuplicate describes the first dropped metric, for logging
+	firstDuplicate string
+}
+
+// newSeriesGuard creates a guard for one collection of one collector
+func newSeriesGuard(windows []maintenance.Window) *seriesGuard {
+	return &seriesGuard{
+		seen:    make(map[*prometheus.Desc]map[string]struct{}),
+		names:   make(map[*prometheus.Desc]string),
+		windows: windows,
+	}
+}
+
+// forward copies metrics from source to dest until source is closed,
+// dropping duplicates and applying the active maintenance windows
+func (g *seriesGuard) forward(source <-chan prometheus.Metric, dest chan<- prometheus.Metric) {
+	for metric := range source {
+		var out dto.Metric
+		if err := metric.Write(&out); err != nil {
+			// Let the Prometheus registry report invalid metrics
+			dest <- metric
+			continue
+		}
+
+		desc := metric.Desc()
+
+		if g.duplicate(desc, out.GetLabel()) {
+			continue
+		}
+
+		if len(g.windows) > 0 {
+			window, ok := maintenance.Match(g.windows, g.metricName(desc), out.GetLabel())
+			if ok && window.Mode == maintenance.ModeSuppress {
+				continue
+			}
+
+			if ok {
+				metric = &metricWithLabel{
+					Metric: metric,
+					name:   maintenance.SilencedLabel,
+					value:  window.Name,
+				}
+			}
+		}
+
+		dest <- metric
+
+		if g.migration != nil {
+			if renamed, ok := g.migration.rename(metric); ok {
+				dest <- renamed
+			}
+		}
+	}
+}
+
+// duplicate records a series and returns whether it was already emitted
+func (g *seriesGuard) duplicate(desc *prometheus.Desc, labels []*dto.LabelPair) bool {
+	values := make([]string, 0, len(labels))
+	for _, label := range labels {
+		values = append(values, label.GetName()+"="+label.GetValue())
+	}
+
+	key := strings.Join(values, labelValueSeparator)
+
+	series, ok := g.seen[desc]
+	if !ok {
+		series = make(map[string]struct{})
+		g.seen[desc] = series
+	}
+
+	if _, ok := series[key]; ok {
+		if g.dropped == 0 {
+			g.firstDuplicate = desc.String() + " {" + strings.Join(values, ",") + "}"
+		}
+
+		g.dropped++
+
+		return true
+	}
+
+	series[key] = struct{}{}
+
+	return false
+}
+
+// metricName returns the fully-qualified name of a descriptor.
+// prometheus.Desc does not expose it, so it is parsed from its string form.
+func (g *seriesGuard) metricName(desc *prometheus.Desc) string {
+	if name, ok := g.names[desc]; ok {
+		return name
+	}
+
+	name := desc.String()
+	if _, rest, ok := strings.Cut(name, `fqName: "`); ok {
+		name, _, _ = strings.Cut(rest, `"`)
+	}
+
+	g.names[desc] = name
+
+	return name
+}
+
+// metricWithLabel wraps a prometheus.Metric and adds a constant label
+type metricWithLabel struct {
+	prometheus.Metric
+	name  string
+	value string
+}
+
+// Write implements prometheus.Metric by adding the label
+func (m *metricWithLabel) Write(out *dto.Metric) error {
+	if err := m.Metric.Write(out); err != nil {
+		return err
+	}
+
+	out.Label = append(out.Label, &dto.LabelPair{
+		Name:  stringPtr(m.name),
+		Value: stringPtr(m.value),
+	})
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+const (
+	// DefaultVersion is the version of collectors not declaring one
+	DefaultVersion = "v1"
+
+	// InstanceSeparator separates the collector type from the instance name
+	// in enabled collectors, e.g. "domain:internal" is the "internal"
+	// instance of the domain collector
+	InstanceSeparator = ":"
+)
+
+// Metadata describes a registered collector type
+type Metadata struct {
+	// Type is the name the collector factory is registered with
+	Type string `json:"type"`
+	// Description is a one-line summary of what the collector reports
+	Description string `json:"description,omitempty"`
+	// Version of the collector metrics and configuration, bumped on incompatible changes
+	Version string `json:"version"`
+	// ConfigKey is the configuration section of the collector (e.g. collectors.domain)
+	ConfigKey string `json:"configKey"`
+	// RBAC lists the Kubernetes permissions the collector requires
+	RBAC []rbacv1.PolicyRule `json:"rbac,omitempty"`
+	// ConfigRBAC returns the permissions depending on the collector configuration
+	ConfigRBAC RBACFunc `json:"-"`
+	// Standalone is true for collectors that can run without Kubernetes
+	Standalone bool `json:"standalone"`
+	// ConfigEndpoints returns the external APIs the collector queries with
+	// its configuration, verified by the preflight checks
+	ConfigEndpoints EndpointsFunc `json:"-"`
+	// ConfigInstances returns the instances created when the collector type
+	// is enabled without an instance name
+	ConfigInstances InstancesFunc `json:"-"`
+}
+
+// RBACFunc returns the Kubernetes permissions a collector requires with the
+// configuration of loader, for permissions that depend on it (e.g. the
+// configured custom resources)
+type RBACFunc func(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error)
+
+// EndpointsFunc returns the host:port addresses of the external APIs a
+// collector queries with the configuration of loader (e.g. the cloud
+// provider APIs of the configured accounts)
+type EndpointsFunc func(loader collector.ConfigLoader) ([]string, error)
+
+// InstancesFunc returns the names of the instances of a collector configured
+// in loader (e.g. the endpoints of the plugin collector)
+type InstancesFunc func(loader collector.ConfigLoader) ([]string, error)
+
+// Option configures the metadata of a registered collector
+type Option func(*Metadata)
+
+// WithDescription sets the collector description
+func WithDescription(description string) Option {
+	return func(m *Metadata) {
+		m.Description = description
+	}
+}
+
+// WithVersion sets the collector version (defaults to DefaultVersion)
+func WithVersion(version string) Option {
+	return func(m *Metadata) {
+		m.Version = version
+	}
+}
+
+// WithConfigKey sets the configuration section of the collector
+// (defaults to collectors.<type>)
+func WithConfigKey(key string) Option {
+	return func(m *Metadata) {
+		m.ConfigKey = key
+	}
+}
+
+// WithRBAC adds a Kubernetes permission required by the collector
+func WithRBAC(apiGroups, resources, verbs []string) Option {
+	return func(m *Metadata) {
+		m.RBAC = append(m.RBAC, rbacv1.PolicyRule{
+			APIGroups: apiGroups,
+			Resources: resources,
+			Verbs:     verbs,
+		})
+	}
+}
+
+// WithRBACRule adds a Kubernetes permission required by the collector, for
+// rules WithRBAC cannot express (e.g. restricted to resource names)
+func WithRBACRule(rule rbacv1.PolicyRule) Option {
+	return func(m *Metadata) {
+		m.RBAC = append(m.RBAC, rule)
+	}
+}
+
+// WithConfigRBAC sets the function returning the permissions that depend on the
+// collector configuration, required in addition to the WithRBAC ones
+func WithConfigRBAC(fn RBACFunc) Option {
+	return func(m *Metadata) {
+		m.ConfigRBAC = fn
+	}
+}
+
+// WithConfigEndpoints sets the function returning the external APIs the
+// collector queries with its configuration
+func WithConfigEndpoints(fn EndpointsFunc) Option {
+	return func(m *Metadata) {
+		m.ConfigEndpoints = fn
+	}
+}
+
+// WithConfigInstances sets the function returning the instances created when
+// the collector type is enabled without an instance name, e.g. "plugin"
+// creates a "plugin:<name>" instance per configured endpoint. The default
+// instance is created when the function returns none.
+func WithConfigInstances(fn InstancesFunc) Option {
+	return func(m *Metadata) {
+		m.ConfigInstances = fn
+	}
+}
+
+// WithStandalone marks the collector as able to run without Kubernetes,
+// so it stays enabled in standalone mode
+func WithStandalone() Option {
+	return func(m *Metadata) {
+		m.Standalone = true
+	}
+}
+
+// newMetadata returns the metadata of a collector type with defaults applied
+func newMetadata(name string, opts []Option) Metadata {
+	metadata := Metadata{
+		Type:      name,
+		Version:   DefaultVersion,
+		ConfigKey: "collectors." + name,
+	}
+
+	for _, opt := range opts {
+		opt(&metadata)
+	}
+
+	return metadata
+}
+
+// ParseInstanceName splits an enabled collector entry into the collector type
+// and the instance name. The instance name is empty for the default instance:
+//   - "domain" -> ("domain", "")
+//   - "domain:internal" -> ("domain", "internal")
+func ParseInstanceName(name string) (collectorType, instance string) {
+	collectorType, instance, _ = strings.Cut(name, InstanceSeparator)
+	return collectorType, instance
+}
+
+// InstanceInfo describes a configured collector instance
+type InstanceInfo struct {
+	// Name is the instance name as enabled (e.g. domain:internal)
+	Name string `json:"name"`
+	// Type is the collector type of the instance
+	Type string `json:"type"`
+	// ConfigKey is the configuration section overriding the collector type
+	// section for this instance (empty for the default instance)
+	ConfigKey string `json:"configKey,omitempty"`
+	// RequiresLeaderElection is only set for created instances
+	RequiresLeaderElection bool `json:"requiresLeaderElection"`
+	// Error is the initialization error of instances that failed to initialize
+	Error string `json:"error,omitempty"`
+	// Skipped is the reason instances were not created (e.g. standalone mode)
+	Skipped string `json:"skipped,omitempty"`
+}
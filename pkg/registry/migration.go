@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// namespaceMigration emits metrics under a former namespace as well as the
+// current one, so alerts and dashboards can move to the new names before the
+// old ones disappear
+type namespaceMigration struct {
+	namespace string
+	legacy    string
+	// collectors are the collector instances whose metrics are duplicated (empty = all)
+	collectors map[string]bool
+	// until is the end of the migration window (zero = no end)
+	until time.Time
+
+	mu    sync.Mutex
+	descs map[*prometheus.Desc]*prometheus.Desc // nil value: not renamed
+}
+
+// newNamespaceMigration returns the migration from legacy to namespace, or
+// nil when no legacy namespace is set
+func newNamespaceMigration(namespace, legacy string, collectors []string, until time.Time) *namespaceMigration {
+	if legacy == "" || legacy == namespace {
+		return nil
+	}
+
+	m := &namespaceMigration{
+		namespace:  namespace,
+		legacy:     legacy,
+		collectors: make(map[string]bool, len(collectors)),
+		until:      until,
+		descs:      make(map[*prometheus.Desc]*prometheus.Desc),
+	}
+
+	for _, name := range collectors {
+		m.collectors[name] = true
+	}
+
+	return m
+}
+
+// active returns whether metrics are duplicated at now
+func (m *namespaceMigration) active(now time.Time) bool {
+	return m != nil && (m.until.IsZero() || now.Before(m.until))
+}
+
+// covers returns whether the metrics of a collector instance are duplicated.
+// Instances are selected by instance name or collector type.
+func (m *namespaceMigration) covers(name string) bool {
+	if len(m.collectors) == 0 {
+		return true
+	}
+
+	collectorType, _ := ParseInstanceName(name)
+
+	return m.collectors[name] || m.collectors[collectorType]
+}
+
+// legacyName returns the name of a metric in the legacy namespace, false for
+// metrics outside the current namespace
+func (m *namespaceMigration) legacyName(name string) (string, bool) {
+	if m.namespace == "" {
+		return m.legacy + "_" + name, true
+	}
+
+	rest, ok := strings.CutPrefix(name, m.namespace+"_")
+	if !ok {
+		return "", false
+	}
+
+	return m.legacy + "_" + rest, true
+}
+
+// rename returns a copy of a metric under its legacy name
+func (m *namespaceMigration) rename(metric prometheus.Metric) (prometheus.Metric, bool) {
+	desc := m.legacyDesc(metric.Desc())
+	if desc == nil {
+		return nil, false
+	}
+
+	return &renamedMetric{Metric: metric, desc: desc}, true
+}
+
+// legacyDesc returns the descriptor of the legacy name of a descriptor, nil
+// when it is not renamed. Labels are written by the metric itself, so the
+// descriptor only carries the name and help.
+func (m *namespaceMigration) legacyDesc(desc *prometheus.Desc) *prometheus.Desc {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if legacy, ok := m.descs[desc]; ok {
+		return legacy
+	}
+
+	var legacy *prometheus.Desc
+
+	name, help, ok := parseDesc(desc)
+	if ok {
+		if legacyName, ok := m.legacyName(name); ok {
+			legacy = prometheus.NewDesc(legacyName, help, nil, nil)
+		}
+	}
+
+	m.descs[desc] = legacy
+
+	return legacy
+}
+
+// forward copies metrics from source to dest until source is closed, adding
+// the legacy copy of each metric
+func (m *namespaceMigration) forward(source <-chan prometheus.Metric, dest chan<- prometheus.Metric) {
+	for metric := range source {
+		dest <- metric
+
+		if renamed, ok := m.rename(metric); ok {
+			dest <- renamed
+		}
+	}
+}
+
+// parseDesc returns the fully-qualified name and help of a descriptor.
+// prometheus.Desc does not expose them, so they are parsed from its string form.
+func parseDesc(desc *prometheus.Desc) (name, help string, ok bool) {
+	s := desc.String()
+
+	_, rest, found := strings.Cut(s, "fqName: ")
+	if !found {
+		return "", "", false
+	}
+
+	quoted, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return "", "", false
+	}
+
+	name, _ = strconv.Unquote(quoted)
+
+	_, rest, found = strings.Cut(rest[len(quoted):], "help: ")
+	if !found {
+		return "", "", false
+	}
+
+	quoted, err = strconv.QuotedPrefix(rest)
+	if err != nil {
+		return "", "", false
+	}
+
+	help, _ = strconv.Unquote(quoted)
+
+	return name, help, true
+}
+
+// renamedMetric is a metric exposed under another descriptor
+type renamedMetric struct {
+	prometheus.Metric
+	desc *prometheus.Desc
+}
+
+// Desc implements prometheus.Metric
+func (m *renamedMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
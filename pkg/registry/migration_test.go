@@ -0,0 +1,117 @@
+//nolint:testpackage
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gaugeCollector is a mock collector emitting one gauge
+type gaugeCollector struct {
+	mockCollector
+
+	desc *prometheus.Desc
+}
+
+func (c *gaugeCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+
+func (c *gaugeCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1, "example.com")
+}
+
+func TestNamespaceMigration(t *testing.T) {
+	newGauge := func(subsystem string) *gaugeCollector {
+		return &gaugeCollector{desc: prometheus.NewDesc(
+			prometheus.BuildFQName("sealos_v2", subsystem, "up"),
+			"Whether the target is up",
+			[]string{"target"},
+			prometheus.Labels{"tier": "edge"},
+		)}
+	}
+
+	r := &Registry{
+		collectors: map[string]collector.Collector{
+			"domain":       newGauge("domain"),
+			"probe:public": newGauge("probe"),
+			"node":         newGauge("node"),
+		},
+		migration: newNamespaceMigration("sealos_v2", "sealos", []string{"domain", "probe"}, time.Time{}),
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewPrometheusCollector(r, "sealos_v2"))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	names := make(map[string]bool)
+
+	for _, family := range families {
+		names[family.GetName()] = true
+
+		if family.GetName() == "sealos_domain_up" {
+			labels := make(map[string]string)
+			for _, label := range family.GetMetric()[0].GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["target"] != "example.com" || labels["tier"] != "edge" {
+				t.Errorf("Expected the labels of the original metric, got %v", labels)
+			}
+
+			if family.GetHelp() != "Whether the target is up" {
+				t.Errorf("Expected the help of the original metric, got %q", family.GetHelp())
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"sealos_v2_domain_up",
+		"sealos_domain_up",
+		"sealos_v2_probe_up",
+		"sealos_probe_up", // selected by collector type
+		"sealos_v2_node_up",
+		"sealos_v2_state_metric_collector_success",
+		"sealos_state_metric_collector_success", // own metrics are always migrated
+	} {
+		if !names[name] {
+			t.Errorf("Expected metric %s, got %v", name, names)
+		}
+	}
+
+	if names["sealos_node_up"] {
+		t.Error("Expected no legacy metric of a collector not selected")
+	}
+}
+
+func TestNamespaceMigrationWindow(t *testing.T) {
+	until := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	m := newNamespaceMigration("sealos_v2", "sealos", nil, until)
+
+	if !m.active(until.Add(-time.Second)) || m.active(until) {
+		t.Error("Expected the migration to end at its deadline")
+	}
+
+	if newNamespaceMigration("sealos", "", nil, until) != nil {
+		t.Error("Expected no migration without a legacy namespace")
+	}
+
+	var none *namespaceMigration
+	if none.active(until) {
+		t.Error("Expected a nil migration to be inactive")
+	}
+
+	// Without a current namespace, the legacy namespace is prepended
+	if name, ok := newNamespaceMigration("", "sealos", nil, until).legacyName("domain_up"); !ok || name != "sealos_domain_up" {
+		t.Errorf("Unexpected legacy name %q", name)
+	}
+
+	if _, ok := m.legacyName("go_goroutines"); ok {
+		t.Error("Expected metrics outside the namespace not to be renamed")
+	}
+}
@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// RequiredRBAC returns the Kubernetes permissions required by the enabled
+// collector instances with the given config content: the static permissions
+// of their metadata and the ones depending on their configuration.
+// Permissions are merged with MergeRBAC.
+func (r *Registry) RequiredRBAC(configContent []byte, enabled []string) ([]rbacv1.PolicyRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	configLoader := newConfigLoader(configContent)
+	// Entries failing to load their instances fail when created
+	enabled, _ = r.expandInstances(configLoader, enabled)
+
+	var rules []rbacv1.PolicyRule
+
+	for _, name := range enabled {
+		collectorType, instance := ParseInstanceName(name)
+
+		metadata, exists := r.metadata[collectorType]
+		if !exists {
+			return nil, fmt.Errorf("collector factory not found: %s", collectorType)
+		}
+
+		rules = append(rules, metadata.RBAC...)
+
+		if metadata.ConfigRBAC == nil {
+			continue
+		}
+
+		configRules, err := metadata.ConfigRBAC(instanceConfigLoader(configLoader, metadata, instance))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions of collector %s: %w", name, err)
+		}
+
+		rules = append(rules, configRules...)
+	}
+
+	return MergeRBAC(rules), nil
+}
+
+// RequiredEndpoints returns the host:port addresses of the external APIs
+// queried by the enabled collector instances with the given config content,
+// keyed by instance name. Instances querying none are missing.
+func (r *Registry) RequiredEndpoints(configContent []byte, enabled []string) (map[string][]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	configLoader := newConfigLoader(configContent)
+	// Entries failing to load their instances fail when created
+	enabled, _ = r.expandInstances(configLoader, enabled)
+	endpoints := make(map[string][]string)
+
+	for _, name := range enabled {
+		collectorType, instance := ParseInstanceName(name)
+
+		metadata, exists := r.metadata[collectorType]
+		if !exists {
+			return nil, fmt.Errorf("collector factory not found: %s", collectorType)
+		}
+
+		if metadata.ConfigEndpoints == nil {
+			continue
+		}
+
+		addresses, err := metadata.ConfigEndpoints(instanceConfigLoader(configLoader, metadata, instance))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get endpoints of collector %s: %w", name, err)
+		}
+
+		if len(addresses) > 0 {
+			endpoints[name] = addresses
+		}
+	}
+
+	return endpoints, nil
+}
+
+// MergeRBAC merges the rules on the same API groups, resources and resource
+// names into one rule with the union of their verbs. Rules, and the lists of
+// each rule, are sorted so the result is stable.
+func MergeRBAC(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	merged := make(map[string]*rbacv1.PolicyRule, len(rules))
+
+	for _, rule := range rules {
+		key := rbacRuleKey(rule)
+
+		existing, ok := merged[key]
+		if !ok {
+			existing = &rbacv1.PolicyRule{
+				APIGroups:     sortedUnique(rule.APIGroups),
+				Resources:     sortedUnique(rule.Resources),
+				ResourceNames: sortedUnique(rule.ResourceNames),
+			}
+			merged[key] = existing
+		}
+
+		existing.Verbs = sortedUnique(append(existing.Verbs, rule.Verbs...))
+	}
+
+	result := make([]rbacv1.PolicyRule, 0, len(merged))
+	for _, rule := range merged {
+		result = append(result, *rule)
+	}
+
+	slices.SortFunc(result, func(a, b rbacv1.PolicyRule) int {
+		return cmp.Compare(rbacRuleKey(a), rbacRuleKey(b))
+	})
+
+	return result
+}
+
+// rbacRuleKey identifies the API groups, resources and resource names of a rule
+func rbacRuleKey(rule rbacv1.PolicyRule) string {
+	return strings.Join([]string{
+		strings.Join(sortedUnique(rule.APIGroups), ","),
+		strings.Join(sortedUnique(rule.Resources), ","),
+		strings.Join(sortedUnique(rule.ResourceNames), ","),
+	}, "/")
+}
+
+// sortedUnique returns a sorted copy of values without duplicates, nil when empty
+func sortedUnique(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return slices.Compact(slices.Sorted(slices.Values(values)))
+}
@@ -0,0 +1,105 @@
+//nolint:testpackage
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// TestRequiredRBAC tests that the static and configuration dependent
+// permissions of every enabled instance are merged
+func TestRequiredRBAC(t *testing.T) {
+	r := &Registry{
+		metadata: make(map[string]Metadata),
+	}
+
+	r.metadata["mock"] = newMetadata("mock", []Option{
+		WithRBAC([]string{""}, []string{"pods"}, []string{"list"}),
+		WithRBAC([]string{""}, []string{"pods"}, []string{"watch", "list"}),
+		WithConfigRBAC(func(loader collector.ConfigLoader) ([]rbacv1.PolicyRule, error) {
+			cfg := &struct {
+				ConfigMap string `yaml:"configMap"`
+			}{}
+			if err := loader.LoadModuleConfig("collectors.mock", cfg); err != nil {
+				return nil, err
+			}
+
+			return []rbacv1.PolicyRule{{
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{cfg.ConfigMap},
+				Verbs:         []string{"get"},
+			}}, nil
+		}),
+	})
+
+	rules, err := r.RequiredRBAC([]byte(`
+collectors:
+  mock:
+    configMap: targets
+  mock:internal:
+    configMap: internal-targets
+`), []string{"mock", "mock:internal"})
+	if err != nil {
+		t.Fatalf("RequiredRBAC() error = %v", err)
+	}
+
+	expected := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"internal-targets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"targets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected rules %+v, got %+v", expected, rules)
+	}
+
+	if _, err := r.RequiredRBAC(nil, []string{"missing"}); err == nil {
+		t.Error("Expected error for an unknown collector type")
+	}
+}
+
+// TestRequiredEndpoints tests that the endpoints of every enabled instance
+// are returned with its configuration
+func TestRequiredEndpoints(t *testing.T) {
+	r := &Registry{
+		metadata: map[string]Metadata{
+			"mock": newMetadata("mock", []Option{
+				WithConfigEndpoints(func(loader collector.ConfigLoader) ([]string, error) {
+					cfg := &struct {
+						Host string `yaml:"host"`
+					}{}
+					if err := loader.LoadModuleConfig("collectors.mock", cfg); err != nil {
+						return nil, err
+					}
+
+					if cfg.Host == "" {
+						return nil, nil
+					}
+
+					return []string{cfg.Host + ":443"}, nil
+				}),
+			}),
+			"plain": newMetadata("plain", nil),
+		},
+	}
+
+	endpoints, err := r.RequiredEndpoints([]byte(`
+collectors:
+  mock:
+    host: api.example.com
+  mock:none:
+    host: ""
+`), []string{"mock", "mock:none", "plain"})
+	if err != nil {
+		t.Fatalf("RequiredEndpoints() error = %v", err)
+	}
+
+	expected := map[string][]string{"mock": {"api.example.com:443"}}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("Expected endpoints %v, got %v", expected, endpoints)
+	}
+}
@@ -0,0 +1,47 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// CollectorReady returns why a collector expected to run does not serve
+// complete metrics yet, nil when it does. Informer collectors are ready once
+// their informers synced, polling collectors once a poll succeeded since
+// their last start, so scrapes right after a deploy or a reload do not see
+// empty metrics.
+func CollectorReady(c collector.Collector) error {
+	reporter, ok := c.(collector.LivenessReporter)
+	if !ok {
+		return nil
+	}
+
+	liveness := reporter.Liveness()
+	if liveness.StartError != nil {
+		return fmt.Errorf("start failed: %w", liveness.StartError)
+	}
+
+	if !liveness.Started {
+		return errors.New("not started")
+	}
+
+	if _, ok := c.(collector.PollingCollector); ok {
+		if liveness.LastPollSuccess.IsZero() {
+			return errors.New("waiting for the first successful poll")
+		}
+
+		return nil
+	}
+
+	if informer, ok := c.(collector.InformerCollector); ok && !informer.HasSynced() {
+		return errors.New("waiting for the informers to sync")
+	}
+
+	if !liveness.Ready {
+		return errors.New("waiting for the collector to be ready")
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+//nolint:testpackage
+package registry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// livePollingCollector is a mock polling collector reporting its liveness
+type livePollingCollector struct {
+	pollingCollector
+
+	liveness collector.Liveness
+}
+
+func (c *livePollingCollector) Liveness() collector.Liveness { return c.liveness }
+
+// liveInformerCollector is a mock informer collector reporting its liveness
+type liveInformerCollector struct {
+	informerCollector
+
+	liveness collector.Liveness
+}
+
+func (c *liveInformerCollector) Liveness() collector.Liveness { return c.liveness }
+
+func TestCollectorReady(t *testing.T) {
+	now := time.Now()
+	started := collector.Liveness{Started: true, Ready: true, StartedAt: now}
+
+	polled := started
+	polled.LastPollSuccess = now
+
+	tests := []struct {
+		name          string
+		collector     collector.Collector
+		expectedReady bool
+	}{
+		{
+			name:          "no liveness",
+			collector:     &mockCollector{},
+			expectedReady: true,
+		},
+		{
+			name: "start failed",
+			collector: &liveInformerCollector{
+				informerCollector: informerCollector{synced: true},
+				liveness:          collector.Liveness{StartError: errors.New("forbidden")},
+			},
+		},
+		{
+			name:      "not started",
+			collector: &liveInformerCollector{informerCollector: informerCollector{synced: true}},
+		},
+		{
+			name:      "informer syncing",
+			collector: &liveInformerCollector{liveness: started},
+		},
+		{
+			name: "informer synced",
+			collector: &liveInformerCollector{
+				informerCollector: informerCollector{synced: true},
+				liveness:          started,
+			},
+			expectedReady: true,
+		},
+		{
+			name: "first poll pending",
+			collector: &livePollingCollector{
+				pollingCollector: pollingCollector{synced: true, interval: time.Minute},
+				liveness:         started,
+			},
+		},
+		{
+			name: "first poll succeeded",
+			collector: &livePollingCollector{
+				pollingCollector: pollingCollector{synced: true, interval: time.Minute},
+				liveness:         polled,
+			},
+			expectedReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CollectorReady(tt.collector)
+			if (err == nil) != tt.expectedReady {
+				t.Errorf("Expected ready=%v, got %v", tt.expectedReady, err)
+			}
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/labring/sealos-state-metrics/pkg/config"
 	"github.com/labring/sealos-state-metrics/pkg/identity"
+	"github.com/labring/sealos-state-metrics/pkg/maintenance"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -29,9 +33,24 @@ var (
 type Registry struct {
 	mu               sync.RWMutex
 	factories        map[string]collector.Factory
+	metadata         map[string]Metadata // key: collector type
 	collectors       map[string]collector.Collector
-	failedCollectors map[string]error // Records collectors that failed to initialize
-	instance         string           // instance identity (pod name or hostname)
+	failedCollectors map[string]error  // Records collectors that failed to initialize
+	skipped          map[string]string // Records collectors not created and why (e.g. standalone mode)
+	enabled          []string          // enabled collector instances, in configuration order
+	instance         string            // instance identity (pod name or hostname)
+	maintenance      *maintenance.Schedule
+	migration        *namespaceMigration // nil when no legacy metrics namespace is set
+
+	// collections are the stats of the last collections, per collector
+	// (see CollectionStats)
+	collectionsMu sync.Mutex
+	collections   map[string]collector.CollectionStats
+
+	// initConfig and sections are the configuration the collectors were
+	// created with (sections key: instance name or maintenance section)
+	initConfig *InitConfig
+	sections   map[string]map[string]any
 }
 
 // GetRegistry returns the singleton registry instance
@@ -39,6 +58,7 @@ func GetRegistry() *Registry {
 	once.Do(func() {
 		globalRegistry = &Registry{
 			factories:        make(map[string]collector.Factory),
+			metadata:         make(map[string]Metadata),
 			collectors:       make(map[string]collector.Collector),
 			failedCollectors: make(map[string]error),
 		}
@@ -47,9 +67,9 @@ func GetRegistry() *Registry {
 	return globalRegistry
 }
 
-// Register registers a collector factory with the given name.
+// Register registers a collector factory with the given name and metadata.
 // This function is typically called from init() functions in collector packages.
-func Register(name string, factory collector.Factory) {
+func Register(name string, factory collector.Factory, opts ...Option) {
 	registry := GetRegistry()
 
 	registry.mu.Lock()
@@ -62,20 +82,25 @@ func Register(name string, factory collector.Factory) {
 	}
 
 	registry.factories[name] = factory
+	registry.metadata[name] = newMetadata(name, opts)
 	logger.WithField("name", name).Debug("Collector factory registered")
 }
 
 // MustRegister is like Register but panics if registration fails
-func MustRegister(name string, factory collector.Factory) {
+func MustRegister(name string, factory collector.Factory, opts ...Option) {
 	if name == "" {
 		panic("collector name cannot be empty")
 	}
 
+	if strings.Contains(name, InstanceSeparator) {
+		panic(fmt.Sprintf("collector name %s cannot contain %q", name, InstanceSeparator))
+	}
+
 	if factory == nil {
 		panic(fmt.Sprintf("collector factory for %s cannot be nil", name))
 	}
 
-	Register(name, factory)
+	Register(name, factory, opts...)
 }
 
 // InitConfig holds the configuration for initializing collectors
@@ -89,7 +114,21 @@ type InitConfig struct {
 	PodName              string
 	MetricsNamespace     string
 	InformerResyncPeriod time.Duration
+	CollectionTimeout    time.Duration
+	StaleWatchTimeout    time.Duration
+	EventQueueWorkers    int
+	EventQueueMaxDepth   int
 	EnabledCollectors    []string
+	// Standalone skips the collectors requiring Kubernetes
+	Standalone bool
+	// Cluster is the cluster identity passed to collectors
+	Cluster identity.Cluster
+	// LegacyMetricsNamespace is a former namespace the metrics of
+	// LegacyMetricsCollectors (empty = all) are also emitted under until
+	// LegacyMetricsUntil (zero = no end)
+	LegacyMetricsNamespace  string
+	LegacyMetricsCollectors []string
+	LegacyMetricsUntil      time.Time
 }
 
 // Initialize creates collector instances for the specified collectors.
@@ -138,50 +177,224 @@ func (r *Registry) createCollectors(cfg *InitConfig, action string) {
 	r.instance = identity.GetWithConfig(cfg.Identity, cfg.NodeName, cfg.PodName)
 
 	logger.WithFields(log.Fields{
-		"enabled":  cfg.EnabledCollectors,
-		"instance": r.instance,
+		"enabled":    cfg.EnabledCollectors,
+		"instance":   r.instance,
+		"standalone": cfg.Standalone,
 	}).Infof("%s collectors", action)
 
-	// Create config loader: content -> env (priority: defaults < content < env)
-	configLoader := config.NewWrapConfigLoader()
-	if len(cfg.ConfigContent) > 0 {
-		configLoader.Add(config.NewModuleConfigLoader(cfg.ConfigContent))
+	configLoader := newConfigLoader(cfg.ConfigContent)
+
+	r.maintenance = loadMaintenance(configLoader, logger)
+	r.migration = newNamespaceMigration(
+		cfg.MetricsNamespace,
+		cfg.LegacyMetricsNamespace,
+		cfg.LegacyMetricsCollectors,
+		cfg.LegacyMetricsUntil,
+	)
+
+	if r.migration != nil {
+		logger.WithFields(log.Fields{
+			"legacyNamespace": cfg.LegacyMetricsNamespace,
+			"collectors":      cfg.LegacyMetricsCollectors,
+			"until":           cfg.LegacyMetricsUntil,
+		}).Info("Metrics are also emitted under the legacy namespace")
 	}
+	enabled, failed := r.expandInstances(configLoader, cfg.EnabledCollectors)
 
-	configLoader.Add(config.NewEnvConfigLoader())
+	r.enabled = enabled
+	r.skipped = make(map[string]string)
+
+	r.sections = nil
+	r.recordSections(cfg, enabled)
 
 	// Create collectors from factories
-	for _, name := range cfg.EnabledCollectors {
-		factory, exists := r.factories[name]
-		if !exists {
-			err := errors.New("collector factory not found")
+	for _, name := range enabled {
+		if err, ok := failed[name]; ok {
 			r.failedCollectors[name] = err
-			logger.Warnf("Collector factory not found: %s", name)
+			logger.WithField("name", name).WithError(err).Error("Failed to load collector instances")
+
+			continue
+		}
+
+		r.createCollector(cfg, configLoader, name, logger)
+	}
+}
+
+// expandInstances returns the enabled collector instances, with the collector
+// types enabled without an instance name and configuring their instances (see
+// WithConfigInstances) replaced by these instances. Instances enabled twice
+// are only returned once. Entries whose instances cannot be loaded are kept
+// and returned with their error.
+// Must be called with r.mu held
+func (r *Registry) expandInstances(
+	configLoader collector.ConfigLoader,
+	names []string,
+) ([]string, map[string]error) {
+	var (
+		enabled []string
+		failed  map[string]error
+	)
+
+	seen := make(map[string]bool, len(names))
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			enabled = append(enabled, name)
+		}
+	}
+
+	for _, name := range names {
+		collectorType, instance := ParseInstanceName(name)
+
+		metadata, ok := r.metadata[collectorType]
+		if !ok || instance != "" || metadata.ConfigInstances == nil {
+			add(name)
 			continue
 		}
 
-		factoryCtx := &collector.FactoryContext{
-			Ctx:                  cfg.Ctx,
-			ConfigLoader:         configLoader,
-			ClientProvider:       cfg.ClientProvider,
-			Identity:             r.instance,
-			NodeName:             cfg.NodeName,
-			PodName:              cfg.PodName,
-			MetricsNamespace:     cfg.MetricsNamespace,
-			InformerResyncPeriod: cfg.InformerResyncPeriod,
-			Logger:               logger.WithField("collector", name),
+		instances, err := metadata.ConfigInstances(configLoader)
+		if err == nil {
+			err = validateInstances(instances)
 		}
 
-		c, err := factory(factoryCtx)
 		if err != nil {
-			r.failedCollectors[name] = err
-			logger.WithField("name", name).WithError(err).Error("Collector initialization failed")
+			if failed == nil {
+				failed = make(map[string]error)
+			}
+
+			failed[name] = err
+			add(name)
+
 			continue
 		}
 
-		r.collectors[name] = c
-		logger.WithField("name", name).Info("Collector created")
+		if len(instances) == 0 {
+			add(name)
+			continue
+		}
+
+		for _, instance := range instances {
+			add(collectorType + InstanceSeparator + instance)
+		}
+	}
+
+	return enabled, failed
+}
+
+// validateInstances checks the names of configured instances
+func validateInstances(instances []string) error {
+	for _, instance := range instances {
+		if instance == "" || strings.Contains(instance, InstanceSeparator) {
+			return fmt.Errorf("invalid instance name %q", instance)
+		}
 	}
+
+	return nil
+}
+
+// createCollector creates a collector instance from its factory, recording
+// why it is not created otherwise.
+// Must be called with r.mu held
+func (r *Registry) createCollector(
+	cfg *InitConfig,
+	configLoader collector.ConfigLoader,
+	name string,
+	logger *log.Entry,
+) {
+	collectorType, instance := ParseInstanceName(name)
+
+	factory, exists := r.factories[collectorType]
+	if !exists {
+		err := errors.New("collector factory not found")
+		r.failedCollectors[name] = err
+		logger.Warnf("Collector factory not found: %s", collectorType)
+		return
+	}
+
+	metadata := r.metadata[collectorType]
+	if cfg.Standalone && !metadata.Standalone {
+		r.skipped[name] = "requires Kubernetes, not available in standalone mode"
+		logger.WithField("name", name).Warn("Collector requires Kubernetes, skipped in standalone mode")
+
+		return
+	}
+
+	factoryCtx := &collector.FactoryContext{
+		Ctx:                  cfg.Ctx,
+		ConfigLoader:         instanceConfigLoader(configLoader, metadata, instance),
+		Instance:             instance,
+		ClientProvider:       cfg.ClientProvider,
+		Identity:             r.instance,
+		NodeName:             cfg.NodeName,
+		PodName:              cfg.PodName,
+		MetricsNamespace:     cfg.MetricsNamespace,
+		InformerResyncPeriod: cfg.InformerResyncPeriod,
+		CollectionTimeout:    cfg.CollectionTimeout,
+		StaleWatchTimeout:    cfg.StaleWatchTimeout,
+		EventQueueWorkers:    cfg.EventQueueWorkers,
+		EventQueueMaxDepth:   cfg.EventQueueMaxDepth,
+		Standalone:           cfg.Standalone,
+		Cluster:              cfg.Cluster,
+		Logger:               logger.WithField("collector", name),
+		Collectors:           r.GetAllCollectors,
+	}
+
+	c, err := factory(factoryCtx)
+	if err != nil {
+		r.failedCollectors[name] = err
+		logger.WithField("name", name).WithError(err).Error("Collector initialization failed")
+		return
+	}
+
+	r.collectors[name] = c
+	logger.WithField("name", name).Info("Collector created")
+}
+
+// newConfigLoader returns the config loader of the collectors:
+// content -> env (priority: defaults < content < env)
+func newConfigLoader(configContent []byte) collector.ConfigLoader {
+	configLoader := config.NewWrapConfigLoader()
+	if len(configContent) > 0 {
+		configLoader.Add(config.NewModuleConfigLoader(configContent))
+	}
+
+	configLoader.Add(config.NewEnvConfigLoader())
+
+	return configLoader
+}
+
+// instanceConfigLoader returns the config loader of a collector instance.
+// Named instances load the collector type section overridden by their own section.
+func instanceConfigLoader(loader collector.ConfigLoader, metadata Metadata, instance string) collector.ConfigLoader {
+	if instance == "" {
+		return loader
+	}
+
+	return config.NewInstanceConfigLoader(
+		loader,
+		metadata.ConfigKey,
+		metadata.ConfigKey+InstanceSeparator+instance,
+	)
+}
+
+// loadMaintenance loads the maintenance windows from the "maintenance" config section.
+// Invalid windows are logged and skipped.
+func loadMaintenance(loader collector.ConfigLoader, logger *log.Entry) *maintenance.Schedule {
+	cfg := &maintenance.Config{}
+	if err := loader.LoadModuleConfig("maintenance", cfg); err != nil {
+		logger.WithError(err).Warn("Failed to load maintenance windows")
+	}
+
+	schedule, err := maintenance.NewSchedule(cfg)
+	if err != nil {
+		logger.WithError(err).Warn("Skipping invalid maintenance windows")
+	}
+
+	if windows := schedule.Windows(); len(windows) > 0 {
+		logger.WithField("windows", len(windows)).Info("Maintenance windows configured")
+	}
+
+	return schedule
 }
 
 // Start starts all registered collectors
@@ -366,6 +579,62 @@ func (r *Registry) StopNonLeaderCollectors() error {
 	return nil
 }
 
+// LeaderCollectorNames returns the sorted names of the collectors requiring
+// leader election
+func (r *Registry) LeaderCollectorNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for name, c := range r.collectors {
+		if c.RequiresLeaderElection() {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// StartCollector starts a single collector by name
+func (r *Registry) StartCollector(ctx context.Context, name string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collectors[name]
+	if !ok {
+		return fmt.Errorf("collector %s not found", name)
+	}
+
+	if err := c.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start collector %s: %w", name, err)
+	}
+
+	log.WithField("module", "registry").WithField("name", name).Info("Collector started")
+
+	return nil
+}
+
+// StopCollector stops a single collector by name
+func (r *Registry) StopCollector(name string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collectors[name]
+	if !ok {
+		return fmt.Errorf("collector %s not found", name)
+	}
+
+	if err := c.Stop(); err != nil {
+		return fmt.Errorf("failed to stop collector %s: %w", name, err)
+	}
+
+	log.WithField("module", "registry").WithField("name", name).Info("Collector stopped")
+
+	return nil
+}
+
 // GetCollector returns a collector by name
 func (r *Registry) GetCollector(name string) (collector.Collector, bool) {
 	r.mu.RLock()
@@ -389,13 +658,12 @@ func (r *Registry) ListCollectors() []string {
 	return names
 }
 
-// GetAllCollectors returns the internal collectors map
-// Note: Caller should not modify the returned map
+// GetAllCollectors returns a copy of the collectors map, keyed by instance name
 func (r *Registry) GetAllCollectors() map[string]collector.Collector {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return r.collectors
+	return maps.Clone(r.collectors)
 }
 
 // GetFailedCollectors returns a map of collectors that failed to initialize
@@ -404,5 +672,63 @@ func (r *Registry) GetFailedCollectors() map[string]error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return r.failedCollectors
+	return maps.Clone(r.failedCollectors)
+}
+
+// GetMetadata returns the metadata of a registered collector type
+func (r *Registry) GetMetadata(collectorType string) (Metadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metadata, exists := r.metadata[collectorType]
+
+	return metadata, exists
+}
+
+// ListMetadata returns the metadata of all registered collector types, sorted by type
+func (r *Registry) ListMetadata() []Metadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := slices.Sorted(maps.Keys(r.metadata))
+
+	metadata := make([]Metadata, 0, len(types))
+	for _, collectorType := range types {
+		metadata = append(metadata, r.metadata[collectorType])
+	}
+
+	return metadata
+}
+
+// ListInstances returns the enabled collector instances, in configuration order
+func (r *Registry) ListInstances() []InstanceInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instances := make([]InstanceInfo, 0, len(r.enabled))
+
+	for _, name := range r.enabled {
+		collectorType, instance := ParseInstanceName(name)
+
+		info := InstanceInfo{
+			Name: name,
+			Type: collectorType,
+		}
+
+		if metadata, ok := r.metadata[collectorType]; ok && instance != "" {
+			info.ConfigKey = metadata.ConfigKey + InstanceSeparator + instance
+		}
+
+		if c, ok := r.collectors[name]; ok {
+			info.RequiresLeaderElection = c.RequiresLeaderElection()
+		} else if err, ok := r.failedCollectors[name]; ok {
+			info.Error = err.Error()
+		} else if reason, ok := r.skipped[name]; ok {
+			info.Skipped = reason
+		}
+
+		instances = append(instances, info)
+	}
+
+	return instances
 }
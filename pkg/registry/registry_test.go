@@ -133,3 +133,335 @@ func TestReinitializeClearsFailedCollectors(t *testing.T) {
 		t.Errorf("Expected failed collectors to be cleared, got %d", len(failedCollectors))
 	}
 }
+
+// duplicateCollector emits the same series twice
+type duplicateCollector struct {
+	mockCollector
+	desc *prometheus.Desc
+}
+
+func (d *duplicateCollector) Describe(ch chan<- *prometheus.Desc) { ch <- d.desc }
+
+func (d *duplicateCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(d.desc, prometheus.GaugeValue, 1, "a")
+	ch <- prometheus.MustNewConstMetric(d.desc, prometheus.GaugeValue, 2, "a")
+	ch <- prometheus.MustNewConstMetric(d.desc, prometheus.GaugeValue, 3, "b")
+}
+
+// TestPrometheusCollectorDropsDuplicateSeries tests that duplicate series do not fail the scrape
+func TestPrometheusCollectorDropsDuplicateSeries(t *testing.T) {
+	r := &Registry{
+		factories:        make(map[string]collector.Factory),
+		collectors:       make(map[string]collector.Collector),
+		failedCollectors: make(map[string]error),
+	}
+
+	r.collectors["dup"] = &duplicateCollector{
+		mockCollector: mockCollector{name: "dup"},
+		desc:          prometheus.NewDesc("test_series", "Test series", []string{"key"}, nil),
+	}
+
+	promRegistry := prometheus.NewPedanticRegistry()
+	promRegistry.MustRegister(NewPrometheusCollector(r, "test"))
+
+	families, err := promRegistry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	counts := make(map[string]int)
+	dropped := 0.0
+
+	for _, family := range families {
+		counts[family.GetName()] = len(family.GetMetric())
+
+		if family.GetName() == "test_state_metric_duplicate_series_dropped_total" {
+			dropped = family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+
+	if counts["test_series"] != 2 {
+		t.Errorf("Expected 2 unique series, got %d", counts["test_series"])
+	}
+
+	if dropped != 1 {
+		t.Errorf("Expected 1 dropped duplicate, got %v", dropped)
+	}
+}
+
+// intervalConfig is the configuration of the instance test collector
+type intervalConfig struct {
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+}
+
+// TestNamedInstances tests that named instances override the collector type configuration
+func TestNamedInstances(t *testing.T) {
+	r := &Registry{
+		factories:        make(map[string]collector.Factory),
+		metadata:         make(map[string]Metadata),
+		collectors:       make(map[string]collector.Collector),
+		failedCollectors: make(map[string]error),
+	}
+
+	configs := make(map[string]*intervalConfig)
+
+	r.factories["mock"] = func(ctx *collector.FactoryContext) (collector.Collector, error) {
+		cfg := &intervalConfig{}
+		if err := ctx.ConfigLoader.LoadModuleConfig("collectors.mock", cfg); err != nil {
+			return nil, err
+		}
+
+		configs[ctx.Logger.Data["collector"].(string)] = cfg
+
+		return &mockCollector{name: "mock"}, nil
+	}
+	r.metadata["mock"] = newMetadata("mock", []Option{
+		WithDescription("Mock collector"),
+		WithRBAC([]string{""}, []string{"pods"}, []string{"list", "watch"}),
+	})
+
+	cfg := &InitConfig{
+		Ctx: context.Background(),
+		ConfigContent: []byte(`
+collectors:
+  mock:
+    interval: 5m
+    timeout: 10s
+  mock:fast:
+    interval: 30s
+`),
+		EnabledCollectors: []string{"mock", "mock:fast", "missing:one"},
+	}
+
+	r.createCollectors(cfg, "Testing")
+
+	expected := map[string]intervalConfig{
+		"mock":      {Interval: "5m", Timeout: "10s"},
+		"mock:fast": {Interval: "30s", Timeout: "10s"},
+	}
+
+	for name, want := range expected {
+		got, ok := configs[name]
+		if !ok {
+			t.Errorf("Expected instance %s to be created", name)
+			continue
+		}
+
+		if *got != want {
+			t.Errorf("Instance %s: expected config %+v, got %+v", name, want, *got)
+		}
+	}
+
+	instances := r.ListInstances()
+	if len(instances) != 3 {
+		t.Fatalf("Expected 3 instances, got %d", len(instances))
+	}
+
+	if instances[1].Type != "mock" || instances[1].ConfigKey != "collectors.mock:fast" {
+		t.Errorf("Unexpected instance info %+v", instances[1])
+	}
+
+	if instances[2].Error == "" {
+		t.Error("Expected instance of an unknown collector type to fail")
+	}
+
+	metadata, ok := r.GetMetadata("mock")
+	if !ok {
+		t.Fatal("Expected metadata of the mock collector")
+	}
+
+	if metadata.Version != DefaultVersion || metadata.ConfigKey != "collectors.mock" ||
+		len(metadata.RBAC) != 1 {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+}
+
+// TestConfigInstances tests that a collector type enabled without an instance
+// name is replaced by its configured instances
+func TestConfigInstances(t *testing.T) {
+	r := &Registry{
+		factories:        make(map[string]collector.Factory),
+		metadata:         make(map[string]Metadata),
+		collectors:       make(map[string]collector.Collector),
+		failedCollectors: make(map[string]error),
+	}
+
+	instances := make(map[string]string)
+
+	r.factories["mock"] = func(ctx *collector.FactoryContext) (collector.Collector, error) {
+		instances[ctx.Logger.Data["collector"].(string)] = ctx.Instance
+		return &mockCollector{name: "mock"}, nil
+	}
+	r.metadata["mock"] = newMetadata("mock", []Option{
+		WithConfigInstances(func(loader collector.ConfigLoader) ([]string, error) {
+			cfg := &struct {
+				Endpoints []struct {
+					Name string `yaml:"name"`
+				} `yaml:"endpoints"`
+			}{}
+			if err := loader.LoadModuleConfig("collectors.mock", cfg); err != nil {
+				return nil, err
+			}
+
+			var names []string
+			for _, endpoint := range cfg.Endpoints {
+				names = append(names, endpoint.Name)
+			}
+
+			return names, nil
+		}),
+	})
+
+	cfg := &InitConfig{
+		Ctx: context.Background(),
+		ConfigContent: []byte(`
+collectors:
+  mock:
+    endpoints:
+      - name: billing
+      - name: node-agent
+`),
+		EnabledCollectors: []string{"mock:node-agent", "mock"},
+	}
+
+	r.createCollectors(cfg, "Testing")
+
+	expected := map[string]string{"mock:node-agent": "node-agent", "mock:billing": "billing"}
+	if len(instances) != len(expected) {
+		t.Fatalf("Expected instances %v, got %v", expected, instances)
+	}
+
+	for name, instance := range expected {
+		if instances[name] != instance {
+			t.Errorf("Expected %s to be created as instance %q, got %q", name, instance, instances[name])
+		}
+	}
+
+	listed := r.ListInstances()
+	if len(listed) != 2 || listed[0].Name != "mock:node-agent" || listed[1].Name != "mock:billing" {
+		t.Errorf("Expected the enabled instances once in configuration order, got %+v", listed)
+	}
+
+	// Without configured instances, the default instance is created
+	r.collectors = make(map[string]collector.Collector)
+	clear(instances)
+
+	cfg.ConfigContent = nil
+	cfg.EnabledCollectors = []string{"mock"}
+	r.createCollectors(cfg, "Testing")
+
+	if instance, ok := instances["mock"]; len(instances) != 1 || !ok || instance != "" {
+		t.Errorf("Expected the default instance, got %v", instances)
+	}
+
+	// Instances that cannot be loaded fail the entry
+	r.collectors = make(map[string]collector.Collector)
+	clear(instances)
+
+	for _, endpoints := range []string{"[billing]", "[{name: billing}, {name: ''}]", "[{name: 'a:b'}]"} {
+		r.collectors = make(map[string]collector.Collector)
+		r.failedCollectors = make(map[string]error)
+
+		cfg.ConfigContent = []byte("collectors:\n  mock:\n    endpoints: " + endpoints + "\n")
+		r.createCollectors(cfg, "Testing")
+
+		if len(instances) != 0 || r.failedCollectors["mock"] == nil {
+			t.Errorf("Expected mock to fail with endpoints %s, got %v and %v", endpoints, instances, r.failedCollectors)
+		}
+	}
+}
+
+// TestPrometheusCollectorSharedDescriptors tests that instances of the same
+// collector type can be registered together
+func TestPrometheusCollectorSharedDescriptors(t *testing.T) {
+	r := &Registry{
+		collectors: make(map[string]collector.Collector),
+	}
+
+	desc := prometheus.NewDesc("test_series", "Test series", []string{"key"}, nil)
+	r.collectors["dup"] = &duplicateCollector{mockCollector: mockCollector{name: "dup"}, desc: desc}
+	r.collectors["dup:other"] = &duplicateCollector{mockCollector: mockCollector{name: "dup"}, desc: desc}
+
+	promRegistry := prometheus.NewPedanticRegistry()
+	if err := promRegistry.Register(NewPrometheusCollector(r, "test")); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+}
+
+// TestStandaloneSkipsKubernetesCollectors tests that only standalone collectors are created in standalone mode
+func TestStandaloneSkipsKubernetesCollectors(t *testing.T) {
+	r := &Registry{
+		factories:        make(map[string]collector.Factory),
+		metadata:         make(map[string]Metadata),
+		collectors:       make(map[string]collector.Collector),
+		failedCollectors: make(map[string]error),
+	}
+
+	for _, name := range []string{"cloud", "cluster"} {
+		r.factories[name] = func(ctx *collector.FactoryContext) (collector.Collector, error) {
+			if !ctx.Standalone {
+				t.Errorf("Expected standalone factory context for %s", name)
+			}
+
+			return &mockCollector{name: name}, nil
+		}
+	}
+
+	r.metadata["cloud"] = newMetadata("cloud", []Option{WithStandalone()})
+	r.metadata["cluster"] = newMetadata("cluster", nil)
+
+	r.createCollectors(&InitConfig{
+		Ctx:               context.Background(),
+		EnabledCollectors: []string{"cloud", "cluster"},
+		Standalone:        true,
+	}, "Testing")
+
+	if _, ok := r.collectors["cloud"]; !ok {
+		t.Error("Expected standalone collector to be created")
+	}
+
+	if _, ok := r.collectors["cluster"]; ok {
+		t.Error("Expected Kubernetes collector to be skipped")
+	}
+
+	if len(r.GetFailedCollectors()) != 0 {
+		t.Errorf("Expected skipped collectors not to be failed, got %v", r.GetFailedCollectors())
+	}
+
+	if instances := r.ListInstances(); instances[1].Skipped == "" {
+		t.Errorf("Expected skip reason, got %+v", instances[1])
+	}
+}
+
+func TestStartStopCollector(t *testing.T) {
+	leader := &lifecycleCollector{leader: true}
+	other := &lifecycleCollector{}
+
+	r := &Registry{collectors: map[string]collector.Collector{
+		"node":        leader,
+		"domain":      &lifecycleCollector{leader: true},
+		"imagepuller": other,
+	}}
+
+	names := r.LeaderCollectorNames()
+	if len(names) != 2 || names[0] != "domain" || names[1] != "node" {
+		t.Errorf("Expected leader collectors [domain node], got %v", names)
+	}
+
+	if err := r.StartCollector(context.Background(), "node"); err != nil {
+		t.Fatalf("Failed to start collector: %v", err)
+	}
+
+	if err := r.StopCollector("node"); err != nil {
+		t.Fatalf("Failed to stop collector: %v", err)
+	}
+
+	if leader.started != 1 || leader.stopped != 1 || other.started != 0 {
+		t.Error("Expected only the named collector to be started and stopped")
+	}
+
+	if err := r.StartCollector(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error starting an unknown collector")
+	}
+}
@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// ChangedCollectors returns the enabled collector instances whose
+// configuration in cfg differs from the one they were created with. The
+// second result is false when a setting shared by all collectors changed
+// (e.g. the metrics namespace, the enabled collectors, the instances
+// configured for an enabled collector type or the maintenance windows), in
+// which case every collector has to be recreated.
+//
+// Sections are compared with their valueFrom references resolved, so a
+// rotated secret file changes the configuration of the collectors using it.
+func (r *Registry) ChangedCollectors(cfg *InitConfig) ([]string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.initConfig == nil || !sameSharedConfig(r.initConfig, cfg) {
+		return nil, false
+	}
+
+	loader := config.NewModuleConfigLoader(cfg.ConfigContent)
+
+	if !reflect.DeepEqual(r.sections[maintenanceSection], loadSection(loader, maintenanceSection)) {
+		return nil, false
+	}
+
+	enabled, failed := r.expandInstances(newConfigLoader(cfg.ConfigContent), cfg.EnabledCollectors)
+	if len(failed) > 0 || !slices.Equal(enabled, r.enabled) {
+		return nil, false
+	}
+
+	var changed []string
+
+	for _, name := range enabled {
+		if !reflect.DeepEqual(r.sections[name], r.instanceSections(loader, name)) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed, true
+}
+
+// maintenanceSection is the configuration section of the maintenance windows
+const maintenanceSection = "maintenance"
+
+// recordSections records the resolved configuration sections the collectors
+// of cfg are created with, compared by ChangedCollectors.
+// Must be called with r.mu held
+func (r *Registry) recordSections(cfg *InitConfig, names []string) {
+	loader := config.NewModuleConfigLoader(cfg.ConfigContent)
+
+	r.initConfig = cfg
+	if r.sections == nil {
+		r.sections = make(map[string]map[string]any)
+	}
+
+	r.sections[maintenanceSection] = loadSection(loader, maintenanceSection)
+
+	for _, name := range names {
+		r.sections[name] = r.instanceSections(loader, name)
+	}
+}
+
+// instanceSections returns the collector type and instance sections of a
+// collector instance.
+// Must be called with r.mu held
+func (r *Registry) instanceSections(loader *config.ModuleConfigLoader, name string) map[string]any {
+	collectorType, instance := ParseInstanceName(name)
+
+	configKey := "collectors." + collectorType
+	if metadata, ok := r.metadata[collectorType]; ok {
+		configKey = metadata.ConfigKey
+	}
+
+	sections := map[string]any{"type": loadSection(loader, configKey)}
+	if instance != "" {
+		sections["instance"] = loadSection(loader, configKey+InstanceSeparator+instance)
+	}
+
+	return sections
+}
+
+// loadSection returns a configuration section with its valueFrom references
+// resolved. Sections that cannot be decoded are returned as their error, so
+// they differ from any decoded section.
+func loadSection(loader *config.ModuleConfigLoader, key string) map[string]any {
+	var section map[string]any
+	if err := loader.LoadModuleConfig(key, &section); err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	return section
+}
+
+// sameSharedConfig returns whether the settings passed to every collector are equal
+func sameSharedConfig(a, b *InitConfig) bool {
+	return a.Identity == b.Identity &&
+		a.NodeName == b.NodeName &&
+		a.PodName == b.PodName &&
+		a.MetricsNamespace == b.MetricsNamespace &&
+		a.InformerResyncPeriod == b.InformerResyncPeriod &&
+		a.CollectionTimeout == b.CollectionTimeout &&
+		a.StaleWatchTimeout == b.StaleWatchTimeout &&
+		a.EventQueueWorkers == b.EventQueueWorkers &&
+		a.EventQueueMaxDepth == b.EventQueueMaxDepth &&
+		slices.Equal(a.EnabledCollectors, b.EnabledCollectors) &&
+		a.Standalone == b.Standalone &&
+		a.Cluster == b.Cluster &&
+		a.LegacyMetricsNamespace == b.LegacyMetricsNamespace &&
+		slices.Equal(a.LegacyMetricsCollectors, b.LegacyMetricsCollectors) &&
+		a.LegacyMetricsUntil.Equal(b.LegacyMetricsUntil)
+}
+
+// Restart recreates the named collector instances from cfg and starts them,
+// leaving the other instances running. Instances requiring leader election
+// are only stopped and started when isLeader returns true for their name.
+// prepare (optional) is called with every recreated instance before it is
+// started.
+func (r *Registry) Restart(
+	cfg *InitConfig,
+	names []string,
+	isLeader func(name string) bool,
+	prepare func(c collector.Collector),
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger := log.WithField("module", "registry")
+	logger.WithField("collectors", names).Info("Restarting collectors")
+
+	if r.skipped == nil {
+		r.skipped = make(map[string]string)
+	}
+
+	configLoader := newConfigLoader(cfg.ConfigContent)
+	r.recordSections(cfg, names)
+
+	var errs []error
+
+	for _, name := range names {
+		if c, ok := r.collectors[name]; ok && (!c.RequiresLeaderElection() || isLeader(name)) {
+			if err := c.Stop(); err != nil {
+				logger.WithError(err).WithField("name", name).Warn("Failed to stop collector")
+			}
+		}
+
+		delete(r.collectors, name)
+		delete(r.failedCollectors, name)
+		delete(r.skipped, name)
+
+		r.createCollector(cfg, configLoader, name, logger)
+
+		c, ok := r.collectors[name]
+		if !ok {
+			continue
+		}
+
+		if prepare != nil {
+			prepare(c)
+		}
+
+		if c.RequiresLeaderElection() && !isLeader(name) {
+			logger.WithField("name", name).Info("Collector recreated, started once this instance leads")
+			continue
+		}
+
+		if err := c.Start(cfg.Ctx); err != nil {
+			errs = append(errs, err)
+			logger.WithError(err).WithField("name", name).Error("Failed to start collector")
+
+			continue
+		}
+
+		logger.WithField("name", name).Info("Collector restarted")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to restart %d collector(s): %v", len(errs), errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+//nolint:testpackage
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// lifecycleCollector is a mock collector recording its lifecycle
+type lifecycleCollector struct {
+	mockCollector
+
+	leader  bool
+	started int
+	stopped int
+}
+
+func (c *lifecycleCollector) RequiresLeaderElection() bool { return c.leader }
+
+func (c *lifecycleCollector) Start(context.Context) error {
+	c.started++
+	return nil
+}
+
+func (c *lifecycleCollector) Stop() error {
+	c.stopped++
+	return nil
+}
+
+func TestChangedCollectors(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secret, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Registry{metadata: map[string]Metadata{
+		"domain": newMetadata("domain", nil),
+		"node":   newMetadata("node", nil),
+		"probe":  newMetadata("probe", nil),
+	}}
+
+	base := `
+collectors:
+  domain:
+    checkInterval: 5m
+  node:
+    token:
+      valueFrom:
+        file: ` + secret + `
+  probe:public:
+    url: https://example.com
+`
+	enabled := []string{"domain", "node", "probe:public"}
+	cfg := &InitConfig{ConfigContent: []byte(base), EnabledCollectors: enabled, MetricsNamespace: "sealos"}
+
+	r.enabled = enabled
+	r.recordSections(cfg, enabled)
+
+	if changed, ok := r.ChangedCollectors(cfg); !ok || len(changed) != 0 {
+		t.Errorf("Expected no change, got %v (%v)", changed, ok)
+	}
+
+	// A collector type section applies to its named instances
+	newCfg := *cfg
+	newCfg.ConfigContent = []byte(base + "  probe:\n    timeout: 3s\n")
+
+	changed, ok := r.ChangedCollectors(&newCfg)
+	if !ok || !reflect.DeepEqual(changed, []string{"probe:public"}) {
+		t.Errorf("Expected probe:public to change, got %v (%v)", changed, ok)
+	}
+
+	// A rotated valueFrom file changes the section using it, the content being the same
+	if err := os.WriteFile(secret, []byte("v2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, ok = r.ChangedCollectors(cfg)
+	if !ok || !reflect.DeepEqual(changed, []string{"node"}) {
+		t.Errorf("Expected node to change after the rotation, got %v (%v)", changed, ok)
+	}
+
+	newCfg = *cfg
+	newCfg.MetricsNamespace = "sealos_v2"
+
+	if _, ok := r.ChangedCollectors(&newCfg); ok {
+		t.Error("Expected a shared setting change to require a full restart")
+	}
+
+	newCfg = *cfg
+	newCfg.ConfigContent = []byte(base + "maintenance:\n  windows: []\n")
+
+	if _, ok := r.ChangedCollectors(&newCfg); ok {
+		t.Error("Expected a maintenance change to require a full restart")
+	}
+}
+
+func TestChangedCollectorsConfigInstances(t *testing.T) {
+	r := &Registry{metadata: map[string]Metadata{
+		"plugin": newMetadata("plugin", []Option{
+			WithConfigInstances(func(loader collector.ConfigLoader) ([]string, error) {
+				cfg := &struct {
+					Names []string `yaml:"names"`
+				}{}
+				err := loader.LoadModuleConfig("collectors.plugin", cfg)
+
+				return cfg.Names, err
+			}),
+		}),
+	}}
+
+	base := `
+collectors:
+  plugin:
+    names: [billing]
+`
+	enabled := []string{"plugin:billing"}
+	cfg := &InitConfig{ConfigContent: []byte(base), EnabledCollectors: []string{"plugin"}}
+
+	r.enabled = enabled
+	r.recordSections(cfg, enabled)
+
+	if changed, ok := r.ChangedCollectors(cfg); !ok || len(changed) != 0 {
+		t.Errorf("Expected no change, got %v (%v)", changed, ok)
+	}
+
+	newCfg := *cfg
+	newCfg.ConfigContent = []byte(base + "  plugin:billing:\n    address: billing:9100\n")
+
+	changed, ok := r.ChangedCollectors(&newCfg)
+	if !ok || !reflect.DeepEqual(changed, []string{"plugin:billing"}) {
+		t.Errorf("Expected plugin:billing to change, got %v (%v)", changed, ok)
+	}
+
+	newCfg.ConfigContent = []byte("collectors:\n  plugin:\n    names: [billing, node-agent]\n")
+
+	if _, ok := r.ChangedCollectors(&newCfg); ok {
+		t.Error("Expected a new instance to require a full restart")
+	}
+}
+
+func TestRestart(t *testing.T) {
+	created := make(map[string]*lifecycleCollector)
+
+	factory := func(leader bool) collector.Factory {
+		return func(ctx *collector.FactoryContext) (collector.Collector, error) {
+			c := &lifecycleCollector{leader: leader}
+			created[ctx.Logger.Data["collector"].(string)] = c
+
+			return c, nil
+		}
+	}
+
+	old := &lifecycleCollector{}
+	oldLeader := &lifecycleCollector{leader: true}
+	untouched := &lifecycleCollector{}
+
+	r := &Registry{
+		factories: map[string]collector.Factory{"domain": factory(false), "node": factory(true)},
+		metadata: map[string]Metadata{
+			"domain": newMetadata("domain", nil),
+			"node":   newMetadata("node", nil),
+		},
+		collectors: map[string]collector.Collector{
+			"domain": old,
+			"node":   oldLeader,
+			"pod":    untouched,
+		},
+		failedCollectors: make(map[string]error),
+	}
+
+	cfg := &InitConfig{Ctx: context.Background(), EnabledCollectors: []string{"domain", "node", "pod"}}
+	prepared := 0
+
+	err := r.Restart(cfg, []string{"domain", "node"}, func(string) bool { return false }, func(collector.Collector) {
+		prepared++
+	})
+	if err != nil {
+		t.Fatalf("Failed to restart collectors: %v", err)
+	}
+
+	if old.stopped != 1 || created["domain"].started != 1 {
+		t.Error("Expected the changed collector to be stopped and recreated")
+	}
+
+	// Leader collectors are neither stopped nor started on a follower
+	if oldLeader.stopped != 0 || created["node"].started != 0 {
+		t.Error("Expected the leader collector not to run on a follower")
+	}
+
+	if r.collectors["node"] != created["node"] {
+		t.Error("Expected the leader collector to be replaced")
+	}
+
+	if untouched.stopped != 0 || r.collectors["pod"] != untouched {
+		t.Error("Expected the unchanged collector to keep running")
+	}
+
+	if prepared != 2 {
+		t.Errorf("Expected 2 prepared collectors, got %d", prepared)
+	}
+}
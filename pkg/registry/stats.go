@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"maps"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// recordCollections records the results of the collections of a scrape
+// finished at now
+func (r *Registry) recordCollections(results []collectorResult, now time.Time) {
+	r.collectionsMu.Lock()
+	defer r.collectionsMu.Unlock()
+
+	if r.collections == nil {
+		r.collections = make(map[string]collector.CollectionStats)
+	}
+
+	for _, result := range results {
+		stats := r.collections[result.name]
+		stats.Duration = result.duration
+
+		if result.success {
+			stats.LastSuccess = now
+		}
+
+		r.collections[result.name] = stats
+	}
+}
+
+// CollectionStats returns the stats of the last collections, keyed by
+// instance name. Collectors not collected since the start are missing.
+func (r *Registry) CollectionStats() map[string]collector.CollectionStats {
+	r.collectionsMu.Lock()
+	defer r.collectionsMu.Unlock()
+
+	return maps.Clone(r.collections)
+}
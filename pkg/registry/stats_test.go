@@ -0,0 +1,49 @@
+//nolint:testpackage // Tests need access to private functions
+package registry
+
+import (
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// panickingCollector panics on every collection
+type panickingCollector struct {
+	mockCollector
+}
+
+func (p *panickingCollector) Collect(chan<- prometheus.Metric) { panic("collect") }
+
+func TestCollectionStats(t *testing.T) {
+	r := &Registry{
+		collectors: map[string]collector.Collector{
+			"ok":    &mockCollector{name: "ok"},
+			"panic": &panickingCollector{mockCollector{name: "panic"}},
+		},
+	}
+
+	if stats := r.CollectionStats(); len(stats) != 0 {
+		t.Errorf("Expected no stats before the first scrape, got %v", stats)
+	}
+
+	promRegistry := prometheus.NewRegistry()
+	promRegistry.MustRegister(NewPrometheusCollector(r, "test"))
+
+	if _, err := promRegistry.Gather(); err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	stats := r.CollectionStats()
+	if len(stats) != 2 {
+		t.Fatalf("Expected stats of 2 collectors, got %v", stats)
+	}
+
+	if stats["ok"].LastSuccess.IsZero() {
+		t.Error("Expected a last success for the working collector")
+	}
+
+	if !stats["panic"].LastSuccess.IsZero() {
+		t.Error("Expected no last success for the panicking collector")
+	}
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// stalePollIntervals is the number of polling intervals without a successful
+// poll after which a polling collector is down
+const stalePollIntervals = 3
+
+// collectorUp returns whether a collector works, and false for running when
+// it is not expected to run on this instance (e.g. leader collectors on
+// followers). A collector is down when its start failed, when its informers
+// stopped being synced after the initial sync, or when no poll succeeded for
+// stalePollIntervals intervals.
+func collectorUp(c collector.Collector, liveness collector.Liveness, now time.Time) (up, running bool) {
+	if liveness.StartError != nil {
+		return false, true
+	}
+
+	if !liveness.Started {
+		return false, false
+	}
+
+	if informer, ok := c.(collector.InformerCollector); ok && liveness.Ready && !informer.HasSynced() {
+		return false, true
+	}
+
+	if poller, ok := c.(collector.PollingCollector); ok && poller.Interval() > 0 {
+		since := liveness.LastPollSuccess
+		if since.IsZero() {
+			since = liveness.StartedAt
+		}
+
+		if now.Sub(since) > stalePollIntervals*poller.Interval() {
+			return false, true
+		}
+	}
+
+	return true, true
+}
+
+// emitCollectorUp emits whether each collector running on this instance works
+func (pc *PrometheusCollector) emitCollectorUp(
+	collectors map[string]collector.Collector,
+	now time.Time,
+	instance string,
+	ch chan<- prometheus.Metric,
+) {
+	for name, c := range collectors {
+		reporter, ok := c.(collector.LivenessReporter)
+		if !ok {
+			continue
+		}
+
+		up, running := collectorUp(c, reporter.Liveness(), now)
+		if !running {
+			continue
+		}
+
+		value := 0.0
+		if up {
+			value = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			pc.collectorUp,
+			prometheus.GaugeValue,
+			value,
+			name,
+			instance,
+		)
+	}
+}
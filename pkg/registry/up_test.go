@@ -0,0 +1,117 @@
+//nolint:testpackage
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// pollingCollector is a mock polling collector
+type pollingCollector struct {
+	mockCollector
+
+	synced   bool
+	interval time.Duration
+}
+
+func (c *pollingCollector) HasSynced() bool                { return c.synced }
+func (c *pollingCollector) Interval() time.Duration        { return c.interval }
+func (c *pollingCollector) Poll(ctx context.Context) error { return nil }
+
+// informerCollector is a mock informer collector
+type informerCollector struct {
+	mockCollector
+
+	synced bool
+}
+
+func (c *informerCollector) HasSynced() bool { return c.synced }
+
+func TestCollectorUp(t *testing.T) {
+	now := time.Now()
+	started := collector.Liveness{Started: true, Ready: true, StartedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name            string
+		collector       collector.Collector
+		liveness        collector.Liveness
+		expectedUp      bool
+		expectedRunning bool
+	}{
+		{
+			name:            "start failed",
+			collector:       &informerCollector{synced: true},
+			liveness:        collector.Liveness{StartError: errors.New("forbidden")},
+			expectedRunning: true,
+		},
+		{
+			name:      "not running on this instance",
+			collector: &informerCollector{synced: true},
+			liveness:  collector.Liveness{},
+		},
+		{
+			name:            "informer synced",
+			collector:       &informerCollector{synced: true},
+			liveness:        started,
+			expectedUp:      true,
+			expectedRunning: true,
+		},
+		{
+			name:            "informer still syncing",
+			collector:       &informerCollector{},
+			liveness:        collector.Liveness{Started: true, StartedAt: now},
+			expectedUp:      true,
+			expectedRunning: true,
+		},
+		{
+			name:            "informer sync regressed",
+			collector:       &informerCollector{},
+			liveness:        started,
+			expectedRunning: true,
+		},
+		{
+			name:      "recent poll",
+			collector: &pollingCollector{synced: true, interval: time.Minute},
+			liveness: collector.Liveness{
+				Started: true, Ready: true, StartedAt: now.Add(-time.Hour), LastPollSuccess: now.Add(-2 * time.Minute),
+			},
+			expectedUp:      true,
+			expectedRunning: true,
+		},
+		{
+			name:      "stale poll",
+			collector: &pollingCollector{synced: true, interval: time.Minute},
+			liveness: collector.Liveness{
+				Started: true, Ready: true, StartedAt: now.Add(-time.Hour), LastPollSuccess: now.Add(-4 * time.Minute),
+			},
+			expectedRunning: true,
+		},
+		{
+			name:            "no poll succeeded since start",
+			collector:       &pollingCollector{synced: true, interval: time.Minute},
+			liveness:        collector.Liveness{Started: true, StartedAt: now.Add(-4 * time.Minute)},
+			expectedRunning: true,
+		},
+		{
+			name:            "first poll pending",
+			collector:       &pollingCollector{synced: true, interval: time.Minute},
+			liveness:        collector.Liveness{Started: true, StartedAt: now.Add(-time.Minute)},
+			expectedUp:      true,
+			expectedRunning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, running := collectorUp(tt.collector, tt.liveness, now)
+			if up != tt.expectedUp || running != tt.expectedRunning {
+				t.Errorf("Expected up=%v running=%v, got up=%v running=%v",
+					tt.expectedUp, tt.expectedRunning, up, running)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+// Package sampling dumps the raw objects seen by collectors to the log while a
+// debug sampling window is active, to investigate unexpected metric values
+// without direct access to tenant namespaces
+package sampling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// DefaultDuration is the sampling window used when none is requested
+	DefaultDuration = 5 * time.Minute
+	// MaxDuration bounds a sampling window
+	MaxDuration = time.Hour
+	// DefaultLimit is the number of objects logged per rule when none is requested
+	DefaultLimit = 100
+
+	redacted = "[REDACTED]"
+)
+
+// sensitiveKeys mark string fields to redact (case-insensitive substring of the field name)
+var sensitiveKeys = []string{"password", "secret", "token", "credential", "privatekey"}
+
+// Rule selects the objects to sample
+type Rule struct {
+	// Collector matches the collector name, or the name prefix for dynamic
+	// collectors (e.g. "dynamic" matches "dynamic-app"). Empty matches all.
+	Collector string `json:"collector,omitempty"`
+	// Namespace of the sampled objects. Empty matches all.
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the sampled objects. Empty matches all.
+	Name string `json:"name,omitempty"`
+	// LabelSelector filters the sampled objects by label
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// Duration of the sampling window
+	Duration time.Duration `json:"-"`
+	// Limit is the maximum number of objects logged
+	Limit int `json:"limit"`
+}
+
+// Session is an active sampling rule
+type Session struct {
+	ID        int       `json:"id"`
+	Rule      Rule      `json:"rule"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Sampled   int       `json:"sampled"`
+
+	selector labels.Selector
+}
+
+// Sampler holds the active sampling sessions
+type Sampler struct {
+	active atomic.Bool
+
+	mu       sync.Mutex
+	sessions map[int]*Session
+	nextID   int
+	now      func() time.Time
+}
+
+var (
+	defaultSampler *Sampler
+	once           sync.Once
+)
+
+// Default returns the process-wide sampler used by collectors
+func Default() *Sampler {
+	once.Do(func() {
+		defaultSampler = NewSampler()
+	})
+
+	return defaultSampler
+}
+
+// NewSampler creates a sampler without active sessions
+func NewSampler() *Sampler {
+	return &Sampler{
+		sessions: make(map[int]*Session),
+		nextID:   1,
+		now:      time.Now,
+	}
+}
+
+// Sample logs obj if it matches an active session. It is a no-op unless
+// sampling is enabled, so collectors can call it on every informer event.
+func Sample(collectorName string, obj any) {
+	Default().Sample(collectorName, obj)
+}
+
+// Enable starts a sampling session and returns it
+func (s *Sampler) Enable(rule Rule) (Session, error) {
+	if rule.Duration <= 0 {
+		rule.Duration = DefaultDuration
+	}
+
+	if rule.Duration > MaxDuration {
+		return Session{}, fmt.Errorf("duration %s exceeds maximum of %s", rule.Duration, MaxDuration)
+	}
+
+	if rule.Limit <= 0 {
+		rule.Limit = DefaultLimit
+	}
+
+	selector := labels.Everything()
+	if rule.LabelSelector != "" {
+		parsed, err := labels.Parse(rule.LabelSelector)
+		if err != nil {
+			return Session{}, fmt.Errorf("invalid label selector: %w", err)
+		}
+
+		selector = parsed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := &Session{
+		ID:        s.nextID,
+		Rule:      rule,
+		ExpiresAt: s.now().Add(rule.Duration),
+		selector:  selector,
+	}
+	s.nextID++
+	s.sessions[session.ID] = session
+	s.active.Store(true)
+
+	log.WithFields(log.Fields{
+		"id":            session.ID,
+		"collector":     rule.Collector,
+		"namespace":     rule.Namespace,
+		"name":          rule.Name,
+		"labelSelector": rule.LabelSelector,
+		"expiresAt":     session.ExpiresAt,
+		"limit":         rule.Limit,
+	}).Info("Debug sampling enabled")
+
+	return *session, nil
+}
+
+// Disable stops the session with the given ID, or all sessions when id is 0
+func (s *Sampler) Disable(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == 0 {
+		clear(s.sessions)
+	} else {
+		if _, ok := s.sessions[id]; !ok {
+			return errors.New("sampling session not found")
+		}
+
+		delete(s.sessions, id)
+	}
+
+	s.active.Store(len(s.sessions) > 0)
+
+	log.WithField("id", id).Info("Debug sampling disabled")
+
+	return nil
+}
+
+// Sessions returns the active sampling sessions
+func (s *Sampler) Sessions() []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expireLocked()
+
+	sessions := make([]Session, 0, len(s.sessions))
+	for id := 1; id < s.nextID; id++ {
+		if session, ok := s.sessions[id]; ok {
+			sessions = append(sessions, *session)
+		}
+	}
+
+	return sessions
+}
+
+// Sample logs the redacted JSON of obj for every active session it matches
+func (s *Sampler) Sample(collectorName string, obj any) {
+	if !s.active.Load() {
+		return
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.expireLocked()
+
+	var matched []int
+
+	for _, session := range s.sessions {
+		if session.Sampled >= session.Rule.Limit || !session.matches(collectorName, accessor) {
+			continue
+		}
+
+		session.Sampled++
+		matched = append(matched, session.ID)
+	}
+	s.mu.Unlock()
+
+	if len(matched) == 0 {
+		return
+	}
+
+	content, err := redactedJSON(obj)
+	if err != nil {
+		log.WithError(err).Debug("Failed to encode sampled object")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"component": "sampling",
+		"sessions":  matched,
+		"collector": collectorName,
+		"namespace": accessor.GetNamespace(),
+		"name":      accessor.GetName(),
+		"object":    content,
+	}).Info("Sampled object")
+}
+
+// expireLocked drops expired sessions. Must be called with s.mu held.
+func (s *Sampler) expireLocked() {
+	now := s.now()
+
+	for id, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, id)
+			log.WithFields(log.Fields{
+				"id":      id,
+				"sampled": session.Sampled,
+			}).Info("Debug sampling expired")
+		}
+	}
+
+	s.active.Store(len(s.sessions) > 0)
+}
+
+// matches returns whether an object seen by the named collector is selected by the session
+func (session *Session) matches(collectorName string, obj metav1.Object) bool {
+	rule := session.Rule
+
+	if rule.Collector != "" && collectorName != rule.Collector &&
+		!strings.HasPrefix(collectorName, rule.Collector+"-") {
+		return false
+	}
+
+	if rule.Namespace != "" && obj.GetNamespace() != rule.Namespace {
+		return false
+	}
+
+	if rule.Name != "" && obj.GetName() != rule.Name {
+		return false
+	}
+
+	return session.selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// redactedJSON encodes obj with managed fields, the last-applied annotation,
+// secret data, environment values and sensitive-looking fields removed
+func redactedJSON(obj any) (string, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return "", err
+	}
+
+	if metadata, ok := data["metadata"].(map[string]any); ok {
+		delete(metadata, "managedFields")
+
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		}
+	}
+
+	for _, key := range []string{"data", "stringData", "binaryData"} {
+		if _, ok := data[key]; ok {
+			data[key] = redacted
+		}
+	}
+
+	redact(data)
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// redact replaces sensitive values in place
+func redact(data any) {
+	switch v := data.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if _, ok := value.(string); ok && isSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+
+			// Container environment variables: keep names, drop literal values
+			if key == "env" {
+				redactEnv(value)
+				continue
+			}
+
+			redact(value)
+		}
+	case []any:
+		for _, value := range v {
+			redact(value)
+		}
+	}
+}
+
+// redactEnv drops the literal values of container environment variables
+func redactEnv(env any) {
+	vars, ok := env.([]any)
+	if !ok {
+		return
+	}
+
+	for _, item := range vars {
+		if envVar, ok := item.(map[string]any); ok {
+			if _, ok := envVar["value"]; ok {
+				envVar["value"] = redacted
+			}
+		}
+	}
+}
+
+// isSensitive returns whether a field name looks like it holds a credential.
+// References to credentials (secretName, secretKeyRef, ...) are kept.
+func isSensitive(key string) bool {
+	if strings.HasSuffix(key, "Name") || strings.HasSuffix(key, "Ref") {
+		return false
+	}
+
+	lower := strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(lower, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+//nolint:testpackage // Tests need access to private functions
+package sampling
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSampler_Matching(t *testing.T) {
+	now := time.Now()
+	s := NewSampler()
+	s.now = func() time.Time { return now }
+
+	session, err := s.Enable(Rule{
+		Collector:     "dynamic",
+		Namespace:     "ns-a",
+		LabelSelector: "app=web",
+		Limit:         2,
+	})
+	if err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+
+	pod := func(namespace, app string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "p",
+			Namespace: namespace,
+			Labels:    map[string]string{"app": app},
+		}}
+	}
+
+	s.Sample("dynamic-app", pod("ns-a", "web"))
+	s.Sample("dynamicother", pod("ns-a", "web"))
+	s.Sample("dynamic-app", pod("ns-b", "web"))
+	s.Sample("dynamic-app", pod("ns-a", "api"))
+
+	if got := s.Sessions()[0].Sampled; got != 1 {
+		t.Errorf("Expected 1 sampled object, got %d", got)
+	}
+
+	for range 3 {
+		s.Sample("dynamic", pod("ns-a", "web"))
+	}
+
+	if got := s.Sessions()[0].Sampled; got != 2 {
+		t.Errorf("Expected sampling to stop at the limit, got %d", got)
+	}
+
+	now = session.ExpiresAt.Add(time.Second)
+
+	if len(s.Sessions()) != 0 {
+		t.Error("Expected session to expire")
+	}
+
+	if s.active.Load() {
+		t.Error("Expected sampler to be inactive after expiry")
+	}
+}
+
+func TestSampler_Enable(t *testing.T) {
+	s := NewSampler()
+
+	if _, err := s.Enable(Rule{Duration: 2 * time.Hour}); err == nil {
+		t.Error("Expected error for duration above maximum")
+	}
+
+	if _, err := s.Enable(Rule{LabelSelector: "app in ("}); err == nil {
+		t.Error("Expected error for invalid label selector")
+	}
+
+	session, err := s.Enable(Rule{})
+	if err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+
+	if session.Rule.Limit != DefaultLimit || session.Rule.Duration != DefaultDuration {
+		t.Errorf("Expected defaults, got limit %d duration %s", session.Rule.Limit, session.Rule.Duration)
+	}
+
+	if err := s.Disable(session.ID + 1); err == nil {
+		t.Error("Expected error when disabling an unknown session")
+	}
+
+	if err := s.Disable(0); err != nil || len(s.Sessions()) != 0 {
+		t.Errorf("Expected all sessions to be disabled, err = %v", err)
+	}
+}
+
+func TestRedactedJSON(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web",
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{\"spec\":{}}",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "app",
+				Env: []corev1.EnvVar{
+					{Name: "DB_URL", Value: "postgres://user:pass@db"},
+					{Name: "API_KEY", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "api"},
+							Key:                  "key",
+						},
+					}},
+				},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "tls",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: "tls-cert"},
+				},
+			}},
+		},
+	}
+
+	content, err := redactedJSON(pod)
+	if err != nil {
+		t.Fatalf("redactedJSON() error = %v", err)
+	}
+
+	for _, leaked := range []string{"postgres://", "last-applied-configuration", "managedFields"} {
+		if strings.Contains(content, leaked) {
+			t.Errorf("Expected %q to be redacted, got %s", leaked, content)
+		}
+	}
+
+	for _, kept := range []string{`"DB_URL"`, `"secretKeyRef"`, `"tls-cert"`} {
+		if !strings.Contains(content, kept) {
+			t.Errorf("Expected %s to be kept, got %s", kept, content)
+		}
+	}
+}
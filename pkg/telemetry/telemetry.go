@@ -0,0 +1,206 @@
+// Package telemetry exposes the self-telemetry of the exporter: how each
+// collector is collected, polled and fed by its informers, and the
+// goroutines and memory of the process. It tells a collector silently stuck
+// (no successful poll, watch errors, no informer events) from one that works.
+package telemetry
+
+import (
+	"runtime"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// subsystem prefixes all self-telemetry metrics
+const subsystem = "exporter"
+
+// Source provides the running collectors and the stats of their last
+// collections (implemented by the registry)
+type Source interface {
+	// GetAllCollectors returns the collectors, keyed by instance name
+	GetAllCollectors() map[string]collector.Collector
+	// CollectionStats returns the stats of the last collections, keyed by
+	// instance name
+	CollectionStats() map[string]collector.CollectionStats
+}
+
+// telemetryCollector exposes the self-telemetry of the exporter
+type telemetryCollector struct {
+	source Source
+
+	// Collector metrics
+	scrapeDuration    *prometheus.Desc
+	lastScrapeSuccess *prometheus.Desc
+	lastPollSuccess   *prometheus.Desc
+	goroutines        *prometheus.Desc
+
+	// Informer metrics
+	informerEvents *prometheus.Desc
+	watchErrors    *prometheus.Desc
+	cacheObjects   *prometheus.Desc
+
+	// Process metrics
+	processGoroutines *prometheus.Desc
+	heapAlloc         *prometheus.Desc
+	memorySys         *prometheus.Desc
+	gcCycles          *prometheus.Desc
+}
+
+// NewCollector returns a collector exposing the self-telemetry of the
+// exporter under <namespace>_exporter_*
+func NewCollector(namespace string, source Source) prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+	}
+
+	return &telemetryCollector{
+		source: source,
+		scrapeDuration: desc(
+			"collector_scrape_duration_seconds",
+			"Duration of the last collection of the collector during a scrape",
+			"collector",
+		),
+		lastScrapeSuccess: desc(
+			"collector_last_scrape_success_timestamp_seconds",
+			"Unix time of the last collection of the collector that did not panic",
+			"collector",
+		),
+		lastPollSuccess: desc(
+			"collector_last_poll_success_timestamp_seconds",
+			"Unix time of the last successful poll cycle of the collector since it started (polling collectors only)",
+			"collector",
+		),
+		goroutines: desc(
+			"collector_goroutines",
+			"Number of goroutines started by the collector",
+			"collector",
+		),
+		informerEvents: desc(
+			"informer_events_total",
+			"Number of informer notifications handled by the collector, per event type (add, update, delete)",
+			"collector", "event",
+		),
+		watchErrors: desc(
+			"informer_watch_errors_total",
+			"Number of failed informer watches of the collector, other than closed or expired watches",
+			"collector",
+		),
+		cacheObjects: desc(
+			"informer_cache_objects",
+			"Number of objects cached by the informers of the collector, per resource",
+			"collector", "resource",
+		),
+		processGoroutines: desc(
+			"goroutines",
+			"Number of goroutines of the exporter",
+		),
+		heapAlloc: desc(
+			"memory_heap_alloc_bytes",
+			"Bytes of allocated heap objects of the exporter",
+		),
+		memorySys: desc(
+			"memory_sys_bytes",
+			"Bytes of memory obtained from the OS by the exporter",
+		),
+		gcCycles: desc(
+			"gc_cycles_total",
+			"Number of completed garbage collection cycles of the exporter",
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (t *telemetryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.scrapeDuration
+	ch <- t.lastScrapeSuccess
+	ch <- t.lastPollSuccess
+	ch <- t.goroutines
+	ch <- t.informerEvents
+	ch <- t.watchErrors
+	ch <- t.cacheObjects
+	ch <- t.processGoroutines
+	ch <- t.heapAlloc
+	ch <- t.memorySys
+	ch <- t.gcCycles
+}
+
+// Collect implements prometheus.Collector
+func (t *telemetryCollector) Collect(ch chan<- prometheus.Metric) {
+	collections := t.source.CollectionStats()
+	goroutines := collector.GoroutinesByCollector()
+
+	for name, c := range t.source.GetAllCollectors() {
+		ch <- prometheus.MustNewConstMetric(t.goroutines, prometheus.GaugeValue, float64(goroutines[name]), name)
+
+		if stats, ok := collections[name]; ok {
+			t.collectCollection(ch, name, stats)
+		}
+
+		if reporter, ok := c.(collector.LivenessReporter); ok {
+			if success := reporter.Liveness().LastPollSuccess; !success.IsZero() {
+				ch <- prometheus.MustNewConstMetric(
+					t.lastPollSuccess,
+					prometheus.GaugeValue,
+					float64(success.UnixNano())/1e9,
+					name,
+				)
+			}
+		}
+
+		if reporter, ok := c.(collector.RuntimeReporter); ok {
+			if stats := reporter.RuntimeStats(); stats.Instrumented {
+				t.collectInformers(ch, name, &stats)
+			}
+		}
+	}
+
+	t.collectProcess(ch)
+}
+
+// collectCollection emits the duration and last success of the collections
+// of a collector
+func (t *telemetryCollector) collectCollection(
+	ch chan<- prometheus.Metric,
+	name string,
+	stats collector.CollectionStats,
+) {
+	ch <- prometheus.MustNewConstMetric(t.scrapeDuration, prometheus.GaugeValue, stats.Duration.Seconds(), name)
+
+	if !stats.LastSuccess.IsZero() {
+		ch <- prometheus.MustNewConstMetric(
+			t.lastScrapeSuccess,
+			prometheus.GaugeValue,
+			float64(stats.LastSuccess.UnixNano())/1e9,
+			name,
+		)
+	}
+}
+
+// collectInformers emits the informer events, watch errors and cache sizes
+// of a collector
+func (t *telemetryCollector) collectInformers(
+	ch chan<- prometheus.Metric,
+	name string,
+	stats *collector.RuntimeStats,
+) {
+	for event, count := range stats.HandlerEventsByType {
+		ch <- prometheus.MustNewConstMetric(t.informerEvents, prometheus.CounterValue, float64(count), name, event)
+	}
+
+	ch <- prometheus.MustNewConstMetric(t.watchErrors, prometheus.CounterValue, float64(stats.WatchErrors), name)
+
+	for resource, objects := range stats.CacheObjects {
+		ch <- prometheus.MustNewConstMetric(t.cacheObjects, prometheus.GaugeValue, float64(objects), name, resource)
+	}
+}
+
+// collectProcess emits the goroutine and memory stats of the process
+func (t *telemetryCollector) collectProcess(ch chan<- prometheus.Metric) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	ch <- prometheus.MustNewConstMetric(t.processGoroutines, prometheus.GaugeValue, float64(runtime.NumGoroutine()))
+	ch <- prometheus.MustNewConstMetric(t.heapAlloc, prometheus.GaugeValue, float64(memStats.HeapAlloc))
+	ch <- prometheus.MustNewConstMetric(t.memorySys, prometheus.GaugeValue, float64(memStats.Sys))
+	ch <- prometheus.MustNewConstMetric(t.gcCycles, prometheus.CounterValue, float64(memStats.NumGC))
+}
@@ -0,0 +1,102 @@
+package telemetry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
+	"github.com/labring/sealos-state-metrics/pkg/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeSource serves fixed collectors and collection stats
+type fakeSource struct {
+	collectors  map[string]collector.Collector
+	collections map[string]collector.CollectionStats
+}
+
+func (f *fakeSource) GetAllCollectors() map[string]collector.Collector { return f.collectors }
+
+func (f *fakeSource) CollectionStats() map[string]collector.CollectionStats { return f.collections }
+
+func TestCollect(t *testing.T) {
+	logger := log.NewEntry(log.StandardLogger())
+
+	informed := base.NewBaseCollector("informed", logger)
+	handler := informed.InstrumentHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) {},
+		DeleteFunc: func(any) {},
+	})
+	handler.OnAdd(nil, false)
+	handler.OnAdd(nil, false)
+	handler.OnDelete(nil)
+
+	lastSuccess := time.Unix(1700000000, 0)
+
+	source := &fakeSource{
+		collectors: map[string]collector.Collector{
+			"informed": informed,
+			"plain":    base.NewBaseCollector("plain", logger),
+		},
+		collections: map[string]collector.CollectionStats{
+			"informed": {Duration: 250 * time.Millisecond, LastSuccess: lastSuccess},
+			"plain":    {Duration: time.Second},
+		},
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(telemetry.NewCollector("sealos", source))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	// values maps each series to its value, keyed by name and label values
+	values := make(map[string]float64)
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range metric.GetLabel() {
+				key += "/" + label.GetValue()
+			}
+
+			values[key] = metric.GetGauge().GetValue() + metric.GetCounter().GetValue()
+		}
+	}
+
+	want := map[string]float64{
+		"sealos_exporter_collector_scrape_duration_seconds/informed":               0.25,
+		"sealos_exporter_collector_scrape_duration_seconds/plain":                  1,
+		"sealos_exporter_collector_last_scrape_success_timestamp_seconds/informed": 1700000000,
+		"sealos_exporter_informer_events_total/informed/add":                       2,
+		"sealos_exporter_informer_events_total/informed/update":                    0,
+		"sealos_exporter_informer_events_total/informed/delete":                    1,
+		"sealos_exporter_informer_watch_errors_total/informed":                     0,
+	}
+
+	for key, value := range want {
+		if got, ok := values[key]; !ok || got != value {
+			t.Errorf("Expected %s = %v, got %v (present: %v)", key, value, got, ok)
+		}
+	}
+
+	// Collectors without a successful collection nor instrumentation
+	for _, key := range []string{
+		"sealos_exporter_collector_last_scrape_success_timestamp_seconds/plain",
+		"sealos_exporter_informer_watch_errors_total/plain",
+		"sealos_exporter_collector_last_poll_success_timestamp_seconds/informed",
+	} {
+		if _, ok := values[key]; ok {
+			t.Errorf("Unexpected series %s", key)
+		}
+	}
+
+	if values["sealos_exporter_goroutines"] <= 0 || values["sealos_exporter_memory_heap_alloc_bytes"] <= 0 {
+		t.Error("Expected process goroutine and memory stats")
+	}
+}
@@ -3,8 +3,10 @@ package tlscache
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -14,16 +16,29 @@ import (
 
 // Cache caches TLS certificate with fsnotify-based reloading
 type Cache struct {
-	mu       sync.RWMutex
-	cert     *tls.Certificate
-	certFile string
-	keyFile  string
-	watcher  *fsnotify.Watcher
-	stopCh   chan struct{}
+	mu           sync.RWMutex
+	cert         *tls.Certificate
+	clientCAs    *x509.CertPool
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	watcher      *fsnotify.Watcher
+	stopCh       chan struct{}
+}
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithClientCA requires clients to present a certificate signed by a CA of
+// caFile (mutual TLS), reloaded like the server certificate
+func WithClientCA(caFile string) Option {
+	return func(c *Cache) {
+		c.clientCAFile = caFile
+	}
 }
 
 // New creates a new certificate cache with file watching
-func New(certFile, keyFile string) (*Cache, error) {
+func New(certFile, keyFile string, opts ...Option) (*Cache, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file watcher: %w", err)
@@ -36,6 +51,10 @@ func New(certFile, keyFile string) (*Cache, error) {
 		stopCh:   make(chan struct{}),
 	}
 
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	// Load initial certificate
 	if err := c.loadCertificate(); err != nil {
 		watcher.Close()
@@ -61,6 +80,12 @@ func (c *Cache) startWatching() error {
 		return fmt.Errorf("failed to watch key file: %w", err)
 	}
 
+	if c.clientCAFile != "" {
+		if err := c.watcher.Add(c.clientCAFile); err != nil {
+			return fmt.Errorf("failed to watch client CA file: %w", err)
+		}
+	}
+
 	// Start watch loop in goroutine
 	go c.watchLoop()
 
@@ -135,8 +160,23 @@ func (c *Cache) loadCertificate() error {
 		return fmt.Errorf("failed to load certificate: %w", err)
 	}
 
+	var clientCAs *x509.CertPool
+
+	if c.clientCAFile != "" {
+		pem, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client CA: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("failed to load client CA: no certificate found in %s", c.clientCAFile)
+		}
+	}
+
 	c.mu.Lock()
 	c.cert = &cert
+	c.clientCAs = clientCAs
 	c.mu.Unlock()
 
 	log.Debug("Certificate loaded successfully")
@@ -156,6 +196,32 @@ func (c *Cache) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error)
 	return c.cert, nil
 }
 
+// TLSConfig returns a TLS configuration serving the cached certificate and,
+// with a client CA, verifying client certificates against the cached CA pool
+func (c *Cache) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		GetCertificate: c.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	if c.clientCAFile != "" {
+		// The CA pool is read per handshake to follow its reloads
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+
+			return &tls.Config{
+				GetCertificate: c.GetCertificate,
+				MinVersion:     tls.VersionTLS12,
+				ClientAuth:     tls.RequireAndVerifyClientCert,
+				ClientCAs:      c.clientCAs,
+			}, nil
+		}
+	}
+
+	return config
+}
+
 // Stop stops the certificate watcher
 func (c *Cache) Stop() {
 	close(c.stopCh)
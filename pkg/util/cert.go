@@ -1,7 +1,9 @@
 package util
 
 import (
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -11,38 +13,84 @@ import (
 // CertInfo contains parsed certificate information
 type CertInfo struct {
 	CommonName string
-	Issuer     string
-	NotBefore  time.Time
-	NotAfter   time.Time
-	ExpiresIn  time.Duration
-	IsValid    bool
-	Error      string
+	// SANs are the DNS names, IP addresses and email addresses of the leaf
+	SANs   []string
+	Issuer string
+	// Fingerprint is the hex-encoded SHA-256 digest of the DER-encoded leaf
+	Fingerprint string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	ExpiresIn   time.Duration
+	IsValid     bool
+	Error       string
+
+	// ChainNotAfter is the earliest expiry across the whole chain, leaf and
+	// intermediates included
+	ChainNotAfter  time.Time
+	ChainExpiresIn time.Duration
 }
 
-// ParseCertificate parses a PEM-encoded certificate
+// ParseCertificate parses a PEM-encoded certificate chain, leaf first
 func ParseCertificate(certPEM []byte) (*CertInfo, error) {
-	block, _ := pem.Decode(certPEM)
-	if block == nil {
+	var chain []*x509.Certificate
+
+	for rest := certPEM; ; {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %d: %w", len(chain), err)
+		}
+
+		chain = append(chain, cert)
+	}
+
+	if len(chain) == 0 {
 		return nil, errors.New("failed to decode PEM block")
 	}
 
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	return NewCertInfo(chain, time.Now()), nil
+}
+
+// NewCertInfo returns the information of a certificate chain, leaf first
+func NewCertInfo(chain []*x509.Certificate, now time.Time) *CertInfo {
+	leaf := chain[0]
+
+	chainNotAfter := leaf.NotAfter
+	for _, cert := range chain[1:] {
+		if cert.NotAfter.Before(chainNotAfter) {
+			chainNotAfter = cert.NotAfter
+		}
 	}
 
-	now := time.Now()
-	expiresIn := cert.NotAfter.Sub(now)
-	isValid := now.After(cert.NotBefore) && now.Before(cert.NotAfter)
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	sans = append(sans, leaf.EmailAddresses...)
 
 	return &CertInfo{
-		CommonName: cert.Subject.CommonName,
-		Issuer:     cert.Issuer.CommonName,
-		NotBefore:  cert.NotBefore,
-		NotAfter:   cert.NotAfter,
-		ExpiresIn:  expiresIn,
-		IsValid:    isValid,
-	}, nil
+		CommonName:     leaf.Subject.CommonName,
+		SANs:           sans,
+		Issuer:         leaf.Issuer.CommonName,
+		Fingerprint:    Fingerprint(leaf),
+		NotBefore:      leaf.NotBefore,
+		NotAfter:       leaf.NotAfter,
+		ExpiresIn:      leaf.NotAfter.Sub(now),
+		IsValid:        now.After(leaf.NotBefore) && now.Before(leaf.NotAfter),
+		ChainNotAfter:  chainNotAfter,
+		ChainExpiresIn: chainNotAfter.Sub(now),
+	}
 }
 
 // ParseCertificateSafe safely parses a certificate and returns error info if it fails
@@ -57,3 +105,9 @@ func ParseCertificateSafe(certPEM []byte) *CertInfo {
 
 	return info
 }
+
+// Fingerprint returns the hex-encoded SHA-256 digest of a DER-encoded certificate
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
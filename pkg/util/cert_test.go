@@ -0,0 +1,125 @@
+package util_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+)
+
+// issue creates a certificate signed by parent (self-signed when nil)
+func issue(
+	t *testing.T,
+	name string,
+	notAfter time.Time,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  parent == nil || name != "leaf",
+		BasicConstraintsValid: true,
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	return cert, key
+}
+
+func TestParseCertificateChain(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	root, rootKey := issue(t, "root", now.Add(10*365*24*time.Hour), nil, nil)
+	intermediate, intermediateKey := issue(t, "intermediate", now.Add(30*24*time.Hour), root, rootKey)
+	leaf, _ := issue(t, "leaf", now.Add(90*24*time.Hour), intermediate, intermediateKey)
+
+	var bundle []byte
+	for _, cert := range []*x509.Certificate{leaf, intermediate} {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+
+	info, err := util.ParseCertificate(bundle)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	if info.CommonName != "leaf" || !info.NotAfter.Equal(leaf.NotAfter) {
+		t.Errorf("Expected the leaf to be described, got %+v", info)
+	}
+
+	if !info.ChainNotAfter.Equal(intermediate.NotAfter) {
+		t.Errorf("Expected the chain to expire with the intermediate at %v, got %v",
+			intermediate.NotAfter, info.ChainNotAfter)
+	}
+
+	chainFingerprint := info.Fingerprint
+
+	// A lone leaf expires with itself
+	info, err = util.ParseCertificate(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw}))
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	if !info.ChainNotAfter.Equal(leaf.NotAfter) {
+		t.Errorf("Expected the chain to expire with the leaf, got %v", info.ChainNotAfter)
+	}
+
+	// The fingerprint only depends on the leaf
+	if len(info.Fingerprint) != 64 || info.Fingerprint != chainFingerprint {
+		t.Errorf("Expected the leaf fingerprint %q, got %q", chainFingerprint, info.Fingerprint)
+	}
+
+	if _, err := util.ParseCertificate([]byte("not a certificate")); err == nil {
+		t.Error("Expected an error for invalid PEM")
+	}
+}
+
+func TestNewCertInfoSANs(t *testing.T) {
+	now := time.Now()
+	leaf := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "example.com"},
+		DNSNames:       []string{"example.com", "*.example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("192.0.2.1")},
+		EmailAddresses: []string{"admin@example.com"},
+		NotBefore:      now.Add(-time.Hour),
+		NotAfter:       now.Add(time.Hour),
+	}
+
+	info := util.NewCertInfo([]*x509.Certificate{leaf}, now)
+
+	expected := []string{"example.com", "*.example.com", "192.0.2.1", "admin@example.com"}
+	if !slices.Equal(info.SANs, expected) {
+		t.Errorf("Expected SANs %v, got %v", expected, info.SANs)
+	}
+}
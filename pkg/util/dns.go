@@ -9,37 +9,39 @@ import (
 
 // DNSCheckResult contains the result of a DNS check
 type DNSCheckResult struct {
-	Success bool
-	IPs     []string
-	Error   string
+	Success  bool
+	IPs      []string
+	Error    string
+	Duration time.Duration // Time spent resolving the domain
 }
 
-// CheckDNS performs a DNS lookup
-func CheckDNS(ctx context.Context, domain string, timeout time.Duration) *DNSCheckResult {
+// CheckDNS performs a DNS lookup.
+// The lookup is bounded by the deadline of ctx.
+func CheckDNS(ctx context.Context, domain string) *DNSCheckResult {
 	resolver := &net.Resolver{}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	start := time.Now()
 
 	ips, err := resolver.LookupHost(ctx, domain)
 	if err != nil {
 		return &DNSCheckResult{
-			Success: false,
-			Error:   fmt.Sprintf("DNS lookup failed: %v", err),
+			Success:  false,
+			Error:    fmt.Sprintf("DNS lookup failed: %v", err),
+			Duration: time.Since(start),
 		}
 	}
 
 	return &DNSCheckResult{
-		Success: len(ips) > 0,
-		IPs:     ips,
+		Success:  len(ips) > 0,
+		IPs:      ips,
+		Duration: time.Since(start),
 	}
 }
 
-// CheckIPReachability checks if an IP is reachable
-func CheckIPReachability(ctx context.Context, ip string, port int, timeout time.Duration) bool {
-	dialer := &net.Dialer{
-		Timeout: timeout,
-	}
+// CheckIPReachability checks if an IP is reachable.
+// The dial is bounded by the deadline of ctx.
+func CheckIPReachability(ctx context.Context, ip string, port int) bool {
+	dialer := &net.Dialer{}
 
 	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", ip, port))
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"time"
@@ -16,12 +17,42 @@ type HTTPCheckResult struct {
 	ResponseTime time.Duration
 	StatusCode   int
 	Error        string
+
+	// Redirects is the number of redirects followed
+	Redirects int
+	// TooManyRedirects is set when the response still redirects after
+	// MaxRedirects redirects; StatusCode is then the last redirect status
+	TooManyRedirects bool
+	// Body is the start of the response body, only read with BodyLimit
+	Body []byte
+
+	// TLS posture, set for HTTPS responses
+	TLSVersion  string // Negotiated protocol version, e.g. "TLS 1.3"
+	CipherSuite string // Negotiated cipher suite name
+	HSTS        bool   // Whether a Strict-Transport-Security header was returned
+
+	// Phases break ResponseTime down by layer
+	Phases HTTPPhases
+}
+
+// DefaultMaxRedirects is the number of redirects followed by default, as by net/http
+const DefaultMaxRedirects = 10
+
+// HTTPCheckOptions tunes an HTTP check
+type HTTPCheckOptions struct {
+	// Header is sent along with the request (optional)
+	Header http.Header
+	// MaxRedirects is the number of redirects followed before the check fails
+	MaxRedirects int
+	// BodyLimit is the number of bytes of the response body read into the
+	// result (0 = body not read)
+	BodyLimit int64
 }
 
-// CheckHTTP performs an HTTP/HTTPS health check
-func CheckHTTP(ctx context.Context, url string, timeout time.Duration) *HTTPCheckResult {
+// CheckHTTP performs an HTTP/HTTPS health check, sending header along with
+// the request (optional). The check is bounded by the deadline of ctx.
+func CheckHTTP(ctx context.Context, url string, header http.Header) *HTTPCheckResult {
 	client := &http.Client{
-		Timeout: timeout,
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: false,
@@ -30,9 +61,11 @@ func CheckHTTP(ctx context.Context, url string, timeout time.Duration) *HTTPChec
 		},
 	}
 
+	var tracer phaseTracer
+
 	start := time.Now()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(tracer.withPhaseTrace(ctx), http.MethodGet, url, nil)
 	if err != nil {
 		return &HTTPCheckResult{
 			Success: false,
@@ -40,6 +73,8 @@ func CheckHTTP(ctx context.Context, url string, timeout time.Duration) *HTTPChec
 		}
 	}
 
+	setHeader(req, header)
+
 	resp, err := client.Do(req)
 	responseTime := time.Since(start)
 
@@ -48,48 +83,75 @@ func CheckHTTP(ctx context.Context, url string, timeout time.Duration) *HTTPChec
 			Success:      false,
 			ResponseTime: responseTime,
 			Error:        fmt.Sprintf("request failed: %v", err),
+			Phases:       tracer.result(),
 		}
 	}
 
 	defer resp.Body.Close()
 
-	return &HTTPCheckResult{
-		Success:      resp.StatusCode >= 200 && resp.StatusCode < 500,
-		ResponseTime: responseTime,
-		StatusCode:   resp.StatusCode,
-	}
+	return newHTTPCheckResult(resp, responseTime, tracer.result())
 }
 
-// CheckHTTPWithIP performs an HTTP/HTTPS health check to a specific IP address
-func CheckHTTPWithIP(
+// CheckHTTPWithIP performs an HTTP/HTTPS health check to a specific IP address,
+// sending header along with the request (optional). The check is bounded by
+// the deadline of ctx.
+func CheckHTTPWithIP(ctx context.Context, domain, ip string, header http.Header) *HTTPCheckResult {
+	return CheckHTTPWithIPPort(ctx, "https", domain, ip, "443", HTTPCheckOptions{
+		Header:       header,
+		MaxRedirects: DefaultMaxRedirects,
+	})
+}
+
+// CheckHTTPWithIPPort performs a health check of a domain to a specific IP
+// address and port, over scheme (http or https). The domain is sent as Host
+// header, and as SNI over https. Redirects are followed up to
+// opts.MaxRedirects, through the same IP and port.
+func CheckHTTPWithIPPort(
 	ctx context.Context,
-	domain, ip string,
-	timeout time.Duration,
+	scheme, domain, ip, port string,
+	opts HTTPCheckOptions,
 ) *HTTPCheckResult {
+	var (
+		redirects        int
+		tooManyRedirects bool
+	)
+
 	// Create a transport that dials the specific IP
 	client := &http.Client{
-		Timeout: timeout,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				tooManyRedirects = true
+				return http.ErrUseLastResponse
+			}
+
+			redirects = len(via)
+
+			return nil
+		},
 		Transport: &http.Transport{
 			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
 				// Override the address with our specific IP
-				return (&net.Dialer{
-					Timeout: 15 * time.Second,
-				}).DialContext(ctx, network, net.JoinHostPort(ip, "443"))
+				return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(ip, port))
 			},
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: false,
-				MinVersion:         tls.VersionTLS12,
-				ServerName:         domain, // Important: use domain for SNI
+				// Legacy versions are accepted so endpoints still terminating
+				// TLS 1.0/1.1 are reported through TLSVersion instead of failing
+				//nolint:gosec // G402: intentionally probing legacy TLS versions
+				MinVersion: tls.VersionTLS10,
+				ServerName: domain, // Important: use domain for SNI
 			},
 		},
 	}
 
+	var tracer phaseTracer
+
 	start := time.Now()
 
 	// Build URL with domain (not IP)
-	url := "https://" + domain + "/"
+	url := scheme + "://" + HostPort(scheme, domain, port) + "/"
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(tracer.withPhaseTrace(ctx), http.MethodGet, url, nil)
 	if err != nil {
 		return &HTTPCheckResult{
 			Success: false,
@@ -97,7 +159,9 @@ func CheckHTTPWithIP(
 		}
 	}
 
-	// Set Host header to domain
+	setHeader(req, opts.Header)
+
+	// Set Host header to domain, without the port, as Ingress rules match host names
 	req.Host = domain
 
 	resp, err := client.Do(req)
@@ -108,31 +172,92 @@ func CheckHTTPWithIP(
 			Success:      false,
 			ResponseTime: responseTime,
 			Error:        fmt.Sprintf("request failed: %v", err),
+			Phases:       tracer.result(),
 		}
 	}
 
 	defer resp.Body.Close()
 
-	return &HTTPCheckResult{
+	result := newHTTPCheckResult(resp, responseTime, tracer.result())
+	result.Redirects = redirects
+
+	if tooManyRedirects {
+		result.Success = false
+		result.TooManyRedirects = true
+	}
+
+	if opts.BodyLimit > 0 {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, opts.BodyLimit))
+		if err != nil {
+			result.Success = false
+			result.Error = fmt.Sprintf("failed to read body: %v", err)
+		}
+
+		result.Body = body
+	}
+
+	return result
+}
+
+// HostPort returns the host of a URL, without the port when it is the
+// default port of the scheme
+func HostPort(scheme, host, port string) string {
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		return host
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// setHeader adds header to the request, replacing the default values (e.g. User-Agent)
+func setHeader(req *http.Request, header http.Header) {
+	for key, values := range header {
+		req.Header[key] = values
+	}
+}
+
+// newHTTPCheckResult builds a check result from a response, including its TLS posture
+func newHTTPCheckResult(resp *http.Response, responseTime time.Duration, phases HTTPPhases) *HTTPCheckResult {
+	result := &HTTPCheckResult{
 		Success:      resp.StatusCode >= 200 && resp.StatusCode < 500,
 		ResponseTime: responseTime,
 		StatusCode:   resp.StatusCode,
+		Phases:       phases,
+	}
+
+	if resp.TLS != nil {
+		result.TLSVersion = tls.VersionName(resp.TLS.Version)
+		result.CipherSuite = tls.CipherSuiteName(resp.TLS.CipherSuite)
+		result.HSTS = resp.Header.Get("Strict-Transport-Security") != ""
 	}
+
+	return result
 }
 
-// GetTLSCert retrieves the TLS certificate from a domain
-func GetTLSCert(domain string, timeout time.Duration) (*CertInfo, error) {
+// GetTLSCert retrieves the TLS certificate chain presented by a domain on a
+// port (e.g. "443"). The handshake is bounded by the deadline of ctx.
+func GetTLSCert(ctx context.Context, domain, port string) (*CertInfo, error) {
+	return getTLSCert(ctx, net.JoinHostPort(domain, port), domain)
+}
+
+// GetTLSCertWithIP retrieves the TLS certificate chain presented for a domain
+// by a specific IP address on a port, sending the domain as SNI and bypassing
+// DNS. The handshake is bounded by the deadline of ctx.
+func GetTLSCertWithIP(ctx context.Context, domain, ip, port string) (*CertInfo, error) {
+	return getTLSCert(ctx, net.JoinHostPort(ip, port), domain)
+}
+
+// getTLSCert dials addr and verifies the certificate chain against serverName
+func getTLSCert(ctx context.Context, addr, serverName string) (*CertInfo, error) {
 	dialer := &tls.Dialer{
 		Config: &tls.Config{
 			InsecureSkipVerify: false,
 			MinVersion:         tls.VersionTLS12,
+			ServerName:         serverName,
 		},
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	conn, err := dialer.DialContext(ctx, "tcp", domain+":443")
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial: %w", err)
 	}
@@ -148,17 +273,5 @@ func GetTLSCert(domain string, timeout time.Duration) (*CertInfo, error) {
 		return nil, errors.New("no certificates found")
 	}
 
-	cert := state.PeerCertificates[0]
-	now := time.Now()
-	expiresIn := cert.NotAfter.Sub(now)
-	isValid := now.After(cert.NotBefore) && now.Before(cert.NotAfter)
-
-	return &CertInfo{
-		CommonName: cert.Subject.CommonName,
-		Issuer:     cert.Issuer.CommonName,
-		NotBefore:  cert.NotBefore,
-		NotAfter:   cert.NotAfter,
-		ExpiresIn:  expiresIn,
-		IsValid:    isValid,
-	}, nil
+	return NewCertInfo(state.PeerCertificates, time.Now()), nil
 }
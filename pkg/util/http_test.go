@@ -0,0 +1,126 @@
+package util_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+)
+
+func TestCheckHTTPPhases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := util.CheckHTTP(context.Background(), server.URL, nil)
+	if !result.Success {
+		t.Fatalf("Expected a successful check, got %q", result.Error)
+	}
+
+	phases := result.Phases
+	if phases.Connect <= 0 {
+		t.Errorf("Expected the connect phase to be measured, got %v", phases.Connect)
+	}
+
+	if phases.FirstByte < 20*time.Millisecond {
+		t.Errorf("Expected the first byte to wait for the handler, got %v", phases.FirstByte)
+	}
+
+	if phases.DNS != 0 || phases.TLSHandshake != 0 {
+		t.Errorf("Expected no DNS nor TLS phase for a plain HTTP IP URL, got %+v", phases)
+	}
+
+	if phases.Connect+phases.FirstByte > result.ResponseTime {
+		t.Errorf("Expected the phases to fit in the response time %v, got %+v", result.ResponseTime, phases)
+	}
+}
+
+func TestCheckHTTPHeader(t *testing.T) {
+	var received http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("User-Agent", "probe/1.0")
+	header.Set("X-Request-Id", "42")
+
+	if result := util.CheckHTTP(context.Background(), server.URL, header); !result.Success {
+		t.Fatalf("Expected a successful check, got %q", result.Error)
+	}
+
+	if received.Get("User-Agent") != "probe/1.0" || received.Get("X-Request-Id") != "42" {
+		t.Errorf("Expected the probe headers to be sent, got %v", received)
+	}
+}
+
+func TestCheckHTTPWithIPPort(t *testing.T) {
+	var host string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ip, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := util.CheckHTTPWithIPPort(context.Background(), "http", "app.example.com", ip, port, util.HTTPCheckOptions{})
+	if !result.Success {
+		t.Fatalf("Expected a successful check, got %q", result.Error)
+	}
+
+	if host != "app.example.com" {
+		t.Errorf("Expected the domain as Host header, got %s", host)
+	}
+
+	if got := util.HostPort("https", "app.example.com", "443"); got != "app.example.com" {
+		t.Errorf("Expected the default port to be omitted, got %s", got)
+	}
+}
+
+func TestCheckHTTPWithIPPortRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			http.Redirect(w, r, "/home", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("welcome home"))
+		}
+	}))
+	defer server.Close()
+
+	ip, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := util.CheckHTTPWithIPPort(context.Background(), "http", "app.example.com", ip, port, util.HTTPCheckOptions{
+		MaxRedirects: 2,
+		BodyLimit:    7,
+	})
+	if !result.Success || result.Redirects != 2 || string(result.Body) != "welcome" {
+		t.Errorf("Expected 2 redirects and the start of the body, got %+v", result)
+	}
+
+	result = util.CheckHTTPWithIPPort(context.Background(), "http", "app.example.com", ip, port, util.HTTPCheckOptions{
+		MaxRedirects: 1,
+	})
+	if result.Success || !result.TooManyRedirects || result.StatusCode != http.StatusFound {
+		t.Errorf("Expected the check to stop on the second redirect, got %+v", result)
+	}
+}
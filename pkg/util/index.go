@@ -0,0 +1,55 @@
+package util
+
+// Index is a two-level map keyed by a parent (e.g. namespace/pod) and a child
+// (e.g. container). Removing a parent drops its children without scanning the
+// entries of other parents.
+type Index[V any] map[string]map[string]V
+
+// Get returns the value of a child
+func (i Index[V]) Get(parent, child string) (V, bool) {
+	v, ok := i[parent][child]
+	return v, ok
+}
+
+// Set stores the value of a child
+func (i Index[V]) Set(parent, child string, v V) {
+	children, ok := i[parent]
+	if !ok {
+		children = make(map[string]V)
+		i[parent] = children
+	}
+
+	children[child] = v
+}
+
+// Delete removes a child, and its parent once it has no children left
+func (i Index[V]) Delete(parent, child string) {
+	children, ok := i[parent]
+	if !ok {
+		return
+	}
+
+	delete(children, child)
+
+	if len(children) == 0 {
+		delete(i, parent)
+	}
+}
+
+// DeleteParent removes a parent and returns its children
+func (i Index[V]) DeleteParent(parent string) map[string]V {
+	children := i[parent]
+	delete(i, parent)
+
+	return children
+}
+
+// Len returns the number of children of all parents
+func (i Index[V]) Len() int {
+	n := 0
+	for _, children := range i {
+		n += len(children)
+	}
+
+	return n
+}
@@ -0,0 +1,45 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+)
+
+// TestIndex verifies children are removed individually and per parent
+func TestIndex(t *testing.T) {
+	index := util.Index[int]{}
+	index.Set("ns/web", "app", 1)
+	index.Set("ns/web", "sidecar", 2)
+	index.Set("ns/api", "app", 3)
+
+	if v, ok := index.Get("ns/web", "sidecar"); !ok || v != 2 {
+		t.Errorf("Expected ns/web sidecar to be 2, got %d (found %v)", v, ok)
+	}
+
+	if index.Len() != 3 {
+		t.Errorf("Expected 3 entries, got %d", index.Len())
+	}
+
+	removed := index.DeleteParent("ns/web")
+	if len(removed) != 2 || removed["app"] != 1 {
+		t.Errorf("Expected the 2 children of ns/web, got %v", removed)
+	}
+
+	if _, ok := index.Get("ns/web", "app"); ok {
+		t.Error("Expected ns/web to be removed")
+	}
+
+	index.Delete("ns/api", "app")
+
+	if len(index) != 0 {
+		t.Errorf("Expected parents without children to be removed, got %v", index)
+	}
+
+	// Missing parents and children are ignored
+	index.Delete("ns/missing", "app")
+
+	if index.DeleteParent("ns/missing") != nil {
+		t.Error("Expected no children for a missing parent")
+	}
+}
@@ -3,8 +3,13 @@ package util
 import (
 	"fmt"
 	"os"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -67,3 +72,90 @@ func buildConfig(kubeconfig string) (*rest.Config, error) {
 
 	return config, nil
 }
+
+// NewInformerFactories creates one SharedInformerFactory per namespace so that
+// collectors only need namespaced list/watch permissions when namespaces are configured.
+// When namespaces is empty, a single cluster-wide factory is returned.
+// Duplicate and empty namespace entries are ignored.
+func NewInformerFactories(
+	client kubernetes.Interface,
+	resyncPeriod time.Duration,
+	namespaces []string,
+	opts ...informers.SharedInformerOption,
+) []informers.SharedInformerFactory {
+	seen := make(map[string]struct{}, len(namespaces))
+	factories := make([]informers.SharedInformerFactory, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			continue
+		}
+
+		if _, ok := seen[namespace]; ok {
+			continue
+		}
+
+		seen[namespace] = struct{}{}
+
+		nsOpts := append([]informers.SharedInformerOption{informers.WithNamespace(namespace)}, opts...)
+		factories = append(factories, informers.NewSharedInformerFactoryWithOptions(
+			client,
+			resyncPeriod,
+			nsOpts...,
+		))
+	}
+
+	if len(factories) == 0 {
+		factories = append(factories, informers.NewSharedInformerFactoryWithOptions(
+			client,
+			resyncPeriod,
+			opts...,
+		))
+	}
+
+	return factories
+}
+
+// NewMetadataInformerFactories is the metadata-only equivalent of
+// NewInformerFactories: one factory per namespace, or a single cluster-wide
+// factory when namespaces is empty. Metadata informers only cache object
+// metadata, for watches that never need the object spec or status.
+func NewMetadataInformerFactories(
+	client metadata.Interface,
+	resyncPeriod time.Duration,
+	namespaces []string,
+	tweakListOptions metadatainformer.TweakListOptionsFunc,
+) []metadatainformer.SharedInformerFactory {
+	seen := make(map[string]struct{}, len(namespaces))
+	factories := make([]metadatainformer.SharedInformerFactory, 0, len(namespaces))
+
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			continue
+		}
+
+		if _, ok := seen[namespace]; ok {
+			continue
+		}
+
+		seen[namespace] = struct{}{}
+
+		factories = append(factories, metadatainformer.NewFilteredSharedInformerFactory(
+			client,
+			resyncPeriod,
+			namespace,
+			tweakListOptions,
+		))
+	}
+
+	if len(factories) == 0 {
+		factories = append(factories, metadatainformer.NewFilteredSharedInformerFactory(
+			client,
+			resyncPeriod,
+			metav1.NamespaceAll,
+			tweakListOptions,
+		))
+	}
+
+	return factories
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// SystemNamespaces are the platform namespaces excluded by default from
+// tenant-focused collectors, to reduce noise and cardinality
+var SystemNamespaces = []string{
+	"kube-system",
+	"kube-public",
+	"kube-node-lease",
+	"sealos",
+	"sealos-system",
+	"account-system",
+	"kb-system",
+	"cert-manager",
+	"higress-system",
+	"ingress-nginx",
+	"openebs",
+}
+
+// ExcludedNamespaces returns the namespaces a collector should exclude.
+// Nothing is excluded when system namespaces are included, or when the
+// collector watches an explicit list of namespaces.
+func ExcludedNamespaces(watched []string, includeSystem bool) []string {
+	if includeSystem || len(watched) > 0 {
+		return nil
+	}
+
+	return append([]string(nil), SystemNamespaces...)
+}
+
+// NamespaceExclusionSelector returns a field selector matching objects outside
+// the excluded namespaces, or nil when nothing is excluded
+func NamespaceExclusionSelector(excluded []string) fields.Selector {
+	if len(excluded) == 0 {
+		return nil
+	}
+
+	selectors := make([]fields.Selector, 0, len(excluded))
+	for _, namespace := range excluded {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+	}
+
+	return fields.AndSelectors(selectors...)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// HTTPPhases are the durations of the phases of an HTTP request. A phase that
+// did not happen is zero (e.g. DNS when dialing a given IP, TLS over HTTP).
+type HTTPPhases struct {
+	DNS          time.Duration // Name resolution
+	Connect      time.Duration // TCP connection establishment
+	TLSHandshake time.Duration // TLS handshake
+	FirstByte    time.Duration // From the request being written to the first response byte
+}
+
+// phaseTracer records the phases of a request from httptrace hooks, which may
+// be called concurrently
+type phaseTracer struct {
+	mu     sync.Mutex
+	phases HTTPPhases
+
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+}
+
+// withPhaseTrace returns a context tracing the phases of the request it is used for
+func (t *phaseTracer) withPhaseTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.start(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.done(&t.dnsStart, &t.phases.DNS)
+		},
+		ConnectStart: func(string, string) {
+			t.start(&t.connectStart)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.done(&t.connectStart, &t.phases.Connect)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.start(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				t.done(&t.tlsStart, &t.phases.TLSHandshake)
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.start(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.done(&t.wroteRequest, &t.phases.FirstByte)
+		},
+	})
+}
+
+// start records the start time of a phase
+func (t *phaseTracer) start(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	*at = time.Now()
+}
+
+// done records the duration of a phase since its start
+func (t *phaseTracer) done(start *time.Time, duration *time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !start.IsZero() {
+		*duration = time.Since(*start)
+	}
+}
+
+// result returns the recorded phases
+func (t *phaseTracer) result() HTTPPhases {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.phases
+}
@@ -0,0 +1,86 @@
+package util
+
+import "time"
+
+// windowSample is a value recorded at a point in time
+type windowSample struct {
+	time  time.Time
+	value float64
+}
+
+// WindowSummary aggregates the samples of a window
+type WindowSummary struct {
+	Min   float64
+	Max   float64
+	Avg   float64
+	Last  float64
+	Count int
+}
+
+// Window keeps the samples recorded over a sliding time window, so checks
+// running more often than Prometheus scrapes can be summarized over the scrape
+// interval instead of only exposing their last result. Window is not safe for
+// concurrent use.
+type Window struct {
+	size    time.Duration
+	samples []windowSample // in recording order
+}
+
+// NewWindow creates a window keeping the samples of the last size
+func NewWindow(size time.Duration) *Window {
+	return &Window{size: size}
+}
+
+// Add records a sample and drops the samples that left the window
+func (w *Window) Add(t time.Time, value float64) {
+	w.samples = append(w.samples, windowSample{time: t, value: value})
+
+	cutoff := t.Add(-w.size)
+
+	i := 0
+	for i < len(w.samples) && !w.samples[i].time.After(cutoff) {
+		i++
+	}
+
+	if i > 0 {
+		w.samples = append(w.samples[:0], w.samples[i:]...)
+	}
+}
+
+// Summary returns the aggregates of the samples recorded within the window
+// ending at now, and false when there are none. The window is not modified,
+// so Summary may be called under a read lock.
+func (w *Window) Summary(now time.Time) (WindowSummary, bool) {
+	cutoff := now.Add(-w.size)
+
+	var (
+		summary WindowSummary
+		sum     float64
+	)
+
+	for _, sample := range w.samples {
+		if !sample.time.After(cutoff) {
+			continue
+		}
+
+		if summary.Count == 0 || sample.value < summary.Min {
+			summary.Min = sample.value
+		}
+
+		if summary.Count == 0 || sample.value > summary.Max {
+			summary.Max = sample.value
+		}
+
+		sum += sample.value
+		summary.Last = sample.value
+		summary.Count++
+	}
+
+	if summary.Count == 0 {
+		return WindowSummary{}, false
+	}
+
+	summary.Avg = sum / float64(summary.Count)
+
+	return summary, true
+}
@@ -0,0 +1,51 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+)
+
+// TestWindow verifies samples are aggregated over the sliding window only
+func TestWindow(t *testing.T) {
+	start := time.Now()
+	window := util.NewWindow(time.Minute)
+
+	if _, ok := window.Summary(start); ok {
+		t.Error("Expected no summary for an empty window")
+	}
+
+	// A flap captured between two scrapes
+	for i, value := range []float64{1, 1, 0, 1, 1, 1} {
+		window.Add(start.Add(time.Duration(i)*10*time.Second), value)
+	}
+
+	summary, ok := window.Summary(start.Add(55 * time.Second))
+	if !ok {
+		t.Fatal("Expected a summary")
+	}
+
+	expected := util.WindowSummary{Min: 0, Max: 1, Avg: 5.0 / 6, Last: 1, Count: 6}
+	if summary != expected {
+		t.Errorf("Expected %+v, got %+v", expected, summary)
+	}
+
+	// The failed sample leaves the window 1m after it was recorded
+	summary, _ = window.Summary(start.Add(80 * time.Second))
+	if summary.Min != 1 || summary.Count != 3 {
+		t.Errorf("Expected the 3 successful samples of the last minute, got %+v", summary)
+	}
+
+	// Adding drops the samples that left the window
+	window.Add(start.Add(5*time.Minute), 0)
+
+	summary, _ = window.Summary(start.Add(5 * time.Minute))
+	if summary.Count != 1 || summary.Last != 0 {
+		t.Errorf("Expected only the last sample, got %+v", summary)
+	}
+
+	if _, ok := window.Summary(start.Add(10 * time.Minute)); ok {
+		t.Error("Expected no summary once every sample left the window")
+	}
+}
@@ -0,0 +1,76 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/labring/sealos-state-metrics/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestWorkloadOf verifies workload attribution from owner references
+func TestWorkloadOf(t *testing.T) {
+	controller := true
+
+	tests := []struct {
+		name         string
+		owner        *metav1.OwnerReference
+		labels       map[string]string
+		expectedKind string
+		expectedName string
+	}{
+		{
+			name:         "no owner",
+			expectedKind: "",
+			expectedName: "",
+		},
+		{
+			name: "deployment replicaset",
+			owner: &metav1.OwnerReference{
+				Kind:       "ReplicaSet",
+				Name:       "web-5d8f7c9b4",
+				Controller: &controller,
+			},
+			labels:       map[string]string{"pod-template-hash": "5d8f7c9b4"},
+			expectedKind: "Deployment",
+			expectedName: "web",
+		},
+		{
+			name: "standalone replicaset",
+			owner: &metav1.OwnerReference{
+				Kind:       "ReplicaSet",
+				Name:       "web",
+				Controller: &controller,
+			},
+			expectedKind: "ReplicaSet",
+			expectedName: "web",
+		},
+		{
+			name: "statefulset",
+			owner: &metav1.OwnerReference{
+				Kind:       "StatefulSet",
+				Name:       "db",
+				Controller: &controller,
+			},
+			expectedKind: "StatefulSet",
+			expectedName: "db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Labels: tt.labels},
+			}
+			if tt.owner != nil {
+				pod.OwnerReferences = []metav1.OwnerReference{*tt.owner}
+			}
+
+			kind, name := util.WorkloadOf(pod)
+			if kind != tt.expectedKind || name != tt.expectedName {
+				t.Errorf("Expected %s/%s, got %s/%s",
+					tt.expectedKind, tt.expectedName, kind, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// certsPath is the path of the certificate inventory endpoint
+const certsPath = "/api/v1/certs"
+
+// certsCSVHeader is the header row of the CSV inventory
+var certsCSVHeader = []string{
+	"namespace", "secret", "common_name", "sans", "issuer",
+	"not_before", "not_after", "expires_in_seconds", "valid", "error", "fingerprint",
+}
+
+// CertificateEntry is a certificate of the inventory along with its validity
+// at the time of the request
+type CertificateEntry struct {
+	collector.Certificate
+
+	Valid            bool    `json:"valid"`
+	ExpiresInSeconds float64 `json:"expiresInSeconds"`
+}
+
+// CertsResponse is the versioned body of the certificate inventory endpoint
+type CertsResponse struct {
+	APIVersion   string             `json:"apiVersion"`
+	GeneratedAt  time.Time          `json:"generatedAt"`
+	Certificates []CertificateEntry `json:"certificates"`
+}
+
+// certFilter holds the query parameters filtering the inventory
+type certFilter struct {
+	namespaces     []string
+	issuers        []string
+	hosts          []string
+	expiringWithin time.Duration // 0 = no filter
+	errors         bool          // only certificates failing to parse
+}
+
+// parseCertFilter reads the filter from the query parameters
+func parseCertFilter(r *http.Request) (certFilter, error) {
+	query := r.URL.Query()
+
+	filter := certFilter{
+		namespaces: query["namespace"],
+		issuers:    query["issuer"],
+		hosts:      query["host"],
+	}
+
+	if value := query.Get("expiringWithin"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.expiringWithin = d
+	}
+
+	if value := query.Get("errors"); value != "" {
+		errorsOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.errors = errorsOnly
+	}
+
+	return filter, nil
+}
+
+// matches returns whether a certificate passes the filter
+func (f *certFilter) matches(cert *collector.Certificate, now time.Time) bool {
+	if len(f.namespaces) > 0 && !slices.Contains(f.namespaces, cert.Namespace) {
+		return false
+	}
+
+	if f.errors {
+		return cert.Error != ""
+	}
+
+	if cert.Error != "" {
+		// The fields of certificates failing to parse are unknown
+		return len(f.issuers) == 0 && len(f.hosts) == 0 && f.expiringWithin == 0
+	}
+
+	if len(f.issuers) > 0 && !slices.Contains(f.issuers, cert.Issuer) {
+		return false
+	}
+
+	if len(f.hosts) > 0 && !slices.ContainsFunc(f.hosts, func(host string) bool { return certCovers(cert, host) }) {
+		return false
+	}
+
+	if f.expiringWithin > 0 && cert.NotAfter.After(now.Add(f.expiringWithin)) {
+		return false
+	}
+
+	return true
+}
+
+// certCovers returns whether the common name or a SAN of a certificate
+// matches host, a leading wildcard matching a single label
+func certCovers(cert *collector.Certificate, host string) bool {
+	host = strings.ToLower(host)
+
+	for _, name := range append([]string{cert.CommonName}, cert.SANs...) {
+		name = strings.ToLower(name)
+		if name == host {
+			return true
+		}
+
+		suffix, wildcard := strings.CutPrefix(name, "*.")
+		if !wildcard {
+			continue
+		}
+
+		if label, rest, found := strings.Cut(host, "."); found && label != "" && rest == suffix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleCerts returns the certificates of every collector implementing
+// collector.CertificateReporter as JSON, or CSV with ?format=csv. The
+// inventory can be filtered with ?namespace=, ?issuer= and ?host= (repeatable),
+// ?expiringWithin=720h and ?errors=true.
+func (s *Server) handleCerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"error": "method not allowed",
+		})
+
+		return
+	}
+
+	filter, err := parseCertFilter(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error": "invalid filter: " + err.Error(),
+		})
+
+		return
+	}
+
+	now := time.Now().UTC()
+	entries := s.certificateEntries(&filter, now)
+
+	if r.URL.Query().Get("format") == "csv" {
+		writeCertsCSV(w, entries)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, CertsResponse{
+		APIVersion:   statusAPIVersion,
+		GeneratedAt:  now,
+		Certificates: entries,
+	})
+}
+
+// certificateEntries returns the filtered certificates of all collectors,
+// sorted by namespace and secret. Secrets tracked by several collector
+// instances are listed once.
+func (s *Server) certificateEntries(filter *certFilter, now time.Time) []CertificateEntry {
+	seen := make(map[string]bool)
+	entries := []CertificateEntry{}
+
+	for _, c := range s.registry.GetAllCollectors() {
+		reporter, ok := c.(collector.CertificateReporter)
+		if !ok {
+			continue
+		}
+
+		for _, cert := range reporter.Certificates() {
+			key := cert.Namespace + "/" + cert.Secret
+			if seen[key] || !filter.matches(&cert, now) {
+				continue
+			}
+
+			seen[key] = true
+
+			entry := CertificateEntry{Certificate: cert}
+			if cert.Error == "" {
+				entry.Valid = now.After(cert.NotBefore) && now.Before(cert.NotAfter)
+				entry.ExpiresInSeconds = cert.NotAfter.Sub(now).Seconds()
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+
+		return entries[i].Secret < entries[j].Secret
+	})
+
+	return entries
+}
+
+// writeCertsCSV writes the inventory as CSV, SANs being separated by spaces
+func writeCertsCSV(w http.ResponseWriter, entries []CertificateEntry) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write(certsCSVHeader)
+
+	for _, entry := range entries {
+		record := []string{
+			entry.Namespace,
+			entry.Secret,
+			entry.CommonName,
+			strings.Join(entry.SANs, " "),
+			entry.Issuer,
+			"",
+			"",
+			"",
+			strconv.FormatBool(entry.Valid),
+			entry.Error,
+			entry.Fingerprint,
+		}
+
+		if entry.Error == "" {
+			record[5] = entry.NotBefore.UTC().Format(time.RFC3339)
+			record[6] = entry.NotAfter.UTC().Format(time.RFC3339)
+			record[7] = strconv.FormatFloat(entry.ExpiresInSeconds, 'f', 0, 64)
+		}
+
+		_ = writer.Write(record)
+	}
+
+	writer.Flush()
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+
+	"github.com/labring/sealos-state-metrics/pkg/identity"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// resolveCluster determines the cluster identity from the configuration,
+// node labels and cloud metadata. Node labels are not read in standalone mode.
+func (s *Server) resolveCluster(ctx context.Context) identity.Cluster {
+	cfg := s.config.Cluster
+
+	opts := identity.ClusterOptions{
+		Name:          cfg.Name,
+		Region:        cfg.Region,
+		Zone:          cfg.Zone,
+		NodeLabels:    cfg.NodeLabels && !s.config.Standalone,
+		NodeName:      s.config.NodeName,
+		NameLabel:     cfg.NameLabel,
+		CloudMetadata: cfg.CloudMetadata,
+		Timeout:       cfg.Timeout,
+	}
+
+	if opts.NodeLabels {
+		client, err := s.clientProvider.GetClient()
+		if err != nil {
+			log.WithError(err).Warn("Kubernetes client not available, cluster identity not detected from node labels")
+		} else {
+			opts.Client = client
+		}
+	}
+
+	cluster := identity.ResolveCluster(ctx, opts)
+
+	log.WithFields(log.Fields{
+		"cluster": cluster.Name,
+		"region":  cluster.Region,
+		"zone":    cluster.Zone,
+	}).Info("Cluster identity resolved")
+
+	return cluster
+}
+
+// metricsRegisterer returns the registerer of collector metrics, adding the
+// cluster identity target labels when enabled
+func (s *Server) metricsRegisterer() prometheus.Registerer {
+	if !s.config.Cluster.TargetLabels {
+		return s.promRegistry
+	}
+
+	return prometheus.WrapRegistererWith(s.cluster.Labels(), s.promRegistry)
+}
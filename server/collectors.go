@@ -55,6 +55,8 @@ func (s *Server) reinitializeAndStartCollectors() error {
 		return fmt.Errorf("failed to reinitialize collectors: %w", err)
 	}
 
+	s.attachHeartbeat()
+
 	// Start collectors with new configuration
 	if err := s.startCollectors(); err != nil {
 		return fmt.Errorf("failed to start collectors: %w", err)
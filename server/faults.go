@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/faults"
+)
+
+// faultsPath is the admin endpoint controlling fault injection
+const faultsPath = "/debug/faults"
+
+// handleFaults lists (GET), arms (POST) or disarms (DELETE) faults injected
+// into collector checks. Only served by the debug server, which listens on localhost.
+//
+//	POST   /debug/faults?collector=domain&target=example.com&count=3
+//	POST   /debug/faults?collector=cloudbalance&mode=delay&delay=30s&duration=15m
+//	DELETE /debug/faults?id=1 (all faults without id)
+func (s *Server) handleFaults(w http.ResponseWriter, r *http.Request) {
+	injector := faults.Default()
+	query := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{
+			"faults": injector.Faults(),
+		})
+	case http.MethodPost:
+		rule := faults.Rule{
+			Collector: query.Get("collector"),
+			Target:    query.Get("target"),
+			Mode:      query.Get("mode"),
+		}
+
+		for param, target := range map[string]*time.Duration{
+			"delay":    &rule.Delay,
+			"duration": &rule.Duration,
+		} {
+			if value := query.Get(param); value != "" {
+				duration, err := time.ParseDuration(value)
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid " + param})
+					return
+				}
+
+				*target = duration
+			}
+		}
+
+		if value := query.Get("count"); value != "" {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid count"})
+				return
+			}
+
+			rule.Count = count
+		}
+
+		fault, err := injector.Arm(rule)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, fault)
+	case http.MethodDelete:
+		var id int
+
+		if value := query.Get("id"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid id"})
+				return
+			}
+
+			id = parsed
+		}
+
+		if err := injector.Disarm(id); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"error": "method not allowed",
+		})
+	}
+}
@@ -3,46 +3,134 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/labring/sealos-state-metrics/pkg/auth"
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
+// readyPath is the readiness endpoint, ?verbose lists the state of each collector
+const readyPath = "/readyz"
+
+// livenessPath is the Kubernetes-style alias of the configured health path
+const livenessPath = "/healthz"
+
+// newAuthenticator creates the authenticator of the named server, reviewing
+// bearer tokens with Kubernetes when enabled and accepting the static
+// credentials of the configured files
+func (s *Server) newAuthenticator(serverName string) (*auth.Authenticator, error) {
+	cfg := s.config.Server.Auth
+	opts := []auth.Option{auth.WithResultObserver(s.scrapeMetrics.authObserver(serverName))}
+
+	if cfg.TokensFile != "" {
+		tokens, err := auth.LoadTokensFile(cfg.TokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load static tokens: %w", err)
+		}
+
+		opts = append(opts, auth.WithStaticTokens(tokens))
+	}
+
+	if cfg.BasicAuthFile != "" {
+		users, err := auth.LoadBasicAuthFile(cfg.BasicAuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load basic authentication users: %w", err)
+		}
+
+		opts = append(opts, auth.WithBasicAuth(users))
+	}
+
+	var client kubernetes.Interface
+
+	if cfg.Enabled {
+		var err error
+
+		// Get Kubernetes client for authentication
+		client, err = s.getKubernetesClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubernetes client for authentication: %w", err)
+		}
+	}
+
+	return auth.NewAuthenticator(client, opts...), nil
+}
+
 // setupRoutes configures HTTP routes with optional authentication
 func (s *Server) setupRoutes(
 	mux *http.ServeMux,
-	metricsPath, healthPath string,
+	serverName, metricsPath, healthPath string,
 	enableAuth bool,
 ) error {
-	// Metrics endpoint with optional authentication
-	metricsHandler := promhttp.HandlerFor(
-		s.promRegistry,
-		promhttp.HandlerOpts{
-			EnableOpenMetrics: true,
-		},
-	)
+	// Metrics endpoint with optional authentication.
+	// Families are encoded straight to the response (gzip when negotiated via
+	// Accept-Encoding) rather than building the full payload in memory.
+	handlerOpts := promhttp.HandlerOpts{
+		EnableOpenMetrics:   true,
+		DisableCompression:  !s.config.Server.Compression,
+		OfferedCompressions: []promhttp.Compression{promhttp.Gzip},
+	}
+
+	var handler http.Handler = promhttp.HandlerFor(s.promRegistry, handlerOpts)
+
+	// Scrapes are served the last snapshot rather than timing out
+	if s.config.Server.ScrapeTimeoutAware {
+		handler = s.scrapeTimeoutHandler(serverName, handler, handlerOpts)
+	}
+
+	metricsHandler := s.scrapeMetrics.instrument(serverName, handler)
+
+	// Status API exposes the same data as metrics in structured form
+	var statusHandler http.Handler = http.HandlerFunc(s.handleStatus)
+
+	// History API exposes the last results per target
+	var historyHandler http.Handler = http.HandlerFunc(s.handleHistory)
+
+	// Certificate inventory API exposes the tracked certificates
+	var certsHandler http.Handler = http.HandlerFunc(s.handleCerts)
 
 	// Apply authentication middleware if enabled
 	if enableAuth {
-		// Get Kubernetes client for authentication
-		client, err := s.getKubernetesClient()
+		authenticator, err := s.newAuthenticator(serverName)
 		if err != nil {
-			return fmt.Errorf("failed to get Kubernetes client for authentication: %w", err)
+			return err
 		}
 
-		authenticator := auth.NewAuthenticator(client)
 		metricsHandler = authenticator.Middleware(metricsHandler)
+		statusHandler = authenticator.Middleware(statusHandler)
+		historyHandler = authenticator.Middleware(historyHandler)
+		certsHandler = authenticator.Middleware(certsHandler)
 
-		log.Info("Kubernetes authentication enabled for metrics endpoint")
+		log.Info("Authentication enabled for metrics, status, history and certs endpoints")
 	}
 
 	mux.Handle(metricsPath, metricsHandler)
 
+	// Structured status endpoint (same authentication as metrics)
+	mux.Handle(statusPath, statusHandler)
+
+	// Per-target history endpoint (same authentication as metrics)
+	mux.Handle(historyPath, historyHandler)
+
+	// Certificate inventory endpoint (same authentication as metrics)
+	mux.Handle(certsPath, certsHandler)
+
 	// Health endpoint (no authentication)
 	mux.HandleFunc(healthPath, s.handleHealth)
 
+	if healthPath != livenessPath {
+		mux.HandleFunc(livenessPath, s.handleHealth)
+	}
+
+	// Readiness endpoint (no authentication)
+	mux.HandleFunc(readyPath, s.handleReady)
+
 	// Collectors list endpoint (no authentication)
 	mux.HandleFunc("/collectors", s.handleCollectors)
 
@@ -65,29 +153,8 @@ func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 	healthStatus := make(map[string]string)
 	allHealthy := true
 
-	// Leader-required collector: only check if we are the leader
-	s.leMu.Lock()
-	isLeader := s.leaderElector != nil && s.leaderElector.IsLeader()
-	s.leMu.Unlock()
-
 	for name, c := range allCollectors {
-		// Determine if this collector should be checked
-		var shouldCheck bool
-
-		if !s.config.LeaderElection.Enabled {
-			// Leader election disabled: all collectors should be running
-			shouldCheck = true
-		} else {
-			// Leader election enabled
-			if c.RequiresLeaderElection() {
-				shouldCheck = isLeader
-			} else {
-				// Non-leader collector: always check (should always be running)
-				shouldCheck = true
-			}
-		}
-
-		if shouldCheck {
+		if s.expectedRunning(name, c) {
 			err := c.Health()
 			if err != nil {
 				healthStatus[name] = err.Error()
@@ -113,6 +180,89 @@ func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 	})
 }
 
+// handleReady handles readiness requests: the instance is ready once every
+// collector expected to run on it serves complete metrics (see
+// registry.CollectorReady). The response follows the Kubernetes /readyz
+// format, with one line per collector when verbose or not ready.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	allCollectors := s.registry.GetAllCollectors()
+
+	names := slices.Sorted(maps.Keys(allCollectors))
+
+	var (
+		report strings.Builder
+		ready  = true
+	)
+
+	for _, name := range names {
+		c := allCollectors[name]
+		if !s.expectedRunning(name, c) {
+			fmt.Fprintf(&report, "[+]%s skipped: runs on the leader\n", name)
+			continue
+		}
+
+		if err := registry.CollectorReady(c); err != nil {
+			fmt.Fprintf(&report, "[-]%s not ready: %v\n", name, err)
+
+			ready = false
+
+			continue
+		}
+
+		fmt.Fprintf(&report, "[+]%s ok\n", name)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, report.String())
+		fmt.Fprint(w, "readyz check failed\n")
+
+		return
+	}
+
+	if _, verbose := r.URL.Query()["verbose"]; verbose {
+		fmt.Fprint(w, report.String())
+		fmt.Fprint(w, "readyz check passed\n")
+
+		return
+	}
+
+	fmt.Fprint(w, "ok")
+}
+
+// isLeader returns whether this instance currently holds the leader lease
+func (s *Server) isLeader() bool {
+	s.leMu.Lock()
+	defer s.leMu.Unlock()
+
+	return s.leaderElector != nil && s.leaderElector.IsLeader()
+}
+
+// leadsCollector returns whether this instance leads a leader-required
+// collector: with sharding, whether it holds the lease of the collector,
+// otherwise whether it holds the leader lease
+func (s *Server) leadsCollector(name string) bool {
+	if se := s.currentShardedElector(); se != nil {
+		return se.IsLeader(name)
+	}
+
+	return s.isLeader()
+}
+
+// expectedRunning returns whether a collector should be running on this
+// instance: leader collectors only run on their leader when leader election
+// is enabled, other collectors always run
+func (s *Server) expectedRunning(name string, c collector.Collector) bool {
+	if !s.config.LeaderElection.Enabled || !c.RequiresLeaderElection() {
+		return true
+	}
+
+	return s.leadsCollector(name)
+}
+
 // handleCollectors handles collector list requests
 func (s *Server) handleCollectors(w http.ResponseWriter, _ *http.Request) {
 	collectors := s.registry.ListCollectors()
@@ -128,11 +278,29 @@ func (s *Server) handleLeader(w http.ResponseWriter, _ *http.Request) {
 		"enabled": s.config.LeaderElection.Enabled,
 	}
 
-	if s.leaderElector != nil {
-		response["isLeader"] = s.leaderElector.IsLeader()
-		response["currentLeader"] = s.leaderElector.GetLeader()
-		response["identity"] = s.leaderElector.GetIdentity()
-	} else {
+	leaderElector := s.currentLeaderElector()
+	shardedElector := s.currentShardedElector()
+
+	switch {
+	case leaderElector != nil:
+		response["isLeader"] = leaderElector.IsLeader()
+		response["currentLeader"] = leaderElector.GetLeader()
+		response["identity"] = leaderElector.GetIdentity()
+	case shardedElector != nil:
+		leaders := shardedElector.Leaders()
+		shards := make(map[string]any)
+
+		for _, shard := range shardedElector.Shards() {
+			shards[shard] = map[string]any{
+				"isLeader":      shardedElector.IsLeader(shard),
+				"currentLeader": leaders[shard],
+			}
+		}
+
+		response["sharding"] = true
+		response["shards"] = shards
+		response["identity"] = shardedElector.GetIdentity()
+	default:
 		response["isLeader"] = true
 		response["message"] = "Leader election disabled"
 	}
@@ -167,7 +335,10 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	<div>
 		<a href="%s">Metrics</a>
 		<a href="%s">Health</a>
+		<a href="/readyz?verbose">Readiness</a>
 		<a href="/collectors">Collectors</a>
+		<a href="/api/v1/status">Status</a>
+		<a href="/api/v1/certs">Certificates</a>
 	</div>
 </body>
 </html>
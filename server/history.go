@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+// historyPath is the pattern of the per-target history endpoint
+const historyPath = "/api/v1/history/{collector}/{name}"
+
+// handleHistory returns the last results of a target of a collector
+// implementing collector.HistoryReporter, e.g. /api/v1/history/domain/example.com
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"error": "method not allowed",
+		})
+
+		return
+	}
+
+	c, ok := s.registry.GetCollector(r.PathValue("collector"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error": "collector not found",
+		})
+
+		return
+	}
+
+	reporter, ok := c.(collector.HistoryReporter)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error": "collector does not keep history",
+		})
+
+		return
+	}
+
+	history, ok := reporter.History(r.PathValue("name"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error": "no history for target",
+		})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"apiVersion": statusAPIVersion,
+		"collector":  c.Name(),
+		"history":    history,
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/labring/sealos-state-metrics/pkg/leaderelection"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
 )
 
 // setupLeaderElection creates and starts the leader elector
@@ -16,8 +17,17 @@ func (s *Server) setupLeaderElection() error {
 		return fmt.Errorf("failed to get Kubernetes client for leader election: %w", err)
 	}
 
+	leConfig, err := s.buildLeaderElectionConfig()
+	if err != nil {
+		return err
+	}
+
+	if s.config.LeaderElection.Sharding {
+		return s.setupShardedLeaderElection(leConfig, client)
+	}
+
 	elector, err := leaderelection.NewLeaderElector(
-		s.buildLeaderElectionConfig(),
+		leConfig,
 		client,
 		log.WithField("component", "leader-election"),
 	)
@@ -69,6 +79,80 @@ func (s *Server) setupLeaderElection() error {
 	return nil
 }
 
+// setupShardedLeaderElection creates and starts the sharded leader elector:
+// each leader-required collector instance has its own lease and runs on the
+// replica holding it
+func (s *Server) setupShardedLeaderElection(leConfig *leaderelection.Config, client kubernetes.Interface) error {
+	elector, err := leaderelection.NewShardedElector(
+		leConfig,
+		client,
+		s.config.LeaderElection.MaxLeasesPerReplica,
+		log.WithField("component", "leader-election"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create sharded leader elector: %w", err)
+	}
+
+	elector.SetCallbacks(
+		func(ctx context.Context, shard string) {
+			log.WithField("collector", shard).Info("Acquired collector lease, starting collector")
+
+			if err := s.registry.StartCollector(ctx, shard); err != nil {
+				log.WithError(err).WithField("collector", shard).Error("Failed to start collector")
+			}
+		},
+		func(shard string) {
+			log.WithField("collector", shard).Info("Lost collector lease, stopping collector")
+
+			if err := s.registry.StopCollector(shard); err != nil {
+				log.WithError(err).WithField("collector", shard).Error("Failed to stop collector")
+			}
+		},
+	)
+
+	shards := s.registry.LeaderCollectorNames()
+
+	s.leMu.Lock()
+	defer s.leMu.Unlock()
+
+	leCtx, leCtxCancel := context.WithCancel(s.serverCtx)
+	s.leCtxCancel = leCtxCancel
+	s.leDoneCh = make(chan struct{})
+	s.shardedElector = elector
+
+	go func() {
+		defer close(s.leDoneCh)
+
+		log.WithField("collectors", shards).Info("Starting sharded leader election")
+
+		if err := elector.Run(leCtx, shards); err != nil {
+			log.WithError(err).Error("Sharded leader election exited with error")
+		}
+
+		log.Info("Sharded leader election stopped")
+	}()
+
+	return nil
+}
+
+// currentLeaderElector returns the running leader elector, nil when leader
+// election is disabled or stopped
+func (s *Server) currentLeaderElector() *leaderelection.LeaderElector {
+	s.leMu.Lock()
+	defer s.leMu.Unlock()
+
+	return s.leaderElector
+}
+
+// currentShardedElector returns the running sharded leader elector, nil when
+// sharding is disabled or leader election stopped
+func (s *Server) currentShardedElector() *leaderelection.ShardedElector {
+	s.leMu.Lock()
+	defer s.leMu.Unlock()
+
+	return s.shardedElector
+}
+
 // stopLeaderElection stops the current leader election and releases the lease
 func (s *Server) stopLeaderElection() {
 	s.leMu.Lock()
@@ -89,5 +173,6 @@ func (s *Server) stopLeaderElection() {
 		s.leCtxCancel = nil
 		s.leDoneCh = nil
 		s.leaderElector = nil
+		s.shardedElector = nil
 	}
 }
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrThresholdBreached is returned by RunOnce when at least one critical
+// threshold was breached during the batch cycle
+var ErrThresholdBreached = errors.New("critical threshold breached")
+
+// readyWaiter is implemented by collectors that can report when their first
+// collection cycle has completed (see base.BaseCollector.WaitReady)
+type readyWaiter interface {
+	WaitReady(ctx context.Context) error
+}
+
+// RunOnce runs all enabled collectors for a single cycle, writes the gathered
+// metrics to the configured output and evaluates the batch thresholds.
+// Leader election is ignored in batch mode: every collector runs locally.
+func (s *Server) RunOnce(ctx context.Context) error {
+	logger := log.WithField("component", "batch")
+
+	if err := s.initRegistry(ctx); err != nil {
+		return err
+	}
+
+	if err := s.registry.Start(ctx); err != nil {
+		logger.WithError(err).Warn("Some collectors failed to start")
+	}
+
+	defer func() {
+		if err := s.registry.Stop(); err != nil {
+			logger.WithError(err).Warn("Failed to stop collectors")
+		}
+	}()
+
+	s.waitCollectorsReady(ctx, logger)
+
+	families, err := s.promRegistry.Gather()
+	if err != nil {
+		// Gather returns partial results alongside the error
+		logger.WithError(err).Warn("Errors occurred while gathering metrics")
+	}
+
+	if err := s.writeBatchOutput(families); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	breaches := s.evaluateThresholds(families)
+	if len(breaches) > 0 {
+		for _, breach := range breaches {
+			logger.WithField("breach", breach).Error("Critical threshold breached")
+		}
+
+		return fmt.Errorf("%w: %d breach(es)", ErrThresholdBreached, len(breaches))
+	}
+
+	logger.Info("Batch cycle completed, no thresholds breached")
+
+	return nil
+}
+
+// waitCollectorsReady blocks until every started collector has completed its
+// first cycle or the batch timeout elapses
+func (s *Server) waitCollectorsReady(ctx context.Context, logger *log.Entry) {
+	waitCtx, cancel := context.WithTimeout(ctx, s.config.Batch.Timeout)
+	defer cancel()
+
+	for name, c := range s.registry.GetAllCollectors() {
+		waiter, ok := c.(readyWaiter)
+		if !ok {
+			continue
+		}
+
+		if err := waiter.WaitReady(waitCtx); err != nil {
+			logger.WithError(err).
+				WithField("collector", name).
+				Warn("Collector did not become ready, its metrics may be incomplete")
+		}
+	}
+}
+
+// writeBatchOutput writes metric families in Prometheus text format to the
+// configured output file, or stdout when the output is "-" or empty
+func (s *Server) writeBatchOutput(families []*dto.MetricFamily) error {
+	var w io.Writer = os.Stdout
+
+	if output := s.config.Batch.Output; output != "" && output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	encoder := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evaluateThresholds checks gathered metrics against the batch thresholds and
+// returns a human-readable description of every breach
+func (s *Server) evaluateThresholds(families []*dto.MetricFamily) []string {
+	namespace := s.config.Metrics.Namespace
+	certExpiryName := prometheus.BuildFQName(namespace, "domain", "cert_expiry_seconds")
+	domainHealthName := prometheus.BuildFQName(namespace, "domain", "health")
+	minCertExpiry := time.Duration(s.config.Batch.CertExpiryDays) * 24 * time.Hour
+
+	var breaches []string
+
+	for _, family := range families {
+		switch family.GetName() {
+		case certExpiryName:
+			if minCertExpiry <= 0 {
+				continue
+			}
+
+			for _, m := range family.GetMetric() {
+				expiry := time.Duration(m.GetGauge().GetValue() * float64(time.Second))
+				if expiry < minCertExpiry {
+					breaches = append(breaches, fmt.Sprintf(
+						"certificate for %s expires in %s (threshold %d days)",
+						describeLabels(m),
+						expiry.Round(time.Minute),
+						s.config.Batch.CertExpiryDays,
+					))
+				}
+			}
+		case domainHealthName:
+			if !s.config.Batch.FailOnDomainDown {
+				continue
+			}
+
+			for _, m := range family.GetMetric() {
+				if labelValue(m, "type") == "healthy_ips" && m.GetGauge().GetValue() == 0 {
+					breaches = append(breaches, fmt.Sprintf(
+						"domain %s has no healthy IPs",
+						labelValue(m, "domain"),
+					))
+				}
+			}
+		}
+	}
+
+	return breaches
+}
+
+// labelValue returns the value of the named label, or an empty string
+func labelValue(m *dto.Metric, name string) string {
+	for _, label := range m.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+// describeLabels formats metric labels as name=value pairs
+func describeLabels(m *dto.Metric) string {
+	pairs := make([]string, 0, len(m.GetLabel()))
+	for _, label := range m.GetLabel() {
+		pairs = append(pairs, label.GetName()+"="+label.GetValue())
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+
+	"github.com/labring/sealos-state-metrics/pkg/preflight"
+	"github.com/labring/sealos-state-metrics/pkg/registry"
+	log "github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// runPreflight verifies the Kubernetes API, the permissions of the enabled
+// collectors and server features, DNS and the cloud APIs queried by the
+// collectors, before the collectors are created. The report is logged,
+// written to the report file and exported as metrics. An error listing the
+// failed checks is returned when the preflight is set to fail on errors.
+func (s *Server) runPreflight(ctx context.Context) error {
+	cfg := s.config.Preflight
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logger := log.WithField("component", "preflight")
+
+	opts := preflight.Options{
+		DNSNames: cfg.DNSNames,
+		Timeout:  cfg.Timeout,
+	}
+
+	endpoints, err := s.registry.RequiredEndpoints(s.configContent, s.config.EnabledCollectors)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the endpoints of the collectors, not checking them")
+	}
+
+	opts.Endpoints = endpoints
+
+	if !s.config.Standalone {
+		opts.Client = func() (kubernetes.Interface, error) {
+			return s.clientProvider.GetClient()
+		}
+
+		opts.Permissions, err = s.requiredPermissions()
+		if err != nil {
+			logger.WithError(err).Warn("Failed to get the permissions of the collectors, not checking them")
+		}
+	}
+
+	report := preflight.Run(ctx, opts)
+
+	for _, failure := range report.Failures() {
+		logger.WithFields(log.Fields{
+			"check":      failure.Check,
+			"target":     failure.Target,
+			"collectors": failure.Collectors,
+			"hint":       failure.Hint,
+		}).Warn("Preflight check failed: " + failure.Error)
+	}
+
+	if report.Passed {
+		logger.WithField("checks", len(report.Results)).Info("All preflight checks passed")
+	}
+
+	if cfg.ReportPath != "" {
+		if err := report.WriteFile(cfg.ReportPath); err != nil {
+			logger.WithError(err).Warn("Failed to write the preflight report")
+		}
+	}
+
+	s.promRegistry.MustRegister(preflight.NewMetricsCollector(s.config.Metrics.Namespace, func() *preflight.Report {
+		return report
+	}))
+
+	if cfg.FailOnError {
+		return report.Err()
+	}
+
+	return nil
+}
+
+// requiredPermissions returns the permissions of the enabled collectors, of
+// authentication and of the leader election lease, like `generate rbac`
+func (s *Server) requiredPermissions() ([]preflight.Permission, error) {
+	rules, err := s.registry.RequiredRBAC(s.configContent, s.config.EnabledCollectors)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.Server.Auth.Enabled {
+		rules = append(rules,
+			rbacv1.PolicyRule{
+				APIGroups: []string{"authentication.k8s.io"},
+				Resources: []string{"tokenreviews"},
+				Verbs:     []string{"create"},
+			},
+			rbacv1.PolicyRule{
+				APIGroups: []string{"authorization.k8s.io"},
+				Resources: []string{"subjectaccessreviews"},
+				Verbs:     []string{"create"},
+			},
+		)
+	}
+
+	var permissions []preflight.Permission
+	for _, rule := range registry.MergeRBAC(rules) {
+		permissions = append(permissions, preflight.Permission{Rule: rule})
+	}
+
+	if le := s.config.LeaderElection; le.Enabled {
+		// Sharded lease names depend on the collector instances
+		var leaseNames []string
+		if !le.Sharding {
+			leaseNames = []string{le.LeaseName}
+		}
+
+		permissions = append(permissions,
+			preflight.Permission{
+				Namespace: le.Namespace,
+				Rule: rbacv1.PolicyRule{
+					APIGroups: []string{"coordination.k8s.io"},
+					Resources: []string{"leases"},
+					Verbs:     []string{"create"},
+				},
+			},
+			preflight.Permission{
+				Namespace: le.Namespace,
+				Rule: rbacv1.PolicyRule{
+					APIGroups:     []string{"coordination.k8s.io"},
+					Resources:     []string{"leases"},
+					ResourceNames: leaseNames,
+					Verbs:         []string{"get", "update"},
+				},
+			},
+		)
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+)
+
+// registryPath is the admin endpoint describing registered collectors
+const registryPath = "/debug/registry"
+
+// handleRegistry returns the metadata (version, config key, required RBAC)
+// of every registered collector type and the enabled collector instances.
+// Only served by the debug server, which listens on localhost.
+func (s *Server) handleRegistry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"error": "method not allowed",
+		})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"types":     s.registry.ListMetadata(),
+		"instances": s.registry.ListInstances(),
+	})
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
 	"github.com/labring/sealos-state-metrics/pkg/config"
@@ -24,9 +25,15 @@ func (s *Server) Reload(newConfigContent []byte, newConfig *config.GlobalConfig)
 		return errors.New("server not running, context is nil")
 	}
 
+	// Restart only the collectors whose configuration changed, unless a
+	// setting shared by all of them changed
+	changed, selective := s.changedCollectors(newConfigContent, newConfig)
+
 	// 1. Stop all collectors based on current configuration
-	if err := s.stopCollectors(); err != nil {
-		logger.WithError(err).Warn("Failed to stop collectors")
+	if !selective {
+		if err := s.stopCollectors(); err != nil {
+			logger.WithError(err).Warn("Failed to stop collectors")
+		}
 	}
 
 	// Check if K8s config changed before applying new config
@@ -39,6 +46,18 @@ func (s *Server) Reload(newConfigContent []byte, newConfig *config.GlobalConfig)
 		)
 	}
 
+	if s.config.Push != newConfig.Push {
+		logger.Warn(
+			"Push configuration changed but cannot be hot-reloaded - please restart the pod for changes to take effect",
+		)
+	}
+
+	if s.config.Cluster != newConfig.Cluster {
+		logger.Warn(
+			"Cluster identity configuration changed but cannot be hot-reloaded - please restart the pod for changes to take effect",
+		)
+	}
+
 	// Check if DebugServer config changed
 	debugServerConfigChanged := !s.config.DebugServer.Equal(newConfig.DebugServer)
 
@@ -71,6 +90,10 @@ func (s *Server) Reload(newConfigContent []byte, newConfig *config.GlobalConfig)
 		)
 	}
 
+	if selective {
+		return s.restartCollectors(changed, logger)
+	}
+
 	// 3. Reinitialize and start collectors atomically, and setup leader election if needed
 	// This is done atomically to minimize the gap where collectors are running
 	// but leader election is not yet set up
@@ -83,6 +106,43 @@ func (s *Server) Reload(newConfigContent []byte, newConfig *config.GlobalConfig)
 	return nil
 }
 
+// changedCollectors returns the collectors whose configuration changes with
+// newConfig, and whether restarting only them applies it. Changes of the
+// Kubernetes client, leader election or heartbeat, or of a setting shared by
+// all collectors, require recreating every collector.
+// Must be called with s.mu held, before the new configuration is applied.
+func (s *Server) changedCollectors(newConfigContent []byte, newConfig *config.GlobalConfig) ([]string, bool) {
+	if !s.config.Kubernetes.Equal(newConfig.Kubernetes) ||
+		!reflect.DeepEqual(s.config.LeaderElection, newConfig.LeaderElection) ||
+		!reflect.DeepEqual(s.config.Heartbeat, newConfig.Heartbeat) {
+		return nil, false
+	}
+
+	return s.registry.ChangedCollectors(s.initConfigFor(newConfig, newConfigContent))
+}
+
+// restartCollectors restarts the collectors whose configuration changed,
+// leaving the others running.
+// Must be called with s.mu held, after the new configuration is applied.
+func (s *Server) restartCollectors(changed []string, logger *log.Entry) error {
+	if len(changed) == 0 {
+		logger.Info("No collector configuration changed, collectors keep running")
+		return nil
+	}
+
+	isLeader := func(name string) bool {
+		return !s.config.LeaderElection.Enabled || s.leadsCollector(name)
+	}
+
+	if err := s.registry.Restart(s.buildInitConfig(), changed, isLeader, s.heartbeatObserver()); err != nil {
+		return fmt.Errorf("failed to restart collectors: %w", err)
+	}
+
+	logger.WithField("collectors", changed).Info("Server reload completed, changed collectors restarted")
+
+	return nil
+}
+
 // reloadDebugServer reloads the debug HTTP server with new configuration
 func (s *Server) reloadDebugServer() error {
 	// Stop existing debug server if running
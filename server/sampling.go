@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/sampling"
+)
+
+// samplingPath is the admin endpoint controlling debug sampling of raw objects
+const samplingPath = "/debug/sampling"
+
+// handleSampling lists (GET), enables (POST) or disables (DELETE) debug
+// sampling of the raw objects seen by collectors. Only served by the debug
+// server, which listens on localhost.
+//
+//	POST   /debug/sampling?collector=pod&namespace=ns-foo&labelSelector=app=web&duration=10m&limit=50
+//	DELETE /debug/sampling?id=1 (all sessions without id)
+func (s *Server) handleSampling(w http.ResponseWriter, r *http.Request) {
+	sampler := sampling.Default()
+	query := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{
+			"sessions": sampler.Sessions(),
+		})
+	case http.MethodPost:
+		rule := sampling.Rule{
+			Collector:     query.Get("collector"),
+			Namespace:     query.Get("namespace"),
+			Name:          query.Get("name"),
+			LabelSelector: query.Get("labelSelector"),
+		}
+
+		if value := query.Get("duration"); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid duration"})
+				return
+			}
+
+			rule.Duration = duration
+		}
+
+		if value := query.Get("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid limit"})
+				return
+			}
+
+			rule.Limit = limit
+		}
+
+		session, err := sampler.Enable(rule)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, session)
+	case http.MethodDelete:
+		var id int
+
+		if value := query.Get("id"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid id"})
+				return
+			}
+
+			id = parsed
+		}
+
+		if err := sampler.Disable(id); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"error": "method not allowed",
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrapeMetrics instruments the metrics endpoints
+type scrapeMetrics struct {
+	duration     *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+	authRequests *prometheus.CounterVec
+	// timeoutServed counts the scrapes served the last snapshot
+	timeoutServed *prometheus.CounterVec
+}
+
+// newScrapeMetrics creates the scrape instrumentation and registers it with reg
+func newScrapeMetrics(namespace string, reg prometheus.Registerer) *scrapeMetrics {
+	m := &scrapeMetrics{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "state_metric",
+				Name:      "scrape_duration_seconds",
+				Help:      "Duration of metrics endpoint requests, including gathering and encoding",
+				Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"server", "code"},
+		),
+		responseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "state_metric",
+				Name:      "scrape_response_size_bytes",
+				Help:      "Size of metrics endpoint responses as sent on the wire (after compression)",
+				Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+			},
+			[]string{"server"},
+		),
+		authRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "state_metric",
+				Name:      "auth_requests_total",
+				Help:      "Number of requests to authenticated endpoints by result (authorized, unauthenticated, forbidden, error)",
+			},
+			[]string{"server", "result"},
+		),
+		timeoutServed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "state_metric",
+				Name:      "scrape_timeout_served_total",
+				Help:      "Number of scrapes served the last complete snapshot because gathering would exceed their scrape timeout",
+			},
+			[]string{"server"},
+		),
+	}
+
+	reg.MustRegister(m.duration, m.responseSize, m.authRequests, m.timeoutServed)
+
+	return m
+}
+
+// instrument wraps a metrics handler with the scrape histograms for the named server
+func (m *scrapeMetrics) instrument(server string, handler http.Handler) http.Handler {
+	labels := prometheus.Labels{"server": server}
+
+	return promhttp.InstrumentHandlerDuration(
+		m.duration.MustCurryWith(labels),
+		promhttp.InstrumentHandlerResponseSize(
+			m.responseSize.MustCurryWith(labels),
+			handler,
+		),
+	)
+}
+
+// authObserver returns an auth result observer counting the requests of the named server
+func (m *scrapeMetrics) authObserver(server string) func(result string) {
+	requests := m.authRequests.MustCurryWith(prometheus.Labels{"server": server})
+
+	return func(result string) {
+		requests.WithLabelValues(result).Inc()
+	}
+}
@@ -10,11 +10,15 @@ import (
 	"sync"
 
 	"github.com/labring/sealos-state-metrics/pkg/collector"
+	"github.com/labring/sealos-state-metrics/pkg/collector/base"
 	"github.com/labring/sealos-state-metrics/pkg/config"
+	"github.com/labring/sealos-state-metrics/pkg/heartbeat"
 	"github.com/labring/sealos-state-metrics/pkg/httpserver"
 	"github.com/labring/sealos-state-metrics/pkg/identity"
 	"github.com/labring/sealos-state-metrics/pkg/leaderelection"
+	"github.com/labring/sealos-state-metrics/pkg/push"
 	"github.com/labring/sealos-state-metrics/pkg/registry"
+	"github.com/labring/sealos-state-metrics/pkg/telemetry"
 	"github.com/labring/sealos-state-metrics/pkg/tlscache"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -29,8 +33,12 @@ type Server struct {
 	debugServer    *httpserver.Server
 	registry       *registry.Registry
 	promRegistry   *prometheus.Registry
+	scrapeMetrics  *scrapeMetrics
+	snapshots      *snapshotGatherer // Serves the last snapshot to scrapes that would time out
 	leaderElector  *leaderelection.LeaderElector
-	clientProvider collector.ClientProvider // Shared client provider for lazy initialization
+	shardedElector *leaderelection.ShardedElector // Set instead of leaderElector with sharding
+	clientProvider collector.ClientProvider       // Shared client provider for lazy initialization
+	cluster        identity.Cluster               // Cluster identity, resolved once at startup
 
 	// Fields needed for reinitialization
 	mu sync.RWMutex // Protects reload operations; readers (Collect) use RLock, writers (Reload) use Lock
@@ -45,11 +53,15 @@ type Server struct {
 
 // New creates a new server instance
 func New(cfg *config.GlobalConfig, configContent []byte) *Server {
+	promRegistry := prometheus.NewRegistry()
+
 	return &Server{
 		config:        cfg,
 		configContent: configContent,
 		registry:      registry.GetRegistry(),
-		promRegistry:  prometheus.NewRegistry(),
+		promRegistry:  promRegistry,
+		scrapeMetrics: newScrapeMetrics(cfg.Metrics.Namespace, promRegistry),
+		snapshots:     newSnapshotGatherer(promRegistry),
 	}
 }
 
@@ -66,17 +78,42 @@ func (s *Server) Run(ctx context.Context) error {
 // Init initializes the server (Kubernetes client, collectors, HTTP server)
 // This method is exported to allow external control of initialization timing
 func (s *Server) Init(ctx context.Context) error {
+	if err := s.initRegistry(ctx); err != nil {
+		return err
+	}
+
+	// Start collectors (with or without leader election)
+	// Note: This may take several seconds waiting for informer cache sync
+	return s.startCollectors()
+}
+
+// initRegistry creates the client provider and collectors and registers them
+// with the Prometheus registry without starting them
+func (s *Server) initRegistry(ctx context.Context) error {
 	s.serverCtx = ctx
 
 	// Create shared client provider for lazy Kubernetes client initialization
-	s.clientProvider = collector.NewClientProvider(
-		collector.ClientConfig{
-			Kubeconfig: s.config.Kubernetes.Kubeconfig,
-			QPS:        s.config.Kubernetes.QPS,
-			Burst:      s.config.Kubernetes.Burst,
-		},
-		log.WithField("component", "client-provider"),
-	)
+	// (never initialized in standalone mode)
+	if s.config.Standalone {
+		s.clientProvider = collector.NewStandaloneClientProvider()
+	} else {
+		s.clientProvider = collector.NewClientProvider(
+			collector.ClientConfig{
+				Kubeconfig: s.config.Kubernetes.Kubeconfig,
+				QPS:        s.config.Kubernetes.QPS,
+				Burst:      s.config.Kubernetes.Burst,
+			},
+			log.WithField("component", "client-provider"),
+		)
+	}
+
+	// Fail before creating collectors that cannot work
+	if err := s.runPreflight(ctx); err != nil {
+		return err
+	}
+
+	// Resolved once: target labels cannot change without re-registering collectors
+	s.cluster = s.resolveCluster(ctx)
 
 	// Initialize collectors with lazy client loading
 	// The Kubernetes client will be initialized on-demand when collectors call GetClient()
@@ -84,6 +121,8 @@ func (s *Server) Init(ctx context.Context) error {
 		return fmt.Errorf("failed to initialize collectors: %w", err)
 	}
 
+	s.attachHeartbeat()
+
 	// Register collectors with Prometheus wrapped by ReloadAwareCollector
 	// This ensures metrics collection is blocked during reload operations
 	innerCollector := registry.NewPrometheusCollector(s.registry, s.config.Metrics.Namespace)
@@ -91,19 +130,26 @@ func (s *Server) Init(ctx context.Context) error {
 		server: s,
 		inner:  innerCollector,
 	}
-	s.promRegistry.MustRegister(wrappedCollector)
+	s.metricsRegisterer().MustRegister(wrappedCollector)
+	s.promRegistry.MustRegister(identity.NewClusterInfoCollector(s.config.Metrics.Namespace, s.cluster))
+	s.promRegistry.MustRegister(leaderelection.NewMetricsCollector(s.config.Metrics.Namespace, s.currentLeaderElector))
+	s.promRegistry.MustRegister(leaderelection.NewShardMetricsCollector(s.config.Metrics.Namespace, s.currentShardedElector))
+	s.promRegistry.MustRegister(telemetry.NewCollector(s.config.Metrics.Namespace, s.registry))
 
-	// Start collectors (with or without leader election)
-	// Note: This may take several seconds waiting for informer cache sync
-	return s.startCollectors()
+	return nil
 }
 
 // Serve starts the HTTP server and blocks until shutdown
 func (s *Server) Serve() error {
 	// Create TLS config if enabled
 	var tlsConfig *tls.Config
-	if s.config.Server.TLS.Enabled {
-		cache, err := tlscache.New(s.config.Server.TLS.CertFile, s.config.Server.TLS.KeyFile)
+	if tlsCfg := s.config.Server.TLS; tlsCfg.Enabled {
+		var opts []tlscache.Option
+		if tlsCfg.ClientCAFile != "" {
+			opts = append(opts, tlscache.WithClientCA(tlsCfg.ClientCAFile))
+		}
+
+		cache, err := tlscache.New(tlsCfg.CertFile, tlsCfg.KeyFile, opts...)
 		if err != nil {
 			return fmt.Errorf("failed to create TLS certificate cache: %w", err)
 		}
@@ -114,14 +160,12 @@ func (s *Server) Serve() error {
 			return fmt.Errorf("failed to load TLS certificate at startup: %w", err)
 		}
 
-		tlsConfig = &tls.Config{
-			GetCertificate: cache.GetCertificate,
-			MinVersion:     tls.VersionTLS12,
-		}
+		tlsConfig = cache.TLSConfig()
 
 		log.WithFields(log.Fields{
-			"certFile": s.config.Server.TLS.CertFile,
-			"keyFile":  s.config.Server.TLS.KeyFile,
+			"certFile":     tlsCfg.CertFile,
+			"keyFile":      tlsCfg.KeyFile,
+			"clientCAFile": tlsCfg.ClientCAFile,
 		}).Info("TLS enabled with certificate auto-reload via fsnotify")
 	}
 
@@ -161,6 +205,8 @@ func (s *Server) Serve() error {
 		}
 	}
 
+	s.startPush()
+
 	// Wait for context cancellation
 	<-s.serverCtx.Done()
 	log.Info("Context cancelled, shutting down")
@@ -195,6 +241,32 @@ func (s *Server) Shutdown() error {
 	return nil
 }
 
+// startPush starts pushing the metrics to the configured remote endpoint
+// until the server context is done
+func (s *Server) startPush() {
+	cfg := s.config.Push
+	if !cfg.Enabled {
+		return
+	}
+
+	pusher := push.NewPusher(push.Config{
+		URL:          cfg.URL,
+		Format:       cfg.Format,
+		Interval:     cfg.Interval,
+		Timeout:      cfg.Timeout,
+		BatchSize:    cfg.BatchSize,
+		MaxRetries:   cfg.MaxRetries,
+		RetryBackoff: cfg.RetryBackoff,
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		BearerToken:  cfg.BearerToken,
+	}, s.promRegistry, s.config.Metrics.Namespace)
+
+	s.promRegistry.MustRegister(pusher)
+
+	go pusher.Run(s.serverCtx)
+}
+
 // getKubernetesClient returns the Kubernetes client via the shared client provider
 // This is used by leader election
 func (s *Server) getKubernetesClient() (kubernetes.Interface, error) {
@@ -206,33 +278,105 @@ func (s *Server) getKubernetesClient() (kubernetes.Interface, error) {
 
 // buildInitConfig creates registry.InitConfig from current server state
 func (s *Server) buildInitConfig() *registry.InitConfig {
+	return s.initConfigFor(s.config, s.configContent)
+}
+
+// initConfigFor creates registry.InitConfig from a configuration
+func (s *Server) initConfigFor(cfg *config.GlobalConfig, configContent []byte) *registry.InitConfig {
+	// Validated with the configuration
+	legacyUntil, _ := cfg.Metrics.LegacyDeadline()
+
 	return &registry.InitConfig{
 		Ctx:                  s.serverCtx,
 		ClientProvider:       s.clientProvider,
-		ConfigContent:        s.configContent,
-		Identity:             s.config.Identity,
-		NodeName:             s.config.NodeName,
-		PodName:              s.config.PodName,
-		MetricsNamespace:     s.config.Metrics.Namespace,
-		InformerResyncPeriod: s.config.Performance.InformerResyncPeriod,
-		EnabledCollectors:    s.config.EnabledCollectors,
+		ConfigContent:        configContent,
+		Identity:             cfg.Identity,
+		NodeName:             cfg.NodeName,
+		PodName:              cfg.PodName,
+		MetricsNamespace:     cfg.Metrics.Namespace,
+		InformerResyncPeriod: cfg.Performance.InformerResyncPeriod,
+		CollectionTimeout:    cfg.Performance.CollectionTimeout,
+		StaleWatchTimeout:    cfg.Performance.StaleWatchTimeout,
+		EventQueueWorkers:    cfg.Performance.EventQueueWorkers,
+		EventQueueMaxDepth:   cfg.Performance.EventQueueMaxDepth,
+		EnabledCollectors:    cfg.EnabledCollectors,
+		Standalone:           cfg.Standalone,
+		Cluster:              s.cluster,
+
+		LegacyMetricsNamespace:  cfg.Metrics.LegacyNamespace,
+		LegacyMetricsCollectors: cfg.Metrics.LegacyCollectors,
+		LegacyMetricsUntil:      legacyUntil,
+	}
+}
+
+// pollObservable is implemented by collectors embedding base.BaseCollector
+type pollObservable interface {
+	AddPollObserver(observer base.PollObserver)
+}
+
+// attachHeartbeat registers a heartbeat publisher as poll observer on all collectors
+// Must be called after collectors are (re)created and before they are started
+func (s *Server) attachHeartbeat() {
+	observe := s.heartbeatObserver()
+	if observe == nil {
+		return
+	}
+
+	for _, c := range s.registry.GetAllCollectors() {
+		observe(c)
+	}
+}
+
+// heartbeatObserver returns a function registering a heartbeat publisher as
+// poll observer on a collector, nil when the heartbeat is disabled
+func (s *Server) heartbeatObserver() func(c collector.Collector) {
+	if !s.config.Heartbeat.Enabled {
+		return nil
+	}
+
+	publisher := heartbeat.NewPublisher(heartbeat.Config{
+		URL:            s.config.Heartbeat.URL,
+		Collectors:     s.config.Heartbeat.Collectors,
+		Timeout:        s.config.Heartbeat.Timeout,
+		MinInterval:    s.config.Heartbeat.MinInterval,
+		ReportFailures: s.config.Heartbeat.ReportFailures,
+	})
+
+	log.WithFields(log.Fields{
+		"url":        s.config.Heartbeat.URL,
+		"collectors": s.config.Heartbeat.Collectors,
+	}).Info("Heartbeat publisher enabled")
+
+	return func(c collector.Collector) {
+		if observable, ok := c.(pollObservable); ok {
+			observable.AddPollObserver(publisher.Observe)
+		}
 	}
 }
 
 // buildLeaderElectionConfig creates leaderelection.Config from current server state
-func (s *Server) buildLeaderElectionConfig() *leaderelection.Config {
-	return &leaderelection.Config{
-		Namespace: s.config.LeaderElection.Namespace,
-		LeaseName: s.config.LeaderElection.LeaseName,
-		Identity: identity.GetWithConfig(
-			s.config.Identity,
-			s.config.NodeName,
-			s.config.PodName,
-		),
-		LeaseDuration: s.config.LeaderElection.LeaseDuration,
-		RenewDeadline: s.config.LeaderElection.RenewDeadline,
-		RetryPeriod:   s.config.LeaderElection.RetryPeriod,
+func (s *Server) buildLeaderElectionConfig() (*leaderelection.Config, error) {
+	leaderIdentity, err := identity.LeaderIdentity(identity.LeaderOptions{
+		Strategy: s.config.LeaderElection.IdentityStrategy,
+		Identity: s.config.Identity,
+		NodeName: s.config.NodeName,
+		PodName:  s.config.PodName,
+		PodUID:   s.config.PodUID,
+		File:     s.config.LeaderElection.IdentityFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine leader election identity: %w", err)
 	}
+
+	return &leaderelection.Config{
+		Namespace:        s.config.LeaderElection.Namespace,
+		LeaseName:        s.config.LeaderElection.LeaseName,
+		Identity:         leaderIdentity,
+		IdentityStrategy: s.config.LeaderElection.IdentityStrategy,
+		LeaseDuration:    s.config.LeaderElection.LeaseDuration,
+		RenewDeadline:    s.config.LeaderElection.RenewDeadline,
+		RetryPeriod:      s.config.LeaderElection.RetryPeriod,
+	}, nil
 }
 
 // createMainHandler creates the HTTP handler for main server (with optional auth)
@@ -240,9 +384,10 @@ func (s *Server) createMainHandler() (http.Handler, error) {
 	mux := http.NewServeMux()
 	if err := s.setupRoutes(
 		mux,
+		"main",
 		s.config.Server.MetricsPath,
 		s.config.Server.HealthPath,
-		s.config.Server.Auth.Enabled,
+		s.config.Server.Auth.Active(),
 	); err != nil {
 		return nil, err
 	}
@@ -250,11 +395,12 @@ func (s *Server) createMainHandler() (http.Handler, error) {
 	return mux, nil
 }
 
-// createDebugHandler creates HTTP handler for debug server (no auth)
+// createDebugHandler creates HTTP handler for debug server (no auth, admin endpoints)
 func (s *Server) createDebugHandler() (http.Handler, error) {
 	mux := http.NewServeMux()
 	if err := s.setupRoutes(
 		mux,
+		"debug",
 		s.config.DebugServer.MetricsPath,
 		s.config.DebugServer.HealthPath,
 		false,
@@ -262,5 +408,10 @@ func (s *Server) createDebugHandler() (http.Handler, error) {
 		return nil, err
 	}
 
+	// Admin endpoints are only exposed on the localhost debug server
+	mux.HandleFunc(samplingPath, s.handleSampling)
+	mux.HandleFunc(faultsPath, s.handleFaults)
+	mux.HandleFunc(registryPath, s.handleRegistry)
+
 	return mux, nil
 }
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// scrapeTimeoutHeader is set by Prometheus to the scrape timeout of the target
+const scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+
+// snapshotGatherer gathers the metrics at most once at a time and keeps the
+// last complete result, to serve the scrapes a slow gathering would make time
+// out. Scrapes arriving during a gathering wait for it instead of starting
+// another one.
+type snapshotGatherer struct {
+	inner prometheus.Gatherer
+
+	mu sync.Mutex
+	// done is closed when the running gathering completes, nil when none runs
+	done chan struct{}
+	// families and err are the result of the last gathering
+	families []*dto.MetricFamily
+	err      error
+	// snapshot is the result of the last gathering without error, taken at snapshotAt
+	snapshot   []*dto.MetricFamily
+	snapshotAt time.Time
+}
+
+// newSnapshotGatherer creates a snapshot gatherer of inner
+func newSnapshotGatherer(inner prometheus.Gatherer) *snapshotGatherer {
+	return &snapshotGatherer{inner: inner}
+}
+
+// gather returns the metrics gathered by the running or a new gathering. When
+// it does not complete before deadline, the last snapshot is returned along
+// with its time instead; without snapshot, gather waits for the gathering.
+func (g *snapshotGatherer) gather(deadline time.Time) ([]*dto.MetricFamily, time.Time, error) {
+	g.mu.Lock()
+
+	done := g.done
+	if done == nil {
+		done = make(chan struct{})
+		g.done = done
+
+		go g.run(done)
+	}
+
+	g.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		g.mu.Lock()
+		snapshot, snapshotAt := g.snapshot, g.snapshotAt
+		g.mu.Unlock()
+
+		if !snapshotAt.IsZero() {
+			return snapshot, snapshotAt, nil
+		}
+
+		<-done
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.families, time.Time{}, g.err
+}
+
+// run gathers the metrics and records the result, closing done once complete
+func (g *snapshotGatherer) run(done chan struct{}) {
+	families, err := g.inner.Gather()
+
+	g.mu.Lock()
+	g.families, g.err = families, err
+
+	if err == nil {
+		g.snapshot, g.snapshotAt = families, time.Now()
+	}
+
+	g.done = nil
+	g.mu.Unlock()
+
+	close(done)
+}
+
+// scrapeDeadline returns the deadline of a scrape from the scrape timeout
+// header, minus offset when the timeout exceeds it, and false when the header
+// is missing or invalid
+func scrapeDeadline(r *http.Request, start time.Time, offset time.Duration) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(r.Header.Get(scrapeTimeoutHeader), 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > offset {
+		timeout -= offset
+	}
+
+	return start.Add(timeout), true
+}
+
+// scrapeTimeoutHandler serves the scrapes carrying the Prometheus scrape
+// timeout from the snapshot gatherer, the last snapshot being served when the
+// gathering would exceed the timeout. Other scrapes are served by handler.
+func (s *Server) scrapeTimeoutHandler(serverName string, handler http.Handler, opts promhttp.HandlerOpts) http.Handler {
+	offset := s.config.Server.ScrapeTimeoutOffset
+	served := s.scrapeMetrics.timeoutServed.WithLabelValues(serverName)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := scrapeDeadline(r, time.Now(), offset)
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			families, snapshotAt, err := s.snapshots.gather(deadline)
+			if !snapshotAt.IsZero() {
+				served.Inc()
+				log.WithFields(log.Fields{
+					"server":        serverName,
+					"scrapeTimeout": r.Header.Get(scrapeTimeoutHeader),
+					"snapshotAge":   time.Since(snapshotAt).Round(time.Millisecond).String(),
+				}).Warn("Gathering metrics would exceed the scrape timeout, serving the last snapshot")
+			}
+
+			return families, err
+		})
+
+		promhttp.HandlerFor(gatherer, opts).ServeHTTP(w, r)
+	})
+}
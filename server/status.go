@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labring/sealos-state-metrics/pkg/collector"
+)
+
+const (
+	// statusPath is the path of the structured status endpoint
+	statusPath = "/api/v1/status"
+	// statusAPIVersion is bumped on incompatible changes of the status schema
+	statusAPIVersion = "v1"
+)
+
+// StatusResponse is the versioned body of the status endpoint
+type StatusResponse struct {
+	APIVersion  string         `json:"apiVersion"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Collectors  map[string]any `json:"collectors"`
+}
+
+// handleStatus returns the cached state of every collector implementing
+// collector.StatusReporter, so consumers can render health pages without
+// querying Prometheus. Collectors can be filtered with ?collector=name (repeatable).
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"error": "method not allowed",
+		})
+
+		return
+	}
+
+	filter := make(map[string]bool)
+	for _, name := range r.URL.Query()["collector"] {
+		filter[name] = true
+	}
+
+	response := StatusResponse{
+		APIVersion:  statusAPIVersion,
+		GeneratedAt: time.Now().UTC(),
+		Collectors:  make(map[string]any),
+	}
+
+	for name, c := range s.registry.GetAllCollectors() {
+		if len(filter) > 0 && !filter[name] {
+			continue
+		}
+
+		reporter, ok := c.(collector.StatusReporter)
+		if !ok {
+			continue
+		}
+
+		response.Collectors[name] = reporter.Status()
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
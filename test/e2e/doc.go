@@ -0,0 +1,9 @@
+// Package e2e holds the end-to-end tests of the server. They run the full
+// server in-process against a real apiserver (e.g. a kind cluster), apply
+// fixtures and assert on the /metrics output. They are built with the e2e
+// tag and skipped without a KUBECONFIG:
+//
+//	kind create cluster --name sealos-e2e
+//	kind get kubeconfig --name sealos-e2e > /tmp/sealos-e2e.kubeconfig
+//	KUBECONFIG=/tmp/sealos-e2e.kubeconfig go test -tags e2e -v ./test/e2e/
+package e2e
@@ -0,0 +1,211 @@
+//go:build e2e
+
+package e2e_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// widgetGroup is the API group of the Widget CRD installed by the tests
+	widgetGroup = "e2e.sealos.io"
+	// ingressHost is the host of the fixture Ingress, never resolvable
+	ingressHost = "app.sealos-e2e.invalid"
+)
+
+var (
+	crdGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+	widgetGVR = schema.GroupVersionResource{Group: widgetGroup, Version: "v1", Resource: "widgets"}
+)
+
+// tlsNotAfter is the expiry of the certificate stored in the fixture secret
+var tlsNotAfter = time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+// applyFixtures creates the test namespace and the objects the tests assert
+// on: a TLS secret, an Ingress and a Widget custom resource. The returned
+// function deletes them, also when applying failed halfway.
+func applyFixtures(ctx context.Context) (func(), error) {
+	var cleanups []func()
+
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	_, err := env.client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: env.namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return cleanup, fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	cleanups = append(cleanups, func() {
+		_ = env.client.CoreV1().Namespaces().Delete(context.Background(), env.namespace, metav1.DeleteOptions{})
+	})
+
+	secret, err := tlsSecret("web-tls", "web.sealos-e2e.invalid")
+	if err != nil {
+		return cleanup, err
+	}
+
+	if _, err := env.client.CoreV1().Secrets(env.namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return cleanup, fmt.Errorf("failed to create TLS secret: %w", err)
+	}
+
+	if _, err := env.client.NetworkingV1().Ingresses(env.namespace).Create(ctx, ingress(), metav1.CreateOptions{}); err != nil {
+		return cleanup, fmt.Errorf("failed to create Ingress: %w", err)
+	}
+
+	_, err = env.dynamicClient.Resource(crdGVR).Create(ctx, widgetCRD(), metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return cleanup, fmt.Errorf("failed to create Widget CRD: %w", err)
+	}
+
+	cleanups = append(cleanups, func() {
+		_ = env.dynamicClient.Resource(crdGVR).
+			Delete(context.Background(), widgetGVR.Resource+"."+widgetGroup, metav1.DeleteOptions{})
+	})
+
+	// The CRD is served once established, creating Widgets fails until then
+	err = wait.PollUntilContextTimeout(ctx, pollInterval, pollTimeout, true, func(ctx context.Context) (bool, error) {
+		_, err := env.dynamicClient.Resource(widgetGVR).Namespace(env.namespace).
+			Create(ctx, widget("blue", 3, "Ready"), metav1.CreateOptions{})
+
+		return err == nil, nil
+	})
+	if err != nil {
+		return cleanup, fmt.Errorf("failed to create Widget: %w", err)
+	}
+
+	return cleanup, nil
+}
+
+// tlsSecret returns a TLS secret storing a self-signed certificate for host
+func tlsSecret(name, host string) (*corev1.Secret, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     tlsNotAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}, nil
+}
+
+// ingress returns an Ingress routing ingressHost to a Service
+func ingress() *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: ingressHost,
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: "web",
+							Port: networkingv1.ServiceBackendPort{Number: 80},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+// widgetCRD returns the namespaced Widget CustomResourceDefinition
+func widgetCRD() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": widgetGVR.Resource + "." + widgetGroup},
+		"spec": map[string]any{
+			"group": widgetGroup,
+			"scope": "Namespaced",
+			"names": map[string]any{
+				"plural":   "widgets",
+				"singular": "widget",
+				"kind":     "Widget",
+				"listKind": "WidgetList",
+			},
+			"versions": []any{map[string]any{
+				"name":    "v1",
+				"served":  true,
+				"storage": true,
+				"schema": map[string]any{"openAPIV3Schema": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"spec": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"replicas": map[string]any{"type": "integer"},
+								"phase":    map[string]any{"type": "string"},
+							},
+						},
+					},
+				}},
+			}},
+		},
+	}}
+}
+
+// widget returns a Widget custom resource
+func widget(name string, replicas int64, phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": widgetGroup + "/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": name},
+		"spec": map[string]any{
+			"replicas": replicas,
+			"phase":    phase,
+		},
+	}}
+}
@@ -0,0 +1,264 @@
+//go:build e2e
+
+package e2e_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/labring/sealos-state-metrics/pkg/collector/all" // Import all collectors
+	"github.com/labring/sealos-state-metrics/pkg/config"
+	"github.com/labring/sealos-state-metrics/server"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// pollInterval and pollTimeout bound the wait for a metric to appear
+	pollInterval = time.Second
+	pollTimeout  = time.Minute
+)
+
+// env is the cluster and server shared by the tests
+var env struct {
+	client        kubernetes.Interface
+	dynamicClient dynamic.Interface
+	namespace     string
+	metricsURL    string
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv("KUBECONFIG") == "" {
+		fmt.Println("KUBECONFIG is not set, skipping the end-to-end tests")
+		os.Exit(0)
+	}
+
+	os.Exit(run(m))
+}
+
+// run applies the fixtures, starts the server and runs the tests, cleaning
+// up whatever was set up before returning the exit code
+func run(m *testing.M) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+	if err != nil {
+		fmt.Printf("Failed to load KUBECONFIG: %v\n", err)
+		return 1
+	}
+
+	env.client = kubernetes.NewForConfigOrDie(restConfig)
+	env.dynamicClient = dynamic.NewForConfigOrDie(restConfig)
+	env.namespace = fmt.Sprintf("sealos-e2e-%d", time.Now().Unix())
+
+	cleanup, err := applyFixtures(ctx)
+	defer cleanup()
+
+	if err != nil {
+		fmt.Printf("Failed to apply fixtures: %v\n", err)
+		return 1
+	}
+
+	stop, err := startServer(ctx)
+	if err != nil {
+		fmt.Printf("Failed to start server: %v\n", err)
+		return 1
+	}
+	defer stop()
+
+	return m.Run()
+}
+
+// serverConfig is the configuration the server runs the tests with
+const serverConfig = `
+server:
+  address: %q
+debugServer:
+  enabled: false
+leaderElection:
+  enabled: false
+metrics:
+  namespace: sealos
+enabledCollectors: [cert, domain, dynamic]
+collectors:
+  cert:
+    namespaces: [%[2]s]
+  domain:
+    discoverIngresses: true
+    includeCertCheck: false
+    checkTimeout: 2s
+    checkInterval: 10s
+  dynamic:
+    crds:
+      - name: widget
+        gvr:
+          group: ` + widgetGroup + `
+          version: v1
+          resource: widgets
+        namespaces: [%[2]s]
+        commonLabels:
+          namespace: metadata.namespace
+          widget: metadata.name
+        metrics:
+          - type: gauge
+            name: replicas
+            help: Widget replicas
+            path: spec.replicas
+          - type: info
+            name: info
+            help: Widget information
+            labels:
+              phase: spec.phase
+`
+
+// startServer runs the full server in-process on a free port and waits for
+// it to serve metrics. The returned function stops it.
+func startServer(ctx context.Context) (func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a free port: %w", err)
+	}
+
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	content := []byte(fmt.Sprintf(serverConfig, address, env.namespace))
+
+	cfg, err := config.LoadGlobalConfig(config.LoadOptions{ConfigContent: content, DisableExit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	serverCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- server.New(cfg, content).Run(serverCtx)
+	}()
+
+	stop := func() {
+		cancel()
+
+		if err := <-done; err != nil {
+			fmt.Printf("Server exited with error: %v\n", err)
+		}
+	}
+
+	env.metricsURL = "http://" + address + cfg.Server.MetricsPath
+
+	deadline := time.Now().Add(pollTimeout)
+	for {
+		if _, err := scrape(ctx); err == nil {
+			return stop, nil
+		}
+
+		select {
+		case err := <-done:
+			cancel()
+			return nil, fmt.Errorf("server exited before serving metrics: %w", err)
+		case <-time.After(pollInterval):
+		}
+
+		if time.Now().After(deadline) {
+			stop()
+			return nil, errors.New("server did not serve metrics in time")
+		}
+	}
+}
+
+// scrape returns the metric families served on /metrics
+func scrape(ctx context.Context) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, env.metricsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// waitForMetric scrapes /metrics until the family name has a series with
+// labels (a subset of its labels), and returns the series
+func waitForMetric(t *testing.T, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	var lastErr error
+
+	deadline := time.Now().Add(pollTimeout)
+	for time.Now().Before(deadline) {
+		families, err := scrape(context.Background())
+		if err != nil {
+			lastErr = err
+		} else if metric := findMetric(families[name], labels); metric != nil {
+			return metric
+		}
+
+		time.Sleep(pollInterval)
+	}
+
+	t.Fatalf("Metric %s%v not served within %s (last scrape error: %v)", name, labels, pollTimeout, lastErr)
+
+	return nil
+}
+
+// findMetric returns the first series of a family with labels, nil if none
+func findMetric(family *dto.MetricFamily, labels map[string]string) *dto.Metric {
+	if family == nil {
+		return nil
+	}
+
+	for _, metric := range family.GetMetric() {
+		matched := 0
+
+		for _, label := range metric.GetLabel() {
+			if value, ok := labels[label.GetName()]; ok && value == label.GetValue() {
+				matched++
+			}
+		}
+
+		if matched == len(labels) {
+			return metric
+		}
+	}
+
+	return nil
+}
+
+// describe formats a series for failure messages
+func describe(metric *dto.Metric) string {
+	pairs := make([]string, 0, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		pairs = append(pairs, label.GetName()+"="+label.GetValue())
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,64 @@
+//go:build e2e
+
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCertExpiry(t *testing.T) {
+	metric := waitForMetric(t, "sealos_cert_expiry_timestamp_seconds", map[string]string{
+		"namespace":   env.namespace,
+		"secret":      "web-tls",
+		"common_name": "web.sealos-e2e.invalid",
+	})
+
+	if got := int64(metric.GetGauge().GetValue()); got != tlsNotAfter.Unix() {
+		t.Errorf("Expected expiry %d, got %d for %s", tlsNotAfter.Unix(), got, describe(metric))
+	}
+}
+
+func TestIngressHostChecked(t *testing.T) {
+	metric := waitForMetric(t, "sealos_domain_status", map[string]string{
+		"domain":     ingressHost,
+		"check_type": "http",
+	})
+
+	// The host never resolves
+	if metric.GetGauge().GetValue() != 0 {
+		t.Errorf("Expected the unresolvable Ingress host to fail, got %s", describe(metric))
+	}
+}
+
+func TestCustomResourceMetrics(t *testing.T) {
+	labels := map[string]string{"namespace": env.namespace, "widget": "blue"}
+
+	if metric := waitForMetric(t, "sealos_widget_replicas", labels); metric.GetGauge().GetValue() != 3 {
+		t.Errorf("Expected 3 replicas, got %s %v", describe(metric), metric.GetGauge().GetValue())
+	}
+
+	waitForMetric(t, "sealos_widget_info", map[string]string{"widget": "blue", "phase": "Ready"})
+
+	// Updates are picked up from the watch
+	patch, err := json.Marshal(map[string]any{"spec": map[string]any{"replicas": 5, "phase": "Scaling"}})
+	if err != nil {
+		t.Fatalf("Failed to marshal patch: %v", err)
+	}
+
+	_, err = env.dynamicClient.Resource(widgetGVR).Namespace(env.namespace).
+		Patch(context.Background(), "blue", types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		t.Fatalf("Failed to patch Widget: %v", err)
+	}
+
+	waitForMetric(t, "sealos_widget_info", map[string]string{"widget": "blue", "phase": "Scaling"})
+
+	if metric := waitForMetric(t, "sealos_widget_replicas", labels); metric.GetGauge().GetValue() != 5 {
+		t.Errorf("Expected 5 replicas after the update, got %s %v", describe(metric), metric.GetGauge().GetValue())
+	}
+}